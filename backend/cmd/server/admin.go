@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/Hermitf/the-pass/internal/config"
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/internal/service"
+)
+
+const adminUsage = `用法: server admin [-config ./config.yaml] <命令> [参数]
+
+命令:
+  create -username NAME -password PASS [-name 姓名] [-email 邮箱]
+              创建后台管理员（管理员账号不开放注册）`
+
+// runAdmin 执行 admin 子命令
+func runAdmin(args []string) error {
+	fs := flag.NewFlagSet("admin", flag.ContinueOnError)
+	configPath := fs.String("config", "./config.yaml", "配置文件路径")
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), adminUsage) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("缺少管理员命令")
+	}
+
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+	if cmd != "create" {
+		fs.Usage()
+		return fmt.Errorf("未知的管理员命令: %s", cmd)
+	}
+
+	createFlags := flag.NewFlagSet("admin create", flag.ContinueOnError)
+	username := createFlags.String("username", "", "用户名")
+	password := createFlags.String("password", "", "密码（需满足密码强度要求）")
+	name := createFlags.String("name", "", "姓名")
+	email := createFlags.String("email", "", "邮箱")
+	if err := createFlags.Parse(rest); err != nil {
+		return err
+	}
+	if *username == "" || *password == "" {
+		return errors.New("用法: admin create -username NAME -password PASS")
+	}
+
+	configManager := config.NewConfigManager()
+	if err := configManager.Load(*configPath); err != nil {
+		return fmt.Errorf("配置加载失败: %w", err)
+	}
+	dbManager := database.NewDatabaseManager()
+	if err := dbManager.Connect(configManager.GetConfig().Database); err != nil {
+		return err
+	}
+	defer dbManager.Close()
+
+	adminService := service.NewAdminService(service.AdminServiceDependencies{
+		AdminRepo: repository.NewAdminRepository(dbManager.GetDB()),
+	})
+	admin := &model.Admin{
+		Username:     *username,
+		PasswordHash: *password,
+		Name:         *name,
+		Email:        *email,
+	}
+	if err := adminService.CreateAdmin(context.Background(), admin); err != nil {
+		return err
+	}
+	fmt.Printf("管理员已创建: id=%d username=%s\n", admin.ID, admin.Username)
+	return nil
+}
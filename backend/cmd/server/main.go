@@ -34,6 +34,22 @@ import (
 // @description 使用Bearer Token进行认证，格式: Bearer {token}
 
 func main() {
+	// 数据库迁移子命令：server migrate up|down N|status|create NAME
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal("迁移失败: ", err)
+		}
+		return
+	}
+
+	// 管理员子命令：server admin create -username NAME -password PASS
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		if err := runAdmin(os.Args[2:]); err != nil {
+			log.Fatal("管理员命令执行失败: ", err)
+		}
+		return
+	}
+
 	// 创建应用上下文（核心依赖管理）
 	appCtx := app.NewAppContext()
 
@@ -50,7 +66,10 @@ func main() {
 	}()
 
 	// 创建路由（传入应用上下文）
-	router := handler.NewRouter(appCtx)
+	router, err := handler.NewRouter(appCtx)
+	if err != nil {
+		log.Fatal("路由初始化失败:", err)
+	}
 
 	// 启动服务
 	port := appCtx.Config.Server.Port
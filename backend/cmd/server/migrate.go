@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/config"
+	"github.com/Hermitf/the-pass/internal/database"
+)
+
+const migrateUsage = `用法: server migrate [-config ./config.yaml] [-dir internal/database/migrations] <命令>
+
+命令:
+  up          执行全部未执行的迁移
+  down [N]    回滚最近执行的 N 个迁移（默认 1）
+  status      查看迁移执行状态
+  create NAME 在 -dir 目录下生成下一个版本的 up/down 空白文件`
+
+// runMigrate 执行 migrate 子命令
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	configPath := fs.String("config", "./config.yaml", "配置文件路径")
+	dir := fs.String("dir", database.MigrationsDir, "迁移文件目录（仅 create 使用）")
+	fs.Usage = func() { fmt.Fprintln(fs.Output(), migrateUsage) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("缺少迁移命令")
+	}
+
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+	if cmd == "create" {
+		if len(rest) != 1 {
+			return errors.New("用法: migrate create NAME")
+		}
+		up, down, err := database.CreateMigration(*dir, rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已创建:\n  %s\n  %s\n", up, down)
+		return nil
+	}
+
+	configManager := config.NewConfigManager()
+	if err := configManager.Load(*configPath); err != nil {
+		return fmt.Errorf("配置加载失败: %w", err)
+	}
+	dbManager := database.NewDatabaseManager()
+	if err := dbManager.Connect(configManager.GetConfig().Database); err != nil {
+		return err
+	}
+	defer dbManager.Close()
+
+	migrator, err := dbManager.Migrator()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch cmd {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已执行 %d 个迁移\n", len(applied))
+	case "down":
+		n := 1
+		if len(rest) > 0 {
+			if n, err = strconv.Atoi(rest[0]); err != nil || n <= 0 {
+				return fmt.Errorf("回滚数量必须为正整数: %s", rest[0])
+			}
+		}
+		reverted, err := migrator.Down(ctx, n)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已回滚 %d 个迁移\n", len(reverted))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, st := range statuses {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Local().Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%06d\t%s\t%s\n", st.Version, st.Name, applied)
+		}
+		return w.Flush()
+	default:
+		fs.Usage()
+		return fmt.Errorf("未知的迁移命令: %s", cmd)
+	}
+	return nil
+}
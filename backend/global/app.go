@@ -13,7 +13,6 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/Hermitf/the-pass/internal/config"
-	"github.com/Hermitf/the-pass/internal/model"
 )
 
 // Application 全局应用实例
@@ -64,17 +63,7 @@ func (a *Application) InitDatabase() error {
 	a.DB = db
 	log.Println("数据库连接成功")
 
-	// create tables and initialize data
-	if err := a.DB.AutoMigrate(
-		&model.User{},
-		&model.Employee{},
-		&model.Merchant{},
-		&model.Rider{},
-	); err != nil {
-		log.Printf("数据库自动迁移失败: %v", err)
-		return err
-	}
-	log.Println("数据库自动迁移完成")
+	// 表结构由 internal/database 的版本化迁移维护（`server migrate up`），此处不再 AutoMigrate
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,6 +12,10 @@ import (
 
 	"github.com/Hermitf/the-pass/internal/config"
 	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/auth"
+	"github.com/Hermitf/the-pass/pkg/challenge"
+	"github.com/Hermitf/the-pass/pkg/mail"
 	"github.com/Hermitf/the-pass/pkg/sms"
 )
 
@@ -26,11 +31,45 @@ type AppContext struct {
 	DB          *gorm.DB
 	RedisClient *redis.Client
 	SMSService  *sms.Service
+	JWTKeys     auth.KeyProvider
+
+	// SMSProvider 短信通道，供验证码以外的业务通知复用；SMS 未启用时为 nil
+	SMSProvider sms.Provider
+
+	// SMSSendLog 短信发送日志，启用异步发送队列时用于处理送达回执；否则为 nil
+	SMSSendLog sms.SendLogStore
+
+	// SMSChallenge 发送验证码前的人机验证关卡，未启用时为 nil
+	SMSChallenge *challenge.Gate
+
+	// SMSPhoneLists 短信号码黑白名单，SMS 未启用时为 nil
+	SMSPhoneLists sms.PhoneListStore
+
+	// Mailer 邮件发送服务，未配置 mail.provider 时为 nil（邮件找回密码不可用）
+	Mailer mail.Sender
+
+	// 后台任务生命周期：Close 时取消并等待全部任务退出后再释放连接
+	bgCtx    context.Context
+	bgCancel context.CancelFunc
+	bgWG     sync.WaitGroup
 }
 
 // NewAppContext 创建应用上下文
 func NewAppContext() *AppContext {
-	return &AppContext{}
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	return &AppContext{bgCtx: bgCtx, bgCancel: bgCancel}
+}
+
+// RunBackground 启动随应用生命周期运行的后台任务
+// task 应在 ctx 取消后尽快返回；Close 会等待其退出，再关闭数据库与 Redis
+func (ctx *AppContext) RunBackground(name string, task func(context.Context)) {
+	ctx.bgWG.Add(1)
+	go func() {
+		defer ctx.bgWG.Done()
+		log.Printf("▶️ 后台任务启动: %s", name)
+		task(ctx.bgCtx)
+		log.Printf("⏹️ 后台任务退出: %s", name)
+	}()
 }
 
 // Initialize 初始化应用上下文，加载所有依赖
@@ -47,6 +86,12 @@ func (ctx *AppContext) Initialize(configPath string) error {
 	ctx.Config = configManager.GetConfig()
 	log.Println("✅ 配置加载成功")
 
+	// 加载JWT签名密钥（PEM 文件有误时尽早失败）
+	if err := ctx.initJWTKeys(); err != nil {
+		return fmt.Errorf("JWT密钥加载失败: %w", err)
+	}
+	log.Println("✅ JWT密钥加载成功")
+
 	// 初始化数据库
 	dbManager := database.NewDatabaseManager()
 	if err := dbManager.Initialize(ctx.Config.Database); err != nil {
@@ -63,8 +108,15 @@ func (ctx *AppContext) Initialize(configPath string) error {
 
 	log.Println("✅ Redis初始化成功")
 
-	// 初始化短信服务（如果启用）
-	ctx.initSMSService()
+	// 初始化短信服务（如果启用；服务商配置有误时尽早失败）
+	if err := ctx.initSMSService(); err != nil {
+		return fmt.Errorf("SMS服务初始化失败: %w", err)
+	}
+
+	// 初始化邮件服务（配置有误时尽早失败）
+	if err := ctx.initMailer(); err != nil {
+		return fmt.Errorf("邮件服务初始化失败: %w", err)
+	}
 
 	log.Println("🎉 应用上下文初始化完成")
 	return nil
@@ -94,32 +146,194 @@ func (ctx *AppContext) initRedis() error {
 	return nil
 }
 
+// initJWTKeys 根据配置加载当前签名密钥与轮换中的旧验证密钥
+func (ctx *AppContext) initJWTKeys() error {
+	jwtCfg := ctx.Config.JWT
+
+	active := auth.KeyConfig{
+		KeyID:          jwtCfg.KeyID,
+		Algorithm:      jwtCfg.Algorithm,
+		Secret:         jwtCfg.SecretKey,
+		PrivateKeyFile: jwtCfg.PrivateKeyFile,
+		PublicKeyFile:  jwtCfg.PublicKeyFile,
+	}
+	previous := make([]auth.KeyConfig, 0, len(jwtCfg.PreviousKeys))
+	for _, k := range jwtCfg.PreviousKeys {
+		previous = append(previous, auth.KeyConfig{
+			KeyID:         k.KeyID,
+			Algorithm:     k.Algorithm,
+			Secret:        k.Secret,
+			PublicKeyFile: k.PublicKeyFile,
+		})
+	}
+
+	keys, err := auth.LoadKeyProvider(active, previous)
+	if err != nil {
+		return err
+	}
+	ctx.JWTKeys = keys
+	return nil
+}
+
 // initSMSService 初始化短信业务服务
-func (ctx *AppContext) initSMSService() {
+func (ctx *AppContext) initSMSService() error {
 	smsCfg := ctx.Config.SMS
 	if !smsCfg.Enabled {
 		log.Println("SMS 服务未启用，跳过初始化")
-		return
+		return nil
 	}
 
 	store := sms.NewRedisStore(ctx.RedisClient)
-	provider := sms.NewMockProvider()
+	provider, err := newSMSProvider(smsCfg)
+	if err != nil {
+		return err
+	}
 	runtimeCfg := sms.SMSRuntimeConfig{
-		Enabled:    smsCfg.Enabled,
-		ExpireIn:   smsCfg.ExpireIn,
-		RateMax:    smsCfg.RateLimit.MaxCount,
-		RateWindow: smsCfg.RateLimit.Interval,
-		DailyMax:   0, // 当前配置未提供每日上限，如需使用可在配置中添加
-		Template:   smsCfg.TemplateCode,
+		Enabled:     smsCfg.Enabled,
+		ExpireIn:    smsCfg.ExpireIn,
+		RateMax:     smsCfg.RateLimit.MaxCount,
+		RateWindow:  smsCfg.RateLimit.Interval,
+		DailyMax:    smsCfg.RateLimit.DailyMax,
+		MaxAttempts: smsCfg.MaxAttempts,
+		Template:    smsCfg.TemplateCode,
+
+		Limits: sms.SendLimits{
+			IPMax:           smsCfg.Limits.IPMax,
+			IPWindow:        smsCfg.Limits.IPWindow,
+			DeviceMax:       smsCfg.Limits.DeviceMax,
+			DeviceWindow:    smsCfg.Limits.DeviceWindow,
+			PrefixMax:       smsCfg.Limits.PrefixMax,
+			PrefixWindow:    smsCfg.Limits.PrefixWindow,
+			PrefixLength:    smsCfg.Limits.PrefixLength,
+			GlobalHourlyMax: smsCfg.Limits.GlobalHourlyMax,
+
+			UnknownDeviceMax: smsCfg.Limits.UnknownDeviceMax,
+		},
 	}
+	ctx.SMSProvider = provider
+	ctx.SMSPhoneLists = store
 	ctx.SMSService = sms.NewService(store, provider, runtimeCfg)
+
+	if queueCfg := smsCfg.Queue; queueCfg.Enabled {
+		ctx.SMSSendLog = repository.NewSMSSendLogRepository(ctx.DB)
+		queue := sms.NewQueue(ctx.RedisClient, provider, ctx.SMSSendLog, sms.QueueConfig{
+			Workers:     queueCfg.Workers,
+			MaxAttempts: queueCfg.MaxAttempts,
+			ClaimIdle:   queueCfg.ClaimIdle,
+		})
+		ctx.SMSService.SetQueue(queue)
+		ctx.RunBackground("sms-send-queue", queue.Run)
+		log.Println("短信异步发送队列已启用")
+	}
+	if challengeCfg := smsCfg.Challenge; challengeCfg.Enabled {
+		ctx.SMSChallenge = challenge.NewGate(
+			challenge.NewRedisStore(ctx.RedisClient),
+			challenge.NewVolumeRisk(ctx.RedisClient, challenge.VolumePolicy{
+				Window:       challengeCfg.Window,
+				FreeSends:    challengeCfg.FreeSends,
+				CaptchaAfter: challengeCfg.CaptchaAfter,
+			}),
+			challenge.GateConfig{
+				TTL: challengeCfg.TTL,
+				Challengers: map[challenge.Risk]challenge.Challenger{
+					challenge.RiskElevated: challenge.NewProofOfWork(challengeCfg.PoWDifficulty),
+					challenge.RiskHigh:     challenge.NewImageCaptcha(),
+				},
+			},
+		)
+		log.Println("短信发送人机验证已启用")
+	}
 	log.Println("✅ SMS 服务初始化成功")
+	return nil
+}
+
+// initMailer 初始化邮件服务；未配置时邮件渠道关闭，不回退到只打日志的实现
+func (ctx *AppContext) initMailer() error {
+	mailCfg := ctx.Config.Mail
+	sender, err := mail.NewSender(mail.SenderConfig{
+		Provider: mailCfg.Provider,
+		Host:     mailCfg.Host,
+		Port:     mailCfg.Port,
+		Username: mailCfg.Username,
+		Password: mailCfg.Password,
+		From:     mailCfg.From,
+		Timeout:  mailCfg.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+	if sender == nil {
+		log.Println("邮件服务未配置，邮件找回密码不可用")
+		return nil
+	}
+
+	ctx.Mailer = sender
+	log.Println("✅ 邮件服务初始化成功")
+	return nil
+}
+
+// newSMSProvider 按配置创建短信通道
+// 配置了 sms.providers 时组合为故障转移通道，否则使用单个服务商
+func newSMSProvider(smsCfg config.SMSConfig) (sms.Provider, error) {
+	if len(smsCfg.Providers) == 0 {
+		log.Printf("短信服务商: %s", smsCfg.Provider)
+		return sms.NewProvider(sms.ProviderConfig{
+			Name:               smsCfg.Provider,
+			AccessKey:          smsCfg.APIKey,
+			SecretKey:          smsCfg.APISecret,
+			SignName:           smsCfg.SignName,
+			TemplateCode:       smsCfg.TemplateCode,
+			NotifyTemplateCode: smsCfg.NotifyTemplateCode,
+			AppID:              smsCfg.AppID,
+			Region:             smsCfg.Region,
+			Endpoint:           smsCfg.Endpoint,
+			Timeout:            smsCfg.Timeout,
+		})
+	}
+
+	members := make([]sms.FailoverMember, 0, len(smsCfg.Providers))
+	for _, p := range smsCfg.Providers {
+		provider, err := sms.NewProvider(sms.ProviderConfig{
+			Name:               p.Provider,
+			AccessKey:          p.APIKey,
+			SecretKey:          p.APISecret,
+			SignName:           p.SignName,
+			TemplateCode:       p.TemplateCode,
+			NotifyTemplateCode: p.NotifyTemplateCode,
+			AppID:              p.AppID,
+			Region:             p.Region,
+			Endpoint:           p.Endpoint,
+			Timeout:            p.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		name := p.Name
+		if name == "" {
+			name = p.Provider
+		}
+		members = append(members, sms.FailoverMember{Name: name, Provider: provider, Priority: p.Priority, Weight: p.Weight})
+		log.Printf("短信服务商: %s（优先级 %d，权重 %d）", name, p.Priority, p.Weight)
+	}
+	return sms.NewFailoverProvider(members, sms.FailoverConfig{
+		FailureThreshold: smsCfg.Failover.FailureThreshold,
+		OpenTimeout:      smsCfg.Failover.OpenTimeout,
+		MaxAttempts:      smsCfg.Failover.MaxAttempts,
+		Backoff:          smsCfg.Failover.Backoff,
+		MaxBackoff:       smsCfg.Failover.MaxBackoff,
+	})
 }
 
 // Close 关闭所有资源
 func (ctx *AppContext) Close() error {
 	var errors []error
 
+	// 停止后台任务（任务退出前可能仍需访问数据库与 Redis）
+	if ctx.bgCancel != nil {
+		ctx.bgCancel()
+		ctx.bgWG.Wait()
+	}
+
 	// 关闭Redis连接
 	if ctx.RedisClient != nil {
 		if err := ctx.RedisClient.Close(); err != nil {
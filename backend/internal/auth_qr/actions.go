@@ -2,6 +2,12 @@ package authqr
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // mergeMetadata 将给定的 kv 合并进票据的 Metadata
@@ -20,6 +26,7 @@ func mergeMetadata(dst map[string]string, src map[string]string) map[string]stri
 }
 
 // MarkScanned 将票据状态从 pending 推进到 scanned，写入可选的元数据
+// meta 中携带 MetaScannedBy 时，重复扫码须为同一账号
 func (s *Store) MarkScanned(ctx context.Context, id string, meta map[string]string) (*Ticket, error) {
 	return s.UpdateTicket(ctx, id, func(t *Ticket) error {
 		switch t.Status {
@@ -28,7 +35,10 @@ func (s *Store) MarkScanned(ctx context.Context, id string, meta map[string]stri
 			t.Metadata = mergeMetadata(t.Metadata, meta)
 			return nil
 		case TicketStatusScanned:
-			// 幂等：已是 scanned 直接合并元数据
+			// 幂等：同一账号重复扫码直接合并元数据
+			if err := checkScanner(t, meta[MetaScannedBy]); err != nil {
+				return err
+			}
 			t.Metadata = mergeMetadata(t.Metadata, meta)
 			return nil
 		default:
@@ -38,12 +48,23 @@ func (s *Store) MarkScanned(ctx context.Context, id string, meta map[string]stri
 	})
 }
 
+// checkScanner 若票据已记录扫码账号，则要求操作者与之一致
+func checkScanner(t *Ticket, identity string) error {
+	if scannedBy, ok := t.Metadata[MetaScannedBy]; ok && identity != "" && scannedBy != identity {
+		return ErrTicketScannedByOther
+	}
+	return nil
+}
+
 // Confirm 将票据状态从 scanned 推进到 confirmed，并绑定用户信息
 func (s *Store) Confirm(ctx context.Context, id string, userID int64, userType string, meta map[string]string) (*Ticket, error) {
 	return s.UpdateTicket(ctx, id, func(t *Ticket) error {
 		if t.Status != TicketStatusScanned {
 			return ErrTicketExpired
 		}
+		if err := checkScanner(t, ScannerIdentity(userID, userType)); err != nil {
+			return err
+		}
 		t.Status = TicketStatusConfirmed
 		t.UserID = userID
 		t.UserType = userType
@@ -53,10 +74,14 @@ func (s *Store) Confirm(ctx context.Context, id string, userID int64, userType s
 }
 
 // Reject 将票据置为 rejected，可附带原因到 Metadata
+// meta 中携带 MetaScannedBy 时，会校验操作者与已记录的扫码账号一致
 func (s *Store) Reject(ctx context.Context, id string, reason string, meta map[string]string) (*Ticket, error) {
 	return s.UpdateTicket(ctx, id, func(t *Ticket) error {
 		switch t.Status {
 		case TicketStatusPending, TicketStatusScanned:
+			if err := checkScanner(t, meta[MetaScannedBy]); err != nil {
+				return err
+			}
 			t.Status = TicketStatusRejected
 			m := map[string]string{"reject_reason": reason}
 			t.Metadata = mergeMetadata(t.Metadata, m)
@@ -67,3 +92,50 @@ func (s *Store) Reject(ctx context.Context, id string, reason string, meta map[s
 		}
 	})
 }
+
+// Redeem 兑换已确认的票据：校验状态为 confirmed 且未过期后在同一事务内删除票据。
+// 并发轮询时只有一个调用方能拿到票据，其余返回 ErrTicketNotFound，保证票据只能兑换一次。
+// 过期以票据中的 ExpiresAt 为准，不依赖 Redis key 的 TTL。
+func (s *Store) Redeem(ctx context.Context, id string) (*Ticket, error) {
+	key := ticketKey(id)
+	var redeemed *Ticket
+
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				return ErrTicketNotFound
+			}
+			return err
+		}
+
+		var ticket Ticket
+		if err := json.Unmarshal(data, &ticket); err != nil {
+			return fmt.Errorf("unmarshal ticket failed: %w", err)
+		}
+		if time.Now().UTC().After(ticket.ExpiresAt) {
+			return ErrTicketExpired
+		}
+		if ticket.Status != TicketStatusConfirmed {
+			return ErrTicketNotConfirmed
+		}
+
+		pipe := tx.TxPipeline()
+		pipe.Del(ctx, key)
+		if _, err := pipe.Exec(ctx); err != nil {
+			if errors.Is(err, redis.TxFailedErr) {
+				return ErrTicketNotFound
+			}
+			return err
+		}
+
+		redeemed = &ticket
+		return nil
+	}, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return redeemed, nil
+}
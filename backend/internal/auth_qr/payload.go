@@ -0,0 +1,47 @@
+package authqr
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PayloadType 二维码载荷类型标识，移动端据此识别扫码登录二维码。
+const PayloadType = "the-pass/qr-login"
+
+// ErrInvalidPayload 表示二维码内容无法解析或类型不匹配。
+var ErrInvalidPayload = errors.New("二维码内容无效")
+
+// Payload 编码进二维码的内容，仅包含票据 ID 与过期时间，不含任何凭据。
+type Payload struct {
+	Type      string    `json:"type"`
+	TicketID  string    `json:"ticket_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// EncodePayload 将票据编码为二维码文本（JSON 后使用 URL 安全的 base64）。
+func EncodePayload(t *Ticket) (string, error) {
+	if t == nil || t.ID == "" {
+		return "", ErrInvalidPayload
+	}
+	data, err := json.Marshal(Payload{Type: PayloadType, TicketID: t.ID, ExpiresAt: t.ExpiresAt})
+	if err != nil {
+		return "", fmt.Errorf("marshal payload failed: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodePayload 解析二维码文本，供移动端或测试还原票据 ID。
+func DecodePayload(encoded string) (*Payload, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidPayload
+	}
+	var p Payload
+	if err := json.Unmarshal(data, &p); err != nil || p.Type != PayloadType || p.TicketID == "" {
+		return nil, ErrInvalidPayload
+	}
+	return &p, nil
+}
@@ -0,0 +1,44 @@
+package authqr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestStore_RedeemRejectsExpiredTicket(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	store := NewStore(client)
+	ctx := context.Background()
+
+	// key 的 TTL 仍然有效，但票据本身已过期（例如 TTL 与时钟不一致）
+	now := time.Now().UTC()
+	ticket := Ticket{
+		ID:        "expired",
+		Status:    TicketStatusConfirmed,
+		UserID:    7,
+		UserType:  "user",
+		ExpiresAt: now.Add(-time.Second),
+		CreatedAt: now.Add(-2 * time.Minute),
+		UpdatedAt: now.Add(-time.Minute),
+	}
+	payload, _ := json.Marshal(ticket)
+	if err := client.Set(ctx, ticketKey(ticket.ID), payload, time.Hour).Err(); err != nil {
+		t.Fatalf("seed ticket: %v", err)
+	}
+
+	if _, err := store.Redeem(ctx, ticket.ID); !errors.Is(err, ErrTicketExpired) {
+		t.Fatalf("Redeem err=%v want ErrTicketExpired", err)
+	}
+}
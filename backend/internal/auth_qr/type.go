@@ -3,6 +3,7 @@ package authqr
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -35,8 +36,21 @@ var (
 	ErrTicketNotFound = errors.New("票据不存在或已失效")
 	// ErrTicketExpired 表示票据已过期，不可继续使用（与 NotFound 区分：key 仍存在但逻辑上失效）。
 	ErrTicketExpired = errors.New("票据已过期")
+	// ErrTicketNotConfirmed 表示票据尚未被移动端确认，不能兑换登录令牌。
+	ErrTicketNotConfirmed = errors.New("票据尚未确认")
+	// ErrTicketScannedByOther 表示票据已被其他账号扫码，当前账号无权确认或拒绝。
+	ErrTicketScannedByOther = errors.New("票据已被其他账号扫码")
 )
 
+// MetaScannedBy 记录扫码账号的 Metadata 键，值格式为 "{userType}:{userID}"。
+// 确认/拒绝阶段据此校验操作者与扫码者一致，防止票据被他人劫持确认。
+const MetaScannedBy = "scanned_by"
+
+// ScannerIdentity 生成写入 MetaScannedBy 的账号标识。
+func ScannerIdentity(userID int64, userType string) string {
+	return fmt.Sprintf("%s:%d", userType, userID)
+}
+
 // Ticket 承载扫码登录票据的状态数据。
 type Ticket struct {
 	ID        string            `json:"id"`
@@ -9,11 +9,17 @@ import (
 )
 
 type Configuration struct {
-	Server   ServerConfig   `mapstructure:"server" json:"server" yaml:"server"`
-	Database DatabaseConfig `mapstructure:"database" json:"database" yaml:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt" json:"jwt" yaml:"jwt"`
-	SMS      SMSConfig      `json:"sms" yaml:"sms"`
-	Redis    RedisConfig    `mapstructure:"redis" json:"redis" yaml:"redis"`
+	Server        ServerConfig        `mapstructure:"server" json:"server" yaml:"server"`
+	Database      DatabaseConfig      `mapstructure:"database" json:"database" yaml:"database"`
+	JWT           JWTConfig           `mapstructure:"jwt" json:"jwt" yaml:"jwt"`
+	SMS           SMSConfig           `mapstructure:"sms" json:"sms" yaml:"sms"`
+	Redis         RedisConfig         `mapstructure:"redis" json:"redis" yaml:"redis"`
+	Dispatch      DispatchConfig      `mapstructure:"dispatch" json:"dispatch" yaml:"dispatch"`
+	RiderLocation RiderLocationConfig `mapstructure:"rider_location" json:"rider_location" yaml:"rider_location"`
+	PasswordReset PasswordResetConfig `mapstructure:"password_reset" json:"password_reset" yaml:"password_reset"`
+	LoginLimit    LoginLimitConfig    `mapstructure:"login_limit" json:"login_limit" yaml:"login_limit"`
+	Storage       StorageConfig       `mapstructure:"storage" json:"storage" yaml:"storage"`
+	Mail          MailConfig          `mapstructure:"mail" json:"mail" yaml:"mail"`
 }
 
 type CORSConfig struct {
@@ -24,6 +30,10 @@ type CORSConfig struct {
 type ServerConfig struct {
 	Port int        `mapstructure:"port" json:"port" yaml:"port"`
 	CORS CORSConfig `mapstructure:"cors" json:"cors" yaml:"cors"`
+	// RequestTimeout 单个请求的处理超时，超时后取消下游数据库与 Redis 调用；默认 10s，负数表示不限制
+	RequestTimeout time.Duration `mapstructure:"request_timeout" json:"request_timeout" yaml:"request_timeout"`
+	// TrustedProxies 允许设置 X-Forwarded-For 的反向代理地址或网段；为空时不信任任何代理，客户端 IP 取连接来源地址
+	TrustedProxies []string `mapstructure:"trusted_proxies" json:"trusted_proxies" yaml:"trusted_proxies"`
 }
 
 type DatabaseConfig struct {
@@ -32,26 +42,135 @@ type DatabaseConfig struct {
 	Username string `mapstructure:"username" json:"username" yaml:"username"`
 	Password string `mapstructure:"password" json:"password" yaml:"password"`
 	DbName   string `mapstructure:"db_name" json:"db_name" yaml:"db_name"`
+	// MigrateOnStart 启动时自动执行未执行的迁移；关闭时需手动执行 `server migrate up`
+	MigrateOnStart bool `mapstructure:"migrate_on_start" json:"migrate_on_start" yaml:"migrate_on_start"`
 }
 
 type JWTConfig struct {
 	SecretKey string `mapstructure:"secret_key" json:"secret_key" yaml:"secret_key"`
 	ExpiresIn int64  `mapstructure:"expires_in" json:"expires_in" yaml:"expires_in"`
+	// RefreshExpiresIn 刷新令牌有效期（秒），为 0 时默认 7 天
+	RefreshExpiresIn int64 `mapstructure:"refresh_expires_in" json:"refresh_expires_in" yaml:"refresh_expires_in"`
+
+	// Algorithm 签名算法：HS256（默认，使用 SecretKey）/ RS256 / ES256（使用 PEM 文件）
+	Algorithm      string `mapstructure:"algorithm" json:"algorithm" yaml:"algorithm"`
+	KeyID          string `mapstructure:"key_id" json:"key_id" yaml:"key_id"`
+	PrivateKeyFile string `mapstructure:"private_key_file" json:"private_key_file" yaml:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file" json:"public_key_file" yaml:"public_key_file"`
+	// PreviousKeys 密钥轮换期间仍需用于验证的旧密钥
+	PreviousKeys []JWTKeyConfig `mapstructure:"previous_keys" json:"previous_keys" yaml:"previous_keys"`
+
+	Issuer   string `mapstructure:"issuer" json:"issuer" yaml:"issuer"`
+	Audience string `mapstructure:"audience" json:"audience" yaml:"audience"`
+	Leeway   int64  `mapstructure:"leeway" json:"leeway" yaml:"leeway"` // 时钟偏差容忍（秒）
+}
+
+// JWTKeyConfig 轮换期间的验证密钥，非对称算法只需公钥
+type JWTKeyConfig struct {
+	KeyID         string `mapstructure:"key_id" json:"key_id" yaml:"key_id"`
+	Algorithm     string `mapstructure:"algorithm" json:"algorithm" yaml:"algorithm"`
+	Secret        string `mapstructure:"secret" json:"secret" yaml:"secret"`
+	PublicKeyFile string `mapstructure:"public_key_file" json:"public_key_file" yaml:"public_key_file"`
 }
 
 type SMSConfig struct {
-	Enabled      bool            `json:"enabled" yaml:"enabled"`
-	Provider     string          `json:"provider" yaml:"provider"`
-	APIKey       string          `json:"api_key" yaml:"api_key"`
-	APISecret    string          `json:"api_secret" yaml:"api_secret"`
-	TemplateCode string          `json:"template_code" yaml:"template_code"`
-	ExpireIn     time.Duration   `json:"expire_in" yaml:"expire_in"`
-	RateLimit    RateLimitConfig `json:"rate_limit" yaml:"rate_limit"`
+	Enabled      bool            `mapstructure:"enabled" json:"enabled" yaml:"enabled"`
+	Provider     string          `mapstructure:"provider" json:"provider" yaml:"provider"`
+	APIKey       string          `mapstructure:"api_key" json:"api_key" yaml:"api_key"`
+	APISecret    string          `mapstructure:"api_secret" json:"api_secret" yaml:"api_secret"`
+	TemplateCode string          `mapstructure:"template_code" json:"template_code" yaml:"template_code"`
+	ExpireIn     time.Duration   `mapstructure:"expire_in" json:"expire_in" yaml:"expire_in"`
+	RateLimit    RateLimitConfig `mapstructure:"rate_limit" json:"rate_limit" yaml:"rate_limit"`
+	MaxAttempts  int             `mapstructure:"max_attempts" json:"max_attempts" yaml:"max_attempts"` // 单个验证码允许的校验次数，0 使用默认值
+
+	// 服务商接入参数：provider 取 mock（默认）/ aliyun / tencent，api_key、api_secret 为对应的 AccessKey 或 SecretId/SecretKey；
+	// 真实服务商下 template_code 为服务商模板编号，验证码作为变量 code（腾讯云为第 1 个变量）传入
+	SignName           string        `mapstructure:"sign_name" json:"sign_name" yaml:"sign_name"`                                  // 短信签名，真实服务商必填
+	NotifyTemplateCode string        `mapstructure:"notify_template_code" json:"notify_template_code" yaml:"notify_template_code"` // 审核结果等业务通知模板，正文作为变量 content 传入；为空时通知短信发送失败只记日志
+	AppID              string        `mapstructure:"app_id" json:"app_id" yaml:"app_id"`                                           // 腾讯云 SmsSdkAppId
+	Region             string        `mapstructure:"region" json:"region" yaml:"region"`                                           // 地域，默认阿里云 cn-hangzhou / 腾讯云 ap-guangzhou
+	Endpoint           string        `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`                                     // 接口地址，为空使用官方地址
+	Timeout            time.Duration `mapstructure:"timeout" json:"timeout" yaml:"timeout"`                                        // 单次调用超时，默认 5s
+
+	// Providers 多服务商故障转移，非空时忽略上面的单服务商参数；
+	// sms.template_code 会传给每个服务商，各家模板编号不同时应留空，改在各服务商下配置
+	Providers []SMSProviderConfig `mapstructure:"providers" json:"providers" yaml:"providers"`
+	Failover  SMSFailoverConfig   `mapstructure:"failover" json:"failover" yaml:"failover"`
+
+	// Queue 异步发送队列，启用后验证码入队即返回，并记录发送日志与送达回执
+	Queue SMSQueueConfig `mapstructure:"queue" json:"queue" yaml:"queue"`
+
+	// Challenge 发送验证码前的人机验证，按同一 IP、目标手机号、设备的发送量逐级要求工作量证明、图片验证码
+	Challenge SMSChallengeConfig `mapstructure:"challenge" json:"challenge" yaml:"challenge"`
+
+	// Limits 手机号之外的发送限额（客户端 IP、设备、号段、全站每小时预算），零值字段不限制
+	Limits SMSLimitsConfig `mapstructure:"limits" json:"limits" yaml:"limits"`
+}
+
+// SMSProviderConfig 故障转移中的单个短信服务商
+type SMSProviderConfig struct {
+	Name               string        `mapstructure:"name" json:"name" yaml:"name"`                                                 // 实例名称，用于日志与统计，默认取 provider
+	Provider           string        `mapstructure:"provider" json:"provider" yaml:"provider"`                                     // mock / aliyun / tencent
+	Priority           int           `mapstructure:"priority" json:"priority" yaml:"priority"`                                     // 越小越优先
+	Weight             int           `mapstructure:"weight" json:"weight" yaml:"weight"`                                           // 同优先级按权重分流，默认 1
+	APIKey             string        `mapstructure:"api_key" json:"api_key" yaml:"api_key"`                                        // AccessKeyId / SecretId
+	APISecret          string        `mapstructure:"api_secret" json:"api_secret" yaml:"api_secret"`                               // AccessKeySecret / SecretKey
+	SignName           string        `mapstructure:"sign_name" json:"sign_name" yaml:"sign_name"`                                  // 短信签名
+	TemplateCode       string        `mapstructure:"template_code" json:"template_code" yaml:"template_code"`                      // 验证码模板编号
+	NotifyTemplateCode string        `mapstructure:"notify_template_code" json:"notify_template_code" yaml:"notify_template_code"` // 业务通知模板编号
+	AppID              string        `mapstructure:"app_id" json:"app_id" yaml:"app_id"`                                           // 腾讯云 SmsSdkAppId
+	Region             string        `mapstructure:"region" json:"region" yaml:"region"`                                           // 地域
+	Endpoint           string        `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`                                     // 接口地址，为空使用官方地址
+	Timeout            time.Duration `mapstructure:"timeout" json:"timeout" yaml:"timeout"`                                        // 单次调用超时，默认 5s
+}
+
+// SMSFailoverConfig 多服务商熔断与重试配置，零值字段使用默认值
+type SMSFailoverConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold" json:"failure_threshold" yaml:"failure_threshold"` // 连续失败多少次后熔断，默认 3
+	OpenTimeout      time.Duration `mapstructure:"open_timeout" json:"open_timeout" yaml:"open_timeout"`                // 熔断持续时间，默认 30s
+	MaxAttempts      int           `mapstructure:"max_attempts" json:"max_attempts" yaml:"max_attempts"`                // 单条短信最多尝试的服务商个数，默认全部
+	Backoff          time.Duration `mapstructure:"backoff" json:"backoff" yaml:"backoff"`                               // 换服务商重试前的等待，逐次翻倍，默认 200ms
+	MaxBackoff       time.Duration `mapstructure:"max_backoff" json:"max_backoff" yaml:"max_backoff"`                   // 重试等待上限，默认 2s
+}
+
+// SMSQueueConfig 基于 Redis Stream 的短信发送队列配置，零值字段使用默认值
+type SMSQueueConfig struct {
+	Enabled       bool          `mapstructure:"enabled" json:"enabled" yaml:"enabled"`                      // 是否启用异步发送
+	Workers       int           `mapstructure:"workers" json:"workers" yaml:"workers"`                      // 每个实例的工作协程数，默认 4
+	MaxAttempts   int           `mapstructure:"max_attempts" json:"max_attempts" yaml:"max_attempts"`       // 单条短信最多发送次数，默认 3
+	ClaimIdle     time.Duration `mapstructure:"claim_idle" json:"claim_idle" yaml:"claim_idle"`             // 领取后超时未确认即由其他实例接管，默认 1m
+	CallbackToken string        `mapstructure:"callback_token" json:"callback_token" yaml:"callback_token"` // 送达回执回调地址中的 token 参数，为空时不开放回调
+}
+
+// SMSChallengeConfig 短信发送人机验证配置，零值字段使用默认值
+type SMSChallengeConfig struct {
+	Enabled       bool          `mapstructure:"enabled" json:"enabled" yaml:"enabled"`                      // 是否启用
+	Window        time.Duration `mapstructure:"window" json:"window" yaml:"window"`                         // 发送量统计窗口，默认 1h
+	FreeSends     int           `mapstructure:"free_sends" json:"free_sends" yaml:"free_sends"`             // 窗口内同一 IP / 手机号 / 设备免验证的发送次数，默认 3，超出后要求工作量证明
+	CaptchaAfter  int           `mapstructure:"captcha_after" json:"captcha_after" yaml:"captcha_after"`    // 窗口内发送次数超过该值改为图片验证码，默认 10
+	PoWDifficulty int           `mapstructure:"pow_difficulty" json:"pow_difficulty" yaml:"pow_difficulty"` // 工作量证明前导零比特数，默认 20
+	TTL           time.Duration `mapstructure:"ttl" json:"ttl" yaml:"ttl"`                                  // 挑战有效期，默认 2m
+}
+
+// SMSLimitsConfig 短信发送多维度限额配置，max 为 0 的维度不限制
+type SMSLimitsConfig struct {
+	IPMax           int           `mapstructure:"ip_max" json:"ip_max" yaml:"ip_max"`                                  // 同一客户端 IP 窗口内发送次数
+	IPWindow        time.Duration `mapstructure:"ip_window" json:"ip_window" yaml:"ip_window"`                         // IP 统计窗口
+	DeviceMax       int           `mapstructure:"device_max" json:"device_max" yaml:"device_max"`                      // 同一设备（X-Device-ID 请求头）窗口内发送次数
+	DeviceWindow    time.Duration `mapstructure:"device_window" json:"device_window" yaml:"device_window"`             // 设备统计窗口
+	PrefixMax       int           `mapstructure:"prefix_max" json:"prefix_max" yaml:"prefix_max"`                      // 同一号段窗口内发送次数
+	PrefixWindow    time.Duration `mapstructure:"prefix_window" json:"prefix_window" yaml:"prefix_window"`             // 号段统计窗口
+	PrefixLength    int           `mapstructure:"prefix_length" json:"prefix_length" yaml:"prefix_length"`             // 号段长度，默认 7（网号 + 地区码）
+	GlobalHourlyMax int           `mapstructure:"global_hourly_max" json:"global_hourly_max" yaml:"global_hourly_max"` // 全站每小时发送预算
+
+	UnknownDeviceMax int `mapstructure:"unknown_device_max" json:"unknown_device_max" yaml:"unknown_device_max"` // 未上报设备标识的请求共用一个设备桶，窗口内合计发送次数，默认同 device_max
 }
 
 type RateLimitConfig struct {
-	Interval time.Duration `json:"interval" yaml:"interval"`
-	MaxCount int           `json:"max_count" yaml:"max_count"`
+	Interval time.Duration `mapstructure:"interval" json:"interval" yaml:"interval"`
+	MaxCount int           `mapstructure:"max_count" json:"max_count" yaml:"max_count"`
+
+	DailyMax int `mapstructure:"daily_max" json:"daily_max" yaml:"daily_max"` // 单个手机号每日发送上限，0 不限制
 }
 
 type RedisConfig struct {
@@ -63,6 +182,52 @@ type RedisConfig struct {
 	MinIdleConns int    `mapstructure:"min_idle_conns" json:"min_idle_conns" yaml:"min_idle_conns"`
 }
 
+// DispatchConfig 派单配置，零值字段使用默认值
+type DispatchConfig struct {
+	SearchRadiusKm  float64       `mapstructure:"search_radius_km" json:"search_radius_km" yaml:"search_radius_km"`    // 候选配送员搜索半径，默认 5km
+	AcceptTimeout   time.Duration `mapstructure:"accept_timeout" json:"accept_timeout" yaml:"accept_timeout"`          // 单个配送员的接单超时，默认 30s
+	MaxCandidates   int           `mapstructure:"max_candidates" json:"max_candidates" yaml:"max_candidates"`          // 依次派单的候选人数上限，默认 10
+	MaxActiveOrders int64         `mapstructure:"max_active_orders" json:"max_active_orders" yaml:"max_active_orders"` // 配送员同时进行中的订单上限，默认 3
+}
+
+// RiderLocationConfig 配送员实时位置配置，零值字段使用默认值
+type RiderLocationConfig struct {
+	LivenessTTL   time.Duration `mapstructure:"liveness_ttl" json:"liveness_ttl" yaml:"liveness_ttl"`       // 超过该时长未上报视为离线，默认 60s
+	FlushInterval time.Duration `mapstructure:"flush_interval" json:"flush_interval" yaml:"flush_interval"` // 批量落库与失联检查周期，默认 10s
+}
+
+// PasswordResetConfig 找回密码配置，零值字段使用默认值
+type PasswordResetConfig struct {
+	EmailTokenTTL time.Duration `mapstructure:"email_token_ttl" json:"email_token_ttl" yaml:"email_token_ttl"` // 邮件令牌有效期，默认 30m
+	TicketTTL     time.Duration `mapstructure:"ticket_ttl" json:"ticket_ttl" yaml:"ticket_ttl"`                // 校验通过后改密票据有效期，默认 10m
+	ResetURL      string        `mapstructure:"reset_url" json:"reset_url" yaml:"reset_url"`                   // 前端重置页面地址，邮件中附带 ?token=，为空时只发送令牌
+}
+
+// MailConfig 邮件服务配置；provider 为空时不发送邮件，找回密码的邮件渠道随之关闭
+type MailConfig struct {
+	Provider string        `mapstructure:"provider" json:"provider" yaml:"provider"` // 目前支持 smtp
+	Host     string        `mapstructure:"host" json:"host" yaml:"host"`             // SMTP 服务器地址
+	Port     int           `mapstructure:"port" json:"port" yaml:"port"`             // 465 使用隐式 TLS，其余端口支持时升级 STARTTLS
+	Username string        `mapstructure:"username" json:"username" yaml:"username"` // 为空时不认证
+	Password string        `mapstructure:"password" json:"password" yaml:"password"`
+	From     string        `mapstructure:"from" json:"from" yaml:"from"`          // 发件人地址
+	Timeout  time.Duration `mapstructure:"timeout" json:"timeout" yaml:"timeout"` // 单封邮件发送超时，默认 10s
+}
+
+// LoginLimitConfig 登录失败限制配置，零值字段使用默认值
+type LoginLimitConfig struct {
+	AccountMaxAttempts int           `mapstructure:"account_max_attempts" json:"account_max_attempts" yaml:"account_max_attempts"` // 同一账号+IP 窗口内最大失败次数，默认 5
+	IPMaxAttempts      int           `mapstructure:"ip_max_attempts" json:"ip_max_attempts" yaml:"ip_max_attempts"`                // 同一 IP 窗口内最大失败次数，默认 50
+	Window             time.Duration `mapstructure:"window" json:"window" yaml:"window"`                                           // 失败计数窗口，默认 15m
+	Lockout            time.Duration `mapstructure:"lockout" json:"lockout" yaml:"lockout"`                                        // 触发后锁定时长，默认 15m
+}
+
+// StorageConfig 上传文件存储配置，零值字段使用默认值
+type StorageConfig struct {
+	LocalDir      string `mapstructure:"local_dir" json:"local_dir" yaml:"local_dir"`                   // 本地存储根目录，默认 ./data/uploads
+	MaxUploadSize int64  `mapstructure:"max_upload_size" json:"max_upload_size" yaml:"max_upload_size"` // 单个文件大小上限（字节），默认 10MB
+}
+
 // ConfigManager 配置管理器
 type ConfigManager struct {
 	viper  *viper.Viper
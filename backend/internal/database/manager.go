@@ -1,16 +1,20 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"github.com/Hermitf/the-pass/internal/config"
-	"github.com/Hermitf/the-pass/internal/model"
 )
 
+// migrateOnStartTimeout 启动时执行迁移（含等待其它实例释放迁移锁）的最长时间
+const migrateOnStartTimeout = 5 * time.Minute
+
 // DatabaseManager 数据库管理器
 type DatabaseManager struct {
 	db *gorm.DB
@@ -21,8 +25,42 @@ func NewDatabaseManager() *DatabaseManager {
 	return &DatabaseManager{}
 }
 
-// Initialize 初始化数据库连接
+// Initialize 初始化数据库连接并检查迁移状态
+// 表结构由版本化迁移维护（见 migrations/ 与 `server migrate`）：
+// 配置 migrate_on_start 时启动即执行未执行的迁移（advisory lock 保证多实例串行），
+// 否则仅在存在未执行迁移时打印警告。
 func (dm *DatabaseManager) Initialize(dbConfig config.DatabaseConfig) error {
+	if err := dm.Connect(dbConfig); err != nil {
+		return err
+	}
+
+	migrator, err := dm.Migrator()
+	if err != nil {
+		return fmt.Errorf("加载迁移失败: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), migrateOnStartTimeout)
+	defer cancel()
+
+	if dbConfig.MigrateOnStart {
+		if _, err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("数据库迁移失败: %w", err)
+		}
+		log.Println("数据库迁移完成")
+		return nil
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("读取迁移状态失败: %w", err)
+	}
+	if pending > 0 {
+		log.Printf("⚠️ 存在 %d 个未执行的数据库迁移，请执行 `server migrate up`", pending)
+	}
+	return nil
+}
+
+// Connect 仅建立数据库连接，不检查迁移（供 migrate 子命令使用）
+func (dm *DatabaseManager) Connect(dbConfig config.DatabaseConfig) error {
 	// 检查必需的数据库配置字段
 	if dbConfig.Username == "" || dbConfig.Password == "" ||
 		dbConfig.Host == "" || dbConfig.Port == 0 || dbConfig.DbName == "" {
@@ -41,30 +79,16 @@ func (dm *DatabaseManager) Initialize(dbConfig config.DatabaseConfig) error {
 
 	dm.db = db
 	log.Println("数据库连接成功")
-
-	// 自动迁移表结构
-	if err := dm.AutoMigrate(); err != nil {
-		return fmt.Errorf("自动迁移失败: %w", err)
-	}
-
 	return nil
 }
 
-// AutoMigrate 自动迁移数据库表结构
-func (dm *DatabaseManager) AutoMigrate() error {
-	err := dm.db.AutoMigrate(
-		&model.User{},
-		&model.Employee{},
-		&model.Merchant{},
-		&model.Rider{},
-	)
-
+// Migrator 基于当前连接创建迁移执行器
+func (dm *DatabaseManager) Migrator() (*Migrator, error) {
+	sqlDB, err := dm.db.DB()
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	log.Println("数据库自动迁移完成")
-	return nil
+	return NewMigrator(sqlDB)
 }
 
 // GetDB 获取数据库连接
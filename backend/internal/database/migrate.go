@@ -0,0 +1,351 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// #region 迁移文件
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// MigrationsDir 迁移文件在源码树中的目录（migrate create 默认写入此处）
+const MigrationsDir = "internal/database/migrations"
+
+// migrationLockKey 迁移使用的 Postgres 会话级 advisory lock 键，多实例同时执行迁移时串行化
+const migrationLockKey int64 = 0x7468655f70617373 // "the_pass"
+
+// 迁移文件命名：{6 位版本号}_{名称}.{up|down}.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d{6})_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+var migrationNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+var (
+	ErrMigrationInvalid      = errors.New("迁移文件不合法")
+	ErrMigrationNameInvalid  = errors.New("迁移名称只能包含小写字母、数字和下划线")
+	ErrMigrationUnknownApply = errors.New("数据库中存在未知的迁移版本")
+)
+
+// Migration 一个版本的迁移，Up/Down 为完整 SQL
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus 迁移执行状态
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// LoadMigrations 从文件系统读取迁移，按版本号升序返回
+// 每个版本必须同时具备 up 与 down 文件，版本号不允许重复
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMigrationInvalid, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		m := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("%w: 文件名 %s 不符合 {版本}_{名称}.{up|down}.sql", ErrMigrationInvalid, entry.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		raw, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMigrationInvalid, err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("%w: 版本 %06d 存在不同名称 %s / %s", ErrMigrationInvalid, version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(raw)
+		} else {
+			mig.Down = string(raw)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if strings.TrimSpace(mig.Up) == "" || strings.TrimSpace(mig.Down) == "" {
+			return nil, fmt.Errorf("%w: 版本 %06d 缺少 up 或 down 文件", ErrMigrationInvalid, mig.Version)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// CreateMigration 在 dir 下生成下一个版本号的空白 up/down 文件，返回两个文件路径
+func CreateMigration(dir, name string) (string, string, error) {
+	if !migrationNamePattern.MatchString(name) {
+		return "", "", ErrMigrationNameInvalid
+	}
+	existing, err := LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	next := int64(1)
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%06d_%s", next, name)
+	upPath := filepath.Join(dir, base+".up.sql")
+	downPath := filepath.Join(dir, base+".down.sql")
+	if err := os.WriteFile(upPath, []byte("-- "+name+"\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte("-- 回滚 "+name+"\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
+
+// #endregion
+
+// #region 迁移执行器
+
+// Migrator 版本化迁移执行器
+//
+// 已执行的版本记录在 schema_migrations 表中；每个版本在独立事务中执行并登记，
+// 失败时整体回滚（Postgres DDL 支持事务），不会留下“半执行”状态。
+// Up/Down 全程持有 advisory lock，多个实例同时启动时只有一个在执行迁移。
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator 使用内嵌迁移文件创建执行器
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	sub, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := LoadMigrations(sub)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up 执行全部未执行的迁移，返回本次执行的版本
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig, true); err != nil {
+				return err
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 按版本倒序回滚最近执行的 n 个迁移，返回本次回滚的版本
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int64, 0, len(done))
+		for v := range done {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+		if len(versions) > n {
+			versions = versions[:n]
+		}
+
+		for _, v := range versions {
+			mig, ok := m.find(v)
+			if !ok {
+				return fmt.Errorf("%w: %06d", ErrMigrationUnknownApply, v)
+			}
+			if err := m.apply(ctx, conn, mig, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status 返回全部迁移及其执行时间（未执行为 nil）
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	done, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if at, ok := done[mig.Version]; ok {
+			at := at
+			st.AppliedAt = &at
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+// Pending 返回尚未执行的迁移数量
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, st := range statuses {
+		if st.AppliedAt == nil {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
+
+// withLock 在同一连接上获取 advisory lock 后执行 fn；会话级锁必须在同一连接上释放
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("获取迁移锁失败: %w", err)
+	}
+	defer func() {
+		// 使用独立 ctx，避免调用方 ctx 已取消导致锁无法释放
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			log.Printf("释放迁移锁失败: %v", err)
+		}
+	}()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// apply 在事务中执行一个版本的 up 或 down，并同步更新 schema_migrations
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	script, direction := mig.Down, "down"
+	if up {
+		script, direction = mig.Up, "up"
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("迁移 %06d_%s %s 失败: %w", mig.Version, mig.Name, direction, err)
+	}
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", mig.Version, mig.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", mig.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("登记迁移 %06d 失败: %w", mig.Version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("迁移 %06d_%s %s 完成", mig.Version, mig.Name, direction)
+	return nil
+}
+
+func ensureMigrationsTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    bigint PRIMARY KEY,
+    name       varchar(255) NOT NULL,
+    applied_at timestamptz NOT NULL DEFAULT now()
+)`)
+	if err != nil {
+		return fmt.Errorf("创建 schema_migrations 失败: %w", err)
+	}
+	return nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		done[version] = at
+	}
+	return done, rows.Err()
+}
+
+// #endregion
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	sub, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		t.Fatalf("fs.Sub: %v", err)
+	}
+	migrations, err := LoadMigrations(sub)
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 || migrations[0].Name != "baseline" {
+		t.Fatalf("first migration should be 000001_baseline, got %+v", migrations)
+	}
+	// 版本号连续，便于审阅时发现并行分支引入的冲突版本
+	for i, mig := range migrations {
+		if mig.Version != int64(i+1) {
+			t.Fatalf("migration versions not contiguous at %06d_%s", mig.Version, mig.Name)
+		}
+	}
+	for _, table := range []string{"users", "employees", "merchants", "riders"} {
+		if !strings.Contains(migrations[0].Up, "CREATE TABLE IF NOT EXISTS "+table+" (") {
+			t.Fatalf("baseline does not create %s", table)
+		}
+	}
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"missing down": {
+			"000001_init.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"bad name": {
+			"1_init.up.sql":   {Data: []byte("SELECT 1;")},
+			"1_init.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"name mismatch": {
+			"000001_init.up.sql":    {Data: []byte("SELECT 1;")},
+			"000001_other.down.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := LoadMigrations(fsys); !errors.Is(err, ErrMigrationInvalid) {
+			t.Errorf("%s: err=%v want ErrMigrationInvalid", name, err)
+		}
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"000001_init.up.sql", "000001_init.down.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := CreateMigration(dir, "Add-Column"); !errors.Is(err, ErrMigrationNameInvalid) {
+		t.Fatalf("invalid name err=%v", err)
+	}
+	up, down, err := CreateMigration(dir, "add_audit_log")
+	if err != nil {
+		t.Fatalf("CreateMigration: %v", err)
+	}
+	if filepath.Base(up) != "000002_add_audit_log.up.sql" || filepath.Base(down) != "000002_add_audit_log.down.sql" {
+		t.Fatalf("unexpected files %s %s", up, down)
+	}
+	if _, err := LoadMigrations(os.DirFS(dir)); err != nil {
+		t.Fatalf("created files should load: %v", err)
+	}
+}
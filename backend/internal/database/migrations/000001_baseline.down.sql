@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS riders;
+DROP TABLE IF EXISTS employees;
+DROP TABLE IF EXISTS merchants;
+DROP TABLE IF EXISTS users;
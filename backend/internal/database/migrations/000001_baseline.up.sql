@@ -0,0 +1,131 @@
+-- 基线：用户、商家、员工、配送员（与原 AutoMigrate 生成的表结构一致）
+-- 全部使用 IF NOT EXISTS，已由 AutoMigrate 建表的库可直接执行以纳入版本管理
+
+CREATE TABLE IF NOT EXISTS users (
+    id            bigserial PRIMARY KEY,
+    username      varchar(50)  NOT NULL,
+    password_hash varchar(255) NOT NULL,
+    email         varchar(100) NOT NULL,
+    phone         varchar(11)  NOT NULL,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    avatar_url    varchar(255),
+    is_active     boolean DEFAULT true,
+    CONSTRAINT uni_users_username UNIQUE (username),
+    CONSTRAINT uni_users_email UNIQUE (email),
+    CONSTRAINT uni_users_phone UNIQUE (phone)
+);
+COMMENT ON COLUMN users.id IS '用户ID';
+COMMENT ON COLUMN users.username IS '用户名';
+COMMENT ON COLUMN users.password_hash IS '用户密码';
+COMMENT ON COLUMN users.email IS '用户邮箱';
+COMMENT ON COLUMN users.phone IS '用户手机号';
+COMMENT ON COLUMN users.created_at IS '创建时间';
+COMMENT ON COLUMN users.updated_at IS '更新时间';
+COMMENT ON COLUMN users.avatar_url IS '用户头像URL';
+COMMENT ON COLUMN users.is_active IS '用户是否激活';
+
+CREATE TABLE IF NOT EXISTS merchants (
+    id               bigserial PRIMARY KEY,
+    username         varchar(50)  NOT NULL,
+    password_hash    varchar(255) NOT NULL,
+    email            varchar(100) NOT NULL,
+    phone            varchar(20)  NOT NULL,
+    company_name     varchar(100),
+    business_license varchar(100),
+    is_active        boolean DEFAULT true,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    deleted_at       timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_username ON merchants (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_email ON merchants (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_phone ON merchants (phone);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_business_license ON merchants (business_license);
+CREATE INDEX IF NOT EXISTS idx_merchants_deleted_at ON merchants (deleted_at);
+COMMENT ON COLUMN merchants.id IS '商家ID';
+COMMENT ON COLUMN merchants.username IS '用户名';
+COMMENT ON COLUMN merchants.password_hash IS '密码哈希';
+COMMENT ON COLUMN merchants.email IS '邮箱';
+COMMENT ON COLUMN merchants.phone IS '手机号';
+COMMENT ON COLUMN merchants.company_name IS '公司名称';
+COMMENT ON COLUMN merchants.business_license IS '营业执照号';
+COMMENT ON COLUMN merchants.is_active IS '是否激活';
+
+CREATE TABLE IF NOT EXISTS employees (
+    id            bigserial PRIMARY KEY,
+    username      varchar(50)  NOT NULL,
+    password_hash varchar(255) NOT NULL,
+    email         varchar(100) NOT NULL,
+    phone         varchar(20)  NOT NULL,
+    name          varchar(50),
+    id_number     varchar(20),
+    sex           varchar(10),
+    merchant_id   bigint NOT NULL,
+    is_active     boolean DEFAULT true,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz,
+    CONSTRAINT fk_merchants_employees FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employees_username ON employees (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employees_email ON employees (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employees_phone ON employees (phone);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employees_id_number ON employees (id_number);
+CREATE INDEX IF NOT EXISTS idx_employees_merchant_id ON employees (merchant_id);
+CREATE INDEX IF NOT EXISTS idx_employees_deleted_at ON employees (deleted_at);
+COMMENT ON COLUMN employees.id IS '员工ID';
+COMMENT ON COLUMN employees.username IS '用户名';
+COMMENT ON COLUMN employees.password_hash IS '密码哈希';
+COMMENT ON COLUMN employees.email IS '邮箱';
+COMMENT ON COLUMN employees.phone IS '手机号';
+COMMENT ON COLUMN employees.name IS '员工姓名';
+COMMENT ON COLUMN employees.id_number IS '身份证号';
+COMMENT ON COLUMN employees.sex IS '性别';
+COMMENT ON COLUMN employees.merchant_id IS '所属商家ID';
+COMMENT ON COLUMN employees.is_active IS '是否激活';
+
+CREATE TABLE IF NOT EXISTS riders (
+    id             bigserial PRIMARY KEY,
+    username       varchar(50)  NOT NULL,
+    password_hash  varchar(255) NOT NULL,
+    email          varchar(100) NOT NULL,
+    phone          varchar(20)  NOT NULL,
+    name           varchar(50),
+    id_number      varchar(20),
+    license_number varchar(50),
+    vehicle_type   varchar(20),
+    vehicle_number varchar(20),
+    current_lat    decimal,
+    current_lng    decimal,
+    is_online      boolean DEFAULT false,
+    is_active      boolean DEFAULT true,
+    rating         decimal DEFAULT 5.0,
+    total_orders   bigint DEFAULT 0,
+    rating_count   bigint DEFAULT 0,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    deleted_at     timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_riders_username ON riders (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_riders_email ON riders (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_riders_phone ON riders (phone);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_riders_id_number ON riders (id_number);
+CREATE INDEX IF NOT EXISTS idx_riders_deleted_at ON riders (deleted_at);
+COMMENT ON COLUMN riders.id IS '配送员ID';
+COMMENT ON COLUMN riders.username IS '用户名';
+COMMENT ON COLUMN riders.password_hash IS '密码哈希';
+COMMENT ON COLUMN riders.email IS '邮箱';
+COMMENT ON COLUMN riders.phone IS '手机号';
+COMMENT ON COLUMN riders.name IS '真实姓名';
+COMMENT ON COLUMN riders.id_number IS '身份证号';
+COMMENT ON COLUMN riders.license_number IS '驾照号';
+COMMENT ON COLUMN riders.vehicle_type IS '交通工具类型';
+COMMENT ON COLUMN riders.vehicle_number IS '车牌号';
+COMMENT ON COLUMN riders.current_lat IS '当前纬度';
+COMMENT ON COLUMN riders.current_lng IS '当前经度';
+COMMENT ON COLUMN riders.is_online IS '是否在线';
+COMMENT ON COLUMN riders.is_active IS '是否激活';
+COMMENT ON COLUMN riders.rating IS '评分';
+COMMENT ON COLUMN riders.total_orders IS '总订单数';
+COMMENT ON COLUMN riders.rating_count IS '评分次数';
@@ -0,0 +1,83 @@
+-- 订单与订单明细
+
+CREATE TABLE IF NOT EXISTS orders (
+    id               bigserial PRIMARY KEY,
+    order_no         varchar(32) NOT NULL,
+    user_id          bigint NOT NULL,
+    merchant_id      bigint NOT NULL,
+    rider_id         bigint,
+    status           varchar(20) NOT NULL,
+    pickup_address   varchar(255) NOT NULL,
+    pickup_lat       decimal,
+    pickup_lng       decimal,
+    delivery_address varchar(255) NOT NULL,
+    delivery_lat     decimal,
+    delivery_lng     decimal,
+    contact_name     varchar(50),
+    contact_phone    varchar(20),
+    items_amount     bigint NOT NULL DEFAULT 0,
+    delivery_fee     bigint NOT NULL DEFAULT 0,
+    total_amount     bigint NOT NULL DEFAULT 0,
+    remark           varchar(255),
+    cancel_reason    varchar(255),
+    cancelled_by     varchar(20),
+    rider_rating     decimal DEFAULT 0,
+    accepted_at      timestamptz,
+    prepared_at      timestamptz,
+    picked_up_at     timestamptz,
+    delivered_at     timestamptz,
+    cancelled_at     timestamptz,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    deleted_at       timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_order_no ON orders (order_no);
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders (user_id);
+CREATE INDEX IF NOT EXISTS idx_orders_merchant_id ON orders (merchant_id);
+CREATE INDEX IF NOT EXISTS idx_orders_rider_id ON orders (rider_id);
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders (status);
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+COMMENT ON COLUMN orders.id IS '订单ID';
+COMMENT ON COLUMN orders.order_no IS '订单号';
+COMMENT ON COLUMN orders.user_id IS '下单用户ID';
+COMMENT ON COLUMN orders.merchant_id IS '商家ID';
+COMMENT ON COLUMN orders.rider_id IS '配送员ID';
+COMMENT ON COLUMN orders.status IS '订单状态';
+COMMENT ON COLUMN orders.pickup_address IS '取货地址';
+COMMENT ON COLUMN orders.pickup_lat IS '取货纬度';
+COMMENT ON COLUMN orders.pickup_lng IS '取货经度';
+COMMENT ON COLUMN orders.delivery_address IS '收货地址';
+COMMENT ON COLUMN orders.delivery_lat IS '收货纬度';
+COMMENT ON COLUMN orders.delivery_lng IS '收货经度';
+COMMENT ON COLUMN orders.contact_name IS '收货人';
+COMMENT ON COLUMN orders.contact_phone IS '收货人电话';
+COMMENT ON COLUMN orders.items_amount IS '商品金额（分）';
+COMMENT ON COLUMN orders.delivery_fee IS '配送费（分）';
+COMMENT ON COLUMN orders.total_amount IS '订单总额（分）';
+COMMENT ON COLUMN orders.remark IS '备注';
+COMMENT ON COLUMN orders.cancel_reason IS '取消原因';
+COMMENT ON COLUMN orders.cancelled_by IS '取消方';
+COMMENT ON COLUMN orders.rider_rating IS '用户对配送员的评分，0表示未评价';
+COMMENT ON COLUMN orders.accepted_at IS '商家接单时间';
+COMMENT ON COLUMN orders.prepared_at IS '出餐时间';
+COMMENT ON COLUMN orders.picked_up_at IS '取货时间';
+COMMENT ON COLUMN orders.delivered_at IS '送达时间';
+COMMENT ON COLUMN orders.cancelled_at IS '取消时间';
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id         bigserial PRIMARY KEY,
+    order_id   bigint NOT NULL,
+    name       varchar(100) NOT NULL,
+    unit_price bigint NOT NULL,
+    quantity   bigint NOT NULL,
+    amount     bigint NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_orders_items FOREIGN KEY (order_id) REFERENCES orders (id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items (order_id);
+COMMENT ON COLUMN order_items.id IS '明细ID';
+COMMENT ON COLUMN order_items.order_id IS '订单ID';
+COMMENT ON COLUMN order_items.name IS '商品名称';
+COMMENT ON COLUMN order_items.unit_price IS '单价（分）';
+COMMENT ON COLUMN order_items.quantity IS '数量';
+COMMENT ON COLUMN order_items.amount IS '小计（分）';
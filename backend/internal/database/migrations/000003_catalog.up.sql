@@ -0,0 +1,94 @@
+-- 商家菜单：分类、商品、图片、规格组与规格选项
+
+CREATE TABLE IF NOT EXISTS menu_categories (
+    id          bigserial PRIMARY KEY,
+    merchant_id bigint NOT NULL,
+    name        varchar(50) NOT NULL,
+    sort_order  bigint DEFAULT 0,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_menu_categories_merchant_id ON menu_categories (merchant_id);
+CREATE INDEX IF NOT EXISTS idx_menu_categories_deleted_at ON menu_categories (deleted_at);
+COMMENT ON COLUMN menu_categories.id IS '分类ID';
+COMMENT ON COLUMN menu_categories.merchant_id IS '商家ID';
+COMMENT ON COLUMN menu_categories.name IS '分类名称';
+COMMENT ON COLUMN menu_categories.sort_order IS '排序（升序）';
+
+CREATE TABLE IF NOT EXISTS menu_items (
+    id           bigserial PRIMARY KEY,
+    merchant_id  bigint NOT NULL,
+    category_id  bigint NOT NULL,
+    name         varchar(100) NOT NULL,
+    description  varchar(500),
+    price        bigint NOT NULL,
+    stock        bigint,
+    is_available boolean NOT NULL,
+    sort_order   bigint DEFAULT 0,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    CONSTRAINT fk_menu_categories_items FOREIGN KEY (category_id) REFERENCES menu_categories (id)
+);
+CREATE INDEX IF NOT EXISTS idx_menu_items_merchant_id ON menu_items (merchant_id);
+CREATE INDEX IF NOT EXISTS idx_menu_items_category_id ON menu_items (category_id);
+CREATE INDEX IF NOT EXISTS idx_menu_items_deleted_at ON menu_items (deleted_at);
+COMMENT ON COLUMN menu_items.id IS '商品ID';
+COMMENT ON COLUMN menu_items.merchant_id IS '商家ID';
+COMMENT ON COLUMN menu_items.category_id IS '分类ID';
+COMMENT ON COLUMN menu_items.name IS '商品名称';
+COMMENT ON COLUMN menu_items.description IS '商品描述';
+COMMENT ON COLUMN menu_items.price IS '价格（分）';
+COMMENT ON COLUMN menu_items.stock IS '库存，为空表示不限量';
+COMMENT ON COLUMN menu_items.is_available IS '是否可售（上下架）';
+COMMENT ON COLUMN menu_items.sort_order IS '排序（升序）';
+
+CREATE TABLE IF NOT EXISTS menu_item_images (
+    id         bigserial PRIMARY KEY,
+    item_id    bigint NOT NULL,
+    url        varchar(500) NOT NULL,
+    sort_order bigint DEFAULT 0,
+    CONSTRAINT fk_menu_items_images FOREIGN KEY (item_id) REFERENCES menu_items (id)
+);
+CREATE INDEX IF NOT EXISTS idx_menu_item_images_item_id ON menu_item_images (item_id);
+COMMENT ON COLUMN menu_item_images.id IS '图片ID';
+COMMENT ON COLUMN menu_item_images.item_id IS '商品ID';
+COMMENT ON COLUMN menu_item_images.url IS '图片地址';
+COMMENT ON COLUMN menu_item_images.sort_order IS '排序（升序）';
+
+CREATE TABLE IF NOT EXISTS menu_option_groups (
+    id         bigserial PRIMARY KEY,
+    item_id    bigint NOT NULL,
+    name       varchar(50) NOT NULL,
+    required   boolean DEFAULT false,
+    min_select bigint DEFAULT 0,
+    max_select bigint DEFAULT 1,
+    sort_order bigint DEFAULT 0,
+    CONSTRAINT fk_menu_items_option_groups FOREIGN KEY (item_id) REFERENCES menu_items (id)
+);
+CREATE INDEX IF NOT EXISTS idx_menu_option_groups_item_id ON menu_option_groups (item_id);
+COMMENT ON COLUMN menu_option_groups.id IS '规格组ID';
+COMMENT ON COLUMN menu_option_groups.item_id IS '商品ID';
+COMMENT ON COLUMN menu_option_groups.name IS '规格组名称';
+COMMENT ON COLUMN menu_option_groups.required IS '是否必选';
+COMMENT ON COLUMN menu_option_groups.min_select IS '最少选择数';
+COMMENT ON COLUMN menu_option_groups.max_select IS '最多选择数';
+COMMENT ON COLUMN menu_option_groups.sort_order IS '排序（升序）';
+
+CREATE TABLE IF NOT EXISTS menu_options (
+    id           bigserial PRIMARY KEY,
+    group_id     bigint NOT NULL,
+    name         varchar(50) NOT NULL,
+    price_delta  bigint DEFAULT 0,
+    is_available boolean NOT NULL,
+    sort_order   bigint DEFAULT 0,
+    CONSTRAINT fk_menu_option_groups_options FOREIGN KEY (group_id) REFERENCES menu_option_groups (id)
+);
+CREATE INDEX IF NOT EXISTS idx_menu_options_group_id ON menu_options (group_id);
+COMMENT ON COLUMN menu_options.id IS '选项ID';
+COMMENT ON COLUMN menu_options.group_id IS '规格组ID';
+COMMENT ON COLUMN menu_options.name IS '选项名称';
+COMMENT ON COLUMN menu_options.price_delta IS '加价（分）';
+COMMENT ON COLUMN menu_options.is_available IS '是否可选';
+COMMENT ON COLUMN menu_options.sort_order IS '排序（升序）';
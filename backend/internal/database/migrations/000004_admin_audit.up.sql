@@ -0,0 +1,46 @@
+-- 后台管理员与操作审计日志
+
+CREATE TABLE IF NOT EXISTS admins (
+    id            bigserial PRIMARY KEY,
+    username      varchar(50)  NOT NULL,
+    password_hash varchar(255) NOT NULL,
+    name          varchar(50),
+    email         varchar(100),
+    is_active     boolean DEFAULT true,
+    last_login_at timestamptz,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    CONSTRAINT uni_admins_username UNIQUE (username)
+);
+COMMENT ON COLUMN admins.id IS '管理员ID';
+COMMENT ON COLUMN admins.username IS '用户名';
+COMMENT ON COLUMN admins.password_hash IS '密码哈希';
+COMMENT ON COLUMN admins.name IS '姓名';
+COMMENT ON COLUMN admins.email IS '邮箱';
+COMMENT ON COLUMN admins.is_active IS '是否激活';
+COMMENT ON COLUMN admins.last_login_at IS '最近登录时间';
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id          bigserial PRIMARY KEY,
+    actor_type  varchar(20)  NOT NULL,
+    actor_id    bigint       NOT NULL,
+    action      varchar(50)  NOT NULL,
+    target_type varchar(20)  NOT NULL,
+    target_id   bigint       NOT NULL,
+    reason      varchar(500) NOT NULL,
+    detail      text,
+    client_ip   varchar(64),
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor_type, actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target ON audit_logs (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+COMMENT ON COLUMN audit_logs.id IS '日志ID';
+COMMENT ON COLUMN audit_logs.actor_type IS '操作方类型';
+COMMENT ON COLUMN audit_logs.actor_id IS '操作方ID';
+COMMENT ON COLUMN audit_logs.action IS '操作';
+COMMENT ON COLUMN audit_logs.target_type IS '目标类型';
+COMMENT ON COLUMN audit_logs.target_id IS '目标ID';
+COMMENT ON COLUMN audit_logs.reason IS '操作原因';
+COMMENT ON COLUMN audit_logs.detail IS '附加信息(JSON)';
+COMMENT ON COLUMN audit_logs.client_ip IS '操作方IP';
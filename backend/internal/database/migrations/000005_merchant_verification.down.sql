@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS verification_documents;
+DROP INDEX IF EXISTS idx_merchants_verification_status;
+ALTER TABLE merchants DROP COLUMN IF EXISTS verified_at;
+ALTER TABLE merchants DROP COLUMN IF EXISTS verification_submitted_at;
+ALTER TABLE merchants DROP COLUMN IF EXISTS verification_reject_reason;
+ALTER TABLE merchants DROP COLUMN IF EXISTS verification_status;
@@ -0,0 +1,34 @@
+-- 商家资质审核状态与审核材料
+
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS verification_status varchar(20) NOT NULL DEFAULT 'draft';
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS verification_reject_reason varchar(500);
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS verification_submitted_at timestamptz;
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS verified_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_merchants_verification_status ON merchants (verification_status);
+COMMENT ON COLUMN merchants.verification_status IS '资质审核状态';
+COMMENT ON COLUMN merchants.verification_reject_reason IS '审核驳回原因';
+COMMENT ON COLUMN merchants.verification_submitted_at IS '最近提交审核时间';
+COMMENT ON COLUMN merchants.verified_at IS '审核通过时间';
+
+-- 存量商家与新注册商家一样以默认值 draft 开始，须上传材料提交审核，由管理员审核通过（写入审计日志）后才能继续经营
+
+CREATE TABLE IF NOT EXISTS verification_documents (
+    id           bigserial PRIMARY KEY,
+    owner_type   varchar(20)  NOT NULL,
+    owner_id     bigint       NOT NULL,
+    doc_type     varchar(30)  NOT NULL,
+    file_name    varchar(255),
+    content_type varchar(100),
+    size         bigint,
+    storage_key  varchar(255) NOT NULL,
+    created_at   timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_verification_documents_owner ON verification_documents (owner_type, owner_id);
+COMMENT ON COLUMN verification_documents.id IS '材料ID';
+COMMENT ON COLUMN verification_documents.owner_type IS '所属账号类型';
+COMMENT ON COLUMN verification_documents.owner_id IS '所属账号ID';
+COMMENT ON COLUMN verification_documents.doc_type IS '材料类型';
+COMMENT ON COLUMN verification_documents.file_name IS '原始文件名';
+COMMENT ON COLUMN verification_documents.content_type IS '文件类型';
+COMMENT ON COLUMN verification_documents.size IS '文件大小(字节)';
+COMMENT ON COLUMN verification_documents.storage_key IS '存储键';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_riders_id_number;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_riders_id_number ON riders (id_number);
+DROP INDEX IF EXISTS idx_riders_verification_status;
+ALTER TABLE riders DROP COLUMN IF EXISTS verified_at;
+ALTER TABLE riders DROP COLUMN IF EXISTS verification_submitted_at;
+ALTER TABLE riders DROP COLUMN IF EXISTS verification_reject_reason;
+ALTER TABLE riders DROP COLUMN IF EXISTS verification_status;
@@ -0,0 +1,17 @@
+-- 配送员资质审核状态；身份证号唯一索引改为仅约束已填写的号码
+
+ALTER TABLE riders ADD COLUMN IF NOT EXISTS verification_status varchar(20) NOT NULL DEFAULT 'draft';
+ALTER TABLE riders ADD COLUMN IF NOT EXISTS verification_reject_reason varchar(500);
+ALTER TABLE riders ADD COLUMN IF NOT EXISTS verification_submitted_at timestamptz;
+ALTER TABLE riders ADD COLUMN IF NOT EXISTS verified_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_riders_verification_status ON riders (verification_status);
+COMMENT ON COLUMN riders.verification_status IS '资质审核状态';
+COMMENT ON COLUMN riders.verification_reject_reason IS '审核驳回原因';
+COMMENT ON COLUMN riders.verification_submitted_at IS '最近提交审核时间';
+COMMENT ON COLUMN riders.verified_at IS '审核通过时间';
+
+-- 存量配送员与新注册配送员一样以默认值 draft 开始，须补全身份与车辆信息并提交审核，由管理员审核通过（写入审计日志）后才能上线
+
+-- 注册时未填写身份证号的配送员以空字符串入库，不应互相冲突
+DROP INDEX IF EXISTS idx_riders_id_number;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_riders_id_number ON riders (id_number) WHERE id_number <> '';
@@ -0,0 +1,6 @@
+-- 员工角色，权限由角色决定（见 model.RolePermissions）
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'cashier';
+COMMENT ON COLUMN employees.role IS '员工角色';
+
+-- 存量员工与新增员工一样取默认值 cashier（最小权限），需要更多权限时由店主在 /employees/staff 重新分配角色
@@ -0,0 +1,34 @@
+-- 短信发送日志：异步队列入队、服务商受理与送达回执
+
+CREATE TABLE IF NOT EXISTS sms_send_logs (
+    id            bigserial PRIMARY KEY,
+    message_id    varchar(64)  NOT NULL,
+    phone         varchar(20)  NOT NULL,
+    category      varchar(50)  NOT NULL,
+    template_code varchar(50),
+    status        varchar(20)  NOT NULL,
+    provider      varchar(50),
+    receipt_id    varchar(100),
+    attempts      bigint       NOT NULL DEFAULT 0,
+    error_code    varchar(100),
+    error_message varchar(500),
+    sent_at       timestamptz,
+    delivered_at  timestamptz,
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sms_send_logs_message_id ON sms_send_logs (message_id);
+CREATE INDEX IF NOT EXISTS idx_sms_send_logs_receipt ON sms_send_logs (provider, receipt_id);
+COMMENT ON COLUMN sms_send_logs.id IS '日志ID';
+COMMENT ON COLUMN sms_send_logs.message_id IS '消息ID';
+COMMENT ON COLUMN sms_send_logs.phone IS '脱敏手机号';
+COMMENT ON COLUMN sms_send_logs.category IS '业务分类';
+COMMENT ON COLUMN sms_send_logs.template_code IS '服务商模板编号';
+COMMENT ON COLUMN sms_send_logs.status IS '发送状态';
+COMMENT ON COLUMN sms_send_logs.provider IS '受理服务商';
+COMMENT ON COLUMN sms_send_logs.receipt_id IS '服务商流水号';
+COMMENT ON COLUMN sms_send_logs.attempts IS '发送次数';
+COMMENT ON COLUMN sms_send_logs.error_code IS '回执状态码';
+COMMENT ON COLUMN sms_send_logs.error_message IS '失败原因';
+COMMENT ON COLUMN sms_send_logs.sent_at IS '服务商受理时间';
+COMMENT ON COLUMN sms_send_logs.delivered_at IS '送达回执时间';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_order_items_menu_item_id;
+ALTER TABLE order_items DROP COLUMN IF EXISTS menu_item_id;
+ALTER TABLE merchants DROP COLUMN IF EXISTS store_lng;
+ALTER TABLE merchants DROP COLUMN IF EXISTS store_lat;
+ALTER TABLE merchants DROP COLUMN IF EXISTS store_address;
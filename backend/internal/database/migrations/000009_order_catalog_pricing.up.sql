@@ -0,0 +1,12 @@
+-- 下单按商品目录定价：门店地址作为取货地址快照，订单明细关联商品以便退回库存
+
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS store_address varchar(255);
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS store_lat decimal;
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS store_lng decimal;
+COMMENT ON COLUMN merchants.store_address IS '门店地址';
+COMMENT ON COLUMN merchants.store_lat IS '门店纬度';
+COMMENT ON COLUMN merchants.store_lng IS '门店经度';
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS menu_item_id bigint;
+CREATE INDEX IF NOT EXISTS idx_order_items_menu_item_id ON order_items (menu_item_id);
+COMMENT ON COLUMN order_items.menu_item_id IS '商品ID';
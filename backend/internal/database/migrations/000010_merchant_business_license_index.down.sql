@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_merchants_business_license;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_business_license ON merchants (business_license);
@@ -0,0 +1,4 @@
+-- 营业执照号在提交审核前填写，注册时以空字符串入库；唯一索引改为仅约束已填写的号码
+
+DROP INDEX IF EXISTS idx_merchants_business_license;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_merchants_business_license ON merchants (business_license) WHERE business_license <> '';
@@ -0,0 +1,18 @@
+-- 订单明细所选规格：规格组、选项名称与加价为下单时的快照，加价已计入 order_items.unit_price
+
+CREATE TABLE IF NOT EXISTS order_item_options (
+    id            bigserial PRIMARY KEY,
+    order_item_id bigint NOT NULL,
+    option_id     bigint NOT NULL,
+    group_name    varchar(50) NOT NULL,
+    name          varchar(50) NOT NULL,
+    price_delta   bigint NOT NULL DEFAULT 0,
+    CONSTRAINT fk_order_items_options FOREIGN KEY (order_item_id) REFERENCES order_items (id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_item_options_order_item_id ON order_item_options (order_item_id);
+COMMENT ON COLUMN order_item_options.id IS '记录ID';
+COMMENT ON COLUMN order_item_options.order_item_id IS '订单明细ID';
+COMMENT ON COLUMN order_item_options.option_id IS '规格选项ID';
+COMMENT ON COLUMN order_item_options.group_name IS '规格组名称';
+COMMENT ON COLUMN order_item_options.name IS '选项名称';
+COMMENT ON COLUMN order_item_options.price_delta IS '加价（分）';
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// #region 事务管理
+
+type txKey struct{}
+
+// TxManager 跨仓储事务管理接口
+//
+// 事务通过 context 传递：WithinTx 开启事务并把事务连接放入 ctx，
+// 仓储方法以 Conn(ctx, db) 解析连接，因此同一个 ctx 下的所有仓储调用自动共享事务，
+// 业务层无需感知具体仓储的事务实现。
+type TxManager interface {
+	// WithinTx 在事务中执行 fn；fn 返回错误或 panic 时回滚，否则提交
+	// ctx 中已存在事务时直接复用，不开启嵌套事务
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// GormTxManager 基于 GORM 的事务管理实现
+type GormTxManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager 创建事务管理器
+func NewTxManager(db *gorm.DB) *GormTxManager {
+	return &GormTxManager{db: db}
+}
+
+// WithinTx 在事务中执行 fn
+func (m *GormTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Conn 解析仓储应使用的连接：ctx 中有事务时返回事务连接，否则返回绑定 ctx 的 db
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
+
+// #endregion
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type ctxKey struct{}
+
+// newMockTxManager 基于 sqlmock 的事务管理器，测试结束时校验事务的开启、提交与回滚
+func newMockTxManager(t *testing.T) (*GormTxManager, *gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet sql expectations: %v", err)
+		}
+		_ = sqlDB.Close()
+	})
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return NewTxManager(db), db, mock
+}
+
+func TestGormTxManager_Commit(t *testing.T) {
+	m, db, mock := newMockTxManager(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE orders`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := m.WithinTx(context.Background(), func(ctx context.Context) error {
+		return Conn(ctx, db).Exec("UPDATE orders SET status = ?", "accepted").Error
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+}
+
+func TestGormTxManager_RollbackOnError(t *testing.T) {
+	m, db, mock := newMockTxManager(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE orders`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	errFailed := errors.New("rider update failed")
+	err := m.WithinTx(context.Background(), func(ctx context.Context) error {
+		if err := Conn(ctx, db).Exec("UPDATE orders SET status = ?", "delivered").Error; err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("err=%v want %v", err, errFailed)
+	}
+}
+
+func TestGormTxManager_RollbackOnPanic(t *testing.T) {
+	m, _, mock := newMockTxManager(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("recovered %v want the original panic", r)
+		}
+	}()
+	_ = m.WithinTx(context.Background(), func(ctx context.Context) error {
+		panic("boom")
+	})
+	t.Fatal("panic was swallowed")
+}
+
+func TestGormTxManager_ReusesTransactionInContext(t *testing.T) {
+	m, db, mock := newMockTxManager(t)
+	// 嵌套调用只开启并提交一次事务
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE orders`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE riders`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := m.WithinTx(context.Background(), func(outer context.Context) error {
+		if err := Conn(outer, db).Exec("UPDATE orders SET status = ?", "delivered").Error; err != nil {
+			return err
+		}
+		return m.WithinTx(outer, func(inner context.Context) error {
+			if Conn(inner, db) != Conn(outer, db) {
+				t.Error("nested WithinTx did not reuse the outer transaction")
+			}
+			return Conn(inner, db).Exec("UPDATE riders SET total_orders = total_orders + 1").Error
+		})
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+}
+
+func TestConn_FallsBackToDBWithContext(t *testing.T) {
+	_, db, mock := newMockTxManager(t)
+	// 没有事务时直接在连接池上执行，不开启事务
+	mock.ExpectExec(`UPDATE orders`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	conn := Conn(ctx, db)
+	if conn.Statement.Context != ctx {
+		t.Fatal("Conn did not bind the caller's context")
+	}
+	if err := conn.Exec("UPDATE orders SET status = ?", "accepted").Error; err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+}
@@ -0,0 +1,681 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/Hermitf/the-pass/pkg/sms"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAdminPageSize = 20
+	maxAdminPageSize     = 100
+	defaultAdminTopLimit = 10
+)
+
+// AdminHandlerDependencies contains all dependencies for AdminHandler
+type AdminHandlerDependencies struct {
+	AdminService    service.AdminServiceInterface
+	UserService     service.UserServiceInterface
+	MerchantService service.MerchantServiceInterface
+	RiderService    service.RiderServiceInterface
+	EmployeeService service.EmployeeServiceInterface
+
+	// SMSProvider exposes per-vendor counters when it is a failover provider; nil when SMS is disabled
+	SMSProvider sms.Provider
+}
+
+// AdminHandler serves the back-office API used by operators
+// Reads go through the existing account services; every mutation goes through AdminService so it is audited
+type AdminHandler struct {
+	deps *AdminHandlerDependencies
+}
+
+// NewAdminHandler creates a new AdminHandler instance with dependency injection
+func NewAdminHandler(deps AdminHandlerDependencies) *AdminHandler {
+	return &AdminHandler{deps: &deps}
+}
+
+// #region Admin Account
+
+// LoginHandler handles admin login
+// @Summary Admin login
+// @Description Log in to the back office with username and password
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body AdminLoginRequest true "Admin credentials"
+// @Success 200 {object} LoginResponse "Login succeeded"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Invalid credentials"
+// @Failure 403 {object} ErrorResponse "Account deactivated"
+// @Failure 429 {object} ErrorResponse "Too many failed attempts"
+// @Router /admin/login [post]
+func (h *AdminHandler) LoginHandler(c *gin.Context) {
+	var req AdminLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	tokens, err := h.deps.AdminService.LoginAdmin(c.Request.Context(), req.Username, req.Password, c.ClientIP())
+	if err != nil {
+		var locked *service.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			RespondWithError(c, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", err.Error(), nil)
+		case errors.Is(err, service.ErrInvalidCredentials), errors.Is(err, service.ErrLoginInfoEmpty):
+			Unauthorized(c, "用户名或密码错误")
+		case errors.Is(err, service.ErrAccountDeactivated):
+			Forbidden(c, err.Error())
+		default:
+			InternalServerError(c, ErrMsgInternalServer, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		Message:      "登录成功",
+	})
+}
+
+// GetProfileHandler returns the logged-in admin
+// @Summary Admin profile
+// @Description Get the profile of the logged-in admin
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.AdminResponse "Admin profile"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Admin not found"
+// @Router /admin/profile [get]
+func (h *AdminHandler) GetProfileHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	admin, err := h.deps.AdminService.GetAdminByID(c.Request.Context(), claims.UserID)
+	if err != nil {
+		if errors.Is(err, service.ErrAdminNotFound) {
+			NotFound(c, err.Error())
+			return
+		}
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, admin.ToResponse())
+}
+
+// #endregion
+
+// #region Account Management
+
+// ListAccountsHandler lists or searches accounts of the given type
+// @Summary List accounts
+// @Description List accounts of one type; a keyword switches to search. Employees are listed per merchant.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param keyword query string false "Search keyword"
+// @Param merchant_id query int false "Merchant ID (required for employees)"
+// @Param offset query int false "Offset" default(0)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} AccountListResponse "Accounts"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Router /admin/users [get]
+// @Router /admin/merchants [get]
+// @Router /admin/riders [get]
+// @Router /admin/employees [get]
+func (h *AdminHandler) ListAccountsHandler(accountType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		offset, limit, ok := parseAdminPagination(c)
+		if !ok {
+			return
+		}
+		keyword := c.Query("keyword")
+		ctx := c.Request.Context()
+
+		var (
+			accounts interface{}
+			total    int64
+			err      error
+		)
+		switch accountType {
+		case model.AccountTypeUser:
+			accounts, total, err = h.listUsers(ctx, keyword, offset, limit)
+		case model.AccountTypeMerchant:
+			accounts, total, err = h.listMerchants(ctx, keyword, offset, limit)
+		case model.AccountTypeRider:
+			accounts, total, err = h.listRiders(ctx, keyword, offset, limit)
+		case model.AccountTypeEmployee:
+			merchantID, ok := parseMerchantIDQuery(c)
+			if !ok {
+				return
+			}
+			accounts, total, err = h.listEmployees(ctx, keyword, merchantID, offset, limit)
+		default:
+			BadRequest(c, ErrMsgInvalidUserType, nil)
+			return
+		}
+		if err != nil {
+			h.handleAdminError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, AccountListResponse{Accounts: accounts, Total: total})
+	}
+}
+
+// SetAccountStatusHandler activates or deactivates an account and records the reason in the audit log
+// @Summary Activate or deactivate account
+// @Description Deactivating an account also revokes all of its sessions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Account ID"
+// @Param request body AccountStatusRequest true "Target status and reason"
+// @Success 200 {object} SuccessResponse "Status updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Account not found"
+// @Failure 503 {object} ErrorResponse "Account deactivated but sessions not revoked; retry"
+// @Router /admin/users/{id}/status [put]
+// @Router /admin/merchants/{id}/status [put]
+// @Router /admin/riders/{id}/status [put]
+// @Router /admin/employees/{id}/status [put]
+func (h *AdminHandler) SetAccountStatusHandler(accountType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := middleware.GetClaims(c)
+		if !ok {
+			Unauthorized(c, ErrMsgUnauthorized)
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id <= 0 {
+			BadRequest(c, ErrMsgInvalidRequest, "invalid account id")
+			return
+		}
+		var req AccountStatusRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+			return
+		}
+
+		actor := service.AdminActor{AdminID: claims.UserID, ClientIP: c.ClientIP()}
+		if err := h.deps.AdminService.SetAccountActive(c.Request.Context(), actor, accountType, id, *req.IsActive, req.Reason); err != nil {
+			h.handleAdminError(c, err)
+			return
+		}
+
+		message := "账号已启用"
+		if !*req.IsActive {
+			message = "账号已停用"
+		}
+		RespondWithSuccess(c, http.StatusOK, nil, message)
+	}
+}
+
+// #endregion
+
+// #region Statistics
+
+// StatsHandler returns the statistics map of an account type
+// @Summary Account statistics
+// @Description Aggregated statistics of users, merchants or riders; employee statistics are per merchant
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param merchant_id query int false "Merchant ID (required for employees)"
+// @Success 200 {object} map[string]interface{} "Statistics"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Router /admin/stats/users [get]
+// @Router /admin/stats/merchants [get]
+// @Router /admin/stats/riders [get]
+// @Router /admin/stats/employees [get]
+func (h *AdminHandler) StatsHandler(accountType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var (
+			stats map[string]interface{}
+			err   error
+		)
+		switch accountType {
+		case model.AccountTypeUser:
+			stats, err = h.deps.UserService.GetUserStats(ctx)
+		case model.AccountTypeMerchant:
+			stats, err = h.deps.MerchantService.GetMerchantStats(ctx)
+		case model.AccountTypeRider:
+			stats, err = h.deps.RiderService.GetRiderStats(ctx)
+		case model.AccountTypeEmployee:
+			merchantID, ok := parseMerchantIDQuery(c)
+			if !ok {
+				return
+			}
+			stats, err = h.deps.EmployeeService.GetEmployeeStatsByMerchant(ctx, merchantID)
+		default:
+			BadRequest(c, ErrMsgInvalidUserType, nil)
+			return
+		}
+		if err != nil {
+			h.handleAdminError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, stats)
+	}
+}
+
+// TopMerchantsHandler lists merchants with the most employees
+// @Summary Top merchants
+// @Description Merchants ranked by employee count
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of merchants" default(10)
+// @Success 200 {array} model.MerchantResponse "Merchants"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Router /admin/stats/merchants/top [get]
+func (h *AdminHandler) TopMerchantsHandler(c *gin.Context) {
+	limit, ok := parseTopLimit(c)
+	if !ok {
+		return
+	}
+
+	merchants, err := h.deps.MerchantService.GetTopMerchantsByEmployeeCount(c.Request.Context(), limit)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	response := make([]*model.MerchantResponse, len(merchants))
+	for i, merchant := range merchants {
+		response[i] = merchant.ToResponse()
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// TopRidersHandler lists riders with the highest rating
+// @Summary Top riders
+// @Description Riders ranked by rating
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of riders" default(10)
+// @Success 200 {array} model.RiderResponse "Riders"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Router /admin/stats/riders/top [get]
+func (h *AdminHandler) TopRidersHandler(c *gin.Context) {
+	limit, ok := parseTopLimit(c)
+	if !ok {
+		return
+	}
+
+	riders, err := h.deps.RiderService.GetTopRidersByRating(c.Request.Context(), limit)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	response := make([]*model.RiderResponse, len(riders))
+	for i, rider := range riders {
+		response[i] = rider.ToResponse()
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// NearbyRidersHandler lists online riders within a radius of a point, nearest first
+// @Summary Nearby riders
+// @Description Online, active riders inside the great-circle radius around a point, sorted by distance; uses live locations when the location store is enabled
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Param radius_km query number true "Search radius in kilometers"
+// @Param limit query int false "Number of riders" default(20)
+// @Success 200 {array} NearbyRiderResponse "Riders with distance"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Router /admin/riders/nearby [get]
+func (h *AdminHandler) NearbyRidersHandler(c *gin.Context) {
+	lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+	radiusKm, radiusErr := strconv.ParseFloat(c.Query("radius_km"), 64)
+	if latErr != nil || lngErr != nil || radiusErr != nil {
+		BadRequest(c, ErrMsgInvalidRequest, "lat, lng and radius_km are required numbers")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, "invalid limit")
+		return
+	}
+
+	nearby, err := h.deps.RiderService.GetRidersNearLocation(c.Request.Context(), lat, lng, radiusKm, limit)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	response := make([]*NearbyRiderResponse, len(nearby))
+	for i, n := range nearby {
+		response[i] = &NearbyRiderResponse{Rider: n.Rider.ToResponse(), DistanceKm: n.DistanceKm}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SMSProviderStatsHandler returns delivery counters and circuit breaker state of each SMS vendor
+// @Summary SMS provider statistics
+// @Description Success/failure counts, latency and breaker state per SMS vendor; empty when a single provider without failover is configured
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} sms.ProviderStats "Provider statistics"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Router /admin/stats/sms-providers [get]
+func (h *AdminHandler) SMSProviderStatsHandler(c *gin.Context) {
+	reporter, ok := h.deps.SMSProvider.(sms.StatsReporter)
+	if !ok {
+		c.JSON(http.StatusOK, []sms.ProviderStats{})
+		return
+	}
+	c.JSON(http.StatusOK, reporter.Stats())
+}
+
+// #endregion
+
+// #region SMS Phone Lists
+
+// ListSMSPhonesHandler lists the numbers on the SMS blocklist or allowlist
+// @Summary List SMS phone list
+// @Description Blocklisted numbers never receive verification codes; allowlisted numbers bypass all send limits
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param list path string true "Phone list" Enums(blocklist, allowlist)
+// @Success 200 {array} sms.PhoneListEntry "Numbers with the reason they were listed"
+// @Failure 400 {object} ErrorResponse "Unknown list"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 503 {object} ErrorResponse "SMS disabled"
+// @Router /admin/sms/phone-lists/{list} [get]
+func (h *AdminHandler) ListSMSPhonesHandler(c *gin.Context) {
+	entries, err := h.deps.AdminService.ListSMSPhones(c.Request.Context(), c.Param("list"))
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// SetSMSPhoneListedHandler adds a number to, or removes it from, the SMS blocklist or allowlist
+// @Summary Add or remove SMS phone list entry
+// @Description PUT adds the number (updating the reason if already listed), DELETE removes it; both are audited
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param list path string true "Phone list" Enums(blocklist, allowlist)
+// @Param phone path string true "Phone number"
+// @Param request body SMSPhoneListRequest true "Reason"
+// @Success 200 {object} SuccessResponse "List updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Number not listed"
+// @Failure 503 {object} ErrorResponse "SMS disabled"
+// @Router /admin/sms/phone-lists/{list}/{phone} [put]
+// @Router /admin/sms/phone-lists/{list}/{phone} [delete]
+func (h *AdminHandler) SetSMSPhoneListedHandler(listed bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := middleware.GetClaims(c)
+		if !ok {
+			Unauthorized(c, ErrMsgUnauthorized)
+			return
+		}
+		var req SMSPhoneListRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+			return
+		}
+
+		actor := service.AdminActor{AdminID: claims.UserID, ClientIP: c.ClientIP()}
+		if err := h.deps.AdminService.SetSMSPhoneListed(c.Request.Context(), actor, c.Param("list"), c.Param("phone"), listed, req.Reason); err != nil {
+			h.handleAdminError(c, err)
+			return
+		}
+
+		message := "号码已加入名单"
+		if !listed {
+			message = "号码已移出名单"
+		}
+		RespondWithSuccess(c, http.StatusOK, nil, message)
+	}
+}
+
+// #endregion
+
+// #region Audit Log
+
+// ListAuditLogsHandler lists audit log entries, newest first
+// @Summary List audit logs
+// @Description Filter audit logs by actor, action or target
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param actor_id query int false "Admin ID"
+// @Param action query string false "Action, e.g. account.deactivate"
+// @Param target_type query string false "Target type (user/merchant/rider/employee)"
+// @Param target_id query int false "Target ID"
+// @Param offset query int false "Offset" default(0)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} AuditLogListResponse "Audit logs"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Router /admin/audit-logs [get]
+func (h *AdminHandler) ListAuditLogsHandler(c *gin.Context) {
+	offset, limit, ok := parseAdminPagination(c)
+	if !ok {
+		return
+	}
+	filter := repository.AuditLogFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+	}
+	for param, dst := range map[string]*int64{"actor_id": &filter.ActorID, "target_id": &filter.TargetID} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			BadRequest(c, ErrMsgInvalidRequest, "invalid "+param)
+			return
+		}
+		*dst = id
+	}
+	if filter.ActorID > 0 {
+		filter.ActorType = model.AuditActorAdmin
+	}
+
+	logs, total, err := h.deps.AdminService.ListAuditLogs(c.Request.Context(), filter, offset, limit)
+	if err != nil {
+		h.handleAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AuditLogListResponse{Logs: logs, Total: total})
+}
+
+// #endregion
+
+// #region Helpers
+
+func (h *AdminHandler) listUsers(ctx context.Context, keyword string, offset, limit int) ([]*model.UserResponse, int64, error) {
+	users, total, err := h.deps.UserService.SearchUsers(ctx, keyword, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	response := make([]*model.UserResponse, len(users))
+	for i, user := range users {
+		response[i] = user.ToResponse()
+	}
+	return response, total, nil
+}
+
+func (h *AdminHandler) listMerchants(ctx context.Context, keyword string, offset, limit int) ([]*model.MerchantResponse, int64, error) {
+	var (
+		merchants []*model.Merchant
+		total     int64
+		err       error
+	)
+	if keyword != "" {
+		merchants, total, err = h.deps.MerchantService.SearchMerchants(ctx, keyword, offset, limit)
+	} else {
+		merchants, total, err = h.deps.MerchantService.GetMerchantList(ctx, offset, limit)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	response := make([]*model.MerchantResponse, len(merchants))
+	for i, merchant := range merchants {
+		response[i] = merchant.ToResponse()
+	}
+	return response, total, nil
+}
+
+func (h *AdminHandler) listRiders(ctx context.Context, keyword string, offset, limit int) ([]*model.RiderResponse, int64, error) {
+	var (
+		riders []*model.Rider
+		total  int64
+		err    error
+	)
+	if keyword != "" {
+		riders, total, err = h.deps.RiderService.SearchRiders(ctx, keyword, offset, limit)
+	} else {
+		riders, total, err = h.deps.RiderService.GetRiderList(ctx, offset, limit)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	response := make([]*model.RiderResponse, len(riders))
+	for i, rider := range riders {
+		response[i] = rider.ToResponse()
+	}
+	return response, total, nil
+}
+
+func (h *AdminHandler) listEmployees(ctx context.Context, keyword string, merchantID int64, offset, limit int) ([]*model.EmployeeResponse, int64, error) {
+	var (
+		employees []*model.Employee
+		total     int64
+		err       error
+	)
+	if keyword != "" {
+		employees, total, err = h.deps.EmployeeService.SearchEmployees(ctx, keyword, merchantID, offset, limit)
+	} else {
+		employees, total, err = h.deps.EmployeeService.GetEmployeeList(ctx, merchantID, offset, limit)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	response := make([]*model.EmployeeResponse, len(employees))
+	for i, employee := range employees {
+		response[i] = employee.ToResponse()
+	}
+	return response, total, nil
+}
+
+// handleAdminError maps admin and account service errors to HTTP responses
+func (h *AdminHandler) handleAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound), errors.Is(err, service.ErrAdminNotFound),
+		errors.Is(err, service.ErrSMSPhoneNotListed):
+		NotFound(c, err.Error())
+	case errors.Is(err, service.ErrSMSPhoneListUnavailable), errors.Is(err, service.ErrAccountSessionsNotRevoked):
+		RespondWithError(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", err.Error(), nil)
+	case errors.Is(err, service.ErrInvalidAccountType),
+		errors.Is(err, service.ErrInvalidAccountID),
+		errors.Is(err, service.ErrAuditReasonRequired),
+		errors.Is(err, service.ErrAuditReasonTooLong),
+		errors.Is(err, service.ErrPaginationInvalid),
+		errors.Is(err, service.ErrSearchKeywordShort),
+		errors.Is(err, service.ErrInvalidMerchantID),
+		errors.Is(err, service.ErrLimitInvalid),
+		errors.Is(err, service.ErrInvalidLocation),
+		errors.Is(err, service.ErrRadiusInvalid),
+		errors.Is(err, service.ErrPhoneInvalid),
+		errors.Is(err, sms.ErrPhoneListInvalid):
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+	default:
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+	}
+}
+
+// parseAdminPagination parses offset/limit query parameters, capping the page size
+func parseAdminPagination(c *gin.Context) (int, int, bool) {
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		BadRequest(c, ErrMsgInvalidRequest, "invalid offset")
+		return 0, 0, false
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAdminPageSize)))
+	if err != nil || limit <= 0 {
+		BadRequest(c, ErrMsgInvalidRequest, "invalid limit")
+		return 0, 0, false
+	}
+	if limit > maxAdminPageSize {
+		limit = maxAdminPageSize
+	}
+	return offset, limit, true
+}
+
+// parseTopLimit parses the limit of a ranking query
+func parseTopLimit(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAdminTopLimit)))
+	if err != nil || limit <= 0 {
+		BadRequest(c, ErrMsgInvalidRequest, "invalid limit")
+		return 0, false
+	}
+	if limit > maxAdminPageSize {
+		limit = maxAdminPageSize
+	}
+	return limit, true
+}
+
+// parseMerchantIDQuery parses the required merchant_id query parameter
+func parseMerchantIDQuery(c *gin.Context) (int64, bool) {
+	merchantID, err := strconv.ParseInt(c.Query("merchant_id"), 10, 64)
+	if err != nil || merchantID <= 0 {
+		BadRequest(c, ErrMsgInvalidRequest, "merchant_id is required")
+		return 0, false
+	}
+	return merchantID, true
+}
+
+// #endregion
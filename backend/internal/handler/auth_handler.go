@@ -5,8 +5,10 @@ import (
 	"errors"
 	"math"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/Hermitf/the-pass/internal/middleware"
 	"github.com/Hermitf/the-pass/internal/model"
 	"github.com/Hermitf/the-pass/internal/service"
 	"github.com/Hermitf/the-pass/pkg/crypto"
@@ -98,7 +100,7 @@ func (h *AuthHandler) registerUserByType(ctx context.Context, userType string, r
 			Email:        registerReq.Email,
 			Phone:        registerReq.Phone,
 		}
-		return h.deps.EmployeeService.RegisterEmployee(employee)
+		return h.deps.EmployeeService.RegisterEmployee(ctx, employee)
 
 	case "merchant":
 		merchant := &model.Merchant{
@@ -106,8 +108,9 @@ func (h *AuthHandler) registerUserByType(ctx context.Context, userType string, r
 			PasswordHash: passwordHash,
 			Email:        registerReq.Email,
 			Phone:        registerReq.Phone,
+			CompanyName:  registerReq.CompanyName,
 		}
-		return h.deps.MerchantService.RegisterMerchant(merchant)
+		return h.deps.MerchantService.RegisterMerchant(ctx, merchant)
 
 	case "rider":
 		rider := &model.Rider{
@@ -116,7 +119,7 @@ func (h *AuthHandler) registerUserByType(ctx context.Context, userType string, r
 			Email:        registerReq.Email,
 			Phone:        registerReq.Phone,
 		}
-		return h.deps.RiderService.RegisterRider(rider)
+		return h.deps.RiderService.RegisterRider(ctx, rider)
 
 	default:
 		return errors.New(ErrMsgInvalidUserType)
@@ -186,23 +189,31 @@ func (h *AuthHandler) validateLoginRequest(c *gin.Context) (*LoginRequest, error
 }
 
 // authenticateUserByType handles authentication for different user types
-func (h *AuthHandler) authenticateUserByType(userType string, loginReq *LoginRequest) (string, error) {
+// clientIP feeds the per-account and per-IP login failure limits
+func (h *AuthHandler) authenticateUserByType(ctx context.Context, userType, clientIP string, loginReq *LoginRequest) (*service.TokenPair, error) {
 	switch userType {
 	case "user":
-		return h.deps.UserService.LoginUser(loginReq.LoginInfo, loginReq.Password, loginReq.LoginType)
+		return h.deps.UserService.LoginUser(ctx, loginReq.LoginInfo, loginReq.Password, loginReq.LoginType, clientIP)
 	case "employee":
-		return h.deps.EmployeeService.LoginEmployee(loginReq.LoginInfo, loginReq.Password, loginReq.LoginType)
+		return h.deps.EmployeeService.LoginEmployee(ctx, loginReq.LoginInfo, loginReq.Password, loginReq.LoginType, clientIP)
 	case "merchant":
-		return h.deps.MerchantService.LoginMerchant(loginReq.LoginInfo, loginReq.Password, loginReq.LoginType)
+		return h.deps.MerchantService.LoginMerchant(ctx, loginReq.LoginInfo, loginReq.Password, loginReq.LoginType, clientIP)
 	case "rider":
-		return h.deps.RiderService.LoginRider(loginReq.LoginInfo, loginReq.Password, loginReq.LoginType)
+		return h.deps.RiderService.LoginRider(ctx, loginReq.LoginInfo, loginReq.Password, loginReq.LoginType, clientIP)
 	default:
-		return "", errors.New(ErrMsgInvalidUserType)
+		return nil, errors.New(ErrMsgInvalidUserType)
 	}
 }
 
 // handleLoginError handles login errors and returns appropriate responses
+// Locked-out logins get 429 with a Retry-After header in whole seconds
 func (h *AuthHandler) handleLoginError(c *gin.Context, err error) {
+	var locked *service.LoginLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		RespondWithError(c, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", err.Error(), nil)
+		return
+	}
 	if errors.Is(err, service.ErrInvalidCredentials) ||
 		errors.Is(err, service.ErrInvalidPassword) ||
 		errors.Is(err, service.ErrSMSCodeInvalid) ||
@@ -224,10 +235,10 @@ func (h *AuthHandler) handleLoginError(c *gin.Context, err error) {
 // @Success 200 {object} LoginResponse "login successful"
 // @Failure 400 {object} ErrorResponse "invalid request"
 // @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 429 {object} ErrorResponse "too many failed attempts, see Retry-After"
 // @Failure 500 {object} ErrorResponse "internal server error"
 // @Router /{userType}/login [post]
-// TODO: 支持扫码登录并通过移动端进行二次确认。
-// TODO: 引入登录失败次数限制、设备指纹识别等安全策略。
+// TODO: 引入设备指纹识别等安全策略。
 func (h *AuthHandler) LoginHandler(userType string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		loginReq, err := h.validateLoginRequest(c)
@@ -241,31 +252,50 @@ func (h *AuthHandler) LoginHandler(userType string) gin.HandlerFunc {
 			return
 		}
 
-		token, err := h.authenticateUserByType(userType, loginReq)
+		tokens, err := h.authenticateUserByType(c.Request.Context(), userType, c.ClientIP(), loginReq)
 		if err != nil {
 			h.handleLoginError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, LoginResponse{Token: token, Message: "登录成功"})
+		c.JSON(http.StatusOK, LoginResponse{
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			ExpiresIn:    tokens.ExpiresIn,
+			Message:      "登录成功",
+		})
 	}
 }
 
 // #region SMS Code Endpoints
 
+// HeaderDeviceID 客户端上报的设备标识，用于短信发送按设备限额
+const HeaderDeviceID = middleware.HeaderDeviceID
+
+// smsRequestContext 将客户端 IP 与设备标识写入请求上下文，供短信服务按 IP、设备维度限额
+func smsRequestContext(c *gin.Context) context.Context {
+	return sms.WithClientInfo(c.Request.Context(), sms.ClientInfo{
+		IP:       c.ClientIP(),
+		DeviceID: c.GetHeader(HeaderDeviceID),
+	})
+}
+
+// purpose: register / login / reset_password / change_phone，缺省为 login
 type sendSMSRequest struct {
-	Phone string `json:"phone"`
+	Phone   string `json:"phone"`
+	Purpose string `json:"purpose"`
 }
 
 type verifySMSRequest struct {
-	Phone string `json:"phone"`
-	Code  string `json:"code"`
+	Phone   string `json:"phone"`
+	Code    string `json:"code"`
+	Purpose string `json:"purpose"`
 }
 
 // smsServiceContract 定义短信验证码服务需要满足的行为
 type smsServiceContract interface {
-	SendSMSCode(ctx context.Context, phone string) error
-	VerifySMSCode(ctx context.Context, phone, code string) error
+	SendSMSCode(ctx context.Context, phone, purpose string) error
+	VerifySMSCode(ctx context.Context, phone, code, purpose string) error
 	CanSendSMSCode(ctx context.Context, phone string) (bool, time.Duration, error)
 }
 
@@ -276,7 +306,7 @@ func handleSendSMS(c *gin.Context, svc smsServiceContract) {
 		BadRequest(c, ErrMsgInvalidRequest, "手机号不能为空")
 		return
 	}
-	if err := svc.SendSMSCode(c.Request.Context(), req.Phone); err != nil {
+	if err := svc.SendSMSCode(smsRequestContext(c), req.Phone, req.Purpose); err != nil {
 		BadRequest(c, "发送验证码失败", err.Error())
 		return
 	}
@@ -290,7 +320,7 @@ func handleVerifySMS(c *gin.Context, svc smsServiceContract) {
 		BadRequest(c, ErrMsgInvalidRequest, "手机号或验证码不能为空")
 		return
 	}
-	if err := svc.VerifySMSCode(c.Request.Context(), req.Phone, req.Code); err != nil {
+	if err := svc.VerifySMSCode(c.Request.Context(), req.Phone, req.Code, req.Purpose); err != nil {
 		BadRequest(c, "验证码校验失败", err.Error())
 		return
 	}
@@ -302,6 +332,9 @@ type canSendResponse struct {
 	RetryAfterSeconds int    `json:"retry_after_seconds"`
 	Reason            string `json:"reason,omitempty"`
 	Message           string `json:"message,omitempty"`
+
+	// Dimension 拦截维度：blocklist / phone / daily / ip / device / prefix / global
+	Dimension string `json:"dimension,omitempty"`
 }
 
 func handleCanSendSMS(c *gin.Context, svc smsServiceContract) {
@@ -310,30 +343,41 @@ func handleCanSendSMS(c *gin.Context, svc smsServiceContract) {
 		BadRequest(c, ErrMsgInvalidRequest, "手机号不能为空")
 		return
 	}
-	allowed, retryAfter, err := svc.CanSendSMSCode(c.Request.Context(), req.Phone)
+	allowed, retryAfter, err := svc.CanSendSMSCode(smsRequestContext(c), req.Phone)
 	if err != nil {
+		var dimension string
+		var limitErr *sms.LimitError
+		if errors.As(err, &limitErr) {
+			dimension = limitErr.Dimension
+		}
 		switch {
 		case errors.Is(err, service.ErrPhoneInvalid):
 			BadRequest(c, ErrMsgInvalidRequest, err.Error())
 			return
+		case errors.Is(err, sms.ErrPhoneBlocked):
+			respondCanSend(c, allowed, retryAfter, "blocked", "该手机号暂不支持接收验证码", dimension)
+			return
 		case errors.Is(err, sms.ErrSendTooFrequent):
-			respondCanSend(c, allowed, retryAfter, "rate_limit", "发送过于频繁，请稍后再试")
+			respondCanSend(c, allowed, retryAfter, "rate_limit", "发送过于频繁，请稍后再试", dimension)
 			return
 		case errors.Is(err, sms.ErrDailyLimitReached):
-			respondCanSend(c, allowed, retryAfter, "daily_limit", "当天验证码发送次数已达上限")
+			respondCanSend(c, allowed, retryAfter, "daily_limit", "当天验证码发送次数已达上限", dimension)
+			return
+		case errors.Is(err, sms.ErrSendLimited):
+			respondCanSend(c, allowed, retryAfter, "quota_limit", "当前发送量过大，请稍后再试", dimension)
 			return
 		case errors.Is(err, sms.ErrProviderDisabled):
-			respondCanSend(c, allowed, retryAfter, "provider_disabled", "短信服务暂未启用")
+			respondCanSend(c, allowed, retryAfter, "provider_disabled", "短信服务暂未启用", "")
 			return
 		default:
 			InternalServerError(c, ErrMsgInternalServer, err.Error())
 			return
 		}
 	}
-	respondCanSend(c, true, 0, "", "可发送验证码")
+	respondCanSend(c, true, 0, "", "可发送验证码", "")
 }
 
-func respondCanSend(c *gin.Context, allowed bool, retryAfter time.Duration, reason, message string) {
+func respondCanSend(c *gin.Context, allowed bool, retryAfter time.Duration, reason, message, dimension string) {
 	retrySeconds := 0
 	if retryAfter > 0 {
 		retrySeconds = int(math.Ceil(retryAfter.Seconds()))
@@ -343,6 +387,7 @@ func respondCanSend(c *gin.Context, allowed bool, retryAfter time.Duration, reas
 		RetryAfterSeconds: retrySeconds,
 		Reason:            reason,
 		Message:           message,
+		Dimension:         dimension,
 	})
 }
 
@@ -398,31 +443,31 @@ func (h *AuthHandler) CanSendRiderSMSCodeHandler(c *gin.Context) {
 // #region User Profile Module
 
 // getUserProfileByType retrieves user profile by type
-func (h *AuthHandler) getUserProfileByType(userType string, userID int64) (interface{}, error) {
+func (h *AuthHandler) getUserProfileByType(ctx context.Context, userType string, userID int64) (interface{}, error) {
 	switch userType {
 	case "user":
-		user, err := h.deps.UserService.GetUserByID(userID)
+		user, err := h.deps.UserService.GetUserByID(ctx, userID)
 		if err != nil {
 			return nil, err
 		}
 		return user.ToResponse(), nil
 
 	case "employee":
-		employee, err := h.deps.EmployeeService.GetEmployeeByID(userID)
+		employee, err := h.deps.EmployeeService.GetEmployeeByID(ctx, userID)
 		if err != nil {
 			return nil, err
 		}
 		return employee.ToResponse(), nil
 
 	case "merchant":
-		merchant, err := h.deps.MerchantService.GetMerchantByID(userID)
+		merchant, err := h.deps.MerchantService.GetMerchantByID(ctx, userID)
 		if err != nil {
 			return nil, err
 		}
 		return merchant.ToResponse(), nil
 
 	case "rider":
-		rider, err := h.deps.RiderService.GetRiderByID(userID)
+		rider, err := h.deps.RiderService.GetRiderByID(ctx, userID)
 		if err != nil {
 			return nil, err
 		}
@@ -461,7 +506,7 @@ func (h *AuthHandler) GetProfileHandler(userType string) gin.HandlerFunc {
 			return
 		}
 
-		profile, err := h.getUserProfileByType(userType, userID.(int64))
+		profile, err := h.getUserProfileByType(c.Request.Context(), userType, userID.(int64))
 		if err != nil {
 			InternalServerError(c, ErrMsgInternalServer, err.Error())
 			return
@@ -475,23 +520,17 @@ func (h *AuthHandler) GetProfileHandler(userType string) gin.HandlerFunc {
 
 // #region Employee Management Module
 
-// createEmployeeForMerchant creates an employee associated with the merchant
-func (h *AuthHandler) createEmployeeForMerchant(addEmployeeReq *RegisterRequest, merchantID int64) (*model.Employee, error) {
-	passwordHash, err := crypto.HashPassword(addEmployeeReq.Password)
-	if err != nil {
-		return nil, err
-	}
-
+// createEmployeeForMerchant creates an employee associated with the merchant.
+// The plain password is passed through; MerchantService hashes it inside the transaction flow.
+func (h *AuthHandler) createEmployeeForMerchant(ctx context.Context, addEmployeeReq *RegisterRequest, merchantID int64) (*model.Employee, error) {
 	employee := &model.Employee{
 		Username:     addEmployeeReq.Username,
-		PasswordHash: passwordHash,
+		PasswordHash: addEmployeeReq.Password,
 		Email:        addEmployeeReq.Email,
 		Phone:        addEmployeeReq.Phone,
-		MerchantID:   merchantID,
 	}
 
-	err = h.deps.EmployeeService.RegisterEmployee(employee)
-	if err != nil {
+	if err := h.deps.MerchantService.AddEmployee(ctx, merchantID, employee); err != nil {
 		return nil, err
 	}
 
@@ -500,7 +539,7 @@ func (h *AuthHandler) createEmployeeForMerchant(addEmployeeReq *RegisterRequest,
 
 // AddEmployeeHandler 商家端新增员工处理器
 // @Summary 商家添加员工
-// @Description 商家为自己的店铺添加员工账号
+// @Description 商家或拥有 employee.manage 权限的员工为店铺添加员工账号，新员工默认角色为收银（cashier）
 // @Tags Merchant Management
 // @Accept json
 // @Produce json
@@ -509,12 +548,14 @@ func (h *AuthHandler) createEmployeeForMerchant(addEmployeeReq *RegisterRequest,
 // @Success 200 {object} RegisterResponse "员工添加成功"
 // @Failure 400 {object} ErrorResponse "请求参数错误"
 // @Failure 401 {object} ErrorResponse "未授权"
+// @Failure 403 {object} ErrorResponse "商家已停用"
 // @Failure 409 {object} ErrorResponse "员工已存在"
 // @Failure 500 {object} ErrorResponse "内部服务器错误"
 // @Router /merchants/employees [post]
+// @Router /employees/staff [post]
 func (h *AuthHandler) AddEmployeeHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		merchantID, exists := c.Get("userID")
+		merchantID, exists := middleware.GetMerchantID(c)
 		if !exists {
 			Unauthorized(c, ErrMsgUnauthorized)
 			return
@@ -526,11 +567,16 @@ func (h *AuthHandler) AddEmployeeHandler() gin.HandlerFunc {
 			return
 		}
 
-		employee, err := h.createEmployeeForMerchant(&addEmployeeReq, merchantID.(int64))
+		employee, err := h.createEmployeeForMerchant(c.Request.Context(), &addEmployeeReq, merchantID)
 		if err != nil {
-			if errors.Is(err, service.ErrEmployeeAlreadyExists) {
+			switch {
+			case errors.Is(err, service.ErrEmployeeAlreadyExists):
 				Conflict(c, err.Error(), nil)
-			} else {
+			case errors.Is(err, service.ErrValidationFailed):
+				BadRequest(c, ErrMsgInvalidRequest, err.Error())
+			case errors.Is(err, service.ErrMerchantInactive):
+				Forbidden(c, err.Error())
+			default:
 				InternalServerError(c, ErrMsgInternalServer, err.Error())
 			}
 			return
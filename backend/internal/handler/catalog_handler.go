@@ -0,0 +1,512 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CatalogHandlerDependencies contains all dependencies for CatalogHandler
+type CatalogHandlerDependencies struct {
+	CatalogService service.CatalogServiceInterface
+}
+
+// CatalogHandler handles merchant menu management and the public menu
+// Merchants manage their own catalog; employees act on their merchant's catalog as their role permits
+type CatalogHandler struct {
+	deps *CatalogHandlerDependencies
+}
+
+// NewCatalogHandler creates a new CatalogHandler instance with dependency injection
+func NewCatalogHandler(catalogService service.CatalogServiceInterface) *CatalogHandler {
+	return &CatalogHandler{
+		deps: &CatalogHandlerDependencies{
+			CatalogService: catalogService,
+		},
+	}
+}
+
+// merchantID resolves the merchant the caller acts for (the merchant itself or an employee's merchant)
+func (h *CatalogHandler) merchantID(c *gin.Context) (int64, bool) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return 0, false
+	}
+	merchantID, err := h.deps.CatalogService.ResolveMerchantID(c.Request.Context(), claims.UserType, claims.UserID)
+	if err != nil {
+		h.handleCatalogError(c, err)
+		return 0, false
+	}
+	return merchantID, true
+}
+
+// pathID parses a positive integer path parameter
+func (h *CatalogHandler) pathID(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		BadRequest(c, ErrMsgInvalidRequest, "invalid "+name)
+		return 0, false
+	}
+	return id, true
+}
+
+// handleCatalogError maps catalog service errors to HTTP responses
+func (h *CatalogHandler) handleCatalogError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound),
+		errors.Is(err, service.ErrMenuItemNotFound),
+		errors.Is(err, service.ErrMerchantNotFound):
+		NotFound(c, err.Error())
+	case errors.Is(err, service.ErrCatalogAccessDenied), errors.Is(err, service.ErrEmployeeNotFound):
+		Forbidden(c, err.Error())
+	case errors.Is(err, service.ErrCategoryNotEmpty):
+		Conflict(c, err.Error(), nil)
+	case errors.Is(err, service.ErrValidationFailed):
+		BadRequest(c, ErrMsgValidationFailed, err.Error())
+	default:
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+	}
+}
+
+// toMenuItem converts a request into a catalog item
+func (req *MenuItemRequest) toMenuItem() *model.MenuItem {
+	item := &model.MenuItem{
+		CategoryID:  req.CategoryID,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Stock:       req.Stock,
+		IsAvailable: req.IsAvailable == nil || *req.IsAvailable,
+		SortOrder:   req.SortOrder,
+	}
+	for i, url := range req.Images {
+		item.Images = append(item.Images, model.MenuItemImage{URL: url, SortOrder: i})
+	}
+	for i, g := range req.OptionGroups {
+		group := model.MenuOptionGroup{
+			Name:      g.Name,
+			Required:  g.Required,
+			MinSelect: g.MinSelect,
+			MaxSelect: g.MaxSelect,
+			SortOrder: i,
+		}
+		for j, o := range g.Options {
+			group.Options = append(group.Options, model.MenuOption{
+				Name:        o.Name,
+				PriceDelta:  o.PriceDelta,
+				IsAvailable: o.IsAvailable == nil || *o.IsAvailable,
+				SortOrder:   j,
+			})
+		}
+		item.OptionGroups = append(item.OptionGroups, group)
+	}
+	return item
+}
+
+// #region Public menu
+
+// GetPublicMenuHandler returns a merchant's menu to users
+// @Summary Get merchant menu
+// @Description Public menu of an active merchant: categories with their items, images and option groups
+// @Tags catalog
+// @Produce json
+// @Param id path int true "Merchant ID"
+// @Success 200 {object} MenuResponse "Menu"
+// @Failure 400 {object} ErrorResponse "Invalid merchant id"
+// @Failure 404 {object} ErrorResponse "Merchant not found"
+// @Router /merchants/{id}/menu [get]
+func (h *CatalogHandler) GetPublicMenuHandler(c *gin.Context) {
+	merchantID, ok := h.pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	categories, err := h.deps.CatalogService.GetPublicMenu(c.Request.Context(), merchantID)
+	if err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MenuResponse{MerchantID: merchantID, Categories: categories})
+}
+
+// #endregion
+
+// #region Categories
+
+// ListCategoriesHandler lists the merchant's categories
+// @Summary List menu categories
+// @Tags catalog
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.MenuCategory "Categories"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /merchants/menu/categories [get]
+// @Router /employees/menu/categories [get]
+func (h *CatalogHandler) ListCategoriesHandler(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	categories, err := h.deps.CatalogService.ListCategories(c.Request.Context(), merchantID)
+	if err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// CreateCategoryHandler creates a menu category
+// @Summary Create menu category
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param category body MenuCategoryRequest true "Category"
+// @Success 201 {object} model.MenuCategory "Category created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /merchants/menu/categories [post]
+// @Router /employees/menu/categories [post]
+func (h *CatalogHandler) CreateCategoryHandler(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	var req MenuCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	category := &model.MenuCategory{Name: req.Name, SortOrder: req.SortOrder}
+	if err := h.deps.CatalogService.CreateCategory(c.Request.Context(), merchantID, category); err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategoryHandler renames or reorders a menu category
+// @Summary Update menu category
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Category ID"
+// @Param category body MenuCategoryRequest true "Category"
+// @Success 200 {object} model.MenuCategory "Category updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Category not found"
+// @Router /merchants/menu/categories/{id} [put]
+// @Router /employees/menu/categories/{id} [put]
+func (h *CatalogHandler) UpdateCategoryHandler(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+	categoryID, ok := h.pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req MenuCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	category := &model.MenuCategory{ID: categoryID, Name: req.Name, SortOrder: req.SortOrder}
+	if err := h.deps.CatalogService.UpdateCategory(c.Request.Context(), merchantID, category); err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategoryHandler deletes an empty menu category
+// @Summary Delete menu category
+// @Tags catalog
+// @Security BearerAuth
+// @Param id path int true "Category ID"
+// @Success 204 "Category deleted"
+// @Failure 404 {object} ErrorResponse "Category not found"
+// @Failure 409 {object} ErrorResponse "Category still has items"
+// @Router /merchants/menu/categories/{id} [delete]
+// @Router /employees/menu/categories/{id} [delete]
+func (h *CatalogHandler) DeleteCategoryHandler(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+	categoryID, ok := h.pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.deps.CatalogService.DeleteCategory(c.Request.Context(), merchantID, categoryID); err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// #endregion
+
+// #region Items
+
+// ListItemsHandler lists the merchant's items, optionally filtered by category
+// @Summary List menu items
+// @Description Merchants and their employees can list the catalog
+// @Tags catalog
+// @Produce json
+// @Security BearerAuth
+// @Param category_id query int false "Category ID"
+// @Success 200 {array} model.MenuItem "Items"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /merchants/menu/items [get]
+// @Router /employees/menu/items [get]
+func (h *CatalogHandler) ListItemsHandler(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	var categoryID int64
+	if raw := c.Query("category_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			BadRequest(c, ErrMsgInvalidRequest, "invalid category_id")
+			return
+		}
+		categoryID = id
+	}
+
+	items, err := h.deps.CatalogService.ListItems(c.Request.Context(), merchantID, categoryID)
+	if err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// GetItemHandler returns a single menu item
+// @Summary Get menu item
+// @Tags catalog
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Item ID"
+// @Success 200 {object} model.MenuItem "Item"
+// @Failure 404 {object} ErrorResponse "Item not found"
+// @Router /merchants/menu/items/{id} [get]
+// @Router /employees/menu/items/{id} [get]
+func (h *CatalogHandler) GetItemHandler(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+	itemID, ok := h.pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	item, err := h.deps.CatalogService.GetItem(c.Request.Context(), merchantID, itemID)
+	if err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// CreateItemHandler creates a menu item with its images and option groups
+// @Summary Create menu item
+// @Description Prices are in cents; option groups describe choices such as size or spice level
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param item body MenuItemRequest true "Item"
+// @Success 201 {object} model.MenuItem "Item created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Category not found"
+// @Router /merchants/menu/items [post]
+// @Router /employees/menu/items [post]
+func (h *CatalogHandler) CreateItemHandler(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	var req MenuItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	item := req.toMenuItem()
+	if err := h.deps.CatalogService.CreateItem(c.Request.Context(), merchantID, item); err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateItemHandler replaces a menu item, including its images and option groups
+// @Summary Update menu item
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Item ID"
+// @Param item body MenuItemRequest true "Item"
+// @Success 200 {object} model.MenuItem "Item updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Item or category not found"
+// @Router /merchants/menu/items/{id} [put]
+// @Router /employees/menu/items/{id} [put]
+func (h *CatalogHandler) UpdateItemHandler(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+	itemID, ok := h.pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req MenuItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	item := req.toMenuItem()
+	item.ID = itemID
+	if err := h.deps.CatalogService.UpdateItem(c.Request.Context(), merchantID, item); err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteItemHandler deletes a menu item
+// @Summary Delete menu item
+// @Tags catalog
+// @Security BearerAuth
+// @Param id path int true "Item ID"
+// @Success 204 "Item deleted"
+// @Failure 404 {object} ErrorResponse "Item not found"
+// @Router /merchants/menu/items/{id} [delete]
+// @Router /employees/menu/items/{id} [delete]
+func (h *CatalogHandler) DeleteItemHandler(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+	itemID, ok := h.pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.deps.CatalogService.DeleteItem(c.Request.Context(), merchantID, itemID); err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetItemStockHandler sets or clears an item's stock
+// @Summary Set menu item stock
+// @Tags catalog
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "Item ID"
+// @Param stock body MenuItemStockRequest true "Stock (null for unlimited)"
+// @Success 204 "Stock updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Item not found"
+// @Router /merchants/menu/items/{id}/stock [put]
+// @Router /employees/menu/items/{id}/stock [put]
+func (h *CatalogHandler) SetItemStockHandler(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+	itemID, ok := h.pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req MenuItemStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	if err := h.deps.CatalogService.SetItemStock(c.Request.Context(), merchantID, itemID, req.Stock); err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetItemAvailabilityHandler lists or delists an item
+// @Summary Toggle menu item availability
+// @Description Available to the merchant and to its active employees
+// @Tags catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Item ID"
+// @Param availability body MenuItemAvailabilityRequest true "Availability"
+// @Success 200 {object} model.MenuItem "Item updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 403 {object} ErrorResponse "Not allowed"
+// @Failure 404 {object} ErrorResponse "Item not found"
+// @Router /merchants/menu/items/{id}/availability [put]
+// @Router /employees/menu/items/{id}/availability [put]
+func (h *CatalogHandler) SetItemAvailabilityHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+	itemID, ok := h.pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req MenuItemAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	item, err := h.deps.CatalogService.SetItemAvailability(c.Request.Context(), claims.UserType, claims.UserID, itemID, *req.IsAvailable)
+	if err != nil {
+		h.handleCatalogError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// #endregion
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeCatalogService 员工 5 属于商家 9，员工 6 已离职
+type fakeCatalogService struct {
+	service.CatalogServiceInterface
+	toggled map[int64]bool
+}
+
+func (f *fakeCatalogService) ResolveMerchantID(ctx context.Context, userType string, userID int64) (int64, error) {
+	switch {
+	case userType == "merchant":
+		return userID, nil
+	case userType == "employee" && userID == 5:
+		return 9, nil
+	default:
+		return 0, service.ErrCatalogAccessDenied
+	}
+}
+
+func (f *fakeCatalogService) SetItemAvailability(ctx context.Context, userType string, userID, itemID int64, available bool) (*model.MenuItem, error) {
+	merchantID, err := f.ResolveMerchantID(ctx, userType, userID)
+	if err != nil {
+		return nil, err
+	}
+	f.toggled[itemID] = available
+	return &model.MenuItem{ID: itemID, MerchantID: merchantID, IsAvailable: available}, nil
+}
+
+func (f *fakeCatalogService) GetPublicMenu(ctx context.Context, merchantID int64) ([]*model.MenuCategory, error) {
+	if merchantID != 9 {
+		return nil, service.ErrMerchantNotFound
+	}
+	return []*model.MenuCategory{{ID: 1, MerchantID: 9, Name: "热菜", Items: []model.MenuItem{{ID: 3, Price: 2800}}}}, nil
+}
+
+func newCatalogTestRouter(t *testing.T) (*gin.Engine, *fakeCatalogService) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	catalogService := &fakeCatalogService{toggled: map[int64]bool{}}
+	deps := &RouterDependencies{
+		AuthHandler:    NewAuthHandler(nil, nil, nil, nil),
+		OrderHandler:   NewOrderHandler(nil),
+		CatalogHandler: NewCatalogHandler(catalogService),
+		JWTMiddleware:  middleware.NewJWTMiddleware(testJWTConfig, nil),
+
+		MerchantVerified: verifiedMerchants(9),
+
+		Permissions: employeeRoles(
+			&model.Employee{ID: 5, MerchantID: 9, Role: model.EmployeeRoleKitchen, IsActive: true},
+			&model.Employee{ID: 6, MerchantID: 9, Role: model.EmployeeRoleKitchen},
+			&model.Employee{ID: 7, MerchantID: 9, Role: model.EmployeeRoleCashier, IsActive: true},
+		),
+	}
+
+	router := gin.New()
+	_, employeeGroup, _, merchantGroup := setupPublicRoutes(router.Group("/api/v1"), deps)
+	setupEmployeeProtectedRoutes(employeeGroup, deps)
+	setupMerchantProtectedRoutes(merchantGroup, deps)
+	return router, catalogService
+}
+
+func TestCatalogHandler_EmployeeTogglesAvailability(t *testing.T) {
+	router, catalogService := newCatalogTestRouter(t)
+	body := map[string]bool{"is_available": false}
+
+	w := doJSONRequest(router, http.MethodPut, "/api/v1/employees/menu/items/3/availability", issueToken(t, 5, "employee"), body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("employee toggle status=%d body=%s", w.Code, w.Body.String())
+	}
+	var item model.MenuItem
+	_ = json.Unmarshal(w.Body.Bytes(), &item)
+	if item.MerchantID != 9 || item.IsAvailable || catalogService.toggled[3] {
+		t.Fatalf("unexpected item: %s", w.Body.String())
+	}
+
+	if w := doJSONRequest(router, http.MethodPut, "/api/v1/employees/menu/items/3/availability", issueToken(t, 6, "employee"), body); w.Code != http.StatusForbidden {
+		t.Fatalf("inactive employee status=%d want 403", w.Code)
+	}
+	if w := doJSONRequest(router, http.MethodPut, "/api/v1/employees/menu/items/3/availability", issueToken(t, 7, "employee"), body); w.Code != http.StatusForbidden {
+		t.Fatalf("cashier toggle status=%d want 403", w.Code)
+	}
+	// 缺少 is_available 时拒绝，避免误下架
+	if w := doJSONRequest(router, http.MethodPut, "/api/v1/merchants/menu/items/3/availability", issueToken(t, 9, "merchant"), map[string]any{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing flag status=%d want 400", w.Code)
+	}
+}
+
+func TestCatalogHandler_PublicMenu(t *testing.T) {
+	router, _ := newCatalogTestRouter(t)
+
+	w := doRequest(router, http.MethodGet, "/api/v1/merchants/9/menu", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("menu status=%d body=%s", w.Code, w.Body.String())
+	}
+	var menu MenuResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &menu)
+	if menu.MerchantID != 9 || len(menu.Categories) != 1 || len(menu.Categories[0].Items) != 1 {
+		t.Fatalf("unexpected menu: %s", w.Body.String())
+	}
+
+	if w := doRequest(router, http.MethodGet, "/api/v1/merchants/404/menu", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown merchant status=%d want 404", w.Code)
+	}
+}
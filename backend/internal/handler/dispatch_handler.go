@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// DispatchHandlerDependencies contains all dependencies for DispatchHandler
+type DispatchHandlerDependencies struct {
+	DispatchService service.DispatchServiceInterface
+}
+
+// DispatchHandler handles riders responding to dispatch offers
+type DispatchHandler struct {
+	deps *DispatchHandlerDependencies
+}
+
+// NewDispatchHandler creates a new DispatchHandler instance with dependency injection
+func NewDispatchHandler(dispatchService service.DispatchServiceInterface) *DispatchHandler {
+	return &DispatchHandler{
+		deps: &DispatchHandlerDependencies{
+			DispatchService: dispatchService,
+		},
+	}
+}
+
+// offerTarget extracts the rider ID from JWT claims and the order ID from the path
+func (h *DispatchHandler) offerTarget(c *gin.Context) (int64, int64, bool) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return 0, 0, false
+	}
+	orderID, err := strconv.ParseInt(c.Param("order_id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		BadRequest(c, ErrMsgInvalidRequest, "invalid order id")
+		return 0, 0, false
+	}
+	return claims.UserID, orderID, true
+}
+
+// handleDispatchError maps dispatch service errors to HTTP responses
+func (h *DispatchHandler) handleDispatchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNoOfferForRider), errors.Is(err, service.ErrOrderNotFound):
+		NotFound(c, err.Error())
+	case errors.Is(err, service.ErrOrderStatusConflict):
+		Conflict(c, err.Error(), nil)
+	default:
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+	}
+}
+
+// ListOffersHandler lists the offers awaiting the rider's response
+// @Summary List dispatch offers
+// @Description Orders currently offered to the rider, with their accept deadline
+// @Tags dispatch
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} DispatchOfferListResponse "Pending offers"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /riders/dispatch/offers [get]
+func (h *DispatchHandler) ListOffersHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	c.JSON(http.StatusOK, DispatchOfferListResponse{
+		Offers: h.deps.DispatchService.PendingOffers(claims.UserID),
+	})
+}
+
+// AcceptOfferHandler handles a rider accepting an offer
+// @Summary Accept dispatch offer
+// @Description Rider accepts the order offered to them before the offer expires
+// @Tags dispatch
+// @Produce json
+// @Security BearerAuth
+// @Param order_id path int true "Order ID"
+// @Success 200 {object} model.Order "Order assigned to the rider"
+// @Failure 404 {object} ErrorResponse "No pending offer for this rider"
+// @Failure 409 {object} ErrorResponse "Order no longer assignable"
+// @Router /riders/dispatch/offers/{order_id}/accept [post]
+func (h *DispatchHandler) AcceptOfferHandler(c *gin.Context) {
+	riderID, orderID, ok := h.offerTarget(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.deps.DispatchService.AcceptOffer(c.Request.Context(), riderID, orderID)
+	if err != nil {
+		h.handleDispatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// DeclineOfferHandler handles a rider declining an offer
+// @Summary Decline dispatch offer
+// @Description Rider declines the offer; the order is offered to the next candidate
+// @Tags dispatch
+// @Produce json
+// @Security BearerAuth
+// @Param order_id path int true "Order ID"
+// @Success 200 {object} SuccessResponse "Offer declined"
+// @Failure 404 {object} ErrorResponse "No pending offer for this rider"
+// @Router /riders/dispatch/offers/{order_id}/decline [post]
+func (h *DispatchHandler) DeclineOfferHandler(c *gin.Context) {
+	riderID, orderID, ok := h.offerTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.deps.DispatchService.DeclineOffer(c.Request.Context(), riderID, orderID); err != nil {
+		h.handleDispatchError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "已拒绝派单")
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -35,6 +37,7 @@ const (
 	ErrMsgInternalServer   = "服务器内部错误"
 	ErrMsgInvalidUserType  = "无效的用户类型"
 	ErrMsgValidationFailed = "参数验证失败"
+	ErrMsgRequestTimeout   = "请求处理超时"
 )
 
 // #endregion
@@ -42,7 +45,11 @@ const (
 // #region 统一错误处理函数
 
 // RespondWithError 统一错误响应
+// 请求 context 已超时导致的服务端错误统一改写为 504，便于客户端区分超时与内部错误
 func RespondWithError(c *gin.Context, statusCode int, errorType, message string, details interface{}) {
+	if statusCode >= http.StatusInternalServerError && c.Request != nil && errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+		statusCode, errorType, message = http.StatusGatewayTimeout, "GATEWAY_TIMEOUT", ErrMsgRequestTimeout
+	}
 	c.JSON(statusCode, DetailedErrorResponse{
 		Error:   errorType,
 		Message: message,
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/Hermitf/the-pass/internal/middleware"
 	"github.com/Hermitf/the-pass/internal/model"
 	"github.com/Hermitf/the-pass/internal/service"
 	"github.com/gin-gonic/gin"
@@ -30,14 +32,15 @@ func NewMerchantHandler(merchantService service.MerchantServiceInterface, employ
 	}
 }
 
-// validateMerchantID validates the merchant ID from JWT context
+// validateMerchantID resolves the merchant the caller acts for, as set by the permission middleware
+// (the merchant account itself, or the merchant of a permitted employee)
 func (h *MerchantHandler) validateMerchantID(c *gin.Context) (int64, bool) {
-	merchantID, exists := c.Get("userID")
+	merchantID, exists := middleware.GetMerchantID(c)
 	if !exists {
 		Unauthorized(c, ErrMsgUnauthorized)
 		return 0, false
 	}
-	return merchantID.(int64), true
+	return merchantID, true
 }
 
 // GetEmployeesHandler handles fetching merchant's employees
@@ -50,13 +53,14 @@ func (h *MerchantHandler) validateMerchantID(c *gin.Context) (int64, bool) {
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
 // @Router /merchants/employees [get]
+// @Router /employees/staff [get]
 func (h *MerchantHandler) GetEmployeesHandler(c *gin.Context) {
 	merchantID, valid := h.validateMerchantID(c)
 	if !valid {
 		return
 	}
 
-	employees, err := h.deps.EmployeeService.GetEmployeesByMerchantID(merchantID)
+	employees, err := h.deps.EmployeeService.GetEmployeesByMerchantID(c.Request.Context(), merchantID)
 	if err != nil {
 		InternalServerError(c, ErrMsgInternalServer, err.Error())
 		return
@@ -69,3 +73,104 @@ func (h *MerchantHandler) GetEmployeesHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, employeeResponses)
 }
+
+// SetEmployeeRoleHandler assigns a role to one of the merchant's employees
+// @Summary Set employee role
+// @Description Assign owner, manager, cashier or kitchen to an employee of the caller's merchant. Available to the merchant and to employees with employee.manage; employees cannot change their own role.
+// @Tags merchants
+// @Accept json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param request body EmployeeRoleRequest true "Role"
+// @Success 200 {object} model.EmployeeResponse "Employee updated"
+// @Failure 400 {object} ErrorResponse "Invalid role"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not allowed"
+// @Failure 404 {object} ErrorResponse "Employee not found"
+// @Security ApiKeyAuth
+// @Router /merchants/employees/{id}/role [put]
+// @Router /employees/staff/{id}/role [put]
+func (h *MerchantHandler) SetEmployeeRoleHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+	merchantID, valid := h.validateMerchantID(c)
+	if !valid {
+		return
+	}
+	employeeID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req EmployeeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	operator := service.StaffOperator{MerchantID: merchantID}
+	if claims.UserType == "employee" {
+		operator.EmployeeID = claims.UserID
+	}
+	employee, err := h.deps.EmployeeService.SetEmployeeRole(c.Request.Context(), operator, employeeID, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEmployeeNotFound), errors.Is(err, service.ErrEmployeeNotInMerchant):
+			NotFound(c, service.ErrEmployeeNotFound.Error())
+		case errors.Is(err, service.ErrCannotChangeOwnRole):
+			Forbidden(c, err.Error())
+		case errors.Is(err, service.ErrEmployeeRoleInvalid), errors.Is(err, service.ErrInvalidEmployeeID):
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		default:
+			InternalServerError(c, ErrMsgInternalServer, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, employee.ToResponse())
+}
+
+// UpdateStoreLocationHandler sets the store address used as the pickup address of new orders
+// @Summary Set store location
+// @Description Set the merchant's store address and coordinates; orders snapshot them as the pickup location. Available before verification approval.
+// @Tags merchants
+// @Accept json
+// @Produce json
+// @Param request body StoreLocationRequest true "Store location"
+// @Success 200 {object} model.MerchantResponse "Merchant updated"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Merchant not found"
+// @Security ApiKeyAuth
+// @Router /merchants/store [put]
+func (h *MerchantHandler) UpdateStoreLocationHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	var req StoreLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	merchant, err := h.deps.MerchantService.UpdateStoreLocation(c.Request.Context(), claims.UserID, req.Address, req.Lat, req.Lng)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMerchantNotFound):
+			NotFound(c, err.Error())
+		case errors.Is(err, service.ErrValidationFailed), errors.Is(err, service.ErrInvalidMerchantID):
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		default:
+			InternalServerError(c, ErrMsgInternalServer, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, merchant.ToResponse())
+}
@@ -0,0 +1,496 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead leaves room for form boundaries and the doc_type field on top of the file size limit
+const multipartOverhead = 1 << 20
+
+// MerchantVerificationHandler handles merchant KYC: document uploads and submission by the merchant,
+// review decisions by admins
+type MerchantVerificationHandler struct {
+	verificationService service.MerchantVerificationServiceInterface
+	maxUploadSize       int64
+}
+
+// NewMerchantVerificationHandler creates a new MerchantVerificationHandler instance
+func NewMerchantVerificationHandler(verificationService service.MerchantVerificationServiceInterface, maxUploadSize int64) *MerchantVerificationHandler {
+	if maxUploadSize <= 0 {
+		maxUploadSize = service.DefaultMaxDocumentSize
+	}
+	return &MerchantVerificationHandler{
+		verificationService: verificationService,
+		maxUploadSize:       maxUploadSize,
+	}
+}
+
+// #region Merchant Side
+
+// GetVerificationHandler returns the verification progress of the logged-in merchant
+// @Summary Get verification status
+// @Description Get the KYC status, rejection reason and uploaded documents of the logged-in merchant
+// @Tags merchant-verification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} service.MerchantVerification "Verification progress"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Merchant not found"
+// @Router /merchants/verification [get]
+func (h *MerchantVerificationHandler) GetVerificationHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	verification, err := h.verificationService.GetVerification(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// UpdateApplicationHandler saves the business details to be reviewed
+// @Summary Update verification details
+// @Description Save the store name and business license number. Only allowed while the application is a draft or rejected.
+// @Tags merchant-verification
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MerchantApplicationRequest true "Business details"
+// @Success 200 {object} SuccessResponse "Saved"
+// @Failure 400 {object} ErrorResponse "Invalid details"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Locked during review or license number already used"
+// @Router /merchants/verification/application [put]
+func (h *MerchantVerificationHandler) UpdateApplicationHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+	var req MerchantApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	err := h.verificationService.UpdateApplication(c.Request.Context(), claims.UserID, service.MerchantApplication{
+		CompanyName:     req.CompanyName,
+		BusinessLicense: req.BusinessLicense,
+	})
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "审核信息已保存")
+}
+
+// UploadDocumentHandler uploads a verification document
+// @Summary Upload verification document
+// @Description Upload a license document (JPG, PNG or PDF). Only allowed while the application is a draft or rejected.
+// @Tags merchant-verification
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param doc_type formData string true "Document type" Enums(business_license, food_license, other)
+// @Param file formData file true "Document file"
+// @Success 201 {object} model.VerificationDocument "Uploaded document"
+// @Failure 400 {object} ErrorResponse "Invalid document"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Documents locked during review"
+// @Failure 413 {object} ErrorResponse "File too large"
+// @Router /merchants/verification/documents [post]
+func (h *MerchantVerificationHandler) UploadDocumentHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			RespondWithError(c, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", service.ErrDocumentTooLarge.Error(), nil)
+			return
+		}
+		BadRequest(c, ErrMsgInvalidRequest, "file is required")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	doc, err := h.verificationService.UploadDocument(c.Request.Context(), claims.UserID, service.DocumentUpload{
+		DocType:  c.PostForm("doc_type"),
+		FileName: fileHeader.Filename,
+		Size:     fileHeader.Size,
+		Content:  file,
+	})
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusCreated, doc, "材料上传成功")
+}
+
+// GetDocumentHandler downloads one of the merchant's own documents
+// @Summary Download verification document
+// @Description Download a document uploaded by the logged-in merchant
+// @Tags merchant-verification
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Success 200 {file} file "Document content"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Document not found"
+// @Router /merchants/verification/documents/{id} [get]
+func (h *MerchantVerificationHandler) GetDocumentHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+	docID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	h.serveDocument(c, claims.UserID, docID)
+}
+
+// DeleteDocumentHandler deletes a verification document
+// @Summary Delete verification document
+// @Description Delete a document. Only allowed while the application is a draft or rejected.
+// @Tags merchant-verification
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Success 200 {object} SuccessResponse "Document deleted"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Document not found"
+// @Failure 409 {object} ErrorResponse "Documents locked during review"
+// @Router /merchants/verification/documents/{id} [delete]
+func (h *MerchantVerificationHandler) DeleteDocumentHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+	docID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.verificationService.DeleteDocument(c.Request.Context(), claims.UserID, docID); err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "材料已删除")
+}
+
+// SubmitVerificationHandler submits the application for admin review
+// @Summary Submit verification
+// @Description Submit the application for review; requires a valid business license number and an uploaded business license
+// @Tags merchant-verification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse "Submitted"
+// @Failure 400 {object} ErrorResponse "Missing license or documents"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Already submitted or approved"
+// @Router /merchants/verification/submit [post]
+func (h *MerchantVerificationHandler) SubmitVerificationHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	if err := h.verificationService.SubmitVerification(c.Request.Context(), claims.UserID); err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "已提交审核")
+}
+
+// #endregion
+
+// #region Admin Review
+
+// ListVerificationsHandler lists merchants by verification status
+// @Summary List merchant verifications
+// @Description List merchants in a verification status, oldest submission first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Verification status" Enums(draft, submitted, under_review, approved, rejected) default(submitted)
+// @Param offset query int false "Offset" default(0)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} MerchantVerificationListResponse "Merchants"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Router /admin/merchant-verifications [get]
+func (h *MerchantVerificationHandler) ListVerificationsHandler(c *gin.Context) {
+	offset, limit, ok := parseAdminPagination(c)
+	if !ok {
+		return
+	}
+
+	status := c.DefaultQuery("status", model.VerificationSubmitted)
+	merchants, total, err := h.verificationService.ListVerifications(c.Request.Context(), status, offset, limit)
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	response := make([]*model.MerchantResponse, len(merchants))
+	for i, merchant := range merchants {
+		response[i] = merchant.ToResponse()
+	}
+	c.JSON(http.StatusOK, MerchantVerificationListResponse{Merchants: response, Total: total})
+}
+
+// GetMerchantVerificationHandler returns the verification progress of a merchant
+// @Summary Get merchant verification
+// @Description Get the KYC status and documents of a merchant
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Merchant ID"
+// @Success 200 {object} service.MerchantVerification "Verification progress"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Merchant not found"
+// @Router /admin/merchants/{id}/verification [get]
+func (h *MerchantVerificationHandler) GetMerchantVerificationHandler(c *gin.Context) {
+	merchantID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	verification, err := h.verificationService.GetVerification(c.Request.Context(), merchantID)
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// GetMerchantDocumentHandler downloads a merchant's document for review
+// @Summary Download merchant document
+// @Description Download a verification document of a merchant
+// @Tags admin
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Merchant ID"
+// @Param doc_id path int true "Document ID"
+// @Success 200 {file} file "Document content"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Document not found"
+// @Router /admin/merchants/{id}/verification/documents/{doc_id} [get]
+func (h *MerchantVerificationHandler) GetMerchantDocumentHandler(c *gin.Context) {
+	merchantID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	docID, ok := parseIDParam(c, "doc_id")
+	if !ok {
+		return
+	}
+
+	h.serveDocument(c, merchantID, docID)
+}
+
+// StartReviewHandler marks a submitted application as under review
+// @Summary Start merchant review
+// @Description Take a submitted application into review
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Merchant ID"
+// @Success 200 {object} SuccessResponse "Under review"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Merchant not found"
+// @Failure 409 {object} ErrorResponse "Not submitted"
+// @Router /admin/merchants/{id}/verification/review [post]
+func (h *MerchantVerificationHandler) StartReviewHandler(c *gin.Context) {
+	actor, merchantID, ok := h.reviewTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.verificationService.StartReview(c.Request.Context(), actor, merchantID); err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "已受理审核")
+}
+
+// ApproveHandler approves a merchant under review
+// @Summary Approve merchant
+// @Description Approve an application under review; the merchant is notified by SMS and can start trading
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Merchant ID"
+// @Param request body VerificationApproveRequest false "Review remark"
+// @Success 200 {object} SuccessResponse "Approved"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Merchant not found"
+// @Failure 409 {object} ErrorResponse "Not under review"
+// @Router /admin/merchants/{id}/verification/approve [post]
+func (h *MerchantVerificationHandler) ApproveHandler(c *gin.Context) {
+	actor, merchantID, ok := h.reviewTarget(c)
+	if !ok {
+		return
+	}
+	var req VerificationApproveRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	if err := h.verificationService.ApproveVerification(c.Request.Context(), actor, merchantID, req.Remark); err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "审核已通过")
+}
+
+// RejectHandler rejects a merchant under review
+// @Summary Reject merchant
+// @Description Reject an application under review with a reason shown to the merchant; the merchant is notified by SMS
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Merchant ID"
+// @Param request body VerificationRejectRequest true "Rejection reason"
+// @Success 200 {object} SuccessResponse "Rejected"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Merchant not found"
+// @Failure 409 {object} ErrorResponse "Not under review"
+// @Router /admin/merchants/{id}/verification/reject [post]
+func (h *MerchantVerificationHandler) RejectHandler(c *gin.Context) {
+	actor, merchantID, ok := h.reviewTarget(c)
+	if !ok {
+		return
+	}
+	var req VerificationRejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	if err := h.verificationService.RejectVerification(c.Request.Context(), actor, merchantID, req.Reason); err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "审核已驳回")
+}
+
+// #endregion
+
+// #region Helpers
+
+// reviewTarget resolves the acting admin and the merchant ID of a review request
+func (h *MerchantVerificationHandler) reviewTarget(c *gin.Context) (service.AdminActor, int64, bool) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return service.AdminActor{}, 0, false
+	}
+	merchantID, ok := parseIDParam(c, "id")
+	if !ok {
+		return service.AdminActor{}, 0, false
+	}
+	return service.AdminActor{AdminID: claims.UserID, ClientIP: c.ClientIP()}, merchantID, true
+}
+
+// serveDocument streams a stored document as an attachment
+func (h *MerchantVerificationHandler) serveDocument(c *gin.Context, merchantID, docID int64) {
+	doc, content, err := h.verificationService.OpenDocument(c.Request.Context(), merchantID, docID)
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, doc.Size, doc.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// handleVerificationError maps verification service errors to HTTP responses
+func (h *MerchantVerificationHandler) handleVerificationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMerchantNotFound), errors.Is(err, service.ErrDocumentNotFound):
+		NotFound(c, err.Error())
+	case errors.Is(err, service.ErrDocumentsLocked), errors.Is(err, service.ErrInvalidVerificationState),
+		errors.Is(err, service.ErrMerchantProfileLocked), errors.Is(err, service.ErrBusinessLicenseTaken):
+		Conflict(c, err.Error(), nil)
+	case errors.Is(err, service.ErrDocumentTooLarge):
+		RespondWithError(c, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", err.Error(), nil)
+	case errors.Is(err, service.ErrDocumentTypeInvalid),
+		errors.Is(err, service.ErrDocumentEmpty),
+		errors.Is(err, service.ErrDocumentFormatUnsupported),
+		errors.Is(err, service.ErrBusinessLicenseDocRequired),
+		errors.Is(err, service.ErrValidationFailed),
+		errors.Is(err, service.ErrVerificationStatusInvalid),
+		errors.Is(err, service.ErrPaginationInvalid),
+		errors.Is(err, service.ErrInvalidMerchantID),
+		errors.Is(err, service.ErrAuditReasonRequired),
+		errors.Is(err, service.ErrAuditReasonTooLong):
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+	default:
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+	}
+}
+
+// parseIDParam parses a positive integer path parameter
+func parseIDParam(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		BadRequest(c, ErrMsgInvalidRequest, "invalid "+name)
+		return 0, false
+	}
+	return id, true
+}
+
+// #endregion
@@ -0,0 +1,383 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultOrderPageSize = 20
+	maxOrderPageSize     = 100
+)
+
+// OrderHandlerDependencies contains all dependencies for OrderHandler
+type OrderHandlerDependencies struct {
+	OrderService service.OrderServiceInterface
+}
+
+// OrderHandler handles the order lifecycle for users, merchants, employees and riders
+// The caller's role comes from the JWT claims; the service enforces who may make each transition
+type OrderHandler struct {
+	deps *OrderHandlerDependencies
+}
+
+// NewOrderHandler creates a new OrderHandler instance with dependency injection
+func NewOrderHandler(orderService service.OrderServiceInterface) *OrderHandler {
+	return &OrderHandler{
+		deps: &OrderHandlerDependencies{
+			OrderService: orderService,
+		},
+	}
+}
+
+// orderActor builds the acting party from JWT claims
+func (h *OrderHandler) orderActor(c *gin.Context) (service.OrderActor, bool) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return service.OrderActor{}, false
+	}
+	return service.OrderActor{UserType: claims.UserType, UserID: claims.UserID}, true
+}
+
+// orderID parses the :id path parameter
+func (h *OrderHandler) orderID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		BadRequest(c, ErrMsgInvalidRequest, "invalid order id")
+		return 0, false
+	}
+	return id, true
+}
+
+// handleOrderError maps order service errors to HTTP responses
+func (h *OrderHandler) handleOrderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound),
+		errors.Is(err, service.ErrMerchantNotFound),
+		errors.Is(err, service.ErrOrderItemNotFound):
+		NotFound(c, err.Error())
+	case errors.Is(err, service.ErrOrderAccessDenied):
+		Forbidden(c, err.Error())
+	case errors.Is(err, service.ErrInvalidOrderTransition),
+		errors.Is(err, service.ErrOrderStatusConflict),
+		errors.Is(err, service.ErrOrderNotDelivered),
+		errors.Is(err, service.ErrOrderAlreadyRated),
+		errors.Is(err, service.ErrMerchantNotAccepting),
+		errors.Is(err, service.ErrMerchantLocationMissing),
+		errors.Is(err, service.ErrOrderItemUnavailable),
+		errors.Is(err, service.ErrOrderNotDispatchable),
+		errors.Is(err, service.ErrNoAvailableRiders),
+		errors.Is(err, service.ErrRiderUnavailable):
+		Conflict(c, err.Error(), nil)
+	case errors.Is(err, service.ErrValidationFailed),
+		errors.Is(err, service.ErrPaginationInvalid),
+		errors.Is(err, model.ErrInvalidRiderRating):
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+	default:
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+	}
+}
+
+// CreateOrderHandler handles placing a new order
+// @Summary Create order
+// @Description Place an order with a merchant by menu item IDs and selected option IDs; names, prices (including option price deltas) and the pickup address come from the merchant's catalog and store record, amounts are in cents
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param order body CreateOrderRequest true "Order"
+// @Success 201 {object} model.Order "Order created"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Merchant or menu item not found"
+// @Failure 409 {object} ErrorResponse "Merchant not accepting orders or item sold out"
+// @Router /users/orders [post]
+func (h *OrderHandler) CreateOrderHandler(c *gin.Context) {
+	actor, ok := h.orderActor(c)
+	if !ok {
+		return
+	}
+
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	order := &model.Order{
+		MerchantID:      req.MerchantID,
+		DeliveryAddress: req.DeliveryAddress,
+		DeliveryLat:     req.DeliveryLat,
+		DeliveryLng:     req.DeliveryLng,
+		ContactName:     req.ContactName,
+		ContactPhone:    req.ContactPhone,
+		Remark:          req.Remark,
+	}
+	for _, item := range req.Items {
+		line := model.OrderItem{
+			MenuItemID: item.MenuItemID,
+			Quantity:   item.Quantity,
+		}
+		for _, optionID := range item.OptionIDs {
+			line.Options = append(line.Options, model.OrderItemOption{OptionID: optionID})
+		}
+		order.Items = append(order.Items, line)
+	}
+
+	if err := h.deps.OrderService.CreateOrder(c.Request.Context(), actor.UserID, order); err != nil {
+		h.handleOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// ListOrdersHandler handles listing the caller's orders
+// @Summary List orders
+// @Description List orders visible to the caller (own orders for users and riders, the shop's orders for merchants and employees)
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status"
+// @Param offset query int false "Offset" default(0)
+// @Param limit query int false "Page size (max 100)" default(20)
+// @Success 200 {object} OrderListResponse "Orders"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /users/orders [get]
+// @Router /merchants/orders [get]
+// @Router /employees/orders [get]
+// @Router /riders/orders [get]
+func (h *OrderHandler) ListOrdersHandler(c *gin.Context) {
+	actor, ok := h.orderActor(c)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		BadRequest(c, ErrMsgInvalidRequest, "invalid offset")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultOrderPageSize)))
+	if err != nil || limit <= 0 {
+		BadRequest(c, ErrMsgInvalidRequest, "invalid limit")
+		return
+	}
+	if limit > maxOrderPageSize {
+		limit = maxOrderPageSize
+	}
+
+	orders, total, err := h.deps.OrderService.ListOrders(c.Request.Context(), actor, c.Query("status"), offset, limit)
+	if err != nil {
+		h.handleOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, OrderListResponse{Orders: orders, Total: total})
+}
+
+// GetOrderHandler handles fetching a single order
+// @Summary Get order
+// @Description Get an order the caller takes part in
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} model.Order "Order"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not a party to this order"
+// @Failure 404 {object} ErrorResponse "Order not found"
+// @Router /users/orders/{id} [get]
+// @Router /merchants/orders/{id} [get]
+// @Router /employees/orders/{id} [get]
+// @Router /riders/orders/{id} [get]
+func (h *OrderHandler) GetOrderHandler(c *gin.Context) {
+	actor, ok := h.orderActor(c)
+	if !ok {
+		return
+	}
+	id, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.deps.OrderService.GetOrder(c.Request.Context(), actor, id)
+	if err != nil {
+		h.handleOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// orderTransitionFunc is a service method moving an order to its next status
+type orderTransitionFunc func(ctx context.Context, actor service.OrderActor, orderID int64) (*model.Order, error)
+
+// transitionHandler wraps a status transition that takes no request body
+func (h *OrderHandler) transitionHandler(c *gin.Context, transition orderTransitionFunc) {
+	actor, ok := h.orderActor(c)
+	if !ok {
+		return
+	}
+	id, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	order, err := transition(c.Request.Context(), actor, id)
+	if err != nil {
+		h.handleOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// AcceptOrderHandler handles a merchant accepting an order
+// @Summary Accept order
+// @Description Merchant (or one of its employees) accepts a newly created order
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} model.Order "Order accepted"
+// @Failure 403 {object} ErrorResponse "Not allowed"
+// @Failure 404 {object} ErrorResponse "Order not found"
+// @Failure 409 {object} ErrorResponse "Invalid status transition"
+// @Router /merchants/orders/{id}/accept [post]
+// @Router /employees/orders/{id}/accept [post]
+func (h *OrderHandler) AcceptOrderHandler(c *gin.Context) {
+	h.transitionHandler(c, h.deps.OrderService.AcceptOrder)
+}
+
+// PrepareOrderHandler handles a merchant marking an order as prepared
+// @Summary Mark order prepared
+// @Description Merchant (or one of its employees) marks an accepted order ready for pickup
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} model.Order "Order prepared"
+// @Failure 403 {object} ErrorResponse "Not allowed"
+// @Failure 404 {object} ErrorResponse "Order not found"
+// @Failure 409 {object} ErrorResponse "Invalid status transition"
+// @Router /merchants/orders/{id}/prepared [post]
+// @Router /employees/orders/{id}/prepared [post]
+func (h *OrderHandler) PrepareOrderHandler(c *gin.Context) {
+	h.transitionHandler(c, h.deps.OrderService.MarkOrderPrepared)
+}
+
+// DispatchOrderHandler handles a merchant re-dispatching an order
+// @Summary Dispatch order
+// @Description Merchant (or one of its employees) restarts dispatch for a prepared order that has no rider, e.g. after every offered rider declined
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} model.Order "Dispatch started"
+// @Failure 403 {object} ErrorResponse "Not allowed"
+// @Failure 404 {object} ErrorResponse "Order not found"
+// @Failure 409 {object} ErrorResponse "Order not dispatchable or no rider available"
+// @Router /merchants/orders/{id}/dispatch [post]
+// @Router /employees/orders/{id}/dispatch [post]
+func (h *OrderHandler) DispatchOrderHandler(c *gin.Context) {
+	h.transitionHandler(c, h.deps.OrderService.DispatchOrder)
+}
+
+// PickUpOrderHandler handles a rider picking up an order
+// @Summary Pick up order
+// @Description The rider the order was dispatched to picks up the prepared order
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} model.Order "Order picked up"
+// @Failure 403 {object} ErrorResponse "Not allowed"
+// @Failure 404 {object} ErrorResponse "Order not found"
+// @Failure 409 {object} ErrorResponse "Invalid status transition"
+// @Router /riders/orders/{id}/pickup [post]
+func (h *OrderHandler) PickUpOrderHandler(c *gin.Context) {
+	h.transitionHandler(c, h.deps.OrderService.PickUpOrder)
+}
+
+// DeliverOrderHandler handles a rider delivering an order
+// @Summary Deliver order
+// @Description Assigned rider confirms delivery; the rider's order count is updated atomically
+// @Tags orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} model.Order "Order delivered"
+// @Failure 403 {object} ErrorResponse "Not allowed"
+// @Failure 404 {object} ErrorResponse "Order not found"
+// @Failure 409 {object} ErrorResponse "Invalid status transition"
+// @Router /riders/orders/{id}/deliver [post]
+func (h *OrderHandler) DeliverOrderHandler(c *gin.Context) {
+	h.transitionHandler(c, h.deps.OrderService.DeliverOrder)
+}
+
+// CancelOrderHandler handles cancelling an order
+// @Summary Cancel order
+// @Description Users may cancel before the merchant accepts; merchants and employees until pickup
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body CancelOrderRequest false "Cancel reason"
+// @Success 200 {object} model.Order "Order cancelled"
+// @Failure 403 {object} ErrorResponse "Not allowed"
+// @Failure 404 {object} ErrorResponse "Order not found"
+// @Failure 409 {object} ErrorResponse "Invalid status transition"
+// @Router /users/orders/{id}/cancel [post]
+// @Router /merchants/orders/{id}/cancel [post]
+// @Router /employees/orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrderHandler(c *gin.Context) {
+	var req CancelOrderRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	h.transitionHandler(c, func(ctx context.Context, actor service.OrderActor, orderID int64) (*model.Order, error) {
+		return h.deps.OrderService.CancelOrder(ctx, actor, orderID, req.Reason)
+	})
+}
+
+// RateOrderHandler handles a user rating the rider of a delivered order
+// @Summary Rate order
+// @Description Rate the rider of a delivered order (1-5, once per order)
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body RateOrderRequest true "Rating"
+// @Success 200 {object} model.Order "Order rated"
+// @Failure 400 {object} ErrorResponse "Invalid rating"
+// @Failure 403 {object} ErrorResponse "Not allowed"
+// @Failure 404 {object} ErrorResponse "Order not found"
+// @Failure 409 {object} ErrorResponse "Not delivered or already rated"
+// @Router /users/orders/{id}/rate [post]
+func (h *OrderHandler) RateOrderHandler(c *gin.Context) {
+	var req RateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	h.transitionHandler(c, func(ctx context.Context, actor service.OrderActor, orderID int64) (*model.Order, error) {
+		return h.deps.OrderService.RateOrder(ctx, actor, orderID, req.Rating)
+	})
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/Hermitf/the-pass/pkg/sms"
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordResetHandlerDependencies contains all dependencies for PasswordResetHandler
+type PasswordResetHandlerDependencies struct {
+	PasswordResetService service.PasswordResetServiceInterface
+}
+
+// PasswordResetHandler handles the public forgot-password flow shared by all user types
+type PasswordResetHandler struct {
+	deps *PasswordResetHandlerDependencies
+}
+
+// NewPasswordResetHandler creates a new PasswordResetHandler instance with dependency injection
+func NewPasswordResetHandler(passwordResetService service.PasswordResetServiceInterface) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		deps: &PasswordResetHandlerDependencies{
+			PasswordResetService: passwordResetService,
+		},
+	}
+}
+
+// handleResetError maps password reset service errors to HTTP responses
+func (h *PasswordResetHandler) handleResetError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrResetUserTypeInvalid):
+		BadRequest(c, ErrMsgInvalidUserType, err.Error())
+	case errors.Is(err, service.ErrResetChannelInvalid),
+		errors.Is(err, service.ErrPhoneInvalid),
+		errors.Is(err, service.ErrEmailInvalid),
+		errors.Is(err, service.ErrSMSCodeEmpty),
+		errors.Is(err, service.ErrPasswordTooWeak):
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+	case errors.Is(err, service.ErrSMSCodeInvalid), errors.Is(err, service.ErrResetTokenInvalid):
+		Unauthorized(c, err.Error())
+	case errors.Is(err, service.ErrAccountDeactivated):
+		Forbidden(c, err.Error())
+	case errors.Is(err, sms.ErrSendTooFrequent), errors.Is(err, sms.ErrDailyLimitReached):
+		RespondWithError(c, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", err.Error(), nil)
+	case errors.Is(err, service.ErrResetUnavailable), errors.Is(err, sms.ErrProviderDisabled):
+		RespondWithError(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", err.Error(), nil)
+	default:
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+	}
+}
+
+// ForgotPasswordHandler starts a password reset for the given user type
+// @Summary Request password reset
+// @Description Sends a reset_password SMS code (channel=sms, identifier=phone) or emails a one-time reset token (channel=email, identifier=email). Responds the same whether or not the account exists
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param userType path string true "user type" Enums(users, employees, merchants, riders)
+// @Param forgotRequest body ForgotPasswordRequest true "reset channel and identifier"
+// @Success 200 {object} SuccessResponse "reset requested"
+// @Failure 400 {object} ErrorResponse "invalid request"
+// @Failure 429 {object} ErrorResponse "sending too frequently"
+// @Failure 503 {object} ErrorResponse "channel unavailable"
+// @Router /{userType}/password/forgot [post]
+func (h *PasswordResetHandler) ForgotPasswordHandler(userType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ForgotPasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+			return
+		}
+
+		if err := h.deps.PasswordResetService.RequestReset(smsRequestContext(c), userType, req.Channel, req.Identifier); err != nil {
+			h.handleResetError(c, err)
+			return
+		}
+
+		RespondWithSuccess(c, http.StatusOK, nil, "如账号存在，验证信息已发送")
+	}
+}
+
+// VerifyPasswordResetHandler exchanges an SMS code or emailed token for a reset ticket
+// @Summary Verify password reset
+// @Description Verifies the SMS code or emailed token and returns a short-lived, single-use reset ticket
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param userType path string true "user type" Enums(users, employees, merchants, riders)
+// @Param verifyRequest body VerifyPasswordResetRequest true "code or token"
+// @Success 200 {object} PasswordResetTicketResponse "verified"
+// @Failure 400 {object} ErrorResponse "invalid request"
+// @Failure 401 {object} ErrorResponse "code or token invalid"
+// @Failure 403 {object} ErrorResponse "account deactivated"
+// @Router /{userType}/password/verify [post]
+func (h *PasswordResetHandler) VerifyPasswordResetHandler(userType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req VerifyPasswordResetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+			return
+		}
+
+		ticket, err := h.deps.PasswordResetService.VerifyReset(c.Request.Context(), userType, req.Channel, req.Identifier, req.Code)
+		if err != nil {
+			h.handleResetError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, PasswordResetTicketResponse{ResetTicket: ticket})
+	}
+}
+
+// ResetPasswordHandler sets a new password with a reset ticket
+// @Summary Reset password
+// @Description Sets a new password using the reset ticket. The password must pass the strength policy; every existing session of the account is revoked
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param userType path string true "user type" Enums(users, employees, merchants, riders)
+// @Param resetRequest body ResetPasswordRequest true "reset ticket and new password"
+// @Success 200 {object} SuccessResponse "password reset"
+// @Failure 400 {object} ErrorResponse "invalid request or weak password"
+// @Failure 401 {object} ErrorResponse "reset ticket invalid"
+// @Failure 500 {object} ErrorResponse "internal server error"
+// @Router /{userType}/password/reset [post]
+func (h *PasswordResetHandler) ResetPasswordHandler(userType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ResetPasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+			return
+		}
+
+		if err := h.deps.PasswordResetService.CompleteReset(c.Request.Context(), userType, req.ResetTicket, req.NewPassword); err != nil {
+			h.handleResetError(c, err)
+			return
+		}
+
+		RespondWithSuccess(c, http.StatusOK, nil, "密码已重置，请重新登录")
+	}
+}
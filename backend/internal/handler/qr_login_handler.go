@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	authqr "github.com/Hermitf/the-pass/internal/auth_qr"
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// QRLoginHandlerDependencies contains all dependencies for QRLoginHandler
+type QRLoginHandlerDependencies struct {
+	TicketStore *authqr.Store
+	JWTService  service.JWTServiceInterface
+}
+
+// QRLoginHandler handles the QR-code login flow
+// Desktop: create ticket -> poll status -> receive token once confirmed
+// Mobile (authenticated): scan -> confirm / reject
+type QRLoginHandler struct {
+	deps *QRLoginHandlerDependencies
+}
+
+// NewQRLoginHandler creates a new QRLoginHandler instance with dependency injection
+func NewQRLoginHandler(ticketStore *authqr.Store, jwtService service.JWTServiceInterface) *QRLoginHandler {
+	return &QRLoginHandler{
+		deps: &QRLoginHandlerDependencies{
+			TicketStore: ticketStore,
+			JWTService:  jwtService,
+		},
+	}
+}
+
+// handleTicketError maps ticket store errors to HTTP responses
+func (h *QRLoginHandler) handleTicketError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, authqr.ErrTicketNotFound):
+		NotFound(c, err.Error())
+	case errors.Is(err, authqr.ErrTicketExpired):
+		RespondWithError(c, http.StatusGone, "GONE", err.Error(), nil)
+	case errors.Is(err, authqr.ErrTicketScannedByOther):
+		Forbidden(c, err.Error())
+	case errors.Is(err, authqr.ErrTicketNotConfirmed):
+		Conflict(c, err.Error(), nil)
+	default:
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+	}
+}
+
+// mobileIdentity extracts the authenticated mobile account from JWT context
+func (h *QRLoginHandler) mobileIdentity(c *gin.Context) (int64, string, bool) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return 0, "", false
+	}
+	return claims.UserID, claims.UserType, true
+}
+
+// CreateTicketHandler creates a QR login ticket for the desktop client
+// @Summary create QR login ticket
+// @Description Create a short-lived ticket and return its ID together with the payload to render as a QR code
+// @Tags QR Login
+// @Produce json
+// @Success 200 {object} QRTicketResponse "ticket created"
+// @Failure 500 {object} ErrorResponse "internal server error"
+// @Router /auth/qr/tickets [post]
+func (h *QRLoginHandler) CreateTicketHandler(c *gin.Context) {
+	ticket, err := h.deps.TicketStore.CreateTicket(c.Request.Context(), 0)
+	if err != nil {
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+		return
+	}
+
+	payload, err := authqr.EncodePayload(ticket)
+	if err != nil {
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, QRTicketResponse{
+		TicketID:  ticket.ID,
+		QRPayload: payload,
+		ExpiresAt: ticket.ExpiresAt,
+	})
+}
+
+// PollTicketHandler returns the ticket status; once confirmed it issues a desktop token and deletes the ticket
+// @Summary poll QR login ticket
+// @Description Poll ticket status. When the status is confirmed, a JWT is returned exactly once and the ticket is removed
+// @Tags QR Login
+// @Produce json
+// @Param id path string true "ticket ID"
+// @Success 200 {object} QRTicketStatusResponse "ticket status"
+// @Failure 404 {object} ErrorResponse "ticket not found or already redeemed"
+// @Failure 410 {object} ErrorResponse "ticket expired"
+// @Failure 500 {object} ErrorResponse "internal server error"
+// @Router /auth/qr/tickets/{id} [get]
+func (h *QRLoginHandler) PollTicketHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	ticket, err := h.deps.TicketStore.GetTicket(ctx, c.Param("id"))
+	if err != nil {
+		h.handleTicketError(c, err)
+		return
+	}
+
+	if ticket.Status != authqr.TicketStatusConfirmed {
+		c.JSON(http.StatusOK, QRTicketStatusResponse{Status: string(ticket.Status), ExpiresAt: ticket.ExpiresAt})
+		return
+	}
+
+	// 原子兑换：并发轮询时只有一个请求能拿到令牌
+	redeemed, err := h.deps.TicketStore.Redeem(ctx, ticket.ID)
+	if err != nil {
+		h.handleTicketError(c, err)
+		return
+	}
+
+	tokens, err := h.deps.JWTService.IssueTokenPair(ctx, redeemed.UserID, redeemed.UserType)
+	if err != nil {
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, QRTicketStatusResponse{
+		Status:       string(redeemed.Status),
+		ExpiresAt:    redeemed.ExpiresAt,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		UserType:     redeemed.UserType,
+	})
+}
+
+// ScanTicketHandler marks the ticket as scanned by the authenticated mobile account
+// @Summary scan QR login ticket
+// @Description Mark the ticket as scanned by the logged-in mobile account
+// @Tags QR Login
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ticket ID"
+// @Success 200 {object} QRTicketStatusResponse "ticket scanned"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 403 {object} ErrorResponse "ticket scanned by another account"
+// @Failure 404 {object} ErrorResponse "ticket not found"
+// @Failure 410 {object} ErrorResponse "ticket expired"
+// @Router /auth/qr/tickets/{id}/scan [post]
+func (h *QRLoginHandler) ScanTicketHandler(c *gin.Context) {
+	userID, userType, ok := h.mobileIdentity(c)
+	if !ok {
+		return
+	}
+
+	ticket, err := h.deps.TicketStore.MarkScanned(c.Request.Context(), c.Param("id"), map[string]string{
+		authqr.MetaScannedBy: authqr.ScannerIdentity(userID, userType),
+		"scan_user_agent":    c.Request.UserAgent(),
+		"scan_ip":            c.ClientIP(),
+	})
+	if err != nil {
+		h.handleTicketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, QRTicketStatusResponse{Status: string(ticket.Status), ExpiresAt: ticket.ExpiresAt})
+}
+
+// ConfirmTicketHandler confirms the login on behalf of the authenticated mobile account
+// @Summary confirm QR login
+// @Description Confirm the desktop login; the desktop client receives a token for this account on its next poll
+// @Tags QR Login
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ticket ID"
+// @Success 200 {object} QRTicketStatusResponse "ticket confirmed"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 403 {object} ErrorResponse "ticket scanned by another account"
+// @Failure 404 {object} ErrorResponse "ticket not found"
+// @Failure 410 {object} ErrorResponse "ticket expired or not scanned"
+// @Router /auth/qr/tickets/{id}/confirm [post]
+func (h *QRLoginHandler) ConfirmTicketHandler(c *gin.Context) {
+	userID, userType, ok := h.mobileIdentity(c)
+	if !ok {
+		return
+	}
+
+	ticket, err := h.deps.TicketStore.Confirm(c.Request.Context(), c.Param("id"), userID, userType, nil)
+	if err != nil {
+		h.handleTicketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, QRTicketStatusResponse{Status: string(ticket.Status), ExpiresAt: ticket.ExpiresAt})
+}
+
+// RejectTicketHandler rejects the login from the authenticated mobile account
+// @Summary reject QR login
+// @Description Reject the desktop login; the desktop client stops polling
+// @Tags QR Login
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ticket ID"
+// @Param rejectRequest body QRRejectRequest false "reject reason"
+// @Success 200 {object} QRTicketStatusResponse "ticket rejected"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 403 {object} ErrorResponse "ticket scanned by another account"
+// @Failure 404 {object} ErrorResponse "ticket not found"
+// @Failure 410 {object} ErrorResponse "ticket expired"
+// @Router /auth/qr/tickets/{id}/reject [post]
+func (h *QRLoginHandler) RejectTicketHandler(c *gin.Context) {
+	userID, userType, ok := h.mobileIdentity(c)
+	if !ok {
+		return
+	}
+
+	var rejectReq QRRejectRequest
+	// 拒绝原因可选，请求体为空时忽略绑定错误
+	_ = c.ShouldBindJSON(&rejectReq)
+
+	ticket, err := h.deps.TicketStore.Reject(c.Request.Context(), c.Param("id"), rejectReq.Reason, map[string]string{
+		authqr.MetaScannedBy: authqr.ScannerIdentity(userID, userType),
+	})
+	if err != nil {
+		h.handleTicketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, QRTicketStatusResponse{Status: string(ticket.Status), ExpiresAt: ticket.ExpiresAt})
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	authqr "github.com/Hermitf/the-pass/internal/auth_qr"
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/Hermitf/the-pass/pkg/auth"
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func newQRTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(func() { mr.Close() })
+
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	deps := &RouterDependencies{
+		QRLoginHandler: NewQRLoginHandler(authqr.NewStore(rdb), service.NewJWTService(service.JWTServiceDependencies{Config: testJWTConfig, RefreshStore: auth.NewRedisRefreshTokenStore(rdb)})),
+		JWTMiddleware:  middleware.NewJWTMiddleware(testJWTConfig, nil),
+	}
+
+	router := gin.New()
+	setupQRLoginRoutes(router.Group("/api/v1"), deps)
+	return router
+}
+
+func createQRTicket(t *testing.T, router *gin.Engine) QRTicketResponse {
+	t.Helper()
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/qr/tickets", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("create status=%d body=%s", w.Code, w.Body.String())
+	}
+	var resp QRTicketResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode create response: %v", err)
+	}
+	return resp
+}
+
+func TestQRLogin_FullFlow(t *testing.T) {
+	router := newQRTestRouter(t)
+	ticket := createQRTicket(t, router)
+
+	payload, err := authqr.DecodePayload(ticket.QRPayload)
+	if err != nil || payload.TicketID != ticket.TicketID {
+		t.Fatalf("payload=%+v err=%v", payload, err)
+	}
+
+	base := "/api/v1/auth/qr/tickets/" + ticket.TicketID
+	mobileToken := issueToken(t, 42, "merchant")
+
+	if w := doRequest(router, http.MethodPost, base+"/scan", mobileToken); w.Code != http.StatusOK {
+		t.Fatalf("scan status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	// 扫码后未确认，轮询只返回状态
+	w := doRequest(router, http.MethodGet, base, "")
+	var status QRTicketStatusResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &status)
+	if w.Code != http.StatusOK || status.Status != string(authqr.TicketStatusScanned) || status.Token != "" {
+		t.Fatalf("poll before confirm status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	if w := doRequest(router, http.MethodPost, base+"/confirm", mobileToken); w.Code != http.StatusOK {
+		t.Fatalf("confirm status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(router, http.MethodGet, base, "")
+	status = QRTicketStatusResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &status)
+	if w.Code != http.StatusOK || status.Token == "" || status.RefreshToken == "" {
+		t.Fatalf("poll after confirm status=%d body=%s", w.Code, w.Body.String())
+	}
+	claims, err := auth.VerifyToken(status.Token, testJWTConfig)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if claims.UserID != 42 || claims.UserType != "merchant" {
+		t.Fatalf("claims=%+v want merchant:42", claims)
+	}
+
+	// 令牌只能领取一次
+	if w := doRequest(router, http.MethodGet, base, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("second poll status=%d want 404", w.Code)
+	}
+}
+
+func TestQRLogin_OtherAccountCannotConfirm(t *testing.T) {
+	router := newQRTestRouter(t)
+	ticket := createQRTicket(t, router)
+	base := "/api/v1/auth/qr/tickets/" + ticket.TicketID
+
+	if w := doRequest(router, http.MethodPost, base+"/scan", issueToken(t, 1, "user")); w.Code != http.StatusOK {
+		t.Fatalf("scan status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	other := issueToken(t, 2, "user")
+	if w := doRequest(router, http.MethodPost, base+"/scan", other); w.Code != http.StatusForbidden {
+		t.Fatalf("rescan by other status=%d want 403", w.Code)
+	}
+	if w := doRequest(router, http.MethodPost, base+"/confirm", other); w.Code != http.StatusForbidden {
+		t.Fatalf("confirm by other status=%d want 403", w.Code)
+	}
+	if w := doRequest(router, http.MethodPost, base+"/reject", other); w.Code != http.StatusForbidden {
+		t.Fatalf("reject by other status=%d want 403", w.Code)
+	}
+}
+
+func TestQRLogin_MobileEndpointsRequireToken(t *testing.T) {
+	router := newQRTestRouter(t)
+	ticket := createQRTicket(t, router)
+
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/qr/tickets/"+ticket.TicketID+"/scan", "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status=%d want 401", w.Code)
+	}
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/Hermitf/the-pass/internal/service"
 	"github.com/gin-gonic/gin"
@@ -39,7 +41,7 @@ func (h *RiderHandler) validateUserID(c *gin.Context) (int64, bool) {
 
 // getRiderAndRespond retrieves rider information and responds with it
 func (h *RiderHandler) getRiderAndRespond(c *gin.Context, userID int64) {
-	rider, err := h.deps.RiderService.GetRiderByID(userID)
+	rider, err := h.deps.RiderService.GetRiderByID(c.Request.Context(), userID)
 	if err != nil {
 		InternalServerError(c, ErrMsgInternalServer, err.Error())
 		return
@@ -68,6 +70,7 @@ func (h *RiderHandler) validateOnlineStatusRequest(c *gin.Context) (*RiderOnline
 // @Success 200 {object} model.RiderResponse "Online status updated successfully"
 // @Failure 400 {object} ErrorResponse "Invalid request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Rider deactivated or not verified"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /riders/online-status [put]
 func (h *RiderHandler) UpdateOnlineStatusHandler(c *gin.Context) {
@@ -81,8 +84,12 @@ func (h *RiderHandler) UpdateOnlineStatusHandler(c *gin.Context) {
 		return
 	}
 
-	err := h.deps.RiderService.SetOnlineStatus(userID, statusReq.IsOnline)
+	err := h.deps.RiderService.SetOnlineStatus(c.Request.Context(), userID, statusReq.IsOnline)
 	if err != nil {
+		if errors.Is(err, service.ErrRiderNotVerified) || errors.Is(err, service.ErrCannotSetInactiveOnline) {
+			Forbidden(c, err.Error())
+			return
+		}
 		InternalServerError(c, ErrMsgInternalServer, err.Error())
 		return
 	}
@@ -102,13 +109,13 @@ func (h *RiderHandler) validateLocationRequest(c *gin.Context) (*RiderLocationUp
 
 // UpdateLocationHandler handles updating rider location
 // @Summary update rider location
-// @Description Update the location of the logged-in rider
+// @Description Report the current location of the logged-in rider; riders should report every few seconds to stay online
 // @Tags riders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param location body RiderLocationUpdateRequest true "Location coordinates"
-// @Success 200 {object} model.RiderResponse "Location updated successfully"
+// @Success 200 {object} RiderLocationUpdateResponse "Location updated successfully"
 // @Failure 400 {object} ErrorResponse "Invalid request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -124,11 +131,19 @@ func (h *RiderHandler) UpdateLocationHandler(c *gin.Context) {
 		return
 	}
 
-	err := h.deps.RiderService.UpdateLocation(userID, locationReq.Latitude, locationReq.Longitude)
+	err := h.deps.RiderService.UpdateLocation(c.Request.Context(), userID, locationReq.Latitude, locationReq.Longitude)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidLocation) {
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+			return
+		}
 		InternalServerError(c, ErrMsgInternalServer, err.Error())
 		return
 	}
 
-	h.getRiderAndRespond(c, userID)
+	c.JSON(http.StatusOK, RiderLocationUpdateResponse{
+		Latitude:   locationReq.Latitude,
+		Longitude:  locationReq.Longitude,
+		ReportedAt: time.Now(),
+	})
 }
@@ -0,0 +1,487 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// RiderVerificationHandler handles rider onboarding: identity/vehicle details and documents by the rider,
+// review decisions by admins
+type RiderVerificationHandler struct {
+	verificationService service.RiderVerificationServiceInterface
+	maxUploadSize       int64
+}
+
+// NewRiderVerificationHandler creates a new RiderVerificationHandler instance
+func NewRiderVerificationHandler(verificationService service.RiderVerificationServiceInterface, maxUploadSize int64) *RiderVerificationHandler {
+	if maxUploadSize <= 0 {
+		maxUploadSize = service.DefaultMaxDocumentSize
+	}
+	return &RiderVerificationHandler{
+		verificationService: verificationService,
+		maxUploadSize:       maxUploadSize,
+	}
+}
+
+// #region Rider Side
+
+// GetVerificationHandler returns the verification progress of the logged-in rider
+// @Summary Get verification status
+// @Description Get the verification status, submitted details, required and uploaded documents of the logged-in rider
+// @Tags rider-verification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} service.RiderVerification "Verification progress"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Rider not found"
+// @Router /riders/verification [get]
+func (h *RiderVerificationHandler) GetVerificationHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	verification, err := h.verificationService.GetVerification(c.Request.Context(), claims.UserID)
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// UpdateApplicationHandler saves the identity and vehicle details to be reviewed
+// @Summary Update verification details
+// @Description Save name, ID number and vehicle details. Only allowed while the application is a draft or rejected.
+// @Tags rider-verification
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RiderApplicationRequest true "Identity and vehicle details"
+// @Success 200 {object} SuccessResponse "Saved"
+// @Failure 400 {object} ErrorResponse "Invalid details"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Locked during review or ID number already used"
+// @Router /riders/verification/application [put]
+func (h *RiderVerificationHandler) UpdateApplicationHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+	var req RiderApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	err := h.verificationService.UpdateApplication(c.Request.Context(), claims.UserID, service.RiderApplication{
+		Name:          req.Name,
+		IDNumber:      req.IDNumber,
+		VehicleType:   req.VehicleType,
+		VehicleNumber: req.VehicleNumber,
+		LicenseNumber: req.LicenseNumber,
+	})
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "审核信息已保存")
+}
+
+// UploadDocumentHandler uploads a verification document
+// @Summary Upload verification document
+// @Description Upload an ID card or license photo (JPG, PNG or PDF). Only allowed while the application is a draft or rejected.
+// @Tags rider-verification
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param doc_type formData string true "Document type" Enums(id_card_front, id_card_back, driver_license, vehicle_license, other)
+// @Param file formData file true "Document file"
+// @Success 201 {object} model.VerificationDocument "Uploaded document"
+// @Failure 400 {object} ErrorResponse "Invalid document"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Documents locked during review"
+// @Failure 413 {object} ErrorResponse "File too large"
+// @Router /riders/verification/documents [post]
+func (h *RiderVerificationHandler) UploadDocumentHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			RespondWithError(c, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", service.ErrDocumentTooLarge.Error(), nil)
+			return
+		}
+		BadRequest(c, ErrMsgInvalidRequest, "file is required")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	doc, err := h.verificationService.UploadDocument(c.Request.Context(), claims.UserID, service.DocumentUpload{
+		DocType:  c.PostForm("doc_type"),
+		FileName: fileHeader.Filename,
+		Size:     fileHeader.Size,
+		Content:  file,
+	})
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusCreated, doc, "材料上传成功")
+}
+
+// GetDocumentHandler downloads one of the rider's own documents
+// @Summary Download verification document
+// @Description Download a document uploaded by the logged-in rider
+// @Tags rider-verification
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Success 200 {file} file "Document content"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Document not found"
+// @Router /riders/verification/documents/{id} [get]
+func (h *RiderVerificationHandler) GetDocumentHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+	docID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	h.serveDocument(c, claims.UserID, docID)
+}
+
+// DeleteDocumentHandler deletes a verification document
+// @Summary Delete verification document
+// @Description Delete a document. Only allowed while the application is a draft or rejected.
+// @Tags rider-verification
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Success 200 {object} SuccessResponse "Document deleted"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Document not found"
+// @Failure 409 {object} ErrorResponse "Documents locked during review"
+// @Router /riders/verification/documents/{id} [delete]
+func (h *RiderVerificationHandler) DeleteDocumentHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+	docID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.verificationService.DeleteDocument(c.Request.Context(), claims.UserID, docID); err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "材料已删除")
+}
+
+// SubmitVerificationHandler submits the application for admin review
+// @Summary Submit verification
+// @Description Submit the application for review; the ID number must pass its checksum, licence details must match the vehicle type and all required documents must be uploaded
+// @Tags rider-verification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse "Submitted"
+// @Failure 400 {object} ErrorResponse "Invalid details or missing documents"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Already submitted or approved"
+// @Router /riders/verification/submit [post]
+func (h *RiderVerificationHandler) SubmitVerificationHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	if err := h.verificationService.SubmitVerification(c.Request.Context(), claims.UserID); err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "已提交审核")
+}
+
+// #endregion
+
+// #region Admin Review
+
+// ListVerificationsHandler lists riders by verification status
+// @Summary List rider verifications
+// @Description List riders in a verification status, oldest submission first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Verification status" Enums(draft, submitted, under_review, approved, rejected) default(submitted)
+// @Param offset query int false "Offset" default(0)
+// @Param limit query int false "Page size" default(20)
+// @Success 200 {object} RiderVerificationListResponse "Riders"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Router /admin/rider-verifications [get]
+func (h *RiderVerificationHandler) ListVerificationsHandler(c *gin.Context) {
+	offset, limit, ok := parseAdminPagination(c)
+	if !ok {
+		return
+	}
+
+	status := c.DefaultQuery("status", model.VerificationSubmitted)
+	riders, total, err := h.verificationService.ListVerifications(c.Request.Context(), status, offset, limit)
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	response := make([]*model.RiderResponse, len(riders))
+	for i, rider := range riders {
+		response[i] = rider.ToResponse()
+	}
+	c.JSON(http.StatusOK, RiderVerificationListResponse{Riders: response, Total: total})
+}
+
+// GetRiderVerificationHandler returns the verification progress of a rider
+// @Summary Get rider verification
+// @Description Get the verification status, submitted details and documents of a rider
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rider ID"
+// @Success 200 {object} service.RiderVerification "Verification progress"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Rider not found"
+// @Router /admin/riders/{id}/verification [get]
+func (h *RiderVerificationHandler) GetRiderVerificationHandler(c *gin.Context) {
+	riderID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	verification, err := h.verificationService.GetVerification(c.Request.Context(), riderID)
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
+
+// GetRiderDocumentHandler downloads a rider's document for review
+// @Summary Download rider document
+// @Description Download a verification document of a rider
+// @Tags admin
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Rider ID"
+// @Param doc_id path int true "Document ID"
+// @Success 200 {file} file "Document content"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Document not found"
+// @Router /admin/riders/{id}/verification/documents/{doc_id} [get]
+func (h *RiderVerificationHandler) GetRiderDocumentHandler(c *gin.Context) {
+	riderID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	docID, ok := parseIDParam(c, "doc_id")
+	if !ok {
+		return
+	}
+
+	h.serveDocument(c, riderID, docID)
+}
+
+// StartReviewHandler marks a submitted application as under review
+// @Summary Start rider review
+// @Description Take a submitted application into review
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rider ID"
+// @Success 200 {object} SuccessResponse "Under review"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Rider not found"
+// @Failure 409 {object} ErrorResponse "Not submitted"
+// @Router /admin/riders/{id}/verification/review [post]
+func (h *RiderVerificationHandler) StartReviewHandler(c *gin.Context) {
+	actor, riderID, ok := h.reviewTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.verificationService.StartReview(c.Request.Context(), actor, riderID); err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "已受理审核")
+}
+
+// ApproveHandler approves a rider under review
+// @Summary Approve rider
+// @Description Approve an application under review; the rider is notified by SMS and can go online
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rider ID"
+// @Param request body VerificationApproveRequest false "Review remark"
+// @Success 200 {object} SuccessResponse "Approved"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Rider not found"
+// @Failure 409 {object} ErrorResponse "Not under review"
+// @Router /admin/riders/{id}/verification/approve [post]
+func (h *RiderVerificationHandler) ApproveHandler(c *gin.Context) {
+	actor, riderID, ok := h.reviewTarget(c)
+	if !ok {
+		return
+	}
+	var req VerificationApproveRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, ErrMsgInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	if err := h.verificationService.ApproveVerification(c.Request.Context(), actor, riderID, req.Remark); err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "审核已通过")
+}
+
+// RejectHandler rejects a rider under review
+// @Summary Reject rider
+// @Description Reject an application under review with a reason shown to the rider; the rider is notified by SMS
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Rider ID"
+// @Param request body VerificationRejectRequest true "Rejection reason"
+// @Success 200 {object} SuccessResponse "Rejected"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not an admin"
+// @Failure 404 {object} ErrorResponse "Rider not found"
+// @Failure 409 {object} ErrorResponse "Not under review"
+// @Router /admin/riders/{id}/verification/reject [post]
+func (h *RiderVerificationHandler) RejectHandler(c *gin.Context) {
+	actor, riderID, ok := h.reviewTarget(c)
+	if !ok {
+		return
+	}
+	var req VerificationRejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	if err := h.verificationService.RejectVerification(c.Request.Context(), actor, riderID, req.Reason); err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "审核已驳回")
+}
+
+// #endregion
+
+// #region Helpers
+
+// reviewTarget resolves the acting admin and the rider ID of a review request
+func (h *RiderVerificationHandler) reviewTarget(c *gin.Context) (service.AdminActor, int64, bool) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return service.AdminActor{}, 0, false
+	}
+	riderID, ok := parseIDParam(c, "id")
+	if !ok {
+		return service.AdminActor{}, 0, false
+	}
+	return service.AdminActor{AdminID: claims.UserID, ClientIP: c.ClientIP()}, riderID, true
+}
+
+// serveDocument streams a stored document as an attachment
+func (h *RiderVerificationHandler) serveDocument(c *gin.Context, riderID, docID int64) {
+	doc, content, err := h.verificationService.OpenDocument(c.Request.Context(), riderID, docID)
+	if err != nil {
+		h.handleVerificationError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, doc.Size, doc.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// handleVerificationError maps verification service errors to HTTP responses
+func (h *RiderVerificationHandler) handleVerificationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRiderNotFound), errors.Is(err, service.ErrDocumentNotFound):
+		NotFound(c, err.Error())
+	case errors.Is(err, service.ErrDocumentsLocked),
+		errors.Is(err, service.ErrRiderProfileLocked),
+		errors.Is(err, service.ErrRiderIDNumberTaken),
+		errors.Is(err, service.ErrInvalidVerificationState):
+		Conflict(c, err.Error(), nil)
+	case errors.Is(err, service.ErrDocumentTooLarge):
+		RespondWithError(c, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", err.Error(), nil)
+	case errors.Is(err, service.ErrDocumentTypeInvalid),
+		errors.Is(err, service.ErrDocumentEmpty),
+		errors.Is(err, service.ErrDocumentFormatUnsupported),
+		errors.Is(err, service.ErrRiderDocumentRequired),
+		errors.Is(err, service.ErrValidationFailed),
+		errors.Is(err, service.ErrVerificationStatusInvalid),
+		errors.Is(err, service.ErrPaginationInvalid),
+		errors.Is(err, service.ErrInvalidRiderID),
+		errors.Is(err, service.ErrAuditReasonRequired),
+		errors.Is(err, service.ErrAuditReasonTooLong):
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+	default:
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+	}
+}
+
+// #endregion
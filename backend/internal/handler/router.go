@@ -1,14 +1,22 @@
 package handler
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/Hermitf/the-pass/internal/app"
+	authqr "github.com/Hermitf/the-pass/internal/auth_qr"
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/location"
 	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/model"
 	"github.com/Hermitf/the-pass/internal/repository"
 	"github.com/Hermitf/the-pass/internal/service"
 	"github.com/Hermitf/the-pass/pkg/auth"
+	"github.com/Hermitf/the-pass/pkg/clock"
+	"github.com/Hermitf/the-pass/pkg/crypto"
 	"github.com/Hermitf/the-pass/pkg/sms"
+	"github.com/Hermitf/the-pass/pkg/storage"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -17,19 +25,46 @@ import (
 
 // RouterDependencies holds all the dependencies needed for route setup
 type RouterDependencies struct {
-	AuthHandler     *AuthHandler
-	MerchantHandler *MerchantHandler
-	RiderHandler    *RiderHandler
-	JWTMiddleware   *middleware.JWTMiddleware
+	AuthHandler          *AuthHandler
+	MerchantHandler      *MerchantHandler
+	RiderHandler         *RiderHandler
+	OrderHandler         *OrderHandler
+	CatalogHandler       *CatalogHandler
+	DispatchHandler      *DispatchHandler
+	QRLoginHandler       *QRLoginHandler
+	TokenHandler         *TokenHandler
+	PasswordResetHandler *PasswordResetHandler
+	AdminHandler         *AdminHandler
+	JWTMiddleware        *middleware.JWTMiddleware
+
+	MerchantVerificationHandler *MerchantVerificationHandler
+	// MerchantVerified gates merchant business routes until the admin approves the merchant's KYC
+	MerchantVerified gin.HandlerFunc
+
+	RiderVerificationHandler *RiderVerificationHandler
+
+	// Permissions checks employee roles on merchant-scoped routes; merchant accounts pass as owners
+	Permissions *middleware.PermissionMiddleware
+
+	// SMSCallbackHandler receives vendor delivery receipts; nil unless the SMS send queue and a callback token are configured
+	SMSCallbackHandler *SMSCallbackHandler
+
+	// SMSChallenge requires a captcha or proof-of-work before public SMS sends and password reset requests once send volume rises
+	SMSChallenge gin.HandlerFunc
 }
 
 // setupMiddleware 配置CORS和其他中间件
-func setupMiddleware(router *gin.Engine, appCtx *app.AppContext) {
+// 仅信任配置的反向代理转发的 X-Forwarded-For，否则客户端可伪造 IP 绕过按 IP 的登录与短信限制
+func setupMiddleware(router *gin.Engine, appCtx *app.AppContext) error {
+	if err := router.SetTrustedProxies(appCtx.Config.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid server.trusted_proxies: %w", err)
+	}
+
 	// CORS middleware configuration
 	corsConfig := cors.Config{
 		AllowOrigins:     appCtx.Config.Server.CORS.AllowedOrigins,
 		AllowMethods:     appCtx.Config.Server.CORS.AllowedMethods,
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", middleware.HeaderChallengeID, middleware.HeaderChallengeAnswer, HeaderDeviceID},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -38,6 +73,8 @@ func setupMiddleware(router *gin.Engine, appCtx *app.AppContext) {
 	router.Use(cors.New(corsConfig))
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(middleware.RequestTimeout(appCtx.Config.Server.RequestTimeout))
+	return nil
 }
 
 // initializeDependencies creates and returns all dependencies needed for routing
@@ -47,15 +84,32 @@ func initializeDependencies(appCtx *app.AppContext) *RouterDependencies {
 	employeeRepo := repository.NewEmployeeRepository(appCtx.DB)
 	merchantRepo := repository.NewMerchantRepository(appCtx.DB)
 	riderRepo := repository.NewRiderRepository(appCtx.DB)
+	orderRepo := repository.NewOrderRepository(appCtx.DB)
+	catalogRepo := repository.NewCatalogRepository(appCtx.DB)
+	adminRepo := repository.NewAdminRepository(appCtx.DB)
+	auditLogRepo := repository.NewAuditLogRepository(appCtx.DB)
+	verificationDocRepo := repository.NewVerificationDocumentRepository(appCtx.DB)
+	// Repositories resolve the active transaction from ctx, so one TxManager spans all of them
+	txManager := database.NewTxManager(appCtx.DB)
 
 	// Create JWT config from application context configuration
 	jwtConfig := auth.JWTConfig{
-		SecretKey: appCtx.Config.JWT.SecretKey,
-		ExpiresIn: appCtx.Config.JWT.ExpiresIn,
+		SecretKey:        appCtx.Config.JWT.SecretKey,
+		ExpiresIn:        appCtx.Config.JWT.ExpiresIn,
+		RefreshExpiresIn: appCtx.Config.JWT.RefreshExpiresIn,
+		Issuer:           appCtx.Config.JWT.Issuer,
+		Audience:         appCtx.Config.JWT.Audience,
+		Leeway:           appCtx.Config.JWT.Leeway,
+		Keys:             appCtx.JWTKeys,
 	}
 
-	// Initialize shared JWT service
-	jwtService := service.NewJWTService(jwtConfig)
+	// Initialize shared JWT service (refresh tokens and revocations are kept in Redis)
+	revocationStore := auth.NewRedisRevocationStore(appCtx.RedisClient)
+	jwtService := service.NewJWTService(service.JWTServiceDependencies{
+		Config:          jwtConfig,
+		RefreshStore:    auth.NewRedisRefreshTokenStore(appCtx.RedisClient),
+		RevocationStore: revocationStore,
+	})
 
 	// Initialize services with proper dependencies
 	var smsService *sms.Service
@@ -63,43 +117,177 @@ func initializeDependencies(appCtx *app.AppContext) *RouterDependencies {
 		smsService = appCtx.SMSService
 	}
 
+	// Login failures are counted per account+IP and per IP in Redis, shared by all account types
+	accountPolicy, ipPolicy := service.LoginLimiterPolicies(appCtx.Config.LoginLimit)
+	loginGuard := service.NewLoginGuard(
+		crypto.NewRedisAttemptLimiter(appCtx.RedisClient, "auth:login:account", accountPolicy),
+		crypto.NewRedisAttemptLimiter(appCtx.RedisClient, "auth:login:ip", ipPolicy),
+	)
+
 	userService := service.NewUserService(service.UserServiceDependencies{
 		UserRepo:   userRepo,
 		JWTService: jwtService,
 		SMSService: smsService,
+		LoginGuard: loginGuard,
+		TxManager:  txManager,
 	})
 	employeeService := service.NewEmployeeService(service.EmployeeServiceDependencies{
 		EmployeeRepo: employeeRepo,
+		MerchantRepo: merchantRepo,
 		JWTService:   jwtService,
+		LoginGuard:   loginGuard,
 	})
 	merchantService := service.NewMerchantService(service.MerchantServiceDependencies{
 		MerchantRepo: merchantRepo,
 		EmployeeRepo: employeeRepo,
 		JWTService:   jwtService,
 		SMSService:   smsService,
+		LoginGuard:   loginGuard,
+		TxManager:    txManager,
 	})
+	// Rider locations are ingested into Redis GEO and flushed to Postgres in batches
+	locationStore := location.NewStore(appCtx.RedisClient, appCtx.Config.RiderLocation.LivenessTTL)
+	locationSyncer := service.NewRiderLocationSyncer(service.RiderLocationSyncerDependencies{
+		LocationStore: locationStore,
+		RiderRepo:     riderRepo,
+		Interval:      appCtx.Config.RiderLocation.FlushInterval,
+	})
+	appCtx.RunBackground("rider-location-sync", locationSyncer.Run)
+
 	riderService := service.NewRiderService(service.RiderServiceDependencies{
-		RiderRepo:  riderRepo,
-		JWTService: jwtService,
-		SMSService: smsService,
+		RiderRepo:     riderRepo,
+		JWTService:    jwtService,
+		SMSService:    smsService,
+		LocationStore: locationStore,
+		LoginGuard:    loginGuard,
+	})
+	dispatchService := service.NewDispatchService(service.DispatchServiceDependencies{
+		OrderRepo:   orderRepo,
+		RiderFinder: riderService,
+		Clock:       clock.Real(),
+		Config:      appCtx.Config.Dispatch,
+	})
+	orderService := service.NewOrderService(service.OrderServiceDependencies{
+		OrderRepo:    orderRepo,
+		MerchantRepo: merchantRepo,
+		EmployeeRepo: employeeRepo,
+		RiderRepo:    riderRepo,
+		Dispatcher:   dispatchService,
+		TxManager:    txManager,
+		CatalogRepo:  catalogRepo,
+	})
+	catalogService := service.NewCatalogService(service.CatalogServiceDependencies{
+		CatalogRepo:  catalogRepo,
+		MerchantRepo: merchantRepo,
+		EmployeeRepo: employeeRepo,
+	})
+	passwordResetService := service.NewPasswordResetService(service.PasswordResetServiceDependencies{
+		UserRepo:     userRepo,
+		EmployeeRepo: employeeRepo,
+		MerchantRepo: merchantRepo,
+		RiderRepo:    riderRepo,
+		SMSService:   smsService,
+		Mailer:       appCtx.Mailer,
+		TokenStore:   auth.NewRedisPasswordResetStore(appCtx.RedisClient),
+		JWTService:   jwtService,
+		Config:       appCtx.Config.PasswordReset,
+	})
+	adminService := service.NewAdminService(service.AdminServiceDependencies{
+		AdminRepo:    adminRepo,
+		AuditRepo:    auditLogRepo,
+		UserRepo:     userRepo,
+		MerchantRepo: merchantRepo,
+		RiderRepo:    riderRepo,
+		EmployeeRepo: employeeRepo,
+		JWTService:   jwtService,
+		LoginGuard:   loginGuard,
+		TxManager:    txManager,
+
+		PhoneLists: appCtx.SMSPhoneLists,
+	})
+	// Verification documents are private files; they are only served through authenticated handlers
+	merchantVerificationService := service.NewMerchantVerificationService(service.MerchantVerificationServiceDependencies{
+		MerchantRepo:  merchantRepo,
+		DocumentRepo:  verificationDocRepo,
+		AuditRepo:     auditLogRepo,
+		Storage:       storage.NewLocalStorage(appCtx.Config.Storage.LocalDir),
+		Notifier:      appCtx.SMSProvider,
+		TxManager:     txManager,
+		MaxUploadSize: appCtx.Config.Storage.MaxUploadSize,
+	})
+	riderVerificationService := service.NewRiderVerificationService(service.RiderVerificationServiceDependencies{
+		RiderRepo:     riderRepo,
+		DocumentRepo:  verificationDocRepo,
+		AuditRepo:     auditLogRepo,
+		Storage:       storage.NewLocalStorage(appCtx.Config.Storage.LocalDir),
+		Notifier:      appCtx.SMSProvider,
+		TxManager:     txManager,
+		MaxUploadSize: appCtx.Config.Storage.MaxUploadSize,
 	})
 
 	// Initialize handlers
 	authHandler := NewAuthHandler(userService, employeeService, merchantService, riderService)
 	merchantHandler := NewMerchantHandler(merchantService, employeeService)
 	riderHandler := NewRiderHandler(riderService)
+	orderHandler := NewOrderHandler(orderService)
+	catalogHandler := NewCatalogHandler(catalogService)
+	dispatchHandler := NewDispatchHandler(dispatchService)
+	qrLoginHandler := NewQRLoginHandler(authqr.NewStore(appCtx.RedisClient), jwtService)
+	tokenHandler := NewTokenHandler(jwtService)
+	passwordResetHandler := NewPasswordResetHandler(passwordResetService)
+	adminHandler := NewAdminHandler(AdminHandlerDependencies{
+		AdminService:    adminService,
+		UserService:     userService,
+		MerchantService: merchantService,
+		RiderService:    riderService,
+		EmployeeService: employeeService,
+
+		SMSProvider: appCtx.SMSProvider,
+	})
+	merchantVerificationHandler := NewMerchantVerificationHandler(merchantVerificationService, appCtx.Config.Storage.MaxUploadSize)
+	riderVerificationHandler := NewRiderVerificationHandler(riderVerificationService, appCtx.Config.Storage.MaxUploadSize)
+
+	var smsCallbackHandler *SMSCallbackHandler
+	if token := appCtx.Config.SMS.Queue.CallbackToken; appCtx.SMSSendLog != nil && token != "" {
+		smsCallbackHandler = NewSMSCallbackHandler(appCtx.SMSSendLog, token)
+	}
 
 	// Initialize middleware
-	jwtMiddleware := middleware.NewJWTMiddleware(jwtConfig)
+	jwtMiddleware := middleware.NewJWTMiddleware(jwtConfig, revocationStore)
+	merchantVerified := middleware.RequireVerified(merchantVerificationService.IsMerchantVerified)
+	permissions := middleware.NewPermissionMiddleware(employeeService.CheckPermission)
+	smsChallenge := middleware.RequireChallenge(appCtx.SMSChallenge)
 
 	return &RouterDependencies{
-		AuthHandler:     authHandler,
-		MerchantHandler: merchantHandler,
-		RiderHandler:    riderHandler,
-		JWTMiddleware:   jwtMiddleware,
+		AuthHandler:          authHandler,
+		MerchantHandler:      merchantHandler,
+		RiderHandler:         riderHandler,
+		OrderHandler:         orderHandler,
+		CatalogHandler:       catalogHandler,
+		DispatchHandler:      dispatchHandler,
+		QRLoginHandler:       qrLoginHandler,
+		TokenHandler:         tokenHandler,
+		PasswordResetHandler: passwordResetHandler,
+		AdminHandler:         adminHandler,
+		JWTMiddleware:        jwtMiddleware,
+
+		MerchantVerificationHandler: merchantVerificationHandler,
+		MerchantVerified:            merchantVerified,
+
+		RiderVerificationHandler: riderVerificationHandler,
+
+		Permissions: permissions,
+
+		SMSCallbackHandler: smsCallbackHandler,
+		SMSChallenge:       smsChallenge,
 	}
 }
 
+// setupWellKnownRoutes configures public discovery routes outside the versioned API
+func setupWellKnownRoutes(router *gin.Engine, deps *RouterDependencies) {
+	router.GET("/.well-known/jwks.json", deps.TokenHandler.JWKSHandler)
+}
+
 // setupSwaggerRoutes configures Swagger documentation routes
 func setupSwaggerRoutes(router *gin.Engine) {
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -112,7 +300,10 @@ func setupPublicRoutes(v1 *gin.RouterGroup, deps *RouterDependencies) (*gin.Rout
 	{
 		userGroup.POST("/register", deps.AuthHandler.RegisterHandler("user"))
 		userGroup.POST("/login", deps.AuthHandler.LoginHandler("user"))
-		userGroup.POST("/sms/send", deps.AuthHandler.SendSMSCodeHandler)
+		userGroup.POST("/password/forgot", deps.SMSChallenge, deps.PasswordResetHandler.ForgotPasswordHandler("user"))
+		userGroup.POST("/password/verify", deps.PasswordResetHandler.VerifyPasswordResetHandler("user"))
+		userGroup.POST("/password/reset", deps.PasswordResetHandler.ResetPasswordHandler("user"))
+		userGroup.POST("/sms/send", deps.SMSChallenge, deps.AuthHandler.SendSMSCodeHandler)
 		userGroup.POST("/sms/verify", deps.AuthHandler.VerifySMSCodeHandler)
 		userGroup.POST("/sms/can-send", deps.AuthHandler.CanSendSMSCodeHandler)
 	}
@@ -122,6 +313,9 @@ func setupPublicRoutes(v1 *gin.RouterGroup, deps *RouterDependencies) (*gin.Rout
 	{
 		employeeGroup.POST("/register", deps.AuthHandler.RegisterHandler("employee"))
 		employeeGroup.POST("/login", deps.AuthHandler.LoginHandler("employee"))
+		employeeGroup.POST("/password/forgot", deps.SMSChallenge, deps.PasswordResetHandler.ForgotPasswordHandler("employee"))
+		employeeGroup.POST("/password/verify", deps.PasswordResetHandler.VerifyPasswordResetHandler("employee"))
+		employeeGroup.POST("/password/reset", deps.PasswordResetHandler.ResetPasswordHandler("employee"))
 	}
 
 	// Rider routes
@@ -129,7 +323,10 @@ func setupPublicRoutes(v1 *gin.RouterGroup, deps *RouterDependencies) (*gin.Rout
 	{
 		riderGroup.POST("/register", deps.AuthHandler.RegisterHandler("rider"))
 		riderGroup.POST("/login", deps.AuthHandler.LoginHandler("rider"))
-		riderGroup.POST("/sms/send", deps.AuthHandler.SendRiderSMSCodeHandler)
+		riderGroup.POST("/password/forgot", deps.SMSChallenge, deps.PasswordResetHandler.ForgotPasswordHandler("rider"))
+		riderGroup.POST("/password/verify", deps.PasswordResetHandler.VerifyPasswordResetHandler("rider"))
+		riderGroup.POST("/password/reset", deps.PasswordResetHandler.ResetPasswordHandler("rider"))
+		riderGroup.POST("/sms/send", deps.SMSChallenge, deps.AuthHandler.SendRiderSMSCodeHandler)
 		riderGroup.POST("/sms/verify", deps.AuthHandler.VerifyRiderSMSCodeHandler)
 		riderGroup.POST("/sms/can-send", deps.AuthHandler.CanSendRiderSMSCodeHandler)
 	}
@@ -139,9 +336,13 @@ func setupPublicRoutes(v1 *gin.RouterGroup, deps *RouterDependencies) (*gin.Rout
 	{
 		merchantGroup.POST("/register", deps.AuthHandler.RegisterHandler("merchant"))
 		merchantGroup.POST("/login", deps.AuthHandler.LoginHandler("merchant"))
-		merchantGroup.POST("/sms/send", deps.AuthHandler.SendMerchantSMSCodeHandler)
+		merchantGroup.POST("/password/forgot", deps.SMSChallenge, deps.PasswordResetHandler.ForgotPasswordHandler("merchant"))
+		merchantGroup.POST("/password/verify", deps.PasswordResetHandler.VerifyPasswordResetHandler("merchant"))
+		merchantGroup.POST("/password/reset", deps.PasswordResetHandler.ResetPasswordHandler("merchant"))
+		merchantGroup.POST("/sms/send", deps.SMSChallenge, deps.AuthHandler.SendMerchantSMSCodeHandler)
 		merchantGroup.POST("/sms/verify", deps.AuthHandler.VerifyMerchantSMSCodeHandler)
 		merchantGroup.POST("/sms/can-send", deps.AuthHandler.CanSendMerchantSMSCodeHandler)
+		merchantGroup.GET("/:id/menu", deps.CatalogHandler.GetPublicMenuHandler)
 	}
 
 	return userGroup, employeeGroup, riderGroup, merchantGroup
@@ -150,56 +351,249 @@ func setupPublicRoutes(v1 *gin.RouterGroup, deps *RouterDependencies) (*gin.Rout
 // setupUserProtectedRoutes configures user-specific protected routes
 func setupUserProtectedRoutes(userGroup *gin.RouterGroup, deps *RouterDependencies) {
 	usersAuth := userGroup.Group("")
-	usersAuth.Use(deps.JWTMiddleware.AuthMiddleware())
+	usersAuth.Use(deps.JWTMiddleware.AuthMiddleware(), deps.JWTMiddleware.RequireUserType("user"))
 	{
 		usersAuth.GET("/profile", deps.AuthHandler.GetProfileHandler("user"))
+
+		// Orders
+		usersAuth.POST("/orders", deps.OrderHandler.CreateOrderHandler)
+		usersAuth.GET("/orders", deps.OrderHandler.ListOrdersHandler)
+		usersAuth.GET("/orders/:id", deps.OrderHandler.GetOrderHandler)
+		usersAuth.POST("/orders/:id/cancel", deps.OrderHandler.CancelOrderHandler)
+		usersAuth.POST("/orders/:id/rate", deps.OrderHandler.RateOrderHandler)
 	}
 }
 
 // setupEmployeeProtectedRoutes configures employee-specific protected routes
+// Each business route requires a permission granted by the employee's role within its merchant;
+// the permission check also rejects employees of inactive or unverified merchants
 func setupEmployeeProtectedRoutes(employeeGroup *gin.RouterGroup, deps *RouterDependencies) {
 	employeesAuth := employeeGroup.Group("")
-	employeesAuth.Use(deps.JWTMiddleware.AuthMiddleware())
+	employeesAuth.Use(deps.JWTMiddleware.AuthMiddleware(), deps.JWTMiddleware.RequireUserType("employee"))
 	{
 		employeesAuth.GET("/profile", deps.AuthHandler.GetProfileHandler("employee"))
+
+		// Orders of the employee's merchant
+		orderView := deps.Permissions.RequirePermission(model.PermOrderView)
+		orderAccept := deps.Permissions.RequirePermission(model.PermOrderAccept)
+		orderCancel := deps.Permissions.RequirePermission(model.PermOrderCancel)
+		orderPrepare := deps.Permissions.RequirePermission(model.PermOrderPrepare)
+		employeesAuth.GET("/orders", orderView, deps.OrderHandler.ListOrdersHandler)
+		employeesAuth.GET("/orders/:id", orderView, deps.OrderHandler.GetOrderHandler)
+		employeesAuth.POST("/orders/:id/accept", orderAccept, deps.OrderHandler.AcceptOrderHandler)
+		employeesAuth.POST("/orders/:id/prepared", orderPrepare, deps.OrderHandler.PrepareOrderHandler)
+		employeesAuth.POST("/orders/:id/dispatch", orderPrepare, deps.OrderHandler.DispatchOrderHandler)
+		employeesAuth.POST("/orders/:id/cancel", orderCancel, deps.OrderHandler.CancelOrderHandler)
+
+		// Catalog of the employee's merchant
+		menuEdit := deps.Permissions.RequirePermission(model.PermMenuEdit)
+		employeesAuth.GET("/menu/items", deps.Permissions.RequirePermission(model.PermMenuView), deps.CatalogHandler.ListItemsHandler)
+		employeesAuth.PUT("/menu/items/:id/availability", deps.Permissions.RequirePermission(model.PermMenuAvailability), deps.CatalogHandler.SetItemAvailabilityHandler)
+		employeesAuth.GET("/menu/categories", menuEdit, deps.CatalogHandler.ListCategoriesHandler)
+		employeesAuth.POST("/menu/categories", menuEdit, deps.CatalogHandler.CreateCategoryHandler)
+		employeesAuth.PUT("/menu/categories/:id", menuEdit, deps.CatalogHandler.UpdateCategoryHandler)
+		employeesAuth.DELETE("/menu/categories/:id", menuEdit, deps.CatalogHandler.DeleteCategoryHandler)
+		employeesAuth.POST("/menu/items", menuEdit, deps.CatalogHandler.CreateItemHandler)
+		employeesAuth.GET("/menu/items/:id", menuEdit, deps.CatalogHandler.GetItemHandler)
+		employeesAuth.PUT("/menu/items/:id", menuEdit, deps.CatalogHandler.UpdateItemHandler)
+		employeesAuth.DELETE("/menu/items/:id", menuEdit, deps.CatalogHandler.DeleteItemHandler)
+		employeesAuth.PUT("/menu/items/:id/stock", menuEdit, deps.CatalogHandler.SetItemStockHandler)
+
+		// Staff of the employee's merchant
+		employeeManage := deps.Permissions.RequirePermission(model.PermEmployeeManage)
+		employeesAuth.GET("/staff", employeeManage, deps.MerchantHandler.GetEmployeesHandler)
+		employeesAuth.POST("/staff", employeeManage, deps.AuthHandler.AddEmployeeHandler())
+		employeesAuth.PUT("/staff/:id/role", employeeManage, deps.MerchantHandler.SetEmployeeRoleHandler)
 	}
 }
 
 // setupRiderProtectedRoutes configures rider-specific protected routes
 func setupRiderProtectedRoutes(riderGroup *gin.RouterGroup, deps *RouterDependencies) {
 	ridersAuth := riderGroup.Group("")
-	ridersAuth.Use(deps.JWTMiddleware.AuthMiddleware())
+	ridersAuth.Use(deps.JWTMiddleware.AuthMiddleware(), deps.JWTMiddleware.RequireUserType("rider"))
 	{
 		// Common routes (unified handler)
 		ridersAuth.GET("/profile", deps.AuthHandler.GetProfileHandler("rider"))
 
+		// Onboarding verification; going online is refused until approved
+		ridersAuth.GET("/verification", deps.RiderVerificationHandler.GetVerificationHandler)
+		ridersAuth.PUT("/verification/application", deps.RiderVerificationHandler.UpdateApplicationHandler)
+		ridersAuth.POST("/verification/documents", deps.RiderVerificationHandler.UploadDocumentHandler)
+		ridersAuth.GET("/verification/documents/:id", deps.RiderVerificationHandler.GetDocumentHandler)
+		ridersAuth.DELETE("/verification/documents/:id", deps.RiderVerificationHandler.DeleteDocumentHandler)
+		ridersAuth.POST("/verification/submit", deps.RiderVerificationHandler.SubmitVerificationHandler)
+
 		// Rider-specific business routes (specialized handler)
 		ridersAuth.PUT("/online-status", deps.RiderHandler.UpdateOnlineStatusHandler)
 		ridersAuth.PUT("/location", deps.RiderHandler.UpdateLocationHandler)
+
+		// Orders
+		ridersAuth.GET("/orders", deps.OrderHandler.ListOrdersHandler)
+		ridersAuth.GET("/orders/:id", deps.OrderHandler.GetOrderHandler)
+		ridersAuth.POST("/orders/:id/pickup", deps.OrderHandler.PickUpOrderHandler)
+		ridersAuth.POST("/orders/:id/deliver", deps.OrderHandler.DeliverOrderHandler)
+
+		// Dispatch offers
+		ridersAuth.GET("/dispatch/offers", deps.DispatchHandler.ListOffersHandler)
+		ridersAuth.POST("/dispatch/offers/:order_id/accept", deps.DispatchHandler.AcceptOfferHandler)
+		ridersAuth.POST("/dispatch/offers/:order_id/decline", deps.DispatchHandler.DeclineOfferHandler)
 	}
 }
 
 // setupMerchantProtectedRoutes configures merchant-specific protected routes
 func setupMerchantProtectedRoutes(merchantGroup *gin.RouterGroup, deps *RouterDependencies) {
 	merchantsAuth := merchantGroup.Group("")
-	merchantsAuth.Use(deps.JWTMiddleware.AuthMiddleware())
+	merchantsAuth.Use(deps.JWTMiddleware.AuthMiddleware(), deps.JWTMiddleware.RequireUserType("merchant"))
 	{
 		// Common routes (unified handler)
 		merchantsAuth.GET("/profile", deps.AuthHandler.GetProfileHandler("merchant"))
+		merchantsAuth.PUT("/store", deps.MerchantHandler.UpdateStoreLocationHandler)
+
+		// KYC verification (available before approval)
+		merchantsAuth.GET("/verification", deps.MerchantVerificationHandler.GetVerificationHandler)
+		merchantsAuth.PUT("/verification/application", deps.MerchantVerificationHandler.UpdateApplicationHandler)
+		merchantsAuth.POST("/verification/documents", deps.MerchantVerificationHandler.UploadDocumentHandler)
+		merchantsAuth.GET("/verification/documents/:id", deps.MerchantVerificationHandler.GetDocumentHandler)
+		merchantsAuth.DELETE("/verification/documents/:id", deps.MerchantVerificationHandler.DeleteDocumentHandler)
+		merchantsAuth.POST("/verification/submit", deps.MerchantVerificationHandler.SubmitVerificationHandler)
+	}
 
+	// Business routes require an approved KYC verification
+	merchantsVerified := merchantsAuth.Group("")
+	merchantsVerified.Use(deps.MerchantVerified)
+	{
 		// Merchant-specific business routes (specialized handlers)
-		merchantsAuth.POST("/employees", deps.AuthHandler.AddEmployeeHandler())
-		merchantsAuth.GET("/employees", deps.MerchantHandler.GetEmployeesHandler)
+		employeeManage := deps.Permissions.RequirePermission(model.PermEmployeeManage)
+		merchantsVerified.POST("/employees", employeeManage, deps.AuthHandler.AddEmployeeHandler())
+		merchantsVerified.GET("/employees", employeeManage, deps.MerchantHandler.GetEmployeesHandler)
+		merchantsVerified.PUT("/employees/:id/role", employeeManage, deps.MerchantHandler.SetEmployeeRoleHandler)
+
+		// Orders
+		merchantsVerified.GET("/orders", deps.OrderHandler.ListOrdersHandler)
+		merchantsVerified.GET("/orders/:id", deps.OrderHandler.GetOrderHandler)
+		merchantsVerified.POST("/orders/:id/accept", deps.OrderHandler.AcceptOrderHandler)
+		merchantsVerified.POST("/orders/:id/prepared", deps.OrderHandler.PrepareOrderHandler)
+		merchantsVerified.POST("/orders/:id/dispatch", deps.OrderHandler.DispatchOrderHandler)
+		merchantsVerified.POST("/orders/:id/cancel", deps.OrderHandler.CancelOrderHandler)
+
+		// Catalog
+		merchantsVerified.GET("/menu/categories", deps.CatalogHandler.ListCategoriesHandler)
+		merchantsVerified.POST("/menu/categories", deps.CatalogHandler.CreateCategoryHandler)
+		merchantsVerified.PUT("/menu/categories/:id", deps.CatalogHandler.UpdateCategoryHandler)
+		merchantsVerified.DELETE("/menu/categories/:id", deps.CatalogHandler.DeleteCategoryHandler)
+		merchantsVerified.GET("/menu/items", deps.CatalogHandler.ListItemsHandler)
+		merchantsVerified.POST("/menu/items", deps.CatalogHandler.CreateItemHandler)
+		merchantsVerified.GET("/menu/items/:id", deps.CatalogHandler.GetItemHandler)
+		merchantsVerified.PUT("/menu/items/:id", deps.CatalogHandler.UpdateItemHandler)
+		merchantsVerified.DELETE("/menu/items/:id", deps.CatalogHandler.DeleteItemHandler)
+		merchantsVerified.PUT("/menu/items/:id/stock", deps.CatalogHandler.SetItemStockHandler)
+		merchantsVerified.PUT("/menu/items/:id/availability", deps.CatalogHandler.SetItemAvailabilityHandler)
+	}
+}
+
+// setupTokenRoutes configures token lifecycle routes
+// Refresh is public (authenticated by the refresh token); logout accepts an access token of any user type
+func setupTokenRoutes(v1 *gin.RouterGroup, deps *RouterDependencies) {
+	authGroup := v1.Group("/auth")
+	{
+		authGroup.POST("/refresh", deps.TokenHandler.RefreshHandler)
+	}
+
+	authProtected := authGroup.Group("")
+	authProtected.Use(deps.JWTMiddleware.AuthMiddleware())
+	{
+		authProtected.POST("/logout", deps.TokenHandler.LogoutHandler)
+		authProtected.POST("/logout-all", deps.TokenHandler.LogoutAllHandler)
+	}
+}
+
+// setupQRLoginRoutes configures QR-code login routes
+// Desktop endpoints are public; mobile endpoints accept a token of any user type
+func setupQRLoginRoutes(v1 *gin.RouterGroup, deps *RouterDependencies) {
+	qrGroup := v1.Group("/auth/qr")
+	{
+		qrGroup.POST("/tickets", deps.QRLoginHandler.CreateTicketHandler)
+		qrGroup.GET("/tickets/:id", deps.QRLoginHandler.PollTicketHandler)
+	}
+
+	qrMobile := qrGroup.Group("/tickets/:id")
+	qrMobile.Use(deps.JWTMiddleware.AuthMiddleware())
+	{
+		qrMobile.POST("/scan", deps.QRLoginHandler.ScanTicketHandler)
+		qrMobile.POST("/confirm", deps.QRLoginHandler.ConfirmTicketHandler)
+		qrMobile.POST("/reject", deps.QRLoginHandler.RejectTicketHandler)
+	}
+}
+
+// setupSMSCallbackRoutes configures vendor delivery-receipt callbacks
+// Vendors cannot send our JWTs, so the endpoint is authenticated by the token in its URL
+func setupSMSCallbackRoutes(v1 *gin.RouterGroup, deps *RouterDependencies) {
+	if deps.SMSCallbackHandler == nil {
+		return
+	}
+	v1.POST("/sms/callbacks/:provider", deps.SMSCallbackHandler.DeliveryReportHandler)
+}
+
+// setupAdminRoutes configures the back-office API
+// Login is public; everything else requires an admin token
+func setupAdminRoutes(v1 *gin.RouterGroup, deps *RouterDependencies) {
+	adminGroup := v1.Group("/admin")
+	{
+		adminGroup.POST("/login", deps.AdminHandler.LoginHandler)
+	}
+
+	adminAuth := adminGroup.Group("")
+	adminAuth.Use(deps.JWTMiddleware.AuthMiddleware(), deps.JWTMiddleware.RequireUserType("admin"))
+	{
+		adminAuth.GET("/profile", deps.AdminHandler.GetProfileHandler)
+
+		// Accounts
+		for _, accountType := range []string{"user", "merchant", "rider", "employee"} {
+			path := "/" + accountType + "s"
+			adminAuth.GET(path, deps.AdminHandler.ListAccountsHandler(accountType))
+			adminAuth.PUT(path+"/:id/status", deps.AdminHandler.SetAccountStatusHandler(accountType))
+			adminAuth.GET("/stats"+path, deps.AdminHandler.StatsHandler(accountType))
+		}
+		adminAuth.GET("/stats/merchants/top", deps.AdminHandler.TopMerchantsHandler)
+		adminAuth.GET("/stats/riders/top", deps.AdminHandler.TopRidersHandler)
+		adminAuth.GET("/stats/sms-providers", deps.AdminHandler.SMSProviderStatsHandler)
+		adminAuth.GET("/riders/nearby", deps.AdminHandler.NearbyRidersHandler)
+
+		// SMS blocklist / allowlist
+		adminAuth.GET("/sms/phone-lists/:list", deps.AdminHandler.ListSMSPhonesHandler)
+		adminAuth.PUT("/sms/phone-lists/:list/:phone", deps.AdminHandler.SetSMSPhoneListedHandler(true))
+		adminAuth.DELETE("/sms/phone-lists/:list/:phone", deps.AdminHandler.SetSMSPhoneListedHandler(false))
+
+		// Merchant KYC review
+		adminAuth.GET("/merchant-verifications", deps.MerchantVerificationHandler.ListVerificationsHandler)
+		adminAuth.GET("/merchants/:id/verification", deps.MerchantVerificationHandler.GetMerchantVerificationHandler)
+		adminAuth.GET("/merchants/:id/verification/documents/:doc_id", deps.MerchantVerificationHandler.GetMerchantDocumentHandler)
+		adminAuth.POST("/merchants/:id/verification/review", deps.MerchantVerificationHandler.StartReviewHandler)
+		adminAuth.POST("/merchants/:id/verification/approve", deps.MerchantVerificationHandler.ApproveHandler)
+		adminAuth.POST("/merchants/:id/verification/reject", deps.MerchantVerificationHandler.RejectHandler)
+
+		// Rider onboarding review
+		adminAuth.GET("/rider-verifications", deps.RiderVerificationHandler.ListVerificationsHandler)
+		adminAuth.GET("/riders/:id/verification", deps.RiderVerificationHandler.GetRiderVerificationHandler)
+		adminAuth.GET("/riders/:id/verification/documents/:doc_id", deps.RiderVerificationHandler.GetRiderDocumentHandler)
+		adminAuth.POST("/riders/:id/verification/review", deps.RiderVerificationHandler.StartReviewHandler)
+		adminAuth.POST("/riders/:id/verification/approve", deps.RiderVerificationHandler.ApproveHandler)
+		adminAuth.POST("/riders/:id/verification/reject", deps.RiderVerificationHandler.RejectHandler)
+
+		// Audit log
+		adminAuth.GET("/audit-logs", deps.AdminHandler.ListAuditLogsHandler)
 	}
 }
 
 // SetupRouter creates a new Gin router and sets up all routes
 // NewRouter creates a new router with dependency injection
-func NewRouter(appCtx *app.AppContext) *gin.Engine {
+func NewRouter(appCtx *app.AppContext) (*gin.Engine, error) {
 	router := gin.Default()
 
 	// Setup middleware
-	setupMiddleware(router, appCtx)
+	if err := setupMiddleware(router, appCtx); err != nil {
+		return nil, err
+	}
 
 	// Initialize all dependencies
 	deps := initializeDependencies(appCtx)
@@ -207,6 +601,7 @@ func NewRouter(appCtx *app.AppContext) *gin.Engine {
 	// Setup API group and Swagger
 	v1 := router.Group("/api/v1")
 	setupSwaggerRoutes(router)
+	setupWellKnownRoutes(router, deps)
 
 	// Setup public routes
 	userGroup, employeeGroup, riderGroup, merchantGroup := setupPublicRoutes(v1, deps)
@@ -217,5 +612,17 @@ func NewRouter(appCtx *app.AppContext) *gin.Engine {
 	setupRiderProtectedRoutes(riderGroup, deps)
 	setupMerchantProtectedRoutes(merchantGroup, deps)
 
-	return router
+	// Setup token refresh routes
+	setupTokenRoutes(v1, deps)
+
+	// Setup QR-code login routes
+	setupQRLoginRoutes(v1, deps)
+
+	// Setup back-office routes
+	setupAdminRoutes(v1, deps)
+
+	// Setup SMS vendor callbacks
+	setupSMSCallbackRoutes(v1, deps)
+
+	return router, nil
 }
@@ -0,0 +1,372 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/app"
+	"github.com/Hermitf/the-pass/internal/config"
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/Hermitf/the-pass/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+var testJWTConfig = auth.JWTConfig{SecretKey: "test-secret", ExpiresIn: 3600}
+
+// fakeEmployeeService 仅覆盖测试涉及的方法，其余方法调用会 panic
+type fakeEmployeeService struct {
+	service.EmployeeServiceInterface
+	gotMerchantID int64
+	loginIPs      []string
+}
+
+func (f *fakeEmployeeService) GetEmployeesByMerchantID(ctx context.Context, merchantID int64) ([]*model.Employee, error) {
+	f.gotMerchantID = merchantID
+	return []*model.Employee{{ID: 1, MerchantID: merchantID}}, nil
+}
+
+func (f *fakeEmployeeService) GetEmployeeByID(ctx context.Context, id int64) (*model.Employee, error) {
+	return &model.Employee{ID: id}, nil
+}
+
+func (f *fakeEmployeeService) LoginEmployee(ctx context.Context, loginInfo, password, loginType, clientIP string) (*service.TokenPair, error) {
+	f.loginIPs = append(f.loginIPs, clientIP)
+	return nil, &service.LoginLockedError{RetryAfter: 90*time.Second + time.Millisecond}
+}
+
+// fakeRiderService 附近配送员查询固定返回两名配送员，并记录查询参数
+type fakeRiderService struct {
+	service.RiderServiceInterface
+	gotRadiusKm float64
+	gotLimit    int
+}
+
+func (f *fakeRiderService) GetRidersNearLocation(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error) {
+	if radiusKm <= 0 {
+		return nil, service.ErrRadiusInvalid
+	}
+	f.gotRadiusKm, f.gotLimit = radiusKm, limit
+	return []*model.NearbyRider{
+		{Rider: &model.Rider{ID: 2, Username: "near"}, DistanceKm: 0.5},
+		{Rider: &model.Rider{ID: 1, Username: "far"}, DistanceKm: 2.25},
+	}, nil
+}
+
+// employeeRoles 按给定员工的角色与在职状态校验权限（未列出的员工视为不存在）
+func employeeRoles(employees ...*model.Employee) *middleware.PermissionMiddleware {
+	return middleware.NewPermissionMiddleware(func(ctx context.Context, employeeID int64, permission string) (int64, bool, error) {
+		for _, employee := range employees {
+			if employee.ID == employeeID {
+				return employee.MerchantID, employee.HasPermission(permission), nil
+			}
+		}
+		return 0, false, nil
+	})
+}
+
+// verifiedMerchants 资质审核已通过的商家（其余商家视为未审核）
+func verifiedMerchants(ids ...int64) gin.HandlerFunc {
+	return middleware.RequireVerified(func(ctx context.Context, merchantID int64) (bool, error) {
+		for _, id := range ids {
+			if id == merchantID {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+func newTestRouter(t *testing.T) (*gin.Engine, *fakeEmployeeService) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	employeeService := &fakeEmployeeService{}
+	deps := &RouterDependencies{
+		AuthHandler:     NewAuthHandler(nil, employeeService, nil, nil),
+		MerchantHandler: NewMerchantHandler(nil, employeeService),
+		RiderHandler:    NewRiderHandler(nil),
+		JWTMiddleware:   middleware.NewJWTMiddleware(testJWTConfig, nil),
+
+		MerchantVerified: verifiedMerchants(5),
+
+		Permissions: employeeRoles(
+			&model.Employee{ID: 7, MerchantID: 5, Role: model.EmployeeRoleOwner, IsActive: true},
+			&model.Employee{ID: 8, MerchantID: 5, Role: model.EmployeeRoleKitchen, IsActive: true},
+		),
+	}
+
+	router := gin.New()
+	v1 := router.Group("/api/v1")
+	setupUserProtectedRoutes(v1.Group("/users"), deps)
+	setupEmployeeProtectedRoutes(v1.Group("/employees"), deps)
+	setupRiderProtectedRoutes(v1.Group("/riders"), deps)
+	setupMerchantProtectedRoutes(v1.Group("/merchants"), deps)
+	return router, employeeService
+}
+
+func issueToken(t *testing.T, userID int64, userType string) string {
+	t.Helper()
+	token, err := auth.GenerateToken(userID, userType, testJWTConfig)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	return token
+}
+
+func doRequest(router *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestProtectedRoutes_RejectMismatchedUserType(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	cases := []struct {
+		name     string
+		method   string
+		path     string
+		userType string
+	}{
+		{"rider token on merchant employees", http.MethodGet, "/api/v1/merchants/employees", "rider"},
+		{"user token on merchant add employee", http.MethodPost, "/api/v1/merchants/employees", "user"},
+		{"merchant token on rider location", http.MethodPut, "/api/v1/riders/location", "merchant"},
+		{"employee token on rider online status", http.MethodPut, "/api/v1/riders/online-status", "employee"},
+		{"rider token on user profile", http.MethodGet, "/api/v1/users/profile", "rider"},
+		{"merchant token on employee profile", http.MethodGet, "/api/v1/employees/profile", "merchant"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := doRequest(router, tc.method, tc.path, issueToken(t, 5, tc.userType))
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("status=%d want %d, body=%s", w.Code, http.StatusForbidden, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestProtectedRoutes_AllowMatchingUserType(t *testing.T) {
+	router, employeeService := newTestRouter(t)
+
+	w := doRequest(router, http.MethodGet, "/api/v1/merchants/employees", issueToken(t, 5, "merchant"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("merchant employees status=%d want 200, body=%s", w.Code, w.Body.String())
+	}
+	if employeeService.gotMerchantID != 5 {
+		t.Fatalf("merchantID=%d want 5", employeeService.gotMerchantID)
+	}
+
+	w = doRequest(router, http.MethodGet, "/api/v1/employees/profile", issueToken(t, 7, "employee"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("employee profile status=%d want 200, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestProtectedRoutes_RequireVerifiedMerchant(t *testing.T) {
+	router, employeeService := newTestRouter(t)
+
+	w := doRequest(router, http.MethodGet, "/api/v1/merchants/employees", issueToken(t, 6, "merchant"))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("unverified merchant status=%d want 403, body=%s", w.Code, w.Body.String())
+	}
+	if employeeService.gotMerchantID != 0 {
+		t.Fatalf("handler should not run for unverified merchant")
+	}
+}
+
+func TestProtectedRoutes_EmployeePermissions(t *testing.T) {
+	router, employeeService := newTestRouter(t)
+
+	w := doRequest(router, http.MethodGet, "/api/v1/employees/staff", issueToken(t, 8, "employee"))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("kitchen staff list status=%d want 403, body=%s", w.Code, w.Body.String())
+	}
+	if employeeService.gotMerchantID != 0 {
+		t.Fatalf("handler should not run without employee.manage")
+	}
+
+	// 员工操作的是其所属商家，而不是令牌中的员工ID
+	w = doRequest(router, http.MethodGet, "/api/v1/employees/staff", issueToken(t, 7, "employee"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("owner staff list status=%d want 200, body=%s", w.Code, w.Body.String())
+	}
+	if employeeService.gotMerchantID != 5 {
+		t.Fatalf("merchantID=%d want 5", employeeService.gotMerchantID)
+	}
+}
+
+func TestProtectedRoutes_EmployeeOrderAndMenuPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 取消订单使用独立的 order.cancel 权限；商品列表同样需要 menu.view
+	cases := []struct {
+		method     string
+		path       string
+		permission string
+	}{
+		{http.MethodPost, "/api/v1/employees/orders/1/cancel", model.PermOrderCancel},
+		{http.MethodGet, "/api/v1/employees/menu/items", model.PermMenuView},
+	}
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			var checked []string
+			deps := &RouterDependencies{
+				JWTMiddleware: middleware.NewJWTMiddleware(testJWTConfig, nil),
+				Permissions: middleware.NewPermissionMiddleware(func(ctx context.Context, employeeID int64, permission string) (int64, bool, error) {
+					checked = append(checked, permission)
+					return 5, false, nil
+				}),
+			}
+			router := gin.New()
+			setupEmployeeProtectedRoutes(router.Group("/api/v1/employees"), deps)
+
+			w := doRequest(router, tc.method, tc.path, issueToken(t, 9, "employee"))
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("status=%d want 403, body=%s", w.Code, w.Body.String())
+			}
+			if len(checked) != 1 || checked[0] != tc.permission {
+				t.Fatalf("checked=%v want [%s]", checked, tc.permission)
+			}
+		})
+	}
+}
+
+func TestProtectedRoutes_RequireToken(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	w := doRequest(router, http.MethodGet, "/api/v1/merchants/employees", "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status=%d want 401", w.Code)
+	}
+
+	w = doRequest(router, http.MethodGet, "/api/v1/merchants/employees", "not-a-jwt")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status=%d want 401", w.Code)
+	}
+}
+
+func TestPublicRoutes_PasswordForgotRequiresSMSChallenge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var challenged []string
+	deps := &RouterDependencies{
+		SMSChallenge: func(c *gin.Context) {
+			challenged = append(challenged, c.FullPath())
+			c.AbortWithStatus(http.StatusPreconditionRequired)
+		},
+	}
+	router := gin.New()
+	setupPublicRoutes(router.Group("/api/v1"), deps)
+
+	for _, group := range []string{"users", "employees", "riders", "merchants"} {
+		path := "/api/v1/" + group + "/password/forgot"
+		w := doJSONRequest(router, http.MethodPost, path, "", ForgotPasswordRequest{Channel: "sms", Identifier: "13800138000"})
+		if w.Code != http.StatusPreconditionRequired {
+			t.Fatalf("%s status=%d want 428", path, w.Code)
+		}
+	}
+	if len(challenged) != 4 {
+		t.Fatalf("challenged routes=%v want all four password/forgot routes", challenged)
+	}
+}
+
+func TestAdminRoutes_NearbyRidersIncludeDistance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	riderService := &fakeRiderService{}
+	deps := &RouterDependencies{
+		AdminHandler:  NewAdminHandler(AdminHandlerDependencies{RiderService: riderService}),
+		JWTMiddleware: middleware.NewJWTMiddleware(testJWTConfig, nil),
+	}
+	router := gin.New()
+	setupAdminRoutes(router.Group("/api/v1"), deps)
+	token := issueToken(t, 1, "admin")
+
+	w := doRequest(router, http.MethodGet, "/api/v1/admin/riders/nearby?lat=39.9&lng=116.4&radius_km=3&limit=5", token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d want 200, body=%s", w.Code, w.Body.String())
+	}
+	var got []NearbyRiderResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 2 || got[0].Rider.ID != 2 || got[0].DistanceKm != 0.5 || got[1].DistanceKm != 2.25 {
+		t.Fatalf("unexpected nearby riders: %s", w.Body.String())
+	}
+	if riderService.gotRadiusKm != 3 || riderService.gotLimit != 5 {
+		t.Fatalf("radius=%v limit=%d want 3/5", riderService.gotRadiusKm, riderService.gotLimit)
+	}
+
+	for _, query := range []string{"lat=39.9&lng=116.4", "lat=x&lng=116.4&radius_km=3", "lat=39.9&lng=116.4&radius_km=-1"} {
+		if w := doRequest(router, http.MethodGet, "/api/v1/admin/riders/nearby?"+query, token); w.Code != http.StatusBadRequest {
+			t.Errorf("%s status=%d want 400", query, w.Code)
+		}
+	}
+	if w := doRequest(router, http.MethodGet, "/api/v1/admin/riders/nearby?lat=39.9&lng=116.4&radius_km=3", issueToken(t, 1, "rider")); w.Code != http.StatusForbidden {
+		t.Fatalf("rider token status=%d want 403", w.Code)
+	}
+}
+
+func TestLoginHandler_LockedOutReturnsRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/login", NewAuthHandler(nil, &fakeEmployeeService{}, nil, nil).LoginHandler("employee"))
+
+	w := doJSONRequest(router, http.MethodPost, "/login", "", LoginRequest{LoginInfo: "alice", Password: "secret"})
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status=%d want 429, body=%s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Retry-After"); got != "91" {
+		t.Fatalf("Retry-After=%q want 91", got)
+	}
+}
+
+func TestSetupMiddleware_ClientIPIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 登录失败计数按 clientIP 分桶：伪造的 X-Forwarded-For 不能换出新的计数桶
+	login := func(trustedProxies []string, forwardedFor string) string {
+		t.Helper()
+		appCtx := &app.AppContext{Config: &config.Configuration{}}
+		appCtx.Config.Server.CORS.AllowedOrigins = []string{"http://localhost"}
+		appCtx.Config.Server.TrustedProxies = trustedProxies
+		router := gin.New()
+		if err := setupMiddleware(router, appCtx); err != nil {
+			t.Fatalf("setupMiddleware: %v", err)
+		}
+		employeeService := &fakeEmployeeService{}
+		router.POST("/login", NewAuthHandler(nil, employeeService, nil, nil).LoginHandler("employee"))
+
+		raw, _ := json.Marshal(LoginRequest{LoginInfo: "alice", Password: "secret"})
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.RemoteAddr = "192.0.2.10:40000"
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		if len(employeeService.loginIPs) != 1 {
+			t.Fatalf("login attempts=%v want 1", employeeService.loginIPs)
+		}
+		return employeeService.loginIPs[0]
+	}
+
+	for _, spoofed := range []string{"198.51.100.1", "198.51.100.2"} {
+		if ip := login(nil, spoofed); ip != "192.0.2.10" {
+			t.Fatalf("untrusted proxy clientIP=%s want 192.0.2.10", ip)
+		}
+	}
+	if ip := login([]string{"192.0.2.0/24"}, "198.51.100.1"); ip != "198.51.100.1" {
+		t.Fatalf("trusted proxy clientIP=%s want 198.51.100.1", ip)
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+
+	"github.com/Hermitf/the-pass/pkg/sms"
+	"github.com/gin-gonic/gin"
+)
+
+// maxCallbackBodySize caps vendor delivery-receipt payloads; vendors batch at most a few hundred reports
+const maxCallbackBodySize = 1 << 20
+
+// SMSCallbackHandler receives delivery receipts pushed by SMS vendors
+type SMSCallbackHandler struct {
+	sendLog sms.SendLogStore
+	token   string
+}
+
+// NewSMSCallbackHandler creates a new SMSCallbackHandler instance
+// token is the shared secret configured in the vendor console as part of the callback URL
+func NewSMSCallbackHandler(sendLog sms.SendLogStore, token string) *SMSCallbackHandler {
+	return &SMSCallbackHandler{
+		sendLog: sendLog,
+		token:   token,
+	}
+}
+
+// DeliveryReportHandler applies a batch of vendor delivery receipts to the send log
+// @Summary SMS delivery receipt callback
+// @Description Called by the SMS vendor (aliyun or tencent) with a JSON array of delivery reports. The response body follows the vendor's acknowledgement format; reports for unknown messages are acknowledged and ignored
+// @Tags SMS
+// @Accept json
+// @Produce json
+// @Param provider path string true "vendor" Enums(aliyun, tencent)
+// @Param token query string true "callback token"
+// @Success 200 {object} map[string]interface{} "vendor acknowledgement"
+// @Failure 400 {object} ErrorResponse "malformed payload"
+// @Failure 401 {object} ErrorResponse "invalid token"
+// @Failure 404 {object} ErrorResponse "unknown vendor"
+// @Failure 500 {object} ErrorResponse "internal server error"
+// @Router /sms/callbacks/{provider} [post]
+func (h *SMSCallbackHandler) DeliveryReportHandler(c *gin.Context) {
+	if subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(h.token)) != 1 {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	provider := c.Param("provider")
+	if provider != sms.ProviderAliyun && provider != sms.ProviderTencent {
+		NotFound(c, ErrMsgNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxCallbackBodySize))
+	if err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+	reports, err := sms.ParseDeliveryReports(provider, body)
+	if err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	for _, report := range reports {
+		if _, err := h.sendLog.ApplyDeliveryReport(c.Request.Context(), report); err != nil {
+			// Not acknowledging makes the vendor retry the whole batch later
+			InternalServerError(c, ErrMsgInternalServer, err.Error())
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, sms.DeliveryReportAck(provider))
+}
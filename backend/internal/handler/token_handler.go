@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandlerDependencies contains all dependencies for TokenHandler
+type TokenHandlerDependencies struct {
+	JWTService service.JWTServiceInterface
+}
+
+// TokenHandler handles token lifecycle endpoints shared by all user types
+type TokenHandler struct {
+	deps *TokenHandlerDependencies
+}
+
+// NewTokenHandler creates a new TokenHandler instance with dependency injection
+func NewTokenHandler(jwtService service.JWTServiceInterface) *TokenHandler {
+	return &TokenHandler{
+		deps: &TokenHandlerDependencies{
+			JWTService: jwtService,
+		},
+	}
+}
+
+// RefreshHandler exchanges a refresh token for a new access/refresh token pair
+// @Summary refresh tokens
+// @Description Exchange a refresh token for a new access/refresh pair. The submitted refresh token is rotated and must not be used again; reusing it revokes the whole login session
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param refreshRequest body RefreshTokenRequest true "refresh token"
+// @Success 200 {object} TokenPairResponse "tokens refreshed"
+// @Failure 400 {object} ErrorResponse "invalid request"
+// @Failure 401 {object} ErrorResponse "refresh token invalid, expired or reused"
+// @Failure 500 {object} ErrorResponse "internal server error"
+// @Router /auth/refresh [post]
+func (h *TokenHandler) RefreshHandler(c *gin.Context) {
+	var refreshReq RefreshTokenRequest
+	if err := c.ShouldBindJSON(&refreshReq); err != nil {
+		BadRequest(c, ErrMsgInvalidRequest, err.Error())
+		return
+	}
+
+	tokens, err := h.deps.JWTService.RefreshToken(c.Request.Context(), refreshReq.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrRefreshTokenInvalid) || errors.Is(err, service.ErrRefreshTokenReused) {
+			Unauthorized(c, err.Error())
+			return
+		}
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, TokenPairResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	})
+}
+
+// LogoutHandler revokes the current access token and, if supplied, the refresh token of this session
+// @Summary logout current session
+// @Description Revoke the access token used for this request. When a refresh token is supplied, its whole token family is revoked as well
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param logoutRequest body LogoutRequest false "refresh token of the current session"
+// @Success 200 {object} SuccessResponse "logged out"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 403 {object} ErrorResponse "refresh token belongs to another account"
+// @Failure 500 {object} ErrorResponse "internal server error"
+// @Router /auth/logout [post]
+func (h *TokenHandler) LogoutHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	var logoutReq LogoutRequest
+	// 刷新令牌可选，请求体为空时忽略绑定错误
+	_ = c.ShouldBindJSON(&logoutReq)
+
+	if err := h.deps.JWTService.Logout(c.Request.Context(), claims, logoutReq.RefreshToken); err != nil {
+		if errors.Is(err, service.ErrRefreshTokenNotOwned) {
+			Forbidden(c, err.Error())
+			return
+		}
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "已登出")
+}
+
+// LogoutAllHandler revokes every access and refresh token issued to the current account
+// @Summary logout all sessions
+// @Description Invalidate all tokens issued to the current account before now, on every device
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SuccessResponse "logged out from all sessions"
+// @Failure 401 {object} ErrorResponse "unauthorized"
+// @Failure 500 {object} ErrorResponse "internal server error"
+// @Router /auth/logout-all [post]
+func (h *TokenHandler) LogoutAllHandler(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		Unauthorized(c, ErrMsgUnauthorized)
+		return
+	}
+
+	if err := h.deps.JWTService.LogoutAll(c.Request.Context(), claims.UserID, claims.UserType); err != nil {
+		InternalServerError(c, ErrMsgInternalServer, err.Error())
+		return
+	}
+
+	RespondWithSuccess(c, http.StatusOK, nil, "已登出全部会话")
+}
+
+// JWKSHandler publishes the public verification keys as a JSON Web Key Set
+// @Summary JSON Web Key Set
+// @Description Public keys (RS256/ES256) that other services can use to verify tokens issued by this service. Symmetric HS256 keys are never published
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} auth.JWKSet "key set"
+// @Router /.well-known/jwks.json [get]
+func (h *TokenHandler) JWKSHandler(c *gin.Context) {
+	// 允许下游缓存，密钥轮换时旧密钥仍会保留在集合中一段时间
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.deps.JWTService.JWKS())
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Hermitf/the-pass/internal/middleware"
+	"github.com/Hermitf/the-pass/internal/service"
+	"github.com/Hermitf/the-pass/pkg/auth"
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTokenTestRouter(t *testing.T) (*gin.Engine, service.JWTServiceInterface) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(func() { mr.Close() })
+
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	revocationStore := auth.NewRedisRevocationStore(rdb)
+	jwtService := service.NewJWTService(service.JWTServiceDependencies{
+		Config:          testJWTConfig,
+		RefreshStore:    auth.NewRedisRefreshTokenStore(rdb),
+		RevocationStore: revocationStore,
+	})
+	deps := &RouterDependencies{
+		TokenHandler:  NewTokenHandler(jwtService),
+		JWTMiddleware: middleware.NewJWTMiddleware(testJWTConfig, revocationStore),
+	}
+
+	router := gin.New()
+	setupTokenRoutes(router.Group("/api/v1"), deps)
+	router.GET("/api/v1/ping", deps.JWTMiddleware.AuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router, jwtService
+}
+
+func doJSONRequest(router *gin.Engine, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	raw, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTokenHandler_RefreshRotatesAndDetectsReuse(t *testing.T) {
+	router, jwtService := newTokenTestRouter(t)
+
+	pair, err := jwtService.IssueTokenPair(context.Background(), 3, "user")
+	if err != nil {
+		t.Fatalf("IssueTokenPair: %v", err)
+	}
+
+	w := doJSONRequest(router, http.MethodPost, "/api/v1/auth/refresh", "", RefreshTokenRequest{RefreshToken: pair.RefreshToken})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh status=%d body=%s", w.Code, w.Body.String())
+	}
+	var rotated TokenPairResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &rotated)
+	if rotated.Token == "" || rotated.RefreshToken == "" || rotated.RefreshToken == pair.RefreshToken {
+		t.Fatalf("unexpected refresh response: %s", w.Body.String())
+	}
+
+	// 重放旧令牌吊销整个令牌族，新令牌也随之失效
+	w = doJSONRequest(router, http.MethodPost, "/api/v1/auth/refresh", "", RefreshTokenRequest{RefreshToken: pair.RefreshToken})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("reuse status=%d want 401", w.Code)
+	}
+	w = doJSONRequest(router, http.MethodPost, "/api/v1/auth/refresh", "", RefreshTokenRequest{RefreshToken: rotated.RefreshToken})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("family member status=%d want 401", w.Code)
+	}
+}
+
+func TestTokenHandler_LogoutRevokesCurrentSession(t *testing.T) {
+	router, jwtService := newTokenTestRouter(t)
+
+	pair, err := jwtService.IssueTokenPair(context.Background(), 3, "user")
+	if err != nil {
+		t.Fatalf("IssueTokenPair: %v", err)
+	}
+	other := issueToken(t, 3, "user")
+
+	w := doJSONRequest(router, http.MethodPost, "/api/v1/auth/logout", pair.AccessToken, LogoutRequest{RefreshToken: pair.RefreshToken})
+	if w.Code != http.StatusOK {
+		t.Fatalf("logout status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	if w := doRequest(router, http.MethodGet, "/api/v1/ping", pair.AccessToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("revoked token status=%d want 401", w.Code)
+	}
+	w = doJSONRequest(router, http.MethodPost, "/api/v1/auth/refresh", "", RefreshTokenRequest{RefreshToken: pair.RefreshToken})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after logout status=%d want 401", w.Code)
+	}
+	// 其他会话不受影响
+	if w := doRequest(router, http.MethodGet, "/api/v1/ping", other); w.Code != http.StatusOK {
+		t.Fatalf("other session status=%d want 200", w.Code)
+	}
+}
+
+func TestTokenHandler_LogoutRejectsForeignRefreshToken(t *testing.T) {
+	router, jwtService := newTokenTestRouter(t)
+
+	victim, err := jwtService.IssueTokenPair(context.Background(), 3, "user")
+	if err != nil {
+		t.Fatalf("IssueTokenPair: %v", err)
+	}
+	attacker := issueToken(t, 4, "user")
+	sameIDOtherType := issueToken(t, 3, "rider")
+
+	for _, token := range []string{attacker, sameIDOtherType} {
+		w := doJSONRequest(router, http.MethodPost, "/api/v1/auth/logout", token, LogoutRequest{RefreshToken: victim.RefreshToken})
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("foreign logout status=%d want 403 body=%s", w.Code, w.Body.String())
+		}
+	}
+
+	// 被冒用的刷新令牌仍然可用
+	w := doJSONRequest(router, http.MethodPost, "/api/v1/auth/refresh", "", RefreshTokenRequest{RefreshToken: victim.RefreshToken})
+	if w.Code != http.StatusOK {
+		t.Fatalf("victim refresh status=%d want 200", w.Code)
+	}
+}
+
+func TestTokenHandler_LogoutAllRevokesEverySession(t *testing.T) {
+	router, jwtService := newTokenTestRouter(t)
+
+	pair, err := jwtService.IssueTokenPair(context.Background(), 3, "rider")
+	if err != nil {
+		t.Fatalf("IssueTokenPair: %v", err)
+	}
+	other := issueToken(t, 3, "rider")
+	sameIDOtherType := issueToken(t, 3, "merchant")
+
+	w := doRequest(router, http.MethodPost, "/api/v1/auth/logout-all", other)
+	if w.Code != http.StatusOK {
+		t.Fatalf("logout-all status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	for _, token := range []string{pair.AccessToken, other} {
+		if w := doRequest(router, http.MethodGet, "/api/v1/ping", token); w.Code != http.StatusUnauthorized {
+			t.Fatalf("token after logout-all status=%d want 401", w.Code)
+		}
+	}
+	w = doJSONRequest(router, http.MethodPost, "/api/v1/auth/refresh", "", RefreshTokenRequest{RefreshToken: pair.RefreshToken})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after logout-all status=%d want 401", w.Code)
+	}
+	if w := doRequest(router, http.MethodGet, "/api/v1/ping", sameIDOtherType); w.Code != http.StatusOK {
+		t.Fatalf("other account type status=%d want 200", w.Code)
+	}
+}
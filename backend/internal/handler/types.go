@@ -1,5 +1,12 @@
 package handler
 
+import (
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/service"
+)
+
 // ================================================================
 // 请求类型 - 用于API输入层
 // ================================================================
@@ -18,6 +25,8 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email" example:"user@example.com"`
 	Phone    string `json:"phone" binding:"required" example:"1234567890"`
 	SMSCode  string `json:"sms_code" example:"123456"` // 短信验证码（注册校验）
+
+	CompanyName string `json:"company_name" binding:"max=100" example:"老王面馆"` // 店铺名称（商家注册必填）
 }
 
 // ProfileRequest - 更新用户档案请求结构
@@ -27,6 +36,35 @@ type ProfileRequest struct {
 	Phone    string `json:"phone" binding:"required" example:"1234567890"`
 }
 
+// RefreshTokenRequest - 刷新令牌请求结构
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"opaque_refresh_token"`
+}
+
+// LogoutRequest - 登出请求结构（可选携带刷新令牌，一并吊销当前会话的刷新令牌）
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" example:"opaque_refresh_token"`
+}
+
+// ForgotPasswordRequest - 发起找回密码请求（sms 渠道填手机号，email 渠道填邮箱）
+type ForgotPasswordRequest struct {
+	Channel    string `json:"channel" binding:"required,oneof=sms email" example:"sms"`
+	Identifier string `json:"identifier" binding:"required" example:"13800138000"`
+}
+
+// VerifyPasswordResetRequest - 校验找回密码凭证请求（sms 渠道为验证码，email 渠道为邮件中的令牌）
+type VerifyPasswordResetRequest struct {
+	Channel    string `json:"channel" binding:"required,oneof=sms email" example:"sms"`
+	Identifier string `json:"identifier" example:"13800138000"` // email 渠道可省略
+	Code       string `json:"code" binding:"required" example:"123456"`
+}
+
+// ResetPasswordRequest - 凭改密票据设置新密码请求
+type ResetPasswordRequest struct {
+	ResetTicket string `json:"reset_ticket" binding:"required" example:"opaque_reset_ticket"`
+	NewPassword string `json:"new_password" binding:"required" example:"NewPassw0rd"`
+}
+
 // RiderLocationUpdateRequest - 更新配送员位置请求
 type RiderLocationUpdateRequest struct {
 	Latitude  float64 `json:"latitude" binding:"required" example:"39.9042"`
@@ -44,8 +82,17 @@ type RiderOnlineStatusRequest struct {
 
 // LoginResponse - 登录响应结构
 type LoginResponse struct {
-	Token   string `json:"token" example:"jwt_token_here"`
-	Message string `json:"message" example:"登录成功"`
+	Token        string `json:"token" example:"jwt_token_here"`
+	RefreshToken string `json:"refresh_token" example:"opaque_refresh_token"`
+	ExpiresIn    int64  `json:"expires_in" example:"3600"` // 访问令牌有效期（秒）
+	Message      string `json:"message" example:"登录成功"`
+}
+
+// TokenPairResponse - 刷新令牌响应结构
+type TokenPairResponse struct {
+	Token        string `json:"token" example:"jwt_token_here"`
+	RefreshToken string `json:"refresh_token" example:"opaque_refresh_token"`
+	ExpiresIn    int64  `json:"expires_in" example:"3600"`
 }
 
 // RegisterResponse - 注册响应结构
@@ -54,6 +101,11 @@ type RegisterResponse struct {
 	Message string `json:"message" example:"注册成功"`
 }
 
+// PasswordResetTicketResponse - 找回密码校验通过后返回的改密票据
+type PasswordResetTicketResponse struct {
+	ResetTicket string `json:"reset_ticket" example:"opaque_reset_ticket"`
+}
+
 // ErrorResponse - 错误响应结构
 type ErrorResponse struct {
 	Error string `json:"error" example:"请求参数无效"`
@@ -71,3 +123,223 @@ type EmployeeListItemResponse struct {
 	Phone    string `json:"phone" example:"1234567890"`
 	IsActive bool   `json:"is_active" example:"true"`
 }
+
+// ================================================================
+// 扫码登录类型 - 用于二维码登录流程
+// ================================================================
+
+// QRTicketResponse - 创建扫码登录票据响应
+type QRTicketResponse struct {
+	TicketID  string    `json:"ticket_id" example:"1f0c7a52-6f1e-4c8e-9d51-3c2b8f1d9a10"`
+	QRPayload string    `json:"qr_payload" example:"eyJ0eXBlIjoidGhlLXBhc3MvcXItbG9naW4iLCJ0aWNrZXRfaWQiOiIuLi4ifQ"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// QRTicketStatusResponse - 扫码登录票据状态响应（确认后携带桌面端令牌）
+type QRTicketStatusResponse struct {
+	Status       string    `json:"status" example:"pending"` // pending/scanned/confirmed/rejected
+	ExpiresAt    time.Time `json:"expires_at"`
+	Token        string    `json:"token,omitempty" example:"jwt_token_here"`
+	RefreshToken string    `json:"refresh_token,omitempty" example:"opaque_refresh_token"`
+	UserType     string    `json:"user_type,omitempty" example:"user"`
+}
+
+// QRRejectRequest - 移动端拒绝扫码登录请求
+type QRRejectRequest struct {
+	Reason string `json:"reason" example:"不是本人操作"`
+}
+
+// ================================================================
+// 订单类型 - 用于订单生命周期
+// ================================================================
+
+// OrderItemRequest - 订单明细（名称与单价由服务端按商品目录确定，规格加价计入单价）
+type OrderItemRequest struct {
+	MenuItemID int64 `json:"menu_item_id" binding:"required,min=1" example:"1"`
+	Quantity   int   `json:"quantity" binding:"required,min=1,max=99" example:"2"`
+
+	OptionIDs []int64 `json:"option_ids" binding:"max=20,dive,min=1" example:"3,5"`
+}
+
+// CreateOrderRequest - 用户下单请求
+type CreateOrderRequest struct {
+	MerchantID      int64              `json:"merchant_id" binding:"required" example:"1"`
+	Items           []OrderItemRequest `json:"items" binding:"required,min=1,dive"`
+	DeliveryAddress string             `json:"delivery_address" binding:"required,max=255" example:"北京市东城区东长安街1号"`
+	DeliveryLat     float64            `json:"delivery_lat" example:"39.9042"`
+	DeliveryLng     float64            `json:"delivery_lng" example:"116.4074"`
+	ContactName     string             `json:"contact_name" binding:"max=50" example:"张三"`
+	ContactPhone    string             `json:"contact_phone" example:"13800138000"`
+	Remark          string             `json:"remark" binding:"max=255" example:"少辣"`
+}
+
+// CancelOrderRequest - 取消订单请求
+type CancelOrderRequest struct {
+	Reason string `json:"reason" binding:"max=255" example:"暂时不需要了"`
+}
+
+// RateOrderRequest - 用户为配送员评分请求
+type RateOrderRequest struct {
+	Rating float32 `json:"rating" binding:"required,min=1,max=5" example:"5"`
+}
+
+// OrderListResponse - 订单分页列表响应
+type OrderListResponse struct {
+	Orders []*model.Order `json:"orders"`
+	Total  int64          `json:"total" example:"42"`
+}
+
+// RiderLocationUpdateResponse - 位置上报确认（高频接口，不回读配送员资料）
+type RiderLocationUpdateResponse struct {
+	Latitude   float64   `json:"latitude" example:"39.9042"`
+	Longitude  float64   `json:"longitude" example:"116.4074"`
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+// DispatchOfferListResponse - 配送员待响应派单列表
+type DispatchOfferListResponse struct {
+	Offers []service.DispatchOffer `json:"offers"`
+}
+
+// ================================================================
+// 商品目录类型 - 用于商家菜单管理（金额单位：分）
+// ================================================================
+
+// MenuCategoryRequest - 创建/更新分类请求
+type MenuCategoryRequest struct {
+	Name      string `json:"name" binding:"required,max=50" example:"热菜"`
+	SortOrder int    `json:"sort_order" example:"1"`
+}
+
+// MenuOptionRequest - 规格选项
+type MenuOptionRequest struct {
+	Name        string `json:"name" binding:"required,max=50" example:"大份"`
+	PriceDelta  int64  `json:"price_delta" binding:"min=0" example:"300"`
+	IsAvailable *bool  `json:"is_available" example:"true"` // 默认可选
+}
+
+// MenuOptionGroupRequest - 规格组（如份量、辣度）
+type MenuOptionGroupRequest struct {
+	Name      string              `json:"name" binding:"required,max=50" example:"辣度"`
+	Required  bool                `json:"required" example:"true"`
+	MinSelect int                 `json:"min_select" binding:"min=0" example:"1"`
+	MaxSelect int                 `json:"max_select" binding:"min=1" example:"1"`
+	Options   []MenuOptionRequest `json:"options" binding:"required,min=1,dive"`
+}
+
+// MenuItemRequest - 创建/更新商品请求；图片与规格组整体替换
+type MenuItemRequest struct {
+	CategoryID   int64                    `json:"category_id" binding:"required" example:"1"`
+	Name         string                   `json:"name" binding:"required,max=100" example:"宫保鸡丁"`
+	Description  string                   `json:"description" binding:"max=500" example:"经典川菜"`
+	Price        int64                    `json:"price" binding:"min=0" example:"2800"`
+	Stock        *int                     `json:"stock" binding:"omitempty,min=0" example:"50"` // 为空表示不限量
+	IsAvailable  *bool                    `json:"is_available" example:"true"`                  // 默认上架
+	SortOrder    int                      `json:"sort_order" example:"1"`
+	Images       []string                 `json:"images" binding:"dive,required,max=500"`
+	OptionGroups []MenuOptionGroupRequest `json:"option_groups" binding:"dive"`
+}
+
+// MenuItemAvailabilityRequest - 上下架请求
+type MenuItemAvailabilityRequest struct {
+	IsAvailable *bool `json:"is_available" binding:"required" example:"false"`
+}
+
+// MenuItemStockRequest - 设置库存请求（stock 为空表示不限量）
+type MenuItemStockRequest struct {
+	Stock *int `json:"stock" binding:"omitempty,min=0" example:"20"`
+}
+
+// MenuResponse - 商家公开菜单
+type MenuResponse struct {
+	MerchantID int64                 `json:"merchant_id" example:"1"`
+	Categories []*model.MenuCategory `json:"categories"`
+}
+
+// ================================================================
+// 运营后台类型 - 用于管理员接口
+// ================================================================
+
+// AdminLoginRequest - 管理员登录请求（仅支持用户名 + 密码）
+type AdminLoginRequest struct {
+	Username string `json:"username" binding:"required" example:"ops_admin"`
+	Password string `json:"password" binding:"required" example:"Passw0rd!"`
+}
+
+// AccountStatusRequest - 启用/停用账号请求，原因写入审计日志
+type AccountStatusRequest struct {
+	IsActive *bool  `json:"is_active" binding:"required" example:"false"`
+	Reason   string `json:"reason" binding:"required,max=500" example:"用户投诉，核实为恶意刷单"`
+}
+
+// SMSPhoneListRequest - 号码加入/移出短信黑白名单请求，原因写入审计日志
+type SMSPhoneListRequest struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"接码平台号码，多次触发发送限额"`
+}
+
+// AccountListResponse - 后台账号分页列表（accounts 元素类型随账号类型而定）
+type AccountListResponse struct {
+	Accounts interface{} `json:"accounts"`
+	Total    int64       `json:"total" example:"42"`
+}
+
+// NearbyRiderResponse - 附近配送员及其与查询点的球面距离
+type NearbyRiderResponse struct {
+	Rider      *model.RiderResponse `json:"rider"`
+	DistanceKm float64              `json:"distance_km" example:"1.25"`
+}
+
+// AuditLogListResponse - 审计日志分页列表
+type AuditLogListResponse struct {
+	Logs  []*model.AuditLog `json:"logs"`
+	Total int64             `json:"total" example:"42"`
+}
+
+// VerificationApproveRequest - 审核通过请求，备注写入审计日志
+type VerificationApproveRequest struct {
+	Remark string `json:"remark" binding:"max=500" example:"营业执照与门头照一致"`
+}
+
+// VerificationRejectRequest - 驳回审核请求，原因展示给申请人并写入审计日志
+type VerificationRejectRequest struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"营业执照照片模糊，请重新上传"`
+}
+
+// MerchantVerificationListResponse - 按审核状态查询的商家分页列表
+type MerchantVerificationListResponse struct {
+	Merchants []*model.MerchantResponse `json:"merchants"`
+	Total     int64                     `json:"total" example:"42"`
+}
+
+// MerchantApplicationRequest - 商家填写待审核的店铺名称与营业执照号（18 位统一社会信用代码）
+type MerchantApplicationRequest struct {
+	CompanyName     string `json:"company_name" binding:"required,max=100" example:"老王面馆"`
+	BusinessLicense string `json:"business_license" binding:"required" example:"91350100M000100Y43"`
+}
+
+// RiderApplicationRequest - 配送员填写待审核的身份与车辆信息（骑自行车可不填驾驶证号与车牌号）
+type RiderApplicationRequest struct {
+	Name          string `json:"name" binding:"required,max=50" example:"张三"`
+	IDNumber      string `json:"id_number" binding:"required" example:"11010519491231002X"`
+	VehicleType   string `json:"vehicle_type" binding:"required" example:"motorcycle"`
+	VehicleNumber string `json:"vehicle_number" example:"京A12345"`
+	LicenseNumber string `json:"license_number" example:"11010519491231002X"`
+}
+
+// RiderVerificationListResponse - 按审核状态查询的配送员分页列表
+type RiderVerificationListResponse struct {
+	Riders []*model.RiderResponse `json:"riders"`
+	Total  int64                  `json:"total" example:"42"`
+}
+
+// StoreLocationRequest - 设置门店地址请求（下单时作为取货地址）
+type StoreLocationRequest struct {
+	Address string  `json:"address" binding:"required,max=255" example:"北京市朝阳区建国路88号"`
+	Lat     float64 `json:"lat" binding:"min=-90,max=90" example:"39.9087"`
+	Lng     float64 `json:"lng" binding:"min=-180,max=180" example:"116.4605"`
+}
+
+// EmployeeRoleRequest - 为员工分配角色请求
+type EmployeeRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner manager cashier kitchen" example:"kitchen"`
+}
@@ -0,0 +1,20 @@
+package location
+
+import "github.com/redis/go-redis/v9"
+
+// ---------- Lua 脚本（集中管理） ----------
+
+// 原子取出并清空待落库位置：返回 HGETALL 结果后删除哈希
+var luaDrainPendingScript = redis.NewScript(`
+local values = redis.call('HGETALL', KEYS[1])
+redis.call('DEL', KEYS[1])
+return values
+`)
+
+// 存活键已过期时将配送员移出 GEO 集合；期间重新上报（存活键存在）则保留
+var luaReapScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then
+  return 0
+end
+return redis.call('ZREM', KEYS[1], ARGV[1])
+`)
@@ -0,0 +1,232 @@
+// Package location 配送员实时位置存储
+//
+// 位置上报写入 Redis 而非直接更新数据库：
+//   - {rider:loc}:geo          GEO 集合，成员为配送员ID，用于 GEOSEARCH 附近查询
+//   - {rider:loc}:alive:{id}   存活键，每次上报刷新 TTL；过期表示配送员失联
+//   - {rider:loc}:pending      待落库位置（哈希，配送员ID -> "lat,lng"），由定时任务批量写入数据库
+//
+// 键名使用同一哈希标签，保证 Lua 脚本涉及的键位于同一槽位。
+package location
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultKeyPrefix = "{rider:loc}"
+
+	// DefaultLivenessTTL 默认存活时长：超过该时长未上报位置视为离线
+	DefaultLivenessTTL = 60 * time.Second
+)
+
+// Hit 附近查询结果
+type Hit struct {
+	model.RiderPosition
+	DistanceKm float64
+}
+
+// Store 基于 Redis GEO 的配送员位置存储
+type Store struct {
+	client      *redis.Client
+	prefix      string
+	livenessTTL time.Duration
+}
+
+// NewStore 创建位置存储；livenessTTL 为 0 时使用 DefaultLivenessTTL
+func NewStore(client *redis.Client, livenessTTL time.Duration) *Store {
+	if livenessTTL <= 0 {
+		livenessTTL = DefaultLivenessTTL
+	}
+	return &Store{client: client, prefix: defaultKeyPrefix, livenessTTL: livenessTTL}
+}
+
+// Redis 键生成函数
+func (s *Store) geoKey() string {
+	return s.prefix + ":geo"
+}
+
+func (s *Store) pendingKey() string {
+	return s.prefix + ":pending"
+}
+
+func (s *Store) aliveKey(member string) string {
+	return s.prefix + ":alive:" + member
+}
+
+// Update 记录一次位置上报：更新 GEO 坐标、刷新存活键并登记待落库位置
+func (s *Store) Update(ctx context.Context, riderID int64, lat, lng float64) error {
+	member := strconv.FormatInt(riderID, 10)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.GeoAdd(ctx, s.geoKey(), &redis.GeoLocation{Name: member, Latitude: lat, Longitude: lng})
+		pipe.Set(ctx, s.aliveKey(member), 1, s.livenessTTL)
+		pipe.HSet(ctx, s.pendingKey(), member, formatPoint(lat, lng))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("update rider location failed (rider=%d): %w", riderID, err)
+	}
+	return nil
+}
+
+// Remove 将配送员移出附近查询（下线时调用）；尚未落库的位置保留，由下一次批量写入处理
+func (s *Store) Remove(ctx context.Context, riderID int64) error {
+	member := strconv.FormatInt(riderID, 10)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, s.geoKey(), member)
+		pipe.Del(ctx, s.aliveKey(member))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("remove rider location failed (rider=%d): %w", riderID, err)
+	}
+	return nil
+}
+
+// Search 使用 GEOSEARCH 查询半径内的配送员，按距离由近到远返回至多 limit 个
+func (s *Store) Search(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]Hit, error) {
+	locations, err := s.client.GeoSearchLocation(ctx, s.geoKey(), &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  lng,
+			Latitude:   lat,
+			Radius:     radiusKm,
+			RadiusUnit: "km",
+			Sort:       "ASC",
+			Count:      limit,
+		},
+		WithCoord: true,
+		WithDist:  true,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("geosearch failed (key=%s): %w", s.geoKey(), err)
+	}
+
+	hits := make([]Hit, 0, len(locations))
+	for _, loc := range locations {
+		riderID, err := strconv.ParseInt(loc.Name, 10, 64)
+		if err != nil {
+			continue
+		}
+		hits = append(hits, Hit{
+			RiderPosition: model.RiderPosition{RiderID: riderID, Lat: loc.Latitude, Lng: loc.Longitude},
+			DistanceKm:    loc.Dist,
+		})
+	}
+	return hits, nil
+}
+
+// DrainPending 原子取出自上次调用以来的待落库位置（每个配送员只保留最新一次）
+func (s *Store) DrainPending(ctx context.Context) ([]model.RiderPosition, error) {
+	values, err := luaDrainPendingScript.Run(ctx, s.client, []string{s.pendingKey()}).StringSlice()
+	if err != nil {
+		return nil, fmt.Errorf("drain pending locations failed (key=%s): %w", s.pendingKey(), err)
+	}
+
+	positions := make([]model.RiderPosition, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		riderID, err := strconv.ParseInt(values[i], 10, 64)
+		if err != nil {
+			continue
+		}
+		lat, lng, ok := parsePoint(values[i+1])
+		if !ok {
+			continue
+		}
+		positions = append(positions, model.RiderPosition{RiderID: riderID, Lat: lat, Lng: lng})
+	}
+	return positions, nil
+}
+
+// Requeue 将落库失败的位置放回待落库集合；期间已有更新位置的配送员保留新值
+func (s *Store) Requeue(ctx context.Context, positions []model.RiderPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, p := range positions {
+			pipe.HSetNX(ctx, s.pendingKey(), strconv.FormatInt(p.RiderID, 10), formatPoint(p.Lat, p.Lng))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("requeue pending locations failed (key=%s): %w", s.pendingKey(), err)
+	}
+	return nil
+}
+
+// ListExpired 返回仍在 GEO 集合中、但存活键已过期的配送员ID（不移除）
+func (s *Store) ListExpired(ctx context.Context) ([]int64, error) {
+	members, err := s.client.ZRange(ctx, s.geoKey(), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list rider locations failed (key=%s): %w", s.geoKey(), err)
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	exists := make([]*redis.IntCmd, len(members))
+	if _, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, member := range members {
+			exists[i] = pipe.Exists(ctx, s.aliveKey(member))
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("check rider liveness failed: %w", err)
+	}
+
+	var expired []int64
+	for i, member := range members {
+		if exists[i].Val() == 1 {
+			continue
+		}
+		if riderID, err := strconv.ParseInt(member, 10, 64); err == nil {
+			expired = append(expired, riderID)
+		}
+	}
+	return expired, nil
+}
+
+// Reap 将存活键仍未恢复的配送员移出 GEO 集合，返回被移除的配送员ID
+// 期间重新上报位置的配送员保留在集合中
+func (s *Store) Reap(ctx context.Context, riderIDs []int64) ([]int64, error) {
+	var reaped []int64
+	for _, riderID := range riderIDs {
+		member := strconv.FormatInt(riderID, 10)
+		removed, err := luaReapScript.Run(ctx, s.client, []string{s.geoKey(), s.aliveKey(member)}, member).Int()
+		if err != nil {
+			return reaped, fmt.Errorf("reap rider location failed (rider=%d): %w", riderID, err)
+		}
+		if removed == 1 {
+			reaped = append(reaped, riderID)
+		}
+	}
+	return reaped, nil
+}
+
+// formatPoint 序列化坐标为 "lat,lng"
+func formatPoint(lat, lng float64) string {
+	return strconv.FormatFloat(lat, 'f', -1, 64) + "," + strconv.FormatFloat(lng, 'f', -1, 64)
+}
+
+// parsePoint 解析 "lat,lng"
+func parsePoint(value string) (float64, float64, bool) {
+	latStr, lngStr, found := strings.Cut(value, ",")
+	if !found {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lng, err := strconv.ParseFloat(lngStr, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
@@ -0,0 +1,100 @@
+package location
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestStore(t *testing.T) (*Store, *miniredis.Miniredis, context.Context) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(func() { mr.Close() })
+
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	return NewStore(rdb, 30*time.Second), mr, context.Background()
+}
+
+func TestStore_DrainPendingKeepsLatest(t *testing.T) {
+	store, _, ctx := newTestStore(t)
+
+	_ = store.Update(ctx, 1, 31.2, 121.4)
+	_ = store.Update(ctx, 1, 31.3, 121.5)
+	_ = store.Update(ctx, 2, 39.9, 116.4)
+
+	positions, err := store.DrainPending(ctx)
+	if err != nil {
+		t.Fatalf("DrainPending: %v", err)
+	}
+	got := map[int64]model.RiderPosition{}
+	for _, p := range positions {
+		got[p.RiderID] = p
+	}
+	if len(got) != 2 || got[1].Lat != 31.3 || got[1].Lng != 121.5 || got[2].Lat != 39.9 {
+		t.Fatalf("unexpected positions: %+v", positions)
+	}
+
+	if positions, _ := store.DrainPending(ctx); len(positions) != 0 {
+		t.Fatalf("pending not cleared: %+v", positions)
+	}
+
+	// 回填不覆盖期间的新位置
+	_ = store.Update(ctx, 1, 31.4, 121.6)
+	if err := store.Requeue(ctx, []model.RiderPosition{{RiderID: 1, Lat: 31.3, Lng: 121.5}, {RiderID: 2, Lat: 39.9, Lng: 116.4}}); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	positions, _ = store.DrainPending(ctx)
+	got = map[int64]model.RiderPosition{}
+	for _, p := range positions {
+		got[p.RiderID] = p
+	}
+	if len(got) != 2 || got[1].Lat != 31.4 || got[2].Lat != 39.9 {
+		t.Fatalf("unexpected requeued positions: %+v", positions)
+	}
+}
+
+func TestStore_ReapExpired(t *testing.T) {
+	store, mr, ctx := newTestStore(t)
+
+	_ = store.Update(ctx, 1, 31.2, 121.4)
+	_ = store.Update(ctx, 2, 31.2, 121.4)
+	_ = store.Update(ctx, 3, 31.2, 121.4)
+	_ = store.Remove(ctx, 3)
+
+	mr.FastForward(20 * time.Second)
+	_ = store.Update(ctx, 2, 31.25, 121.45) // 2 号持续上报
+	mr.FastForward(15 * time.Second)
+
+	expired, err := store.ListExpired(ctx)
+	if err != nil || len(expired) != 1 || expired[0] != 1 {
+		t.Fatalf("ListExpired: expired=%v err=%v want [1]", expired, err)
+	}
+	// 列出后、移除前重新上报的配送员不会被移除
+	_ = store.Update(ctx, 1, 31.2, 121.4)
+	if reaped, err := store.Reap(ctx, []int64{1}); err != nil || len(reaped) != 0 {
+		t.Fatalf("Reap revived: reaped=%v err=%v want none", reaped, err)
+	}
+	mr.FastForward(31 * time.Second)
+	_ = store.Update(ctx, 2, 31.25, 121.45)
+
+	reaped, err := store.Reap(ctx, []int64{1})
+	if err != nil {
+		t.Fatalf("Reap: %v", err)
+	}
+	if len(reaped) != 1 || reaped[0] != 1 {
+		t.Fatalf("reaped=%v want [1]", reaped)
+	}
+
+	members, _ := mr.ZMembers(store.geoKey())
+	if len(members) != 1 || members[0] != "2" {
+		t.Fatalf("geo members=%v want [2]", members)
+	}
+}
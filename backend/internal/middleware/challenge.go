@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Hermitf/the-pass/pkg/challenge"
+	"github.com/gin-gonic/gin"
+)
+
+// #region 人机验证守卫
+
+// 客户端提交挑战答案使用的请求头
+const (
+	HeaderChallengeID     = "X-Challenge-ID"
+	HeaderChallengeAnswer = "X-Challenge-Answer"
+)
+
+// HeaderDeviceID 客户端上报的设备标识，用于人机验证风险评估与短信发送按设备限额
+const HeaderDeviceID = "X-Device-ID"
+
+// maxChallengeBodySize 读取目标手机号时最多缓冲的请求体字节数
+const maxChallengeBodySize = 64 << 10
+
+// RequireChallenge 人机验证守卫，挂在公开的短信发送与找回密码接口之前
+// 需要验证时返回 428 并附带新挑战，客户端完成后通过请求头提交挑战ID与答案重试；gate 为 nil 时直接放行
+// 风险按客户端 IP、请求体中的目标手机号与设备标识共同评估，请求体读取后原样放回供处理器绑定
+func RequireChallenge(gate *challenge.Gate) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if gate == nil {
+			c.Next()
+			return
+		}
+
+		subject := challenge.Subject{
+			IP:       c.ClientIP(),
+			Phone:    peekPhone(c),
+			DeviceID: c.GetHeader(HeaderDeviceID),
+		}
+		next, err := gate.Check(c.Request.Context(), subject,
+			c.GetHeader(HeaderChallengeID), c.GetHeader(HeaderChallengeAnswer))
+		if err == nil {
+			c.Next()
+			return
+		}
+		if next == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "人机验证服务异常"})
+			c.Abort()
+			return
+		}
+
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": err.Error(), "challenge": next})
+		c.Abort()
+	}
+}
+
+// peekPhone 读取 JSON 请求体中的目标手机号，已读取的部分接回请求体之前，无法解析时返回空
+// 短信接口取 phone 字段；找回密码接口在 sms 渠道下取 identifier 字段
+func peekPhone(c *gin.Context) string {
+	body := c.Request.Body
+	if body == nil {
+		return ""
+	}
+	raw, err := io.ReadAll(io.LimitReader(body, maxChallengeBodySize))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(raw), body), body}
+	if err != nil {
+		return ""
+	}
+
+	var req struct {
+		Phone      string `json:"phone"`
+		Channel    string `json:"channel"`
+		Identifier string `json:"identifier"`
+	}
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return ""
+	}
+	if req.Phone == "" && req.Channel == "sms" {
+		return strings.TrimSpace(req.Identifier)
+	}
+	return strings.TrimSpace(req.Phone)
+}
+
+// #endregion
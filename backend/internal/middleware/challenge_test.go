@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hermitf/the-pass/pkg/challenge"
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRequireChallenge_RiskKeyIncludesPhone(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	gate := challenge.NewGate(challenge.NewRedisStore(client),
+		challenge.NewVolumeRisk(client, challenge.VolumePolicy{FreeSends: 1, CaptchaAfter: 3}),
+		challenge.GateConfig{TTL: time.Minute, Challengers: map[challenge.Risk]challenge.Challenger{
+			challenge.RiskElevated: challenge.NewProofOfWork(8),
+		}})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var bodies []string
+	router.POST("/sms/send", RequireChallenge(gate), func(c *gin.Context) {
+		raw, _ := io.ReadAll(c.Request.Body)
+		bodies = append(bodies, string(raw))
+		c.Status(http.StatusOK)
+	})
+	send := func(ip, device string) int {
+		req := httptest.NewRequest(http.MethodPost, "/sms/send", strings.NewReader(`{"phone":"13800000001"}`))
+		req.RemoteAddr = ip + ":40000"
+		req.Header.Set(HeaderDeviceID, device)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// 处理器仍能读到完整请求体
+	if code := send("192.0.2.1", "device-1"); code != http.StatusOK {
+		t.Fatalf("first send status=%d want 200", code)
+	}
+	if len(bodies) != 1 || bodies[0] != `{"phone":"13800000001"}` {
+		t.Fatalf("handler bodies=%q", bodies)
+	}
+
+	// 换 IP 与设备轰炸同一号码仍要求验证
+	if code := send("192.0.2.2", "device-2"); code != http.StatusPreconditionRequired {
+		t.Fatalf("rotated ip status=%d want 428", code)
+	}
+}
+
+func TestPeekPhone(t *testing.T) {
+	cases := map[string]string{
+		`{"phone":" 13800000001 "}`:                        "13800000001",
+		`{"channel":"sms","identifier":"13800000002"}`:     "13800000002",
+		`{"channel":"email","identifier":"a@example.com"}`: "",
+		`not json`: "",
+	}
+	for body, want := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if got := peekPhone(c); got != want {
+			t.Errorf("peekPhone(%s)=%q want %q", body, got, want)
+		}
+		if raw, _ := io.ReadAll(c.Request.Body); string(raw) != body {
+			t.Errorf("body not restored: %q", raw)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -10,15 +11,25 @@ import (
 
 // #region 中间件结构
 
+// 上下文键名，供后续处理器读取认证信息
+const (
+	ContextKeyUserID   = "userID"
+	ContextKeyUserType = "userType"
+	ContextKeyClaims   = "claims"
+)
+
 // JWTMiddleware JWT认证中间件结构体
 type JWTMiddleware struct {
-	config auth.JWTConfig
+	config      auth.JWTConfig
+	revocations auth.TokenRevocationStore
 }
 
 // NewJWTMiddleware 创建JWT中间件实例
-func NewJWTMiddleware(config auth.JWTConfig) *JWTMiddleware {
+// revocations 为空时不校验令牌吊销状态
+func NewJWTMiddleware(config auth.JWTConfig, revocations auth.TokenRevocationStore) *JWTMiddleware {
 	return &JWTMiddleware{
-		config: config,
+		config:      config,
+		revocations: revocations,
 	}
 }
 
@@ -56,15 +67,31 @@ func (m *JWTMiddleware) validateBearerFormat(c *gin.Context, authHeader string)
 	return token, true
 }
 
-// verifyTokenAndExtractUserID 验证Token并提取用户ID
-func (m *JWTMiddleware) verifyTokenAndExtractUserID(c *gin.Context, token string) (int64, bool) {
+// verifyTokenAndExtractClaims 验证Token并提取完整声明
+func (m *JWTMiddleware) verifyTokenAndExtractClaims(c *gin.Context, token string) (*auth.Claims, bool) {
 	claims, err := auth.VerifyToken(token, m.config)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token无效"})
 		c.Abort()
-		return 0, false
+		return nil, false
 	}
-	return claims.UserID, true
+	return claims, true
+}
+
+// checkRevocation 校验令牌是否已被登出吊销
+// 吊销存储不可用时拒绝请求（fail-closed），避免已登出的令牌在故障期间重新生效
+func (m *JWTMiddleware) checkRevocation(c *gin.Context, claims *auth.Claims) bool {
+	err := auth.CheckRevocation(c.Request.Context(), m.revocations, claims)
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, auth.ErrTokenRevoked) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token已失效"})
+	} else {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "认证服务暂不可用"})
+	}
+	c.Abort()
+	return false
 }
 
 // #endregion
@@ -72,7 +99,8 @@ func (m *JWTMiddleware) verifyTokenAndExtractUserID(c *gin.Context, token string
 // #region 中间件主函数
 
 // AuthMiddleware JWT认证中间件主函数
-// TODO: 支持角色/权限校验与多租户场景下的额外令牌校验。
+// 仅负责身份认证，用户类型校验请组合 RequireUserType 使用。
+// TODO: 支持多租户场景下的额外令牌校验。
 func (m *JWTMiddleware) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 步骤1：提取Authorization头部
@@ -87,16 +115,60 @@ func (m *JWTMiddleware) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 步骤3：验证token并提取用户ID
-		userID, ok := m.verifyTokenAndExtractUserID(c, token)
+		// 步骤3：验证token并提取声明
+		claims, ok := m.verifyTokenAndExtractClaims(c, token)
 		if !ok {
 			return
 		}
 
-		// 步骤4：将用户ID存储到上下文中，供后续处理器使用
-		c.Set("userID", userID)
+		// 步骤4：校验令牌是否已被吊销（登出/全部登出）
+		if !m.checkRevocation(c, claims) {
+			return
+		}
+
+		// 步骤5：将用户ID、用户类型与完整声明存储到上下文中，供后续处理器使用
+		c.Set(ContextKeyUserID, claims.UserID)
+		c.Set(ContextKeyUserType, claims.UserType)
+		c.Set(ContextKeyClaims, claims)
 		c.Next()
 	}
 }
 
+// RequireUserType 用户类型守卫，需挂在 AuthMiddleware 之后
+// 令牌中的 user_type 不在允许列表内时返回 403，防止不同身份的同ID令牌越权访问
+func (m *JWTMiddleware) RequireUserType(userTypes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未提供Token"})
+			c.Abort()
+			return
+		}
+
+		for _, userType := range userTypes {
+			if claims.UserType == userType {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
+		c.Abort()
+	}
+}
+
+// #endregion
+
+// #region 上下文读取
+
+// GetClaims 从上下文中读取 AuthMiddleware 写入的令牌声明
+func GetClaims(c *gin.Context) (*auth.Claims, bool) {
+	value, exists := c.Get(ContextKeyClaims)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*auth.Claims)
+	return claims, ok && claims != nil
+}
+
 // #endregion
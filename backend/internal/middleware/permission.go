@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// #region 员工权限守卫
+
+// ContextKeyMerchantID 当前请求所代表的商家ID，由 RequirePermission 写入
+const ContextKeyMerchantID = "merchantID"
+
+// PermissionCheck 校验员工是否在职且拥有指定权限，返回员工所属商家ID
+// 员工不存在、已停用或无权限时返回 allowed=false，只有查询失败才返回错误
+type PermissionCheck func(ctx context.Context, employeeID int64, permission string) (merchantID int64, allowed bool, err error)
+
+// PermissionMiddleware 商家内员工的细粒度权限守卫
+type PermissionMiddleware struct {
+	check PermissionCheck
+}
+
+// NewPermissionMiddleware 创建员工权限守卫
+func NewPermissionMiddleware(check PermissionCheck) *PermissionMiddleware {
+	return &PermissionMiddleware{check: check}
+}
+
+// RequirePermission 要求操作方拥有指定权限，需挂在 AuthMiddleware 之后
+//
+// 商家账号即店铺所有者，拥有全部权限；员工每次请求实时查询角色与在职状态，
+// 角色变更或停用立即生效。通过后把操作方所属商家写入上下文，处理器据此限定数据范围，
+// 员工只能操作自己所属商家的数据。
+func (m *PermissionMiddleware) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未提供Token"})
+			c.Abort()
+			return
+		}
+
+		switch claims.UserType {
+		case "merchant":
+			c.Set(ContextKeyMerchantID, claims.UserID)
+		case "employee":
+			merchantID, allowed, err := m.check(c.Request.Context(), claims.UserID, permission)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "员工权限查询失败"})
+				c.Abort()
+				return
+			}
+			if !allowed || merchantID <= 0 {
+				c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
+				c.Abort()
+				return
+			}
+			c.Set(ContextKeyMerchantID, merchantID)
+		default:
+			c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetMerchantID 读取 RequirePermission 写入的商家ID
+func GetMerchantID(c *gin.Context) (int64, bool) {
+	value, exists := c.Get(ContextKeyMerchantID)
+	if !exists {
+		return 0, false
+	}
+	merchantID, ok := value.(int64)
+	return merchantID, ok && merchantID > 0
+}
+
+// #endregion
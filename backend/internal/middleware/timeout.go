@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// #region 请求超时
+
+// DefaultRequestTimeout 未配置 server.request_timeout 时的请求处理超时
+const DefaultRequestTimeout = 10 * time.Second
+
+// RequestTimeout 为每个请求的 context 设置截止时间
+// 处理器把 c.Request.Context() 传入服务与仓储，超时或客户端断开时数据库与 Redis 调用随之取消；
+// 超时后处理器尚未写出响应时返回 504。timeout 为 0 使用默认值，负数表示不限制
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	if timeout == 0 {
+		timeout = DefaultRequestTimeout
+	}
+	if timeout < 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"error": "请求处理超时"})
+		}
+	}
+}
+
+// #endregion
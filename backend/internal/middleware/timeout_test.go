@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestTimeout(20 * time.Millisecond))
+	router.GET("/slow", func(c *gin.Context) {
+		// 模拟尊重 ctx 的下游调用：超时后直接返回，不写响应
+		<-c.Request.Context().Done()
+	})
+	router.GET("/fast", func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); !ok {
+			t.Error("request context should carry a deadline")
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("slow status=%d want 504", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("fast status=%d want 204", rec.Code)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// #region 资质审核守卫
+
+// VerificationCheck 查询账号是否已通过资质审核
+type VerificationCheck func(ctx context.Context, accountID int64) (bool, error)
+
+// RequireVerified 资质审核守卫，需挂在 AuthMiddleware 与 RequireUserType 之后
+// 令牌中的账号尚未通过资质审核时返回 403；每次请求实时查询，审核结果变更立即生效
+func RequireVerified(check VerificationCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未提供Token"})
+			c.Abort()
+			return
+		}
+
+		verified, err := check(c.Request.Context(), claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "资质审核状态查询失败"})
+			c.Abort()
+			return
+		}
+		if !verified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "资质审核未通过，暂不能使用该功能"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// #endregion
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// #region 模型定义
+
+// Admin 后台管理员（运营）模型
+// 管理员账号不开放注册，通过 `server admin create` 命令创建
+type Admin struct {
+	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement;comment:管理员ID"`
+	Username     string     `json:"username" gorm:"type:varchar(50);unique;not null;comment:用户名"`
+	PasswordHash string     `json:"-" gorm:"type:varchar(255);not null;comment:密码哈希"`
+	Name         string     `json:"name" gorm:"type:varchar(50);comment:姓名"`
+	Email        string     `json:"email" gorm:"type:varchar(100);comment:邮箱"`
+	IsActive     bool       `json:"is_active" gorm:"default:true;comment:是否激活"`
+	LastLoginAt  *time.Time `json:"last_login_at" gorm:"comment:最近登录时间"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 设置表名
+func (Admin) TableName() string {
+	return "admins"
+}
+
+// #endregion
+
+// #region 响应DTO
+
+// AdminResponse 管理员响应DTO
+type AdminResponse struct {
+	ID          int64      `json:"id"`
+	Username    string     `json:"username"`
+	Name        string     `json:"name,omitempty"`
+	Email       string     `json:"email,omitempty"`
+	IsActive    bool       `json:"is_active"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// ToResponse 将 Admin 模型转换为响应DTO
+func (a *Admin) ToResponse() *AdminResponse {
+	return &AdminResponse{
+		ID:          a.ID,
+		Username:    a.Username,
+		Name:        a.Name,
+		Email:       a.Email,
+		IsActive:    a.IsActive,
+		LastLoginAt: a.LastLoginAt,
+	}
+}
+
+// #endregion
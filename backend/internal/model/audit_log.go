@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// #region 常量
+
+// 审计日志操作方类型
+const (
+	AuditActorAdmin = "admin"
+)
+
+// 审计日志目标账号类型（与 JWT 中的 userType 一致）
+const (
+	AccountTypeUser     = "user"
+	AccountTypeMerchant = "merchant"
+	AccountTypeRider    = "rider"
+	AccountTypeEmployee = "employee"
+)
+
+// AuditTargetSMSPhone 短信号码名单中的号码，无数字ID，号码与名单记录在 Detail 中
+const AuditTargetSMSPhone = "sms_phone"
+
+// 审计动作
+const (
+	AuditActionAccountActivate   = "account.activate"
+	AuditActionAccountDeactivate = "account.deactivate"
+)
+
+// 短信号码名单审计动作
+const (
+	AuditActionSMSPhoneListAdd    = "sms_phone_list.add"
+	AuditActionSMSPhoneListRemove = "sms_phone_list.remove"
+)
+
+// MaxAuditReasonLength 审计原因最大长度（字符）
+const MaxAuditReasonLength = 500
+
+// #endregion
+
+// #region 模型定义
+
+// AuditLog 操作审计日志，只追加不修改
+// 记录“谁（操作方）在何时因何原因对哪个对象做了什么”
+type AuditLog struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement;comment:日志ID"`
+	ActorType  string    `json:"actor_type" gorm:"type:varchar(20);not null;comment:操作方类型"`
+	ActorID    int64     `json:"actor_id" gorm:"not null;comment:操作方ID"`
+	Action     string    `json:"action" gorm:"type:varchar(50);not null;comment:操作"`
+	TargetType string    `json:"target_type" gorm:"type:varchar(20);not null;comment:目标类型"`
+	TargetID   int64     `json:"target_id" gorm:"not null;comment:目标ID"`
+	Reason     string    `json:"reason" gorm:"type:varchar(500);not null;comment:操作原因"`
+	Detail     string    `json:"detail,omitempty" gorm:"type:text;comment:附加信息(JSON)"`
+	ClientIP   string    `json:"client_ip,omitempty" gorm:"type:varchar(64);comment:操作方IP"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName 设置表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// #endregion
@@ -0,0 +1,227 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// #region 模型定义
+
+// MenuCategory 商品分类
+type MenuCategory struct {
+	ID         int64          `json:"id" gorm:"primaryKey;autoIncrement;comment:分类ID"`
+	MerchantID int64          `json:"merchant_id" gorm:"not null;index;comment:商家ID"`
+	Name       string         `json:"name" gorm:"type:varchar(50);not null;comment:分类名称"`
+	SortOrder  int            `json:"sort_order" gorm:"default:0;comment:排序（升序）"`
+	Items      []MenuItem     `json:"items,omitempty" gorm:"foreignKey:CategoryID"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName 设置表名
+func (MenuCategory) TableName() string {
+	return "menu_categories"
+}
+
+// MenuItem 商品（价格单位：分）
+type MenuItem struct {
+	ID           int64             `json:"id" gorm:"primaryKey;autoIncrement;comment:商品ID"`
+	MerchantID   int64             `json:"merchant_id" gorm:"not null;index;comment:商家ID"`
+	CategoryID   int64             `json:"category_id" gorm:"not null;index;comment:分类ID"`
+	Name         string            `json:"name" gorm:"type:varchar(100);not null;comment:商品名称"`
+	Description  string            `json:"description,omitempty" gorm:"type:varchar(500);comment:商品描述"`
+	Price        int64             `json:"price" gorm:"not null;comment:价格（分）"`
+	Stock        *int              `json:"stock,omitempty" gorm:"comment:库存，为空表示不限量"`
+	IsAvailable  bool              `json:"is_available" gorm:"not null;comment:是否可售（上下架）"`
+	SortOrder    int               `json:"sort_order" gorm:"default:0;comment:排序（升序）"`
+	Images       []MenuItemImage   `json:"images,omitempty" gorm:"foreignKey:ItemID"`
+	OptionGroups []MenuOptionGroup `json:"option_groups,omitempty" gorm:"foreignKey:ItemID"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt    `json:"-" gorm:"index"`
+}
+
+// TableName 设置表名
+func (MenuItem) TableName() string {
+	return "menu_items"
+}
+
+// MenuItemImage 商品图片（第一张为主图）
+type MenuItemImage struct {
+	ID        int64  `json:"id" gorm:"primaryKey;autoIncrement;comment:图片ID"`
+	ItemID    int64  `json:"item_id" gorm:"not null;index;comment:商品ID"`
+	URL       string `json:"url" gorm:"type:varchar(500);not null;comment:图片地址"`
+	SortOrder int    `json:"sort_order" gorm:"default:0;comment:排序（升序）"`
+}
+
+// TableName 设置表名
+func (MenuItemImage) TableName() string {
+	return "menu_item_images"
+}
+
+// MenuOptionGroup 规格组（如：份量、辣度）
+type MenuOptionGroup struct {
+	ID        int64        `json:"id" gorm:"primaryKey;autoIncrement;comment:规格组ID"`
+	ItemID    int64        `json:"item_id" gorm:"not null;index;comment:商品ID"`
+	Name      string       `json:"name" gorm:"type:varchar(50);not null;comment:规格组名称"`
+	Required  bool         `json:"required" gorm:"default:false;comment:是否必选"`
+	MinSelect int          `json:"min_select" gorm:"default:0;comment:最少选择数"`
+	MaxSelect int          `json:"max_select" gorm:"default:1;comment:最多选择数"`
+	SortOrder int          `json:"sort_order" gorm:"default:0;comment:排序（升序）"`
+	Options   []MenuOption `json:"options" gorm:"foreignKey:GroupID"`
+}
+
+// TableName 设置表名
+func (MenuOptionGroup) TableName() string {
+	return "menu_option_groups"
+}
+
+// MenuOption 规格选项（加价单位：分）
+type MenuOption struct {
+	ID          int64  `json:"id" gorm:"primaryKey;autoIncrement;comment:选项ID"`
+	GroupID     int64  `json:"group_id" gorm:"not null;index;comment:规格组ID"`
+	Name        string `json:"name" gorm:"type:varchar(50);not null;comment:选项名称"`
+	PriceDelta  int64  `json:"price_delta" gorm:"default:0;comment:加价（分）"`
+	IsAvailable bool   `json:"is_available" gorm:"not null;comment:是否可选"`
+	SortOrder   int    `json:"sort_order" gorm:"default:0;comment:排序（升序）"`
+}
+
+// TableName 设置表名
+func (MenuOption) TableName() string {
+	return "menu_options"
+}
+
+// #endregion
+
+// #region 业务方法
+
+// IsInStock 是否有库存（未设置库存视为不限量）
+func (i *MenuItem) IsInStock() bool {
+	return i.Stock == nil || *i.Stock > 0
+}
+
+// IsSellable 是否可下单：已上架且有库存
+func (i *MenuItem) IsSellable() bool {
+	return i.IsAvailable && i.IsInStock()
+}
+
+// SelectOptions 按规格组规则校验下单所选的规格选项，返回规格快照与加价合计
+// 选项须属于本商品且不重复；每组选择数不少于 MinSelect（必选组至少一项）、不多于 MaxSelect
+func (i *MenuItem) SelectOptions(optionIDs []int64) ([]OrderItemOption, int64, error) {
+	selected := make(map[int64]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if selected[id] {
+			return nil, 0, fmt.Errorf("%w: 选项 %d 重复", ErrInvalidOptionSelection, id)
+		}
+		selected[id] = true
+	}
+
+	var (
+		snapshots  []OrderItemOption
+		priceDelta int64
+	)
+	for _, group := range i.OptionGroups {
+		count := 0
+		for _, option := range group.Options {
+			if !selected[option.ID] {
+				continue
+			}
+			if !option.IsAvailable {
+				return nil, 0, fmt.Errorf("%w: %s", ErrOptionUnavailable, option.Name)
+			}
+			delete(selected, option.ID)
+			count++
+			priceDelta += option.PriceDelta
+			snapshots = append(snapshots, OrderItemOption{
+				OptionID:   option.ID,
+				GroupName:  group.Name,
+				Name:       option.Name,
+				PriceDelta: option.PriceDelta,
+			})
+		}
+
+		minSelect := group.MinSelect
+		if group.Required && minSelect < 1 {
+			minSelect = 1
+		}
+		if count < minSelect || count > group.MaxSelect {
+			return nil, 0, fmt.Errorf("%w: %s 需选择 %d 到 %d 项", ErrInvalidOptionSelection, group.Name, minSelect, group.MaxSelect)
+		}
+	}
+	for id := range selected {
+		return nil, 0, fmt.Errorf("%w: 选项 %d 不属于该商品", ErrInvalidOptionSelection, id)
+	}
+	return snapshots, priceDelta, nil
+}
+
+// #endregion
+
+// #region 验证方法
+
+// Validate 验证分类数据
+func (c *MenuCategory) Validate() error {
+	if c.MerchantID <= 0 {
+		return ErrInvalidMerchantID
+	}
+	if c.Name == "" || len([]rune(c.Name)) > 50 {
+		return ErrInvalidCategoryName
+	}
+	return nil
+}
+
+// Validate 验证商品及其图片、规格组
+func (i *MenuItem) Validate() error {
+	if i.MerchantID <= 0 {
+		return ErrInvalidMerchantID
+	}
+	if i.CategoryID <= 0 {
+		return ErrCategoryRequired
+	}
+	if i.Name == "" || len([]rune(i.Name)) > 100 {
+		return ErrInvalidMenuItemName
+	}
+	if i.Price < 0 {
+		return ErrInvalidPrice
+	}
+	if i.Stock != nil && *i.Stock < 0 {
+		return ErrInvalidStock
+	}
+	for _, image := range i.Images {
+		if image.URL == "" {
+			return ErrInvalidImageURL
+		}
+	}
+	for _, group := range i.OptionGroups {
+		if err := group.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate 验证规格组：选择数量范围需与选项数量匹配，必选组至少选一项
+func (g *MenuOptionGroup) Validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("%w: 规格组名称不能为空", ErrInvalidOptionGroup)
+	}
+	if len(g.Options) == 0 {
+		return fmt.Errorf("%w: %s 没有选项", ErrInvalidOptionGroup, g.Name)
+	}
+	if g.Required && g.MinSelect < 1 {
+		return fmt.Errorf("%w: %s 为必选但最少选择数小于1", ErrInvalidOptionGroup, g.Name)
+	}
+	if g.MinSelect < 0 || g.MaxSelect < 1 || g.MinSelect > g.MaxSelect || g.MaxSelect > len(g.Options) {
+		return fmt.Errorf("%w: %s 选择数量范围无效", ErrInvalidOptionGroup, g.Name)
+	}
+	for _, option := range g.Options {
+		if option.Name == "" || option.PriceDelta < 0 {
+			return fmt.Errorf("%w: %s 存在无效选项", ErrInvalidOptionGroup, g.Name)
+		}
+	}
+	return nil
+}
+
+// #endregion
@@ -26,6 +26,8 @@ type Employee struct {
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Role string `json:"role" gorm:"type:varchar(20);not null;default:cashier;comment:员工角色"`
 }
 
 // TableName 设置表名
@@ -47,6 +49,11 @@ type EmployeeResponse struct {
 	Sex        string `json:"sex,omitempty"`
 	MerchantID int64  `json:"merchant_id"`
 	IsActive   bool   `json:"is_active"`
+
+	// Role 员工角色：owner/manager/cashier/kitchen
+	Role string `json:"role"`
+	// Permissions 角色对应的权限，供客户端控制功能入口
+	Permissions []string `json:"permissions"`
 }
 
 // ToResponse 将 Employee 模型转换为响应DTO
@@ -60,6 +67,9 @@ func (e *Employee) ToResponse() *EmployeeResponse {
 		Sex:        e.Sex,
 		MerchantID: e.MerchantID,
 		IsActive:   e.IsActive,
+
+		Role:        e.Role,
+		Permissions: RolePermissions(e.Role),
 	}
 }
 
@@ -73,6 +83,8 @@ type EmployeeSafeResponse struct {
 	Sex        string `json:"sex,omitempty"`
 	MerchantID int64  `json:"merchant_id"`
 	IsActive   bool   `json:"is_active"`
+
+	Role string `json:"role"`
 }
 
 // ToSafeResponse 转换为安全响应（脱敏）
@@ -86,6 +98,8 @@ func (e *Employee) ToSafeResponse() *EmployeeSafeResponse {
 		Sex:        e.Sex,
 		MerchantID: e.MerchantID,
 		IsActive:   e.IsActive,
+
+		Role: e.Role,
 	}
 }
 
@@ -123,6 +137,14 @@ func (e *Employee) ValidateMerchantID() error {
 	return nil
 }
 
+// ValidateRole 验证员工角色，为空时使用默认角色
+func (e *Employee) ValidateRole() error {
+	if e.Role != "" && !IsValidEmployeeRole(e.Role) {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
 // ValidateAll 验证所有字段
 func (e *Employee) ValidateAll() error {
 	if err := e.ValidateIDNumber(); err != nil {
@@ -134,6 +156,9 @@ func (e *Employee) ValidateAll() error {
 	if err := e.ValidateMerchantID(); err != nil {
 		return err
 	}
+	if err := e.ValidateRole(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -180,6 +205,17 @@ func (e *Employee) UpdateProfile(name, sex, idNumber string) error {
 	return nil
 }
 
+// ChangeRole 变更员工角色
+func (e *Employee) ChangeRole(role string) error {
+	if !IsValidEmployeeRole(role) {
+		return ErrInvalidRole
+	}
+
+	e.Role = role
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
 // Activate 激活员工账号
 func (e *Employee) Activate() {
 	e.IsActive = true
@@ -0,0 +1,70 @@
+package model
+
+// #region 常量
+
+// 员工角色，由所属商家分配；商家账号本人即店铺所有者，不占用员工角色
+const (
+	EmployeeRoleOwner   = "owner"   // 店主：全部权限，可管理员工
+	EmployeeRoleManager = "manager" // 店长：订单与菜单管理
+	EmployeeRoleCashier = "cashier" // 收银：接单与取消订单
+	EmployeeRoleKitchen = "kitchen" // 后厨：出餐与沽清
+)
+
+// 员工权限
+const (
+	PermOrderView        = "order.view"        // 查看本店订单
+	PermOrderAccept      = "order.accept"      // 接单
+	PermOrderCancel      = "order.cancel"      // 取消订单
+	PermOrderPrepare     = "order.prepare"     // 标记出餐、派单
+	PermMenuView         = "menu.view"         // 查看本店商品
+	PermMenuEdit         = "menu.edit"         // 管理分类、商品与库存
+	PermMenuAvailability = "menu.availability" // 商品上下架（沽清）
+	PermEmployeeManage   = "employee.manage"   // 添加员工、分配角色
+)
+
+// DefaultEmployeeRole 新增员工的默认角色
+const DefaultEmployeeRole = EmployeeRoleCashier
+
+// rolePermissions 角色到权限的映射
+var rolePermissions = map[string][]string{
+	EmployeeRoleOwner: {
+		PermOrderView, PermOrderAccept, PermOrderCancel, PermOrderPrepare,
+		PermMenuView, PermMenuEdit, PermMenuAvailability, PermEmployeeManage,
+	},
+	EmployeeRoleManager: {
+		PermOrderView, PermOrderAccept, PermOrderCancel, PermOrderPrepare,
+		PermMenuView, PermMenuEdit, PermMenuAvailability,
+	},
+	EmployeeRoleCashier: {PermOrderView, PermOrderAccept, PermOrderCancel, PermMenuView},
+	EmployeeRoleKitchen: {PermOrderView, PermOrderPrepare, PermMenuView, PermMenuAvailability},
+}
+
+// #endregion
+
+// #region 业务方法
+
+// IsValidEmployeeRole 检查员工角色是否有效
+func IsValidEmployeeRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RolePermissions 返回角色拥有的权限，未知角色返回空
+func RolePermissions(role string) []string {
+	return append([]string(nil), rolePermissions[role]...)
+}
+
+// HasPermission 检查员工是否在职且其角色拥有指定权限
+func (e *Employee) HasPermission(permission string) bool {
+	if !e.IsActive {
+		return false
+	}
+	for _, p := range rolePermissions[e.Role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// #endregion
@@ -17,6 +17,8 @@ var (
 	// 手机号验证错误
 	ErrPhoneRequired      = errors.New("手机号不能为空")
 	ErrInvalidPhoneFormat = errors.New("手机号格式无效")
+
+	ErrInvalidUserID = errors.New("无效的用户ID")
 )
 
 // #endregion
@@ -28,6 +30,7 @@ var (
 	ErrInvalidIDNumber   = errors.New("身份证号格式无效")
 	ErrEmployeeNotActive = errors.New("员工账号未激活")
 	ErrInvalidMerchantID = errors.New("无效的商家ID")
+	ErrInvalidRole       = errors.New("员工角色无效")
 )
 
 // #endregion
@@ -39,6 +42,10 @@ var (
 	ErrInvalidBusinessLicense = errors.New("营业执照号格式无效")
 	ErrMerchantNotActive      = errors.New("商家账号未激活")
 	ErrCompanyNameRequired    = errors.New("公司名称不能为空")
+
+	ErrInvalidVerificationTransition = errors.New("当前审核状态不允许该操作")
+
+	ErrStoreAddressRequired = errors.New("门店地址不能为空且不超过255个字符")
 )
 
 // #endregion
@@ -53,6 +60,45 @@ var (
 	ErrRiderOffline         = errors.New("配送员未在线")
 	ErrRiderNotActive       = errors.New("配送员账号未激活")
 	ErrInvalidLocation      = errors.New("无效的位置信息")
+
+	ErrRiderNotVerified      = errors.New("配送员尚未通过资质审核")
+	ErrRiderNameRequired     = errors.New("真实姓名不能为空")
+	ErrVehicleTypeRequired   = errors.New("交通工具类型不能为空")
+	ErrLicenseRequired       = errors.New("摩托车和汽车配送须提供驾驶证号")
+	ErrLicenseIDMismatch     = errors.New("驾驶证号与身份证号不一致")
+	ErrVehicleNumberRequired = errors.New("摩托车和汽车配送须提供车牌号")
+)
+
+// #endregion
+
+// #region 订单相关错误
+
+var (
+	ErrOrderNotFound            = errors.New("订单不存在")
+	ErrOrderItemsEmpty          = errors.New("订单明细不能为空")
+	ErrInvalidOrderItem         = errors.New("订单明细无效")
+	ErrInvalidOrderAmount       = errors.New("订单金额无效")
+	ErrOrderAddressRequired     = errors.New("取货地址和收货地址不能为空")
+	ErrInvalidOrderTransition   = errors.New("订单状态不允许该操作")
+	ErrOrderTransitionForbidden = errors.New("无权执行该订单操作")
+	ErrInvalidRiderRating       = errors.New("评分必须在1到5之间")
+)
+
+// #endregion
+
+// #region 商品目录相关错误
+
+var (
+	ErrInvalidCategoryName = errors.New("分类名称无效")
+	ErrCategoryRequired    = errors.New("商品必须属于一个分类")
+	ErrInvalidMenuItemName = errors.New("商品名称无效")
+	ErrInvalidPrice        = errors.New("价格不能为负数")
+	ErrInvalidStock        = errors.New("库存不能为负数")
+	ErrInvalidImageURL     = errors.New("图片地址不能为空")
+	ErrInvalidOptionGroup  = errors.New("规格组无效")
+
+	ErrInvalidOptionSelection = errors.New("所选规格无效")
+	ErrOptionUnavailable      = errors.New("所选规格已售罄")
 )
 
 // #endregion
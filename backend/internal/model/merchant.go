@@ -24,6 +24,17 @@ type Merchant struct {
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 资质审核：审核通过前不能经营（接单、管理菜单与员工）
+	VerificationStatus       string     `json:"verification_status" gorm:"type:varchar(20);not null;default:draft;index;comment:资质审核状态"`
+	VerificationRejectReason string     `json:"verification_reject_reason,omitempty" gorm:"type:varchar(500);comment:审核驳回原因"`
+	VerificationSubmittedAt  *time.Time `json:"verification_submitted_at,omitempty" gorm:"comment:最近提交审核时间"`
+	VerifiedAt               *time.Time `json:"verified_at,omitempty" gorm:"comment:审核通过时间"`
+
+	// 门店地址：下单时快照为订单的取货地址，未设置时不能下单
+	StoreAddress string  `json:"store_address,omitempty" gorm:"type:varchar(255);comment:门店地址"`
+	StoreLat     float64 `json:"store_lat,omitempty" gorm:"comment:门店纬度"`
+	StoreLng     float64 `json:"store_lng,omitempty" gorm:"comment:门店经度"`
 }
 
 // TableName 设置表名
@@ -44,6 +55,14 @@ type MerchantResponse struct {
 	CompanyName     string `json:"company_name,omitempty"`
 	BusinessLicense string `json:"business_license,omitempty"`
 	IsActive        bool   `json:"is_active"`
+
+	// VerificationStatus 资质审核状态：draft/submitted/under_review/approved/rejected
+	VerificationStatus string `json:"verification_status"`
+
+	// 门店地址：下单时作为取货地址
+	StoreAddress string  `json:"store_address,omitempty"`
+	StoreLat     float64 `json:"store_lat,omitempty"`
+	StoreLng     float64 `json:"store_lng,omitempty"`
 }
 
 // ToResponse 将 Merchant 模型转换为响应DTO
@@ -56,6 +75,12 @@ func (m *Merchant) ToResponse() *MerchantResponse {
 		CompanyName:     m.CompanyName,
 		BusinessLicense: m.BusinessLicense,
 		IsActive:        m.IsActive,
+
+		VerificationStatus: m.VerificationStatus,
+
+		StoreAddress: m.StoreAddress,
+		StoreLat:     m.StoreLat,
+		StoreLng:     m.StoreLng,
 	}
 }
 
@@ -129,6 +154,30 @@ func (m *Merchant) ValidateAll() error {
 
 // #region 业务方法
 
+// CanTrade 商家是否可以经营：账号启用且资质审核已通过
+func (m *Merchant) CanTrade() bool {
+	return m.IsActive && m.VerificationStatus == VerificationApproved
+}
+
+// HasStoreLocation 是否已设置门店地址
+func (m *Merchant) HasStoreLocation() bool {
+	return m.StoreAddress != ""
+}
+
+// SetStoreLocation 设置门店地址与坐标
+func (m *Merchant) SetStoreLocation(address string, lat, lng float64) error {
+	if address == "" || len([]rune(address)) > 255 {
+		return ErrStoreAddressRequired
+	}
+	if !isValidCoordinate(lat, lng) {
+		return ErrInvalidLocation
+	}
+	m.StoreAddress = address
+	m.StoreLat = lat
+	m.StoreLng = lng
+	return nil
+}
+
 // IsActiveMerchant 检查商家是否为激活状态
 func (m *Merchant) IsActiveMerchant() bool {
 	return m.IsActive
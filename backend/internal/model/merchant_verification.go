@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// #region 常量
+
+// 商家资质材料类型
+const (
+	MerchantDocBusinessLicense = "business_license" // 营业执照（提交审核必需）
+	MerchantDocFoodLicense     = "food_license"     // 食品经营许可证
+	MerchantDocOther           = "other"            // 其他补充材料
+)
+
+// 商家资质审核相关审计动作
+const (
+	AuditActionMerchantReview  = "merchant.verification.review"
+	AuditActionMerchantApprove = "merchant.verification.approve"
+	AuditActionMerchantReject  = "merchant.verification.reject"
+)
+
+// #endregion
+
+// #region 业务方法
+
+// IsValidMerchantDocType 检查商家材料类型是否有效
+func IsValidMerchantDocType(docType string) bool {
+	switch docType {
+	case MerchantDocBusinessLicense, MerchantDocFoodLicense, MerchantDocOther:
+		return true
+	}
+	return false
+}
+
+// TransitionVerification 按状态机迁移商家审核状态，并维护提交/通过时间与驳回原因
+func (m *Merchant) TransitionVerification(to, rejectReason string, now time.Time) error {
+	if !CanTransitionVerification(m.VerificationStatus, to) {
+		return ErrInvalidVerificationTransition
+	}
+
+	m.VerificationStatus = to
+	switch to {
+	case VerificationSubmitted:
+		m.VerificationSubmittedAt = &now
+		m.VerificationRejectReason = ""
+	case VerificationApproved:
+		m.VerifiedAt = &now
+	case VerificationRejected:
+		m.VerificationRejectReason = rejectReason
+	}
+	return nil
+}
+
+// #endregion
@@ -0,0 +1,243 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// #region 模型定义
+
+// Order 订单模型（金额单位：分）
+type Order struct {
+	ID         int64       `json:"id" gorm:"primaryKey;autoIncrement;comment:订单ID"`
+	OrderNo    string      `json:"order_no" gorm:"type:varchar(32);uniqueIndex;not null;comment:订单号"`
+	UserID     int64       `json:"user_id" gorm:"not null;index;comment:下单用户ID"`
+	MerchantID int64       `json:"merchant_id" gorm:"not null;index;comment:商家ID"`
+	RiderID    *int64      `json:"rider_id,omitempty" gorm:"index;comment:配送员ID"`
+	Status     string      `json:"status" gorm:"type:varchar(20);not null;index;comment:订单状态"`
+	Items      []OrderItem `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+
+	// 取货与收货地址（下单时快照）
+	PickupAddress   string  `json:"pickup_address" gorm:"type:varchar(255);not null;comment:取货地址"`
+	PickupLat       float64 `json:"pickup_lat" gorm:"comment:取货纬度"`
+	PickupLng       float64 `json:"pickup_lng" gorm:"comment:取货经度"`
+	DeliveryAddress string  `json:"delivery_address" gorm:"type:varchar(255);not null;comment:收货地址"`
+	DeliveryLat     float64 `json:"delivery_lat" gorm:"comment:收货纬度"`
+	DeliveryLng     float64 `json:"delivery_lng" gorm:"comment:收货经度"`
+	ContactName     string  `json:"contact_name" gorm:"type:varchar(50);comment:收货人"`
+	ContactPhone    string  `json:"contact_phone" gorm:"type:varchar(20);comment:收货人电话"`
+
+	// 金额
+	ItemsAmount int64 `json:"items_amount" gorm:"not null;default:0;comment:商品金额（分）"`
+	DeliveryFee int64 `json:"delivery_fee" gorm:"not null;default:0;comment:配送费（分）"`
+	TotalAmount int64 `json:"total_amount" gorm:"not null;default:0;comment:订单总额（分）"`
+
+	Remark       string  `json:"remark,omitempty" gorm:"type:varchar(255);comment:备注"`
+	CancelReason string  `json:"cancel_reason,omitempty" gorm:"type:varchar(255);comment:取消原因"`
+	CancelledBy  string  `json:"cancelled_by,omitempty" gorm:"type:varchar(20);comment:取消方"`
+	RiderRating  float32 `json:"rider_rating,omitempty" gorm:"default:0;comment:用户对配送员的评分，0表示未评价"`
+
+	// 状态时间线
+	AcceptedAt  *time.Time     `json:"accepted_at,omitempty" gorm:"comment:商家接单时间"`
+	PreparedAt  *time.Time     `json:"prepared_at,omitempty" gorm:"comment:出餐时间"`
+	PickedUpAt  *time.Time     `json:"picked_up_at,omitempty" gorm:"comment:取货时间"`
+	DeliveredAt *time.Time     `json:"delivered_at,omitempty" gorm:"comment:送达时间"`
+	CancelledAt *time.Time     `json:"cancelled_at,omitempty" gorm:"comment:取消时间"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName 设置表名
+func (Order) TableName() string {
+	return "orders"
+}
+
+// OrderItem 订单明细（商品名称与单价为下单时从商品目录取得的快照）
+type OrderItem struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement;comment:明细ID"`
+	OrderID   int64     `json:"order_id" gorm:"not null;index;comment:订单ID"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null;comment:商品名称"`
+	UnitPrice int64     `json:"unit_price" gorm:"not null;comment:单价（分）"`
+	Quantity  int       `json:"quantity" gorm:"not null;comment:数量"`
+	Amount    int64     `json:"amount" gorm:"not null;comment:小计（分）"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// MenuItemID 下单的商品，取消订单时据此退回库存
+	MenuItemID int64 `json:"menu_item_id" gorm:"index;comment:商品ID"`
+
+	// Options 所选规格，下单时只需提交 OptionID，其余字段由商品目录填充；加价已计入 UnitPrice
+	Options []OrderItemOption `json:"options,omitempty" gorm:"foreignKey:OrderItemID"`
+}
+
+// TableName 设置表名
+func (OrderItem) TableName() string {
+	return "order_items"
+}
+
+// OrderItemOption 订单明细所选规格（规格组、选项名称与加价为下单时的快照）
+type OrderItemOption struct {
+	ID          int64  `json:"id" gorm:"primaryKey;autoIncrement;comment:记录ID"`
+	OrderItemID int64  `json:"order_item_id" gorm:"not null;index;comment:订单明细ID"`
+	OptionID    int64  `json:"option_id" gorm:"not null;comment:规格选项ID"`
+	GroupName   string `json:"group_name" gorm:"type:varchar(50);not null;comment:规格组名称"`
+	Name        string `json:"name" gorm:"type:varchar(50);not null;comment:选项名称"`
+	PriceDelta  int64  `json:"price_delta" gorm:"not null;default:0;comment:加价（分）"`
+}
+
+// TableName 设置表名
+func (OrderItemOption) TableName() string {
+	return "order_item_options"
+}
+
+// #endregion
+
+// #region 常量定义
+
+// 订单状态
+const (
+	OrderStatusCreated   = "created"   // 已下单，待商家接单
+	OrderStatusAccepted  = "accepted"  // 商家已接单
+	OrderStatusPrepared  = "prepared"  // 已出餐，待取货
+	OrderStatusPickedUp  = "picked_up" // 配送员已取货
+	OrderStatusDelivered = "delivered" // 已送达
+	OrderStatusCancelled = "cancelled" // 已取消
+)
+
+// 订单操作方（与JWT中的用户类型一致）
+const (
+	OrderActorUser     = "user"
+	OrderActorMerchant = "merchant"
+	OrderActorEmployee = "employee"
+	OrderActorRider    = "rider"
+)
+
+// 评分范围
+const (
+	MinRiderRating = 1
+	MaxRiderRating = 5
+)
+
+// orderTransition 状态迁移（from -> to）
+type orderTransition struct {
+	from string
+	to   string
+}
+
+// orderTransitionActors 状态机：每条合法迁移及允许执行它的操作方
+//
+//	created -> accepted -> prepared -> picked_up -> delivered
+//	   \___________\___________\_______-> cancelled
+//
+// 用户只能在商家接单前取消；商家（含员工）在取货前均可取消；取货后订单不可取消。
+var orderTransitionActors = map[orderTransition][]string{
+	{OrderStatusCreated, OrderStatusAccepted}:   {OrderActorMerchant, OrderActorEmployee},
+	{OrderStatusAccepted, OrderStatusPrepared}:  {OrderActorMerchant, OrderActorEmployee},
+	{OrderStatusPrepared, OrderStatusPickedUp}:  {OrderActorRider},
+	{OrderStatusPickedUp, OrderStatusDelivered}: {OrderActorRider},
+	{OrderStatusCreated, OrderStatusCancelled}:  {OrderActorUser, OrderActorMerchant, OrderActorEmployee},
+	{OrderStatusAccepted, OrderStatusCancelled}: {OrderActorMerchant, OrderActorEmployee},
+	{OrderStatusPrepared, OrderStatusCancelled}: {OrderActorMerchant, OrderActorEmployee},
+}
+
+// #endregion
+
+// #region 业务方法
+
+// CanTransitionTo 判断订单能否迁移到目标状态（不考虑操作方）
+func (o *Order) CanTransitionTo(to string) bool {
+	_, ok := orderTransitionActors[orderTransition{o.Status, to}]
+	return ok
+}
+
+// CheckTransition 校验操作方能否将订单迁移到目标状态
+// 仅校验状态机与角色；操作方是否属于该订单（商家归属、指派的配送员）由服务层判断
+func (o *Order) CheckTransition(to, actorType string) error {
+	actors, ok := orderTransitionActors[orderTransition{o.Status, to}]
+	if !ok {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidOrderTransition, o.Status, to)
+	}
+	for _, actor := range actors {
+		if actor == actorType {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s 不能执行 %s -> %s", ErrOrderTransitionForbidden, actorType, o.Status, to)
+}
+
+// ApplyTransition 迁移到目标状态并记录时间线（调用前应先通过 CheckTransition）
+func (o *Order) ApplyTransition(to string, at time.Time) {
+	o.Status = to
+	switch to {
+	case OrderStatusAccepted:
+		o.AcceptedAt = &at
+	case OrderStatusPrepared:
+		o.PreparedAt = &at
+	case OrderStatusPickedUp:
+		o.PickedUpAt = &at
+	case OrderStatusDelivered:
+		o.DeliveredAt = &at
+	case OrderStatusCancelled:
+		o.CancelledAt = &at
+	}
+}
+
+// IsFinal 订单是否已结束（已送达或已取消）
+func (o *Order) IsFinal() bool {
+	return o.Status == OrderStatusDelivered || o.Status == OrderStatusCancelled
+}
+
+// CalculateAmounts 根据明细计算商品金额与订单总额
+func (o *Order) CalculateAmounts() {
+	var itemsAmount int64
+	for i := range o.Items {
+		o.Items[i].Amount = o.Items[i].UnitPrice * int64(o.Items[i].Quantity)
+		itemsAmount += o.Items[i].Amount
+	}
+	o.ItemsAmount = itemsAmount
+	o.TotalAmount = itemsAmount + o.DeliveryFee
+}
+
+// #endregion
+
+// #region 验证方法
+
+// Validate 验证新订单数据
+func (o *Order) Validate() error {
+	if o.UserID <= 0 {
+		return ErrInvalidUserID
+	}
+	if o.MerchantID <= 0 {
+		return ErrInvalidMerchantID
+	}
+	if len(o.Items) == 0 {
+		return ErrOrderItemsEmpty
+	}
+	for _, item := range o.Items {
+		if item.Name == "" || item.UnitPrice < 0 || item.Quantity <= 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidOrderItem, item.Name)
+		}
+	}
+	if o.DeliveryFee < 0 {
+		return ErrInvalidOrderAmount
+	}
+	if o.PickupAddress == "" || o.DeliveryAddress == "" {
+		return ErrOrderAddressRequired
+	}
+	if !isValidCoordinate(o.PickupLat, o.PickupLng) || !isValidCoordinate(o.DeliveryLat, o.DeliveryLng) {
+		return ErrInvalidLocation
+	}
+	if o.ContactPhone != "" && !phoneRegex.MatchString(o.ContactPhone) {
+		return ErrInvalidPhoneFormat
+	}
+	return nil
+}
+
+// isValidCoordinate 校验经纬度范围
+func isValidCoordinate(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// #endregion
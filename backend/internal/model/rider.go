@@ -30,9 +30,16 @@ type Rider struct {
 	IsActive      bool           `json:"is_active" gorm:"default:true;comment:是否激活"`
 	Rating        float32        `json:"rating" gorm:"default:5.0;comment:评分"`
 	TotalOrders   int64          `json:"total_orders" gorm:"default:0;comment:总订单数"`
+	RatingCount   int64          `json:"rating_count" gorm:"default:0;comment:评分次数"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 资质审核：审核通过前不能上线接单
+	VerificationStatus       string     `json:"verification_status" gorm:"type:varchar(20);not null;default:draft;index;comment:资质审核状态"`
+	VerificationRejectReason string     `json:"verification_reject_reason,omitempty" gorm:"type:varchar(500);comment:审核驳回原因"`
+	VerificationSubmittedAt  *time.Time `json:"verification_submitted_at,omitempty" gorm:"comment:最近提交审核时间"`
+	VerifiedAt               *time.Time `json:"verified_at,omitempty" gorm:"comment:审核通过时间"`
 }
 
 // TableName 设置表名
@@ -51,6 +58,9 @@ const (
 	VehicleTypeCar        = "car"        // 汽车
 )
 
+// EarthRadiusKm 地球平均半径（公里），用于球面距离计算
+const EarthRadiusKm = 6371.0
+
 // 有效的交通工具类型列表
 var ValidVehicleTypes = []string{
 	VehicleTypeBike,
@@ -75,6 +85,9 @@ type RiderResponse struct {
 	IsActive      bool    `json:"is_active"`
 	Rating        float32 `json:"rating"`
 	TotalOrders   int64   `json:"total_orders"`
+
+	// VerificationStatus 资质审核状态：draft/submitted/under_review/approved/rejected
+	VerificationStatus string `json:"verification_status"`
 }
 
 // ToResponse 将 Rider 模型转换为响应DTO
@@ -91,9 +104,24 @@ func (r *Rider) ToResponse() *RiderResponse {
 		IsActive:      r.IsActive,
 		Rating:        r.Rating,
 		TotalOrders:   r.TotalOrders,
+
+		VerificationStatus: r.VerificationStatus,
 	}
 }
 
+// RiderPosition 配送员实时位置（来自位置上报）
+type RiderPosition struct {
+	RiderID int64   `json:"rider_id"`
+	Lat     float64 `json:"lat"`
+	Lng     float64 `json:"lng"`
+}
+
+// NearbyRider 附近配送员及其与查询点的球面距离
+type NearbyRider struct {
+	Rider      *Rider  `json:"rider"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
 // RiderLocationResponse 配送员位置响应DTO
 type RiderLocationResponse struct {
 	ID         int64   `json:"id"`
@@ -174,8 +202,8 @@ func (r *Rider) ValidateVehicleNumber() error {
 		return nil // 车牌号可选
 	}
 
-	// 简单的车牌号验证（中国车牌格式）
-	matched, _ := regexp.MatchString(`^[京津沪渝冀豫云辽黑湘皖鲁新苏浙赣鄂桂甘晋蒙陕吉闽贵粤青藏川宁琼使领A-Z]{1}[A-Z]{1}[A-Z0-9]{4}[A-Z0-9挂学警港澳]{1}$`, r.VehicleNumber)
+	// 简单的车牌号验证（中国车牌格式，新能源车牌多一位）
+	matched, _ := regexp.MatchString(`^[京津沪渝冀豫云辽黑湘皖鲁新苏浙赣鄂桂甘晋蒙陕吉闽贵粤青藏川宁琼使领A-Z]{1}[A-Z]{1}[A-Z0-9]{4,5}[A-Z0-9挂学警港澳]{1}$`, r.VehicleNumber)
 	if !matched {
 		return ErrInvalidVehicleNumber
 	}
@@ -188,8 +216,8 @@ func (r *Rider) ValidateLicenseNumber() error {
 		return nil // 驾照号可选
 	}
 
-	// 驾照号验证（通常是18位数字）
-	matched, _ := regexp.MatchString(`^\d{18}$`, r.LicenseNumber)
+	// 驾驶证号与持证人身份证号一致：17 位数字 + 数字或 X
+	matched, _ := regexp.MatchString(`^\d{17}[\dXx]$`, r.LicenseNumber)
 	if !matched {
 		return ErrInvalidLicenseNumber
 	}
@@ -259,8 +287,6 @@ func (r *Rider) GetCurrentLocation() (float64, float64) {
 // CalculateDistance 计算与指定位置的距离（公里）
 func (r *Rider) CalculateDistance(lat, lng float64) float64 {
 	// 使用 Haversine 公式计算两点间距离
-	const earthRadius = 6371.0 // 地球半径（公里）
-
 	lat1 := r.CurrentLat * math.Pi / 180
 	lng1 := r.CurrentLng * math.Pi / 180
 	lat2 := lat * math.Pi / 180
@@ -272,7 +298,7 @@ func (r *Rider) CalculateDistance(lat, lng float64) float64 {
 	a := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dlng/2)*math.Sin(dlng/2)
 	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
 
-	return earthRadius * c
+	return EarthRadiusKm * c
 }
 
 // IsNearLocation 检查是否在指定位置附近（范围内，单位：公里）
@@ -281,6 +307,34 @@ func (r *Rider) IsNearLocation(lat, lng, radiusKm float64) bool {
 	return distance <= radiusKm
 }
 
+// BoundingBox 计算以 (lat, lng) 为圆心、radiusKm 为半径的球面圆的外接经纬度矩形
+// 经度跨度随纬度变化：Δlng = asin(sin(δ) / cos(lat))，δ 为半径对应的圆心角；
+// 圆覆盖极点时经度取全范围；跨越 180° 经线时返回 minLng > maxLng，调用方需按两段区间查询
+func BoundingBox(lat, lng, radiusKm float64) (minLat, maxLat, minLng, maxLng float64) {
+	angular := radiusKm / EarthRadiusKm
+	latRad := lat * math.Pi / 180
+	deltaLat := angular * 180 / math.Pi
+
+	minLat = math.Max(lat-deltaLat, -90)
+	maxLat = math.Min(lat+deltaLat, 90)
+
+	ratio := math.Sin(angular) / math.Cos(latRad)
+	if lat+deltaLat >= 90 || lat-deltaLat <= -90 || ratio >= 1 || angular >= math.Pi/2 {
+		return minLat, maxLat, -180, 180
+	}
+
+	deltaLng := math.Asin(ratio) * 180 / math.Pi
+	minLng = lng - deltaLng
+	maxLng = lng + deltaLng
+	if minLng < -180 {
+		minLng += 360
+	}
+	if maxLng > 180 {
+		maxLng -= 360
+	}
+	return minLat, maxLat, minLng, maxLng
+}
+
 // #endregion
 
 // #region 状态管理
@@ -290,6 +344,9 @@ func (r *Rider) GoOnline() error {
 	if !r.IsActive {
 		return ErrRiderNotActive
 	}
+	if !r.IsVerified() {
+		return ErrRiderNotVerified
+	}
 
 	r.IsOnline = true
 	r.UpdatedAt = time.Now()
@@ -304,7 +361,7 @@ func (r *Rider) GoOffline() {
 
 // IsAvailableForOrder 检查是否可接订单
 func (r *Rider) IsAvailableForOrder() bool {
-	return r.IsActive && r.IsOnline
+	return r.IsActive && r.IsOnline && r.IsVerified()
 }
 
 // Activate 激活配送员账号
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+// destination 从 (lat, lng) 沿方位角 bearing（度）走 distanceKm 后到达的点
+func destination(lat, lng, bearing, distanceKm float64) (float64, float64) {
+	angular := distanceKm / EarthRadiusKm
+	lat1 := lat * math.Pi / 180
+	lng1 := lng * math.Pi / 180
+	theta := bearing * math.Pi / 180
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(angular) + math.Cos(lat1)*math.Sin(angular)*math.Cos(theta))
+	lng2 := lng1 + math.Atan2(math.Sin(theta)*math.Sin(angular)*math.Cos(lat1), math.Cos(angular)-math.Sin(lat1)*math.Sin(lat2))
+
+	lngDeg := math.Mod(lng2*180/math.Pi+540, 360) - 180
+	return lat2 * 180 / math.Pi, lngDeg
+}
+
+func inLngRange(lng, minLng, maxLng float64) bool {
+	if minLng <= maxLng {
+		return lng >= minLng && lng <= maxLng
+	}
+	return lng >= minLng || lng <= maxLng
+}
+
+func TestBoundingBox(t *testing.T) {
+	const eps = 1e-6
+	oneDegreeKm := EarthRadiusKm * math.Pi / 180
+
+	cases := []struct {
+		name     string
+		lat, lng float64
+		radiusKm float64
+		// 期望的外接矩形
+		minLat, maxLat, minLng, maxLng float64
+	}{
+		{"equator", 0, 0, oneDegreeKm, -1, 1, -1, 1},
+		{"60 degrees", 60, 10, oneDegreeKm, 59, 61,
+			10 - math.Asin(math.Sin(math.Pi/180)/0.5)*180/math.Pi,
+			10 + math.Asin(math.Sin(math.Pi/180)/0.5)*180/math.Pi},
+		{"near north pole", 89.5, 30, oneDegreeKm, 88.5, 90, -180, 180},
+		{"near south pole", -89.5, 30, oneDegreeKm, -90, -88.5, -180, 180},
+		{"antimeridian east", 0, 179.5, oneDegreeKm, -1, 1, 178.5, -179.5},
+		{"antimeridian west", 0, -179.5, oneDegreeKm, -1, 1, 179.5, -178.5},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			minLat, maxLat, minLng, maxLng := BoundingBox(tc.lat, tc.lng, tc.radiusKm)
+			got := []float64{minLat, maxLat, minLng, maxLng}
+			want := []float64{tc.minLat, tc.maxLat, tc.minLng, tc.maxLng}
+			for i := range got {
+				if math.Abs(got[i]-want[i]) > eps {
+					t.Fatalf("box=%v want %v", got, want)
+				}
+			}
+
+			// 圆周上各方向的点都落在矩形内
+			for bearing := 0.0; bearing < 360; bearing += 5 {
+				lat, lng := destination(tc.lat, tc.lng, bearing, tc.radiusKm*0.999)
+				if lat < minLat || lat > maxLat || !inLngRange(lng, minLng, maxLng) {
+					t.Fatalf("bearing %v point (%v, %v) outside box %v", bearing, lat, lng, got)
+				}
+			}
+		})
+	}
+
+	// 60° 处经度跨度约为赤道处的两倍，固定系数 0.707 会漏掉东西两侧
+	_, _, minLng, maxLng := BoundingBox(60, 10, oneDegreeKm)
+	if span := maxLng - minLng; span < 3.99 || span > 4.01 {
+		t.Fatalf("lng span at 60°=%v want ~4", span)
+	}
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Hermitf/the-pass/pkg/validator"
+)
+
+// #region 常量
+
+// 配送员资质材料类型
+const (
+	RiderDocIDCardFront    = "id_card_front"   // 身份证人像面
+	RiderDocIDCardBack     = "id_card_back"    // 身份证国徽面
+	RiderDocDriverLicense  = "driver_license"  // 驾驶证（摩托车、汽车必需）
+	RiderDocVehicleLicense = "vehicle_license" // 行驶证（摩托车、汽车必需）
+	RiderDocOther          = "other"           // 其他补充材料
+)
+
+// 配送员资质审核相关审计动作
+const (
+	AuditActionRiderReview  = "rider.verification.review"
+	AuditActionRiderApprove = "rider.verification.approve"
+	AuditActionRiderReject  = "rider.verification.reject"
+)
+
+// #endregion
+
+// #region 业务方法
+
+// IsValidRiderDocType 检查配送员材料类型是否有效
+func IsValidRiderDocType(docType string) bool {
+	switch docType {
+	case RiderDocIDCardFront, RiderDocIDCardBack, RiderDocDriverLicense, RiderDocVehicleLicense, RiderDocOther:
+		return true
+	}
+	return false
+}
+
+// RequiredRiderDocuments 按交通工具类型返回提交审核必需的材料
+// 自行车（含电动自行车）只需身份证；摩托车、汽车还需驾驶证与行驶证
+func RequiredRiderDocuments(vehicleType string) []string {
+	docs := []string{RiderDocIDCardFront, RiderDocIDCardBack}
+	if requiresMotorLicense(vehicleType) {
+		docs = append(docs, RiderDocDriverLicense, RiderDocVehicleLicense)
+	}
+	return docs
+}
+
+// ValidateForVerification 提交审核前校验身份与车辆信息
+//
+// 身份证号按 GB 11643 校验出生日期与校验码；驾驶证号即持证人身份证号，填写时必须一致；
+// 摩托车、汽车须提供驾驶证号与车牌号。
+func (r *Rider) ValidateForVerification() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return ErrRiderNameRequired
+	}
+	if err := validator.ValidateResidentIDNumber(r.IDNumber); err != nil {
+		return ErrInvalidIDNumber
+	}
+	if r.VehicleType == "" {
+		return ErrVehicleTypeRequired
+	}
+	if err := r.ValidateAll(); err != nil {
+		return err
+	}
+
+	if requiresMotorLicense(r.VehicleType) {
+		if r.LicenseNumber == "" {
+			return ErrLicenseRequired
+		}
+		if r.VehicleNumber == "" {
+			return ErrVehicleNumberRequired
+		}
+	}
+	if r.LicenseNumber != "" && !strings.EqualFold(r.LicenseNumber, r.IDNumber) {
+		return ErrLicenseIDMismatch
+	}
+	return nil
+}
+
+// IsVerified 配送员资质审核是否已通过
+func (r *Rider) IsVerified() bool {
+	return r.VerificationStatus == VerificationApproved
+}
+
+// TransitionVerification 按状态机迁移配送员审核状态，并维护提交/通过时间与驳回原因
+func (r *Rider) TransitionVerification(to, rejectReason string, now time.Time) error {
+	if !CanTransitionVerification(r.VerificationStatus, to) {
+		return ErrInvalidVerificationTransition
+	}
+
+	r.VerificationStatus = to
+	switch to {
+	case VerificationSubmitted:
+		r.VerificationSubmittedAt = &now
+		r.VerificationRejectReason = ""
+	case VerificationApproved:
+		r.VerifiedAt = &now
+	case VerificationRejected:
+		r.VerificationRejectReason = rejectReason
+	}
+	return nil
+}
+
+// requiresMotorLicense 机动车配送须持驾驶证
+func requiresMotorLicense(vehicleType string) bool {
+	return vehicleType == VehicleTypeMotorcycle || vehicleType == VehicleTypeCar
+}
+
+// #endregion
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// #region 模型定义
+
+// SMSSendLog 短信发送日志
+// 记录每条短信从入队、服务商受理到送达回执的状态（状态取值见 sms.SendStatus*）
+// 手机号只保存脱敏形式，短信正文与验证码不落库
+type SMSSendLog struct {
+	ID           int64      `json:"id" gorm:"primaryKey;autoIncrement;comment:日志ID"`
+	MessageID    string     `json:"message_id" gorm:"type:varchar(64);uniqueIndex;not null;comment:消息ID"`
+	Phone        string     `json:"phone" gorm:"type:varchar(20);not null;comment:脱敏手机号"`
+	Category     string     `json:"category" gorm:"type:varchar(50);not null;comment:业务分类"`
+	TemplateCode string     `json:"template_code,omitempty" gorm:"type:varchar(50);comment:服务商模板编号"`
+	Status       string     `json:"status" gorm:"type:varchar(20);not null;comment:发送状态"`
+	Provider     string     `json:"provider,omitempty" gorm:"type:varchar(50);index:idx_sms_send_logs_receipt,priority:1;comment:受理服务商"`
+	ReceiptID    string     `json:"receipt_id,omitempty" gorm:"type:varchar(100);index:idx_sms_send_logs_receipt,priority:2;comment:服务商流水号"`
+	Attempts     int        `json:"attempts" gorm:"not null;default:0;comment:发送次数"`
+	ErrorCode    string     `json:"error_code,omitempty" gorm:"type:varchar(100);comment:回执状态码"`
+	ErrorMessage string     `json:"error_message,omitempty" gorm:"type:varchar(500);comment:失败原因"`
+	SentAt       *time.Time `json:"sent_at,omitempty" gorm:"comment:服务商受理时间"`
+	DeliveredAt  *time.Time `json:"delivered_at,omitempty" gorm:"comment:送达回执时间"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 设置表名
+func (SMSSendLog) TableName() string {
+	return "sms_send_logs"
+}
+
+// #endregion
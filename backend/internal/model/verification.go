@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// #region 常量
+
+// 资质审核状态（商家、配送员共用）
+// draft/rejected →(提交) submitted →(管理员受理) under_review →(管理员审核) approved / rejected
+const (
+	VerificationDraft       = "draft"
+	VerificationSubmitted   = "submitted"
+	VerificationUnderReview = "under_review"
+	VerificationApproved    = "approved"
+	VerificationRejected    = "rejected"
+)
+
+// verificationTransitions 允许的审核状态迁移
+var verificationTransitions = map[string][]string{
+	VerificationDraft:       {VerificationSubmitted},
+	VerificationRejected:    {VerificationSubmitted},
+	VerificationSubmitted:   {VerificationUnderReview},
+	VerificationUnderReview: {VerificationApproved, VerificationRejected},
+}
+
+// #endregion
+
+// #region 模型定义
+
+// VerificationDocument 资质审核材料（商家营业执照、配送员证件等）
+// 文件本身保存在文件存储中，表中只记录存储键；OwnerType 取 AccountType* 常量
+type VerificationDocument struct {
+	ID          int64     `json:"id" gorm:"primaryKey;autoIncrement;comment:材料ID"`
+	OwnerType   string    `json:"owner_type" gorm:"type:varchar(20);not null;comment:所属账号类型"`
+	OwnerID     int64     `json:"owner_id" gorm:"not null;comment:所属账号ID"`
+	DocType     string    `json:"doc_type" gorm:"type:varchar(30);not null;comment:材料类型"`
+	FileName    string    `json:"file_name" gorm:"type:varchar(255);comment:原始文件名"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(100);comment:文件类型"`
+	Size        int64     `json:"size" gorm:"comment:文件大小(字节)"`
+	StorageKey  string    `json:"-" gorm:"type:varchar(255);not null;comment:存储键"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName 设置表名
+func (VerificationDocument) TableName() string {
+	return "verification_documents"
+}
+
+// #endregion
+
+// #region 业务方法
+
+// CanTransitionVerification 检查审核状态迁移是否合法
+func CanTransitionVerification(from, to string) bool {
+	for _, next := range verificationTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidVerificationStatus 检查审核状态是否有效
+func IsValidVerificationStatus(status string) bool {
+	switch status {
+	case VerificationDraft, VerificationSubmitted, VerificationUnderReview, VerificationApproved, VerificationRejected:
+		return true
+	}
+	return false
+}
+
+// CanEditVerificationDocuments 仅草稿或被驳回状态下允许增删审核材料
+func CanEditVerificationDocuments(status string) bool {
+	return status == VerificationDraft || status == VerificationRejected
+}
+
+// #endregion
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"gorm.io/gorm"
+)
+
+// #region 仓库定义
+
+// AdminRepositoryInterface 管理员仓库接口
+type AdminRepositoryInterface interface {
+	Create(ctx context.Context, admin *model.Admin) error
+	GetByID(ctx context.Context, id int64) (*model.Admin, error)
+	GetByUsername(ctx context.Context, username string) (*model.Admin, error)
+	UpdateLastLogin(ctx context.Context, id int64, at time.Time) error
+	ExistsWithUsername(ctx context.Context, username string) (bool, error)
+}
+
+// AdminRepository 管理员仓库实现
+type AdminRepository struct {
+	db *gorm.DB
+}
+
+// NewAdminRepository 创建管理员仓库实例
+func NewAdminRepository(db *gorm.DB) AdminRepositoryInterface {
+	return &AdminRepository{
+		db: db,
+	}
+}
+
+// conn 解析本次调用使用的连接：ctx 中有事务时加入事务
+func (r *AdminRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+// #endregion
+
+// #region 基础操作
+
+// Create 创建管理员
+func (r *AdminRepository) Create(ctx context.Context, admin *model.Admin) error {
+	if admin == nil {
+		return ErrAdminNil
+	}
+
+	return r.conn(ctx).Create(admin).Error
+}
+
+// GetByID 根据ID获取管理员
+func (r *AdminRepository) GetByID(ctx context.Context, id int64) (*model.Admin, error) {
+	if id <= 0 {
+		return nil, ErrAdminIDInvalid
+	}
+
+	var admin model.Admin
+	if err := r.conn(ctx).Where("id = ?", id).First(&admin).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAdminNotFound
+		}
+		return nil, err
+	}
+	return &admin, nil
+}
+
+// GetByUsername 根据用户名获取管理员
+func (r *AdminRepository) GetByUsername(ctx context.Context, username string) (*model.Admin, error) {
+	if username == "" {
+		return nil, ErrUsernameEmpty
+	}
+
+	var admin model.Admin
+	if err := r.conn(ctx).Where("username = ?", username).First(&admin).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAdminNotFound
+		}
+		return nil, err
+	}
+	return &admin, nil
+}
+
+// UpdateLastLogin 只更新最近登录时间
+func (r *AdminRepository) UpdateLastLogin(ctx context.Context, id int64, at time.Time) error {
+	if id <= 0 {
+		return ErrAdminIDInvalid
+	}
+
+	return r.conn(ctx).Model(&model.Admin{}).Where("id = ?", id).Update("last_login_at", at).Error
+}
+
+// ExistsWithUsername 检查用户名是否存在
+func (r *AdminRepository) ExistsWithUsername(ctx context.Context, username string) (bool, error) {
+	if username == "" {
+		return false, ErrUsernameEmpty
+	}
+
+	var count int64
+	if err := r.conn(ctx).Model(&model.Admin{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// #endregion
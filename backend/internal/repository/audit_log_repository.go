@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"gorm.io/gorm"
+)
+
+// #region 仓库定义
+
+// AuditLogFilter 审计日志查询条件，零值字段不参与过滤
+type AuditLogFilter struct {
+	ActorType  string
+	ActorID    int64
+	Action     string
+	TargetType string
+	TargetID   int64
+}
+
+// AuditLogRepositoryInterface 审计日志仓库接口（只追加，不提供更新与删除）
+type AuditLogRepositoryInterface interface {
+	Create(ctx context.Context, entry *model.AuditLog) error
+	List(ctx context.Context, filter AuditLogFilter, offset, limit int) ([]*model.AuditLog, int64, error)
+}
+
+// AuditLogRepository 审计日志仓库实现
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository 创建审计日志仓库实例
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepositoryInterface {
+	return &AuditLogRepository{
+		db: db,
+	}
+}
+
+// conn 解析本次调用使用的连接：ctx 中有事务时加入事务
+func (r *AuditLogRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+// #endregion
+
+// #region 数据访问
+
+// Create 追加一条审计日志
+func (r *AuditLogRepository) Create(ctx context.Context, entry *model.AuditLog) error {
+	if entry == nil {
+		return ErrAuditLogNil
+	}
+
+	return r.conn(ctx).Create(entry).Error
+}
+
+// List 按条件分页查询审计日志，按时间倒序
+func (r *AuditLogRepository) List(ctx context.Context, filter AuditLogFilter, offset, limit int) ([]*model.AuditLog, int64, error) {
+	if offset < 0 || limit <= 0 {
+		return nil, 0, ErrPaginationInvalid
+	}
+
+	query := r.conn(ctx).Model(&model.AuditLog{})
+	if filter.ActorType != "" {
+		query = query.Where("actor_type = ?", filter.ActorType)
+	}
+	if filter.ActorID > 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID > 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []*model.AuditLog
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// #endregion
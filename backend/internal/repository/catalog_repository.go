@@ -0,0 +1,374 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// #region 仓库定义
+
+// CatalogRepositoryInterface 商品目录仓库接口
+// 所有按ID的读写均带 merchantID 条件，避免跨商家访问
+type CatalogRepositoryInterface interface {
+	// 分类
+	CreateCategory(ctx context.Context, category *model.MenuCategory) error
+	GetCategory(ctx context.Context, merchantID, id int64) (*model.MenuCategory, error)
+	UpdateCategory(ctx context.Context, category *model.MenuCategory) error
+	DeleteCategory(ctx context.Context, merchantID, id int64) error
+	ListCategories(ctx context.Context, merchantID int64) ([]*model.MenuCategory, error)
+	CountItemsInCategory(ctx context.Context, merchantID, categoryID int64) (int64, error)
+
+	// 商品（含图片与规格组）
+	CreateItem(ctx context.Context, item *model.MenuItem) error
+	GetItem(ctx context.Context, merchantID, id int64) (*model.MenuItem, error)
+	UpdateItem(ctx context.Context, item *model.MenuItem) error
+	DeleteItem(ctx context.Context, merchantID, id int64) error
+	ListItems(ctx context.Context, merchantID, categoryID int64) ([]*model.MenuItem, error)
+
+	// 上下架与库存
+	SetItemAvailability(ctx context.Context, merchantID, id int64, available bool) error
+	SetItemStock(ctx context.Context, merchantID, id int64, stock *int) error
+
+	// 下单扣减与取消退回库存（不限量的商品不变）
+	// DeductItemStock 仅对上架且库存充足的商品生效，否则返回 ErrMenuItemUnavailable
+	DeductItemStock(ctx context.Context, merchantID, id int64, quantity int) error
+	// RestoreItemStock 退回库存，商品已删除时忽略
+	RestoreItemStock(ctx context.Context, merchantID, id int64, quantity int) error
+
+	// GetMenu 获取商家完整菜单（分类及其商品，按排序字段升序）
+	GetMenu(ctx context.Context, merchantID int64) ([]*model.MenuCategory, error)
+}
+
+// CatalogRepository 商品目录仓库实现
+type CatalogRepository struct {
+	db *gorm.DB
+}
+
+// NewCatalogRepository 创建商品目录仓库实例
+func NewCatalogRepository(db *gorm.DB) CatalogRepositoryInterface {
+	return &CatalogRepository{
+		db: db,
+	}
+}
+
+// conn 解析本次调用使用的连接：ctx 中有事务时加入事务
+func (r *CatalogRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+// #endregion
+
+// #region 分类
+
+// CreateCategory 创建分类
+func (r *CatalogRepository) CreateCategory(ctx context.Context, category *model.MenuCategory) error {
+	if category == nil {
+		return ErrCategoryNil
+	}
+
+	return r.conn(ctx).Omit(clause.Associations).Create(category).Error
+}
+
+// GetCategory 获取商家的分类
+func (r *CatalogRepository) GetCategory(ctx context.Context, merchantID, id int64) (*model.MenuCategory, error) {
+	if id <= 0 {
+		return nil, ErrCategoryIDInvalid
+	}
+
+	var category model.MenuCategory
+	if err := r.conn(ctx).Where("id = ? AND merchant_id = ?", id, merchantID).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateCategory 更新分类名称与排序
+func (r *CatalogRepository) UpdateCategory(ctx context.Context, category *model.MenuCategory) error {
+	if category == nil {
+		return ErrCategoryNil
+	}
+
+	result := r.conn(ctx).Model(&model.MenuCategory{}).
+		Where("id = ? AND merchant_id = ?", category.ID, category.MerchantID).
+		Updates(map[string]interface{}{"name": category.Name, "sort_order": category.SortOrder})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// DeleteCategory 删除分类（软删除）
+func (r *CatalogRepository) DeleteCategory(ctx context.Context, merchantID, id int64) error {
+	if id <= 0 {
+		return ErrCategoryIDInvalid
+	}
+
+	result := r.conn(ctx).Where("merchant_id = ?", merchantID).Delete(&model.MenuCategory{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// ListCategories 获取商家全部分类
+func (r *CatalogRepository) ListCategories(ctx context.Context, merchantID int64) ([]*model.MenuCategory, error) {
+	if merchantID <= 0 {
+		return nil, ErrMerchantIDInvalid
+	}
+
+	var categories []*model.MenuCategory
+	if err := r.conn(ctx).Where("merchant_id = ?", merchantID).
+		Order("sort_order ASC, id ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// CountItemsInCategory 统计分类下的商品数量
+func (r *CatalogRepository) CountItemsInCategory(ctx context.Context, merchantID, categoryID int64) (int64, error) {
+	var count int64
+	err := r.conn(ctx).Model(&model.MenuItem{}).
+		Where("merchant_id = ? AND category_id = ?", merchantID, categoryID).
+		Count(&count).Error
+	return count, err
+}
+
+// #endregion
+
+// #region 商品
+
+// CreateItem 创建商品及其图片、规格组和选项
+func (r *CatalogRepository) CreateItem(ctx context.Context, item *model.MenuItem) error {
+	if item == nil {
+		return ErrMenuItemNil
+	}
+
+	return r.conn(ctx).Create(item).Error
+}
+
+// GetItem 获取商家的商品（含图片与规格组）
+func (r *CatalogRepository) GetItem(ctx context.Context, merchantID, id int64) (*model.MenuItem, error) {
+	if id <= 0 {
+		return nil, ErrMenuItemIDInvalid
+	}
+
+	var item model.MenuItem
+	if err := r.withDetails(r.conn(ctx)).
+		Where("id = ? AND merchant_id = ?", id, merchantID).
+		First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuItemNotFound
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateItem 更新商品；图片与规格组整体替换
+func (r *CatalogRepository) UpdateItem(ctx context.Context, item *model.MenuItem) error {
+	if item == nil {
+		return ErrMenuItemNil
+	}
+	if item.ID <= 0 {
+		return ErrMenuItemIDInvalid
+	}
+
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(item).
+			Where("merchant_id = ?", item.MerchantID).
+			Select("category_id", "name", "description", "price", "stock", "is_available", "sort_order").
+			Updates(item)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrMenuItemNotFound
+		}
+
+		if err := deleteItemDetails(tx, item.ID); err != nil {
+			return err
+		}
+
+		for i := range item.Images {
+			item.Images[i].ID = 0
+			item.Images[i].ItemID = item.ID
+		}
+		if len(item.Images) > 0 {
+			if err := tx.Create(&item.Images).Error; err != nil {
+				return err
+			}
+		}
+		for i := range item.OptionGroups {
+			item.OptionGroups[i].ID = 0
+			item.OptionGroups[i].ItemID = item.ID
+			for j := range item.OptionGroups[i].Options {
+				item.OptionGroups[i].Options[j].ID = 0
+			}
+		}
+		if len(item.OptionGroups) > 0 {
+			if err := tx.Create(&item.OptionGroups).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteItem 删除商品（软删除），同时清理图片与规格组
+func (r *CatalogRepository) DeleteItem(ctx context.Context, merchantID, id int64) error {
+	if id <= 0 {
+		return ErrMenuItemIDInvalid
+	}
+
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("merchant_id = ?", merchantID).Delete(&model.MenuItem{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrMenuItemNotFound
+		}
+		return deleteItemDetails(tx, id)
+	})
+}
+
+// ListItems 获取商家商品列表；categoryID 为 0 时返回全部
+func (r *CatalogRepository) ListItems(ctx context.Context, merchantID, categoryID int64) ([]*model.MenuItem, error) {
+	if merchantID <= 0 {
+		return nil, ErrMerchantIDInvalid
+	}
+
+	query := r.withDetails(r.conn(ctx)).Where("merchant_id = ?", merchantID)
+	if categoryID > 0 {
+		query = query.Where("category_id = ?", categoryID)
+	}
+
+	var items []*model.MenuItem
+	if err := query.Order("sort_order ASC, id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// #endregion
+
+// #region 上下架与库存
+
+// SetItemAvailability 上架或下架商品
+func (r *CatalogRepository) SetItemAvailability(ctx context.Context, merchantID, id int64, available bool) error {
+	return r.updateItemColumn(ctx, merchantID, id, "is_available", available)
+}
+
+// SetItemStock 设置库存；stock 为空表示不限量
+func (r *CatalogRepository) SetItemStock(ctx context.Context, merchantID, id int64, stock *int) error {
+	return r.updateItemColumn(ctx, merchantID, id, "stock", stock)
+}
+
+// DeductItemStock 条件扣减：上架且库存充足（或不限量）时才写入，防止并发下单超卖
+func (r *CatalogRepository) DeductItemStock(ctx context.Context, merchantID, id int64, quantity int) error {
+	if id <= 0 {
+		return ErrMenuItemIDInvalid
+	}
+
+	result := r.conn(ctx).Model(&model.MenuItem{}).
+		Where("id = ? AND merchant_id = ? AND is_available = ? AND (stock IS NULL OR stock >= ?)", id, merchantID, true, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMenuItemUnavailable
+	}
+	return nil
+}
+
+// RestoreItemStock 退回库存（NULL 库存运算后仍为 NULL，不限量商品不受影响）
+func (r *CatalogRepository) RestoreItemStock(ctx context.Context, merchantID, id int64, quantity int) error {
+	if id <= 0 {
+		return ErrMenuItemIDInvalid
+	}
+
+	return r.conn(ctx).Model(&model.MenuItem{}).
+		Where("id = ? AND merchant_id = ?", id, merchantID).
+		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+}
+
+// updateItemColumn 更新商品单个字段
+func (r *CatalogRepository) updateItemColumn(ctx context.Context, merchantID, id int64, column string, value interface{}) error {
+	if id <= 0 {
+		return ErrMenuItemIDInvalid
+	}
+
+	result := r.conn(ctx).Model(&model.MenuItem{}).
+		Where("id = ? AND merchant_id = ?", id, merchantID).
+		Update(column, value)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMenuItemNotFound
+	}
+	return nil
+}
+
+// #endregion
+
+// #region 菜单
+
+// GetMenu 获取商家完整菜单
+func (r *CatalogRepository) GetMenu(ctx context.Context, merchantID int64) ([]*model.MenuCategory, error) {
+	if merchantID <= 0 {
+		return nil, ErrMerchantIDInvalid
+	}
+
+	var categories []*model.MenuCategory
+	if err := r.conn(ctx).Where("merchant_id = ?", merchantID).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return r.withDetails(db).Order("sort_order ASC, id ASC")
+		}).
+		Order("sort_order ASC, id ASC").
+		Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// #endregion
+
+// #region 工具方法
+
+// withDetails 预加载商品图片、规格组与选项（均按排序字段升序）
+func (r *CatalogRepository) withDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Preload("OptionGroups", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Preload("OptionGroups.Options", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") })
+}
+
+// deleteItemDetails 删除商品的图片、规格组与选项
+func deleteItemDetails(tx *gorm.DB, itemID int64) error {
+	groupIDs := tx.Model(&model.MenuOptionGroup{}).Select("id").Where("item_id = ?", itemID)
+	if err := tx.Where("group_id IN (?)", groupIDs).Delete(&model.MenuOption{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("item_id = ?", itemID).Delete(&model.MenuOptionGroup{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("item_id = ?", itemID).Delete(&model.MenuItemImage{}).Error
+}
+
+// #endregion
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/Hermitf/the-pass/internal/database"
 	"github.com/Hermitf/the-pass/internal/model"
 	"gorm.io/gorm"
 )
@@ -12,30 +14,33 @@ import (
 // EmployeeRepositoryInterface 员工仓库接口
 type EmployeeRepositoryInterface interface {
 	// 基础CRUD操作
-	Create(employee *model.Employee) error
-	GetByID(id int64) (*model.Employee, error)
-	Update(employee *model.Employee) error
-	Delete(id int64) error
+	Create(ctx context.Context, employee *model.Employee) error
+	GetByID(ctx context.Context, id int64) (*model.Employee, error)
+	Update(ctx context.Context, employee *model.Employee) error
+	UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error
+	SetActive(ctx context.Context, id int64, active bool) error
+	UpdateRole(ctx context.Context, id int64, role string) error
+	Delete(ctx context.Context, id int64) error
 
 	// 查询方法
-	GetByUsername(username string) (*model.Employee, error)
-	GetByEmail(email string) (*model.Employee, error)
-	GetByPhone(phone string) (*model.Employee, error)
-	GetByIDNumber(idNumber string) (*model.Employee, error)
+	GetByUsername(ctx context.Context, username string) (*model.Employee, error)
+	GetByEmail(ctx context.Context, email string) (*model.Employee, error)
+	GetByPhone(ctx context.Context, phone string) (*model.Employee, error)
+	GetByIDNumber(ctx context.Context, idNumber string) (*model.Employee, error)
 
 	// 商家关联查询
-	GetByMerchantID(merchantID int64) ([]*model.Employee, error)
-	GetActiveEmployeesByMerchant(merchantID int64) ([]*model.Employee, error)
-	GetEmployeesByMerchantWithPagination(merchantID int64, offset, limit int) ([]*model.Employee, int64, error)
+	GetByMerchantID(ctx context.Context, merchantID int64) ([]*model.Employee, error)
+	GetActiveEmployeesByMerchant(ctx context.Context, merchantID int64) ([]*model.Employee, error)
+	GetEmployeesByMerchantWithPagination(ctx context.Context, merchantID int64, offset, limit int) ([]*model.Employee, int64, error)
 
 	// 业务查询方法
-	CheckEmployeeExists(username, email, phone string) (bool, error)
-	SearchEmployees(keyword string, merchantID int64, offset, limit int) ([]*model.Employee, int64, error)
-	GetEmployeeStatsByMerchant(merchantID int64) (map[string]interface{}, error)
+	CheckEmployeeExists(ctx context.Context, username, email, phone string) (bool, error)
+	SearchEmployees(ctx context.Context, keyword string, merchantID int64, offset, limit int) ([]*model.Employee, int64, error)
+	GetEmployeeStatsByMerchant(ctx context.Context, merchantID int64) (map[string]interface{}, error)
 
 	// 员工转移
-	TransferEmployee(employeeID, newMerchantID int64) error
-	BulkTransferEmployees(employeeIDs []int64, newMerchantID int64) error
+	TransferEmployee(ctx context.Context, employeeID, newMerchantID int64) error
+	BulkTransferEmployees(ctx context.Context, employeeIDs []int64, newMerchantID int64) error
 }
 
 // EmployeeRepository 员工仓库实现
@@ -54,48 +59,94 @@ func NewEmployeeRepository(db *gorm.DB) EmployeeRepositoryInterface {
 	}
 }
 
+// conn 解析本次调用使用的连接：ctx 中有事务时加入事务
+func (r *EmployeeRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
 // #endregion
 
 // #region 基础CRUD操作
 
 // Create 创建员工
-func (r *EmployeeRepository) Create(employee *model.Employee) error {
+func (r *EmployeeRepository) Create(ctx context.Context, employee *model.Employee) error {
 	if employee == nil {
 		return ErrEmployeeNil
 	}
 
-	return r.db.Create(employee).Error
+	return r.conn(ctx).Create(employee).Error
 }
 
 // GetByID 根据ID获取员工
-func (r *EmployeeRepository) GetByID(id int64) (*model.Employee, error) {
+func (r *EmployeeRepository) GetByID(ctx context.Context, id int64) (*model.Employee, error) {
 	if id <= 0 {
 		return nil, ErrEmployeeIDInvalid
 	}
 
 	var employee model.Employee
-	if err := r.db.Where("id = ?", id).First(&employee).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&employee).Error; err != nil {
 		return nil, err
 	}
 	return &employee, nil
 }
 
 // Update 更新员工信息
-func (r *EmployeeRepository) Update(employee *model.Employee) error {
+func (r *EmployeeRepository) Update(ctx context.Context, employee *model.Employee) error {
 	if employee == nil {
 		return ErrEmployeeNil
 	}
 
-	return r.db.Save(employee).Error
+	return r.conn(ctx).Save(employee).Error
+}
+
+// UpdatePasswordHash 只更新密码哈希，避免整行保存覆盖并发修改的其他字段
+func (r *EmployeeRepository) UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error {
+	if id <= 0 {
+		return ErrEmployeeIDInvalid
+	}
+
+	return r.conn(ctx).Model(&model.Employee{}).Where("id = ?", id).Update("password_hash", passwordHash).Error
+}
+
+// SetActive 只更新激活状态，记录不存在时返回 ErrEmployeeNotFound
+func (r *EmployeeRepository) SetActive(ctx context.Context, id int64, active bool) error {
+	if id <= 0 {
+		return ErrEmployeeIDInvalid
+	}
+
+	result := r.conn(ctx).Model(&model.Employee{}).Where("id = ?", id).Update("is_active", active)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEmployeeNotFound
+	}
+	return nil
+}
+
+// UpdateRole 只更新员工角色
+func (r *EmployeeRepository) UpdateRole(ctx context.Context, id int64, role string) error {
+	if id <= 0 {
+		return ErrEmployeeIDInvalid
+	}
+
+	result := r.conn(ctx).Model(&model.Employee{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEmployeeNotFound
+	}
+	return nil
 }
 
 // Delete 删除员工（软删除）
-func (r *EmployeeRepository) Delete(id int64) error {
+func (r *EmployeeRepository) Delete(ctx context.Context, id int64) error {
 	if id <= 0 {
 		return ErrEmployeeIDInvalid
 	}
 
-	return r.db.Delete(&model.Employee{}, id).Error
+	return r.conn(ctx).Delete(&model.Employee{}, id).Error
 }
 
 // #endregion
@@ -103,52 +154,52 @@ func (r *EmployeeRepository) Delete(id int64) error {
 // #region 查询方法
 
 // GetByUsername 根据用户名获取员工
-func (r *EmployeeRepository) GetByUsername(username string) (*model.Employee, error) {
+func (r *EmployeeRepository) GetByUsername(ctx context.Context, username string) (*model.Employee, error) {
 	if username == "" {
 		return nil, ErrUsernameEmpty
 	}
 
 	var employee model.Employee
-	if err := r.db.Where("username = ?", username).First(&employee).Error; err != nil {
+	if err := r.conn(ctx).Where("username = ?", username).First(&employee).Error; err != nil {
 		return nil, err
 	}
 	return &employee, nil
 }
 
 // GetByEmail 根据邮箱获取员工
-func (r *EmployeeRepository) GetByEmail(email string) (*model.Employee, error) {
+func (r *EmployeeRepository) GetByEmail(ctx context.Context, email string) (*model.Employee, error) {
 	if email == "" {
 		return nil, ErrEmailEmpty
 	}
 
 	var employee model.Employee
-	if err := r.db.Where("email = ?", email).First(&employee).Error; err != nil {
+	if err := r.conn(ctx).Where("email = ?", email).First(&employee).Error; err != nil {
 		return nil, err
 	}
 	return &employee, nil
 }
 
 // GetByPhone 根据手机号获取员工
-func (r *EmployeeRepository) GetByPhone(phone string) (*model.Employee, error) {
+func (r *EmployeeRepository) GetByPhone(ctx context.Context, phone string) (*model.Employee, error) {
 	if phone == "" {
 		return nil, ErrPhoneEmpty
 	}
 
 	var employee model.Employee
-	if err := r.db.Where("phone = ?", phone).First(&employee).Error; err != nil {
+	if err := r.conn(ctx).Where("phone = ?", phone).First(&employee).Error; err != nil {
 		return nil, err
 	}
 	return &employee, nil
 }
 
 // GetByIDNumber 根据身份证号获取员工
-func (r *EmployeeRepository) GetByIDNumber(idNumber string) (*model.Employee, error) {
+func (r *EmployeeRepository) GetByIDNumber(ctx context.Context, idNumber string) (*model.Employee, error) {
 	if idNumber == "" {
 		return nil, ErrIDNumberEmpty
 	}
 
 	var employee model.Employee
-	if err := r.db.Where("id_number = ?", idNumber).First(&employee).Error; err != nil {
+	if err := r.conn(ctx).Where("id_number = ?", idNumber).First(&employee).Error; err != nil {
 		return nil, err
 	}
 	return &employee, nil
@@ -159,33 +210,33 @@ func (r *EmployeeRepository) GetByIDNumber(idNumber string) (*model.Employee, er
 // #region 商家关联查询
 
 // GetByMerchantID 根据商家ID获取员工列表
-func (r *EmployeeRepository) GetByMerchantID(merchantID int64) ([]*model.Employee, error) {
+func (r *EmployeeRepository) GetByMerchantID(ctx context.Context, merchantID int64) ([]*model.Employee, error) {
 	if merchantID <= 0 {
 		return nil, ErrMerchantIDInvalid
 	}
 
 	var employees []*model.Employee
-	if err := r.db.Where("merchant_id = ?", merchantID).Find(&employees).Error; err != nil {
+	if err := r.conn(ctx).Where("merchant_id = ?", merchantID).Find(&employees).Error; err != nil {
 		return nil, err
 	}
 	return employees, nil
 }
 
 // GetActiveEmployeesByMerchant 获取商家的活跃员工列表
-func (r *EmployeeRepository) GetActiveEmployeesByMerchant(merchantID int64) ([]*model.Employee, error) {
+func (r *EmployeeRepository) GetActiveEmployeesByMerchant(ctx context.Context, merchantID int64) ([]*model.Employee, error) {
 	if merchantID <= 0 {
 		return nil, ErrMerchantIDInvalid
 	}
 
 	var employees []*model.Employee
-	if err := r.db.Where("merchant_id = ? AND is_active = ?", merchantID, true).Find(&employees).Error; err != nil {
+	if err := r.conn(ctx).Where("merchant_id = ? AND is_active = ?", merchantID, true).Find(&employees).Error; err != nil {
 		return nil, err
 	}
 	return employees, nil
 }
 
 // GetEmployeesByMerchantWithPagination 分页获取商家员工列表
-func (r *EmployeeRepository) GetEmployeesByMerchantWithPagination(merchantID int64, offset, limit int) ([]*model.Employee, int64, error) {
+func (r *EmployeeRepository) GetEmployeesByMerchantWithPagination(ctx context.Context, merchantID int64, offset, limit int) ([]*model.Employee, int64, error) {
 	if merchantID <= 0 {
 		return nil, 0, ErrMerchantIDInvalid
 	}
@@ -197,12 +248,12 @@ func (r *EmployeeRepository) GetEmployeesByMerchantWithPagination(merchantID int
 	var total int64
 
 	// 获取总数
-	if err := r.db.Model(&model.Employee{}).Where("merchant_id = ?", merchantID).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Employee{}).Where("merchant_id = ?", merchantID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 分页查询
-	if err := r.db.Where("merchant_id = ?", merchantID).Offset(offset).Limit(limit).Find(&employees).Error; err != nil {
+	if err := r.conn(ctx).Where("merchant_id = ?", merchantID).Offset(offset).Limit(limit).Find(&employees).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -214,10 +265,10 @@ func (r *EmployeeRepository) GetEmployeesByMerchantWithPagination(merchantID int
 // #region 业务查询方法
 
 // CheckEmployeeExists 检查员工是否已存在
-func (r *EmployeeRepository) CheckEmployeeExists(username, email, phone string) (bool, error) {
+func (r *EmployeeRepository) CheckEmployeeExists(ctx context.Context, username, email, phone string) (bool, error) {
 	var count int64
 
-	query := r.db.Model(&model.Employee{})
+	query := r.conn(ctx).Model(&model.Employee{})
 	conditions := []string{}
 	args := []interface{}{}
 
@@ -251,7 +302,7 @@ func (r *EmployeeRepository) CheckEmployeeExists(username, email, phone string)
 }
 
 // SearchEmployees 搜索员工
-func (r *EmployeeRepository) SearchEmployees(keyword string, merchantID int64, offset, limit int) ([]*model.Employee, int64, error) {
+func (r *EmployeeRepository) SearchEmployees(ctx context.Context, keyword string, merchantID int64, offset, limit int) ([]*model.Employee, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationParametersInvalid
 	}
@@ -259,7 +310,7 @@ func (r *EmployeeRepository) SearchEmployees(keyword string, merchantID int64, o
 	var employees []*model.Employee
 	var total int64
 
-	query := r.db.Model(&model.Employee{})
+	query := r.conn(ctx).Model(&model.Employee{})
 
 	// 添加商家ID过滤条件
 	if merchantID > 0 {
@@ -289,7 +340,7 @@ func (r *EmployeeRepository) SearchEmployees(keyword string, merchantID int64, o
 }
 
 // GetEmployeeStatsByMerchant 获取商家员工统计信息
-func (r *EmployeeRepository) GetEmployeeStatsByMerchant(merchantID int64) (map[string]interface{}, error) {
+func (r *EmployeeRepository) GetEmployeeStatsByMerchant(ctx context.Context, merchantID int64) (map[string]interface{}, error) {
 	if merchantID <= 0 {
 		return nil, ErrMerchantIDInvalid
 	}
@@ -298,21 +349,21 @@ func (r *EmployeeRepository) GetEmployeeStatsByMerchant(merchantID int64) (map[s
 
 	// 总员工数
 	var totalEmployees int64
-	if err := r.db.Model(&model.Employee{}).Where("merchant_id = ?", merchantID).Count(&totalEmployees).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Employee{}).Where("merchant_id = ?", merchantID).Count(&totalEmployees).Error; err != nil {
 		return nil, err
 	}
 	stats["total_employees"] = totalEmployees
 
 	// 活跃员工数
 	var activeEmployees int64
-	if err := r.db.Model(&model.Employee{}).Where("merchant_id = ? AND is_active = ?", merchantID, true).Count(&activeEmployees).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Employee{}).Where("merchant_id = ? AND is_active = ?", merchantID, true).Count(&activeEmployees).Error; err != nil {
 		return nil, err
 	}
 	stats["active_employees"] = activeEmployees
 
 	// 今日新增员工数
 	var todayAdded int64
-	if err := r.db.Model(&model.Employee{}).Where("merchant_id = ? AND DATE(created_at) = CURDATE()", merchantID).Count(&todayAdded).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Employee{}).Where("merchant_id = ? AND DATE(created_at) = CURDATE()", merchantID).Count(&todayAdded).Error; err != nil {
 		return nil, err
 	}
 	stats["today_added"] = todayAdded
@@ -325,7 +376,7 @@ func (r *EmployeeRepository) GetEmployeeStatsByMerchant(merchantID int64) (map[s
 // #region 员工转移
 
 // TransferEmployee 转移单个员工到新商家
-func (r *EmployeeRepository) TransferEmployee(employeeID, newMerchantID int64) error {
+func (r *EmployeeRepository) TransferEmployee(ctx context.Context, employeeID, newMerchantID int64) error {
 	if employeeID <= 0 {
 		return ErrEmployeeIDInvalid
 	}
@@ -333,11 +384,11 @@ func (r *EmployeeRepository) TransferEmployee(employeeID, newMerchantID int64) e
 		return ErrMerchantIDInvalid
 	}
 
-	return r.db.Model(&model.Employee{}).Where("id = ?", employeeID).Update("merchant_id", newMerchantID).Error
+	return r.conn(ctx).Model(&model.Employee{}).Where("id = ?", employeeID).Update("merchant_id", newMerchantID).Error
 }
 
 // BulkTransferEmployees 批量转移员工到新商家
-func (r *EmployeeRepository) BulkTransferEmployees(employeeIDs []int64, newMerchantID int64) error {
+func (r *EmployeeRepository) BulkTransferEmployees(ctx context.Context, employeeIDs []int64, newMerchantID int64) error {
 	if len(employeeIDs) == 0 {
 		return ErrEmployeeIDsEmpty
 	}
@@ -345,7 +396,7 @@ func (r *EmployeeRepository) BulkTransferEmployees(employeeIDs []int64, newMerch
 		return ErrMerchantIDInvalid
 	}
 
-	return r.db.Model(&model.Employee{}).Where("id IN ?", employeeIDs).Update("merchant_id", newMerchantID).Error
+	return r.conn(ctx).Model(&model.Employee{}).Where("id IN ?", employeeIDs).Update("merchant_id", newMerchantID).Error
 }
 
 // #endregion
@@ -353,13 +404,13 @@ func (r *EmployeeRepository) BulkTransferEmployees(employeeIDs []int64, newMerch
 // #region 工具方法
 
 // CountEmployeesByMerchant 统计商家员工数量
-func (r *EmployeeRepository) CountEmployeesByMerchant(merchantID int64) (int64, error) {
+func (r *EmployeeRepository) CountEmployeesByMerchant(ctx context.Context, merchantID int64) (int64, error) {
 	if merchantID <= 0 {
 		return 0, ErrMerchantIDInvalid
 	}
 
 	var count int64
-	if err := r.db.Model(&model.Employee{}).Where("merchant_id = ?", merchantID).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Employee{}).Where("merchant_id = ?", merchantID).Count(&count).Error; err != nil {
 		return 0, err
 	}
 
@@ -367,7 +418,7 @@ func (r *EmployeeRepository) CountEmployeesByMerchant(merchantID int64) (int64,
 }
 
 // GetEmployeesByAge 根据年龄范围获取员工
-func (r *EmployeeRepository) GetEmployeesByAge(merchantID int64, minAge, maxAge int) ([]*model.Employee, error) {
+func (r *EmployeeRepository) GetEmployeesByAge(ctx context.Context, merchantID int64, minAge, maxAge int) ([]*model.Employee, error) {
 	if merchantID <= 0 {
 		return nil, ErrMerchantIDInvalid
 	}
@@ -385,7 +436,7 @@ func (r *EmployeeRepository) GetEmployeesByAge(merchantID int64, minAge, maxAge
 		currentYear, birthYearFromID,
 	)
 
-	if err := r.db.Where(query, merchantID, minAge, maxAge).Find(&employees).Error; err != nil {
+	if err := r.conn(ctx).Where(query, merchantID, minAge, maxAge).Find(&employees).Error; err != nil {
 		return nil, err
 	}
 
@@ -393,7 +444,7 @@ func (r *EmployeeRepository) GetEmployeesByAge(merchantID int64, minAge, maxAge
 }
 
 // GetRecentlyJoinedEmployees 获取最近加入的员工
-func (r *EmployeeRepository) GetRecentlyJoinedEmployees(merchantID int64, days int) ([]*model.Employee, error) {
+func (r *EmployeeRepository) GetRecentlyJoinedEmployees(ctx context.Context, merchantID int64, days int) ([]*model.Employee, error) {
 	if merchantID <= 0 {
 		return nil, ErrMerchantIDInvalid
 	}
@@ -402,7 +453,7 @@ func (r *EmployeeRepository) GetRecentlyJoinedEmployees(merchantID int64, days i
 	}
 
 	var employees []*model.Employee
-	if err := r.db.Where(
+	if err := r.conn(ctx).Where(
 		"merchant_id = ? AND created_at >= DATE_SUB(CURDATE(), INTERVAL ? DAY)",
 		merchantID, days,
 	).Order("created_at DESC").Find(&employees).Error; err != nil {
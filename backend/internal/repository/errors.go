@@ -1,6 +1,10 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
 
 var (
 	// 通用数据库错误
@@ -46,6 +50,17 @@ var (
 	ErrAtLeastOneFieldRequired = errors.New("至少需要提供一个字段")
 	ErrOrderCountRangeInvalid  = errors.New("订单数量范围无效")
 
+	// 订单相关参数验证错误
+	ErrOrderNil       = errors.New("订单对象不能为空")
+	ErrOrderIDInvalid = errors.New("订单ID必须为正数")
+	ErrOrderNoEmpty   = errors.New("订单号不能为空")
+
+	// 商品目录相关参数验证错误
+	ErrCategoryNil       = errors.New("分类对象不能为空")
+	ErrCategoryIDInvalid = errors.New("分类ID必须为正数")
+	ErrMenuItemNil       = errors.New("商品对象不能为空")
+	ErrMenuItemIDInvalid = errors.New("商品ID必须为正数")
+
 	// 用户相关数据访问错误
 	ErrUserNotFound       = errors.New("用户不存在")
 	ErrUserAlreadyExists  = errors.New("用户已存在")
@@ -54,6 +69,7 @@ var (
 	ErrUserEmailExists    = errors.New("用户邮箱已存在")
 	ErrUserPhoneExists    = errors.New("用户手机号已存在")
 	ErrUserUsernameExists = errors.New("用户名已存在")
+	ErrUserStatusInvalid  = errors.New("用户状态无效")
 
 	// 员工相关数据访问错误
 	ErrEmployeeNotFound      = errors.New("员工不存在")
@@ -72,6 +88,30 @@ var (
 	ErrRiderAlreadyExists = errors.New("配送员已存在")
 	ErrRiderUpdateFailed  = errors.New("配送员更新失败")
 	ErrRiderDeleteFailed  = errors.New("配送员删除失败")
+
+	// 订单相关数据访问错误
+	ErrOrderNotFound       = errors.New("订单不存在")
+	ErrOrderStatusConflict = errors.New("订单状态已变更，请刷新后重试")
+
+	// 管理员相关数据访问错误
+	ErrAdminNil           = errors.New("管理员对象不能为空")
+	ErrAdminIDInvalid     = errors.New("管理员ID必须为正数")
+	ErrAdminNotFound      = errors.New("管理员不存在")
+	ErrAdminAlreadyExists = errors.New("管理员已存在")
+	ErrAuditLogNil        = errors.New("审计日志不能为空")
+
+	// 短信发送日志相关参数验证错误
+	ErrSendLogNil = errors.New("短信发送日志不能为空")
+
+	// 资质审核材料相关数据访问错误
+	ErrVerificationDocumentNil      = errors.New("审核材料不能为空")
+	ErrVerificationDocumentNotFound = errors.New("审核材料不存在")
+
+	// 商品目录相关数据访问错误
+	ErrCategoryNotFound = errors.New("分类不存在")
+	ErrMenuItemNotFound = errors.New("商品不存在")
+
+	ErrMenuItemUnavailable = errors.New("商品已下架或库存不足")
 )
 
 // #endregion
@@ -79,12 +119,19 @@ var (
 // #region 错误检查辅助函数
 
 // IsNotFoundError 检查是否为"未找到"错误
+// 按ID查询的方法直接返回 GORM 错误，gorm.ErrRecordNotFound 同样视为未找到
 func IsNotFoundError(err error) bool {
-	return err == ErrRecordNotFound ||
+	return errors.Is(err, gorm.ErrRecordNotFound) ||
+		err == ErrRecordNotFound ||
 		err == ErrUserNotFound ||
 		err == ErrEmployeeNotFound ||
 		err == ErrMerchantNotFound ||
-		err == ErrRiderNotFound
+		err == ErrRiderNotFound ||
+		err == ErrOrderNotFound ||
+		err == ErrAdminNotFound ||
+		err == ErrVerificationDocumentNotFound ||
+		err == ErrCategoryNotFound ||
+		err == ErrMenuItemNotFound
 }
 
 // IsAlreadyExistsError 检查是否为"已存在"错误
@@ -94,6 +141,7 @@ func IsAlreadyExistsError(err error) bool {
 		err == ErrEmployeeAlreadyExists ||
 		err == ErrMerchantAlreadyExists ||
 		err == ErrRiderAlreadyExists ||
+		err == ErrAdminAlreadyExists ||
 		err == ErrUserEmailExists ||
 		err == ErrUserPhoneExists ||
 		err == ErrUserUsernameExists
@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"context"
+
+	"github.com/Hermitf/the-pass/internal/database"
 	"github.com/Hermitf/the-pass/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // #region 仓库定义
@@ -10,31 +14,42 @@ import (
 // MerchantRepositoryInterface 商家仓库接口
 type MerchantRepositoryInterface interface {
 	// 基础CRUD操作
-	Create(merchant *model.Merchant) error
-	GetByID(id int64) (*model.Merchant, error)
-	Update(merchant *model.Merchant) error
-	Delete(id int64) error
+	Create(ctx context.Context, merchant *model.Merchant) error
+	GetByID(ctx context.Context, id int64) (*model.Merchant, error)
+	// GetByIDForUpdate 在事务中读取并锁定商家行（SELECT ... FOR UPDATE），须在 TxManager.WithinTx 内调用
+	GetByIDForUpdate(ctx context.Context, id int64) (*model.Merchant, error)
+	Update(ctx context.Context, merchant *model.Merchant) error
+	UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error
+	SetActive(ctx context.Context, id int64, active bool) error
+	// UpdateVerification 只更新资质审核相关字段
+	UpdateVerification(ctx context.Context, merchant *model.Merchant) error
+	// UpdateBusinessInfo 只更新待审核的店铺名称与营业执照号
+	UpdateBusinessInfo(ctx context.Context, merchant *model.Merchant) error
+	// UpdateStoreLocation 只更新门店地址与坐标
+	UpdateStoreLocation(ctx context.Context, id int64, address string, lat, lng float64) error
+	Delete(ctx context.Context, id int64) error
 
 	// 查询方法
-	GetByUsername(username string) (*model.Merchant, error)
-	GetByEmail(email string) (*model.Merchant, error)
-	GetByPhone(phone string) (*model.Merchant, error)
-	GetByBusinessLicense(license string) (*model.Merchant, error)
+	GetByUsername(ctx context.Context, username string) (*model.Merchant, error)
+	GetByEmail(ctx context.Context, email string) (*model.Merchant, error)
+	GetByPhone(ctx context.Context, phone string) (*model.Merchant, error)
+	GetByBusinessLicense(ctx context.Context, license string) (*model.Merchant, error)
 
 	// 列表查询
-	GetMerchantList(offset, limit int) ([]*model.Merchant, int64, error)
-	GetActiveMerchants(offset, limit int) ([]*model.Merchant, int64, error)
-	SearchMerchants(keyword string, offset, limit int) ([]*model.Merchant, int64, error)
+	GetMerchantList(ctx context.Context, offset, limit int) ([]*model.Merchant, int64, error)
+	GetActiveMerchants(ctx context.Context, offset, limit int) ([]*model.Merchant, int64, error)
+	SearchMerchants(ctx context.Context, keyword string, offset, limit int) ([]*model.Merchant, int64, error)
+	ListByVerificationStatus(ctx context.Context, status string, offset, limit int) ([]*model.Merchant, int64, error)
 
 	// 员工关联查询
-	GetMerchantWithEmployees(id int64) (*model.Merchant, []*model.Employee, error)
-	GetMerchantsByEmployeeCount(minCount, maxCount int) ([]*model.Merchant, error)
+	GetMerchantWithEmployees(ctx context.Context, id int64) (*model.Merchant, []*model.Employee, error)
+	GetMerchantsByEmployeeCount(ctx context.Context, minCount, maxCount int) ([]*model.Merchant, error)
 
 	// 业务查询方法
-	CheckMerchantExists(username, email, phone, businessLicense string) (bool, error)
-	GetMerchantStats() (map[string]interface{}, error)
-	GetMerchantsByRegion(region string, offset, limit int) ([]*model.Merchant, int64, error)
-	GetTopMerchantsByEmployeeCount(limit int) ([]*model.Merchant, error)
+	CheckMerchantExists(ctx context.Context, username, email, phone, businessLicense string) (bool, error)
+	GetMerchantStats(ctx context.Context) (map[string]interface{}, error)
+	GetMerchantsByRegion(ctx context.Context, region string, offset, limit int) ([]*model.Merchant, int64, error)
+	GetTopMerchantsByEmployeeCount(ctx context.Context, limit int) ([]*model.Merchant, error)
 }
 
 // MerchantRepository 商家仓库实现
@@ -53,48 +68,126 @@ func NewMerchantRepository(db *gorm.DB) MerchantRepositoryInterface {
 	}
 }
 
+// conn 解析本次调用使用的连接：ctx 中有事务时加入事务
+func (r *MerchantRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
 // #endregion
 
 // #region 基础CRUD操作
 
 // Create 创建商家
-func (r *MerchantRepository) Create(merchant *model.Merchant) error {
+func (r *MerchantRepository) Create(ctx context.Context, merchant *model.Merchant) error {
 	if merchant == nil {
 		return ErrMerchantNil
 	}
 
-	return r.db.Create(merchant).Error
+	return r.conn(ctx).Create(merchant).Error
 }
 
 // GetByID 根据ID获取商家
-func (r *MerchantRepository) GetByID(id int64) (*model.Merchant, error) {
+func (r *MerchantRepository) GetByID(ctx context.Context, id int64) (*model.Merchant, error) {
+	if id <= 0 {
+		return nil, ErrMerchantIDInvalid
+	}
+
+	var merchant model.Merchant
+	if err := r.conn(ctx).Where("id = ?", id).First(&merchant).Error; err != nil {
+		return nil, err
+	}
+	return &merchant, nil
+}
+
+// GetByIDForUpdate 根据ID获取商家并加行锁，直到所在事务结束
+func (r *MerchantRepository) GetByIDForUpdate(ctx context.Context, id int64) (*model.Merchant, error) {
 	if id <= 0 {
 		return nil, ErrMerchantIDInvalid
 	}
 
 	var merchant model.Merchant
-	if err := r.db.Where("id = ?", id).First(&merchant).Error; err != nil {
+	if err := r.conn(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&merchant).Error; err != nil {
 		return nil, err
 	}
 	return &merchant, nil
 }
 
 // Update 更新商家信息
-func (r *MerchantRepository) Update(merchant *model.Merchant) error {
+func (r *MerchantRepository) Update(ctx context.Context, merchant *model.Merchant) error {
 	if merchant == nil {
 		return ErrMerchantNil
 	}
 
-	return r.db.Save(merchant).Error
+	return r.conn(ctx).Save(merchant).Error
+}
+
+// UpdatePasswordHash 只更新密码哈希，避免整行保存覆盖并发修改的其他字段
+func (r *MerchantRepository) UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error {
+	if id <= 0 {
+		return ErrMerchantIDInvalid
+	}
+
+	return r.conn(ctx).Model(&model.Merchant{}).Where("id = ?", id).Update("password_hash", passwordHash).Error
+}
+
+// SetActive 只更新激活状态，记录不存在时返回 ErrMerchantNotFound
+func (r *MerchantRepository) SetActive(ctx context.Context, id int64, active bool) error {
+	if id <= 0 {
+		return ErrMerchantIDInvalid
+	}
+
+	result := r.conn(ctx).Model(&model.Merchant{}).Where("id = ?", id).Update("is_active", active)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMerchantNotFound
+	}
+	return nil
+}
+
+// UpdateVerification 只更新资质审核相关字段，避免整行保存覆盖并发修改的其他字段
+func (r *MerchantRepository) UpdateVerification(ctx context.Context, merchant *model.Merchant) error {
+	if merchant == nil {
+		return ErrMerchantNil
+	}
+
+	return r.conn(ctx).Model(merchant).
+		Select("verification_status", "verification_reject_reason", "verification_submitted_at", "verified_at").
+		Updates(merchant).Error
+}
+
+// UpdateBusinessInfo 只更新店铺名称与营业执照号，避免整行保存覆盖并发修改的启用与审核状态
+func (r *MerchantRepository) UpdateBusinessInfo(ctx context.Context, merchant *model.Merchant) error {
+	if merchant == nil {
+		return ErrMerchantNil
+	}
+
+	return r.conn(ctx).Model(merchant).
+		Select("company_name", "business_license", "updated_at").
+		Updates(merchant).Error
+}
+
+// UpdateStoreLocation 只更新门店地址与坐标，避免整行保存覆盖并发修改的启用、审核状态与密码
+func (r *MerchantRepository) UpdateStoreLocation(ctx context.Context, id int64, address string, lat, lng float64) error {
+	if id <= 0 {
+		return ErrMerchantIDInvalid
+	}
+
+	return r.conn(ctx).Model(&model.Merchant{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"store_address": address,
+		"store_lat":     lat,
+		"store_lng":     lng,
+	}).Error
 }
 
 // Delete 删除商家（软删除）
-func (r *MerchantRepository) Delete(id int64) error {
+func (r *MerchantRepository) Delete(ctx context.Context, id int64) error {
 	if id <= 0 {
 		return ErrMerchantIDInvalid
 	}
 
-	return r.db.Delete(&model.Merchant{}, id).Error
+	return r.conn(ctx).Delete(&model.Merchant{}, id).Error
 }
 
 // #endregion
@@ -102,52 +195,52 @@ func (r *MerchantRepository) Delete(id int64) error {
 // #region 查询方法
 
 // GetByUsername 根据用户名获取商家
-func (r *MerchantRepository) GetByUsername(username string) (*model.Merchant, error) {
+func (r *MerchantRepository) GetByUsername(ctx context.Context, username string) (*model.Merchant, error) {
 	if username == "" {
 		return nil, ErrUsernameEmpty
 	}
 
 	var merchant model.Merchant
-	if err := r.db.Where("username = ?", username).First(&merchant).Error; err != nil {
+	if err := r.conn(ctx).Where("username = ?", username).First(&merchant).Error; err != nil {
 		return nil, err
 	}
 	return &merchant, nil
 }
 
 // GetByEmail 根据邮箱获取商家
-func (r *MerchantRepository) GetByEmail(email string) (*model.Merchant, error) {
+func (r *MerchantRepository) GetByEmail(ctx context.Context, email string) (*model.Merchant, error) {
 	if email == "" {
 		return nil, ErrEmailEmpty
 	}
 
 	var merchant model.Merchant
-	if err := r.db.Where("email = ?", email).First(&merchant).Error; err != nil {
+	if err := r.conn(ctx).Where("email = ?", email).First(&merchant).Error; err != nil {
 		return nil, err
 	}
 	return &merchant, nil
 }
 
 // GetByPhone 根据手机号获取商家
-func (r *MerchantRepository) GetByPhone(phone string) (*model.Merchant, error) {
+func (r *MerchantRepository) GetByPhone(ctx context.Context, phone string) (*model.Merchant, error) {
 	if phone == "" {
 		return nil, ErrPhoneEmpty
 	}
 
 	var merchant model.Merchant
-	if err := r.db.Where("phone = ?", phone).First(&merchant).Error; err != nil {
+	if err := r.conn(ctx).Where("phone = ?", phone).First(&merchant).Error; err != nil {
 		return nil, err
 	}
 	return &merchant, nil
 }
 
 // GetByBusinessLicense 根据营业执照号获取商家
-func (r *MerchantRepository) GetByBusinessLicense(license string) (*model.Merchant, error) {
+func (r *MerchantRepository) GetByBusinessLicense(ctx context.Context, license string) (*model.Merchant, error) {
 	if license == "" {
 		return nil, ErrBusinessLicenseEmpty
 	}
 
 	var merchant model.Merchant
-	if err := r.db.Where("business_license = ?", license).First(&merchant).Error; err != nil {
+	if err := r.conn(ctx).Where("business_license = ?", license).First(&merchant).Error; err != nil {
 		return nil, err
 	}
 	return &merchant, nil
@@ -158,7 +251,7 @@ func (r *MerchantRepository) GetByBusinessLicense(license string) (*model.Mercha
 // #region 列表查询
 
 // GetMerchantList 分页获取商家列表
-func (r *MerchantRepository) GetMerchantList(offset, limit int) ([]*model.Merchant, int64, error) {
+func (r *MerchantRepository) GetMerchantList(ctx context.Context, offset, limit int) ([]*model.Merchant, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationInvalid
 	}
@@ -167,12 +260,12 @@ func (r *MerchantRepository) GetMerchantList(offset, limit int) ([]*model.Mercha
 	var total int64
 
 	// 获取总数
-	if err := r.db.Model(&model.Merchant{}).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Merchant{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 分页查询
-	if err := r.db.Offset(offset).Limit(limit).Find(&merchants).Error; err != nil {
+	if err := r.conn(ctx).Offset(offset).Limit(limit).Find(&merchants).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -180,7 +273,7 @@ func (r *MerchantRepository) GetMerchantList(offset, limit int) ([]*model.Mercha
 }
 
 // GetActiveMerchants 获取活跃商家列表
-func (r *MerchantRepository) GetActiveMerchants(offset, limit int) ([]*model.Merchant, int64, error) {
+func (r *MerchantRepository) GetActiveMerchants(ctx context.Context, offset, limit int) ([]*model.Merchant, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationParametersInvalid
 	}
@@ -189,12 +282,12 @@ func (r *MerchantRepository) GetActiveMerchants(offset, limit int) ([]*model.Mer
 	var total int64
 
 	// 获取活跃商家总数
-	if err := r.db.Model(&model.Merchant{}).Where("is_active = ?", true).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Merchant{}).Where("is_active = ?", true).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 分页查询活跃商家
-	if err := r.db.Where("is_active = ?", true).Offset(offset).Limit(limit).Find(&merchants).Error; err != nil {
+	if err := r.conn(ctx).Where("is_active = ?", true).Offset(offset).Limit(limit).Find(&merchants).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -202,9 +295,9 @@ func (r *MerchantRepository) GetActiveMerchants(offset, limit int) ([]*model.Mer
 }
 
 // SearchMerchants 搜索商家
-func (r *MerchantRepository) SearchMerchants(keyword string, offset, limit int) ([]*model.Merchant, int64, error) {
+func (r *MerchantRepository) SearchMerchants(ctx context.Context, keyword string, offset, limit int) ([]*model.Merchant, int64, error) {
 	if keyword == "" {
-		return r.GetMerchantList(offset, limit)
+		return r.GetMerchantList(ctx, offset, limit)
 	}
 
 	if offset < 0 || limit <= 0 {
@@ -215,7 +308,7 @@ func (r *MerchantRepository) SearchMerchants(keyword string, offset, limit int)
 	var total int64
 
 	searchPattern := "%" + keyword + "%"
-	query := r.db.Model(&model.Merchant{}).Where(
+	query := r.conn(ctx).Model(&model.Merchant{}).Where(
 		"username LIKE ? OR email LIKE ? OR phone LIKE ? OR company_name LIKE ? OR business_license LIKE ? OR address LIKE ?",
 		searchPattern, searchPattern, searchPattern, searchPattern, searchPattern, searchPattern,
 	)
@@ -233,23 +326,43 @@ func (r *MerchantRepository) SearchMerchants(keyword string, offset, limit int)
 	return merchants, total, nil
 }
 
+// ListByVerificationStatus 按资质审核状态分页获取商家，先提交的排在前面
+func (r *MerchantRepository) ListByVerificationStatus(ctx context.Context, status string, offset, limit int) ([]*model.Merchant, int64, error) {
+	if offset < 0 || limit <= 0 {
+		return nil, 0, ErrPaginationInvalid
+	}
+
+	var merchants []*model.Merchant
+	var total int64
+
+	query := r.conn(ctx).Model(&model.Merchant{}).Where("verification_status = ?", status)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("verification_submitted_at ASC, id ASC").Offset(offset).Limit(limit).Find(&merchants).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return merchants, total, nil
+}
+
 // #endregion
 
 // #region 员工关联查询
 
 // GetMerchantWithEmployees 获取商家及其员工信息
-func (r *MerchantRepository) GetMerchantWithEmployees(id int64) (*model.Merchant, []*model.Employee, error) {
+func (r *MerchantRepository) GetMerchantWithEmployees(ctx context.Context, id int64) (*model.Merchant, []*model.Employee, error) {
 	if id <= 0 {
 		return nil, nil, ErrMerchantIDInvalid
 	}
 
 	var merchant model.Merchant
-	if err := r.db.Where("id = ?", id).First(&merchant).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&merchant).Error; err != nil {
 		return nil, nil, err
 	}
 
 	var employees []*model.Employee
-	if err := r.db.Where("merchant_id = ?", id).Find(&employees).Error; err != nil {
+	if err := r.conn(ctx).Where("merchant_id = ?", id).Find(&employees).Error; err != nil {
 		return &merchant, nil, err
 	}
 
@@ -257,19 +370,19 @@ func (r *MerchantRepository) GetMerchantWithEmployees(id int64) (*model.Merchant
 }
 
 // GetMerchantsByEmployeeCount 根据员工数量范围获取商家
-func (r *MerchantRepository) GetMerchantsByEmployeeCount(minCount, maxCount int) ([]*model.Merchant, error) {
+func (r *MerchantRepository) GetMerchantsByEmployeeCount(ctx context.Context, minCount, maxCount int) ([]*model.Merchant, error) {
 	if minCount < 0 || maxCount < minCount {
 		return nil, ErrEmployeeCountInvalid
 	}
 
 	var merchants []*model.Merchant
 
-	subQuery := r.db.Model(&model.Employee{}).
+	subQuery := r.conn(ctx).Model(&model.Employee{}).
 		Select("merchant_id, COUNT(*) as employee_count").
 		Group("merchant_id").
 		Having("employee_count BETWEEN ? AND ?", minCount, maxCount)
 
-	if err := r.db.Where("id IN (?)", subQuery).Find(&merchants).Error; err != nil {
+	if err := r.conn(ctx).Where("id IN (?)", subQuery).Find(&merchants).Error; err != nil {
 		return nil, err
 	}
 
@@ -281,10 +394,10 @@ func (r *MerchantRepository) GetMerchantsByEmployeeCount(minCount, maxCount int)
 // #region 业务查询方法
 
 // CheckMerchantExists 检查商家是否已存在
-func (r *MerchantRepository) CheckMerchantExists(username, email, phone, businessLicense string) (bool, error) {
+func (r *MerchantRepository) CheckMerchantExists(ctx context.Context, username, email, phone, businessLicense string) (bool, error) {
 	var count int64
 
-	query := r.db.Model(&model.Merchant{})
+	query := r.conn(ctx).Model(&model.Merchant{})
 	conditions := []string{}
 	args := []interface{}{}
 
@@ -322,33 +435,33 @@ func (r *MerchantRepository) CheckMerchantExists(username, email, phone, busines
 }
 
 // GetMerchantStats 获取商家统计信息
-func (r *MerchantRepository) GetMerchantStats() (map[string]interface{}, error) {
+func (r *MerchantRepository) GetMerchantStats(ctx context.Context) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
 	// 总商家数
 	var totalMerchants int64
-	if err := r.db.Model(&model.Merchant{}).Count(&totalMerchants).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Merchant{}).Count(&totalMerchants).Error; err != nil {
 		return nil, err
 	}
 	stats["total_merchants"] = totalMerchants
 
 	// 活跃商家数
 	var activeMerchants int64
-	if err := r.db.Model(&model.Merchant{}).Where("is_active = ?", true).Count(&activeMerchants).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Merchant{}).Where("is_active = ?", true).Count(&activeMerchants).Error; err != nil {
 		return nil, err
 	}
 	stats["active_merchants"] = activeMerchants
 
 	// 今日注册商家数
 	var todayRegistered int64
-	if err := r.db.Model(&model.Merchant{}).Where("DATE(created_at) = CURDATE()").Count(&todayRegistered).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Merchant{}).Where("DATE(created_at) = CURDATE()").Count(&todayRegistered).Error; err != nil {
 		return nil, err
 	}
 	stats["today_registered"] = todayRegistered
 
 	// 本月注册商家数
 	var monthRegistered int64
-	if err := r.db.Model(&model.Merchant{}).Where("YEAR(created_at) = YEAR(CURDATE()) AND MONTH(created_at) = MONTH(CURDATE())").Count(&monthRegistered).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Merchant{}).Where("YEAR(created_at) = YEAR(CURDATE()) AND MONTH(created_at) = MONTH(CURDATE())").Count(&monthRegistered).Error; err != nil {
 		return nil, err
 	}
 	stats["month_registered"] = monthRegistered
@@ -357,7 +470,7 @@ func (r *MerchantRepository) GetMerchantStats() (map[string]interface{}, error)
 }
 
 // GetMerchantsByRegion 根据地区获取商家
-func (r *MerchantRepository) GetMerchantsByRegion(region string, offset, limit int) ([]*model.Merchant, int64, error) {
+func (r *MerchantRepository) GetMerchantsByRegion(ctx context.Context, region string, offset, limit int) ([]*model.Merchant, int64, error) {
 	if region == "" {
 		return nil, 0, ErrRegionEmpty
 	}
@@ -369,7 +482,7 @@ func (r *MerchantRepository) GetMerchantsByRegion(region string, offset, limit i
 	var total int64
 
 	regionPattern := "%" + region + "%"
-	query := r.db.Model(&model.Merchant{}).Where("address LIKE ?", regionPattern)
+	query := r.conn(ctx).Model(&model.Merchant{}).Where("address LIKE ?", regionPattern)
 
 	// 获取指定地区商家总数
 	if err := query.Count(&total).Error; err != nil {
@@ -389,13 +502,13 @@ func (r *MerchantRepository) GetMerchantsByRegion(region string, offset, limit i
 // #region 工具方法
 
 // IsUsernameAvailable 检查用户名是否可用
-func (r *MerchantRepository) IsUsernameAvailable(username string) (bool, error) {
+func (r *MerchantRepository) IsUsernameAvailable(ctx context.Context, username string) (bool, error) {
 	if username == "" {
 		return false, ErrUsernameEmpty
 	}
 
 	var count int64
-	if err := r.db.Model(&model.Merchant{}).Where("username = ?", username).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Merchant{}).Where("username = ?", username).Count(&count).Error; err != nil {
 		return false, err
 	}
 
@@ -403,13 +516,13 @@ func (r *MerchantRepository) IsUsernameAvailable(username string) (bool, error)
 }
 
 // IsEmailAvailable 检查邮箱是否可用
-func (r *MerchantRepository) IsEmailAvailable(email string) (bool, error) {
+func (r *MerchantRepository) IsEmailAvailable(ctx context.Context, email string) (bool, error) {
 	if email == "" {
 		return false, ErrEmailEmpty
 	}
 
 	var count int64
-	if err := r.db.Model(&model.Merchant{}).Where("email = ?", email).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Merchant{}).Where("email = ?", email).Count(&count).Error; err != nil {
 		return false, err
 	}
 
@@ -417,13 +530,13 @@ func (r *MerchantRepository) IsEmailAvailable(email string) (bool, error) {
 }
 
 // IsPhoneAvailable 检查手机号是否可用
-func (r *MerchantRepository) IsPhoneAvailable(phone string) (bool, error) {
+func (r *MerchantRepository) IsPhoneAvailable(ctx context.Context, phone string) (bool, error) {
 	if phone == "" {
 		return false, ErrPhoneEmpty
 	}
 
 	var count int64
-	if err := r.db.Model(&model.Merchant{}).Where("phone = ?", phone).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Merchant{}).Where("phone = ?", phone).Count(&count).Error; err != nil {
 		return false, err
 	}
 
@@ -431,13 +544,13 @@ func (r *MerchantRepository) IsPhoneAvailable(phone string) (bool, error) {
 }
 
 // IsBusinessLicenseAvailable 检查营业执照号是否可用
-func (r *MerchantRepository) IsBusinessLicenseAvailable(license string) (bool, error) {
+func (r *MerchantRepository) IsBusinessLicenseAvailable(ctx context.Context, license string) (bool, error) {
 	if license == "" {
 		return false, ErrBusinessLicenseEmpty
 	}
 
 	var count int64
-	if err := r.db.Model(&model.Merchant{}).Where("business_license = ?", license).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Merchant{}).Where("business_license = ?", license).Count(&count).Error; err != nil {
 		return false, err
 	}
 
@@ -445,20 +558,20 @@ func (r *MerchantRepository) IsBusinessLicenseAvailable(license string) (bool, e
 }
 
 // GetTopMerchantsByEmployeeCount 获取员工数量最多的商家（排行榜）
-func (r *MerchantRepository) GetTopMerchantsByEmployeeCount(limit int) ([]*model.Merchant, error) {
+func (r *MerchantRepository) GetTopMerchantsByEmployeeCount(ctx context.Context, limit int) ([]*model.Merchant, error) {
 	if limit <= 0 {
 		return nil, ErrLimitInvalid
 	}
 
 	var merchants []*model.Merchant
 
-	subQuery := r.db.Model(&model.Employee{}).
+	subQuery := r.conn(ctx).Model(&model.Employee{}).
 		Select("merchant_id, COUNT(*) as employee_count").
 		Group("merchant_id").
 		Order("employee_count DESC").
 		Limit(limit)
 
-	if err := r.db.Where("id IN (?)", subQuery).Find(&merchants).Error; err != nil {
+	if err := r.conn(ctx).Where("id IN (?)", subQuery).Find(&merchants).Error; err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// #region 仓库定义
+
+// OrderRepositoryInterface 订单仓库接口
+type OrderRepositoryInterface interface {
+	// 基础操作
+	Create(ctx context.Context, order *model.Order) error
+	GetByID(ctx context.Context, id int64) (*model.Order, error)
+	GetByOrderNo(ctx context.Context, orderNo string) (*model.Order, error)
+
+	// UpdateStatus 以 fromStatus 及当前指派的配送员为前提条件保存订单（乐观并发控制）
+	// 订单状态或配送员已被其他请求修改时返回 ErrOrderStatusConflict
+	UpdateStatus(ctx context.Context, order *model.Order, fromStatus string) error
+	// RateRider 记录用户对配送员的评分，仅对已送达且未评价的订单生效
+	RateRider(ctx context.Context, id int64, rating float32) error
+
+	// 派单
+	// AssignRider 为尚未指派且未取货的订单指派配送员，条件不满足时返回 ErrOrderStatusConflict
+	AssignRider(ctx context.Context, id, riderID int64) error
+	// CountActiveByRiders 统计配送员手中未完成的订单数（已指派、未送达且未取消）
+	CountActiveByRiders(ctx context.Context, riderIDs []int64) (map[int64]int64, error)
+
+	// 列表查询（status 为空时不过滤）
+	ListByUser(ctx context.Context, userID int64, status string, offset, limit int) ([]*model.Order, int64, error)
+	ListByMerchant(ctx context.Context, merchantID int64, status string, offset, limit int) ([]*model.Order, int64, error)
+	ListByRider(ctx context.Context, riderID int64, status string, offset, limit int) ([]*model.Order, int64, error)
+}
+
+// OrderRepository 订单仓库实现
+type OrderRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderRepository 创建订单仓库实例
+func NewOrderRepository(db *gorm.DB) OrderRepositoryInterface {
+	return &OrderRepository{
+		db: db,
+	}
+}
+
+// conn 解析本次调用使用的连接：ctx 中有事务时加入事务
+func (r *OrderRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+// #endregion
+
+// #region 基础操作
+
+// Create 创建订单及其明细
+func (r *OrderRepository) Create(ctx context.Context, order *model.Order) error {
+	if order == nil {
+		return ErrOrderNil
+	}
+
+	return r.conn(ctx).Create(order).Error
+}
+
+// GetByID 根据ID获取订单（含明细）
+func (r *OrderRepository) GetByID(ctx context.Context, id int64) (*model.Order, error) {
+	if id <= 0 {
+		return nil, ErrOrderIDInvalid
+	}
+
+	var order model.Order
+	if err := r.conn(ctx).Preload("Items.Options").Where("id = ?", id).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetByOrderNo 根据订单号获取订单（含明细）
+func (r *OrderRepository) GetByOrderNo(ctx context.Context, orderNo string) (*model.Order, error) {
+	if orderNo == "" {
+		return nil, ErrOrderNoEmpty
+	}
+
+	var order model.Order
+	if err := r.conn(ctx).Preload("Items.Options").Where("order_no = ?", orderNo).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// UpdateStatus 条件更新：仅当数据库中的状态仍为 fromStatus、且配送员仍为 order.RiderID 时写入
+// 状态迁移不修改配送员，指派只经由 AssignRider，因此并发指派会使本次更新失败
+func (r *OrderRepository) UpdateStatus(ctx context.Context, order *model.Order, fromStatus string) error {
+	if order == nil {
+		return ErrOrderNil
+	}
+	if order.ID <= 0 {
+		return ErrOrderIDInvalid
+	}
+
+	query := r.conn(ctx).Model(order).Where("status = ?", fromStatus)
+	if order.RiderID == nil {
+		query = query.Where("rider_id IS NULL")
+	} else {
+		query = query.Where("rider_id = ?", *order.RiderID)
+	}
+	result := query.
+		Select("*").
+		Omit("created_at", clause.Associations).
+		Updates(order)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOrderStatusConflict
+	}
+	return nil
+}
+
+// RateRider 以"已送达且未评价"为前提写入评分，防止重复评价
+func (r *OrderRepository) RateRider(ctx context.Context, id int64, rating float32) error {
+	if id <= 0 {
+		return ErrOrderIDInvalid
+	}
+
+	result := r.conn(ctx).Model(&model.Order{}).
+		Where("id = ? AND status = ? AND rider_rating = 0", id, model.OrderStatusDelivered).
+		Update("rider_rating", rating)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOrderStatusConflict
+	}
+	return nil
+}
+
+// #endregion
+
+// #region 派单
+
+// AssignRider 条件指派：订单未指派配送员且处于接单/出餐阶段
+func (r *OrderRepository) AssignRider(ctx context.Context, id, riderID int64) error {
+	if id <= 0 {
+		return ErrOrderIDInvalid
+	}
+	if riderID <= 0 {
+		return ErrRiderIDInvalid
+	}
+
+	result := r.conn(ctx).Model(&model.Order{}).
+		Where("id = ? AND rider_id IS NULL AND status IN ?", id,
+			[]string{model.OrderStatusAccepted, model.OrderStatusPrepared}).
+		Update("rider_id", riderID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOrderStatusConflict
+	}
+	return nil
+}
+
+// CountActiveByRiders 按配送员统计进行中的订单数，没有进行中订单的配送员不出现在结果中
+func (r *OrderRepository) CountActiveByRiders(ctx context.Context, riderIDs []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(riderIDs))
+	if len(riderIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		RiderID int64 `gorm:"column:rider_id"`
+		Count   int64 `gorm:"column:count"`
+	}
+	if err := r.conn(ctx).Model(&model.Order{}).
+		Select("rider_id, COUNT(*) AS count").
+		Where("rider_id IN ? AND status NOT IN ?", riderIDs,
+			[]string{model.OrderStatusDelivered, model.OrderStatusCancelled}).
+		Group("rider_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.RiderID] = row.Count
+	}
+	return counts, nil
+}
+
+// #endregion
+
+// #region 列表查询
+
+// ListByUser 分页获取用户订单
+func (r *OrderRepository) ListByUser(ctx context.Context, userID int64, status string, offset, limit int) ([]*model.Order, int64, error) {
+	if userID <= 0 {
+		return nil, 0, ErrUserIDZero
+	}
+	return r.list(r.conn(ctx).Where("user_id = ?", userID), status, offset, limit)
+}
+
+// ListByMerchant 分页获取商家订单
+func (r *OrderRepository) ListByMerchant(ctx context.Context, merchantID int64, status string, offset, limit int) ([]*model.Order, int64, error) {
+	if merchantID <= 0 {
+		return nil, 0, ErrMerchantIDInvalid
+	}
+	return r.list(r.conn(ctx).Where("merchant_id = ?", merchantID), status, offset, limit)
+}
+
+// ListByRider 分页获取配送员订单
+func (r *OrderRepository) ListByRider(ctx context.Context, riderID int64, status string, offset, limit int) ([]*model.Order, int64, error) {
+	if riderID <= 0 {
+		return nil, 0, ErrRiderIDInvalid
+	}
+	return r.list(r.conn(ctx).Where("rider_id = ?", riderID), status, offset, limit)
+}
+
+// list 按状态过滤并分页，最新订单在前
+func (r *OrderRepository) list(query *gorm.DB, status string, offset, limit int) ([]*model.Order, int64, error) {
+	if offset < 0 || limit <= 0 {
+		return nil, 0, ErrPaginationParametersInvalid
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Model(&model.Order{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orders []*model.Order
+	if err := query.Preload("Items.Options").Order("created_at DESC").
+		Offset(offset).Limit(limit).Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// #endregion
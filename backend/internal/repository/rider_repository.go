@@ -1,8 +1,14 @@
 package repository
 
 import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Hermitf/the-pass/internal/database"
 	"github.com/Hermitf/the-pass/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // #region 仓库定义
@@ -10,39 +16,55 @@ import (
 // RiderRepositoryInterface 配送员仓库接口
 type RiderRepositoryInterface interface {
 	// 基础CRUD
-	Create(rider *model.Rider) error
-	GetByID(id int64) (*model.Rider, error)
-	Update(rider *model.Rider) error
-	Delete(id int64) error
+	Create(ctx context.Context, rider *model.Rider) error
+	GetByID(ctx context.Context, id int64) (*model.Rider, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]*model.Rider, error)
+	// GetByIDForUpdate 在事务中读取并锁定配送员行（SELECT ... FOR UPDATE），须在 TxManager.WithinTx 内调用
+	GetByIDForUpdate(ctx context.Context, id int64) (*model.Rider, error)
+	Update(ctx context.Context, rider *model.Rider) error
+	UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error
+	SetActive(ctx context.Context, id int64, active bool) error
+	// UpdateVerification 只更新资质审核相关字段
+	UpdateVerification(ctx context.Context, rider *model.Rider) error
+	// UpdateIdentity 只更新待审核的身份与车辆信息
+	UpdateIdentity(ctx context.Context, rider *model.Rider) error
+	Delete(ctx context.Context, id int64) error
 
 	// 查询方法
-	GetByUsername(username string) (*model.Rider, error)
-	GetByEmail(email string) (*model.Rider, error)
-	GetByPhone(phone string) (*model.Rider, error)
-	GetByIDNumber(idNumber string) (*model.Rider, error)
-	GetByLicenseNumber(licenseNumber string) (*model.Rider, error)
+	GetByUsername(ctx context.Context, username string) (*model.Rider, error)
+	GetByEmail(ctx context.Context, email string) (*model.Rider, error)
+	GetByPhone(ctx context.Context, phone string) (*model.Rider, error)
+	GetByIDNumber(ctx context.Context, idNumber string) (*model.Rider, error)
+	GetByLicenseNumber(ctx context.Context, licenseNumber string) (*model.Rider, error)
 
 	// 位置管理
-	UpdateLocation(id int64, lat, lng float64) error
-	GetRidersNearLocation(lat, lng, radiusKm float64) ([]*model.Rider, error)
-	GetRidersByRegion(bounds map[string]float64) ([]*model.Rider, error)
+	UpdateLocation(ctx context.Context, id int64, lat, lng float64) error
+	BatchUpdateLocations(ctx context.Context, positions []model.RiderPosition) error
+	GetRidersNearLocation(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error)
+	GetRidersByRegion(ctx context.Context, bounds map[string]float64) ([]*model.Rider, error)
 
 	// 状态管理
-	UpdateOnlineStatus(id int64, isOnline bool) error
-	GetOnlineRiders(offset, limit int) ([]*model.Rider, int64, error)
-	GetActiveRiders(offset, limit int) ([]*model.Rider, int64, error)
-	GetAvailableRiders(lat, lng, radiusKm float64) ([]*model.Rider, error)
+	UpdateOnlineStatus(ctx context.Context, id int64, isOnline bool) error
+	MarkOffline(ctx context.Context, ids []int64) error
+	GetOnlineRiders(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error)
+	GetActiveRiders(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error)
+	GetAvailableRiders(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error)
 
 	// 列表查询
-	GetRiderList(offset, limit int) ([]*model.Rider, int64, error)
-	SearchRiders(keyword string, offset, limit int) ([]*model.Rider, int64, error)
-	GetRidersByVehicleType(vehicleType string, offset, limit int) ([]*model.Rider, int64, error)
+	GetRiderList(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error)
+	SearchRiders(ctx context.Context, keyword string, offset, limit int) ([]*model.Rider, int64, error)
+	GetRidersByVehicleType(ctx context.Context, vehicleType string, offset, limit int) ([]*model.Rider, int64, error)
+	ListByVerificationStatus(ctx context.Context, status string, offset, limit int) ([]*model.Rider, int64, error)
 
 	// 业务查询方法
-	CheckRiderExists(username, email, phone, licenseNumber string) (bool, error)
-	GetRiderStats() (map[string]interface{}, error)
-	GetTopRidersByRating(limit int) ([]*model.Rider, error)
-	GetRidersByOrderCount(minOrders, maxOrders int64) ([]*model.Rider, error)
+	CheckRiderExists(ctx context.Context, username, email, phone, licenseNumber string) (bool, error)
+	GetRiderStats(ctx context.Context) (map[string]interface{}, error)
+	GetTopRidersByRating(ctx context.Context, limit int) ([]*model.Rider, error)
+	GetRidersByOrderCount(ctx context.Context, minOrders, maxOrders int64) ([]*model.Rider, error)
+
+	// 订单统计
+	IncrementTotalOrders(ctx context.Context, id int64) error
+	ApplyRating(ctx context.Context, id int64, rating float32) error
 }
 
 // RiderRepository 配送员仓库实现
@@ -57,48 +79,126 @@ func NewRiderRepository(db *gorm.DB) RiderRepositoryInterface {
 	}
 }
 
+// conn 解析本次调用使用的连接：ctx 中有事务时加入事务
+func (r *RiderRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
 // #endregion
 
 // #region 基础CRUD操作
 
 // Create 创建配送员
-func (r *RiderRepository) Create(rider *model.Rider) error {
+func (r *RiderRepository) Create(ctx context.Context, rider *model.Rider) error {
 	if rider == nil {
 		return ErrRiderNil
 	}
 
-	return r.db.Create(rider).Error
+	return r.conn(ctx).Create(rider).Error
 }
 
 // GetByID 根据ID获取配送员
-func (r *RiderRepository) GetByID(id int64) (*model.Rider, error) {
+func (r *RiderRepository) GetByID(ctx context.Context, id int64) (*model.Rider, error) {
 	if id <= 0 {
 		return nil, ErrRiderIDInvalid
 	}
 
 	var rider model.Rider
-	if err := r.db.Where("id = ?", id).First(&rider).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&rider).Error; err != nil {
+		return nil, err
+	}
+	return &rider, nil
+}
+
+// GetByIDs 批量获取配送员，不存在的ID被忽略，结果顺序不保证
+func (r *RiderRepository) GetByIDs(ctx context.Context, ids []int64) ([]*model.Rider, error) {
+	var riders []*model.Rider
+	if len(ids) == 0 {
+		return riders, nil
+	}
+
+	if err := r.conn(ctx).Where("id IN ?", ids).Find(&riders).Error; err != nil {
+		return nil, err
+	}
+	return riders, nil
+}
+
+// GetByIDForUpdate 根据ID获取配送员并加行锁，直到所在事务结束
+func (r *RiderRepository) GetByIDForUpdate(ctx context.Context, id int64) (*model.Rider, error) {
+	if id <= 0 {
+		return nil, ErrRiderIDInvalid
+	}
+
+	var rider model.Rider
+	if err := r.conn(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&rider).Error; err != nil {
 		return nil, err
 	}
 	return &rider, nil
 }
 
 // Update 更新配送员信息
-func (r *RiderRepository) Update(rider *model.Rider) error {
+func (r *RiderRepository) Update(ctx context.Context, rider *model.Rider) error {
 	if rider == nil {
 		return ErrRiderNil
 	}
 
-	return r.db.Save(rider).Error
+	return r.conn(ctx).Save(rider).Error
+}
+
+// UpdatePasswordHash 只更新密码哈希，避免整行保存覆盖并发修改的其他字段
+func (r *RiderRepository) UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error {
+	if id <= 0 {
+		return ErrRiderIDInvalid
+	}
+
+	return r.conn(ctx).Model(&model.Rider{}).Where("id = ?", id).Update("password_hash", passwordHash).Error
+}
+
+// SetActive 只更新激活状态，记录不存在时返回 ErrRiderNotFound
+func (r *RiderRepository) SetActive(ctx context.Context, id int64, active bool) error {
+	if id <= 0 {
+		return ErrRiderIDInvalid
+	}
+
+	result := r.conn(ctx).Model(&model.Rider{}).Where("id = ?", id).Update("is_active", active)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRiderNotFound
+	}
+	return nil
+}
+
+// UpdateVerification 只更新资质审核相关字段，避免整行保存覆盖并发修改的其他字段
+func (r *RiderRepository) UpdateVerification(ctx context.Context, rider *model.Rider) error {
+	if rider == nil {
+		return ErrRiderNil
+	}
+
+	return r.conn(ctx).Model(rider).
+		Select("verification_status", "verification_reject_reason", "verification_submitted_at", "verified_at").
+		Updates(rider).Error
+}
+
+// UpdateIdentity 只更新身份与车辆信息，避免整行保存覆盖实时位置与在线状态
+func (r *RiderRepository) UpdateIdentity(ctx context.Context, rider *model.Rider) error {
+	if rider == nil {
+		return ErrRiderNil
+	}
+
+	return r.conn(ctx).Model(rider).
+		Select("name", "id_number", "vehicle_type", "vehicle_number", "license_number").
+		Updates(rider).Error
 }
 
 // Delete 删除配送员（软删除）
-func (r *RiderRepository) Delete(id int64) error {
+func (r *RiderRepository) Delete(ctx context.Context, id int64) error {
 	if id <= 0 {
 		return ErrRiderIDInvalid
 	}
 
-	return r.db.Delete(&model.Rider{}, id).Error
+	return r.conn(ctx).Delete(&model.Rider{}, id).Error
 }
 
 // #endregion
@@ -106,65 +206,65 @@ func (r *RiderRepository) Delete(id int64) error {
 // #region 查询方法
 
 // GetByUsername 根据用户名获取配送员
-func (r *RiderRepository) GetByUsername(username string) (*model.Rider, error) {
+func (r *RiderRepository) GetByUsername(ctx context.Context, username string) (*model.Rider, error) {
 	if username == "" {
 		return nil, ErrUsernameEmpty
 	}
 
 	var rider model.Rider
-	if err := r.db.Where("username = ?", username).First(&rider).Error; err != nil {
+	if err := r.conn(ctx).Where("username = ?", username).First(&rider).Error; err != nil {
 		return nil, err
 	}
 	return &rider, nil
 }
 
 // GetByEmail 根据邮箱获取配送员
-func (r *RiderRepository) GetByEmail(email string) (*model.Rider, error) {
+func (r *RiderRepository) GetByEmail(ctx context.Context, email string) (*model.Rider, error) {
 	if email == "" {
 		return nil, ErrEmailEmpty
 	}
 
 	var rider model.Rider
-	if err := r.db.Where("email = ?", email).First(&rider).Error; err != nil {
+	if err := r.conn(ctx).Where("email = ?", email).First(&rider).Error; err != nil {
 		return nil, err
 	}
 	return &rider, nil
 }
 
 // GetByPhone 根据手机号获取配送员
-func (r *RiderRepository) GetByPhone(phone string) (*model.Rider, error) {
+func (r *RiderRepository) GetByPhone(ctx context.Context, phone string) (*model.Rider, error) {
 	if phone == "" {
 		return nil, ErrPhoneEmpty
 	}
 
 	var rider model.Rider
-	if err := r.db.Where("phone = ?", phone).First(&rider).Error; err != nil {
+	if err := r.conn(ctx).Where("phone = ?", phone).First(&rider).Error; err != nil {
 		return nil, err
 	}
 	return &rider, nil
 }
 
 // GetByIDNumber 根据身份证号获取配送员
-func (r *RiderRepository) GetByIDNumber(idNumber string) (*model.Rider, error) {
+func (r *RiderRepository) GetByIDNumber(ctx context.Context, idNumber string) (*model.Rider, error) {
 	if idNumber == "" {
 		return nil, ErrIDNumberEmpty
 	}
 
 	var rider model.Rider
-	if err := r.db.Where("id_number = ?", idNumber).First(&rider).Error; err != nil {
+	if err := r.conn(ctx).Where("id_number = ?", idNumber).First(&rider).Error; err != nil {
 		return nil, err
 	}
 	return &rider, nil
 }
 
 // GetByLicenseNumber 根据驾照号获取配送员
-func (r *RiderRepository) GetByLicenseNumber(licenseNumber string) (*model.Rider, error) {
+func (r *RiderRepository) GetByLicenseNumber(ctx context.Context, licenseNumber string) (*model.Rider, error) {
 	if licenseNumber == "" {
 		return nil, ErrLicenseNumberEmpty
 	}
 
 	var rider model.Rider
-	if err := r.db.Where("license_number = ?", licenseNumber).First(&rider).Error; err != nil {
+	if err := r.conn(ctx).Where("license_number = ?", licenseNumber).First(&rider).Error; err != nil {
 		return nil, err
 	}
 	return &rider, nil
@@ -175,7 +275,7 @@ func (r *RiderRepository) GetByLicenseNumber(licenseNumber string) (*model.Rider
 // #region 位置管理
 
 // UpdateLocation 更新配送员位置
-func (r *RiderRepository) UpdateLocation(id int64, lat, lng float64) error {
+func (r *RiderRepository) UpdateLocation(ctx context.Context, id int64, lat, lng float64) error {
 	if id <= 0 {
 		return ErrRiderIDInvalid
 	}
@@ -185,40 +285,44 @@ func (r *RiderRepository) UpdateLocation(id int64, lat, lng float64) error {
 		return ErrLocationInvalid
 	}
 
-	return r.db.Model(&model.Rider{}).Where("id = ?", id).Updates(map[string]interface{}{
+	return r.conn(ctx).Model(&model.Rider{}).Where("id = ?", id).Updates(map[string]interface{}{
 		"current_lat": lat,
 		"current_lng": lng,
 	}).Error
 }
 
-// GetRidersNearLocation 获取指定位置附近的配送员
-func (r *RiderRepository) GetRidersNearLocation(lat, lng, radiusKm float64) ([]*model.Rider, error) {
-	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
-		return nil, ErrLocationInvalid
-	}
-	if radiusKm <= 0 {
-		return nil, ErrRadiusInvalid
-	}
-
-	var riders []*model.Rider
-
-	// 使用 Haversine 公式计算距离（MySQL版本）
-	// 这里使用简化的矩形范围查询，实际项目中可以使用更精确的距离计算
-	latRange := radiusKm / 111.0           // 大约每度纬度 111km
-	lngRange := radiusKm / (111.0 * 0.707) // 经度范围（简化计算）
-
-	if err := r.db.Where(
-		"current_lat BETWEEN ? AND ? AND current_lng BETWEEN ? AND ? AND is_active = ? AND is_online = ?",
-		lat-latRange, lat+latRange, lng-lngRange, lng+lngRange, true, true,
-	).Find(&riders).Error; err != nil {
-		return nil, err
-	}
+// BatchUpdateLocations 在一条语句中批量写入配送员位置（PostgreSQL UPDATE ... FROM VALUES）
+func (r *RiderRepository) BatchUpdateLocations(ctx context.Context, positions []model.RiderPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(positions))
+	args := make([]interface{}, 0, len(positions)*3)
+	for _, p := range positions {
+		if p.RiderID <= 0 {
+			return ErrRiderIDInvalid
+		}
+		if p.Lat < -90 || p.Lat > 90 || p.Lng < -180 || p.Lng > 180 {
+			return ErrLocationInvalid
+		}
+		values = append(values, "(?::bigint, ?::double precision, ?::double precision)")
+		args = append(args, p.RiderID, p.Lat, p.Lng)
+	}
+
+	sql := "UPDATE riders AS r SET current_lat = v.lat, current_lng = v.lng, updated_at = NOW() " +
+		"FROM (VALUES " + strings.Join(values, ", ") + ") AS v(id, lat, lng) " +
+		"WHERE r.id = v.id AND r.deleted_at IS NULL"
+	return r.conn(ctx).Exec(sql, args...).Error
+}
 
-	return riders, nil
+// GetRidersNearLocation 获取指定位置半径内的在线配送员，按距离由近到远排序
+func (r *RiderRepository) GetRidersNearLocation(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error) {
+	return r.findNearby(r.conn(ctx).Where("is_active = ? AND is_online = ?", true, true), lat, lng, radiusKm, limit)
 }
 
 // GetRidersByRegion 根据地理边界获取配送员
-func (r *RiderRepository) GetRidersByRegion(bounds map[string]float64) ([]*model.Rider, error) {
+func (r *RiderRepository) GetRidersByRegion(ctx context.Context, bounds map[string]float64) ([]*model.Rider, error) {
 	minLat, hasMinLat := bounds["min_lat"]
 	maxLat, hasMaxLat := bounds["max_lat"]
 	minLng, hasMinLng := bounds["min_lng"]
@@ -229,7 +333,7 @@ func (r *RiderRepository) GetRidersByRegion(bounds map[string]float64) ([]*model
 	}
 
 	var riders []*model.Rider
-	if err := r.db.Where(
+	if err := r.conn(ctx).Where(
 		"current_lat BETWEEN ? AND ? AND current_lng BETWEEN ? AND ?",
 		minLat, maxLat, minLng, maxLng,
 	).Find(&riders).Error; err != nil {
@@ -244,16 +348,25 @@ func (r *RiderRepository) GetRidersByRegion(bounds map[string]float64) ([]*model
 // #region 状态管理
 
 // UpdateOnlineStatus 更新在线状态
-func (r *RiderRepository) UpdateOnlineStatus(id int64, isOnline bool) error {
+func (r *RiderRepository) UpdateOnlineStatus(ctx context.Context, id int64, isOnline bool) error {
 	if id <= 0 {
 		return ErrRiderIDInvalid
 	}
 
-	return r.db.Model(&model.Rider{}).Where("id = ?", id).Update("is_online", isOnline).Error
+	return r.conn(ctx).Model(&model.Rider{}).Where("id = ?", id).Update("is_online", isOnline).Error
+}
+
+// MarkOffline 批量将配送员置为离线
+func (r *RiderRepository) MarkOffline(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return r.conn(ctx).Model(&model.Rider{}).Where("id IN ?", ids).Update("is_online", false).Error
 }
 
 // GetOnlineRiders 获取在线配送员列表
-func (r *RiderRepository) GetOnlineRiders(offset, limit int) ([]*model.Rider, int64, error) {
+func (r *RiderRepository) GetOnlineRiders(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationParametersInvalid
 	}
@@ -261,7 +374,7 @@ func (r *RiderRepository) GetOnlineRiders(offset, limit int) ([]*model.Rider, in
 	var riders []*model.Rider
 	var total int64
 
-	query := r.db.Model(&model.Rider{}).Where("is_online = ?", true)
+	query := r.conn(ctx).Model(&model.Rider{}).Where("is_online = ?", true)
 
 	// 获取在线配送员总数
 	if err := query.Count(&total).Error; err != nil {
@@ -277,7 +390,7 @@ func (r *RiderRepository) GetOnlineRiders(offset, limit int) ([]*model.Rider, in
 }
 
 // GetActiveRiders 获取活跃配送员列表
-func (r *RiderRepository) GetActiveRiders(offset, limit int) ([]*model.Rider, int64, error) {
+func (r *RiderRepository) GetActiveRiders(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationParametersInvalid
 	}
@@ -285,7 +398,7 @@ func (r *RiderRepository) GetActiveRiders(offset, limit int) ([]*model.Rider, in
 	var riders []*model.Rider
 	var total int64
 
-	query := r.db.Model(&model.Rider{}).Where("is_active = ?", true)
+	query := r.conn(ctx).Model(&model.Rider{}).Where("is_active = ?", true)
 
 	// 获取活跃配送员总数
 	if err := query.Count(&total).Error; err != nil {
@@ -300,29 +413,56 @@ func (r *RiderRepository) GetActiveRiders(offset, limit int) ([]*model.Rider, in
 	return riders, total, nil
 }
 
-// GetAvailableRiders 获取可接单的配送员（在线且活跃的附近配送员）
-func (r *RiderRepository) GetAvailableRiders(lat, lng, radiusKm float64) ([]*model.Rider, error) {
+// GetAvailableRiders 获取可接单的配送员（在线、活跃且已通过资质审核的附近配送员），按距离由近到远排序
+func (r *RiderRepository) GetAvailableRiders(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error) {
+	query := r.conn(ctx).Where("is_active = ? AND is_online = ? AND verification_status = ?", true, true, model.VerificationApproved)
+	return r.findNearby(query, lat, lng, radiusKm, limit)
+}
+
+// findNearby 先用随纬度变化的外接矩形在数据库中粗筛，再按 Haversine 距离精确过滤、排序并截取前 limit 个
+func (r *RiderRepository) findNearby(query *gorm.DB, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error) {
 	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
 		return nil, ErrLocationInvalid
 	}
 	if radiusKm <= 0 {
 		return nil, ErrRadiusInvalid
 	}
+	if limit <= 0 {
+		return nil, ErrLimitInvalid
+	}
 
-	var riders []*model.Rider
-
-	// 使用简化的矩形范围查询
-	latRange := radiusKm / 111.0
-	lngRange := radiusKm / (111.0 * 0.707)
+	minLat, maxLat, minLng, maxLng := model.BoundingBox(lat, lng, radiusKm)
+	query = query.Where("current_lat BETWEEN ? AND ?", minLat, maxLat)
+	if minLng <= maxLng {
+		query = query.Where("current_lng BETWEEN ? AND ?", minLng, maxLng)
+	} else {
+		// 跨越 180° 经线
+		query = query.Where("(current_lng >= ? OR current_lng <= ?)", minLng, maxLng)
+	}
 
-	if err := r.db.Where(
-		"current_lat BETWEEN ? AND ? AND current_lng BETWEEN ? AND ? AND is_active = ? AND is_online = ?",
-		lat-latRange, lat+latRange, lng-lngRange, lng+lngRange, true, true,
-	).Order("rating DESC").Find(&riders).Error; err != nil {
+	var riders []*model.Rider
+	if err := query.Find(&riders).Error; err != nil {
 		return nil, err
 	}
 
-	return riders, nil
+	nearby := make([]*model.NearbyRider, 0, len(riders))
+	for _, rider := range riders {
+		distance := rider.CalculateDistance(lat, lng)
+		if distance <= radiusKm {
+			nearby = append(nearby, &model.NearbyRider{Rider: rider, DistanceKm: distance})
+		}
+	}
+
+	sort.SliceStable(nearby, func(i, j int) bool {
+		if nearby[i].DistanceKm != nearby[j].DistanceKm {
+			return nearby[i].DistanceKm < nearby[j].DistanceKm
+		}
+		return nearby[i].Rider.ID < nearby[j].Rider.ID
+	})
+	if len(nearby) > limit {
+		nearby = nearby[:limit]
+	}
+	return nearby, nil
 }
 
 // #endregion
@@ -330,7 +470,7 @@ func (r *RiderRepository) GetAvailableRiders(lat, lng, radiusKm float64) ([]*mod
 // #region 列表查询
 
 // GetRiderList 分页获取配送员列表
-func (r *RiderRepository) GetRiderList(offset, limit int) ([]*model.Rider, int64, error) {
+func (r *RiderRepository) GetRiderList(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationParametersInvalid
 	}
@@ -339,12 +479,12 @@ func (r *RiderRepository) GetRiderList(offset, limit int) ([]*model.Rider, int64
 	var total int64
 
 	// 获取总数
-	if err := r.db.Model(&model.Rider{}).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Rider{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 分页查询
-	if err := r.db.Offset(offset).Limit(limit).Find(&riders).Error; err != nil {
+	if err := r.conn(ctx).Offset(offset).Limit(limit).Find(&riders).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -352,9 +492,9 @@ func (r *RiderRepository) GetRiderList(offset, limit int) ([]*model.Rider, int64
 }
 
 // SearchRiders 搜索配送员
-func (r *RiderRepository) SearchRiders(keyword string, offset, limit int) ([]*model.Rider, int64, error) {
+func (r *RiderRepository) SearchRiders(ctx context.Context, keyword string, offset, limit int) ([]*model.Rider, int64, error) {
 	if keyword == "" {
-		return r.GetRiderList(offset, limit)
+		return r.GetRiderList(ctx, offset, limit)
 	}
 
 	if offset < 0 || limit <= 0 {
@@ -365,7 +505,7 @@ func (r *RiderRepository) SearchRiders(keyword string, offset, limit int) ([]*mo
 	var total int64
 
 	searchPattern := "%" + keyword + "%"
-	query := r.db.Model(&model.Rider{}).Where(
+	query := r.conn(ctx).Model(&model.Rider{}).Where(
 		"username LIKE ? OR email LIKE ? OR phone LIKE ? OR name LIKE ? OR license_number LIKE ? OR vehicle_number LIKE ?",
 		searchPattern, searchPattern, searchPattern, searchPattern, searchPattern, searchPattern,
 	)
@@ -384,7 +524,7 @@ func (r *RiderRepository) SearchRiders(keyword string, offset, limit int) ([]*mo
 }
 
 // GetRidersByVehicleType 根据交通工具类型获取配送员
-func (r *RiderRepository) GetRidersByVehicleType(vehicleType string, offset, limit int) ([]*model.Rider, int64, error) {
+func (r *RiderRepository) GetRidersByVehicleType(ctx context.Context, vehicleType string, offset, limit int) ([]*model.Rider, int64, error) {
 	if vehicleType == "" {
 		return nil, 0, ErrVehicleTypeEmpty
 	}
@@ -395,7 +535,7 @@ func (r *RiderRepository) GetRidersByVehicleType(vehicleType string, offset, lim
 	var riders []*model.Rider
 	var total int64
 
-	query := r.db.Model(&model.Rider{}).Where("vehicle_type = ?", vehicleType)
+	query := r.conn(ctx).Model(&model.Rider{}).Where("vehicle_type = ?", vehicleType)
 
 	// 获取指定交通工具类型配送员总数
 	if err := query.Count(&total).Error; err != nil {
@@ -410,15 +550,35 @@ func (r *RiderRepository) GetRidersByVehicleType(vehicleType string, offset, lim
 	return riders, total, nil
 }
 
+// ListByVerificationStatus 按资质审核状态分页获取配送员，先提交的排在前面
+func (r *RiderRepository) ListByVerificationStatus(ctx context.Context, status string, offset, limit int) ([]*model.Rider, int64, error) {
+	if offset < 0 || limit <= 0 {
+		return nil, 0, ErrPaginationParametersInvalid
+	}
+
+	var riders []*model.Rider
+	var total int64
+
+	query := r.conn(ctx).Model(&model.Rider{}).Where("verification_status = ?", status)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("verification_submitted_at ASC, id ASC").Offset(offset).Limit(limit).Find(&riders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return riders, total, nil
+}
+
 // #endregion
 
 // #region 业务查询方法
 
 // CheckRiderExists 检查配送员是否已存在
-func (r *RiderRepository) CheckRiderExists(username, email, phone, licenseNumber string) (bool, error) {
+func (r *RiderRepository) CheckRiderExists(ctx context.Context, username, email, phone, licenseNumber string) (bool, error) {
 	var count int64
 
-	query := r.db.Model(&model.Rider{})
+	query := r.conn(ctx).Model(&model.Rider{})
 	conditions := []string{}
 	args := []interface{}{}
 
@@ -456,33 +616,33 @@ func (r *RiderRepository) CheckRiderExists(username, email, phone, licenseNumber
 }
 
 // GetRiderStats 获取配送员统计信息
-func (r *RiderRepository) GetRiderStats() (map[string]interface{}, error) {
+func (r *RiderRepository) GetRiderStats(ctx context.Context) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
 	// 总配送员数
 	var totalRiders int64
-	if err := r.db.Model(&model.Rider{}).Count(&totalRiders).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Rider{}).Count(&totalRiders).Error; err != nil {
 		return nil, err
 	}
 	stats["total_riders"] = totalRiders
 
 	// 活跃配送员数
 	var activeRiders int64
-	if err := r.db.Model(&model.Rider{}).Where("is_active = ?", true).Count(&activeRiders).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Rider{}).Where("is_active = ?", true).Count(&activeRiders).Error; err != nil {
 		return nil, err
 	}
 	stats["active_riders"] = activeRiders
 
 	// 在线配送员数
 	var onlineRiders int64
-	if err := r.db.Model(&model.Rider{}).Where("is_online = ?", true).Count(&onlineRiders).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Rider{}).Where("is_online = ?", true).Count(&onlineRiders).Error; err != nil {
 		return nil, err
 	}
 	stats["online_riders"] = onlineRiders
 
 	// 各交通工具类型统计
 	var vehicleStats []map[string]interface{}
-	if err := r.db.Model(&model.Rider{}).
+	if err := r.conn(ctx).Model(&model.Rider{}).
 		Select("vehicle_type, COUNT(*) as count").
 		Group("vehicle_type").
 		Scan(&vehicleStats).Error; err != nil {
@@ -492,7 +652,7 @@ func (r *RiderRepository) GetRiderStats() (map[string]interface{}, error) {
 
 	// 今日新增配送员数
 	var todayAdded int64
-	if err := r.db.Model(&model.Rider{}).Where("DATE(created_at) = CURDATE()").Count(&todayAdded).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.Rider{}).Where("DATE(created_at) = CURDATE()").Count(&todayAdded).Error; err != nil {
 		return nil, err
 	}
 	stats["today_added"] = todayAdded
@@ -501,13 +661,13 @@ func (r *RiderRepository) GetRiderStats() (map[string]interface{}, error) {
 }
 
 // GetTopRidersByRating 获取评分最高的配送员
-func (r *RiderRepository) GetTopRidersByRating(limit int) ([]*model.Rider, error) {
+func (r *RiderRepository) GetTopRidersByRating(ctx context.Context, limit int) ([]*model.Rider, error) {
 	if limit <= 0 {
 		return nil, ErrLimitInvalid
 	}
 
 	var riders []*model.Rider
-	if err := r.db.Where("is_active = ?", true).
+	if err := r.conn(ctx).Where("is_active = ?", true).
 		Order("rating DESC, total_orders DESC").
 		Limit(limit).Find(&riders).Error; err != nil {
 		return nil, err
@@ -517,13 +677,13 @@ func (r *RiderRepository) GetTopRidersByRating(limit int) ([]*model.Rider, error
 }
 
 // GetRidersByOrderCount 根据订单数量范围获取配送员
-func (r *RiderRepository) GetRidersByOrderCount(minOrders, maxOrders int64) ([]*model.Rider, error) {
+func (r *RiderRepository) GetRidersByOrderCount(ctx context.Context, minOrders, maxOrders int64) ([]*model.Rider, error) {
 	if minOrders < 0 || maxOrders < minOrders {
 		return nil, ErrOrderCountRangeInvalid
 	}
 
 	var riders []*model.Rider
-	if err := r.db.Where("total_orders BETWEEN ? AND ?", minOrders, maxOrders).
+	if err := r.conn(ctx).Where("total_orders BETWEEN ? AND ?", minOrders, maxOrders).
 		Order("total_orders DESC").Find(&riders).Error; err != nil {
 		return nil, err
 	}
@@ -533,10 +693,50 @@ func (r *RiderRepository) GetRidersByOrderCount(minOrders, maxOrders int64) ([]*
 
 // #endregion
 
+// #region 订单统计
+
+// IncrementTotalOrders 完成配送后累加订单数（原子自增，避免并发覆盖）
+func (r *RiderRepository) IncrementTotalOrders(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return ErrRiderIDInvalid
+	}
+
+	result := r.conn(ctx).Model(&model.Rider{}).Where("id = ?", id).
+		UpdateColumn("total_orders", gorm.Expr("total_orders + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRiderNotFound
+	}
+	return nil
+}
+
+// ApplyRating 计入一次评分，按评分次数滚动计算平均分
+func (r *RiderRepository) ApplyRating(ctx context.Context, id int64, rating float32) error {
+	if id <= 0 {
+		return ErrRiderIDInvalid
+	}
+
+	result := r.conn(ctx).Model(&model.Rider{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"rating":       gorm.Expr("(rating * rating_count + ?) / (rating_count + 1)", rating),
+		"rating_count": gorm.Expr("rating_count + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRiderNotFound
+	}
+	return nil
+}
+
+// #endregion
+
 // #region 工具方法
 
 // GetRiderStatsByVehicleType 根据交通工具类型获取配送员统计
-func (r *RiderRepository) GetRiderStatsByVehicleType() (map[string]int64, error) {
+func (r *RiderRepository) GetRiderStatsByVehicleType(ctx context.Context) (map[string]int64, error) {
 	stats := make(map[string]int64)
 
 	var results []struct {
@@ -544,7 +744,7 @@ func (r *RiderRepository) GetRiderStatsByVehicleType() (map[string]int64, error)
 		Count       int64  `gorm:"column:count"`
 	}
 
-	if err := r.db.Model(&model.Rider{}).
+	if err := r.conn(ctx).Model(&model.Rider{}).
 		Select("vehicle_type, COUNT(*) as count").
 		Group("vehicle_type").
 		Scan(&results).Error; err != nil {
@@ -559,10 +759,10 @@ func (r *RiderRepository) GetRiderStatsByVehicleType() (map[string]int64, error)
 }
 
 // GetAverageRating 获取平均评分
-func (r *RiderRepository) GetAverageRating() (float64, error) {
+func (r *RiderRepository) GetAverageRating(ctx context.Context) (float64, error) {
 	var avgRating float64
 
-	if err := r.db.Model(&model.Rider{}).
+	if err := r.conn(ctx).Model(&model.Rider{}).
 		Select("AVG(rating)").
 		Where("total_orders > 0").
 		Scan(&avgRating).Error; err != nil {
@@ -573,13 +773,13 @@ func (r *RiderRepository) GetAverageRating() (float64, error) {
 }
 
 // GetMostExperiencedRiders 获取最有经验的配送员（订单数最多）
-func (r *RiderRepository) GetMostExperiencedRiders(limit int) ([]*model.Rider, error) {
+func (r *RiderRepository) GetMostExperiencedRiders(ctx context.Context, limit int) ([]*model.Rider, error) {
 	if limit <= 0 {
 		return nil, ErrLimitInvalid
 	}
 
 	var riders []*model.Rider
-	if err := r.db.Where("is_active = ?", true).
+	if err := r.conn(ctx).Where("is_active = ?", true).
 		Order("total_orders DESC, rating DESC").
 		Limit(limit).Find(&riders).Error; err != nil {
 		return nil, err
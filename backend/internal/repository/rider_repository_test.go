@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Hermitf/the-pass/internal/model"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newMockDB 基于 sqlmock 的 gorm 连接，测试结束时校验所有预期的 SQL 均已执行
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet sql expectations: %v", err)
+		}
+		_ = sqlDB.Close()
+	})
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return db, mock
+}
+
+func riderRows(riders ...model.Rider) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "username", "current_lat", "current_lng"})
+	for _, rider := range riders {
+		rows.AddRow(rider.ID, rider.Username, rider.CurrentLat, rider.CurrentLng)
+	}
+	return rows
+}
+
+func TestRiderRepository_GetRidersNearLocation(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRiderRepository(db)
+
+	const lat, lng, radiusKm = 39.9, 116.4, 5.0
+	minLat, maxLat, minLng, maxLng := model.BoundingBox(lat, lng, radiusKm)
+
+	// 数据库按外接矩形粗筛后返回的候选（乱序）：矩形角上的点距离超过半径，需被剔除
+	mock.ExpectQuery(`SELECT \* FROM "riders" WHERE \(is_active = \$1 AND is_online = \$2\) AND \(current_lat BETWEEN \$3 AND \$4\) AND \(current_lng BETWEEN \$5 AND \$6\)`).
+		WithArgs(true, true, minLat, maxLat, minLng, maxLng).
+		WillReturnRows(riderRows(
+			model.Rider{ID: 1, Username: "far", CurrentLat: lat + 0.03, CurrentLng: lng},
+			model.Rider{ID: 2, Username: "corner", CurrentLat: maxLat - 0.001, CurrentLng: maxLng - 0.001},
+			model.Rider{ID: 3, Username: "near", CurrentLat: lat + 0.001, CurrentLng: lng},
+			model.Rider{ID: 4, Username: "mid", CurrentLat: lat, CurrentLng: lng + 0.02},
+		))
+
+	nearby, err := repo.GetRidersNearLocation(context.Background(), lat, lng, radiusKm, 2)
+	if err != nil {
+		t.Fatalf("GetRidersNearLocation: %v", err)
+	}
+	if len(nearby) != 2 || nearby[0].Rider.ID != 3 || nearby[1].Rider.ID != 4 {
+		t.Fatalf("want riders [3 4] nearest first, got %+v", nearby)
+	}
+	for _, n := range nearby {
+		if want := n.Rider.CalculateDistance(lat, lng); math.Abs(n.DistanceKm-want) > 1e-9 || n.DistanceKm > radiusKm {
+			t.Fatalf("rider %d distance=%v want %v within %v", n.Rider.ID, n.DistanceKm, want, radiusKm)
+		}
+	}
+}
+
+func TestRiderRepository_GetRidersNearLocationDropsCornerHits(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRiderRepository(db)
+
+	const lat, lng, radiusKm = 60.0, 10.0, 10.0
+	_, maxLat, _, maxLng := model.BoundingBox(lat, lng, radiusKm)
+
+	mock.ExpectQuery(`SELECT \* FROM "riders"`).
+		WillReturnRows(riderRows(model.Rider{ID: 1, CurrentLat: maxLat - 0.001, CurrentLng: maxLng - 0.001}))
+
+	nearby, err := repo.GetRidersNearLocation(context.Background(), lat, lng, radiusKm, 10)
+	if err != nil {
+		t.Fatalf("GetRidersNearLocation: %v", err)
+	}
+	if len(nearby) != 0 {
+		t.Fatalf("corner hit outside radius returned: %+v", nearby)
+	}
+}
+
+func TestRiderRepository_GetRidersNearLocationAcrossAntimeridian(t *testing.T) {
+	db, mock := newMockDB(t)
+	repo := NewRiderRepository(db)
+
+	const lat, lng, radiusKm = 0.0, 179.99, 5.0
+	_, _, minLng, maxLng := model.BoundingBox(lat, lng, radiusKm)
+	if minLng <= maxLng {
+		t.Fatalf("box should wrap across 180°, got [%v, %v]", minLng, maxLng)
+	}
+
+	// 跨越 180° 经线时按两段经度区间查询
+	mock.ExpectQuery(`current_lng >= \$5 OR current_lng <= \$6`).
+		WithArgs(true, true, sqlmock.AnyArg(), sqlmock.AnyArg(), minLng, maxLng).
+		WillReturnRows(riderRows(
+			model.Rider{ID: 1, CurrentLat: 0, CurrentLng: -179.99},
+			model.Rider{ID: 2, CurrentLat: 0, CurrentLng: 179.98},
+		))
+
+	nearby, err := repo.GetRidersNearLocation(context.Background(), lat, lng, radiusKm, 10)
+	if err != nil {
+		t.Fatalf("GetRidersNearLocation: %v", err)
+	}
+	if len(nearby) != 2 || nearby[0].Rider.ID != 2 || nearby[1].Rider.ID != 1 {
+		t.Fatalf("want riders [2 1] across the antimeridian, got %+v", nearby)
+	}
+}
+
+func TestRiderRepository_GetRidersNearLocationInvalid(t *testing.T) {
+	db, _ := newMockDB(t)
+	repo := NewRiderRepository(db)
+	ctx := context.Background()
+
+	cases := []struct {
+		name               string
+		lat, lng, radiusKm float64
+		limit              int
+		want               error
+	}{
+		{"latitude", 91, 0, 1, 10, ErrLocationInvalid},
+		{"longitude", 0, -181, 1, 10, ErrLocationInvalid},
+		{"radius", 0, 0, 0, 10, ErrRadiusInvalid},
+		{"limit", 0, 0, 1, 0, ErrLimitInvalid},
+	}
+	for _, tc := range cases {
+		if _, err := repo.GetRidersNearLocation(ctx, tc.lat, tc.lng, tc.radiusKm, tc.limit); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err=%v want %v", tc.name, err, tc.want)
+		}
+	}
+}
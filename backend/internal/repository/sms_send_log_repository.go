@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/pkg/sms"
+	"gorm.io/gorm"
+)
+
+// #region 仓库定义
+
+// maxSendLogErrorLength 失败原因最大长度（字符），与 error_message 列宽一致
+const maxSendLogErrorLength = 500
+
+// SMSSendLogRepository 短信发送日志仓库，实现 sms.SendLogStore
+type SMSSendLogRepository struct {
+	db *gorm.DB
+}
+
+// NewSMSSendLogRepository 创建短信发送日志仓库实例
+func NewSMSSendLogRepository(db *gorm.DB) sms.SendLogStore {
+	return &SMSSendLogRepository{
+		db: db,
+	}
+}
+
+// conn 解析本次调用使用的连接：ctx 中有事务时加入事务
+func (r *SMSSendLogRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+// #endregion
+
+// #region 数据访问
+
+// Create 记录一条已入队的消息
+func (r *SMSSendLogRepository) Create(ctx context.Context, record *sms.SendRecord) error {
+	if record == nil {
+		return ErrSendLogNil
+	}
+
+	return r.conn(ctx).Create(&model.SMSSendLog{
+		MessageID:    record.MessageID,
+		Phone:        record.MaskedPhone,
+		Category:     record.Category,
+		TemplateCode: record.TemplateCode,
+		Status:       sms.SendStatusQueued,
+	}).Error
+}
+
+// MarkSent 记录服务商已受理及其流水号
+func (r *SMSSendLogRepository) MarkSent(ctx context.Context, messageID string, receipt sms.Receipt, attempts int) error {
+	return r.conn(ctx).Model(&model.SMSSendLog{}).
+		Where("message_id = ?", messageID).
+		Updates(map[string]any{
+			"status":     sms.SendStatusSent,
+			"provider":   receipt.Provider,
+			"receipt_id": receipt.ReceiptID,
+			"attempts":   attempts,
+			"sent_at":    time.Now(),
+		}).Error
+}
+
+// MarkFailed 记录发送失败原因
+func (r *SMSSendLogRepository) MarkFailed(ctx context.Context, messageID string, attempts int, reason string) error {
+	return r.conn(ctx).Model(&model.SMSSendLog{}).
+		Where("message_id = ?", messageID).
+		Updates(map[string]any{
+			"status":        sms.SendStatusFailed,
+			"attempts":      attempts,
+			"error_message": truncateRunes(reason, maxSendLogErrorLength),
+		}).Error
+}
+
+// ApplyDeliveryReport 按服务商与流水号更新送达状态，返回是否找到对应消息
+func (r *SMSSendLogRepository) ApplyDeliveryReport(ctx context.Context, report sms.DeliveryReport) (bool, error) {
+	if report.ReceiptID == "" {
+		return false, nil
+	}
+
+	status := sms.SendStatusUndelivered
+	if report.Delivered {
+		status = sms.SendStatusDelivered
+	}
+	result := r.conn(ctx).Model(&model.SMSSendLog{}).
+		Where("provider = ? AND receipt_id = ?", report.Provider, report.ReceiptID).
+		Updates(map[string]any{
+			"status":        status,
+			"error_code":    report.ErrCode,
+			"error_message": truncateRunes(report.ErrMessage, maxSendLogErrorLength),
+			"delivered_at":  report.ReportedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// #endregion
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -2,9 +2,10 @@ package repository
 
 import (
 	"context"
-	"gorm.io/gorm"
 
+	"github.com/Hermitf/the-pass/internal/database"
 	"github.com/Hermitf/the-pass/internal/model"
+	"gorm.io/gorm"
 )
 
 // #region 仓库定义
@@ -12,33 +13,26 @@ import (
 // UserRepositoryInterface 用户仓库接口 - 专注于数据访问层
 type UserRepositoryInterface interface {
 	// 基础CRUD操作
-	CreateUser(user *model.User) error
-	GetUserByID(id uint) (*model.User, error)
-	UpdateUser(user *model.User) error
-	DeleteUser(id uint) error
+	CreateUser(ctx context.Context, user *model.User) error
+	GetUserByID(ctx context.Context, id uint) (*model.User, error)
+	UpdateUser(ctx context.Context, user *model.User) error
+	UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error
+	SetActive(ctx context.Context, id int64, active bool) error
+	DeleteUser(ctx context.Context, id uint) error
 
 	// 查询方法
-	GetUserByUsername(username string) (*model.User, error)
-	GetUserByEmail(email string) (*model.User, error)
-	GetUserByPhone(phone string) (*model.User, error)
-	GetUserList(offset, limit int) ([]*model.User, int64, error)
-	SearchUsers(keyword string, offset, limit int) ([]*model.User, int64, error)
+	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	GetUserByPhone(ctx context.Context, phone string) (*model.User, error)
+	GetUserList(ctx context.Context, offset, limit int) ([]*model.User, int64, error)
+	SearchUsers(ctx context.Context, keyword string, offset, limit int) ([]*model.User, int64, error)
 
 	// 数据检查方法（纯数据层）
-	ExistsWithUsername(username string) (bool, error)
-	ExistsWithEmail(email string) (bool, error)
-	ExistsWithPhone(phone string) (bool, error)
-	CountUsers() (int64, error)
-	CountUsersByStatus(status string) (int64, error)
-
-	// WithTx 在事务中执行一组仓储操作，确保原子性
-	// 典型用法：
-	//  return repo.WithTx(ctx, func(txRepo UserRepositoryInterface) error {
-	//      // 在同一事务中执行多步校验与写入
-	//      if err := txRepo.CreateUser(user); err != nil { return err }
-	//      return nil
-	//  })
-	WithTx(ctx context.Context, fn func(txRepo UserRepositoryInterface) error) error
+	ExistsWithUsername(ctx context.Context, username string) (bool, error)
+	ExistsWithEmail(ctx context.Context, email string) (bool, error)
+	ExistsWithPhone(ctx context.Context, phone string) (bool, error)
+	CountUsers(ctx context.Context) (int64, error)
+	CountUsersByStatus(ctx context.Context, status string) (int64, error)
 }
 
 // UserRepository 用户仓库实现
@@ -57,48 +51,78 @@ func NewUserRepository(db *gorm.DB) UserRepositoryInterface {
 	}
 }
 
+// conn 解析本次调用使用的连接：ctx 中有事务时加入事务
+func (r *UserRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
 // #endregion
 
 // #region 基础CRUD操作
 
 // CreateUser 创建用户
-func (r *UserRepository) CreateUser(user *model.User) error {
+func (r *UserRepository) CreateUser(ctx context.Context, user *model.User) error {
 	if user == nil {
 		return ErrUserNil
 	}
 
-	return r.db.Create(user).Error
+	return r.conn(ctx).Create(user).Error
 }
 
 // GetUserByID 根据ID获取用户
-func (r *UserRepository) GetUserByID(id uint) (*model.User, error) {
+func (r *UserRepository) GetUserByID(ctx context.Context, id uint) (*model.User, error) {
 	if id == 0 {
 		return nil, ErrUserIDZero
 	}
 
 	var user model.User
-	if err := r.db.Where("id = ?", id).First(&user).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 // UpdateUser 更新用户
-func (r *UserRepository) UpdateUser(user *model.User) error {
+func (r *UserRepository) UpdateUser(ctx context.Context, user *model.User) error {
 	if user == nil {
 		return ErrUserNil
 	}
 
-	return r.db.Save(user).Error
+	return r.conn(ctx).Save(user).Error
+}
+
+// UpdatePasswordHash 只更新密码哈希，避免整行保存覆盖并发修改的其他字段
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error {
+	if id <= 0 {
+		return ErrUserIDZero
+	}
+
+	return r.conn(ctx).Model(&model.User{}).Where("id = ?", id).Update("password_hash", passwordHash).Error
+}
+
+// SetActive 只更新激活状态，记录不存在时返回 ErrUserNotFound
+func (r *UserRepository) SetActive(ctx context.Context, id int64, active bool) error {
+	if id <= 0 {
+		return ErrUserIDZero
+	}
+
+	result := r.conn(ctx).Model(&model.User{}).Where("id = ?", id).Update("is_active", active)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
 }
 
 // DeleteUser 删除用户（软删除）
-func (r *UserRepository) DeleteUser(id uint) error {
+func (r *UserRepository) DeleteUser(ctx context.Context, id uint) error {
 	if id == 0 {
 		return ErrUserIDZero
 	}
 
-	return r.db.Delete(&model.User{}, id).Error
+	return r.conn(ctx).Delete(&model.User{}, id).Error
 }
 
 // #endregion
@@ -106,39 +130,39 @@ func (r *UserRepository) DeleteUser(id uint) error {
 // #region 查询方法
 
 // GetUserByUsername 根据用户名获取用户
-func (r *UserRepository) GetUserByUsername(username string) (*model.User, error) {
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
 	if username == "" {
 		return nil, ErrUsernameEmpty
 	}
 
 	var user model.User
-	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.conn(ctx).Where("username = ?", username).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 // GetUserByEmail 根据邮箱获取用户
-func (r *UserRepository) GetUserByEmail(email string) (*model.User, error) {
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
 	if email == "" {
 		return nil, ErrEmailEmpty
 	}
 
 	var user model.User
-	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.conn(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 // GetUserByPhone 根据手机号获取用户
-func (r *UserRepository) GetUserByPhone(phone string) (*model.User, error) {
+func (r *UserRepository) GetUserByPhone(ctx context.Context, phone string) (*model.User, error) {
 	if phone == "" {
 		return nil, ErrPhoneEmpty
 	}
 
 	var user model.User
-	if err := r.db.Where("phone = ?", phone).First(&user).Error; err != nil {
+	if err := r.conn(ctx).Where("phone = ?", phone).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -149,7 +173,7 @@ func (r *UserRepository) GetUserByPhone(phone string) (*model.User, error) {
 // #region 数据查询方法
 
 // GetUserList 分页获取用户列表
-func (r *UserRepository) GetUserList(offset, limit int) ([]*model.User, int64, error) {
+func (r *UserRepository) GetUserList(ctx context.Context, offset, limit int) ([]*model.User, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationInvalid
 	}
@@ -158,12 +182,12 @@ func (r *UserRepository) GetUserList(offset, limit int) ([]*model.User, int64, e
 	var total int64
 
 	// 获取总数
-	if err := r.db.Model(&model.User{}).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.User{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 分页查询
-	if err := r.db.Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+	if err := r.conn(ctx).Offset(offset).Limit(limit).Find(&users).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -171,9 +195,9 @@ func (r *UserRepository) GetUserList(offset, limit int) ([]*model.User, int64, e
 }
 
 // SearchUsers 搜索用户
-func (r *UserRepository) SearchUsers(keyword string, offset, limit int) ([]*model.User, int64, error) {
+func (r *UserRepository) SearchUsers(ctx context.Context, keyword string, offset, limit int) ([]*model.User, int64, error) {
 	if keyword == "" {
-		return r.GetUserList(offset, limit)
+		return r.GetUserList(ctx, offset, limit)
 	}
 
 	if offset < 0 || limit <= 0 {
@@ -184,9 +208,9 @@ func (r *UserRepository) SearchUsers(keyword string, offset, limit int) ([]*mode
 	var total int64
 
 	searchPattern := "%" + keyword + "%"
-	query := r.db.Model(&model.User{}).Where(
-		"username LIKE ? OR email LIKE ? OR phone LIKE ? OR name LIKE ?",
-		searchPattern, searchPattern, searchPattern, searchPattern,
+	query := r.conn(ctx).Model(&model.User{}).Where(
+		"username LIKE ? OR email LIKE ? OR phone LIKE ?",
+		searchPattern, searchPattern, searchPattern,
 	)
 
 	// 获取搜索结果总数
@@ -207,13 +231,13 @@ func (r *UserRepository) SearchUsers(keyword string, offset, limit int) ([]*mode
 // #region 数据检查方法
 
 // ExistsWithUsername 检查用户名是否存在
-func (r *UserRepository) ExistsWithUsername(username string) (bool, error) {
+func (r *UserRepository) ExistsWithUsername(ctx context.Context, username string) (bool, error) {
 	if username == "" {
 		return false, ErrUsernameEmpty
 	}
 
 	var count int64
-	if err := r.db.Model(&model.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
 		return false, err
 	}
 
@@ -221,13 +245,13 @@ func (r *UserRepository) ExistsWithUsername(username string) (bool, error) {
 }
 
 // ExistsWithEmail 检查邮箱是否存在
-func (r *UserRepository) ExistsWithEmail(email string) (bool, error) {
+func (r *UserRepository) ExistsWithEmail(ctx context.Context, email string) (bool, error) {
 	if email == "" {
 		return false, ErrEmailEmpty
 	}
 
 	var count int64
-	if err := r.db.Model(&model.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
 		return false, err
 	}
 
@@ -235,13 +259,13 @@ func (r *UserRepository) ExistsWithEmail(email string) (bool, error) {
 }
 
 // ExistsWithPhone 检查手机号是否存在
-func (r *UserRepository) ExistsWithPhone(phone string) (bool, error) {
+func (r *UserRepository) ExistsWithPhone(ctx context.Context, phone string) (bool, error) {
 	if phone == "" {
 		return false, ErrPhoneEmpty
 	}
 
 	var count int64
-	if err := r.db.Model(&model.User{}).Where("phone = ?", phone).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.User{}).Where("phone = ?", phone).Count(&count).Error; err != nil {
 		return false, err
 	}
 
@@ -249,21 +273,28 @@ func (r *UserRepository) ExistsWithPhone(phone string) (bool, error) {
 }
 
 // CountUsers 获取用户总数
-func (r *UserRepository) CountUsers() (int64, error) {
+func (r *UserRepository) CountUsers(ctx context.Context) (int64, error) {
 	var count int64
-	if err := r.db.Model(&model.User{}).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(&model.User{}).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
 // CountUsersByStatus 根据状态统计用户数
-func (r *UserRepository) CountUsersByStatus(status string) (int64, error) {
+// users 表没有 status 列，"active" / "inactive" 映射到 is_active，空字符串统计全部
+func (r *UserRepository) CountUsersByStatus(ctx context.Context, status string) (int64, error) {
 	var count int64
-	query := r.db.Model(&model.User{})
-
-	if status != "" {
-		query = query.Where("status = ?", status)
+	query := r.conn(ctx).Model(&model.User{})
+
+	switch status {
+	case "":
+	case "active":
+		query = query.Where("is_active = ?", true)
+	case "inactive":
+		query = query.Where("is_active = ?", false)
+	default:
+		return 0, ErrUserStatusInvalid
 	}
 
 	if err := query.Count(&count).Error; err != nil {
@@ -273,15 +304,3 @@ func (r *UserRepository) CountUsersByStatus(status string) (int64, error) {
 }
 
 // #endregion
-
-// #region 事务支持
-
-// WithTx 使用 GORM 事务封装一组仓储操作
-func (r *UserRepository) WithTx(ctx context.Context, fn func(txRepo UserRepositoryInterface) error) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		txRepo := &UserRepository{db: tx}
-		return fn(txRepo)
-	})
-}
-
-// #endregion
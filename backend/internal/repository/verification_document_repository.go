@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"gorm.io/gorm"
+)
+
+// #region 仓库定义
+
+// VerificationDocumentRepositoryInterface 资质审核材料仓库接口
+// 所有查询都以 ownerType + ownerID 限定范围，避免越权读取其他账号的材料
+type VerificationDocumentRepositoryInterface interface {
+	Create(ctx context.Context, doc *model.VerificationDocument) error
+	GetByID(ctx context.Context, ownerType string, ownerID, id int64) (*model.VerificationDocument, error)
+	ListByOwner(ctx context.Context, ownerType string, ownerID int64) ([]*model.VerificationDocument, error)
+	Delete(ctx context.Context, ownerType string, ownerID, id int64) error
+	CountByDocType(ctx context.Context, ownerType string, ownerID int64, docType string) (int64, error)
+}
+
+// VerificationDocumentRepository 资质审核材料仓库实现
+type VerificationDocumentRepository struct {
+	db *gorm.DB
+}
+
+// NewVerificationDocumentRepository 创建资质审核材料仓库实例
+func NewVerificationDocumentRepository(db *gorm.DB) VerificationDocumentRepositoryInterface {
+	return &VerificationDocumentRepository{
+		db: db,
+	}
+}
+
+// conn 解析本次调用使用的连接：ctx 中有事务时加入事务
+func (r *VerificationDocumentRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+// #endregion
+
+// #region 数据访问
+
+// Create 保存材料记录
+func (r *VerificationDocumentRepository) Create(ctx context.Context, doc *model.VerificationDocument) error {
+	if doc == nil {
+		return ErrVerificationDocumentNil
+	}
+
+	return r.conn(ctx).Create(doc).Error
+}
+
+// GetByID 获取账号名下的一份材料
+func (r *VerificationDocumentRepository) GetByID(ctx context.Context, ownerType string, ownerID, id int64) (*model.VerificationDocument, error) {
+	if id <= 0 {
+		return nil, ErrVerificationDocumentNotFound
+	}
+
+	var doc model.VerificationDocument
+	err := r.conn(ctx).
+		Where("id = ? AND owner_type = ? AND owner_id = ?", id, ownerType, ownerID).
+		First(&doc).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVerificationDocumentNotFound
+		}
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// ListByOwner 获取账号名下的全部材料，按上传时间升序
+func (r *VerificationDocumentRepository) ListByOwner(ctx context.Context, ownerType string, ownerID int64) ([]*model.VerificationDocument, error) {
+	var docs []*model.VerificationDocument
+	err := r.conn(ctx).
+		Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Order("id ASC").
+		Find(&docs).Error
+	if err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// Delete 删除账号名下的一份材料记录
+func (r *VerificationDocumentRepository) Delete(ctx context.Context, ownerType string, ownerID, id int64) error {
+	result := r.conn(ctx).
+		Where("id = ? AND owner_type = ? AND owner_id = ?", id, ownerType, ownerID).
+		Delete(&model.VerificationDocument{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVerificationDocumentNotFound
+	}
+	return nil
+}
+
+// CountByDocType 统计账号名下某类材料的数量
+func (r *VerificationDocumentRepository) CountByDocType(ctx context.Context, ownerType string, ownerID int64, docType string) (int64, error) {
+	var count int64
+	err := r.conn(ctx).Model(&model.VerificationDocument{}).
+		Where("owner_type = ? AND owner_id = ? AND doc_type = ?", ownerType, ownerID, docType).
+		Count(&count).Error
+	return count, err
+}
+
+// #endregion
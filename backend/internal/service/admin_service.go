@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/crypto"
+	"github.com/Hermitf/the-pass/pkg/sms"
+	"github.com/Hermitf/the-pass/pkg/validator"
+)
+
+// #region 服务定义
+
+// AdminActor 发起后台操作的管理员，写入审计日志
+type AdminActor struct {
+	AdminID  int64
+	ClientIP string
+}
+
+// AdminServiceInterface 管理员（运营后台）服务接口
+type AdminServiceInterface interface {
+	// 管理员账号
+	CreateAdmin(ctx context.Context, admin *model.Admin) error
+	LoginAdmin(ctx context.Context, username, password, clientIP string) (*TokenPair, error)
+	GetAdminByID(ctx context.Context, id int64) (*model.Admin, error)
+
+	// 账号管理：启用/停用用户、商家、配送员、员工，并写入审计日志
+	SetAccountActive(ctx context.Context, actor AdminActor, accountType string, accountID int64, active bool, reason string) error
+
+	// 短信号码名单：黑名单号码禁止接收验证码，白名单号码跳过发送限额；变更写入审计日志
+	ListSMSPhones(ctx context.Context, list string) ([]sms.PhoneListEntry, error)
+	SetSMSPhoneListed(ctx context.Context, actor AdminActor, list, phone string, listed bool, reason string) error
+
+	// 审计日志
+	ListAuditLogs(ctx context.Context, filter repository.AuditLogFilter, offset, limit int) ([]*model.AuditLog, int64, error)
+}
+
+// AdminService 管理员服务实现
+type AdminService struct {
+	adminRepo    repository.AdminRepositoryInterface
+	auditRepo    repository.AuditLogRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	merchantRepo repository.MerchantRepositoryInterface
+	riderRepo    repository.RiderRepositoryInterface
+	employeeRepo repository.EmployeeRepositoryInterface
+	jwtService   JWTServiceInterface
+	loginGuard   *LoginGuard
+	txManager    database.TxManager
+
+	phoneLists sms.PhoneListStore
+}
+
+// #endregion
+
+// #region 构造函数和依赖注入
+
+// AdminServiceDependencies 管理员服务依赖
+type AdminServiceDependencies struct {
+	AdminRepo    repository.AdminRepositoryInterface
+	AuditRepo    repository.AuditLogRepositoryInterface
+	UserRepo     repository.UserRepositoryInterface
+	MerchantRepo repository.MerchantRepositoryInterface
+	RiderRepo    repository.RiderRepositoryInterface
+	EmployeeRepo repository.EmployeeRepositoryInterface
+	JWTService   JWTServiceInterface
+	LoginGuard   *LoginGuard
+	TxManager    database.TxManager
+
+	// PhoneLists 短信号码黑白名单，SMS 未启用时为 nil
+	PhoneLists sms.PhoneListStore
+}
+
+// NewAdminService 创建管理员服务实例
+func NewAdminService(deps AdminServiceDependencies) AdminServiceInterface {
+	return &AdminService{
+		adminRepo:    deps.AdminRepo,
+		auditRepo:    deps.AuditRepo,
+		userRepo:     deps.UserRepo,
+		merchantRepo: deps.MerchantRepo,
+		riderRepo:    deps.RiderRepo,
+		employeeRepo: deps.EmployeeRepo,
+		jwtService:   deps.JWTService,
+		loginGuard:   deps.LoginGuard,
+		txManager:    deps.TxManager,
+
+		phoneLists: deps.PhoneLists,
+	}
+}
+
+// #endregion
+
+// #region 管理员账号
+
+// CreateAdmin 创建管理员，admin.PasswordHash 传入明文密码，入库前加密
+func (s *AdminService) CreateAdmin(ctx context.Context, admin *model.Admin) error {
+	if admin == nil {
+		return ErrAdminNil
+	}
+	admin.Username = strings.TrimSpace(admin.Username)
+	if admin.Username == "" {
+		return fmt.Errorf("%w: 用户名不能为空", ErrValidationFailed)
+	}
+	if err := crypto.ValidatePassword(admin.PasswordHash); err != nil {
+		return fmt.Errorf("%w: %v", ErrPasswordTooWeak, err)
+	}
+
+	exists, err := s.adminRepo.ExistsWithUsername(ctx, admin.Username)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCheckAvailability, err)
+	}
+	if exists {
+		return ErrUsernameAlreadyExists
+	}
+
+	hashedPassword, err := crypto.HashPassword(admin.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPasswordHashing, err)
+	}
+	admin.PasswordHash = hashedPassword
+	admin.IsActive = true
+
+	if err := s.adminRepo.Create(ctx, admin); err != nil {
+		return fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
+	}
+
+	log.Printf("管理员创建成功 - 管理员ID: %d, 用户名: %s, 时间: %s",
+		admin.ID, admin.Username, time.Now().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// LoginAdmin 管理员登录，仅支持用户名 + 密码
+func (s *AdminService) LoginAdmin(ctx context.Context, username, password, clientIP string) (*TokenPair, error) {
+	if username == "" || password == "" {
+		return nil, ErrLoginInfoEmpty
+	}
+	if err := s.loginGuard.Check(ctx, "admin", username, clientIP); err != nil {
+		return nil, err
+	}
+
+	admin, err := s.adminRepo.GetByUsername(ctx, username)
+	if err != nil {
+		s.loginGuard.RecordFailure(ctx, "admin", username, clientIP)
+		// 不区分账号不存在与密码错误，避免枚举后台账号
+		return nil, ErrInvalidCredentials
+	}
+	if err := crypto.VerifyPassword(admin.PasswordHash, password); err != nil {
+		s.loginGuard.RecordFailure(ctx, "admin", username, clientIP)
+		return nil, ErrInvalidCredentials
+	}
+	s.loginGuard.RecordSuccess(ctx, "admin", username, clientIP)
+
+	if !admin.IsActive {
+		return nil, ErrAccountDeactivated
+	}
+
+	tokens, err := s.jwtService.IssueTokenPair(ctx, admin.ID, model.AuditActorAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+	}
+
+	if err := s.adminRepo.UpdateLastLogin(ctx, admin.ID, time.Now()); err != nil {
+		log.Printf("管理员最近登录时间更新失败 - 管理员ID: %d, 错误: %v", admin.ID, err)
+	}
+	log.Printf("管理员登录成功 - 管理员ID: %d, 用户名: %s, IP: %s, 时间: %s",
+		admin.ID, admin.Username, clientIP, time.Now().Format("2006-01-02 15:04:05"))
+	return tokens, nil
+}
+
+// GetAdminByID 根据ID获取管理员
+func (s *AdminService) GetAdminByID(ctx context.Context, id int64) (*model.Admin, error) {
+	if id <= 0 {
+		return nil, ErrInvalidAdminID
+	}
+
+	admin, err := s.adminRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrAdminNotFound) {
+			return nil, ErrAdminNotFound
+		}
+		return nil, err
+	}
+	return admin, nil
+}
+
+// #endregion
+
+// #region 账号管理
+
+// SetAccountActive 启用或停用账号
+//
+// 状态更新与审计日志在同一事务中写入，审计失败则状态不变；
+// 停用成功后吊销该账号全部会话，已签发的令牌立即失效。
+func (s *AdminService) SetAccountActive(ctx context.Context, actor AdminActor, accountType string, accountID int64, active bool, reason string) error {
+	if actor.AdminID <= 0 {
+		return ErrInvalidAdminID
+	}
+	if accountID <= 0 {
+		return ErrInvalidAccountID
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrAuditReasonRequired
+	}
+	if utf8.RuneCountInString(reason) > model.MaxAuditReasonLength {
+		return ErrAuditReasonTooLong
+	}
+	setActive, err := s.accountActivator(accountType)
+	if err != nil {
+		return err
+	}
+
+	action := model.AuditActionAccountActivate
+	if !active {
+		action = model.AuditActionAccountDeactivate
+	}
+	detail, _ := json.Marshal(map[string]bool{"is_active": active})
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := setActive(ctx, accountID, active); err != nil {
+			return err
+		}
+		return s.auditRepo.Create(ctx, &model.AuditLog{
+			ActorType:  model.AuditActorAdmin,
+			ActorID:    actor.AdminID,
+			Action:     action,
+			TargetType: accountType,
+			TargetID:   accountID,
+			Reason:     reason,
+			Detail:     string(detail),
+			ClientIP:   actor.ClientIP,
+		})
+	})
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return ErrAccountNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+	}
+
+	// 停用已提交但会话仍有效时返回错误，由管理员重试；重复停用是幂等的
+	if !active {
+		if err := s.jwtService.LogoutAll(ctx, accountID, accountType); err != nil {
+			log.Printf("停用账号后吊销会话失败 - 类型: %s, ID: %d, 错误: %v", accountType, accountID, err)
+			return fmt.Errorf("%w: %v", ErrAccountSessionsNotRevoked, err)
+		}
+	}
+
+	log.Printf("管理员操作 - 管理员ID: %d, 操作: %s, 对象: %s/%d, 原因: %s, 时间: %s",
+		actor.AdminID, action, accountType, accountID, reason, time.Now().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// accountActivator 按账号类型选择对应仓储的状态更新方法
+func (s *AdminService) accountActivator(accountType string) (func(ctx context.Context, id int64, active bool) error, error) {
+	switch accountType {
+	case model.AccountTypeUser:
+		return s.userRepo.SetActive, nil
+	case model.AccountTypeMerchant:
+		return s.merchantRepo.SetActive, nil
+	case model.AccountTypeRider:
+		return s.riderRepo.SetActive, nil
+	case model.AccountTypeEmployee:
+		return s.employeeRepo.SetActive, nil
+	default:
+		return nil, ErrInvalidAccountType
+	}
+}
+
+// #endregion
+
+// #region 短信号码名单
+
+// ListSMSPhones 列出黑名单或白名单中的号码
+func (s *AdminService) ListSMSPhones(ctx context.Context, list string) ([]sms.PhoneListEntry, error) {
+	if s.phoneLists == nil {
+		return nil, ErrSMSPhoneListUnavailable
+	}
+	phoneList, err := sms.ParsePhoneList(list)
+	if err != nil {
+		return nil, err
+	}
+	return s.phoneLists.ListPhones(ctx, phoneList)
+}
+
+// SetSMSPhoneListed 将号码加入或移出名单
+//
+// 名单存放在 Redis 中，无法与审计日志共用数据库事务：审计日志先在事务内写入，
+// 名单变更失败时随事务回滚，保证有审计记录的变更一定生效。
+func (s *AdminService) SetSMSPhoneListed(ctx context.Context, actor AdminActor, list, phone string, listed bool, reason string) error {
+	if actor.AdminID <= 0 {
+		return ErrInvalidAdminID
+	}
+	if s.phoneLists == nil {
+		return ErrSMSPhoneListUnavailable
+	}
+	phoneList, err := sms.ParsePhoneList(list)
+	if err != nil {
+		return err
+	}
+	if !validator.IsPhone(phone) {
+		return ErrPhoneInvalid
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrAuditReasonRequired
+	}
+	if utf8.RuneCountInString(reason) > model.MaxAuditReasonLength {
+		return ErrAuditReasonTooLong
+	}
+
+	action := model.AuditActionSMSPhoneListAdd
+	if !listed {
+		action = model.AuditActionSMSPhoneListRemove
+	}
+	detail, _ := json.Marshal(map[string]string{"list": string(phoneList), "phone": phone})
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.auditRepo.Create(ctx, &model.AuditLog{
+			ActorType:  model.AuditActorAdmin,
+			ActorID:    actor.AdminID,
+			Action:     action,
+			TargetType: model.AuditTargetSMSPhone,
+			Reason:     reason,
+			Detail:     string(detail),
+			ClientIP:   actor.ClientIP,
+		}); err != nil {
+			return err
+		}
+		if listed {
+			return s.phoneLists.AddPhone(ctx, phoneList, phone, reason)
+		}
+		removed, err := s.phoneLists.RemovePhone(ctx, phoneList, phone)
+		if err == nil && !removed {
+			return ErrSMSPhoneNotListed
+		}
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrSMSPhoneNotListed) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+	}
+
+	log.Printf("管理员操作 - 管理员ID: %d, 操作: %s, 名单: %s, 号码: %s, 原因: %s, 时间: %s",
+		actor.AdminID, action, phoneList, phone, reason, time.Now().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// #endregion
+
+// #region 审计日志
+
+// ListAuditLogs 分页查询审计日志
+func (s *AdminService) ListAuditLogs(ctx context.Context, filter repository.AuditLogFilter, offset, limit int) ([]*model.AuditLog, int64, error) {
+	if offset < 0 || limit <= 0 || limit > 100 {
+		return nil, 0, ErrPaginationInvalid
+	}
+
+	return s.auditRepo.List(ctx, filter, offset, limit)
+}
+
+// #endregion
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/sms"
+)
+
+type activeUserRepo struct {
+	repository.UserRepositoryInterface
+	active map[int64]bool
+}
+
+func (f *activeUserRepo) SetActive(ctx context.Context, id int64, active bool) error {
+	if _, ok := f.active[id]; !ok {
+		return repository.ErrUserNotFound
+	}
+	f.active[id] = active
+	return nil
+}
+
+type fakeAuditLogRepo struct {
+	repository.AuditLogRepositoryInterface
+	entries []*model.AuditLog
+	err     error
+}
+
+func (f *fakeAuditLogRepo) Create(ctx context.Context, entry *model.AuditLog) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+// snapshotTxManager 模拟事务：fn 出错时恢复用户激活状态
+type snapshotTxManager struct {
+	users *activeUserRepo
+}
+
+func (m snapshotTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := make(map[int64]bool, len(m.users.active))
+	for id, active := range m.users.active {
+		snapshot[id] = active
+	}
+	if err := fn(ctx); err != nil {
+		m.users.active = snapshot
+		return err
+	}
+	return nil
+}
+
+type recordingLogoutAll struct {
+	JWTServiceInterface
+	loggedOut []string
+	err       error
+}
+
+func (f *recordingLogoutAll) LogoutAll(ctx context.Context, userID int64, userType string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.loggedOut = append(f.loggedOut, userType)
+	return nil
+}
+
+func TestAdminService_SetAccountActive(t *testing.T) {
+	users := &activeUserRepo{active: map[int64]bool{7: true}}
+	audit := &fakeAuditLogRepo{}
+	jwt := &recordingLogoutAll{}
+	svc := NewAdminService(AdminServiceDependencies{
+		AuditRepo:  audit,
+		UserRepo:   users,
+		JWTService: jwt,
+		TxManager:  snapshotTxManager{users: users},
+	})
+	ctx := context.Background()
+	actor := AdminActor{AdminID: 1, ClientIP: "10.0.0.1"}
+
+	if err := svc.SetAccountActive(ctx, actor, model.AccountTypeUser, 7, false, "  "); !errors.Is(err, ErrAuditReasonRequired) {
+		t.Fatalf("blank reason err=%v want ErrAuditReasonRequired", err)
+	}
+	if err := svc.SetAccountActive(ctx, actor, "admin", 7, false, "x"); !errors.Is(err, ErrInvalidAccountType) {
+		t.Fatalf("admin target err=%v want ErrInvalidAccountType", err)
+	}
+	if err := svc.SetAccountActive(ctx, actor, model.AccountTypeUser, 8, false, "spam"); !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("unknown user err=%v want ErrAccountNotFound", err)
+	}
+
+	if err := svc.SetAccountActive(ctx, actor, model.AccountTypeUser, 7, false, "spam"); err != nil {
+		t.Fatalf("SetAccountActive: %v", err)
+	}
+	if users.active[7] {
+		t.Fatal("user should be deactivated")
+	}
+	if len(audit.entries) != 1 {
+		t.Fatalf("audit entries=%d want 1", len(audit.entries))
+	}
+	entry := audit.entries[0]
+	if entry.ActorID != 1 || entry.Action != model.AuditActionAccountDeactivate ||
+		entry.TargetType != model.AccountTypeUser || entry.TargetID != 7 || entry.Reason != "spam" || entry.ClientIP != "10.0.0.1" {
+		t.Fatalf("unexpected audit entry %+v", entry)
+	}
+	if len(jwt.loggedOut) != 1 || jwt.loggedOut[0] != model.AccountTypeUser {
+		t.Fatalf("deactivation should revoke sessions, got %v", jwt.loggedOut)
+	}
+
+	// 审计写入失败时状态更新一并回滚
+	audit.err = errors.New("db down")
+	if err := svc.SetAccountActive(ctx, actor, model.AccountTypeUser, 7, true, "appeal accepted"); !errors.Is(err, ErrDataUpdateFailed) {
+		t.Fatalf("audit failure err=%v want ErrDataUpdateFailed", err)
+	}
+	if users.active[7] {
+		t.Fatal("status change must roll back when the audit log cannot be written")
+	}
+
+	// 会话吊销失败不能被吞掉：返回错误让管理员重试，重试停用仍然成功
+	audit.err = nil
+	jwt.err = ErrTokenRevocation
+	if err := svc.SetAccountActive(ctx, actor, model.AccountTypeUser, 7, false, "spam"); !errors.Is(err, ErrAccountSessionsNotRevoked) {
+		t.Fatalf("revoke failure err=%v want ErrAccountSessionsNotRevoked", err)
+	}
+	jwt.err = nil
+	if err := svc.SetAccountActive(ctx, actor, model.AccountTypeUser, 7, false, "spam"); err != nil {
+		t.Fatalf("retry SetAccountActive: %v", err)
+	}
+	if len(jwt.loggedOut) != 2 {
+		t.Fatalf("retry should revoke sessions, got %v", jwt.loggedOut)
+	}
+}
+
+type fakePhoneLists struct {
+	lists map[sms.PhoneList]map[string]string
+	err   error
+}
+
+func (f *fakePhoneLists) AddPhone(ctx context.Context, list sms.PhoneList, phone, reason string) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.lists[list] == nil {
+		f.lists[list] = map[string]string{}
+	}
+	f.lists[list][phone] = reason
+	return nil
+}
+
+func (f *fakePhoneLists) RemovePhone(ctx context.Context, list sms.PhoneList, phone string) (bool, error) {
+	if _, ok := f.lists[list][phone]; !ok {
+		return false, f.err
+	}
+	delete(f.lists[list], phone)
+	return true, f.err
+}
+
+func (f *fakePhoneLists) ListPhones(ctx context.Context, list sms.PhoneList) ([]sms.PhoneListEntry, error) {
+	var entries []sms.PhoneListEntry
+	for phone, reason := range f.lists[list] {
+		entries = append(entries, sms.PhoneListEntry{Phone: phone, Reason: reason})
+	}
+	return entries, f.err
+}
+
+// auditTxManager 模拟事务：fn 出错时丢弃本次写入的审计日志
+type auditTxManager struct {
+	audit *fakeAuditLogRepo
+}
+
+func (m auditTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	n := len(m.audit.entries)
+	if err := fn(ctx); err != nil {
+		m.audit.entries = m.audit.entries[:n]
+		return err
+	}
+	return nil
+}
+
+func TestAdminService_SetSMSPhoneListed(t *testing.T) {
+	audit := &fakeAuditLogRepo{}
+	lists := &fakePhoneLists{lists: map[sms.PhoneList]map[string]string{}}
+	svc := NewAdminService(AdminServiceDependencies{
+		AuditRepo:  audit,
+		TxManager:  auditTxManager{audit: audit},
+		PhoneLists: lists,
+	})
+	ctx := context.Background()
+	actor := AdminActor{AdminID: 1, ClientIP: "10.0.0.1"}
+
+	if err := svc.SetSMSPhoneListed(ctx, actor, "greylist", "13800138000", true, "x"); !errors.Is(err, sms.ErrPhoneListInvalid) {
+		t.Fatalf("unknown list err=%v want ErrPhoneListInvalid", err)
+	}
+	if err := svc.SetSMSPhoneListed(ctx, actor, "blocklist", "12345", true, "x"); !errors.Is(err, ErrPhoneInvalid) {
+		t.Fatalf("invalid phone err=%v want ErrPhoneInvalid", err)
+	}
+	if err := svc.SetSMSPhoneListed(ctx, actor, "blocklist", "13800138000", false, "x"); !errors.Is(err, ErrSMSPhoneNotListed) {
+		t.Fatalf("remove unlisted err=%v want ErrSMSPhoneNotListed", err)
+	}
+	if len(audit.entries) != 0 {
+		t.Fatalf("rejected changes must not be audited, got %+v", audit.entries)
+	}
+
+	if err := svc.SetSMSPhoneListed(ctx, actor, "blocklist", "13800138000", true, "接码平台"); err != nil {
+		t.Fatalf("SetSMSPhoneListed: %v", err)
+	}
+	entries, err := svc.ListSMSPhones(ctx, "blocklist")
+	if err != nil || len(entries) != 1 || entries[0].Phone != "13800138000" || entries[0].Reason != "接码平台" {
+		t.Fatalf("ListSMSPhones: entries=%+v err=%v", entries, err)
+	}
+	if len(audit.entries) != 1 || audit.entries[0].Action != model.AuditActionSMSPhoneListAdd ||
+		audit.entries[0].TargetType != model.AuditTargetSMSPhone || audit.entries[0].ActorID != 1 {
+		t.Fatalf("unexpected audit entries %+v", audit.entries)
+	}
+
+	// 名单写入失败时审计日志随事务回滚
+	lists.err = errors.New("redis down")
+	if err := svc.SetSMSPhoneListed(ctx, actor, "allowlist", "13800138001", true, "测试号"); !errors.Is(err, ErrDataUpdateFailed) {
+		t.Fatalf("store failure err=%v want ErrDataUpdateFailed", err)
+	}
+	if len(audit.entries) != 1 {
+		t.Fatalf("audit entry must roll back with the failed change, got %d", len(audit.entries))
+	}
+
+	disabled := NewAdminService(AdminServiceDependencies{})
+	if _, err := disabled.ListSMSPhones(ctx, "blocklist"); !errors.Is(err, ErrSMSPhoneListUnavailable) {
+		t.Fatalf("sms disabled err=%v want ErrSMSPhoneListUnavailable", err)
+	}
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+)
+
+// #region 服务定义
+
+// CatalogServiceInterface 商品目录服务接口
+type CatalogServiceInterface interface {
+	// 分类管理（商家）
+	CreateCategory(ctx context.Context, merchantID int64, category *model.MenuCategory) error
+	UpdateCategory(ctx context.Context, merchantID int64, category *model.MenuCategory) error
+	DeleteCategory(ctx context.Context, merchantID, categoryID int64) error
+	ListCategories(ctx context.Context, merchantID int64) ([]*model.MenuCategory, error)
+
+	// 商品管理（商家）
+	CreateItem(ctx context.Context, merchantID int64, item *model.MenuItem) error
+	UpdateItem(ctx context.Context, merchantID int64, item *model.MenuItem) error
+	DeleteItem(ctx context.Context, merchantID, itemID int64) error
+	GetItem(ctx context.Context, merchantID, itemID int64) (*model.MenuItem, error)
+	ListItems(ctx context.Context, merchantID, categoryID int64) ([]*model.MenuItem, error)
+	SetItemStock(ctx context.Context, merchantID, itemID int64, stock *int) error
+
+	// SetItemAvailability 上下架商品；商家本人或其在职员工均可操作
+	SetItemAvailability(ctx context.Context, userType string, userID, itemID int64, available bool) (*model.MenuItem, error)
+	// ResolveMerchantID 解析操作方所属商家（商家本人或在职员工）
+	ResolveMerchantID(ctx context.Context, userType string, userID int64) (int64, error)
+
+	// GetPublicMenu 用户查看商家菜单
+	GetPublicMenu(ctx context.Context, merchantID int64) ([]*model.MenuCategory, error)
+}
+
+// CatalogService 商品目录服务实现
+type CatalogService struct {
+	catalogRepo  repository.CatalogRepositoryInterface
+	merchantRepo repository.MerchantRepositoryInterface
+	employeeRepo repository.EmployeeRepositoryInterface
+}
+
+// #endregion
+
+// #region 构造函数和依赖注入
+
+// CatalogServiceDependencies 商品目录服务依赖
+type CatalogServiceDependencies struct {
+	CatalogRepo  repository.CatalogRepositoryInterface
+	MerchantRepo repository.MerchantRepositoryInterface
+	EmployeeRepo repository.EmployeeRepositoryInterface
+}
+
+// NewCatalogService 创建商品目录服务实例
+func NewCatalogService(deps CatalogServiceDependencies) CatalogServiceInterface {
+	return &CatalogService{
+		catalogRepo:  deps.CatalogRepo,
+		merchantRepo: deps.MerchantRepo,
+		employeeRepo: deps.EmployeeRepo,
+	}
+}
+
+// #endregion
+
+// #region 分类管理
+
+// CreateCategory 创建分类
+func (s *CatalogService) CreateCategory(ctx context.Context, merchantID int64, category *model.MenuCategory) error {
+	if category == nil {
+		return ErrCategoryNil
+	}
+
+	category.ID = 0
+	category.MerchantID = merchantID
+	if err := category.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+	}
+
+	if err := s.catalogRepo.CreateCategory(ctx, category); err != nil {
+		return fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
+	}
+	return nil
+}
+
+// UpdateCategory 更新分类
+func (s *CatalogService) UpdateCategory(ctx context.Context, merchantID int64, category *model.MenuCategory) error {
+	if category == nil {
+		return ErrCategoryNil
+	}
+
+	category.MerchantID = merchantID
+	if err := category.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+	}
+
+	if err := s.catalogRepo.UpdateCategory(ctx, category); err != nil {
+		return wrapCatalogError(err, ErrDataUpdateFailed)
+	}
+	return nil
+}
+
+// DeleteCategory 删除分类；分类下仍有商品时拒绝删除
+func (s *CatalogService) DeleteCategory(ctx context.Context, merchantID, categoryID int64) error {
+	count, err := s.catalogRepo.CountItemsInCategory(ctx, merchantID, categoryID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+	}
+	if count > 0 {
+		return ErrCategoryNotEmpty
+	}
+
+	if err := s.catalogRepo.DeleteCategory(ctx, merchantID, categoryID); err != nil {
+		return wrapCatalogError(err, ErrDataUpdateFailed)
+	}
+	return nil
+}
+
+// ListCategories 获取商家分类列表
+func (s *CatalogService) ListCategories(ctx context.Context, merchantID int64) ([]*model.MenuCategory, error) {
+	return s.catalogRepo.ListCategories(ctx, merchantID)
+}
+
+// #endregion
+
+// #region 商品管理
+
+// CreateItem 创建商品，分类必须属于同一商家
+func (s *CatalogService) CreateItem(ctx context.Context, merchantID int64, item *model.MenuItem) error {
+	if item == nil {
+		return ErrMenuItemNil
+	}
+
+	item.ID = 0
+	item.MerchantID = merchantID
+	if err := s.validateItem(ctx, item); err != nil {
+		return err
+	}
+
+	if err := s.catalogRepo.CreateItem(ctx, item); err != nil {
+		return fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
+	}
+
+	s.logItemChanged(merchantID, item.ID, "创建")
+	return nil
+}
+
+// UpdateItem 更新商品（图片与规格组整体替换）
+func (s *CatalogService) UpdateItem(ctx context.Context, merchantID int64, item *model.MenuItem) error {
+	if item == nil {
+		return ErrMenuItemNil
+	}
+
+	item.MerchantID = merchantID
+	if err := s.validateItem(ctx, item); err != nil {
+		return err
+	}
+
+	if err := s.catalogRepo.UpdateItem(ctx, item); err != nil {
+		return wrapCatalogError(err, ErrDataUpdateFailed)
+	}
+
+	s.logItemChanged(merchantID, item.ID, "更新")
+	return nil
+}
+
+// DeleteItem 删除商品
+func (s *CatalogService) DeleteItem(ctx context.Context, merchantID, itemID int64) error {
+	if err := s.catalogRepo.DeleteItem(ctx, merchantID, itemID); err != nil {
+		return wrapCatalogError(err, ErrDataUpdateFailed)
+	}
+
+	s.logItemChanged(merchantID, itemID, "删除")
+	return nil
+}
+
+// GetItem 获取商品详情
+func (s *CatalogService) GetItem(ctx context.Context, merchantID, itemID int64) (*model.MenuItem, error) {
+	item, err := s.catalogRepo.GetItem(ctx, merchantID, itemID)
+	if err != nil {
+		return nil, wrapCatalogError(err, ErrMenuItemNotFound)
+	}
+	return item, nil
+}
+
+// ListItems 获取商品列表
+func (s *CatalogService) ListItems(ctx context.Context, merchantID, categoryID int64) ([]*model.MenuItem, error) {
+	return s.catalogRepo.ListItems(ctx, merchantID, categoryID)
+}
+
+// SetItemStock 设置库存；stock 为空表示不限量
+func (s *CatalogService) SetItemStock(ctx context.Context, merchantID, itemID int64, stock *int) error {
+	if stock != nil && *stock < 0 {
+		return fmt.Errorf("%w: %v", ErrValidationFailed, model.ErrInvalidStock)
+	}
+	if err := s.catalogRepo.SetItemStock(ctx, merchantID, itemID, stock); err != nil {
+		return wrapCatalogError(err, ErrDataUpdateFailed)
+	}
+	return nil
+}
+
+// SetItemAvailability 上下架商品
+func (s *CatalogService) SetItemAvailability(ctx context.Context, userType string, userID, itemID int64, available bool) (*model.MenuItem, error) {
+	merchantID, err := s.ResolveMerchantID(ctx, userType, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.catalogRepo.SetItemAvailability(ctx, merchantID, itemID, available); err != nil {
+		return nil, wrapCatalogError(err, ErrDataUpdateFailed)
+	}
+
+	s.logAvailabilityChanged(merchantID, itemID, available, userType, userID)
+	return s.GetItem(ctx, merchantID, itemID)
+}
+
+// ResolveMerchantID 解析操作方所属商家
+func (s *CatalogService) ResolveMerchantID(ctx context.Context, userType string, userID int64) (int64, error) {
+	switch userType {
+	case "merchant":
+		return userID, nil
+	case "employee":
+		employee, err := s.employeeRepo.GetByID(ctx, userID)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrEmployeeNotFound, err)
+		}
+		if !employee.IsActive {
+			return 0, ErrCatalogAccessDenied
+		}
+		return employee.MerchantID, nil
+	default:
+		return 0, ErrCatalogAccessDenied
+	}
+}
+
+// #endregion
+
+// #region 公开菜单
+
+// GetPublicMenu 获取商家菜单；商家未激活或未通过资质审核时视为不存在
+func (s *CatalogService) GetPublicMenu(ctx context.Context, merchantID int64) ([]*model.MenuCategory, error) {
+	merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
+	if err != nil || !merchant.CanTrade() {
+		return nil, ErrMerchantNotFound
+	}
+	return s.catalogRepo.GetMenu(ctx, merchantID)
+}
+
+// #endregion
+
+// #region 辅助方法
+
+// validateItem 校验商品数据及分类归属
+func (s *CatalogService) validateItem(ctx context.Context, item *model.MenuItem) error {
+	if err := item.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+	}
+	if _, err := s.catalogRepo.GetCategory(ctx, item.MerchantID, item.CategoryID); err != nil {
+		return wrapCatalogError(err, ErrCategoryNotFound)
+	}
+	return nil
+}
+
+// wrapCatalogError 将仓储层的"不存在"错误转换为服务层错误，其余错误以 fallback 包装
+func wrapCatalogError(err, fallback error) error {
+	switch {
+	case errors.Is(err, repository.ErrCategoryNotFound):
+		return ErrCategoryNotFound
+	case errors.Is(err, repository.ErrMenuItemNotFound):
+		return ErrMenuItemNotFound
+	default:
+		return fmt.Errorf("%w: %v", fallback, err)
+	}
+}
+
+// #endregion
+
+// #region 日志记录
+
+// logItemChanged 记录商品变更日志
+func (s *CatalogService) logItemChanged(merchantID, itemID int64, action string) {
+	log.Printf("商品%s - 商家ID: %d, 商品ID: %d, 时间: %s",
+		action, merchantID, itemID, time.Now().Format("2006-01-02 15:04:05"))
+}
+
+// logAvailabilityChanged 记录商品上下架日志
+func (s *CatalogService) logAvailabilityChanged(merchantID, itemID int64, available bool, userType string, userID int64) {
+	log.Printf("商品上下架 - 商家ID: %d, 商品ID: %d, 可售: %t, 操作方: %s(%d), 时间: %s",
+		merchantID, itemID, available, userType, userID, time.Now().Format("2006-01-02 15:04:05"))
+}
+
+// #endregion
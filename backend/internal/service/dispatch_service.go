@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/config"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/clock"
+)
+
+// #region 服务定义
+
+// 派单默认参数（配置项为零值时使用）
+const (
+	defaultDispatchRadiusKm        = 5.0
+	defaultDispatchAcceptTimeout   = 30 * time.Second
+	defaultDispatchMaxCandidates   = 10
+	defaultDispatchMaxActiveOrders = 3
+
+	// dispatchCandidatePool 派单时从附近查询的配送员数量，按负载过滤后再截取 MaxCandidates 个
+	dispatchCandidatePool = 100
+)
+
+// 候选配送员评分权重：得分越低越优先
+const (
+	dispatchDistanceWeight = 0.6
+	dispatchRatingWeight   = 0.25
+	dispatchLoadWeight     = 0.15
+)
+
+// NearbyRiderFinder 查询半径内可接单的配送员（按距离升序），由 RiderService 实现
+type NearbyRiderFinder interface {
+	GetAvailableRiders(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error)
+}
+
+// Dispatcher 订单派单入口，供订单服务在出餐后触发
+type Dispatcher interface {
+	Dispatch(ctx context.Context, orderID int64) error
+}
+
+// DispatchOffer 向配送员发出的派单邀约
+type DispatchOffer struct {
+	OrderID    int64     `json:"order_id"`
+	RiderID    int64     `json:"rider_id"`
+	DistanceKm float64   `json:"distance_km"`
+	OfferedAt  time.Time `json:"offered_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// DispatchServiceInterface 派单服务接口
+type DispatchServiceInterface interface {
+	Dispatcher
+
+	// 配送员响应
+	AcceptOffer(ctx context.Context, riderID, orderID int64) (*model.Order, error)
+	DeclineOffer(ctx context.Context, riderID, orderID int64) error
+	PendingOffers(riderID int64) []DispatchOffer
+}
+
+// DispatchService 派单服务实现
+// 进行中的派单保存在进程内存中，仅适用于单实例部署；多实例部署需将派单状态迁移到共享存储
+type DispatchService struct {
+	orderRepo   repository.OrderRepositoryInterface
+	riderFinder NearbyRiderFinder
+	clock       clock.Clock
+	config      config.DispatchConfig
+
+	mu    sync.Mutex
+	tasks map[int64]*dispatchTask // 订单ID -> 派单任务
+}
+
+// dispatchCandidate 排序后的候选配送员
+type dispatchCandidate struct {
+	riderID    int64
+	distanceKm float64
+	score      float64
+}
+
+// dispatchTask 单个订单的派单进度
+type dispatchTask struct {
+	orderID    int64
+	candidates []dispatchCandidate
+	next       int
+	offer      *DispatchOffer
+	timer      clock.Timer
+
+	accepting bool // 当前邀约已被接受、指派写入中，期间不超时也不可拒绝
+}
+
+// #endregion
+
+// #region 构造函数和依赖注入
+
+// DispatchServiceDependencies 派单服务依赖
+type DispatchServiceDependencies struct {
+	OrderRepo   repository.OrderRepositoryInterface
+	RiderFinder NearbyRiderFinder
+	Clock       clock.Clock
+	Config      config.DispatchConfig
+}
+
+// NewDispatchService 创建派单服务实例
+func NewDispatchService(deps DispatchServiceDependencies) DispatchServiceInterface {
+	cfg := deps.Config
+	if cfg.SearchRadiusKm <= 0 {
+		cfg.SearchRadiusKm = defaultDispatchRadiusKm
+	}
+	if cfg.AcceptTimeout <= 0 {
+		cfg.AcceptTimeout = defaultDispatchAcceptTimeout
+	}
+	if cfg.MaxCandidates <= 0 {
+		cfg.MaxCandidates = defaultDispatchMaxCandidates
+	}
+	if cfg.MaxActiveOrders <= 0 {
+		cfg.MaxActiveOrders = defaultDispatchMaxActiveOrders
+	}
+
+	clk := deps.Clock
+	if clk == nil {
+		clk = clock.Real()
+	}
+
+	return &DispatchService{
+		orderRepo:   deps.OrderRepo,
+		riderFinder: deps.RiderFinder,
+		clock:       clk,
+		config:      cfg,
+		tasks:       make(map[int64]*dispatchTask),
+	}
+}
+
+// #endregion
+
+// #region 派单
+
+// Dispatch 为已出餐且未指派配送员的订单排序候选人，并向最优配送员发出邀约
+// 订单已在派单中时直接返回
+func (s *DispatchService) Dispatch(ctx context.Context, orderID int64) error {
+	if orderID <= 0 {
+		return ErrInvalidOrderID
+	}
+
+	s.mu.Lock()
+	_, dispatching := s.tasks[orderID]
+	s.mu.Unlock()
+	if dispatching {
+		return nil
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			return ErrOrderNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrOrderNotFound, err)
+	}
+	if order.Status != model.OrderStatusPrepared || order.RiderID != nil {
+		return ErrOrderNotDispatchable
+	}
+
+	candidates, err := s.rankCandidates(ctx, order)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return ErrNoAvailableRiders
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, dispatching := s.tasks[orderID]; dispatching {
+		return nil
+	}
+	task := &dispatchTask{orderID: orderID, candidates: candidates}
+	s.tasks[orderID] = task
+	s.offerNextLocked(task)
+	return nil
+}
+
+// rankCandidates 按球面距离、评分和当前负载为半径内的配送员排序
+// 得分 = 距离权重*距离/半径 + 评分权重*(1-评分/满分) + 负载权重*负载/负载上限，越低越优先
+func (s *DispatchService) rankCandidates(ctx context.Context, order *model.Order) ([]dispatchCandidate, error) {
+	radius := s.config.SearchRadiusKm
+	nearby, err := s.riderFinder.GetAvailableRiders(ctx, order.PickupLat, order.PickupLng, radius, dispatchCandidatePool)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoAvailableRiders, err)
+	}
+
+	riderIDs := make([]int64, 0, len(nearby))
+	for _, n := range nearby {
+		riderIDs = append(riderIDs, n.Rider.ID)
+	}
+	loads, err := s.orderRepo.CountActiveByRiders(ctx, riderIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoAvailableRiders, err)
+	}
+
+	maxLoad := float64(s.config.MaxActiveOrders)
+	candidates := make([]dispatchCandidate, 0, len(nearby))
+	for _, n := range nearby {
+		rider, distance := n.Rider, n.DistanceKm
+		load := loads[rider.ID]
+		if load >= s.config.MaxActiveOrders {
+			continue
+		}
+
+		score := dispatchDistanceWeight*distance/radius +
+			dispatchRatingWeight*(1-float64(rider.Rating)/model.MaxRiderRating) +
+			dispatchLoadWeight*float64(load)/maxLoad
+		candidates = append(candidates, dispatchCandidate{riderID: rider.ID, distanceKm: distance, score: score})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+		return candidates[i].riderID < candidates[j].riderID
+	})
+	if len(candidates) > s.config.MaxCandidates {
+		candidates = candidates[:s.config.MaxCandidates]
+	}
+	return candidates, nil
+}
+
+// offerNextLocked 向下一位候选人发出邀约；候选人耗尽时结束派单，订单等待商家重新派单
+// 调用方需持有 s.mu
+func (s *DispatchService) offerNextLocked(task *dispatchTask) {
+	task.offer, task.timer = nil, nil
+	if task.next >= len(task.candidates) {
+		delete(s.tasks, task.orderID)
+		s.logDispatchExhausted(task.orderID, len(task.candidates))
+		return
+	}
+
+	candidate := task.candidates[task.next]
+	task.next++
+
+	now := s.clock.Now()
+	offer := &DispatchOffer{
+		OrderID:    task.orderID,
+		RiderID:    candidate.riderID,
+		DistanceKm: candidate.distanceKm,
+		OfferedAt:  now,
+		ExpiresAt:  now.Add(s.config.AcceptTimeout),
+	}
+	task.offer = offer
+	task.timer = s.clock.AfterFunc(s.config.AcceptTimeout, func() {
+		s.expireOffer(offer)
+	})
+	s.logOfferSent(offer)
+}
+
+// expireOffer 邀约超时，转派下一位候选人
+func (s *DispatchService) expireOffer(offer *DispatchOffer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[offer.OrderID]
+	if !ok || task.offer != offer || task.accepting {
+		return
+	}
+	s.logOfferClosed(offer, "超时")
+	s.offerNextLocked(task)
+}
+
+// #endregion
+
+// #region 配送员响应
+
+// AcceptOffer 配送员接受邀约，订单指派给该配送员
+// 派单任务保留到指派写入成功为止；写入失败（非状态冲突）时恢复邀约，配送员可在剩余时间内重试
+func (s *DispatchService) AcceptOffer(ctx context.Context, riderID, orderID int64) (*model.Order, error) {
+	s.mu.Lock()
+	task, err := s.currentTaskLocked(riderID, orderID)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	task.timer.Stop()
+	task.accepting = true
+	offer := task.offer
+	s.mu.Unlock()
+
+	if err := s.orderRepo.AssignRider(ctx, orderID, riderID); err != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		task.accepting = false
+		if errors.Is(err, repository.ErrOrderStatusConflict) {
+			// 订单已被取消或指派，不再派单
+			delete(s.tasks, orderID)
+			return nil, ErrOrderStatusConflict
+		}
+		s.restoreOfferLocked(task)
+		return nil, fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+	}
+
+	s.mu.Lock()
+	delete(s.tasks, orderID)
+	s.mu.Unlock()
+	s.logOfferClosed(offer, "已接受")
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOrderNotFound, err)
+	}
+	return order, nil
+}
+
+// DeclineOffer 配送员拒绝邀约，立即转派下一位候选人
+func (s *DispatchService) DeclineOffer(ctx context.Context, riderID, orderID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.currentTaskLocked(riderID, orderID)
+	if err != nil {
+		return err
+	}
+	task.timer.Stop()
+	s.logOfferClosed(task.offer, "已拒绝")
+	s.offerNextLocked(task)
+	return nil
+}
+
+// PendingOffers 获取配送员当前待响应的邀约
+func (s *DispatchService) PendingOffers(riderID int64) []DispatchOffer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offers := make([]DispatchOffer, 0)
+	for _, task := range s.tasks {
+		if task.offer != nil && task.offer.RiderID == riderID {
+			offers = append(offers, *task.offer)
+		}
+	}
+	sort.Slice(offers, func(i, j int) bool { return offers[i].OfferedAt.Before(offers[j].OfferedAt) })
+	return offers
+}
+
+// currentTaskLocked 获取当前邀约对象为该配送员的派单任务，调用方需持有 s.mu
+func (s *DispatchService) currentTaskLocked(riderID, orderID int64) (*dispatchTask, error) {
+	task, ok := s.tasks[orderID]
+	if !ok || task.offer == nil || task.offer.RiderID != riderID || task.accepting {
+		return nil, ErrNoOfferForRider
+	}
+	return task, nil
+}
+
+// restoreOfferLocked 指派失败后按原过期时间恢复邀约，已过期则转派下一位候选人
+// 调用方需持有 s.mu
+func (s *DispatchService) restoreOfferLocked(task *dispatchTask) {
+	offer := task.offer
+	remaining := offer.ExpiresAt.Sub(s.clock.Now())
+	if remaining <= 0 {
+		s.logOfferClosed(offer, "超时")
+		s.offerNextLocked(task)
+		return
+	}
+	task.timer = s.clock.AfterFunc(remaining, func() {
+		s.expireOffer(offer)
+	})
+}
+
+// #endregion
+
+// #region 日志记录
+
+// logOfferSent 记录派单邀约日志
+func (s *DispatchService) logOfferSent(offer *DispatchOffer) {
+	log.Printf("派单邀约 - 订单ID: %d, 配送员ID: %d, 距离: %.2fkm, 时间: %s",
+		offer.OrderID, offer.RiderID, offer.DistanceKm, s.clock.Now().Format("2006-01-02 15:04:05"))
+}
+
+// logOfferClosed 记录邀约结束日志
+func (s *DispatchService) logOfferClosed(offer *DispatchOffer, result string) {
+	log.Printf("派单邀约%s - 订单ID: %d, 配送员ID: %d, 时间: %s",
+		result, offer.OrderID, offer.RiderID, s.clock.Now().Format("2006-01-02 15:04:05"))
+}
+
+// logDispatchExhausted 记录候选人耗尽日志
+func (s *DispatchService) logDispatchExhausted(orderID int64, candidates int) {
+	log.Printf("派单失败 - 订单ID: %d, 候选配送员: %d 人均未接单，等待商家重新派单, 时间: %s",
+		orderID, candidates, s.clock.Now().Format("2006-01-02 15:04:05"))
+}
+
+// #endregion
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/config"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/clock"
+)
+
+func (f *fakeOrderRepo) AssignRider(ctx context.Context, id, riderID int64) error {
+	if f.assignErr != nil {
+		return f.assignErr
+	}
+	order, ok := f.orders[id]
+	if !ok || order.RiderID != nil || order.Status != model.OrderStatusPrepared {
+		return repository.ErrOrderStatusConflict
+	}
+	order.RiderID = &riderID
+	f.orders[id] = order
+	return nil
+}
+
+func (f *fakeOrderRepo) CountActiveByRiders(ctx context.Context, riderIDs []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64)
+	for _, order := range f.orders {
+		if order.RiderID != nil && !order.IsFinal() {
+			counts[*order.RiderID]++
+		}
+	}
+	return counts, nil
+}
+
+// fakeRiderFinder 按真实距离过滤的附近配送员查询
+type fakeRiderFinder map[int64]*model.Rider
+
+func (f fakeRiderFinder) GetAvailableRiders(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error) {
+	var nearby []*model.NearbyRider
+	for _, rider := range f {
+		if distance := rider.CalculateDistance(lat, lng); rider.IsActive && rider.IsOnline && distance <= radiusKm {
+			nearby = append(nearby, &model.NearbyRider{Rider: rider, DistanceKm: distance})
+		}
+	}
+	return nearby, nil
+}
+
+const (
+	testPickupLat = 31.23
+	testPickupLng = 121.47
+)
+
+// newDispatchTestService 取餐点附近的配送员（得分从低到高）：
+// 1 号 1.1km 满分无负载；3 号 0.4km 满分但手上 2 单；2 号 0.5km 评分 3；
+// 4 号满载、5 号超出半径，均不参与派单
+func newDispatchTestService(t *testing.T) (DispatchServiceInterface, *fakeOrderRepo, *clock.Fake) {
+	t.Helper()
+	riders := fakeRiderFinder{
+		1: {ID: 1, IsActive: true, IsOnline: true, Rating: 5, CurrentLat: testPickupLat + 0.01, CurrentLng: testPickupLng},
+		2: {ID: 2, IsActive: true, IsOnline: true, Rating: 3, CurrentLat: testPickupLat + 0.0045, CurrentLng: testPickupLng},
+		3: {ID: 3, IsActive: true, IsOnline: true, Rating: 5, CurrentLat: testPickupLat + 0.0036, CurrentLng: testPickupLng},
+		4: {ID: 4, IsActive: true, IsOnline: true, Rating: 5, CurrentLat: testPickupLat, CurrentLng: testPickupLng},
+		5: {ID: 5, IsActive: true, IsOnline: true, Rating: 5, CurrentLat: testPickupLat + 0.1, CurrentLng: testPickupLng},
+	}
+	orderRepo := &fakeOrderRepo{orders: map[int64]model.Order{
+		100: {ID: 100, Status: model.OrderStatusPrepared, PickupLat: testPickupLat, PickupLng: testPickupLng},
+	}}
+
+	// 手上订单：3 号 2 单，4 号 3 单（达到上限）
+	busy := map[int64]int{3: 2, 4: 3}
+	nextID := int64(200)
+	for riderID, n := range busy {
+		for i := 0; i < n; i++ {
+			id := riderID
+			orderRepo.orders[nextID] = model.Order{ID: nextID, Status: model.OrderStatusPickedUp, RiderID: &id}
+			nextID++
+		}
+	}
+
+	fake := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	svc := NewDispatchService(DispatchServiceDependencies{
+		OrderRepo:   orderRepo,
+		RiderFinder: riders,
+		Clock:       fake,
+		Config:      config.DispatchConfig{AcceptTimeout: 30 * time.Second},
+	})
+	return svc, orderRepo, fake
+}
+
+// assertOfferedTo 断言订单当前只派给了指定配送员
+func assertOfferedTo(t *testing.T, svc DispatchServiceInterface, riderID int64) {
+	t.Helper()
+	for id := int64(1); id <= 5; id++ {
+		offers := svc.PendingOffers(id)
+		if id == riderID {
+			if len(offers) != 1 || offers[0].OrderID != 100 {
+				t.Fatalf("rider %d offers=%+v want order 100", id, offers)
+			}
+		} else if len(offers) != 0 {
+			t.Fatalf("rider %d unexpectedly has offers %+v", id, offers)
+		}
+	}
+}
+
+func TestDispatchService_RanksAndFallsBack(t *testing.T) {
+	svc, orderRepo, fake := newDispatchTestService(t)
+	ctx := context.Background()
+
+	if err := svc.Dispatch(ctx, 100); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	assertOfferedTo(t, svc, 1)
+	if offer := svc.PendingOffers(1)[0]; !offer.ExpiresAt.Equal(fake.Now().Add(30 * time.Second)) {
+		t.Fatalf("unexpected expiry %v", offer.ExpiresAt)
+	}
+
+	// 重复派单不会重置进度
+	if err := svc.Dispatch(ctx, 100); err != nil {
+		t.Fatalf("repeat Dispatch: %v", err)
+	}
+	assertOfferedTo(t, svc, 1)
+
+	// 超时转派下一位
+	fake.Advance(29 * time.Second)
+	assertOfferedTo(t, svc, 1)
+	fake.Advance(time.Second)
+	assertOfferedTo(t, svc, 3)
+
+	// 过期的邀约不能再接受
+	if _, err := svc.AcceptOffer(ctx, 1, 100); !errors.Is(err, ErrNoOfferForRider) {
+		t.Fatalf("expired accept err=%v want ErrNoOfferForRider", err)
+	}
+
+	// 拒绝后立即转派，且旧定时器不再生效
+	if err := svc.DeclineOffer(ctx, 3, 100); err != nil {
+		t.Fatalf("DeclineOffer: %v", err)
+	}
+	assertOfferedTo(t, svc, 2)
+	if fake.PendingTimers() != 1 {
+		t.Fatalf("pending timers=%d want 1", fake.PendingTimers())
+	}
+
+	order, err := svc.AcceptOffer(ctx, 2, 100)
+	if err != nil {
+		t.Fatalf("AcceptOffer: %v", err)
+	}
+	if order.RiderID == nil || *order.RiderID != 2 || *orderRepo.orders[100].RiderID != 2 {
+		t.Fatalf("order not assigned to rider 2: %+v", order)
+	}
+	if fake.PendingTimers() != 0 {
+		t.Fatalf("pending timers=%d want 0", fake.PendingTimers())
+	}
+	assertOfferedTo(t, svc, 0)
+
+	// 已指派的订单不能再派
+	if err := svc.Dispatch(ctx, 100); !errors.Is(err, ErrOrderNotDispatchable) {
+		t.Fatalf("assigned Dispatch err=%v want ErrOrderNotDispatchable", err)
+	}
+}
+
+func TestDispatchService_ExhaustsCandidates(t *testing.T) {
+	svc, orderRepo, fake := newDispatchTestService(t)
+	ctx := context.Background()
+
+	if err := svc.Dispatch(ctx, 100); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if err := svc.DeclineOffer(ctx, 2, 100); !errors.Is(err, ErrNoOfferForRider) {
+		t.Fatalf("decline by non-offered rider err=%v want ErrNoOfferForRider", err)
+	}
+
+	// 三位候选人依次超时后结束派单，订单保持未指派
+	fake.Advance(90 * time.Second)
+	assertOfferedTo(t, svc, 0)
+	if fake.PendingTimers() != 0 || orderRepo.orders[100].RiderID != nil {
+		t.Fatalf("dispatch not finished: timers=%d order=%+v", fake.PendingTimers(), orderRepo.orders[100])
+	}
+
+	// 结束后可重新派单
+	if err := svc.Dispatch(ctx, 100); err != nil {
+		t.Fatalf("redispatch: %v", err)
+	}
+	assertOfferedTo(t, svc, 1)
+}
+
+func TestDispatchService_AcceptRestoresOfferWhenAssignFails(t *testing.T) {
+	svc, orderRepo, fake := newDispatchTestService(t)
+	ctx := context.Background()
+
+	if err := svc.Dispatch(ctx, 100); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	fake.Advance(10 * time.Second)
+
+	// 写入失败：邀约仍归该配送员，并按原过期时间继续计时
+	orderRepo.assignErr = errors.New("db down")
+	if _, err := svc.AcceptOffer(ctx, 1, 100); !errors.Is(err, ErrDataUpdateFailed) {
+		t.Fatalf("AcceptOffer err=%v want ErrDataUpdateFailed", err)
+	}
+	assertOfferedTo(t, svc, 1)
+	if fake.PendingTimers() != 1 {
+		t.Fatalf("pending timers=%d want 1", fake.PendingTimers())
+	}
+
+	// 重复派单不会另起任务
+	if err := svc.Dispatch(ctx, 100); err != nil {
+		t.Fatalf("repeat Dispatch: %v", err)
+	}
+	assertOfferedTo(t, svc, 1)
+
+	orderRepo.assignErr = nil
+	order, err := svc.AcceptOffer(ctx, 1, 100)
+	if err != nil {
+		t.Fatalf("retry AcceptOffer: %v", err)
+	}
+	if order.RiderID == nil || *order.RiderID != 1 {
+		t.Fatalf("order not assigned to rider 1: %+v", order)
+	}
+	assertOfferedTo(t, svc, 0)
+	if err := svc.Dispatch(ctx, 100); !errors.Is(err, ErrOrderNotDispatchable) {
+		t.Fatalf("assigned Dispatch err=%v want ErrOrderNotDispatchable", err)
+	}
+}
+
+func TestDispatchService_RestoredOfferExpires(t *testing.T) {
+	svc, orderRepo, fake := newDispatchTestService(t)
+	ctx := context.Background()
+
+	if err := svc.Dispatch(ctx, 100); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	fake.Advance(10 * time.Second)
+	orderRepo.assignErr = errors.New("db down")
+	if _, err := svc.AcceptOffer(ctx, 1, 100); !errors.Is(err, ErrDataUpdateFailed) {
+		t.Fatalf("AcceptOffer err=%v want ErrDataUpdateFailed", err)
+	}
+
+	fake.Advance(20 * time.Second)
+	assertOfferedTo(t, svc, 3)
+}
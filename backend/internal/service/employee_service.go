@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -17,35 +18,47 @@ const logTimeLayout = "2006-01-02 15:04:05"
 // EmployeeServiceInterface 员工服务接口
 type EmployeeServiceInterface interface {
 	// 员工注册和认证
-	RegisterEmployee(employee *model.Employee) error
-	LoginEmployee(loginInfo, password, loginType string) (string, error)
+	RegisterEmployee(ctx context.Context, employee *model.Employee) error
+	LoginEmployee(ctx context.Context, loginInfo, password, loginType, clientIP string) (*TokenPair, error)
 
 	// 员工信息管理
-	GetEmployeeByID(id int64) (*model.Employee, error)
-	UpdateEmployeeProfile(employeeID int64, name, email, phone string) error
-	UpdateEmployeePassword(employeeID int64, oldPassword, newPassword string) error
+	GetEmployeeByID(ctx context.Context, id int64) (*model.Employee, error)
+	UpdateEmployeeProfile(ctx context.Context, employeeID int64, name, email, phone string) error
+	UpdateEmployeePassword(ctx context.Context, employeeID int64, oldPassword, newPassword string) error
 
 	// 商家关联管理
-	GetEmployeesByMerchantID(merchantID int64) ([]*model.Employee, error)
-	GetActiveEmployeesByMerchant(merchantID int64) ([]*model.Employee, error)
-	TransferEmployee(employeeID, newMerchantID int64) error
+	GetEmployeesByMerchantID(ctx context.Context, merchantID int64) ([]*model.Employee, error)
+	GetActiveEmployeesByMerchant(ctx context.Context, merchantID int64) ([]*model.Employee, error)
 
 	// 员工验证
 	ValidateEmployeeData(employee *model.Employee) error
-	CheckEmployeeAvailability(username, email, phone string) error
+	CheckEmployeeAvailability(ctx context.Context, username, email, phone string) error
 
 	// 员工列表和搜索
-	GetEmployeeList(merchantID int64, offset, limit int) ([]*model.Employee, int64, error)
-	SearchEmployees(keyword string, merchantID int64, offset, limit int) ([]*model.Employee, int64, error)
+	GetEmployeeList(ctx context.Context, merchantID int64, offset, limit int) ([]*model.Employee, int64, error)
+	SearchEmployees(ctx context.Context, keyword string, merchantID int64, offset, limit int) ([]*model.Employee, int64, error)
 
 	// 员工统计
-	GetEmployeeStatsByMerchant(merchantID int64) (map[string]interface{}, error)
+	GetEmployeeStatsByMerchant(ctx context.Context, merchantID int64) (map[string]interface{}, error)
+
+	// 角色与权限
+	CheckPermission(ctx context.Context, employeeID int64, permission string) (int64, bool, error)
+	SetEmployeeRole(ctx context.Context, operator StaffOperator, employeeID int64, role string) (*model.Employee, error)
+}
+
+// StaffOperator 员工管理的操作方
+// 商家账号本人操作时 EmployeeID 为 0；员工操作时须已通过 employee.manage 权限校验
+type StaffOperator struct {
+	MerchantID int64
+	EmployeeID int64
 }
 
 // EmployeeService 员工服务实现
 type EmployeeService struct {
 	employeeRepo repository.EmployeeRepositoryInterface
+	merchantRepo repository.MerchantRepositoryInterface
 	jwtService   JWTServiceInterface
+	loginGuard   *LoginGuard
 }
 
 // #endregion
@@ -55,14 +68,18 @@ type EmployeeService struct {
 // EmployeeServiceDependencies 员工服务依赖
 type EmployeeServiceDependencies struct {
 	EmployeeRepo repository.EmployeeRepositoryInterface
+	MerchantRepo repository.MerchantRepositoryInterface
 	JWTService   JWTServiceInterface
+	LoginGuard   *LoginGuard
 }
 
 // NewEmployeeService 创建员工服务实例
 func NewEmployeeService(deps EmployeeServiceDependencies) EmployeeServiceInterface {
 	return &EmployeeService{
 		employeeRepo: deps.EmployeeRepo,
+		merchantRepo: deps.MerchantRepo,
 		jwtService:   deps.JWTService,
+		loginGuard:   deps.LoginGuard,
 	}
 }
 
@@ -71,7 +88,7 @@ func NewEmployeeService(deps EmployeeServiceDependencies) EmployeeServiceInterfa
 // #region 员工注册和认证
 
 // RegisterEmployee 注册员工
-func (s *EmployeeService) RegisterEmployee(employee *model.Employee) error {
+func (s *EmployeeService) RegisterEmployee(ctx context.Context, employee *model.Employee) error {
 	if employee == nil {
 		return ErrEmployeeNil
 	}
@@ -82,7 +99,7 @@ func (s *EmployeeService) RegisterEmployee(employee *model.Employee) error {
 	}
 
 	// 检查员工是否已存在
-	if err := s.CheckEmployeeAvailability(employee.Username, employee.Email, employee.Phone); err != nil {
+	if err := s.CheckEmployeeAvailability(ctx, employee.Username, employee.Email, employee.Phone); err != nil {
 		return fmt.Errorf("%w: %v", ErrAvailabilityCheck, err)
 	}
 
@@ -94,9 +111,12 @@ func (s *EmployeeService) RegisterEmployee(employee *model.Employee) error {
 		}
 		employee.PasswordHash = hashedPassword
 	}
+	if employee.Role == "" {
+		employee.Role = model.DefaultEmployeeRole
+	}
 
 	// 创建员工
-	if err := s.employeeRepo.Create(employee); err != nil {
+	if err := s.employeeRepo.Create(ctx, employee); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
 	}
 
@@ -105,35 +125,41 @@ func (s *EmployeeService) RegisterEmployee(employee *model.Employee) error {
 }
 
 // LoginEmployee 员工登录
-func (s *EmployeeService) LoginEmployee(loginInfo, password, loginType string) (string, error) {
+func (s *EmployeeService) LoginEmployee(ctx context.Context, loginInfo, password, loginType, clientIP string) (*TokenPair, error) {
 	if loginInfo == "" || password == "" {
-		return "", ErrLoginInfoEmpty
+		return nil, ErrLoginInfoEmpty
+	}
+	if err := s.loginGuard.Check(ctx, "employee", loginInfo, clientIP); err != nil {
+		return nil, err
 	}
 
 	// 根据登录类型获取员工信息
-	employee, err := s.getEmployeeByLoginInfo(loginInfo, loginType)
+	employee, err := s.getEmployeeByLoginInfo(ctx, loginInfo, loginType)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrEmployeeNotFound, err)
+		s.loginGuard.RecordFailure(ctx, "employee", loginInfo, clientIP)
+		return nil, fmt.Errorf("%w: %v", ErrEmployeeNotFound, err)
 	}
 
 	// 验证密码
 	if err := crypto.VerifyPassword(employee.PasswordHash, password); err != nil {
-		return "", ErrInvalidCredentials
+		s.loginGuard.RecordFailure(ctx, "employee", loginInfo, clientIP)
+		return nil, ErrInvalidCredentials
 	}
+	s.loginGuard.RecordSuccess(ctx, "employee", loginInfo, clientIP)
 
 	// 检查员工状态
 	if !employee.IsActive {
-		return "", ErrAccountDeactivated
+		return nil, ErrAccountDeactivated
 	}
 
 	// 生成JWT令牌
-	token, err := s.jwtService.GenerateToken(employee.ID, "employee")
+	tokens, err := s.jwtService.IssueTokenPair(ctx, employee.ID, "employee")
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+		return nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
 	}
 
 	s.logEmployeeLogin(employee, loginType)
-	return token, nil
+	return tokens, nil
 }
 
 // #endregion
@@ -141,18 +167,18 @@ func (s *EmployeeService) LoginEmployee(loginInfo, password, loginType string) (
 // #region 员工信息管理
 
 // GetEmployeeByID 根据ID获取员工信息
-func (s *EmployeeService) GetEmployeeByID(id int64) (*model.Employee, error) {
-	return s.fetchEmployeeByID(id)
+func (s *EmployeeService) GetEmployeeByID(ctx context.Context, id int64) (*model.Employee, error) {
+	return s.fetchEmployeeByID(ctx, id)
 }
 
 // UpdateEmployeeProfile 更新员工档案
-func (s *EmployeeService) UpdateEmployeeProfile(employeeID int64, name, email, phone string) error {
+func (s *EmployeeService) UpdateEmployeeProfile(ctx context.Context, employeeID int64, name, email, phone string) error {
 	if employeeID <= 0 {
 		return ErrInvalidEmployeeID
 	}
 
 	// 获取员工信息
-	employee, err := s.fetchEmployeeByID(employeeID)
+	employee, err := s.fetchEmployeeByID(ctx, employeeID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrEmployeeNotFound, err)
 	}
@@ -163,7 +189,7 @@ func (s *EmployeeService) UpdateEmployeeProfile(employeeID int64, name, email, p
 	}
 
 	// 检查重复数据（排除当前员工）
-	if err := s.checkEmployeeAvailabilityExcluding(employeeID, "", email, phone); err != nil {
+	if err := s.checkEmployeeAvailabilityExcluding(ctx, employeeID, "", email, phone); err != nil {
 		return err
 	}
 
@@ -172,7 +198,7 @@ func (s *EmployeeService) UpdateEmployeeProfile(employeeID int64, name, email, p
 	employee.Email = email
 	employee.Phone = phone
 
-	if err := s.employeeRepo.Update(employee); err != nil {
+	if err := s.employeeRepo.Update(ctx, employee); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
 	}
 
@@ -181,7 +207,7 @@ func (s *EmployeeService) UpdateEmployeeProfile(employeeID int64, name, email, p
 }
 
 // UpdateEmployeePassword 更新员工密码
-func (s *EmployeeService) UpdateEmployeePassword(employeeID int64, oldPassword, newPassword string) error {
+func (s *EmployeeService) UpdateEmployeePassword(ctx context.Context, employeeID int64, oldPassword, newPassword string) error {
 	if employeeID <= 0 {
 		return ErrInvalidEmployeeID
 	}
@@ -190,7 +216,7 @@ func (s *EmployeeService) UpdateEmployeePassword(employeeID int64, oldPassword,
 	}
 
 	// 获取员工信息
-	employee, err := s.fetchEmployeeByID(employeeID)
+	employee, err := s.fetchEmployeeByID(ctx, employeeID)
 	if err != nil {
 		return err
 	}
@@ -208,7 +234,7 @@ func (s *EmployeeService) UpdateEmployeePassword(employeeID int64, oldPassword,
 
 	// 更新密码
 	employee.PasswordHash = hashedPassword
-	if err := s.employeeRepo.Update(employee); err != nil {
+	if err := s.employeeRepo.Update(ctx, employee); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
 	}
 
@@ -221,12 +247,12 @@ func (s *EmployeeService) UpdateEmployeePassword(employeeID int64, oldPassword,
 // #region 商家关联管理
 
 // GetEmployeesByMerchantID 根据商家ID获取员工列表
-func (s *EmployeeService) GetEmployeesByMerchantID(merchantID int64) ([]*model.Employee, error) {
+func (s *EmployeeService) GetEmployeesByMerchantID(ctx context.Context, merchantID int64) ([]*model.Employee, error) {
 	if merchantID <= 0 {
 		return nil, ErrInvalidMerchantID
 	}
 
-	employees, err := s.employeeRepo.GetByMerchantID(merchantID)
+	employees, err := s.employeeRepo.GetByMerchantID(ctx, merchantID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrGetEmployeeList, err)
 	}
@@ -235,12 +261,12 @@ func (s *EmployeeService) GetEmployeesByMerchantID(merchantID int64) ([]*model.E
 }
 
 // GetActiveEmployeesByMerchant 获取商家的活跃员工
-func (s *EmployeeService) GetActiveEmployeesByMerchant(merchantID int64) ([]*model.Employee, error) {
+func (s *EmployeeService) GetActiveEmployeesByMerchant(ctx context.Context, merchantID int64) ([]*model.Employee, error) {
 	if merchantID <= 0 {
 		return nil, ErrInvalidMerchantID
 	}
 
-	employees, err := s.employeeRepo.GetActiveEmployeesByMerchant(merchantID)
+	employees, err := s.employeeRepo.GetActiveEmployeesByMerchant(ctx, merchantID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrGetEmployeeList, err)
 	}
@@ -248,32 +274,6 @@ func (s *EmployeeService) GetActiveEmployeesByMerchant(merchantID int64) ([]*mod
 	return employees, nil
 }
 
-// TransferEmployee 转移员工到新商家
-func (s *EmployeeService) TransferEmployee(employeeID, newMerchantID int64) error {
-	if employeeID <= 0 || newMerchantID <= 0 {
-		return ErrInvalidEmployeeID
-	}
-
-	// 获取员工信息
-	employee, err := s.fetchEmployeeByID(employeeID)
-	if err != nil {
-		return err
-	}
-
-	// 检查是否转移到相同商家
-	if employee.MerchantID == newMerchantID {
-		return ErrSameMerchantTransfer
-	}
-
-	// 执行转移
-	if err := s.employeeRepo.TransferEmployee(employeeID, newMerchantID); err != nil {
-		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
-	}
-
-	s.logEmployeeTransferred(employeeID, employee.MerchantID, newMerchantID)
-	return nil
-}
-
 // #endregion
 
 // #region 员工验证
@@ -288,8 +288,8 @@ func (s *EmployeeService) ValidateEmployeeData(employee *model.Employee) error {
 }
 
 // CheckEmployeeAvailability 检查员工可用性
-func (s *EmployeeService) CheckEmployeeAvailability(username, email, phone string) error {
-	exists, err := s.employeeRepo.CheckEmployeeExists(username, email, phone)
+func (s *EmployeeService) CheckEmployeeAvailability(ctx context.Context, username, email, phone string) error {
+	exists, err := s.employeeRepo.CheckEmployeeExists(ctx, username, email, phone)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrAvailabilityCheck, err)
 	}
@@ -306,7 +306,7 @@ func (s *EmployeeService) CheckEmployeeAvailability(username, email, phone strin
 // #region 员工列表和搜索
 
 // GetEmployeeList 获取员工列表
-func (s *EmployeeService) GetEmployeeList(merchantID int64, offset, limit int) ([]*model.Employee, int64, error) {
+func (s *EmployeeService) GetEmployeeList(ctx context.Context, merchantID int64, offset, limit int) ([]*model.Employee, int64, error) {
 	if merchantID <= 0 {
 		return nil, 0, ErrInvalidMerchantID
 	}
@@ -314,7 +314,7 @@ func (s *EmployeeService) GetEmployeeList(merchantID int64, offset, limit int) (
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	employees, total, err := s.employeeRepo.GetEmployeesByMerchantWithPagination(merchantID, offset, limit)
+	employees, total, err := s.employeeRepo.GetEmployeesByMerchantWithPagination(ctx, merchantID, offset, limit)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: %v", ErrGetEmployeeList, err)
 	}
@@ -323,7 +323,7 @@ func (s *EmployeeService) GetEmployeeList(merchantID int64, offset, limit int) (
 }
 
 // SearchEmployees 搜索员工
-func (s *EmployeeService) SearchEmployees(keyword string, merchantID int64, offset, limit int) ([]*model.Employee, int64, error) {
+func (s *EmployeeService) SearchEmployees(ctx context.Context, keyword string, merchantID int64, offset, limit int) ([]*model.Employee, int64, error) {
 	if merchantID <= 0 {
 		return nil, 0, ErrInvalidMerchantID
 	}
@@ -331,7 +331,7 @@ func (s *EmployeeService) SearchEmployees(keyword string, merchantID int64, offs
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	employees, total, err := s.employeeRepo.SearchEmployees(keyword, merchantID, offset, limit)
+	employees, total, err := s.employeeRepo.SearchEmployees(ctx, keyword, merchantID, offset, limit)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: %v", ErrSearchEmployees, err)
 	}
@@ -344,12 +344,12 @@ func (s *EmployeeService) SearchEmployees(keyword string, merchantID int64, offs
 // #region 员工统计
 
 // GetEmployeeStatsByMerchant 获取商家员工统计信息
-func (s *EmployeeService) GetEmployeeStatsByMerchant(merchantID int64) (map[string]interface{}, error) {
+func (s *EmployeeService) GetEmployeeStatsByMerchant(ctx context.Context, merchantID int64) (map[string]interface{}, error) {
 	if merchantID <= 0 {
 		return nil, ErrInvalidMerchantID
 	}
 
-	stats, err := s.employeeRepo.GetEmployeeStatsByMerchant(merchantID)
+	stats, err := s.employeeRepo.GetEmployeeStatsByMerchant(ctx, merchantID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrGetStatistics, err)
 	}
@@ -359,13 +359,82 @@ func (s *EmployeeService) GetEmployeeStatsByMerchant(merchantID int64) (map[stri
 
 // #endregion
 
+// #region 角色与权限
+
+// CheckPermission 校验员工是否在职且拥有指定权限，返回员工所属商家ID
+// 员工不存在、已停用或角色不含该权限时返回 false；所属商家已停用或未通过资质审核时同样返回 false，
+// 员工不能绕过商家侧的审核守卫。只有查询失败才返回错误
+func (s *EmployeeService) CheckPermission(ctx context.Context, employeeID int64, permission string) (int64, bool, error) {
+	if employeeID <= 0 {
+		return 0, false, nil
+	}
+
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if !employee.HasPermission(permission) {
+		return employee.MerchantID, false, nil
+	}
+
+	merchant, err := s.merchantRepo.GetByID(ctx, employee.MerchantID)
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return employee.MerchantID, false, nil
+		}
+		return 0, false, err
+	}
+	if !merchant.CanTrade() {
+		return employee.MerchantID, false, nil
+	}
+	return employee.MerchantID, true, nil
+}
+
+// SetEmployeeRole 为本店员工分配角色
+// 目标员工必须属于操作方所在商家；员工不能修改自己的角色，避免店主误操作后失去管理权限
+func (s *EmployeeService) SetEmployeeRole(ctx context.Context, operator StaffOperator, employeeID int64, role string) (*model.Employee, error) {
+	if operator.MerchantID <= 0 {
+		return nil, ErrInvalidMerchantID
+	}
+	if !model.IsValidEmployeeRole(role) {
+		return nil, ErrEmployeeRoleInvalid
+	}
+	if operator.EmployeeID != 0 && operator.EmployeeID == employeeID {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	employee, err := s.fetchEmployeeByID(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !employee.BelongsToMerchant(operator.MerchantID) {
+		return nil, ErrEmployeeNotInMerchant
+	}
+
+	from := employee.Role
+	if err := employee.ChangeRole(role); err != nil {
+		return nil, ErrEmployeeRoleInvalid
+	}
+	if err := s.employeeRepo.UpdateRole(ctx, employeeID, role); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+	}
+
+	s.logEmployeeRoleChanged(operator, employeeID, from, role)
+	return employee, nil
+}
+
+// #endregion
+
 // #region 私有辅助方法
-func (s *EmployeeService) fetchEmployeeByID(id int64) (*model.Employee, error) {
+func (s *EmployeeService) fetchEmployeeByID(ctx context.Context, id int64) (*model.Employee, error) {
 	if id <= 0 {
 		return nil, ErrInvalidEmployeeID
 	}
 
-	employee, err := s.employeeRepo.GetByID(id)
+	employee, err := s.employeeRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrEmployeeNotFound, err)
 	}
@@ -378,20 +447,20 @@ func (s *EmployeeService) now() string {
 }
 
 // getEmployeeByLoginInfo 根据登录信息获取员工
-func (s *EmployeeService) getEmployeeByLoginInfo(loginInfo, loginType string) (*model.Employee, error) {
+func (s *EmployeeService) getEmployeeByLoginInfo(ctx context.Context, loginInfo, loginType string) (*model.Employee, error) {
 	switch loginType {
 	case "email":
-		return s.employeeRepo.GetByEmail(loginInfo)
+		return s.employeeRepo.GetByEmail(ctx, loginInfo)
 	case "phone":
-		return s.employeeRepo.GetByPhone(loginInfo)
+		return s.employeeRepo.GetByPhone(ctx, loginInfo)
 	default:
 		// 智能检测登录类型
 		if validator.IsEmail(loginInfo) {
-			return s.employeeRepo.GetByEmail(loginInfo)
+			return s.employeeRepo.GetByEmail(ctx, loginInfo)
 		} else if validator.IsPhone(loginInfo) {
-			return s.employeeRepo.GetByPhone(loginInfo)
+			return s.employeeRepo.GetByPhone(ctx, loginInfo)
 		} else {
-			return s.employeeRepo.GetByUsername(loginInfo)
+			return s.employeeRepo.GetByUsername(ctx, loginInfo)
 		}
 	}
 }
@@ -410,10 +479,10 @@ func (s *EmployeeService) validateEmployeeFields(email, phone string) error {
 }
 
 // checkEmployeeAvailabilityExcluding 检查员工可用性（排除指定员工）
-func (s *EmployeeService) checkEmployeeAvailabilityExcluding(excludeEmployeeID int64, username, email, phone string) error {
+func (s *EmployeeService) checkEmployeeAvailabilityExcluding(ctx context.Context, excludeEmployeeID int64, username, email, phone string) error {
 	// 检查邮箱
 	if email != "" {
-		existing, err := s.employeeRepo.GetByEmail(email)
+		existing, err := s.employeeRepo.GetByEmail(ctx, email)
 		if err == nil && existing.ID != excludeEmployeeID {
 			return ErrEmailAlreadyExists
 		}
@@ -421,7 +490,7 @@ func (s *EmployeeService) checkEmployeeAvailabilityExcluding(excludeEmployeeID i
 
 	// 检查手机号
 	if phone != "" {
-		existing, err := s.employeeRepo.GetByPhone(phone)
+		existing, err := s.employeeRepo.GetByPhone(ctx, phone)
 		if err == nil && existing.ID != excludeEmployeeID {
 			return ErrPhoneAlreadyExists
 		}
@@ -429,7 +498,7 @@ func (s *EmployeeService) checkEmployeeAvailabilityExcluding(excludeEmployeeID i
 
 	// 检查用户名
 	if username != "" {
-		existing, err := s.employeeRepo.GetByUsername(username)
+		existing, err := s.employeeRepo.GetByUsername(ctx, username)
 		if err == nil && existing.ID != excludeEmployeeID {
 			return ErrUsernameAlreadyExists
 		}
@@ -460,16 +529,16 @@ func (s *EmployeeService) logEmployeeProfileUpdated(employeeID int64) {
 		employeeID, s.now())
 }
 
+// logEmployeeRoleChanged 记录员工角色变更日志
+func (s *EmployeeService) logEmployeeRoleChanged(operator StaffOperator, employeeID int64, from, to string) {
+	log.Printf("员工角色变更 - 商家ID: %d, 操作员工ID: %d, 员工ID: %d, 角色: %s -> %s, 时间: %s",
+		operator.MerchantID, operator.EmployeeID, employeeID, from, to, s.now())
+}
+
 // logEmployeePasswordUpdated 记录员工密码更新日志
 func (s *EmployeeService) logEmployeePasswordUpdated(employeeID int64) {
 	log.Printf("员工密码更新 - 员工ID: %d, 时间: %s",
 		employeeID, s.now())
 }
 
-// logEmployeeTransferred 记录员工转移日志
-func (s *EmployeeService) logEmployeeTransferred(employeeID, oldMerchantID, newMerchantID int64) {
-	log.Printf("员工转移 - 员工ID: %d, 原商家ID: %d, 新商家ID: %d, 时间: %s",
-		employeeID, oldMerchantID, newMerchantID, s.now())
-}
-
 // #endregion
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+)
+
+type roleEmployeeRepo struct {
+	repository.EmployeeRepositoryInterface
+	employees map[int64]*model.Employee
+}
+
+func (f *roleEmployeeRepo) GetByID(ctx context.Context, id int64) (*model.Employee, error) {
+	employee, ok := f.employees[id]
+	if !ok {
+		return nil, repository.ErrEmployeeNotFound
+	}
+	copied := *employee
+	return &copied, nil
+}
+
+func (f *roleEmployeeRepo) UpdateRole(ctx context.Context, id int64, role string) error {
+	f.employees[id].Role = role
+	return nil
+}
+
+func TestEmployeeService_Roles(t *testing.T) {
+	repo := &roleEmployeeRepo{employees: map[int64]*model.Employee{
+		1: {ID: 1, MerchantID: 10, Role: model.EmployeeRoleOwner, IsActive: true},
+		2: {ID: 2, MerchantID: 10, Role: model.EmployeeRoleCashier, IsActive: true},
+		3: {ID: 3, MerchantID: 20, Role: model.EmployeeRoleCashier, IsActive: true},
+	}}
+	merchants := &verificationMerchantRepo{merchants: map[int64]*model.Merchant{
+		10: {ID: 10, IsActive: true, VerificationStatus: model.VerificationApproved},
+		20: {ID: 20, IsActive: true, VerificationStatus: model.VerificationApproved},
+	}}
+	svc := NewEmployeeService(EmployeeServiceDependencies{EmployeeRepo: repo, MerchantRepo: merchants})
+	ctx := context.Background()
+	owner := StaffOperator{MerchantID: 10, EmployeeID: 1}
+
+	if _, err := svc.SetEmployeeRole(ctx, owner, 3, model.EmployeeRoleKitchen); !errors.Is(err, ErrEmployeeNotInMerchant) {
+		t.Fatalf("other merchant's employee err=%v want ErrEmployeeNotInMerchant", err)
+	}
+	if _, err := svc.SetEmployeeRole(ctx, owner, 1, model.EmployeeRoleCashier); !errors.Is(err, ErrCannotChangeOwnRole) {
+		t.Fatalf("own role err=%v want ErrCannotChangeOwnRole", err)
+	}
+	if _, err := svc.SetEmployeeRole(ctx, owner, 2, "chef"); !errors.Is(err, ErrEmployeeRoleInvalid) {
+		t.Fatalf("unknown role err=%v want ErrEmployeeRoleInvalid", err)
+	}
+
+	if _, allowed, _ := svc.CheckPermission(ctx, 2, model.PermOrderPrepare); allowed {
+		t.Fatal("cashier should not mark orders prepared")
+	}
+	if _, err := svc.SetEmployeeRole(ctx, owner, 2, model.EmployeeRoleKitchen); err != nil {
+		t.Fatalf("SetEmployeeRole: %v", err)
+	}
+	merchantID, allowed, err := svc.CheckPermission(ctx, 2, model.PermOrderPrepare)
+	if err != nil || !allowed || merchantID != 10 {
+		t.Fatalf("kitchen prepare: merchant=%d allowed=%v err=%v", merchantID, allowed, err)
+	}
+
+	// 停用或不存在的员工一律无权限，但不视为查询错误
+	repo.employees[2].IsActive = false
+	if _, allowed, err := svc.CheckPermission(ctx, 2, model.PermOrderPrepare); allowed || err != nil {
+		t.Fatalf("inactive employee allowed=%v err=%v", allowed, err)
+	}
+	if _, allowed, err := svc.CheckPermission(ctx, 99, model.PermOrderView); allowed || err != nil {
+		t.Fatalf("missing employee allowed=%v err=%v", allowed, err)
+	}
+
+	// 收银可以取消订单、查看商品，后厨不能取消订单
+	if _, allowed, _ := svc.CheckPermission(ctx, 3, model.PermOrderCancel); !allowed {
+		t.Fatal("cashier should cancel orders")
+	}
+	if _, allowed, _ := svc.CheckPermission(ctx, 3, model.PermMenuView); !allowed {
+		t.Fatal("cashier should view the menu")
+	}
+	repo.employees[2].IsActive = true
+	if _, allowed, _ := svc.CheckPermission(ctx, 2, model.PermOrderCancel); allowed {
+		t.Fatal("kitchen should not cancel orders")
+	}
+}
+
+func TestEmployeeService_CheckPermissionRequiresTradingMerchant(t *testing.T) {
+	repo := &roleEmployeeRepo{employees: map[int64]*model.Employee{
+		1: {ID: 1, MerchantID: 10, Role: model.EmployeeRoleOwner, IsActive: true},
+		2: {ID: 2, MerchantID: 20, Role: model.EmployeeRoleOwner, IsActive: true},
+		3: {ID: 3, MerchantID: 30, Role: model.EmployeeRoleOwner, IsActive: true},
+	}}
+	merchants := &verificationMerchantRepo{merchants: map[int64]*model.Merchant{
+		10: {ID: 10, IsActive: true, VerificationStatus: model.VerificationApproved},
+		20: {ID: 20, IsActive: true, VerificationStatus: model.VerificationSubmitted},
+		30: {ID: 30, IsActive: false, VerificationStatus: model.VerificationApproved},
+	}}
+	svc := NewEmployeeService(EmployeeServiceDependencies{EmployeeRepo: repo, MerchantRepo: merchants})
+	ctx := context.Background()
+
+	if _, allowed, err := svc.CheckPermission(ctx, 1, model.PermMenuView); !allowed || err != nil {
+		t.Fatalf("verified merchant allowed=%v err=%v", allowed, err)
+	}
+	// 商家未通过审核或已停用时，员工与商家账号本人一样被拒绝
+	for _, id := range []int64{2, 3} {
+		if _, allowed, err := svc.CheckPermission(ctx, id, model.PermMenuView); allowed || err != nil {
+			t.Fatalf("employee %d allowed=%v err=%v want denied", id, allowed, err)
+		}
+	}
+	delete(merchants.merchants, 10)
+	if _, allowed, err := svc.CheckPermission(ctx, 1, model.PermMenuView); allowed || err != nil {
+		t.Fatalf("missing merchant allowed=%v err=%v", allowed, err)
+	}
+}
@@ -1,6 +1,9 @@
 package service
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // #region 用户相关错误
 var (
@@ -24,6 +27,8 @@ var (
 	ErrEmployeeNotFound      = errors.New("员工不存在")
 	ErrEmployeeNil           = errors.New("员工对象不能为空")
 	ErrInvalidEmployeeID     = errors.New("员工ID无效")
+	ErrEmployeeRoleInvalid   = errors.New("员工角色无效")
+	ErrCannotChangeOwnRole   = errors.New("不能修改自己的角色")
 )
 
 // #endregion
@@ -34,6 +39,11 @@ var (
 	ErrMerchantNotFound      = errors.New("商家不存在")
 	ErrMerchantNil           = errors.New("商家对象不能为空")
 	ErrInvalidMerchantID     = errors.New("商家ID无效")
+	ErrMerchantInactive      = errors.New("商家已停用")
+	ErrEmployeeNotInMerchant = errors.New("员工不属于该商家")
+
+	ErrMerchantProfileLocked = errors.New("审核中或已通过审核，不能修改营业信息")
+	ErrBusinessLicenseTaken  = errors.New("营业执照号已被其他商家使用")
 )
 
 // #endregion
@@ -44,6 +54,87 @@ var (
 	ErrRiderNotFound      = errors.New("配送员不存在")
 	ErrRiderNil           = errors.New("配送员对象不能为空")
 	ErrInvalidRiderID     = errors.New("配送员ID无效")
+	ErrRiderUnavailable   = errors.New("配送员未激活或不在线")
+	ErrRiderNotVerified   = errors.New("配送员尚未通过资质审核")
+	ErrRiderProfileLocked = errors.New("审核中或已通过审核，不能修改身份与车辆信息")
+	ErrRiderIDNumberTaken = errors.New("身份证号已被其他配送员使用")
+)
+
+// #endregion
+
+// #region 管理员相关错误
+var (
+	ErrAdminNil            = errors.New("管理员对象不能为空")
+	ErrAdminNotFound       = errors.New("管理员不存在")
+	ErrInvalidAdminID      = errors.New("管理员ID无效")
+	ErrInvalidAccountType  = errors.New("账号类型无效")
+	ErrInvalidAccountID    = errors.New("账号ID无效")
+	ErrAccountNotFound     = errors.New("账号不存在")
+	ErrAuditReasonRequired = errors.New("操作原因不能为空")
+	ErrAuditReasonTooLong  = errors.New("操作原因过长")
+
+	ErrAccountSessionsNotRevoked = errors.New("账号已停用，但吊销登录会话失败，请重试停用操作")
+
+	ErrSMSPhoneListUnavailable = errors.New("短信服务未启用，无法管理号码名单")
+	ErrSMSPhoneNotListed       = errors.New("号码不在名单中")
+)
+
+// #endregion
+
+// #region 资质审核相关错误
+var (
+	ErrDocumentTypeInvalid        = errors.New("材料类型无效")
+	ErrDocumentEmpty              = errors.New("上传文件为空")
+	ErrDocumentTooLarge           = errors.New("上传文件过大")
+	ErrDocumentFormatUnsupported  = errors.New("仅支持 JPG、PNG、PDF 格式的材料")
+	ErrDocumentNotFound           = errors.New("材料不存在")
+	ErrDocumentsLocked            = errors.New("审核中或已通过审核，不能修改材料")
+	ErrBusinessLicenseDocRequired = errors.New("请先上传营业执照")
+	ErrRiderDocumentRequired      = errors.New("请先上传全部必需的资质材料")
+	ErrInvalidVerificationState   = errors.New("当前审核状态不允许该操作")
+	ErrVerificationStatusInvalid  = errors.New("审核状态无效")
+	ErrMerchantNotVerified        = errors.New("商家尚未通过资质审核")
+	ErrStorageUnavailable         = errors.New("文件存储不可用")
+)
+
+// #endregion
+
+// #region 订单相关错误
+var (
+	ErrOrderNil               = errors.New("订单对象不能为空")
+	ErrInvalidOrderID         = errors.New("订单ID无效")
+	ErrOrderNotFound          = errors.New("订单不存在")
+	ErrOrderAccessDenied      = errors.New("无权操作该订单")
+	ErrInvalidOrderTransition = errors.New("当前订单状态不允许该操作")
+	ErrOrderStatusConflict    = errors.New("订单状态已变更，请刷新后重试")
+	ErrOrderNotDelivered      = errors.New("订单尚未送达")
+	ErrOrderAlreadyRated      = errors.New("订单已评价")
+	ErrMerchantNotAccepting   = errors.New("商家暂不接单")
+
+	ErrOrderItemNotFound       = errors.New("商品不存在或不属于该商家")
+	ErrOrderItemUnavailable    = errors.New("商品已下架或库存不足")
+	ErrMerchantLocationMissing = errors.New("商家尚未设置门店地址")
+)
+
+// #endregion
+
+// #region 派单相关错误
+var (
+	ErrOrderNotDispatchable = errors.New("订单未出餐或已指派配送员，无法派单")
+	ErrNoAvailableRiders    = errors.New("附近暂无可接单的配送员")
+	ErrNoOfferForRider      = errors.New("该订单当前未派给此配送员或派单已过期")
+)
+
+// #endregion
+
+// #region 商品目录相关错误
+var (
+	ErrCategoryNil         = errors.New("分类对象不能为空")
+	ErrCategoryNotFound    = errors.New("分类不存在")
+	ErrCategoryNotEmpty    = errors.New("分类下仍有商品，无法删除")
+	ErrMenuItemNil         = errors.New("商品对象不能为空")
+	ErrMenuItemNotFound    = errors.New("商品不存在")
+	ErrCatalogAccessDenied = errors.New("无权管理该商家的商品")
 )
 
 // #endregion
@@ -54,6 +145,12 @@ var (
 	ErrAvailabilityCheck       = errors.New("可用性检查失败")
 	ErrPasswordHashing         = errors.New("密码加密失败")
 	ErrTokenGeneration         = errors.New("令牌生成失败")
+	ErrRefreshTokenInvalid     = errors.New("刷新令牌无效或已过期")
+	ErrRefreshTokenReused      = errors.New("刷新令牌已被使用，请重新登录")
+	ErrRefreshUnavailable      = errors.New("刷新令牌服务不可用")
+	ErrRevocationUnavailable   = errors.New("令牌吊销服务不可用")
+	ErrTokenRevocation         = errors.New("令牌吊销失败")
+	ErrRefreshTokenNotOwned    = errors.New("刷新令牌不属于当前账号")
 	ErrLoginInfoEmpty          = errors.New("登录信息不能为空")
 	ErrDataUpdateFailed        = errors.New("数据更新失败")
 	ErrDataSaveFailed          = errors.New("数据保存失败")
@@ -63,6 +160,7 @@ var (
 	ErrSMSCodeInvalid          = errors.New("短信验证码无效")
 	ErrSMSCodeEmpty            = errors.New("短信验证码不能为空")
 	ErrSMSSendFailed           = errors.New("短信发送失败")
+	ErrSMSPurposeInvalid       = errors.New("验证码用途无效")
 	ErrPaginationInvalid       = errors.New("分页参数无效")
 	ErrSearchKeywordShort      = errors.New("搜索关键词过短")
 	ErrEmailInvalid            = errors.New("邮箱格式无效")
@@ -95,3 +193,34 @@ var (
 )
 
 // #endregion
+
+// #region 找回密码相关错误
+var (
+	ErrResetChannelInvalid  = errors.New("找回密码方式无效")
+	ErrResetUserTypeInvalid = errors.New("账号类型不支持找回密码")
+	ErrResetTokenInvalid    = errors.New("找回密码凭证无效或已过期")
+	ErrResetUnavailable     = errors.New("找回密码服务不可用")
+	ErrPasswordTooWeak      = errors.New("新密码不符合强度要求")
+	ErrMailSendFailed       = errors.New("邮件发送失败")
+)
+
+// #endregion
+
+// #region 登录限流相关错误
+var ErrTooManyLoginAttempts = errors.New("登录失败次数过多，请稍后再试")
+
+// LoginLockedError 登录被限流时返回，携带建议的重试等待时长
+// errors.Is(err, ErrTooManyLoginAttempts) 为 true
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// #endregion
@@ -1,21 +1,51 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/Hermitf/the-pass/pkg/auth"
 )
 
 // #region 服务定义
 
+// TokenPair 登录/刷新返回的访问令牌与刷新令牌
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64 // 访问令牌有效期（秒）
+}
+
 // JWTServiceInterface JWT服务接口
 type JWTServiceInterface interface {
 	GenerateToken(userID int64, userType string) (string, error)
-	VerifyToken(tokenString string) (int64, error)
-	RefreshToken(tokenString string) (string, error)
+	VerifyToken(ctx context.Context, tokenString string) (int64, error)
+	// IssueTokenPair 为新登录签发访问令牌，并开启新的刷新令牌族
+	IssueTokenPair(ctx context.Context, userID int64, userType string) (*TokenPair, error)
+	// RefreshToken 使用刷新令牌换取新的令牌对，旧刷新令牌随即失效
+	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
+	// Logout 登出当前会话：吊销当前访问令牌，并吊销刷新令牌所属令牌族（若提供）
+	Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error
+	// LogoutAll 登出用户全部会话：此前签发的访问令牌与刷新令牌全部失效
+	LogoutAll(ctx context.Context, userID int64, userType string) error
+	// JWKS 导出可公开的验证公钥，供其他服务校验本服务签发的令牌
+	JWKS() auth.JWKSet
 }
 
 // JWTService JWT服务实现
 type JWTService struct {
-	config auth.JWTConfig
+	config          auth.JWTConfig
+	refreshStore    auth.RefreshTokenStore
+	revocationStore auth.TokenRevocationStore
+}
+
+// JWTServiceDependencies JWT服务依赖项
+type JWTServiceDependencies struct {
+	Config          auth.JWTConfig
+	RefreshStore    auth.RefreshTokenStore    // 为空时 IssueTokenPair/RefreshToken 返回 ErrRefreshUnavailable
+	RevocationStore auth.TokenRevocationStore // 为空时登出接口返回 ErrRevocationUnavailable
 }
 
 // #endregion
@@ -23,9 +53,11 @@ type JWTService struct {
 // #region 构造函数
 
 // NewJWTService 创建JWT服务实例
-func NewJWTService(config auth.JWTConfig) JWTServiceInterface {
+func NewJWTService(deps JWTServiceDependencies) JWTServiceInterface {
 	return &JWTService{
-		config: config,
+		config:          deps.Config,
+		refreshStore:    deps.RefreshStore,
+		revocationStore: deps.RevocationStore,
 	}
 }
 
@@ -38,24 +70,130 @@ func (s *JWTService) GenerateToken(userID int64, userType string) (string, error
 	return auth.GenerateToken(userID, userType, s.config)
 }
 
-// VerifyToken 验证JWT令牌并返回用户ID
-func (s *JWTService) VerifyToken(tokenString string) (int64, error) {
+// VerifyToken 验证JWT令牌并返回用户ID，已吊销的令牌视为无效
+func (s *JWTService) VerifyToken(ctx context.Context, tokenString string) (int64, error) {
 	claims, err := auth.VerifyToken(tokenString, s.config)
 	if err != nil {
 		return 0, err
 	}
+	if err := auth.CheckRevocation(ctx, s.revocationStore, claims); err != nil {
+		return 0, err
+	}
 	return claims.UserID, nil
 }
 
-// RefreshToken 刷新JWT令牌
-func (s *JWTService) RefreshToken(tokenString string) (string, error) {
-	claims, err := auth.VerifyToken(tokenString, s.config)
+// IssueTokenPair 为新登录签发访问令牌与刷新令牌
+func (s *JWTService) IssueTokenPair(ctx context.Context, userID int64, userType string) (*TokenPair, error) {
+	if s.refreshStore == nil {
+		return nil, ErrRefreshUnavailable
+	}
+
+	accessToken, err := auth.GenerateToken(userID, userType, s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, _, err := s.refreshStore.Issue(ctx, userID, userType, s.config.RefreshTTL())
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{AccessToken: accessToken, RefreshToken: refreshToken, ExpiresIn: s.config.ExpiresIn}, nil
+}
+
+// RefreshToken 轮换刷新令牌并签发新的访问令牌
+// 已轮换过的刷新令牌被再次使用时，整个令牌族会被吊销，返回 ErrRefreshTokenReused
+func (s *JWTService) RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if s.refreshStore == nil {
+		return nil, ErrRefreshUnavailable
+	}
+
+	newRefreshToken, session, err := s.refreshStore.Rotate(ctx, refreshToken, s.config.RefreshTTL())
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrRefreshTokenReused):
+			return nil, ErrRefreshTokenReused
+		case errors.Is(err, auth.ErrRefreshTokenInvalid), errors.Is(err, auth.ErrRefreshTokenRevoked):
+			return nil, ErrRefreshTokenInvalid
+		default:
+			return nil, err
+		}
+	}
+
+	accessToken, err := auth.GenerateToken(session.UserID, session.UserType, s.config)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
 	}
 
-	// 生成新令牌
-	return auth.GenerateToken(claims.UserID, claims.UserType, s.config)
+	return &TokenPair{AccessToken: accessToken, RefreshToken: newRefreshToken, ExpiresIn: s.config.ExpiresIn}, nil
+}
+
+// JWKS 导出可公开的验证公钥（HS256 密钥不会导出）
+func (s *JWTService) JWKS() auth.JWKSet {
+	return auth.BuildJWKSet(s.config.Keys)
+}
+
+// #endregion
+
+// #region 登出与吊销
+
+// Logout 登出当前会话
+// 访问令牌按 jti 加入黑名单直至其过期（含校验允许的时钟偏差 Leeway）；提供刷新令牌时一并吊销其令牌族，防止继续换取新令牌
+// 刷新令牌必须属于当前账号，否则返回 ErrRefreshTokenNotOwned 且不吊销任何令牌
+func (s *JWTService) Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error {
+	if claims == nil {
+		return ErrInvalidUserID
+	}
+	if s.revocationStore == nil {
+		return ErrRevocationUnavailable
+	}
+
+	if refreshToken != "" && s.refreshStore != nil {
+		if err := s.refreshStore.RevokeToken(ctx, refreshToken, claims.UserID, claims.UserType); err != nil {
+			if errors.Is(err, auth.ErrRefreshTokenNotOwned) {
+				return ErrRefreshTokenNotOwned
+			}
+			return fmt.Errorf("%w: %v", ErrTokenRevocation, err)
+		}
+	}
+
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time.Add(s.leeway())
+	}
+	if err := s.revocationStore.RevokeJTI(ctx, claims.ID, expiresAt); err != nil {
+		return fmt.Errorf("%w: %v", ErrTokenRevocation, err)
+	}
+	return nil
+}
+
+// leeway 令牌校验允许的时钟偏差，吊销记录须多保留这段时间
+func (s *JWTService) leeway() time.Duration {
+	return time.Duration(s.config.Leeway) * time.Second
+}
+
+// LogoutAll 登出用户全部会话
+// 将用户"令牌生效起点"推进到当前时间，记录保留一个访问令牌有效期加 Leeway 即可覆盖所有旧令牌
+// （已过期但仍在时钟偏差内的令牌同样会通过校验）；同时吊销全部刷新令牌族
+func (s *JWTService) LogoutAll(ctx context.Context, userID int64, userType string) error {
+	if userID <= 0 {
+		return ErrInvalidUserID
+	}
+	if s.revocationStore == nil {
+		return ErrRevocationUnavailable
+	}
+
+	ttl := time.Duration(s.config.ExpiresIn)*time.Second + s.leeway()
+	if err := s.revocationStore.SetTokensValidAfter(ctx, userType, userID, time.Now(), ttl); err != nil {
+		return fmt.Errorf("%w: %v", ErrTokenRevocation, err)
+	}
+
+	if s.refreshStore != nil {
+		if err := s.refreshStore.RevokeUser(ctx, userID, userType); err != nil {
+			return fmt.Errorf("%w: %v", ErrTokenRevocation, err)
+		}
+	}
+	return nil
 }
 
 // #endregion
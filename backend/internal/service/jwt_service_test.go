@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Hermitf/the-pass/pkg/auth"
+)
+
+// recordingRevocationStore 记录写入的吊销条目
+type recordingRevocationStore struct {
+	auth.TokenRevocationStore
+	validAfterTTL time.Duration
+	jtiExpiresAt  time.Time
+}
+
+func (f *recordingRevocationStore) SetTokensValidAfter(ctx context.Context, userType string, userID int64, at time.Time, ttl time.Duration) error {
+	f.validAfterTTL = ttl
+	return nil
+}
+
+func (f *recordingRevocationStore) RevokeJTI(ctx context.Context, jti string, expiresAt time.Time) error {
+	f.jtiExpiresAt = expiresAt
+	return nil
+}
+
+func TestJWTService_RevocationCoversLeeway(t *testing.T) {
+	cfg := auth.JWTConfig{SecretKey: "test-secret", ExpiresIn: 3600, Leeway: 30}
+	store := &recordingRevocationStore{}
+	svc := NewJWTService(JWTServiceDependencies{Config: cfg, RevocationStore: store})
+	ctx := context.Background()
+
+	// 过期后 Leeway 内的令牌仍能通过校验，吊销记录须保留到那时
+	if err := svc.LogoutAll(ctx, 1, "user"); err != nil {
+		t.Fatalf("LogoutAll: %v", err)
+	}
+	if want := 3630 * time.Second; store.validAfterTTL != want {
+		t.Fatalf("valid-after ttl=%v want %v", store.validAfterTTL, want)
+	}
+
+	token, err := svc.GenerateToken(1, "user")
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	claims, err := auth.VerifyToken(token, cfg)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if err := svc.Logout(ctx, claims, ""); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if want := claims.ExpiresAt.Time.Add(30 * time.Second); !store.jtiExpiresAt.Equal(want) {
+		t.Fatalf("jti revoked until %v want %v", store.jtiExpiresAt, want)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/config"
+	"github.com/Hermitf/the-pass/pkg/crypto"
+)
+
+// #region 登录失败限流
+
+// 登录限流默认值
+const (
+	DefaultLoginAccountMaxAttempts = 5
+	DefaultLoginIPMaxAttempts      = 50
+	DefaultLoginLimitWindow        = 15 * time.Minute
+	DefaultLoginLockout            = 15 * time.Minute
+)
+
+// LoginLimiterPolicies 由配置生成 账号+IP 与 IP 两个维度的限流策略，零值字段使用默认值
+func LoginLimiterPolicies(cfg config.LoginLimitConfig) (account, ip crypto.LimiterPolicy) {
+	window := cfg.Window
+	if window <= 0 {
+		window = DefaultLoginLimitWindow
+	}
+	lockout := cfg.Lockout
+	if lockout <= 0 {
+		lockout = DefaultLoginLockout
+	}
+	accountMax := cfg.AccountMaxAttempts
+	if accountMax <= 0 {
+		accountMax = DefaultLoginAccountMaxAttempts
+	}
+	ipMax := cfg.IPMaxAttempts
+	if ipMax <= 0 {
+		ipMax = DefaultLoginIPMaxAttempts
+	}
+	account = crypto.LimiterPolicy{MaxAttempts: accountMax, Window: window, Lockout: lockout}
+	ip = crypto.LimiterPolicy{MaxAttempts: ipMax, Window: window, Lockout: lockout}
+	return account, ip
+}
+
+// LoginGuard 登录失败限流，同时按 账号+IP 与 IP 两个维度计数
+//
+// 账号+IP 维度防止针对单个账号的暴力破解，IP 维度防止同一来源轮换账号撞库。
+// 账号不存在同样计入失败，避免通过限流行为探测账号是否存在。
+// 零值（nil）LoginGuard 不做任何限制；限流存储故障时放行登录，只记录日志。
+type LoginGuard struct {
+	account crypto.AttemptLimiter
+	ip      crypto.AttemptLimiter
+}
+
+// NewLoginGuard 创建登录限流器；任一维度为 nil 时该维度不生效
+func NewLoginGuard(account, ip crypto.AttemptLimiter) *LoginGuard {
+	return &LoginGuard{account: account, ip: ip}
+}
+
+// loginAccountKey 账号+IP 维度的 key；登录标识大小写不敏感
+func loginAccountKey(userType, loginInfo, clientIP string) string {
+	return userType + ":" + strings.ToLower(strings.TrimSpace(loginInfo)) + "|" + clientIP
+}
+
+// Check 登录前检查两个维度是否处于锁定期
+func (g *LoginGuard) Check(ctx context.Context, userType, loginInfo, clientIP string) error {
+	if g == nil {
+		return nil
+	}
+	var retryAfter time.Duration
+	if g.account != nil {
+		retryAfter = g.check(ctx, g.account, loginAccountKey(userType, loginInfo, clientIP))
+	}
+	if g.ip != nil && clientIP != "" {
+		if d := g.check(ctx, g.ip, clientIP); d > retryAfter {
+			retryAfter = d
+		}
+	}
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure 登录失败时两个维度各计一次
+func (g *LoginGuard) RecordFailure(ctx context.Context, userType, loginInfo, clientIP string) {
+	if g == nil {
+		return
+	}
+	if g.account != nil {
+		g.recordFailure(ctx, g.account, loginAccountKey(userType, loginInfo, clientIP))
+	}
+	if g.ip != nil && clientIP != "" {
+		g.recordFailure(ctx, g.ip, clientIP)
+	}
+}
+
+// RecordSuccess 登录成功时清除 账号+IP 维度的计数；IP 维度不重置，避免攻击者用自有账号刷新计数
+func (g *LoginGuard) RecordSuccess(ctx context.Context, userType, loginInfo, clientIP string) {
+	if g == nil || g.account == nil {
+		return
+	}
+	if err := g.account.Reset(ctx, loginAccountKey(userType, loginInfo, clientIP)); err != nil {
+		log.Printf("登录限流 - 重置失败计数出错: %v, 时间: %s", err, time.Now().Format("2006-01-02 15:04:05"))
+	}
+}
+
+func (g *LoginGuard) check(ctx context.Context, limiter crypto.AttemptLimiter, key string) time.Duration {
+	remaining, err := limiter.Check(ctx, key)
+	if err != nil && !errors.Is(err, crypto.ErrTooManyAttempts) {
+		log.Printf("登录限流 - 读取锁定状态出错，放行: %v, 时间: %s", err, time.Now().Format("2006-01-02 15:04:05"))
+		return 0
+	}
+	return remaining
+}
+
+func (g *LoginGuard) recordFailure(ctx context.Context, limiter crypto.AttemptLimiter, key string) {
+	lock, err := limiter.RecordFailure(ctx, key)
+	switch {
+	case errors.Is(err, crypto.ErrTooManyAttempts):
+		log.Printf("登录限流 - 触发锁定: %s, 锁定时长: %s, 时间: %s", key, lock, time.Now().Format("2006-01-02 15:04:05"))
+	case err != nil:
+		log.Printf("登录限流 - 记录失败次数出错: %v, 时间: %s", err, time.Now().Format("2006-01-02 15:04:05"))
+	}
+}
+
+// #endregion
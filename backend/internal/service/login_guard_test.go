@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/pkg/clock"
+	"github.com/Hermitf/the-pass/pkg/crypto"
+)
+
+type fakeTokenIssuer struct {
+	JWTServiceInterface
+}
+
+func (fakeTokenIssuer) IssueTokenPair(ctx context.Context, userID int64, userType string) (*TokenPair, error) {
+	return &TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil
+}
+
+func TestLoginUser_LocksAfterRepeatedFailures(t *testing.T) {
+	hash, err := crypto.HashPassword("Passw0rd!")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	users := &fakeUserRepo{users: map[int64]*model.User{
+		1: {ID: 1, Phone: "13800138000", IsActive: true, PasswordHash: hash},
+	}}
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	policy := crypto.LimiterPolicy{MaxAttempts: 3, Window: time.Minute, Lockout: time.Minute}
+	svc := NewUserService(UserServiceDependencies{
+		UserRepo:   users,
+		JWTService: fakeTokenIssuer{},
+		LoginGuard: NewLoginGuard(
+			crypto.NewMemoryAttemptLimiter(policy, fake),
+			crypto.NewMemoryAttemptLimiter(crypto.LimiterPolicy{MaxAttempts: 6, Window: time.Minute}, fake),
+		),
+	})
+	ctx := context.Background()
+	phone := users.users[1].Phone
+
+	// 成功登录清除此前的失败计数
+	for i := 0; i < 2; i++ {
+		if _, err := svc.LoginUser(ctx, phone, "wrong", "password", "1.2.3.4"); !errors.Is(err, ErrInvalidPassword) {
+			t.Fatalf("wrong password err=%v want ErrInvalidPassword", err)
+		}
+	}
+	if _, err := svc.LoginUser(ctx, phone, "Passw0rd!", "password", "1.2.3.4"); err != nil {
+		t.Fatalf("LoginUser: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _ = svc.LoginUser(ctx, phone, "wrong", "password", "1.2.3.4")
+	}
+	_, err = svc.LoginUser(ctx, phone, "Passw0rd!", "password", "1.2.3.4")
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) || locked.RetryAfter != time.Minute {
+		t.Fatalf("locked account err=%v want LoginLockedError with 1m", err)
+	}
+
+	// 同一 IP 轮换账号同样计数：账号不存在的失败触发 IP 维度锁定
+	if _, err := svc.LoginUser(ctx, phone, "Passw0rd!", "password", "5.6.7.8"); err != nil {
+		t.Fatalf("other IP should not be locked: %v", err)
+	}
+	if _, err := svc.LoginUser(ctx, "13900139000", "x", "password", "1.2.3.4"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("unknown account err=%v want ErrUserNotFound", err)
+	}
+	if _, err := svc.LoginUser(ctx, "13900139000", "x", "password", "1.2.3.4"); !errors.Is(err, ErrTooManyLoginAttempts) {
+		t.Fatalf("ip lock err=%v want ErrTooManyLoginAttempts", err)
+	}
+
+	fake.Advance(time.Minute + time.Second)
+	if _, err := svc.LoginUser(ctx, phone, "Passw0rd!", "password", "1.2.3.4"); err != nil {
+		t.Fatalf("after lockout: %v", err)
+	}
+}
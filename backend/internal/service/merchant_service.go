@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/Hermitf/the-pass/internal/database"
 	"github.com/Hermitf/the-pass/internal/model"
 	"github.com/Hermitf/the-pass/internal/repository"
 	"github.com/Hermitf/the-pass/pkg/crypto"
@@ -18,36 +19,40 @@ import (
 // MerchantServiceInterface 商家服务接口
 type MerchantServiceInterface interface {
 	// 商家注册和认证
-	RegisterMerchant(merchant *model.Merchant) error
-	LoginMerchant(loginInfo, password, loginType string) (string, error)
+	RegisterMerchant(ctx context.Context, merchant *model.Merchant) error
+	LoginMerchant(ctx context.Context, loginInfo, password, loginType, clientIP string) (*TokenPair, error)
 
 	// 短信验证相关
-	SendSMSCode(ctx context.Context, phone string) error
-	VerifySMSCode(ctx context.Context, phone, code string) error
+	SendSMSCode(ctx context.Context, phone, purpose string) error
+	VerifySMSCode(ctx context.Context, phone, code, purpose string) error
 	CanSendSMSCode(ctx context.Context, phone string) (bool, time.Duration, error)
 
 	// 商家信息管理
-	GetMerchantByID(id int64) (*model.Merchant, error)
-	UpdateMerchantProfile(merchantID int64, companyName, address, contactName string) error
-	UpdateMerchantPassword(merchantID int64, oldPassword, newPassword string) error
+	GetMerchantByID(ctx context.Context, id int64) (*model.Merchant, error)
+	UpdateMerchantProfile(ctx context.Context, merchantID int64, companyName, address, contactName string) error
+	UpdateMerchantPassword(ctx context.Context, merchantID int64, oldPassword, newPassword string) error
+	// UpdateStoreLocation 设置门店地址与坐标，下单时作为订单取货地址
+	UpdateStoreLocation(ctx context.Context, merchantID int64, address string, lat, lng float64) (*model.Merchant, error)
 
 	// 商家验证
 	ValidateMerchantData(merchant *model.Merchant) error
-	CheckMerchantAvailability(username, email, phone, businessLicense string) error
+	CheckMerchantAvailability(ctx context.Context, username, email, phone, businessLicense string) error
 
 	// 员工管理
-	GetMerchantWithEmployees(merchantID int64) (*model.Merchant, []*model.Employee, error)
-	GetMerchantEmployeeStats(merchantID int64) (map[string]interface{}, error)
+	GetMerchantWithEmployees(ctx context.Context, merchantID int64) (*model.Merchant, []*model.Employee, error)
+	GetMerchantEmployeeStats(ctx context.Context, merchantID int64) (map[string]interface{}, error)
+	AddEmployee(ctx context.Context, merchantID int64, employee *model.Employee) error
+	TransferEmployee(ctx context.Context, employeeID, fromMerchantID, toMerchantID int64) error
 
 	// 商家列表和搜索
-	GetMerchantList(offset, limit int) ([]*model.Merchant, int64, error)
-	GetActiveMerchants(offset, limit int) ([]*model.Merchant, int64, error)
-	SearchMerchants(keyword string, offset, limit int) ([]*model.Merchant, int64, error)
-	GetMerchantsByRegion(region string, offset, limit int) ([]*model.Merchant, int64, error)
+	GetMerchantList(ctx context.Context, offset, limit int) ([]*model.Merchant, int64, error)
+	GetActiveMerchants(ctx context.Context, offset, limit int) ([]*model.Merchant, int64, error)
+	SearchMerchants(ctx context.Context, keyword string, offset, limit int) ([]*model.Merchant, int64, error)
+	GetMerchantsByRegion(ctx context.Context, region string, offset, limit int) ([]*model.Merchant, int64, error)
 
 	// 商家统计
-	GetMerchantStats() (map[string]interface{}, error)
-	GetTopMerchantsByEmployeeCount(limit int) ([]*model.Merchant, error)
+	GetMerchantStats(ctx context.Context) (map[string]interface{}, error)
+	GetTopMerchantsByEmployeeCount(ctx context.Context, limit int) ([]*model.Merchant, error)
 }
 
 // MerchantService 商家服务实现
@@ -56,6 +61,8 @@ type MerchantService struct {
 	employeeRepo repository.EmployeeRepositoryInterface
 	jwtService   JWTServiceInterface
 	smsService   *sms.Service
+	loginGuard   *LoginGuard
+	txManager    database.TxManager
 }
 
 // #endregion
@@ -68,6 +75,8 @@ type MerchantServiceDependencies struct {
 	EmployeeRepo repository.EmployeeRepositoryInterface
 	JWTService   JWTServiceInterface
 	SMSService   *sms.Service
+	LoginGuard   *LoginGuard
+	TxManager    database.TxManager
 }
 
 // NewMerchantService 创建商家服务实例
@@ -77,6 +86,8 @@ func NewMerchantService(deps MerchantServiceDependencies) MerchantServiceInterfa
 		employeeRepo: deps.EmployeeRepo,
 		jwtService:   deps.JWTService,
 		smsService:   deps.SMSService,
+		loginGuard:   deps.LoginGuard,
+		txManager:    deps.TxManager,
 	}
 }
 
@@ -85,7 +96,7 @@ func NewMerchantService(deps MerchantServiceDependencies) MerchantServiceInterfa
 // #region 商家注册和认证
 
 // RegisterMerchant 注册商家
-func (s *MerchantService) RegisterMerchant(merchant *model.Merchant) error {
+func (s *MerchantService) RegisterMerchant(ctx context.Context, merchant *model.Merchant) error {
 	if merchant == nil {
 		return ErrMerchantNil
 	}
@@ -96,7 +107,7 @@ func (s *MerchantService) RegisterMerchant(merchant *model.Merchant) error {
 	}
 
 	// 检查商家是否已存在
-	if err := s.CheckMerchantAvailability(merchant.Username, merchant.Email, merchant.Phone, merchant.BusinessLicense); err != nil {
+	if err := s.CheckMerchantAvailability(ctx, merchant.Username, merchant.Email, merchant.Phone, merchant.BusinessLicense); err != nil {
 		return fmt.Errorf("%w: %v", ErrAvailabilityCheck, err)
 	}
 
@@ -109,8 +120,14 @@ func (s *MerchantService) RegisterMerchant(merchant *model.Merchant) error {
 		merchant.PasswordHash = hashedPassword
 	}
 
+	// 新商家需提交资质并经管理员审核通过后才能经营
+	merchant.VerificationStatus = model.VerificationDraft
+	merchant.VerificationRejectReason = ""
+	merchant.VerificationSubmittedAt = nil
+	merchant.VerifiedAt = nil
+
 	// 创建商家
-	if err := s.merchantRepo.Create(merchant); err != nil {
+	if err := s.merchantRepo.Create(ctx, merchant); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
 	}
 
@@ -119,83 +136,104 @@ func (s *MerchantService) RegisterMerchant(merchant *model.Merchant) error {
 }
 
 // LoginMerchant 商家登录
-func (s *MerchantService) LoginMerchant(loginInfo, password, loginType string) (string, error) {
+func (s *MerchantService) LoginMerchant(ctx context.Context, loginInfo, password, loginType, clientIP string) (*TokenPair, error) {
 	if loginInfo == "" || password == "" {
-		return "", ErrLoginInfoEmpty
+		return nil, ErrLoginInfoEmpty
+	}
+	if err := s.loginGuard.Check(ctx, "merchant", loginInfo, clientIP); err != nil {
+		return nil, err
 	}
 
 	// 根据登录类型获取商家信息
-	merchant, err := s.getMerchantByLoginInfo(loginInfo, loginType)
+	merchant, err := s.getMerchantByLoginInfo(ctx, loginInfo, loginType)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrMerchantNotFound, err)
+		s.loginGuard.RecordFailure(ctx, "merchant", loginInfo, clientIP)
+		return nil, fmt.Errorf("%w: %v", ErrMerchantNotFound, err)
 	}
 
 	// 验证密码
 	if err := crypto.VerifyPassword(merchant.PasswordHash, password); err != nil {
-		return "", ErrInvalidCredentials
+		s.loginGuard.RecordFailure(ctx, "merchant", loginInfo, clientIP)
+		return nil, ErrInvalidCredentials
 	}
+	s.loginGuard.RecordSuccess(ctx, "merchant", loginInfo, clientIP)
 
 	// 检查商家状态
 	if !merchant.IsActive {
-		return "", ErrAccountDeactivated
+		return nil, ErrAccountDeactivated
 	}
 
 	// 生成JWT令牌
-	token, err := s.jwtService.GenerateToken(merchant.ID, "merchant")
+	tokens, err := s.jwtService.IssueTokenPair(ctx, merchant.ID, "merchant")
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+		return nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
 	}
 
 	s.logMerchantLogin(merchant, loginType)
-	return token, nil
+	return tokens, nil
 }
 
 // #endregion
 
 // #region 短信验证相关
 
-// SendSMSCode 发送短信验证码
-func (s *MerchantService) SendSMSCode(ctx context.Context, phone string) error {
+// SendSMSCode 发送指定用途的短信验证码
+// 注册/换绑要求手机号尚未注册，登录/找回密码要求商家已注册且处于启用状态
+func (s *MerchantService) SendSMSCode(ctx context.Context, phone, purpose string) error {
 	if phone == "" {
 		return ErrPhoneEmpty
 	}
 	if !validator.IsPhone(phone) {
 		return ErrPhoneInvalid
 	}
-
-	// 检查商家是否存在
-	merchant, err := s.merchantRepo.GetByPhone(phone)
+	scope, err := resolveSMSScope(smsRoleMerchant, purpose)
 	if err != nil {
-		return ErrPhoneNotRegistered
+		return err
 	}
 
-	// 检查商家状态
-	if !merchant.IsActive {
-		return ErrAccountDeactivated
+	// 按用途检查商家是否存在及状态
+	var merchantID int64
+	merchant, err := s.merchantRepo.GetByPhone(ctx, phone)
+	if smsTargetsNewPhone(scope.Purpose) {
+		if err == nil {
+			return ErrPhoneAlreadyExists
+		}
+	} else {
+		if err != nil {
+			return ErrPhoneNotRegistered
+		}
+		if !merchant.IsActive {
+			return ErrAccountDeactivated
+		}
+		merchantID = merchant.ID
 	}
 
 	// 发送验证码
 	if s.smsService == nil {
 		return ErrSMSSendFailed
 	}
-	if err := s.smsService.SendCode(ctx, phone); err != nil {
+	if err := s.smsService.SendCode(ctx, scope, phone); err != nil {
 		return err
 	}
 
-	log.Printf("商家短信发送 - 手机号: %s, 商家ID: %d, 时间: %s",
-		phone, merchant.ID, time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("商家短信发送 - 手机号: %s, 用途: %s, 商家ID: %d, 时间: %s",
+		phone, scope.Purpose, merchantID, time.Now().Format("2006-01-02 15:04:05"))
 	return nil
 }
 
-// VerifySMSCode 验证短信验证码
-func (s *MerchantService) VerifySMSCode(ctx context.Context, phone, code string) error {
+// VerifySMSCode 验证指定用途的短信验证码
+func (s *MerchantService) VerifySMSCode(ctx context.Context, phone, code, purpose string) error {
 	if phone == "" || code == "" {
 		return ErrSMSCodeEmpty
 	}
+	scope, err := resolveSMSScope(smsRoleMerchant, purpose)
+	if err != nil {
+		return err
+	}
 	if s.smsService == nil {
 		return ErrSMSCodeInvalid
 	}
-	if err := s.smsService.VerifyCode(ctx, phone, code); err != nil {
+	if err := s.smsService.VerifyCode(ctx, scope, phone, code); err != nil {
 		return err
 	}
 	return nil
@@ -217,12 +255,12 @@ func (s *MerchantService) CanSendSMSCode(ctx context.Context, phone string) (boo
 // #region 商家信息管理
 
 // GetMerchantByID 根据ID获取商家信息
-func (s *MerchantService) GetMerchantByID(id int64) (*model.Merchant, error) {
+func (s *MerchantService) GetMerchantByID(ctx context.Context, id int64) (*model.Merchant, error) {
 	if id <= 0 {
 		return nil, ErrInvalidMerchantID
 	}
 
-	merchant, err := s.merchantRepo.GetByID(id)
+	merchant, err := s.merchantRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrMerchantNotFound, err)
 	}
@@ -231,13 +269,13 @@ func (s *MerchantService) GetMerchantByID(id int64) (*model.Merchant, error) {
 }
 
 // UpdateMerchantProfile 更新商家档案
-func (s *MerchantService) UpdateMerchantProfile(merchantID int64, companyName, address, contactName string) error {
+func (s *MerchantService) UpdateMerchantProfile(ctx context.Context, merchantID int64, companyName, address, contactName string) error {
 	if merchantID <= 0 {
 		return ErrInvalidMerchantID
 	}
 
 	// 获取商家信息
-	merchant, err := s.merchantRepo.GetByID(merchantID)
+	merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrMerchantNotFound, err)
 	}
@@ -251,7 +289,7 @@ func (s *MerchantService) UpdateMerchantProfile(merchantID int64, companyName, a
 	merchant.CompanyName = companyName
 	// 注意：当前模型没有Address和ContactName字段，只更新CompanyName
 
-	if err := s.merchantRepo.Update(merchant); err != nil {
+	if err := s.merchantRepo.Update(ctx, merchant); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
 	}
 
@@ -259,8 +297,30 @@ func (s *MerchantService) UpdateMerchantProfile(merchantID int64, companyName, a
 	return nil
 }
 
+// UpdateStoreLocation 设置门店地址与坐标
+func (s *MerchantService) UpdateStoreLocation(ctx context.Context, merchantID int64, address string, lat, lng float64) (*model.Merchant, error) {
+	if merchantID <= 0 {
+		return nil, ErrInvalidMerchantID
+	}
+
+	merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMerchantNotFound, err)
+	}
+	if err := merchant.SetStoreLocation(address, lat, lng); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValidationFailed, err)
+	}
+
+	if err := s.merchantRepo.UpdateStoreLocation(ctx, merchantID, merchant.StoreAddress, merchant.StoreLat, merchant.StoreLng); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+	}
+
+	s.logMerchantProfileUpdated(merchantID)
+	return merchant, nil
+}
+
 // UpdateMerchantPassword 更新商家密码
-func (s *MerchantService) UpdateMerchantPassword(merchantID int64, oldPassword, newPassword string) error {
+func (s *MerchantService) UpdateMerchantPassword(ctx context.Context, merchantID int64, oldPassword, newPassword string) error {
 	if merchantID <= 0 {
 		return ErrInvalidMerchantID
 	}
@@ -269,7 +329,7 @@ func (s *MerchantService) UpdateMerchantPassword(merchantID int64, oldPassword,
 	}
 
 	// 获取商家信息
-	merchant, err := s.merchantRepo.GetByID(merchantID)
+	merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrMerchantNotFound, err)
 	}
@@ -287,7 +347,7 @@ func (s *MerchantService) UpdateMerchantPassword(merchantID int64, oldPassword,
 
 	// 更新密码
 	merchant.PasswordHash = hashedPassword
-	if err := s.merchantRepo.Update(merchant); err != nil {
+	if err := s.merchantRepo.Update(ctx, merchant); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
 	}
 
@@ -305,12 +365,15 @@ func (s *MerchantService) ValidateMerchantData(merchant *model.Merchant) error {
 		return ErrMerchantNil
 	}
 
-	return s.validateMerchantFields(merchant.CompanyName)
+	if err := s.validateMerchantFields(merchant.CompanyName); err != nil {
+		return err
+	}
+	return merchant.ValidateBusinessLicense()
 }
 
 // CheckMerchantAvailability 检查商家可用性
-func (s *MerchantService) CheckMerchantAvailability(username, email, phone, businessLicense string) error {
-	exists, err := s.merchantRepo.CheckMerchantExists(username, email, phone, businessLicense)
+func (s *MerchantService) CheckMerchantAvailability(ctx context.Context, username, email, phone, businessLicense string) error {
+	exists, err := s.merchantRepo.CheckMerchantExists(ctx, username, email, phone, businessLicense)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrAvailabilityCheck, err)
 	}
@@ -327,12 +390,12 @@ func (s *MerchantService) CheckMerchantAvailability(username, email, phone, busi
 // #region 员工管理
 
 // GetMerchantWithEmployees 获取商家及其员工信息
-func (s *MerchantService) GetMerchantWithEmployees(merchantID int64) (*model.Merchant, []*model.Employee, error) {
+func (s *MerchantService) GetMerchantWithEmployees(ctx context.Context, merchantID int64) (*model.Merchant, []*model.Employee, error) {
 	if merchantID <= 0 {
 		return nil, nil, ErrInvalidMerchantID
 	}
 
-	merchant, employees, err := s.merchantRepo.GetMerchantWithEmployees(merchantID)
+	merchant, employees, err := s.merchantRepo.GetMerchantWithEmployees(ctx, merchantID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w: %v", ErrMerchantNotFound, err)
 	}
@@ -341,7 +404,7 @@ func (s *MerchantService) GetMerchantWithEmployees(merchantID int64) (*model.Mer
 }
 
 // GetMerchantEmployeeStats 获取商家员工统计信息
-func (s *MerchantService) GetMerchantEmployeeStats(merchantID int64) (map[string]interface{}, error) {
+func (s *MerchantService) GetMerchantEmployeeStats(ctx context.Context, merchantID int64) (map[string]interface{}, error) {
 	if merchantID <= 0 {
 		return nil, ErrInvalidMerchantID
 	}
@@ -350,7 +413,7 @@ func (s *MerchantService) GetMerchantEmployeeStats(merchantID int64) (map[string
 		return nil, ErrEmployeeRepoUnavailable
 	}
 
-	stats, err := s.employeeRepo.GetEmployeeStatsByMerchant(merchantID)
+	stats, err := s.employeeRepo.GetEmployeeStatsByMerchant(ctx, merchantID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrGetStatistics, err)
 	}
@@ -358,17 +421,149 @@ func (s *MerchantService) GetMerchantEmployeeStats(merchantID int64) (map[string
 	return stats, nil
 }
 
+// AddEmployee 商家新增员工
+// employee.PasswordHash 传入明文密码，由服务层加密；
+// 商家行锁与员工写入处于同一事务，避免商家在此期间被停用后仍新增员工
+func (s *MerchantService) AddEmployee(ctx context.Context, merchantID int64, employee *model.Employee) error {
+	if employee == nil {
+		return ErrEmployeeNil
+	}
+	if merchantID <= 0 {
+		return ErrInvalidMerchantID
+	}
+	if employee.Email != "" && !validator.IsEmail(employee.Email) {
+		return fmt.Errorf("%w: %v", ErrValidationFailed, ErrEmailInvalid)
+	}
+	if employee.Phone != "" && !validator.IsPhone(employee.Phone) {
+		return fmt.Errorf("%w: %v", ErrValidationFailed, ErrPhoneInvalid)
+	}
+	if employee.Role == "" {
+		employee.Role = model.DefaultEmployeeRole
+	}
+	if !model.IsValidEmployeeRole(employee.Role) {
+		return fmt.Errorf("%w: %v", ErrValidationFailed, ErrEmployeeRoleInvalid)
+	}
+
+	// 加密密码放在事务外，避免 bcrypt 计算期间持有商家行锁
+	if employee.PasswordHash != "" {
+		hashedPassword, err := crypto.HashPassword(employee.PasswordHash)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrPasswordHashing, err)
+		}
+		employee.PasswordHash = hashedPassword
+	}
+	employee.MerchantID = merchantID
+
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.lockActiveMerchant(ctx, merchantID); err != nil {
+			return err
+		}
+
+		exists, err := s.employeeRepo.CheckEmployeeExists(ctx, employee.Username, employee.Email, employee.Phone)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrAvailabilityCheck, err)
+		}
+		if exists {
+			return ErrEmployeeAlreadyExists
+		}
+
+		if err := s.employeeRepo.Create(ctx, employee); err != nil {
+			return fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.logEmployeeAdded(employee)
+	return nil
+}
+
+// TransferEmployee 将员工从 fromMerchantID 转移到 toMerchantID
+// 两个商家按 ID 升序加锁，避免相向转移时互相等待造成死锁；
+// 原商家授予的角色不带到新商家，转移时在同一事务内重置为默认角色，由新商家店主重新分配
+func (s *MerchantService) TransferEmployee(ctx context.Context, employeeID, fromMerchantID, toMerchantID int64) error {
+	if employeeID <= 0 {
+		return ErrInvalidEmployeeID
+	}
+	if fromMerchantID <= 0 || toMerchantID <= 0 {
+		return ErrInvalidMerchantID
+	}
+	if fromMerchantID == toMerchantID {
+		return ErrSameMerchantTransfer
+	}
+
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		first, second := fromMerchantID, toMerchantID
+		if first > second {
+			first, second = second, first
+		}
+		for _, id := range []int64{first, second} {
+			merchant, err := s.lockMerchant(ctx, id)
+			if err != nil {
+				return err
+			}
+			// 仅要求目标商家处于激活状态，允许从已停用商家迁出员工
+			if id == toMerchantID && !merchant.IsActiveMerchant() {
+				return ErrMerchantInactive
+			}
+		}
+
+		employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrEmployeeNotFound, err)
+		}
+		if employee.MerchantID != fromMerchantID {
+			return ErrEmployeeNotInMerchant
+		}
+
+		if err := s.employeeRepo.TransferEmployee(ctx, employeeID, toMerchantID); err != nil {
+			return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+		}
+		if err := s.employeeRepo.UpdateRole(ctx, employeeID, model.DefaultEmployeeRole); err != nil {
+			return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.logEmployeeTransferred(employeeID, fromMerchantID, toMerchantID)
+	return nil
+}
+
+// lockActiveMerchant 锁定商家行并要求其处于激活状态
+func (s *MerchantService) lockActiveMerchant(ctx context.Context, merchantID int64) (*model.Merchant, error) {
+	merchant, err := s.lockMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !merchant.IsActiveMerchant() {
+		return nil, ErrMerchantInactive
+	}
+	return merchant, nil
+}
+
+// lockMerchant 在当前事务中锁定商家行
+func (s *MerchantService) lockMerchant(ctx context.Context, merchantID int64) (*model.Merchant, error) {
+	merchant, err := s.merchantRepo.GetByIDForUpdate(ctx, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMerchantNotFound, err)
+	}
+	return merchant, nil
+}
+
 // #endregion
 
 // #region 商家列表和搜索
 
 // GetMerchantList 获取商家列表
-func (s *MerchantService) GetMerchantList(offset, limit int) ([]*model.Merchant, int64, error) {
+func (s *MerchantService) GetMerchantList(ctx context.Context, offset, limit int) ([]*model.Merchant, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	merchants, total, err := s.merchantRepo.GetMerchantList(offset, limit)
+	merchants, total, err := s.merchantRepo.GetMerchantList(ctx, offset, limit)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: %v", ErrGetMerchantList, err)
 	}
@@ -377,12 +572,12 @@ func (s *MerchantService) GetMerchantList(offset, limit int) ([]*model.Merchant,
 }
 
 // GetActiveMerchants 获取活跃商家列表
-func (s *MerchantService) GetActiveMerchants(offset, limit int) ([]*model.Merchant, int64, error) {
+func (s *MerchantService) GetActiveMerchants(ctx context.Context, offset, limit int) ([]*model.Merchant, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	merchants, total, err := s.merchantRepo.GetActiveMerchants(offset, limit)
+	merchants, total, err := s.merchantRepo.GetActiveMerchants(ctx, offset, limit)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: %v", ErrGetMerchantList, err)
 	}
@@ -391,12 +586,12 @@ func (s *MerchantService) GetActiveMerchants(offset, limit int) ([]*model.Mercha
 }
 
 // SearchMerchants 搜索商家
-func (s *MerchantService) SearchMerchants(keyword string, offset, limit int) ([]*model.Merchant, int64, error) {
+func (s *MerchantService) SearchMerchants(ctx context.Context, keyword string, offset, limit int) ([]*model.Merchant, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	merchants, total, err := s.merchantRepo.SearchMerchants(keyword, offset, limit)
+	merchants, total, err := s.merchantRepo.SearchMerchants(ctx, keyword, offset, limit)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: %v", ErrSearchMerchants, err)
 	}
@@ -405,7 +600,7 @@ func (s *MerchantService) SearchMerchants(keyword string, offset, limit int) ([]
 }
 
 // GetMerchantsByRegion 根据地区获取商家
-func (s *MerchantService) GetMerchantsByRegion(region string, offset, limit int) ([]*model.Merchant, int64, error) {
+func (s *MerchantService) GetMerchantsByRegion(ctx context.Context, region string, offset, limit int) ([]*model.Merchant, int64, error) {
 	if region == "" {
 		return nil, 0, ErrRegionEmpty
 	}
@@ -413,7 +608,7 @@ func (s *MerchantService) GetMerchantsByRegion(region string, offset, limit int)
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	merchants, total, err := s.merchantRepo.GetMerchantsByRegion(region, offset, limit)
+	merchants, total, err := s.merchantRepo.GetMerchantsByRegion(ctx, region, offset, limit)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: %v", ErrGetMerchantList, err)
 	}
@@ -426,8 +621,8 @@ func (s *MerchantService) GetMerchantsByRegion(region string, offset, limit int)
 // #region 商家统计
 
 // GetMerchantStats 获取商家统计信息
-func (s *MerchantService) GetMerchantStats() (map[string]interface{}, error) {
-	stats, err := s.merchantRepo.GetMerchantStats()
+func (s *MerchantService) GetMerchantStats(ctx context.Context) (map[string]interface{}, error) {
+	stats, err := s.merchantRepo.GetMerchantStats(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrGetStatistics, err)
 	}
@@ -436,12 +631,12 @@ func (s *MerchantService) GetMerchantStats() (map[string]interface{}, error) {
 }
 
 // GetTopMerchantsByEmployeeCount 获取员工数量最多的商家
-func (s *MerchantService) GetTopMerchantsByEmployeeCount(limit int) ([]*model.Merchant, error) {
+func (s *MerchantService) GetTopMerchantsByEmployeeCount(ctx context.Context, limit int) ([]*model.Merchant, error) {
 	if limit <= 0 {
 		return nil, ErrLimitInvalid
 	}
 
-	merchants, err := s.merchantRepo.GetTopMerchantsByEmployeeCount(limit)
+	merchants, err := s.merchantRepo.GetTopMerchantsByEmployeeCount(ctx, limit)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrGetMerchantList, err)
 	}
@@ -454,20 +649,20 @@ func (s *MerchantService) GetTopMerchantsByEmployeeCount(limit int) ([]*model.Me
 // #region 私有辅助方法
 
 // getMerchantByLoginInfo 根据登录信息获取商家
-func (s *MerchantService) getMerchantByLoginInfo(loginInfo, loginType string) (*model.Merchant, error) {
+func (s *MerchantService) getMerchantByLoginInfo(ctx context.Context, loginInfo, loginType string) (*model.Merchant, error) {
 	switch loginType {
 	case "email":
-		return s.merchantRepo.GetByEmail(loginInfo)
+		return s.merchantRepo.GetByEmail(ctx, loginInfo)
 	case "phone":
-		return s.merchantRepo.GetByPhone(loginInfo)
+		return s.merchantRepo.GetByPhone(ctx, loginInfo)
 	default:
 		// 智能检测登录类型
 		if validator.IsEmail(loginInfo) {
-			return s.merchantRepo.GetByEmail(loginInfo)
+			return s.merchantRepo.GetByEmail(ctx, loginInfo)
 		} else if validator.IsPhone(loginInfo) {
-			return s.merchantRepo.GetByPhone(loginInfo)
+			return s.merchantRepo.GetByPhone(ctx, loginInfo)
 		} else {
-			return s.merchantRepo.GetByUsername(loginInfo)
+			return s.merchantRepo.GetByUsername(ctx, loginInfo)
 		}
 	}
 }
@@ -513,4 +708,16 @@ func (s *MerchantService) logMerchantPasswordUpdated(merchantID int64) {
 		merchantID, time.Now().Format("2006-01-02 15:04:05"))
 }
 
+// logEmployeeAdded 记录商家新增员工日志
+func (s *MerchantService) logEmployeeAdded(employee *model.Employee) {
+	log.Printf("商家新增员工 - 商家ID: %d, 员工ID: %d, 用户名: %s, 时间: %s",
+		employee.MerchantID, employee.ID, employee.Username, time.Now().Format("2006-01-02 15:04:05"))
+}
+
+// logEmployeeTransferred 记录员工转移日志
+func (s *MerchantService) logEmployeeTransferred(employeeID, oldMerchantID, newMerchantID int64) {
+	log.Printf("员工转移 - 员工ID: %d, 原商家ID: %d, 新商家ID: %d, 时间: %s",
+		employeeID, oldMerchantID, newMerchantID, time.Now().Format("2006-01-02 15:04:05"))
+}
+
 // #endregion
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+)
+
+// lockingMerchantRepo 记录加锁顺序的商家仓库
+type lockingMerchantRepo struct {
+	repository.MerchantRepositoryInterface
+	merchants map[int64]*model.Merchant
+	locked    []int64
+}
+
+func (f *lockingMerchantRepo) GetByIDForUpdate(ctx context.Context, id int64) (*model.Merchant, error) {
+	merchant, ok := f.merchants[id]
+	if !ok {
+		return nil, repository.ErrMerchantNotFound
+	}
+	f.locked = append(f.locked, id)
+	return merchant, nil
+}
+
+func (f *lockingMerchantRepo) GetByID(ctx context.Context, id int64) (*model.Merchant, error) {
+	merchant, ok := f.merchants[id]
+	if !ok {
+		return nil, repository.ErrMerchantNotFound
+	}
+	copied := *merchant
+	return &copied, nil
+}
+
+// UpdateStoreLocation 只写门店字段
+func (f *lockingMerchantRepo) UpdateStoreLocation(ctx context.Context, id int64, address string, lat, lng float64) error {
+	merchant := f.merchants[id]
+	merchant.StoreAddress, merchant.StoreLat, merchant.StoreLng = address, lat, lng
+	return nil
+}
+
+type transferEmployeeRepo struct {
+	repository.EmployeeRepositoryInterface
+	employees map[int64]*model.Employee
+}
+
+func (f *transferEmployeeRepo) GetByID(ctx context.Context, id int64) (*model.Employee, error) {
+	employee, ok := f.employees[id]
+	if !ok {
+		return nil, repository.ErrEmployeeNotFound
+	}
+	return employee, nil
+}
+
+func (f *transferEmployeeRepo) TransferEmployee(ctx context.Context, employeeID, newMerchantID int64) error {
+	f.employees[employeeID].MerchantID = newMerchantID
+	return nil
+}
+
+func (f *transferEmployeeRepo) UpdateRole(ctx context.Context, id int64, role string) error {
+	f.employees[id].Role = role
+	return nil
+}
+
+// passthroughTxManager 直接执行 fn，用于不关心回滚的用例
+type passthroughTxManager struct{}
+
+func (passthroughTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestMerchantService_TransferEmployee(t *testing.T) {
+	merchants := &lockingMerchantRepo{merchants: map[int64]*model.Merchant{
+		1: {ID: 1, IsActive: true},
+		2: {ID: 2, IsActive: true},
+		3: {ID: 3, IsActive: false},
+	}}
+	employees := &transferEmployeeRepo{employees: map[int64]*model.Employee{
+		100: {ID: 100, MerchantID: 2, Role: model.EmployeeRoleManager},
+	}}
+	svc := NewMerchantService(MerchantServiceDependencies{
+		MerchantRepo: merchants,
+		EmployeeRepo: employees,
+		TxManager:    passthroughTxManager{},
+	})
+	ctx := context.Background()
+
+	if err := svc.TransferEmployee(ctx, 100, 1, 2); !errors.Is(err, ErrEmployeeNotInMerchant) {
+		t.Fatalf("wrong source err=%v want ErrEmployeeNotInMerchant", err)
+	}
+	if err := svc.TransferEmployee(ctx, 100, 2, 3); !errors.Is(err, ErrMerchantInactive) {
+		t.Fatalf("inactive target err=%v want ErrMerchantInactive", err)
+	}
+
+	merchants.locked = nil
+	if err := svc.TransferEmployee(ctx, 100, 2, 1); err != nil {
+		t.Fatalf("TransferEmployee: %v", err)
+	}
+	if employees.employees[100].MerchantID != 1 {
+		t.Fatalf("MerchantID=%d want 1", employees.employees[100].MerchantID)
+	}
+	// 原商家授予的经理角色不带到新商家
+	if role := employees.employees[100].Role; role != model.DefaultEmployeeRole {
+		t.Fatalf("Role=%q want %q", role, model.DefaultEmployeeRole)
+	}
+	// 无论转移方向，均按商家 ID 升序加锁
+	if len(merchants.locked) != 2 || merchants.locked[0] != 1 || merchants.locked[1] != 2 {
+		t.Fatalf("lock order=%v want [1 2]", merchants.locked)
+	}
+}
+
+func TestMerchantService_UpdateStoreLocationOnlyWritesStoreFields(t *testing.T) {
+	merchants := &lockingMerchantRepo{merchants: map[int64]*model.Merchant{
+		1: {ID: 1, IsActive: true, VerificationStatus: model.VerificationApproved},
+	}}
+	svc := NewMerchantService(MerchantServiceDependencies{MerchantRepo: merchants, TxManager: passthroughTxManager{}})
+	ctx := context.Background()
+
+	// 仓库 fake 未实现整行保存的 Update，调用即 panic
+	merchant, err := svc.UpdateStoreLocation(ctx, 1, "北京市东城区", 39.9, 116.4)
+	if err != nil {
+		t.Fatalf("UpdateStoreLocation: %v", err)
+	}
+	if merchant.StoreAddress != "北京市东城区" {
+		t.Fatalf("response StoreAddress=%q", merchant.StoreAddress)
+	}
+	saved := merchants.merchants[1]
+	if saved.StoreAddress != "北京市东城区" || saved.StoreLat != 39.9 || saved.StoreLng != 116.4 {
+		t.Fatalf("unexpected saved merchant: %+v", saved)
+	}
+
+	if _, err := svc.UpdateStoreLocation(ctx, 1, "", 39.9, 116.4); !errors.Is(err, ErrValidationFailed) {
+		t.Fatalf("empty address err=%v want ErrValidationFailed", err)
+	}
+}
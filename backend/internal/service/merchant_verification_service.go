@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/sms"
+	"github.com/Hermitf/the-pass/pkg/storage"
+)
+
+// #region 服务定义
+
+// MerchantVerification 商家资质审核进度及已上传材料
+type MerchantVerification struct {
+	MerchantID   int64                         `json:"merchant_id"`
+	Status       string                        `json:"status"`
+	RejectReason string                        `json:"reject_reason,omitempty"`
+	SubmittedAt  *time.Time                    `json:"submitted_at,omitempty"`
+	VerifiedAt   *time.Time                    `json:"verified_at,omitempty"`
+	Documents    []*model.VerificationDocument `json:"documents"`
+
+	CompanyName     string `json:"company_name"`
+	BusinessLicense string `json:"business_license"`
+}
+
+// MerchantApplication 商家提交审核前填写的营业信息
+type MerchantApplication struct {
+	CompanyName     string
+	BusinessLicense string
+}
+
+// MerchantVerificationServiceInterface 商家资质审核服务接口
+//
+// 状态流转：draft/rejected -> submitted -> under_review -> approved/rejected
+// 商家填写营业信息、上传材料后提交审核；受理、通过、驳回仅限管理员，且均写入审计日志。
+type MerchantVerificationServiceInterface interface {
+	// 商家侧
+	GetVerification(ctx context.Context, merchantID int64) (*MerchantVerification, error)
+	UpdateApplication(ctx context.Context, merchantID int64, app MerchantApplication) error
+	UploadDocument(ctx context.Context, merchantID int64, upload DocumentUpload) (*model.VerificationDocument, error)
+	OpenDocument(ctx context.Context, merchantID, docID int64) (*model.VerificationDocument, io.ReadCloser, error)
+	DeleteDocument(ctx context.Context, merchantID, docID int64) error
+	SubmitVerification(ctx context.Context, merchantID int64) error
+
+	// 管理员审核
+	ListVerifications(ctx context.Context, status string, offset, limit int) ([]*model.Merchant, int64, error)
+	StartReview(ctx context.Context, actor AdminActor, merchantID int64) error
+	ApproveVerification(ctx context.Context, actor AdminActor, merchantID int64, remark string) error
+	RejectVerification(ctx context.Context, actor AdminActor, merchantID int64, reason string) error
+
+	// 经营准入
+	IsMerchantVerified(ctx context.Context, merchantID int64) (bool, error)
+}
+
+// MerchantVerificationService 商家资质审核服务实现
+type MerchantVerificationService struct {
+	merchantRepo repository.MerchantRepositoryInterface
+	auditRepo    repository.AuditLogRepositoryInterface
+	documents    *verificationDocumentStore
+	notifier     sms.Provider
+	txManager    database.TxManager
+}
+
+// #endregion
+
+// #region 构造函数和依赖注入
+
+// MerchantVerificationServiceDependencies 商家资质审核服务依赖
+type MerchantVerificationServiceDependencies struct {
+	MerchantRepo  repository.MerchantRepositoryInterface
+	DocumentRepo  repository.VerificationDocumentRepositoryInterface
+	AuditRepo     repository.AuditLogRepositoryInterface
+	Storage       storage.Storage
+	Notifier      sms.Provider // 审核结果短信通知，为空时只记录日志
+	TxManager     database.TxManager
+	MaxUploadSize int64
+}
+
+// NewMerchantVerificationService 创建商家资质审核服务实例
+func NewMerchantVerificationService(deps MerchantVerificationServiceDependencies) MerchantVerificationServiceInterface {
+	return &MerchantVerificationService{
+		merchantRepo: deps.MerchantRepo,
+		auditRepo:    deps.AuditRepo,
+		documents:    newVerificationDocumentStore(deps.DocumentRepo, deps.Storage, deps.MaxUploadSize),
+		notifier:     deps.Notifier,
+		txManager:    deps.TxManager,
+	}
+}
+
+// #endregion
+
+// #region 商家侧
+
+// GetVerification 获取商家审核进度及材料列表
+func (s *MerchantVerificationService) GetVerification(ctx context.Context, merchantID int64) (*MerchantVerification, error) {
+	merchant, err := s.getMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.documents.repo.ListByOwner(ctx, model.AccountTypeMerchant, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MerchantVerification{
+		MerchantID:   merchant.ID,
+		Status:       merchant.VerificationStatus,
+		RejectReason: merchant.VerificationRejectReason,
+		SubmittedAt:  merchant.VerificationSubmittedAt,
+		VerifiedAt:   merchant.VerifiedAt,
+		Documents:    docs,
+
+		CompanyName:     merchant.CompanyName,
+		BusinessLicense: merchant.BusinessLicense,
+	}, nil
+}
+
+// UpdateApplication 填写或修改待审核的店铺名称与营业执照号，仅草稿或被驳回状态可修改
+func (s *MerchantVerificationService) UpdateApplication(ctx context.Context, merchantID int64, app MerchantApplication) error {
+	companyName := strings.TrimSpace(app.CompanyName)
+	license := strings.ToUpper(strings.TrimSpace(app.BusinessLicense))
+	if license == "" {
+		return fmt.Errorf("%w: 营业执照号不能为空", ErrValidationFailed)
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		merchant, err := s.lockMerchant(ctx, merchantID)
+		if err != nil {
+			return err
+		}
+		if !model.CanEditVerificationDocuments(merchant.VerificationStatus) {
+			return ErrMerchantProfileLocked
+		}
+		if err := merchant.UpdateProfile(companyName, license); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+		}
+
+		existing, err := s.merchantRepo.GetByBusinessLicense(ctx, license)
+		if err != nil && !repository.IsNotFoundError(err) {
+			return err
+		}
+		if existing != nil && existing.ID != merchant.ID {
+			return ErrBusinessLicenseTaken
+		}
+		return s.merchantRepo.UpdateBusinessInfo(ctx, merchant)
+	})
+	if err != nil {
+		return s.wrapVerificationError(err)
+	}
+
+	log.Printf("商家更新审核信息 - 商家ID: %d, 时间: %s", merchantID, time.Now().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// UploadDocument 上传资质材料，仅草稿或被驳回状态可修改材料
+func (s *MerchantVerificationService) UploadDocument(ctx context.Context, merchantID int64, upload DocumentUpload) (*model.VerificationDocument, error) {
+	if !model.IsValidMerchantDocType(upload.DocType) {
+		return nil, ErrDocumentTypeInvalid
+	}
+	merchant, err := s.getMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !model.CanEditVerificationDocuments(merchant.VerificationStatus) {
+		return nil, ErrDocumentsLocked
+	}
+
+	doc, err := s.documents.put(ctx, model.AccountTypeMerchant, merchantID, upload)
+	if err != nil {
+		return nil, err
+	}
+
+	// 锁定商家行后复核状态，避免与提交审核并发时材料在审核期间被修改
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		merchant, err := s.lockMerchant(ctx, merchantID)
+		if err != nil {
+			return err
+		}
+		if !model.CanEditVerificationDocuments(merchant.VerificationStatus) {
+			return ErrDocumentsLocked
+		}
+		return s.documents.repo.Create(ctx, doc)
+	})
+	if err != nil {
+		s.documents.discard(ctx, doc.StorageKey)
+		if errors.Is(err, ErrDocumentsLocked) || errors.Is(err, ErrMerchantNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
+	}
+
+	log.Printf("商家资质材料上传 - 商家ID: %d, 材料ID: %d, 类型: %s, 大小: %d, 时间: %s",
+		merchantID, doc.ID, doc.DocType, doc.Size, time.Now().Format("2006-01-02 15:04:05"))
+	return doc, nil
+}
+
+// OpenDocument 读取商家名下的一份材料，调用方负责关闭返回的 ReadCloser
+func (s *MerchantVerificationService) OpenDocument(ctx context.Context, merchantID, docID int64) (*model.VerificationDocument, io.ReadCloser, error) {
+	if merchantID <= 0 {
+		return nil, nil, ErrInvalidMerchantID
+	}
+	return s.documents.open(ctx, model.AccountTypeMerchant, merchantID, docID)
+}
+
+// DeleteDocument 删除资质材料，仅草稿或被驳回状态可修改材料
+func (s *MerchantVerificationService) DeleteDocument(ctx context.Context, merchantID, docID int64) error {
+	var doc *model.VerificationDocument
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		merchant, err := s.lockMerchant(ctx, merchantID)
+		if err != nil {
+			return err
+		}
+		if !model.CanEditVerificationDocuments(merchant.VerificationStatus) {
+			return ErrDocumentsLocked
+		}
+		doc, err = s.documents.repo.GetByID(ctx, model.AccountTypeMerchant, merchantID, docID)
+		if err != nil {
+			return err
+		}
+		return s.documents.repo.Delete(ctx, model.AccountTypeMerchant, merchantID, docID)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrVerificationDocumentNotFound) {
+			return ErrDocumentNotFound
+		}
+		if errors.Is(err, ErrDocumentsLocked) || errors.Is(err, ErrMerchantNotFound) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+	}
+
+	// 元数据已删除，文件删除失败只会留下孤儿文件，不影响业务
+	s.documents.discard(ctx, doc.StorageKey)
+	return nil
+}
+
+// SubmitVerification 提交资质审核，要求营业执照号有效且已上传营业执照
+func (s *MerchantVerificationService) SubmitVerification(ctx context.Context, merchantID int64) error {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		merchant, err := s.lockMerchant(ctx, merchantID)
+		if err != nil {
+			return err
+		}
+		if merchant.BusinessLicense == "" {
+			return fmt.Errorf("%w: 请先填写营业执照号", ErrValidationFailed)
+		}
+		if err := merchant.ValidateBusinessLicense(); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+		}
+		count, err := s.documents.repo.CountByDocType(ctx, model.AccountTypeMerchant, merchantID, model.MerchantDocBusinessLicense)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrBusinessLicenseDocRequired
+		}
+		return s.transition(ctx, merchant, model.VerificationSubmitted, "")
+	})
+	if err != nil {
+		return s.wrapVerificationError(err)
+	}
+
+	log.Printf("商家提交资质审核 - 商家ID: %d, 时间: %s", merchantID, time.Now().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// #endregion
+
+// #region 管理员审核
+
+// ListVerifications 按审核状态分页查询商家，按提交时间先后排列
+func (s *MerchantVerificationService) ListVerifications(ctx context.Context, status string, offset, limit int) ([]*model.Merchant, int64, error) {
+	if !model.IsValidVerificationStatus(status) {
+		return nil, 0, ErrVerificationStatusInvalid
+	}
+	if offset < 0 || limit <= 0 || limit > 100 {
+		return nil, 0, ErrPaginationInvalid
+	}
+	return s.merchantRepo.ListByVerificationStatus(ctx, status, offset, limit)
+}
+
+// StartReview 管理员受理审核申请
+func (s *MerchantVerificationService) StartReview(ctx context.Context, actor AdminActor, merchantID int64) error {
+	_, err := s.review(ctx, actor, merchantID, model.VerificationUnderReview, model.AuditActionMerchantReview, "受理资质审核")
+	return err
+}
+
+// ApproveVerification 管理员审核通过，remark 为空时使用默认说明
+func (s *MerchantVerificationService) ApproveVerification(ctx context.Context, actor AdminActor, merchantID int64, remark string) error {
+	remark = strings.TrimSpace(remark)
+	if remark == "" {
+		remark = "资质审核通过"
+	}
+	merchant, err := s.review(ctx, actor, merchantID, model.VerificationApproved, model.AuditActionMerchantApprove, remark)
+	if err != nil {
+		return err
+	}
+
+	s.notify(ctx, merchant, fmt.Sprintf("【The Pass】您的店铺「%s」已通过资质审核，现在可以开始经营。", merchant.CompanyName))
+	return nil
+}
+
+// RejectVerification 管理员驳回审核，必须填写驳回原因，原因会展示给商家
+func (s *MerchantVerificationService) RejectVerification(ctx context.Context, actor AdminActor, merchantID int64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrAuditReasonRequired
+	}
+	merchant, err := s.review(ctx, actor, merchantID, model.VerificationRejected, model.AuditActionMerchantReject, reason)
+	if err != nil {
+		return err
+	}
+
+	s.notify(ctx, merchant, fmt.Sprintf("【The Pass】您的店铺「%s」资质审核未通过，原因：%s。请修改材料后重新提交。", merchant.CompanyName, reason))
+	return nil
+}
+
+// review 在同一事务中迁移审核状态并写入审计日志，返回更新后的商家
+func (s *MerchantVerificationService) review(ctx context.Context, actor AdminActor, merchantID int64, to, action, reason string) (*model.Merchant, error) {
+	if actor.AdminID <= 0 {
+		return nil, ErrInvalidAdminID
+	}
+	if utf8.RuneCountInString(reason) > model.MaxAuditReasonLength {
+		return nil, ErrAuditReasonTooLong
+	}
+
+	var merchant *model.Merchant
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		merchant, err = s.lockMerchant(ctx, merchantID)
+		if err != nil {
+			return err
+		}
+		from := merchant.VerificationStatus
+		rejectReason := ""
+		if to == model.VerificationRejected {
+			rejectReason = reason
+		}
+		if err := s.transition(ctx, merchant, to, rejectReason); err != nil {
+			return err
+		}
+
+		detail, _ := json.Marshal(map[string]string{"from": from, "to": to})
+		return s.auditRepo.Create(ctx, &model.AuditLog{
+			ActorType:  model.AuditActorAdmin,
+			ActorID:    actor.AdminID,
+			Action:     action,
+			TargetType: model.AccountTypeMerchant,
+			TargetID:   merchantID,
+			Reason:     reason,
+			Detail:     string(detail),
+			ClientIP:   actor.ClientIP,
+		})
+	})
+	if err != nil {
+		return nil, s.wrapVerificationError(err)
+	}
+
+	log.Printf("管理员操作 - 管理员ID: %d, 操作: %s, 对象: %s/%d, 原因: %s, 时间: %s",
+		actor.AdminID, action, model.AccountTypeMerchant, merchantID, reason, time.Now().Format("2006-01-02 15:04:05"))
+	return merchant, nil
+}
+
+// #endregion
+
+// #region 经营准入
+
+// IsMerchantVerified 商家是否已通过资质审核
+func (s *MerchantVerificationService) IsMerchantVerified(ctx context.Context, merchantID int64) (bool, error) {
+	merchant, err := s.getMerchant(ctx, merchantID)
+	if err != nil {
+		return false, err
+	}
+	return merchant.VerificationStatus == model.VerificationApproved, nil
+}
+
+// #endregion
+
+// #region 辅助方法
+
+// getMerchant 根据ID获取商家
+func (s *MerchantVerificationService) getMerchant(ctx context.Context, merchantID int64) (*model.Merchant, error) {
+	if merchantID <= 0 {
+		return nil, ErrInvalidMerchantID
+	}
+	merchant, err := s.merchantRepo.GetByID(ctx, merchantID)
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, err
+	}
+	return merchant, nil
+}
+
+// lockMerchant 在当前事务中锁定商家行
+func (s *MerchantVerificationService) lockMerchant(ctx context.Context, merchantID int64) (*model.Merchant, error) {
+	if merchantID <= 0 {
+		return nil, ErrInvalidMerchantID
+	}
+	merchant, err := s.merchantRepo.GetByIDForUpdate(ctx, merchantID)
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return nil, ErrMerchantNotFound
+		}
+		return nil, err
+	}
+	return merchant, nil
+}
+
+// transition 迁移审核状态并持久化
+func (s *MerchantVerificationService) transition(ctx context.Context, merchant *model.Merchant, to, rejectReason string) error {
+	if err := merchant.TransitionVerification(to, rejectReason, time.Now()); err != nil {
+		return ErrInvalidVerificationState
+	}
+	return s.merchantRepo.UpdateVerification(ctx, merchant)
+}
+
+// wrapVerificationError 保留业务错误，其余错误归为数据更新失败
+func (s *MerchantVerificationService) wrapVerificationError(err error) error {
+	for _, known := range []error{
+		ErrInvalidMerchantID, ErrMerchantNotFound, ErrInvalidVerificationState,
+		ErrBusinessLicenseDocRequired, ErrValidationFailed,
+		ErrMerchantProfileLocked, ErrBusinessLicenseTaken,
+	} {
+		if errors.Is(err, known) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+}
+
+// notify 通知商家审核结果；通知失败不回滚审核决定，只记录日志
+func (s *MerchantVerificationService) notify(ctx context.Context, merchant *model.Merchant, content string) {
+	if s.notifier == nil || merchant.Phone == "" {
+		log.Printf("商家审核结果未发送短信通知 - 商家ID: %d, 状态: %s", merchant.ID, merchant.VerificationStatus)
+		return
+	}
+	if err := s.notifier.SendSMS(ctx, merchant.Phone, content); err != nil {
+		log.Printf("商家审核结果短信通知失败 - 商家ID: %d, 状态: %s, 错误: %v", merchant.ID, merchant.VerificationStatus, err)
+	}
+}
+
+// #endregion
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/storage"
+)
+
+type verificationMerchantRepo struct {
+	repository.MerchantRepositoryInterface
+	merchants map[int64]*model.Merchant
+}
+
+func (f *verificationMerchantRepo) GetByID(ctx context.Context, id int64) (*model.Merchant, error) {
+	merchant, ok := f.merchants[id]
+	if !ok {
+		return nil, repository.ErrMerchantNotFound
+	}
+	copied := *merchant
+	return &copied, nil
+}
+
+func (f *verificationMerchantRepo) GetByIDForUpdate(ctx context.Context, id int64) (*model.Merchant, error) {
+	return f.GetByID(ctx, id)
+}
+
+func (f *verificationMerchantRepo) UpdateVerification(ctx context.Context, merchant *model.Merchant) error {
+	copied := *merchant
+	f.merchants[merchant.ID] = &copied
+	return nil
+}
+
+func (f *verificationMerchantRepo) Create(ctx context.Context, merchant *model.Merchant) error {
+	merchant.ID = int64(len(f.merchants) + 1)
+	merchant.IsActive = true
+	return f.UpdateVerification(ctx, merchant)
+}
+
+func (f *verificationMerchantRepo) CheckMerchantExists(ctx context.Context, username, email, phone, businessLicense string) (bool, error) {
+	for _, m := range f.merchants {
+		if m.Username == username || m.Email == email || m.Phone == phone {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *verificationMerchantRepo) GetByBusinessLicense(ctx context.Context, license string) (*model.Merchant, error) {
+	for _, m := range f.merchants {
+		if m.BusinessLicense == license {
+			copied := *m
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrMerchantNotFound
+}
+
+func (f *verificationMerchantRepo) UpdateBusinessInfo(ctx context.Context, merchant *model.Merchant) error {
+	stored := f.merchants[merchant.ID]
+	stored.CompanyName = merchant.CompanyName
+	stored.BusinessLicense = merchant.BusinessLicense
+	return nil
+}
+
+type memDocumentRepo struct {
+	repository.VerificationDocumentRepositoryInterface
+	docs   []*model.VerificationDocument
+	nextID int64
+}
+
+func (f *memDocumentRepo) Create(ctx context.Context, doc *model.VerificationDocument) error {
+	f.nextID++
+	doc.ID = f.nextID
+	f.docs = append(f.docs, doc)
+	return nil
+}
+
+func (f *memDocumentRepo) ListByOwner(ctx context.Context, ownerType string, ownerID int64) ([]*model.VerificationDocument, error) {
+	var docs []*model.VerificationDocument
+	for _, doc := range f.docs {
+		if doc.OwnerType == ownerType && doc.OwnerID == ownerID {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+func (f *memDocumentRepo) CountByDocType(ctx context.Context, ownerType string, ownerID int64, docType string) (int64, error) {
+	var count int64
+	for _, doc := range f.docs {
+		if doc.OwnerType == ownerType && doc.OwnerID == ownerID && doc.DocType == docType {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestMerchantVerification_ReviewFlow(t *testing.T) {
+	merchants := &verificationMerchantRepo{merchants: map[int64]*model.Merchant{
+		1: {ID: 1, CompanyName: "老王面馆", Phone: "13800138000", BusinessLicense: "91350100M000100Y43",
+			IsActive: true, VerificationStatus: model.VerificationDraft},
+	}}
+	audit := &fakeAuditLogRepo{}
+	notifier := &lastMessage{}
+	svc := NewMerchantVerificationService(MerchantVerificationServiceDependencies{
+		MerchantRepo: merchants,
+		DocumentRepo: &memDocumentRepo{},
+		AuditRepo:    audit,
+		Storage:      storage.NewLocalStorage(t.TempDir()),
+		Notifier:     notifier,
+		TxManager:    passthroughTxManager{},
+	})
+	ctx := context.Background()
+	admin := AdminActor{AdminID: 1, ClientIP: "10.0.0.1"}
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+	upload := func(content []byte) error {
+		_, err := svc.UploadDocument(ctx, 1, DocumentUpload{
+			DocType: model.MerchantDocBusinessLicense, FileName: "license.png",
+			Size: int64(len(content)), Content: bytes.NewReader(content),
+		})
+		return err
+	}
+
+	if err := svc.SubmitVerification(ctx, 1); !errors.Is(err, ErrBusinessLicenseDocRequired) {
+		t.Fatalf("submit without license err=%v want ErrBusinessLicenseDocRequired", err)
+	}
+	if err := upload([]byte("plain text is not a license")); !errors.Is(err, ErrDocumentFormatUnsupported) {
+		t.Fatalf("text upload err=%v want ErrDocumentFormatUnsupported", err)
+	}
+	if err := upload(png); err != nil {
+		t.Fatalf("UploadDocument: %v", err)
+	}
+	if err := svc.SubmitVerification(ctx, 1); err != nil {
+		t.Fatalf("SubmitVerification: %v", err)
+	}
+
+	// 提交后材料锁定；商家不能跳过受理直接通过
+	if err := upload(png); !errors.Is(err, ErrDocumentsLocked) {
+		t.Fatalf("upload after submit err=%v want ErrDocumentsLocked", err)
+	}
+	if err := svc.ApproveVerification(ctx, admin, 1, ""); !errors.Is(err, ErrInvalidVerificationState) {
+		t.Fatalf("approve before review err=%v want ErrInvalidVerificationState", err)
+	}
+
+	if err := svc.StartReview(ctx, admin, 1); err != nil {
+		t.Fatalf("StartReview: %v", err)
+	}
+	if err := svc.RejectVerification(ctx, admin, 1, " "); !errors.Is(err, ErrAuditReasonRequired) {
+		t.Fatalf("blank reject reason err=%v want ErrAuditReasonRequired", err)
+	}
+	if err := svc.RejectVerification(ctx, admin, 1, "执照照片模糊"); err != nil {
+		t.Fatalf("RejectVerification: %v", err)
+	}
+	if m := merchants.merchants[1]; m.VerificationStatus != model.VerificationRejected || m.VerificationRejectReason != "执照照片模糊" {
+		t.Fatalf("after reject: status=%s reason=%q", m.VerificationStatus, m.VerificationRejectReason)
+	}
+	if !strings.Contains(notifier.content, "执照照片模糊") {
+		t.Fatalf("reject notification=%q should carry the reason", notifier.content)
+	}
+
+	// 驳回后可补充材料并重新提交
+	if err := upload(png); err != nil {
+		t.Fatalf("upload after reject: %v", err)
+	}
+	if err := svc.SubmitVerification(ctx, 1); err != nil {
+		t.Fatalf("resubmit: %v", err)
+	}
+	if verified, _ := svc.IsMerchantVerified(ctx, 1); verified {
+		t.Fatal("merchant should not be verified before approval")
+	}
+	if err := svc.StartReview(ctx, admin, 1); err != nil {
+		t.Fatalf("StartReview: %v", err)
+	}
+	if err := svc.ApproveVerification(ctx, admin, 1, ""); err != nil {
+		t.Fatalf("ApproveVerification: %v", err)
+	}
+	if verified, _ := svc.IsMerchantVerified(ctx, 1); !verified || !merchants.merchants[1].CanTrade() {
+		t.Fatal("merchant should be verified after approval")
+	}
+	if !strings.Contains(notifier.content, "已通过") {
+		t.Fatalf("approve notification=%q", notifier.content)
+	}
+
+	wantActions := []string{
+		model.AuditActionMerchantReview, model.AuditActionMerchantReject,
+		model.AuditActionMerchantReview, model.AuditActionMerchantApprove,
+	}
+	if len(audit.entries) != len(wantActions) {
+		t.Fatalf("audit entries=%d want %d", len(audit.entries), len(wantActions))
+	}
+	for i, entry := range audit.entries {
+		if entry.Action != wantActions[i] || entry.ActorID != admin.AdminID || entry.TargetType != model.AccountTypeMerchant {
+			t.Fatalf("audit[%d]=%+v want action %s", i, entry, wantActions[i])
+		}
+	}
+}
+
+func TestMerchantVerification_RegisterToApproval(t *testing.T) {
+	merchants := &verificationMerchantRepo{merchants: map[int64]*model.Merchant{
+		1: {ID: 1, Username: "other", Email: "other@example.com", Phone: "13900139000", BusinessLicense: "91350100M000100Y43",
+			IsActive: true, VerificationStatus: model.VerificationApproved},
+	}}
+	merchantSvc := NewMerchantService(MerchantServiceDependencies{MerchantRepo: merchants})
+	svc := NewMerchantVerificationService(MerchantVerificationServiceDependencies{
+		MerchantRepo: merchants,
+		DocumentRepo: &memDocumentRepo{},
+		AuditRepo:    &fakeAuditLogRepo{},
+		Storage:      storage.NewLocalStorage(t.TempDir()),
+		TxManager:    passthroughTxManager{},
+	})
+	ctx := context.Background()
+	admin := AdminActor{AdminID: 1, ClientIP: "10.0.0.1"}
+
+	merchant := &model.Merchant{Username: "laowang", Email: "laowang@example.com", Phone: "13800138000", CompanyName: "老王面馆"}
+	if err := merchantSvc.RegisterMerchant(ctx, merchant); err != nil {
+		t.Fatalf("RegisterMerchant: %v", err)
+	}
+	id := merchant.ID
+
+	// 注册时没有营业执照号，须先填写审核信息
+	if err := svc.SubmitVerification(ctx, id); !errors.Is(err, ErrValidationFailed) {
+		t.Fatalf("submit without license number err=%v want ErrValidationFailed", err)
+	}
+	app := func(license string) error {
+		return svc.UpdateApplication(ctx, id, MerchantApplication{CompanyName: "老王面馆（总店）", BusinessLicense: license})
+	}
+	if err := app("not-a-license"); !errors.Is(err, ErrValidationFailed) {
+		t.Fatalf("invalid license err=%v want ErrValidationFailed", err)
+	}
+	if err := app("91350100M000100Y43"); !errors.Is(err, ErrBusinessLicenseTaken) {
+		t.Fatalf("taken license err=%v want ErrBusinessLicenseTaken", err)
+	}
+	if err := app(" 91350100m000200y43 "); err != nil {
+		t.Fatalf("UpdateApplication: %v", err)
+	}
+	verification, err := svc.GetVerification(ctx, id)
+	if err != nil || verification.BusinessLicense != "91350100M000200Y43" || verification.CompanyName != "老王面馆（总店）" {
+		t.Fatalf("GetVerification=%+v err=%v", verification, err)
+	}
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+	if _, err := svc.UploadDocument(ctx, id, DocumentUpload{
+		DocType: model.MerchantDocBusinessLicense, FileName: "license.png",
+		Size: int64(len(png)), Content: bytes.NewReader(png),
+	}); err != nil {
+		t.Fatalf("UploadDocument: %v", err)
+	}
+	if err := svc.SubmitVerification(ctx, id); err != nil {
+		t.Fatalf("SubmitVerification: %v", err)
+	}
+	if err := app("91350100M000200Y43"); !errors.Is(err, ErrMerchantProfileLocked) {
+		t.Fatalf("edit after submit err=%v want ErrMerchantProfileLocked", err)
+	}
+	if err := svc.StartReview(ctx, admin, id); err != nil {
+		t.Fatalf("StartReview: %v", err)
+	}
+	if err := svc.ApproveVerification(ctx, admin, id, ""); err != nil {
+		t.Fatalf("ApproveVerification: %v", err)
+	}
+	if !merchants.merchants[id].CanTrade() {
+		t.Fatalf("registered merchant should trade after approval: %+v", merchants.merchants[id])
+	}
+}
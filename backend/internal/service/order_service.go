@@ -0,0 +1,515 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+)
+
+// #region 服务定义
+
+// OrderActor 订单操作方（来自JWT声明）
+type OrderActor struct {
+	UserType string
+	UserID   int64
+}
+
+// OrderServiceInterface 订单服务接口
+type OrderServiceInterface interface {
+	// 下单与查询
+	CreateOrder(ctx context.Context, userID int64, order *model.Order) error
+	GetOrder(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error)
+	ListOrders(ctx context.Context, actor OrderActor, status string, offset, limit int) ([]*model.Order, int64, error)
+
+	// 状态流转
+	AcceptOrder(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error)
+	MarkOrderPrepared(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error)
+	PickUpOrder(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error)
+	DeliverOrder(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error)
+	CancelOrder(ctx context.Context, actor OrderActor, orderID int64, reason string) (*model.Order, error)
+	// DispatchOrder 商家（或其员工）为已出餐且未指派的订单重新派单
+	DispatchOrder(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error)
+
+	// 评价
+	RateOrder(ctx context.Context, actor OrderActor, orderID int64, rating float32) (*model.Order, error)
+}
+
+// OrderService 订单服务实现
+type OrderService struct {
+	orderRepo    repository.OrderRepositoryInterface
+	merchantRepo repository.MerchantRepositoryInterface
+	employeeRepo repository.EmployeeRepositoryInterface
+	riderRepo    repository.RiderRepositoryInterface
+	dispatcher   Dispatcher
+	txManager    database.TxManager
+
+	catalogRepo repository.CatalogRepositoryInterface
+}
+
+// #endregion
+
+// #region 构造函数和依赖注入
+
+// OrderServiceDependencies 订单服务依赖
+type OrderServiceDependencies struct {
+	OrderRepo    repository.OrderRepositoryInterface
+	MerchantRepo repository.MerchantRepositoryInterface
+	EmployeeRepo repository.EmployeeRepositoryInterface
+	RiderRepo    repository.RiderRepositoryInterface
+	Dispatcher   Dispatcher // 可选，出餐后自动派单
+	TxManager    database.TxManager
+
+	CatalogRepo repository.CatalogRepositoryInterface // 下单定价与库存扣减
+}
+
+// NewOrderService 创建订单服务实例
+func NewOrderService(deps OrderServiceDependencies) OrderServiceInterface {
+	return &OrderService{
+		orderRepo:    deps.OrderRepo,
+		merchantRepo: deps.MerchantRepo,
+		employeeRepo: deps.EmployeeRepo,
+		riderRepo:    deps.RiderRepo,
+		dispatcher:   deps.Dispatcher,
+		txManager:    deps.TxManager,
+		catalogRepo:  deps.CatalogRepo,
+	}
+}
+
+// #endregion
+
+// #region 下单与查询
+
+// CreateOrder 用户下单：明细只取商品ID与数量，名称与单价取自商家商品目录，
+// 取货地址取自商家门店地址；库存扣减与订单写入在同一事务中完成
+func (s *OrderService) CreateOrder(ctx context.Context, userID int64, order *model.Order) error {
+	if order == nil {
+		return ErrOrderNil
+	}
+	if userID <= 0 {
+		return ErrInvalidUserID
+	}
+
+	order.ID = 0
+	order.UserID = userID
+	order.RiderID = nil
+	order.Status = model.OrderStatusCreated
+
+	orderNo, err := generateOrderNo(time.Now())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
+	}
+	order.OrderNo = orderNo
+
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		merchant, err := s.merchantRepo.GetByID(ctx, order.MerchantID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrMerchantNotFound, err)
+		}
+		if !merchant.CanTrade() {
+			return ErrMerchantNotAccepting
+		}
+		if !merchant.HasStoreLocation() {
+			return ErrMerchantLocationMissing
+		}
+		order.PickupAddress = merchant.StoreAddress
+		order.PickupLat = merchant.StoreLat
+		order.PickupLng = merchant.StoreLng
+
+		if err := s.priceOrderItems(ctx, order); err != nil {
+			return err
+		}
+		order.CalculateAmounts()
+		if err := order.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+		}
+
+		if err := s.orderRepo.Create(ctx, order); err != nil {
+			return fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.logOrderCreated(order)
+	return nil
+}
+
+// priceOrderItems 按商家商品目录填充明细的名称、规格与单价并扣减库存
+// 商品须属于下单商家，所选规格须满足规格组规则，规格加价计入单价；客户端提交的名称、单价与规格快照一律忽略
+func (s *OrderService) priceOrderItems(ctx context.Context, order *model.Order) error {
+	if len(order.Items) == 0 {
+		return fmt.Errorf("%w: %v", ErrValidationFailed, model.ErrOrderItemsEmpty)
+	}
+
+	for i := range order.Items {
+		line := &order.Items[i]
+		item, err := s.catalogRepo.GetItem(ctx, order.MerchantID, line.MenuItemID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrOrderItemNotFound, err)
+		}
+		if !item.IsSellable() {
+			return ErrOrderItemUnavailable
+		}
+		if line.Quantity <= 0 {
+			return fmt.Errorf("%w: %v", ErrValidationFailed, model.ErrInvalidOrderItem)
+		}
+
+		optionIDs := make([]int64, 0, len(line.Options))
+		for _, option := range line.Options {
+			optionIDs = append(optionIDs, option.OptionID)
+		}
+		options, priceDelta, err := item.SelectOptions(optionIDs)
+		if err != nil {
+			if errors.Is(err, model.ErrOptionUnavailable) {
+				return fmt.Errorf("%w: %v", ErrOrderItemUnavailable, err)
+			}
+			return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+		}
+		line.Name = item.Name
+		line.UnitPrice = item.Price + priceDelta
+		line.Options = options
+
+		if err := s.catalogRepo.DeductItemStock(ctx, order.MerchantID, item.ID, line.Quantity); err != nil {
+			if errors.Is(err, repository.ErrMenuItemUnavailable) {
+				return ErrOrderItemUnavailable
+			}
+			return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+		}
+	}
+	return nil
+}
+
+// GetOrder 获取订单详情，仅订单相关方可见
+func (s *OrderService) GetOrder(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error) {
+	order, err := s.loadOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkParticipant(ctx, actor, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ListOrders 按操作方身份分页获取订单
+func (s *OrderService) ListOrders(ctx context.Context, actor OrderActor, status string, offset, limit int) ([]*model.Order, int64, error) {
+	if offset < 0 || limit <= 0 {
+		return nil, 0, ErrPaginationInvalid
+	}
+
+	switch actor.UserType {
+	case model.OrderActorUser:
+		return s.orderRepo.ListByUser(ctx, actor.UserID, status, offset, limit)
+	case model.OrderActorMerchant:
+		return s.orderRepo.ListByMerchant(ctx, actor.UserID, status, offset, limit)
+	case model.OrderActorEmployee:
+		merchantID, err := s.employeeMerchantID(ctx, actor.UserID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return s.orderRepo.ListByMerchant(ctx, merchantID, status, offset, limit)
+	case model.OrderActorRider:
+		return s.orderRepo.ListByRider(ctx, actor.UserID, status, offset, limit)
+	default:
+		return nil, 0, ErrOrderAccessDenied
+	}
+}
+
+// #endregion
+
+// #region 状态流转
+
+// AcceptOrder 商家（或其员工）接单
+func (s *OrderService) AcceptOrder(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error) {
+	return s.transition(ctx, actor, orderID, model.OrderStatusAccepted, nil)
+}
+
+// MarkOrderPrepared 商家（或其员工）标记出餐完成
+// 订单尚未指派配送员时触发派单；派单失败不影响出餐结果，商家可稍后重新派单
+func (s *OrderService) MarkOrderPrepared(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error) {
+	order, err := s.transition(ctx, actor, orderID, model.OrderStatusPrepared, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.dispatcher != nil && order.RiderID == nil {
+		if err := s.dispatcher.Dispatch(ctx, order.ID); err != nil {
+			log.Printf("订单派单失败 - 订单号: %s, 错误: %v, 时间: %s",
+				order.OrderNo, err, time.Now().Format("2006-01-02 15:04:05"))
+		}
+	}
+	return order, nil
+}
+
+// PickUpOrder 配送员取货，仅限已指派给该配送员的订单
+func (s *OrderService) PickUpOrder(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error) {
+	return s.transition(ctx, actor, orderID, model.OrderStatusPickedUp, nil)
+}
+
+// DeliverOrder 配送员确认送达，订单状态与配送员订单数在同一事务中更新
+func (s *OrderService) DeliverOrder(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error) {
+	order, err := s.loadOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	fromStatus := order.Status
+	if err := s.authorizeTransition(ctx, actor, order, model.OrderStatusDelivered); err != nil {
+		return nil, err
+	}
+	order.ApplyTransition(model.OrderStatusDelivered, time.Now())
+
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.orderRepo.UpdateStatus(ctx, order, fromStatus); err != nil {
+			return err
+		}
+		return s.riderRepo.IncrementTotalOrders(ctx, *order.RiderID)
+	}); err != nil {
+		return nil, wrapOrderUpdateError(err)
+	}
+
+	s.logOrderTransition(order, fromStatus, actor)
+	return order, nil
+}
+
+// CancelOrder 取消订单，订单状态与库存退回在同一事务中更新
+// 用户仅可在商家接单前取消；商家（含员工）在配送员取货前均可取消
+func (s *OrderService) CancelOrder(ctx context.Context, actor OrderActor, orderID int64, reason string) (*model.Order, error) {
+	order, err := s.loadOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	fromStatus := order.Status
+	if err := s.authorizeTransition(ctx, actor, order, model.OrderStatusCancelled); err != nil {
+		return nil, err
+	}
+	order.CancelReason = reason
+	order.CancelledBy = actor.UserType
+	order.ApplyTransition(model.OrderStatusCancelled, time.Now())
+
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.orderRepo.UpdateStatus(ctx, order, fromStatus); err != nil {
+			return err
+		}
+		for _, line := range order.Items {
+			if line.MenuItemID <= 0 {
+				continue
+			}
+			if err := s.catalogRepo.RestoreItemStock(ctx, order.MerchantID, line.MenuItemID, line.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, wrapOrderUpdateError(err)
+	}
+
+	s.logOrderTransition(order, fromStatus, actor)
+	return order, nil
+}
+
+// DispatchOrder 重新派单：用于首次派单失败或候选配送员均未接单的订单
+func (s *OrderService) DispatchOrder(ctx context.Context, actor OrderActor, orderID int64) (*model.Order, error) {
+	order, err := s.loadOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.UserType != model.OrderActorMerchant && actor.UserType != model.OrderActorEmployee {
+		return nil, ErrOrderAccessDenied
+	}
+	if err := s.checkParticipant(ctx, actor, order); err != nil {
+		return nil, err
+	}
+	if order.Status != model.OrderStatusPrepared || order.RiderID != nil {
+		return nil, ErrOrderNotDispatchable
+	}
+	if s.dispatcher == nil {
+		return nil, ErrNoAvailableRiders
+	}
+
+	if err := s.dispatcher.Dispatch(ctx, order.ID); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// transition 执行一次通用状态迁移：校验状态机与归属，按原状态条件更新
+func (s *OrderService) transition(ctx context.Context, actor OrderActor, orderID int64, to string, mutate func(order *model.Order) error) (*model.Order, error) {
+	order, err := s.loadOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	fromStatus := order.Status
+	if err := s.authorizeTransition(ctx, actor, order, to); err != nil {
+		return nil, err
+	}
+	if mutate != nil {
+		if err := mutate(order); err != nil {
+			return nil, err
+		}
+	}
+	order.ApplyTransition(to, time.Now())
+
+	if err := s.orderRepo.UpdateStatus(ctx, order, fromStatus); err != nil {
+		return nil, wrapOrderUpdateError(err)
+	}
+
+	s.logOrderTransition(order, fromStatus, actor)
+	return order, nil
+}
+
+// authorizeTransition 校验状态机规则及操作方与订单的归属关系
+func (s *OrderService) authorizeTransition(ctx context.Context, actor OrderActor, order *model.Order, to string) error {
+	if err := order.CheckTransition(to, actor.UserType); err != nil {
+		if errors.Is(err, model.ErrOrderTransitionForbidden) {
+			return fmt.Errorf("%w: %v", ErrOrderAccessDenied, err)
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidOrderTransition, err)
+	}
+	return s.checkParticipant(ctx, actor, order)
+}
+
+// #endregion
+
+// #region 评价
+
+// RateOrder 用户在送达后为配送员评分，每个订单仅可评价一次
+func (s *OrderService) RateOrder(ctx context.Context, actor OrderActor, orderID int64, rating float32) (*model.Order, error) {
+	if rating < model.MinRiderRating || rating > model.MaxRiderRating {
+		return nil, model.ErrInvalidRiderRating
+	}
+
+	order, err := s.loadOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.UserType != model.OrderActorUser {
+		return nil, ErrOrderAccessDenied
+	}
+	if err := s.checkParticipant(ctx, actor, order); err != nil {
+		return nil, err
+	}
+	if order.Status != model.OrderStatusDelivered || order.RiderID == nil {
+		return nil, ErrOrderNotDelivered
+	}
+	if order.RiderRating > 0 {
+		return nil, ErrOrderAlreadyRated
+	}
+	order.RiderRating = rating
+
+	// 以"未评价"为前提条件更新，防止重复计分
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.orderRepo.RateRider(ctx, order.ID, rating); err != nil {
+			return err
+		}
+		return s.riderRepo.ApplyRating(ctx, *order.RiderID, rating)
+	}); err != nil {
+		if errors.Is(err, repository.ErrOrderStatusConflict) {
+			return nil, ErrOrderAlreadyRated
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+	}
+
+	return order, nil
+}
+
+// #endregion
+
+// #region 辅助方法
+
+// loadOrder 加载订单并统一错误类型
+func (s *OrderService) loadOrder(ctx context.Context, orderID int64) (*model.Order, error) {
+	if orderID <= 0 {
+		return nil, ErrInvalidOrderID
+	}
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrOrderNotFound, err)
+	}
+	return order, nil
+}
+
+// checkParticipant 校验操作方是否为订单相关方
+func (s *OrderService) checkParticipant(ctx context.Context, actor OrderActor, order *model.Order) error {
+	switch actor.UserType {
+	case model.OrderActorUser:
+		if order.UserID == actor.UserID {
+			return nil
+		}
+	case model.OrderActorMerchant:
+		if order.MerchantID == actor.UserID {
+			return nil
+		}
+	case model.OrderActorEmployee:
+		merchantID, err := s.employeeMerchantID(ctx, actor.UserID)
+		if err != nil {
+			return err
+		}
+		if order.MerchantID == merchantID {
+			return nil
+		}
+	case model.OrderActorRider:
+		if order.RiderID != nil && *order.RiderID == actor.UserID {
+			return nil
+		}
+	}
+	return ErrOrderAccessDenied
+}
+
+// employeeMerchantID 获取在职员工所属商家
+func (s *OrderService) employeeMerchantID(ctx context.Context, employeeID int64) (int64, error) {
+	employee, err := s.employeeRepo.GetByID(ctx, employeeID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrEmployeeNotFound, err)
+	}
+	if !employee.IsActive {
+		return 0, ErrOrderAccessDenied
+	}
+	return employee.MerchantID, nil
+}
+
+// wrapOrderUpdateError 将并发冲突与其他写入错误区分开
+func wrapOrderUpdateError(err error) error {
+	if errors.Is(err, repository.ErrOrderStatusConflict) {
+		return ErrOrderStatusConflict
+	}
+	return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+}
+
+// generateOrderNo 生成订单号：时间戳（秒）+ 6位随机数
+func generateOrderNo(now time.Time) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%06d", now.Format("20060102150405"), n.Int64()), nil
+}
+
+// #endregion
+
+// #region 日志记录
+
+// logOrderCreated 记录下单日志
+func (s *OrderService) logOrderCreated(order *model.Order) {
+	log.Printf("订单创建 - 订单号: %s, 用户ID: %d, 商家ID: %d, 金额: %d分, 时间: %s",
+		order.OrderNo, order.UserID, order.MerchantID, order.TotalAmount, time.Now().Format("2006-01-02 15:04:05"))
+}
+
+// logOrderTransition 记录订单状态变更日志
+func (s *OrderService) logOrderTransition(order *model.Order, fromStatus string, actor OrderActor) {
+	log.Printf("订单状态变更 - 订单号: %s, %s -> %s, 操作方: %s(%d), 时间: %s",
+		order.OrderNo, fromStatus, order.Status, actor.UserType, actor.UserID, time.Now().Format("2006-01-02 15:04:05"))
+}
+
+// #endregion
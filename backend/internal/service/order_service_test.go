@@ -0,0 +1,460 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+)
+
+// fakeOrderRepo 内存订单仓库
+type fakeOrderRepo struct {
+	repository.OrderRepositoryInterface
+	orders map[int64]model.Order
+
+	afterGet  func() // 模拟读取订单之后的并发写入
+	assignErr error  // AssignRider 返回的写入错误
+}
+
+func (f *fakeOrderRepo) Create(ctx context.Context, order *model.Order) error {
+	order.ID = int64(len(f.orders) + 1)
+	f.orders[order.ID] = *order
+	return nil
+}
+
+func (f *fakeOrderRepo) GetByID(ctx context.Context, id int64) (*model.Order, error) {
+	order, ok := f.orders[id]
+	if !ok {
+		return nil, repository.ErrOrderNotFound
+	}
+	if f.afterGet != nil {
+		f.afterGet()
+	}
+	return &order, nil
+}
+
+func (f *fakeOrderRepo) UpdateStatus(ctx context.Context, order *model.Order, fromStatus string) error {
+	current := f.orders[order.ID]
+	if current.Status != fromStatus || !sameRider(current.RiderID, order.RiderID) {
+		return repository.ErrOrderStatusConflict
+	}
+	f.orders[order.ID] = *order
+	return nil
+}
+
+func sameRider(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// fakeTxManager 模拟事务：fn 失败时回滚订单快照
+type fakeTxManager struct {
+	orderRepo *fakeOrderRepo
+}
+
+func (m fakeTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	snapshot := make(map[int64]model.Order, len(m.orderRepo.orders))
+	for id, order := range m.orderRepo.orders {
+		snapshot[id] = order
+	}
+	if err := fn(ctx); err != nil {
+		m.orderRepo.orders = snapshot
+		return err
+	}
+	return nil
+}
+
+// fakeRiderRepo 记录送达计数
+type fakeRiderRepo struct {
+	repository.RiderRepositoryInterface
+	riders      map[int64]*model.Rider
+	incrementFn func(id int64) error
+}
+
+func (f *fakeRiderRepo) GetByID(ctx context.Context, id int64) (*model.Rider, error) {
+	rider, ok := f.riders[id]
+	if !ok {
+		return nil, repository.ErrRiderNotFound
+	}
+	return rider, nil
+}
+
+func (f *fakeRiderRepo) IncrementTotalOrders(ctx context.Context, id int64) error {
+	if f.incrementFn != nil {
+		return f.incrementFn(id)
+	}
+	f.riders[id].TotalOrders++
+	return nil
+}
+
+type fakeMerchantRepo struct {
+	repository.MerchantRepositoryInterface
+}
+
+func (fakeMerchantRepo) GetByID(ctx context.Context, id int64) (*model.Merchant, error) {
+	return &model.Merchant{ID: id, IsActive: true, VerificationStatus: model.VerificationApproved,
+		StoreAddress: "门店", StoreLat: 39.9, StoreLng: 116.4}, nil
+}
+
+// fakeCatalogRepo 内存商品目录
+type fakeCatalogRepo struct {
+	repository.CatalogRepositoryInterface
+	items map[int64]*model.MenuItem
+}
+
+func (f *fakeCatalogRepo) GetItem(ctx context.Context, merchantID, id int64) (*model.MenuItem, error) {
+	item, ok := f.items[id]
+	if !ok || item.MerchantID != merchantID {
+		return nil, repository.ErrMenuItemNotFound
+	}
+	return item, nil
+}
+
+func (f *fakeCatalogRepo) DeductItemStock(ctx context.Context, merchantID, id int64, quantity int) error {
+	item, err := f.GetItem(ctx, merchantID, id)
+	if err != nil || !item.IsAvailable || (item.Stock != nil && *item.Stock < quantity) {
+		return repository.ErrMenuItemUnavailable
+	}
+	if item.Stock != nil {
+		*item.Stock -= quantity
+	}
+	return nil
+}
+
+func (f *fakeCatalogRepo) RestoreItemStock(ctx context.Context, merchantID, id int64, quantity int) error {
+	if item, err := f.GetItem(ctx, merchantID, id); err == nil && item.Stock != nil {
+		*item.Stock += quantity
+	}
+	return nil
+}
+
+type fakeEmployeeRepo struct {
+	repository.EmployeeRepositoryInterface
+}
+
+func (fakeEmployeeRepo) GetByID(ctx context.Context, id int64) (*model.Employee, error) {
+	// 员工 100 属于商家 10，员工 200 属于商家 20
+	return &model.Employee{ID: id, MerchantID: id / 10, IsActive: true}, nil
+}
+
+func newTestCatalogRepo() *fakeCatalogRepo {
+	soupStock := 5
+	return &fakeCatalogRepo{items: map[int64]*model.MenuItem{
+		1: {ID: 1, MerchantID: 10, Name: "米饭", Price: 200, IsAvailable: true},
+		2: {ID: 2, MerchantID: 10, Name: "汤", Price: 600, Stock: &soupStock, IsAvailable: true},
+		3: {ID: 3, MerchantID: 20, Name: "面条", Price: 1500, IsAvailable: true},
+		4: {ID: 4, MerchantID: 10, Name: "奶茶", Price: 1000, IsAvailable: true, OptionGroups: []model.MenuOptionGroup{
+			{ID: 1, Name: "杯型", Required: true, MinSelect: 1, MaxSelect: 1, Options: []model.MenuOption{
+				{ID: 11, Name: "中杯", IsAvailable: true},
+				{ID: 12, Name: "大杯", PriceDelta: 300, IsAvailable: true},
+			}},
+			{ID: 2, Name: "小料", MaxSelect: 2, Options: []model.MenuOption{
+				{ID: 21, Name: "珍珠", PriceDelta: 200, IsAvailable: true},
+				{ID: 22, Name: "椰果", PriceDelta: 150, IsAvailable: true},
+				{ID: 23, Name: "布丁", PriceDelta: 250, IsAvailable: false},
+			}},
+		}},
+	}}
+}
+
+func newOrderTestService() (OrderServiceInterface, *fakeOrderRepo, *fakeRiderRepo) {
+	svc, orderRepo, riderRepo, _ := newOrderTestServiceWithCatalog()
+	return svc, orderRepo, riderRepo
+}
+
+func newOrderTestServiceWithCatalog() (OrderServiceInterface, *fakeOrderRepo, *fakeRiderRepo, *fakeCatalogRepo) {
+	riderRepo := &fakeRiderRepo{riders: map[int64]*model.Rider{
+		7: {ID: 7, IsActive: true, IsOnline: true},
+		8: {ID: 8, IsActive: true, IsOnline: true},
+	}}
+	orderRepo := &fakeOrderRepo{orders: map[int64]model.Order{}}
+	catalogRepo := newTestCatalogRepo()
+	svc := NewOrderService(OrderServiceDependencies{
+		OrderRepo:    orderRepo,
+		MerchantRepo: fakeMerchantRepo{},
+		EmployeeRepo: fakeEmployeeRepo{},
+		RiderRepo:    riderRepo,
+		TxManager:    fakeTxManager{orderRepo: orderRepo},
+		CatalogRepo:  catalogRepo,
+	})
+	return svc, orderRepo, riderRepo, catalogRepo
+}
+
+func placeTestOrder(t *testing.T, svc OrderServiceInterface) *model.Order {
+	t.Helper()
+	order := &model.Order{
+		MerchantID:      10,
+		Items:           []model.OrderItem{{MenuItemID: 1, Quantity: 2}, {MenuItemID: 2, Quantity: 1}},
+		DeliveryFee:     500,
+		DeliveryAddress: "B",
+	}
+	if err := svc.CreateOrder(context.Background(), 1, order); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if order.Status != model.OrderStatusCreated || order.ItemsAmount != 1000 || order.TotalAmount != 1500 || order.OrderNo == "" {
+		t.Fatalf("unexpected order: %+v", order)
+	}
+	return order
+}
+
+func TestOrderService_CreateOrderPricesFromCatalog(t *testing.T) {
+	svc, orderRepo, _, catalogRepo := newOrderTestServiceWithCatalog()
+	ctx := context.Background()
+
+	// 客户端篡改的名称、单价与取货地址一律被目录与门店数据覆盖
+	order := &model.Order{
+		MerchantID:      10,
+		Items:           []model.OrderItem{{MenuItemID: 2, Name: "免费汤", UnitPrice: 1, Quantity: 2}},
+		PickupAddress:   "伪造地址",
+		PickupLat:       1,
+		PickupLng:       1,
+		DeliveryAddress: "B",
+	}
+	if err := svc.CreateOrder(ctx, 1, order); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	saved := orderRepo.orders[order.ID]
+	if item := saved.Items[0]; item.Name != "汤" || item.UnitPrice != 600 || item.Amount != 1200 || saved.ItemsAmount != 1200 {
+		t.Fatalf("order not priced from catalog: %+v", saved)
+	}
+	if saved.PickupAddress != "门店" || saved.PickupLat != 39.9 || saved.PickupLng != 116.4 {
+		t.Fatalf("pickup not snapshotted from merchant: %+v", saved)
+	}
+	if stock := *catalogRepo.items[2].Stock; stock != 3 {
+		t.Fatalf("stock=%d want 3", stock)
+	}
+
+	// 取消订单退回库存
+	if _, err := svc.CancelOrder(ctx, OrderActor{UserType: model.OrderActorUser, UserID: 1}, order.ID, ""); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if stock := *catalogRepo.items[2].Stock; stock != 5 {
+		t.Fatalf("stock after cancel=%d want 5", stock)
+	}
+
+	// 库存不足
+	soldOut := &model.Order{MerchantID: 10, Items: []model.OrderItem{{MenuItemID: 2, Quantity: 6}}, DeliveryAddress: "B"}
+	if err := svc.CreateOrder(ctx, 1, soldOut); !errors.Is(err, ErrOrderItemUnavailable) {
+		t.Fatalf("sold out err=%v want ErrOrderItemUnavailable", err)
+	}
+}
+
+func TestOrderService_CreateOrderPricesOptions(t *testing.T) {
+	svc, orderRepo, _ := newOrderTestService()
+	ctx := context.Background()
+
+	withOptions := func(ids ...int64) *model.Order {
+		line := model.OrderItem{MenuItemID: 4, Quantity: 2}
+		for _, id := range ids {
+			// 客户端提交的规格名称与加价一律忽略
+			line.Options = append(line.Options, model.OrderItemOption{OptionID: id, Name: "伪造", PriceDelta: -1000})
+		}
+		return &model.Order{MerchantID: 10, Items: []model.OrderItem{line}, DeliveryAddress: "B"}
+	}
+
+	order := withOptions(12, 21, 22)
+	if err := svc.CreateOrder(ctx, 1, order); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	saved := orderRepo.orders[order.ID].Items[0]
+	if saved.UnitPrice != 1650 || saved.Amount != 3300 {
+		t.Fatalf("unit price=%d amount=%d want 1650/3300", saved.UnitPrice, saved.Amount)
+	}
+	if len(saved.Options) != 3 || saved.Options[0] != (model.OrderItemOption{OptionID: 12, GroupName: "杯型", Name: "大杯", PriceDelta: 300}) {
+		t.Fatalf("options not snapshotted from catalog: %+v", saved.Options)
+	}
+
+	cases := []struct {
+		name string
+		ids  []int64
+		want error
+	}{
+		{"required group missing", []int64{21}, ErrValidationFailed},
+		{"too many in group", []int64{11, 12}, ErrValidationFailed},
+		{"duplicate option", []int64{11, 21, 21}, ErrValidationFailed},
+		{"foreign option", []int64{11, 99}, ErrValidationFailed},
+		{"unavailable option", []int64{11, 23}, ErrOrderItemUnavailable},
+	}
+	for _, tc := range cases {
+		if err := svc.CreateOrder(ctx, 1, withOptions(tc.ids...)); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err=%v want %v", tc.name, err, tc.want)
+		}
+	}
+
+	// 无规格组的商品不能附带规格
+	plain := &model.Order{MerchantID: 10, Items: []model.OrderItem{{MenuItemID: 1, Quantity: 1, Options: []model.OrderItemOption{{OptionID: 11}}}}, DeliveryAddress: "B"}
+	if err := svc.CreateOrder(ctx, 1, plain); !errors.Is(err, ErrValidationFailed) {
+		t.Fatalf("plain item with options err=%v want ErrValidationFailed", err)
+	}
+}
+
+func TestOrderService_CreateOrderRejectsForeignItem(t *testing.T) {
+	svc, orderRepo, _ := newOrderTestService()
+
+	// 商品 3 属于商家 20，不能在商家 10 下单
+	order := &model.Order{
+		MerchantID:      10,
+		Items:           []model.OrderItem{{MenuItemID: 1, Quantity: 1}, {MenuItemID: 3, Quantity: 1}},
+		DeliveryAddress: "B",
+	}
+	if err := svc.CreateOrder(context.Background(), 1, order); !errors.Is(err, ErrOrderItemNotFound) {
+		t.Fatalf("foreign item err=%v want ErrOrderItemNotFound", err)
+	}
+	if len(orderRepo.orders) != 0 {
+		t.Fatalf("order persisted despite foreign item: %+v", orderRepo.orders)
+	}
+}
+
+func TestOrderService_FullLifecycle(t *testing.T) {
+	svc, orderRepo, riderRepo := newOrderTestService()
+	ctx := context.Background()
+	order := placeTestOrder(t, svc)
+
+	user := OrderActor{UserType: model.OrderActorUser, UserID: 1}
+	merchant := OrderActor{UserType: model.OrderActorMerchant, UserID: 10}
+	employee := OrderActor{UserType: model.OrderActorEmployee, UserID: 100}
+	rider := OrderActor{UserType: model.OrderActorRider, UserID: 7}
+	otherRider := OrderActor{UserType: model.OrderActorRider, UserID: 8}
+
+	// 用户不能替商家接单
+	if _, err := svc.AcceptOrder(ctx, user, order.ID); !errors.Is(err, ErrOrderAccessDenied) {
+		t.Fatalf("user accept err=%v want ErrOrderAccessDenied", err)
+	}
+	// 其他商家的员工无权操作
+	if _, err := svc.AcceptOrder(ctx, OrderActor{UserType: model.OrderActorEmployee, UserID: 200}, order.ID); !errors.Is(err, ErrOrderAccessDenied) {
+		t.Fatalf("foreign employee accept err=%v want ErrOrderAccessDenied", err)
+	}
+	if _, err := svc.AcceptOrder(ctx, employee, order.ID); err != nil {
+		t.Fatalf("employee accept: %v", err)
+	}
+	// 接单后用户不能再取消
+	if _, err := svc.CancelOrder(ctx, user, order.ID, "不想要了"); !errors.Is(err, ErrOrderAccessDenied) {
+		t.Fatalf("user cancel after accept err=%v want ErrOrderAccessDenied", err)
+	}
+	// 未出餐不能取货
+	if _, err := svc.PickUpOrder(ctx, rider, order.ID); !errors.Is(err, ErrInvalidOrderTransition) {
+		t.Fatalf("early pickup err=%v want ErrInvalidOrderTransition", err)
+	}
+	if _, err := svc.MarkOrderPrepared(ctx, merchant, order.ID); err != nil {
+		t.Fatalf("prepared: %v", err)
+	}
+
+	// 未指派的订单不能被配送员取货认领，指派后只有该配送员可以取货
+	if _, err := svc.PickUpOrder(ctx, rider, order.ID); !errors.Is(err, ErrOrderAccessDenied) {
+		t.Fatalf("unassigned pickup err=%v want ErrOrderAccessDenied", err)
+	}
+	if err := orderRepo.AssignRider(ctx, order.ID, 7); err != nil {
+		t.Fatalf("AssignRider: %v", err)
+	}
+	if _, err := svc.PickUpOrder(ctx, otherRider, order.ID); !errors.Is(err, ErrOrderAccessDenied) {
+		t.Fatalf("foreign rider pickup err=%v want ErrOrderAccessDenied", err)
+	}
+
+	picked, err := svc.PickUpOrder(ctx, rider, order.ID)
+	if err != nil {
+		t.Fatalf("pickup: %v", err)
+	}
+	if picked.RiderID == nil || *picked.RiderID != 7 || picked.PickedUpAt == nil {
+		t.Fatalf("unexpected picked up order: %+v", picked)
+	}
+	// 只有指派的配送员可以送达，取货后商家不可取消
+	if _, err := svc.DeliverOrder(ctx, otherRider, order.ID); !errors.Is(err, ErrOrderAccessDenied) {
+		t.Fatalf("other rider deliver err=%v want ErrOrderAccessDenied", err)
+	}
+	if _, err := svc.CancelOrder(ctx, merchant, order.ID, ""); !errors.Is(err, ErrInvalidOrderTransition) {
+		t.Fatalf("cancel after pickup err=%v want ErrInvalidOrderTransition", err)
+	}
+
+	delivered, err := svc.DeliverOrder(ctx, rider, order.ID)
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if delivered.Status != model.OrderStatusDelivered || delivered.DeliveredAt == nil {
+		t.Fatalf("unexpected delivered order: %+v", delivered)
+	}
+	if riderRepo.riders[7].TotalOrders != 1 {
+		t.Fatalf("TotalOrders=%d want 1", riderRepo.riders[7].TotalOrders)
+	}
+	if _, err := svc.DeliverOrder(ctx, rider, order.ID); !errors.Is(err, ErrInvalidOrderTransition) {
+		t.Fatalf("double deliver err=%v want ErrInvalidOrderTransition", err)
+	}
+}
+
+func TestOrderService_DeliverRollsBackWhenRiderUpdateFails(t *testing.T) {
+	svc, orderRepo, riderRepo := newOrderTestService()
+	ctx := context.Background()
+	order := placeTestOrder(t, svc)
+
+	merchant := OrderActor{UserType: model.OrderActorMerchant, UserID: 10}
+	rider := OrderActor{UserType: model.OrderActorRider, UserID: 7}
+	for _, step := range []func() (*model.Order, error){
+		func() (*model.Order, error) { return svc.AcceptOrder(ctx, merchant, order.ID) },
+		func() (*model.Order, error) { return svc.MarkOrderPrepared(ctx, merchant, order.ID) },
+		func() (*model.Order, error) { return nil, orderRepo.AssignRider(ctx, order.ID, 7) },
+		func() (*model.Order, error) { return svc.PickUpOrder(ctx, rider, order.ID) },
+	} {
+		if _, err := step(); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	riderRepo.incrementFn = func(int64) error { return errors.New("db down") }
+	if _, err := svc.DeliverOrder(ctx, rider, order.ID); !errors.Is(err, ErrDataUpdateFailed) {
+		t.Fatalf("deliver err=%v want ErrDataUpdateFailed", err)
+	}
+	if status := orderRepo.orders[order.ID].Status; status != model.OrderStatusPickedUp {
+		t.Fatalf("status=%s want rollback to picked_up", status)
+	}
+}
+
+func TestOrderService_UserCancelBeforeAccept(t *testing.T) {
+	svc, _, _ := newOrderTestService()
+	ctx := context.Background()
+	order := placeTestOrder(t, svc)
+
+	if _, err := svc.CancelOrder(ctx, OrderActor{UserType: model.OrderActorUser, UserID: 2}, order.ID, ""); !errors.Is(err, ErrOrderAccessDenied) {
+		t.Fatalf("other user cancel err=%v want ErrOrderAccessDenied", err)
+	}
+	cancelled, err := svc.CancelOrder(ctx, OrderActor{UserType: model.OrderActorUser, UserID: 1}, order.ID, "下错单")
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if cancelled.Status != model.OrderStatusCancelled || cancelled.CancelledBy != model.OrderActorUser || cancelled.CancelReason != "下错单" {
+		t.Fatalf("unexpected cancelled order: %+v", cancelled)
+	}
+}
+
+func TestOrderService_UpdateConflictsWithConcurrentAssignment(t *testing.T) {
+	svc, orderRepo, _ := newOrderTestService()
+	ctx := context.Background()
+	order := placeTestOrder(t, svc)
+
+	merchant := OrderActor{UserType: model.OrderActorMerchant, UserID: 10}
+	for _, step := range []func() (*model.Order, error){
+		func() (*model.Order, error) { return svc.AcceptOrder(ctx, merchant, order.ID) },
+		func() (*model.Order, error) { return svc.MarkOrderPrepared(ctx, merchant, order.ID) },
+	} {
+		if _, err := step(); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+
+	// 商家读取订单后、写入前，派单已把订单指派给配送员 7：取消不得覆盖指派
+	orderRepo.afterGet = func() {
+		orderRepo.afterGet = nil
+		if err := orderRepo.AssignRider(ctx, order.ID, 7); err != nil {
+			t.Fatalf("AssignRider: %v", err)
+		}
+	}
+	if _, err := svc.CancelOrder(ctx, merchant, order.ID, ""); !errors.Is(err, ErrOrderStatusConflict) {
+		t.Fatalf("cancel err=%v want ErrOrderStatusConflict", err)
+	}
+	saved := orderRepo.orders[order.ID]
+	if saved.Status != model.OrderStatusPrepared || saved.RiderID == nil || *saved.RiderID != 7 {
+		t.Fatalf("assignment overwritten: %+v", saved)
+	}
+}
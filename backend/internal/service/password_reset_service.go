@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/config"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/auth"
+	"github.com/Hermitf/the-pass/pkg/crypto"
+	"github.com/Hermitf/the-pass/pkg/mail"
+	"github.com/Hermitf/the-pass/pkg/sms"
+	"github.com/Hermitf/the-pass/pkg/validator"
+)
+
+// #region 服务定义
+
+// 找回密码的校验渠道
+const (
+	ResetChannelSMS   = "sms"
+	ResetChannelEmail = "email"
+)
+
+// 找回密码凭证默认有效期
+const (
+	defaultResetEmailTokenTTL = 30 * time.Minute
+	defaultResetTicketTTL     = 10 * time.Minute
+)
+
+// PasswordResetServiceInterface 找回密码服务接口（用户、员工、商家、配送员通用）
+//
+// 流程：RequestReset 发送短信验证码或邮件令牌 → VerifyReset 校验后换取改密票据 → CompleteReset 设置新密码
+type PasswordResetServiceInterface interface {
+	// RequestReset 发起找回密码；账号不存在或已停用时同样返回成功，避免被用来探测账号
+	RequestReset(ctx context.Context, userType, channel, identifier string) error
+	// VerifyReset 校验短信验证码（identifier 为手机号）或邮件令牌，通过后返回短时有效的改密票据
+	VerifyReset(ctx context.Context, userType, channel, identifier, code string) (string, error)
+	// CompleteReset 凭改密票据设置新密码，并吊销该账号的全部会话
+	CompleteReset(ctx context.Context, userType, ticket, newPassword string) error
+}
+
+// resetAccount 找回密码所需的统一账号视图
+type resetAccount struct {
+	ID       int64
+	Email    string
+	IsActive bool
+}
+
+// resetAccounts 某一类账号的查找与改密操作
+type resetAccounts struct {
+	findByPhone        func(ctx context.Context, phone string) (*resetAccount, error)
+	findByEmail        func(ctx context.Context, email string) (*resetAccount, error)
+	updatePasswordHash func(ctx context.Context, id int64, passwordHash string) error
+
+	// findByID 凭证兑换后重新加载账号，确认签发凭证后账号未被停用
+	findByID func(ctx context.Context, id int64) (*resetAccount, error)
+}
+
+// PasswordResetService 找回密码服务实现
+type PasswordResetService struct {
+	accounts   map[string]resetAccounts
+	smsService *sms.Service
+	mailer     mail.Sender
+	tokenStore auth.PasswordResetStore
+	jwtService JWTServiceInterface
+	cfg        config.PasswordResetConfig
+}
+
+// PasswordResetServiceDependencies 找回密码服务依赖项
+type PasswordResetServiceDependencies struct {
+	UserRepo     repository.UserRepositoryInterface
+	EmployeeRepo repository.EmployeeRepositoryInterface
+	MerchantRepo repository.MerchantRepositoryInterface
+	RiderRepo    repository.RiderRepositoryInterface
+	SMSService   *sms.Service            // 为空时不支持短信渠道
+	Mailer       mail.Sender             // 为空时不支持邮件渠道
+	TokenStore   auth.PasswordResetStore // 一次性凭证存储
+	JWTService   JWTServiceInterface     // 改密后吊销全部会话
+	Config       config.PasswordResetConfig
+}
+
+// #endregion
+
+// #region 构造函数
+
+// NewPasswordResetService 创建找回密码服务实例，仅为提供了仓库的账号类型开放找回密码
+func NewPasswordResetService(deps PasswordResetServiceDependencies) PasswordResetServiceInterface {
+	cfg := deps.Config
+	if cfg.EmailTokenTTL <= 0 {
+		cfg.EmailTokenTTL = defaultResetEmailTokenTTL
+	}
+	if cfg.TicketTTL <= 0 {
+		cfg.TicketTTL = defaultResetTicketTTL
+	}
+
+	accounts := make(map[string]resetAccounts)
+	if deps.UserRepo != nil {
+		accounts[smsRoleUser] = resetAccounts{
+			findByPhone:        viewUser(deps.UserRepo.GetUserByPhone),
+			findByEmail:        viewUser(deps.UserRepo.GetUserByEmail),
+			updatePasswordHash: deps.UserRepo.UpdatePasswordHash,
+			findByID: viewUser(func(ctx context.Context, id int64) (*model.User, error) {
+				return deps.UserRepo.GetUserByID(ctx, uint(id))
+			}),
+		}
+	}
+	if deps.EmployeeRepo != nil {
+		accounts[smsRoleEmployee] = resetAccounts{
+			findByPhone:        viewEmployee(deps.EmployeeRepo.GetByPhone),
+			findByEmail:        viewEmployee(deps.EmployeeRepo.GetByEmail),
+			updatePasswordHash: deps.EmployeeRepo.UpdatePasswordHash,
+			findByID:           viewEmployee(deps.EmployeeRepo.GetByID),
+		}
+	}
+	if deps.MerchantRepo != nil {
+		accounts[smsRoleMerchant] = resetAccounts{
+			findByPhone:        viewMerchant(deps.MerchantRepo.GetByPhone),
+			findByEmail:        viewMerchant(deps.MerchantRepo.GetByEmail),
+			updatePasswordHash: deps.MerchantRepo.UpdatePasswordHash,
+			findByID:           viewMerchant(deps.MerchantRepo.GetByID),
+		}
+	}
+	if deps.RiderRepo != nil {
+		accounts[smsRoleRider] = resetAccounts{
+			findByPhone:        viewRider(deps.RiderRepo.GetByPhone),
+			findByEmail:        viewRider(deps.RiderRepo.GetByEmail),
+			updatePasswordHash: deps.RiderRepo.UpdatePasswordHash,
+			findByID:           viewRider(deps.RiderRepo.GetByID),
+		}
+	}
+
+	return &PasswordResetService{
+		accounts:   accounts,
+		smsService: deps.SMSService,
+		mailer:     deps.Mailer,
+		tokenStore: deps.TokenStore,
+		jwtService: deps.JWTService,
+		cfg:        cfg,
+	}
+}
+
+// #endregion
+
+// #region 找回密码流程
+
+// RequestReset 发起找回密码
+// sms 渠道向手机号发送 reset_password 用途的验证码；email 渠道向邮箱发送一次性令牌
+func (s *PasswordResetService) RequestReset(ctx context.Context, userType, channel, identifier string) error {
+	accounts, ok := s.accounts[userType]
+	if !ok {
+		return ErrResetUserTypeInvalid
+	}
+
+	switch channel {
+	case ResetChannelSMS:
+		if !validator.IsPhone(identifier) {
+			return ErrPhoneInvalid
+		}
+		if s.smsService == nil {
+			return ErrResetUnavailable
+		}
+		if account, err := accounts.findByPhone(ctx, identifier); err != nil || !account.IsActive {
+			s.logResetIgnored(userType, channel, identifier)
+			return nil
+		}
+		return s.smsService.SendCode(ctx, sms.Scope{Role: userType, Purpose: sms.PurposeResetPassword}, identifier)
+
+	case ResetChannelEmail:
+		if !validator.IsEmail(identifier) {
+			return ErrEmailInvalid
+		}
+		if s.mailer == nil || s.tokenStore == nil {
+			return ErrResetUnavailable
+		}
+		account, err := accounts.findByEmail(ctx, identifier)
+		if err != nil || !account.IsActive {
+			s.logResetIgnored(userType, channel, identifier)
+			return nil
+		}
+		token, err := s.tokenStore.Issue(ctx, auth.ResetKindEmail, auth.ResetSubject{UserID: account.ID, UserType: userType}, s.cfg.EmailTokenTTL)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrResetUnavailable, err)
+		}
+		if err := s.mailer.Send(ctx, s.resetMail(account.Email, token)); err != nil {
+			return fmt.Errorf("%w: %v", ErrMailSendFailed, err)
+		}
+		return nil
+
+	default:
+		return ErrResetChannelInvalid
+	}
+}
+
+// VerifyReset 校验短信验证码或邮件令牌，通过后签发改密票据
+func (s *PasswordResetService) VerifyReset(ctx context.Context, userType, channel, identifier, code string) (string, error) {
+	accounts, ok := s.accounts[userType]
+	if !ok {
+		return "", ErrResetUserTypeInvalid
+	}
+	if s.tokenStore == nil {
+		return "", ErrResetUnavailable
+	}
+
+	var subject auth.ResetSubject
+	switch channel {
+	case ResetChannelSMS:
+		if !validator.IsPhone(identifier) {
+			return "", ErrPhoneInvalid
+		}
+		if code == "" {
+			return "", ErrSMSCodeEmpty
+		}
+		if s.smsService == nil {
+			return "", ErrResetUnavailable
+		}
+		scope := sms.Scope{Role: userType, Purpose: sms.PurposeResetPassword}
+		if err := s.smsService.VerifyCode(ctx, scope, identifier, code); err != nil {
+			return "", ErrSMSCodeInvalid
+		}
+		account, err := accounts.findByPhone(ctx, identifier)
+		if err != nil {
+			return "", ErrResetTokenInvalid
+		}
+		if !account.IsActive {
+			return "", ErrAccountDeactivated
+		}
+		subject = auth.ResetSubject{UserID: account.ID, UserType: userType}
+
+	case ResetChannelEmail:
+		consumed, err := s.consume(ctx, auth.ResetKindEmail, userType, code)
+		if err != nil {
+			return "", err
+		}
+		if err := s.checkActive(ctx, accounts, consumed.UserID); err != nil {
+			return "", err
+		}
+		subject = *consumed
+
+	default:
+		return "", ErrResetChannelInvalid
+	}
+
+	ticket, err := s.tokenStore.Issue(ctx, auth.ResetKindTicket, subject, s.cfg.TicketTTL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrResetUnavailable, err)
+	}
+	return ticket, nil
+}
+
+// CompleteReset 凭改密票据设置新密码，并吊销该账号的全部会话
+// 新密码先做强度校验，弱密码不会消耗票据；会话吊销先于写入新密码，吊销失败时密码保持不变
+func (s *PasswordResetService) CompleteReset(ctx context.Context, userType, ticket, newPassword string) error {
+	accounts, ok := s.accounts[userType]
+	if !ok {
+		return ErrResetUserTypeInvalid
+	}
+	if s.tokenStore == nil || s.jwtService == nil {
+		return ErrResetUnavailable
+	}
+	if err := crypto.ValidatePassword(newPassword); err != nil {
+		return fmt.Errorf("%w: %v", ErrPasswordTooWeak, err)
+	}
+
+	subject, err := s.consume(ctx, auth.ResetKindTicket, userType, ticket)
+	if err != nil {
+		return err
+	}
+	if err := s.checkActive(ctx, accounts, subject.UserID); err != nil {
+		return err
+	}
+
+	hashedPassword, err := crypto.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPasswordHashing, err)
+	}
+
+	// 旧密码可能已泄露：先使该账号此前签发的全部令牌失效，再写入新密码，
+	// 避免出现密码已改而旧会话仍然有效的状态；吊销失败时需重新走找回流程
+	if err := s.jwtService.LogoutAll(ctx, subject.UserID, userType); err != nil {
+		return fmt.Errorf("%w: %v", ErrResetUnavailable, err)
+	}
+	if err := accounts.updatePasswordHash(ctx, subject.UserID, hashedPassword); err != nil {
+		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+	}
+
+	s.logPasswordReset(userType, subject.UserID)
+	return nil
+}
+
+// #endregion
+
+// #region 内部工具
+
+// consume 作废一次性凭证并校验其账号类型
+func (s *PasswordResetService) consume(ctx context.Context, kind, userType, token string) (*auth.ResetSubject, error) {
+	subject, err := s.tokenStore.Consume(ctx, kind, token)
+	if err != nil {
+		if errors.Is(err, auth.ErrResetTokenInvalid) {
+			return nil, ErrResetTokenInvalid
+		}
+		return nil, fmt.Errorf("%w: %v", ErrResetUnavailable, err)
+	}
+	if subject.UserType != userType {
+		return nil, ErrResetTokenInvalid
+	}
+	return subject, nil
+}
+
+// checkActive 重新加载凭证对应的账号：凭证签发后账号被删除视为凭证无效，被停用则拒绝改密
+func (s *PasswordResetService) checkActive(ctx context.Context, accounts resetAccounts, id int64) error {
+	account, err := accounts.findByID(ctx, id)
+	if err != nil {
+		return ErrResetTokenInvalid
+	}
+	if !account.IsActive {
+		return ErrAccountDeactivated
+	}
+	return nil
+}
+
+// resetMail 组装找回密码邮件；配置了重置页面地址时附带链接
+func (s *PasswordResetService) resetMail(to, token string) mail.Message {
+	minutes := int(s.cfg.EmailTokenTTL.Minutes())
+	body := fmt.Sprintf("您正在找回密码，重置令牌：%s，%d 分钟内有效。如非本人操作请忽略本邮件。", token, minutes)
+	if s.cfg.ResetURL != "" {
+		link := s.cfg.ResetURL + "?token=" + url.QueryEscape(token)
+		body = fmt.Sprintf("您正在找回密码，请在 %d 分钟内打开以下链接设置新密码：%s\n如非本人操作请忽略本邮件。", minutes, link)
+	}
+	return mail.Message{To: to, Subject: "找回密码", Body: body}
+}
+
+func viewUser[K any](find func(context.Context, K) (*model.User, error)) func(context.Context, K) (*resetAccount, error) {
+	return func(ctx context.Context, key K) (*resetAccount, error) {
+		u, err := find(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &resetAccount{ID: u.ID, Email: u.Email, IsActive: u.IsActive}, nil
+	}
+}
+
+func viewEmployee[K any](find func(context.Context, K) (*model.Employee, error)) func(context.Context, K) (*resetAccount, error) {
+	return func(ctx context.Context, key K) (*resetAccount, error) {
+		e, err := find(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &resetAccount{ID: e.ID, Email: e.Email, IsActive: e.IsActive}, nil
+	}
+}
+
+func viewMerchant[K any](find func(context.Context, K) (*model.Merchant, error)) func(context.Context, K) (*resetAccount, error) {
+	return func(ctx context.Context, key K) (*resetAccount, error) {
+		m, err := find(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &resetAccount{ID: m.ID, Email: m.Email, IsActive: m.IsActive}, nil
+	}
+}
+
+func viewRider[K any](find func(context.Context, K) (*model.Rider, error)) func(context.Context, K) (*resetAccount, error) {
+	return func(ctx context.Context, key K) (*resetAccount, error) {
+		r, err := find(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &resetAccount{ID: r.ID, Email: r.Email, IsActive: r.IsActive}, nil
+	}
+}
+
+// #endregion
+
+// #region 日志记录
+
+// logResetIgnored 账号不存在或已停用时记录日志，但对外保持相同响应
+func (s *PasswordResetService) logResetIgnored(userType, channel, identifier string) {
+	log.Printf("找回密码请求已忽略（账号不存在或已停用） - 类型: %s, 渠道: %s, 标识: %s, 时间: %s",
+		userType, channel, identifier, time.Now().Format("2006-01-02 15:04:05"))
+}
+
+// logPasswordReset 记录密码重置日志
+func (s *PasswordResetService) logPasswordReset(userType string, userID int64) {
+	log.Printf("密码已重置并吊销全部会话 - 类型: %s, ID: %d, 时间: %s",
+		userType, userID, time.Now().Format("2006-01-02 15:04:05"))
+}
+
+// #endregion
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/config"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/auth"
+	"github.com/Hermitf/the-pass/pkg/crypto"
+	"github.com/Hermitf/the-pass/pkg/mail"
+	"github.com/Hermitf/the-pass/pkg/sms"
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type fakeUserRepo struct {
+	repository.UserRepositoryInterface
+	users map[int64]*model.User
+}
+
+func (f *fakeUserRepo) find(match func(*model.User) bool) (*model.User, error) {
+	for _, u := range f.users {
+		if match(u) {
+			return u, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (f *fakeUserRepo) GetUserByPhone(ctx context.Context, phone string) (*model.User, error) {
+	return f.find(func(u *model.User) bool { return u.Phone == phone })
+}
+
+func (f *fakeUserRepo) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return f.find(func(u *model.User) bool { return u.Email == email })
+}
+
+func (f *fakeUserRepo) GetUserByID(ctx context.Context, id uint) (*model.User, error) {
+	return f.find(func(u *model.User) bool { return u.ID == int64(id) })
+}
+
+func (f *fakeUserRepo) UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) error {
+	f.users[id].PasswordHash = passwordHash
+	return nil
+}
+
+// fakeSessionRevoker 记录 LogoutAll 调用
+type fakeSessionRevoker struct {
+	JWTServiceInterface
+	loggedOut []string
+	err       error
+}
+
+func (f *fakeSessionRevoker) LogoutAll(ctx context.Context, userID int64, userType string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.loggedOut = append(f.loggedOut, userType)
+	return nil
+}
+
+// lastMessage 记录最近一次发出的短信或邮件内容
+type lastMessage struct {
+	content string
+}
+
+func (l *lastMessage) SendSMS(ctx context.Context, phone string, content string) error {
+	l.content = content
+	return nil
+}
+
+func (l *lastMessage) Send(ctx context.Context, msg mail.Message) error {
+	l.content = msg.Body
+	return nil
+}
+
+func newPasswordResetTestService(t *testing.T) (PasswordResetServiceInterface, *fakeUserRepo, *fakeSessionRevoker, *lastMessage) {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	outbox := &lastMessage{}
+	users := &fakeUserRepo{users: map[int64]*model.User{
+		1: {ID: 1, Phone: "13800138000", Email: "alice@example.com", IsActive: true, PasswordHash: "old"},
+	}}
+	revoker := &fakeSessionRevoker{}
+	svc := NewPasswordResetService(PasswordResetServiceDependencies{
+		UserRepo:   users,
+		SMSService: sms.NewService(sms.NewRedisStore(rdb), outbox, sms.SMSRuntimeConfig{Enabled: true, ExpireIn: time.Minute, Template: "%s"}),
+		Mailer:     outbox,
+		TokenStore: auth.NewRedisPasswordResetStore(rdb),
+		JWTService: revoker,
+		Config:     config.PasswordResetConfig{ResetURL: "https://example.com/reset"},
+	})
+	return svc, users, revoker, outbox
+}
+
+func TestPasswordReset_EmailFlow(t *testing.T) {
+	svc, users, revoker, outbox := newPasswordResetTestService(t)
+	ctx := context.Background()
+
+	// 未注册邮箱同样返回成功且不发邮件
+	if err := svc.RequestReset(ctx, "user", ResetChannelEmail, "nobody@example.com"); err != nil || outbox.content != "" {
+		t.Fatalf("unknown email: err=%v mail=%q", err, outbox.content)
+	}
+
+	if err := svc.RequestReset(ctx, "user", ResetChannelEmail, "alice@example.com"); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	_, link, ok := strings.Cut(outbox.content, "?token=")
+	if !ok {
+		t.Fatalf("reset link missing from mail: %q", outbox.content)
+	}
+	token := strings.Fields(link)[0]
+
+	// 邮件令牌不能跨账号类型使用，也不能直接当作改密票据
+	if _, err := svc.VerifyReset(ctx, "rider", ResetChannelEmail, "", token); !errors.Is(err, ErrResetUserTypeInvalid) {
+		t.Fatalf("rider verify err=%v want ErrResetUserTypeInvalid", err)
+	}
+	if err := svc.CompleteReset(ctx, "user", token, "NewPassw0rd"); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Fatalf("token used as ticket err=%v want ErrResetTokenInvalid", err)
+	}
+
+	ticket, err := svc.VerifyReset(ctx, "user", ResetChannelEmail, "", token)
+	if err != nil {
+		t.Fatalf("VerifyReset: %v", err)
+	}
+	if _, err := svc.VerifyReset(ctx, "user", ResetChannelEmail, "", token); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Fatalf("token reuse err=%v want ErrResetTokenInvalid", err)
+	}
+
+	// 弱密码被拒绝且不消耗票据
+	if err := svc.CompleteReset(ctx, "user", ticket, "weak"); !errors.Is(err, ErrPasswordTooWeak) {
+		t.Fatalf("weak password err=%v want ErrPasswordTooWeak", err)
+	}
+	if err := svc.CompleteReset(ctx, "user", ticket, "NewPassw0rd"); err != nil {
+		t.Fatalf("CompleteReset: %v", err)
+	}
+	if err := crypto.VerifyPassword(users.users[1].PasswordHash, "NewPassw0rd"); err != nil {
+		t.Fatalf("password not updated: %v", err)
+	}
+	if len(revoker.loggedOut) != 1 || revoker.loggedOut[0] != "user" {
+		t.Fatalf("sessions not revoked: %v", revoker.loggedOut)
+	}
+	if err := svc.CompleteReset(ctx, "user", ticket, "Another1Pass"); !errors.Is(err, ErrResetTokenInvalid) {
+		t.Fatalf("ticket reuse err=%v want ErrResetTokenInvalid", err)
+	}
+}
+
+func TestPasswordReset_SMSFlow(t *testing.T) {
+	svc, users, revoker, outbox := newPasswordResetTestService(t)
+	ctx := context.Background()
+	phone := users.users[1].Phone
+
+	if err := svc.RequestReset(ctx, "user", ResetChannelSMS, phone); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	code := outbox.content
+
+	if _, err := svc.VerifyReset(ctx, "user", ResetChannelSMS, phone, "000000"+code); !errors.Is(err, ErrSMSCodeInvalid) {
+		t.Fatalf("wrong code err=%v want ErrSMSCodeInvalid", err)
+	}
+	ticket, err := svc.VerifyReset(ctx, "user", ResetChannelSMS, phone, code)
+	if err != nil {
+		t.Fatalf("VerifyReset: %v", err)
+	}
+	if err := svc.CompleteReset(ctx, "user", ticket, "NewPassw0rd"); err != nil {
+		t.Fatalf("CompleteReset: %v", err)
+	}
+	if len(revoker.loggedOut) != 1 {
+		t.Fatalf("sessions not revoked: %v", revoker.loggedOut)
+	}
+}
+
+func TestPasswordReset_DeactivatedAfterIssue(t *testing.T) {
+	svc, users, revoker, outbox := newPasswordResetTestService(t)
+	ctx := context.Background()
+
+	if err := svc.RequestReset(ctx, "user", ResetChannelEmail, "alice@example.com"); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	_, link, _ := strings.Cut(outbox.content, "?token=")
+	token := strings.Fields(link)[0]
+
+	// 邮件令牌签发后账号被停用
+	users.users[1].IsActive = false
+	if _, err := svc.VerifyReset(ctx, "user", ResetChannelEmail, "", token); !errors.Is(err, ErrAccountDeactivated) {
+		t.Fatalf("verify err=%v want ErrAccountDeactivated", err)
+	}
+
+	// 改密票据签发后账号被停用
+	users.users[1].IsActive = true
+	if err := svc.RequestReset(ctx, "user", ResetChannelSMS, users.users[1].Phone); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	ticket, err := svc.VerifyReset(ctx, "user", ResetChannelSMS, users.users[1].Phone, outbox.content)
+	if err != nil {
+		t.Fatalf("VerifyReset: %v", err)
+	}
+	users.users[1].IsActive = false
+	if err := svc.CompleteReset(ctx, "user", ticket, "NewPassw0rd"); !errors.Is(err, ErrAccountDeactivated) {
+		t.Fatalf("complete err=%v want ErrAccountDeactivated", err)
+	}
+	if users.users[1].PasswordHash != "old" || len(revoker.loggedOut) != 0 {
+		t.Fatalf("deactivated account was reset: hash=%q logouts=%v", users.users[1].PasswordHash, revoker.loggedOut)
+	}
+}
+
+func TestPasswordReset_RevocationFailureKeepsOldPassword(t *testing.T) {
+	svc, users, revoker, outbox := newPasswordResetTestService(t)
+	ctx := context.Background()
+	phone := users.users[1].Phone
+
+	if err := svc.RequestReset(ctx, "user", ResetChannelSMS, phone); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	ticket, err := svc.VerifyReset(ctx, "user", ResetChannelSMS, phone, outbox.content)
+	if err != nil {
+		t.Fatalf("VerifyReset: %v", err)
+	}
+
+	// 会话吊销失败时不写入新密码，调用方不会拿到"密码已改"与"失败"并存的结果
+	revoker.err = errors.New("redis down")
+	if err := svc.CompleteReset(ctx, "user", ticket, "NewPassw0rd"); !errors.Is(err, ErrResetUnavailable) {
+		t.Fatalf("complete err=%v want ErrResetUnavailable", err)
+	}
+	if users.users[1].PasswordHash != "old" {
+		t.Fatalf("password changed although sessions were not revoked")
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/repository"
+)
+
+// #region 服务定义
+
+// defaultLocationFlushInterval 默认落库与失联检查周期
+const defaultLocationFlushInterval = 10 * time.Second
+
+// RiderLocationSyncer 实时位置后台同步任务
+// 周期性地将 Redis 中的最新位置批量写入数据库，并将存活键过期的配送员置为离线
+type RiderLocationSyncer struct {
+	store     RiderLocationStore
+	riderRepo repository.RiderRepositoryInterface
+	interval  time.Duration
+}
+
+// #endregion
+
+// #region 构造函数和依赖注入
+
+// RiderLocationSyncerDependencies 位置同步任务依赖
+type RiderLocationSyncerDependencies struct {
+	LocationStore RiderLocationStore
+	RiderRepo     repository.RiderRepositoryInterface
+	Interval      time.Duration // 为 0 时使用默认周期
+}
+
+// NewRiderLocationSyncer 创建位置同步任务
+func NewRiderLocationSyncer(deps RiderLocationSyncerDependencies) *RiderLocationSyncer {
+	interval := deps.Interval
+	if interval <= 0 {
+		interval = defaultLocationFlushInterval
+	}
+	return &RiderLocationSyncer{
+		store:     deps.LocationStore,
+		riderRepo: deps.RiderRepo,
+		interval:  interval,
+	}
+}
+
+// #endregion
+
+// #region 同步
+
+// Run 按周期执行同步，直到 ctx 取消；退出前再落库一次，避免丢失最后一批位置
+func (s *RiderLocationSyncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Sync(ctx)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.Flush(flushCtx)
+			cancel()
+			return
+		}
+	}
+}
+
+// Sync 执行一轮落库与失联检查
+func (s *RiderLocationSyncer) Sync(ctx context.Context) {
+	s.Flush(ctx)
+	s.ReapOffline(ctx)
+}
+
+// Flush 将待落库位置批量写入数据库；写入失败时放回待落库集合，下一轮重试
+func (s *RiderLocationSyncer) Flush(ctx context.Context) {
+	positions, err := s.store.DrainPending(ctx)
+	if err != nil {
+		s.logSyncFailed("读取待落库位置", err)
+		return
+	}
+	if len(positions) == 0 {
+		return
+	}
+
+	if err := s.riderRepo.BatchUpdateLocations(ctx, positions); err != nil {
+		s.logSyncFailed("批量写入位置", err)
+		if err := s.store.Requeue(ctx, positions); err != nil {
+			s.logSyncFailed("回填待落库位置", err)
+		}
+	}
+}
+
+// ReapOffline 将存活键过期的配送员置为离线，再移出附近查询
+// 先写数据库：标记离线失败时配送员保留在 GEO 集合中，下一轮重试，不会出现查询不到却仍为在线的配送员
+func (s *RiderLocationSyncer) ReapOffline(ctx context.Context) {
+	riderIDs, err := s.store.ListExpired(ctx)
+	if err != nil {
+		s.logSyncFailed("检查失联配送员", err)
+		return
+	}
+	if len(riderIDs) == 0 {
+		return
+	}
+
+	if err := s.riderRepo.MarkOffline(ctx, riderIDs); err != nil {
+		s.logSyncFailed("标记离线", err)
+		return
+	}
+	log.Printf("配送员失联自动下线 - 配送员ID: %v, 时间: %s",
+		riderIDs, time.Now().Format("2006-01-02 15:04:05"))
+
+	if _, err := s.store.Reap(ctx, riderIDs); err != nil {
+		s.logSyncFailed("移除失联配送员位置", err)
+	}
+}
+
+// #endregion
+
+// #region 日志记录
+
+// logSyncFailed 记录同步失败日志
+func (s *RiderLocationSyncer) logSyncFailed(step string, err error) {
+	log.Printf("配送员位置同步失败 - 步骤: %s, 错误: %v, 时间: %s",
+		step, err, time.Now().Format("2006-01-02 15:04:05"))
+}
+
+// #endregion
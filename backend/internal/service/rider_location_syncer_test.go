@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Hermitf/the-pass/internal/location"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// liveRiderRepo 实时位置相关的配送员仓库
+type liveRiderRepo struct {
+	repository.RiderRepositoryInterface
+	riders     map[int64]*model.Rider
+	offlineErr error
+}
+
+func (f *liveRiderRepo) GetByIDs(ctx context.Context, ids []int64) ([]*model.Rider, error) {
+	riders := make([]*model.Rider, 0, len(ids))
+	for _, id := range ids {
+		if rider, ok := f.riders[id]; ok {
+			copied := *rider
+			riders = append(riders, &copied)
+		}
+	}
+	return riders, nil
+}
+
+func (f *liveRiderRepo) MarkOffline(ctx context.Context, ids []int64) error {
+	if f.offlineErr != nil {
+		return f.offlineErr
+	}
+	for _, id := range ids {
+		f.riders[id].IsOnline = false
+	}
+	return nil
+}
+
+// sliceLocationStore 按距离升序保存位置的内存 GEO 查询
+type sliceLocationStore struct {
+	RiderLocationStore
+	hits     []location.Hit
+	searches []int
+}
+
+func (f *sliceLocationStore) Search(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]location.Hit, error) {
+	f.searches = append(f.searches, limit)
+	return f.hits[:min(limit, len(f.hits))], nil
+}
+
+func newTestLocationStore(t *testing.T) (*location.Store, *miniredis.Miniredis) {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return location.NewStore(rdb, 30*time.Second), mr
+}
+
+func TestRiderLocationSyncer_ReapKeepsRiderSearchableUntilMarkedOffline(t *testing.T) {
+	store, mr := newTestLocationStore(t)
+	ctx := context.Background()
+	riders := &liveRiderRepo{riders: map[int64]*model.Rider{
+		1: {ID: 1, IsActive: true, IsOnline: true, VerificationStatus: model.VerificationApproved},
+	}}
+	syncer := NewRiderLocationSyncer(RiderLocationSyncerDependencies{LocationStore: store, RiderRepo: riders})
+
+	_ = store.Update(ctx, 1, 31.2, 121.4)
+	mr.FastForward(31 * time.Second)
+
+	// 标记离线失败：位置保留，下一轮重试
+	riders.offlineErr = errors.New("db down")
+	syncer.ReapOffline(ctx)
+	if expired, _ := store.ListExpired(ctx); len(expired) != 1 {
+		t.Fatalf("expired=%v want rider 1 kept for retry", expired)
+	}
+
+	riders.offlineErr = nil
+	syncer.ReapOffline(ctx)
+	if riders.riders[1].IsOnline {
+		t.Fatal("rider 1 should be marked offline")
+	}
+	if expired, _ := store.ListExpired(ctx); len(expired) != 0 {
+		t.Fatalf("expired=%v want rider 1 removed", expired)
+	}
+}
+
+func TestRiderService_LiveSearchSkipsIneligibleRiders(t *testing.T) {
+	store := &sliceLocationStore{}
+	ctx := context.Background()
+	riders := &liveRiderRepo{riders: map[int64]*model.Rider{}}
+
+	// 最近的 10 位配送员仍在上报位置但已离线或未通过审核，更远处有 2 位可接单
+	for id := int64(1); id <= 12; id++ {
+		rider := &model.Rider{ID: id, IsActive: true, IsOnline: true, VerificationStatus: model.VerificationApproved}
+		switch {
+		case id <= 5:
+			rider.IsOnline = false
+		case id <= 10:
+			rider.VerificationStatus = model.VerificationSubmitted
+		}
+		riders.riders[id] = rider
+		store.hits = append(store.hits, location.Hit{
+			RiderPosition: model.RiderPosition{RiderID: id, Lat: 31.2 + float64(id)*0.001, Lng: 121.4},
+			DistanceKm:    float64(id) * 0.11,
+		})
+	}
+	svc := NewRiderService(RiderServiceDependencies{RiderRepo: riders, LocationStore: store})
+
+	nearby, err := svc.GetAvailableRiders(ctx, 31.2, 121.4, 5, 2)
+	if err != nil {
+		t.Fatalf("GetAvailableRiders: %v", err)
+	}
+	if len(nearby) != 2 || nearby[0].Rider.ID != 11 || nearby[1].Rider.ID != 12 {
+		t.Fatalf("nearby=%+v want riders 11 and 12", nearby)
+	}
+	if len(store.searches) != 3 {
+		t.Fatalf("searches=%v want widened twice", store.searches)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/Hermitf/the-pass/internal/location"
 	"github.com/Hermitf/the-pass/internal/model"
 	"github.com/Hermitf/the-pass/internal/repository"
 	"github.com/Hermitf/the-pass/pkg/crypto"
@@ -15,53 +16,76 @@ import (
 
 // #region 服务定义
 
+// 附近配送员查询的结果数量限制
+const (
+	defaultNearbyRiderLimit = 20
+	maxNearbyRiderLimit     = 100
+)
+
+// liveRiderSearchMaxCount 实时位置查询单次 GEOSEARCH 的数量上限
+// 位置上报不查库，GEO 集合中可能有已离线、停用或未通过审核的配送员，查询时按需扩大范围补足
+const liveRiderSearchMaxCount = 1000
+
 // RiderServiceInterface 配送员服务接口
 type RiderServiceInterface interface {
 	// 配送员注册和认证
-	RegisterRider(rider *model.Rider) error
-	LoginRider(loginInfo, password, loginType string) (string, error)
+	RegisterRider(ctx context.Context, rider *model.Rider) error
+	LoginRider(ctx context.Context, loginInfo, password, loginType, clientIP string) (*TokenPair, error)
 
 	// 短信验证相关
-	SendSMSCode(ctx context.Context, phone string) error
-	VerifySMSCode(ctx context.Context, phone, code string) error
+	SendSMSCode(ctx context.Context, phone, purpose string) error
+	VerifySMSCode(ctx context.Context, phone, code, purpose string) error
 	CanSendSMSCode(ctx context.Context, phone string) (bool, time.Duration, error)
 
 	// 配送员信息管理
-	GetRiderByID(id int64) (*model.Rider, error)
-	UpdateRiderProfile(riderID int64, name, vehicleType, vehicleNumber, licenseNumber string) error
-	UpdateRiderPassword(riderID int64, oldPassword, newPassword string) error
+	GetRiderByID(ctx context.Context, id int64) (*model.Rider, error)
+	UpdateRiderProfile(ctx context.Context, riderID int64, name, vehicleType, vehicleNumber, licenseNumber string) error
+	UpdateRiderPassword(ctx context.Context, riderID int64, oldPassword, newPassword string) error
 
 	// 位置管理
-	UpdateLocation(riderID int64, lat, lng float64) error
-	GetRidersNearLocation(lat, lng, radiusKm float64) ([]*model.Rider, error)
-	GetRidersByRegion(bounds map[string]float64) ([]*model.Rider, error)
+	UpdateLocation(ctx context.Context, riderID int64, lat, lng float64) error
+	GetRidersNearLocation(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error)
+	GetRidersByRegion(ctx context.Context, bounds map[string]float64) ([]*model.Rider, error)
 
 	// 状态管理
-	SetOnlineStatus(riderID int64, isOnline bool) error
-	GetOnlineRiders(offset, limit int) ([]*model.Rider, int64, error)
-	GetActiveRiders(offset, limit int) ([]*model.Rider, int64, error)
-	GetAvailableRiders(lat, lng, radiusKm float64) ([]*model.Rider, error)
+	SetOnlineStatus(ctx context.Context, riderID int64, isOnline bool) error
+	GetOnlineRiders(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error)
+	GetActiveRiders(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error)
+	GetAvailableRiders(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error)
 
 	// 配送员验证
 	ValidateRiderData(rider *model.Rider) error
-	CheckRiderAvailability(username, email, phone, licenseNumber string) error
+	CheckRiderAvailability(ctx context.Context, username, email, phone, licenseNumber string) error
 
 	// 配送员列表和搜索
-	GetRiderList(offset, limit int) ([]*model.Rider, int64, error)
-	SearchRiders(keyword string, offset, limit int) ([]*model.Rider, int64, error)
-	GetRidersByVehicleType(vehicleType string, offset, limit int) ([]*model.Rider, int64, error)
+	GetRiderList(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error)
+	SearchRiders(ctx context.Context, keyword string, offset, limit int) ([]*model.Rider, int64, error)
+	GetRidersByVehicleType(ctx context.Context, vehicleType string, offset, limit int) ([]*model.Rider, int64, error)
 
 	// 配送员统计
-	GetRiderStats() (map[string]interface{}, error)
-	GetTopRidersByRating(limit int) ([]*model.Rider, error)
-	GetRidersByOrderCount(minOrders, maxOrders int64) ([]*model.Rider, error)
+	GetRiderStats(ctx context.Context) (map[string]interface{}, error)
+	GetTopRidersByRating(ctx context.Context, limit int) ([]*model.Rider, error)
+	GetRidersByOrderCount(ctx context.Context, minOrders, maxOrders int64) ([]*model.Rider, error)
+}
+
+// RiderLocationStore 配送员实时位置存储（Redis GEO）
+type RiderLocationStore interface {
+	Update(ctx context.Context, riderID int64, lat, lng float64) error
+	Remove(ctx context.Context, riderID int64) error
+	Search(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]location.Hit, error)
+	DrainPending(ctx context.Context) ([]model.RiderPosition, error)
+	Requeue(ctx context.Context, positions []model.RiderPosition) error
+	ListExpired(ctx context.Context) ([]int64, error)
+	Reap(ctx context.Context, riderIDs []int64) ([]int64, error)
 }
 
 // RiderService 配送员服务实现
 type RiderService struct {
-	riderRepo  repository.RiderRepositoryInterface
-	jwtService JWTServiceInterface
-	smsService *sms.Service
+	riderRepo     repository.RiderRepositoryInterface
+	jwtService    JWTServiceInterface
+	smsService    *sms.Service
+	locationStore RiderLocationStore
+	loginGuard    *LoginGuard
 }
 
 // #endregion
@@ -73,14 +97,19 @@ type RiderServiceDependencies struct {
 	RiderRepo  repository.RiderRepositoryInterface
 	JWTService JWTServiceInterface
 	SMSService *sms.Service
+	// LocationStore 可选；配置后位置上报写入 Redis，附近查询使用 GEOSEARCH
+	LocationStore RiderLocationStore
+	LoginGuard    *LoginGuard
 }
 
 // NewRiderService 创建配送员服务实例
 func NewRiderService(deps RiderServiceDependencies) RiderServiceInterface {
 	return &RiderService{
-		riderRepo:  deps.RiderRepo,
-		jwtService: deps.JWTService,
-		smsService: deps.SMSService,
+		riderRepo:     deps.RiderRepo,
+		jwtService:    deps.JWTService,
+		smsService:    deps.SMSService,
+		locationStore: deps.LocationStore,
+		loginGuard:    deps.LoginGuard,
 	}
 }
 
@@ -89,7 +118,7 @@ func NewRiderService(deps RiderServiceDependencies) RiderServiceInterface {
 // #region 配送员注册和认证
 
 // RegisterRider 注册配送员
-func (s *RiderService) RegisterRider(rider *model.Rider) error {
+func (s *RiderService) RegisterRider(ctx context.Context, rider *model.Rider) error {
 	if rider == nil {
 		return ErrRiderNil
 	}
@@ -100,10 +129,13 @@ func (s *RiderService) RegisterRider(rider *model.Rider) error {
 	}
 
 	// 检查配送员是否已存在
-	if err := s.CheckRiderAvailability(rider.Username, rider.Email, rider.Phone, rider.LicenseNumber); err != nil {
+	if err := s.CheckRiderAvailability(ctx, rider.Username, rider.Email, rider.Phone, rider.LicenseNumber); err != nil {
 		return fmt.Errorf("%w: %v", ErrAvailabilityCheck, err)
 	}
 
+	// 新注册配送员须先完成资质审核才能上线接单
+	rider.VerificationStatus = model.VerificationDraft
+
 	// 加密密码
 	if rider.PasswordHash != "" {
 		hashedPassword, err := crypto.HashPassword(rider.PasswordHash)
@@ -114,7 +146,7 @@ func (s *RiderService) RegisterRider(rider *model.Rider) error {
 	}
 
 	// 创建配送员
-	if err := s.riderRepo.Create(rider); err != nil {
+	if err := s.riderRepo.Create(ctx, rider); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
 	}
 
@@ -123,83 +155,104 @@ func (s *RiderService) RegisterRider(rider *model.Rider) error {
 }
 
 // LoginRider 配送员登录
-func (s *RiderService) LoginRider(loginInfo, password, loginType string) (string, error) {
+func (s *RiderService) LoginRider(ctx context.Context, loginInfo, password, loginType, clientIP string) (*TokenPair, error) {
 	if loginInfo == "" || password == "" {
-		return "", ErrLoginInfoEmpty
+		return nil, ErrLoginInfoEmpty
+	}
+	if err := s.loginGuard.Check(ctx, "rider", loginInfo, clientIP); err != nil {
+		return nil, err
 	}
 
 	// 根据登录类型获取配送员信息
-	rider, err := s.getRiderByLoginInfo(loginInfo, loginType)
+	rider, err := s.getRiderByLoginInfo(ctx, loginInfo, loginType)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrRiderNotFound, err)
+		s.loginGuard.RecordFailure(ctx, "rider", loginInfo, clientIP)
+		return nil, fmt.Errorf("%w: %v", ErrRiderNotFound, err)
 	}
 
 	// 验证密码
 	if err := crypto.VerifyPassword(rider.PasswordHash, password); err != nil {
-		return "", ErrInvalidCredentials
+		s.loginGuard.RecordFailure(ctx, "rider", loginInfo, clientIP)
+		return nil, ErrInvalidCredentials
 	}
+	s.loginGuard.RecordSuccess(ctx, "rider", loginInfo, clientIP)
 
 	// 检查配送员状态
 	if !rider.IsActive {
-		return "", ErrAccountDeactivated
+		return nil, ErrAccountDeactivated
 	}
 
 	// 生成JWT令牌
-	token, err := s.jwtService.GenerateToken(rider.ID, "rider")
+	tokens, err := s.jwtService.IssueTokenPair(ctx, rider.ID, "rider")
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+		return nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
 	}
 
 	s.logRiderLogin(rider, loginType)
-	return token, nil
+	return tokens, nil
 }
 
 // #endregion
 
 // #region 短信验证相关
 
-// SendSMSCode 发送短信验证码
-func (s *RiderService) SendSMSCode(ctx context.Context, phone string) error {
+// SendSMSCode 发送指定用途的短信验证码
+// 注册/换绑要求手机号尚未注册，登录/找回密码要求配送员已注册且处于启用状态
+func (s *RiderService) SendSMSCode(ctx context.Context, phone, purpose string) error {
 	if phone == "" {
 		return ErrPhoneEmpty
 	}
 	if !validator.IsPhone(phone) {
 		return ErrPhoneInvalid
 	}
-
-	// 检查配送员是否存在
-	rider, err := s.riderRepo.GetByPhone(phone)
+	scope, err := resolveSMSScope(smsRoleRider, purpose)
 	if err != nil {
-		return ErrPhoneNotRegistered
+		return err
 	}
 
-	// 检查配送员状态
-	if !rider.IsActive {
-		return ErrAccountDeactivated
+	// 按用途检查配送员是否存在及状态
+	var riderID int64
+	rider, err := s.riderRepo.GetByPhone(ctx, phone)
+	if smsTargetsNewPhone(scope.Purpose) {
+		if err == nil {
+			return ErrPhoneAlreadyExists
+		}
+	} else {
+		if err != nil {
+			return ErrPhoneNotRegistered
+		}
+		if !rider.IsActive {
+			return ErrAccountDeactivated
+		}
+		riderID = rider.ID
 	}
 
 	// 发送验证码
 	if s.smsService == nil {
 		return ErrSMSSendFailed
 	}
-	if err := s.smsService.SendCode(ctx, phone); err != nil {
+	if err := s.smsService.SendCode(ctx, scope, phone); err != nil {
 		return err
 	}
 
-	log.Printf("配送员短信发送 - 手机号: %s, 配送员ID: %d, 时间: %s",
-		phone, rider.ID, time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("配送员短信发送 - 手机号: %s, 用途: %s, 配送员ID: %d, 时间: %s",
+		phone, scope.Purpose, riderID, time.Now().Format("2006-01-02 15:04:05"))
 	return nil
 }
 
-// VerifySMSCode 验证短信验证码
-func (s *RiderService) VerifySMSCode(ctx context.Context, phone, code string) error {
+// VerifySMSCode 验证指定用途的短信验证码
+func (s *RiderService) VerifySMSCode(ctx context.Context, phone, code, purpose string) error {
 	if phone == "" || code == "" {
 		return ErrSMSCodeEmpty
 	}
+	scope, err := resolveSMSScope(smsRoleRider, purpose)
+	if err != nil {
+		return err
+	}
 	if s.smsService == nil {
 		return ErrSMSCodeInvalid
 	}
-	if err := s.smsService.VerifyCode(ctx, phone, code); err != nil {
+	if err := s.smsService.VerifyCode(ctx, scope, phone, code); err != nil {
 		return err
 	}
 	return nil
@@ -221,12 +274,12 @@ func (s *RiderService) CanSendSMSCode(ctx context.Context, phone string) (bool,
 // #region 配送员信息管理
 
 // GetRiderByID 根据ID获取配送员信息
-func (s *RiderService) GetRiderByID(id int64) (*model.Rider, error) {
+func (s *RiderService) GetRiderByID(ctx context.Context, id int64) (*model.Rider, error) {
 	if id <= 0 {
 		return nil, ErrInvalidRiderID
 	}
 
-	rider, err := s.riderRepo.GetByID(id)
+	rider, err := s.riderRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrRiderNotFound, err)
 	}
@@ -235,17 +288,22 @@ func (s *RiderService) GetRiderByID(id int64) (*model.Rider, error) {
 }
 
 // UpdateRiderProfile 更新配送员档案
-func (s *RiderService) UpdateRiderProfile(riderID int64, name, vehicleType, vehicleNumber, licenseNumber string) error {
+func (s *RiderService) UpdateRiderProfile(ctx context.Context, riderID int64, name, vehicleType, vehicleNumber, licenseNumber string) error {
 	if riderID <= 0 {
 		return ErrInvalidRiderID
 	}
 
 	// 获取配送员信息
-	rider, err := s.riderRepo.GetByID(riderID)
+	rider, err := s.riderRepo.GetByID(ctx, riderID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrRiderNotFound, err)
 	}
 
+	// 审核中或已通过审核的身份与车辆信息不能直接修改
+	if !model.CanEditVerificationDocuments(rider.VerificationStatus) {
+		return ErrRiderProfileLocked
+	}
+
 	// 验证新的配送员信息
 	if err := s.validateRiderFields(name, vehicleType, vehicleNumber, licenseNumber); err != nil {
 		return fmt.Errorf("%w: %v", ErrValidationFailed, err)
@@ -256,7 +314,7 @@ func (s *RiderService) UpdateRiderProfile(riderID int64, name, vehicleType, vehi
 		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
 	}
 
-	if err := s.riderRepo.Update(rider); err != nil {
+	if err := s.riderRepo.Update(ctx, rider); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
 	}
 
@@ -265,7 +323,7 @@ func (s *RiderService) UpdateRiderProfile(riderID int64, name, vehicleType, vehi
 }
 
 // UpdateRiderPassword 更新配送员密码
-func (s *RiderService) UpdateRiderPassword(riderID int64, oldPassword, newPassword string) error {
+func (s *RiderService) UpdateRiderPassword(ctx context.Context, riderID int64, oldPassword, newPassword string) error {
 	if riderID <= 0 {
 		return ErrInvalidRiderID
 	}
@@ -274,7 +332,7 @@ func (s *RiderService) UpdateRiderPassword(riderID int64, oldPassword, newPasswo
 	}
 
 	// 获取配送员信息
-	rider, err := s.riderRepo.GetByID(riderID)
+	rider, err := s.riderRepo.GetByID(ctx, riderID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrRiderNotFound, err)
 	}
@@ -292,7 +350,7 @@ func (s *RiderService) UpdateRiderPassword(riderID int64, oldPassword, newPasswo
 
 	// 更新密码
 	rider.PasswordHash = hashedPassword
-	if err := s.riderRepo.Update(rider); err != nil {
+	if err := s.riderRepo.Update(ctx, rider); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
 	}
 
@@ -305,13 +363,25 @@ func (s *RiderService) UpdateRiderPassword(riderID int64, oldPassword, newPasswo
 // #region 位置管理
 
 // UpdateLocation 更新配送员位置
-func (s *RiderService) UpdateLocation(riderID int64, lat, lng float64) error {
+// 配置了位置存储时只写入 Redis（由 RiderLocationSyncer 批量落库），不读写数据库
+func (s *RiderService) UpdateLocation(ctx context.Context, riderID int64, lat, lng float64) error {
 	if riderID <= 0 {
 		return ErrInvalidRiderID
 	}
 
+	if s.locationStore != nil {
+		position := &model.Rider{CurrentLat: lat, CurrentLng: lng}
+		if err := position.ValidateLocation(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidLocation, err)
+		}
+		if err := s.locationStore.Update(ctx, riderID, lat, lng); err != nil {
+			return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+		}
+		return nil
+	}
+
 	// 获取配送员信息以验证存在性
-	rider, err := s.riderRepo.GetByID(riderID)
+	rider, err := s.riderRepo.GetByID(ctx, riderID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrRiderNotFound, err)
 	}
@@ -322,7 +392,7 @@ func (s *RiderService) UpdateLocation(riderID int64, lat, lng float64) error {
 	}
 
 	// 保存到数据库
-	if err := s.riderRepo.UpdateLocation(riderID, lat, lng); err != nil {
+	if err := s.riderRepo.UpdateLocation(ctx, riderID, lat, lng); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
 	}
 
@@ -330,16 +400,19 @@ func (s *RiderService) UpdateLocation(riderID int64, lat, lng float64) error {
 	return nil
 }
 
-// GetRidersNearLocation 获取指定位置附近的配送员
-func (s *RiderService) GetRidersNearLocation(lat, lng, radiusKm float64) ([]*model.Rider, error) {
-	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
-		return nil, ErrInvalidLocation
+// GetRidersNearLocation 获取指定位置附近的配送员，按距离由近到远排序
+// limit 为 0 时使用默认值，超过上限时截断
+func (s *RiderService) GetRidersNearLocation(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error) {
+	limit, err := normalizeNearbyQuery(lat, lng, radiusKm, limit)
+	if err != nil {
+		return nil, err
 	}
-	if radiusKm <= 0 {
-		return nil, ErrRadiusInvalid
+
+	if s.locationStore != nil {
+		return s.searchLiveRiders(ctx, lat, lng, radiusKm, limit)
 	}
 
-	riders, err := s.riderRepo.GetRidersNearLocation(lat, lng, radiusKm)
+	riders, err := s.riderRepo.GetRidersNearLocation(ctx, lat, lng, radiusKm, limit)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrGetRiderList, err)
 	}
@@ -348,12 +421,12 @@ func (s *RiderService) GetRidersNearLocation(lat, lng, radiusKm float64) ([]*mod
 }
 
 // GetRidersByRegion 根据地理边界获取配送员
-func (s *RiderService) GetRidersByRegion(bounds map[string]float64) ([]*model.Rider, error) {
+func (s *RiderService) GetRidersByRegion(ctx context.Context, bounds map[string]float64) ([]*model.Rider, error) {
 	if len(bounds) == 0 {
 		return nil, ErrBoundsEmpty
 	}
 
-	riders, err := s.riderRepo.GetRidersByRegion(bounds)
+	riders, err := s.riderRepo.GetRidersByRegion(ctx, bounds)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrGetRiderList, err)
 	}
@@ -366,13 +439,14 @@ func (s *RiderService) GetRidersByRegion(bounds map[string]float64) ([]*model.Ri
 // #region 状态管理
 
 // SetOnlineStatus 设置在线状态
-func (s *RiderService) SetOnlineStatus(riderID int64, isOnline bool) error {
+// 配置了位置存储时，上线以最后已知位置登记到 GEO 集合，下线则移出附近查询
+func (s *RiderService) SetOnlineStatus(ctx context.Context, riderID int64, isOnline bool) error {
 	if riderID <= 0 {
 		return ErrInvalidRiderID
 	}
 
 	// 获取配送员信息
-	rider, err := s.riderRepo.GetByID(riderID)
+	rider, err := s.riderRepo.GetByID(ctx, riderID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrRiderNotFound, err)
 	}
@@ -381,23 +455,46 @@ func (s *RiderService) SetOnlineStatus(riderID int64, isOnline bool) error {
 	if isOnline && !rider.IsActive {
 		return ErrCannotSetInactiveOnline
 	}
+	if isOnline && !rider.IsVerified() {
+		return ErrRiderNotVerified
+	}
 
 	// 更新在线状态
-	if err := s.riderRepo.UpdateOnlineStatus(riderID, isOnline); err != nil {
+	if err := s.riderRepo.UpdateOnlineStatus(ctx, riderID, isOnline); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
 	}
 
+	s.syncLiveLocation(ctx, rider, isOnline)
 	s.logStatusChanged(riderID, isOnline)
 	return nil
 }
 
+// syncLiveLocation 同步上下线到位置存储；失败只记录日志，数据库中的在线状态为准
+func (s *RiderService) syncLiveLocation(ctx context.Context, rider *model.Rider, isOnline bool) {
+	if s.locationStore == nil {
+		return
+	}
+
+	var err error
+	switch {
+	case !isOnline:
+		err = s.locationStore.Remove(ctx, rider.ID)
+	case rider.CurrentLat != 0 || rider.CurrentLng != 0:
+		err = s.locationStore.Update(ctx, rider.ID, rider.CurrentLat, rider.CurrentLng)
+	}
+	if err != nil {
+		log.Printf("配送员实时位置同步失败 - 配送员ID: %d, 在线: %t, 错误: %v, 时间: %s",
+			rider.ID, isOnline, err, time.Now().Format("2006-01-02 15:04:05"))
+	}
+}
+
 // GetOnlineRiders 获取在线配送员列表
-func (s *RiderService) GetOnlineRiders(offset, limit int) ([]*model.Rider, int64, error) {
+func (s *RiderService) GetOnlineRiders(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	riders, total, err := s.riderRepo.GetOnlineRiders(offset, limit)
+	riders, total, err := s.riderRepo.GetOnlineRiders(ctx, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -406,12 +503,12 @@ func (s *RiderService) GetOnlineRiders(offset, limit int) ([]*model.Rider, int64
 }
 
 // GetActiveRiders 获取活跃配送员列表
-func (s *RiderService) GetActiveRiders(offset, limit int) ([]*model.Rider, int64, error) {
+func (s *RiderService) GetActiveRiders(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	riders, total, err := s.riderRepo.GetActiveRiders(offset, limit)
+	riders, total, err := s.riderRepo.GetActiveRiders(ctx, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -419,16 +516,19 @@ func (s *RiderService) GetActiveRiders(offset, limit int) ([]*model.Rider, int64
 	return riders, total, nil
 }
 
-// GetAvailableRiders 获取可接单的配送员
-func (s *RiderService) GetAvailableRiders(lat, lng, radiusKm float64) ([]*model.Rider, error) {
-	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
-		return nil, ErrInvalidLocation
+// GetAvailableRiders 获取可接单的配送员，按距离由近到远排序
+// limit 为 0 时使用默认值，超过上限时截断
+func (s *RiderService) GetAvailableRiders(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error) {
+	limit, err := normalizeNearbyQuery(lat, lng, radiusKm, limit)
+	if err != nil {
+		return nil, err
 	}
-	if radiusKm <= 0 {
-		return nil, ErrRadiusInvalid
+
+	if s.locationStore != nil {
+		return s.searchLiveRiders(ctx, lat, lng, radiusKm, limit)
 	}
 
-	riders, err := s.riderRepo.GetAvailableRiders(lat, lng, radiusKm)
+	riders, err := s.riderRepo.GetAvailableRiders(ctx, lat, lng, radiusKm, limit)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrGetRiderList, err)
 	}
@@ -436,6 +536,57 @@ func (s *RiderService) GetAvailableRiders(lat, lng, radiusKm float64) ([]*model.
 	return riders, nil
 }
 
+// searchLiveRiders 通过 GEOSEARCH 查询实时位置，再从数据库加载配送员并过滤未激活、已离线或未通过审核者
+// 过滤后不足 limit 个且半径内仍有更多配送员时扩大查询数量重查；返回的配送员坐标为最新上报位置
+func (s *RiderService) searchLiveRiders(ctx context.Context, lat, lng, radiusKm float64, limit int) ([]*model.NearbyRider, error) {
+	count := limit
+	for {
+		nearby, hits, err := s.searchLiveRidersOnce(ctx, lat, lng, radiusKm, count)
+		if err != nil {
+			return nil, err
+		}
+		if len(nearby) >= limit {
+			return nearby[:limit], nil
+		}
+		if hits < count || count >= liveRiderSearchMaxCount {
+			return nearby, nil
+		}
+		count = min(count*4, liveRiderSearchMaxCount)
+	}
+}
+
+// searchLiveRidersOnce 查询最近的 count 个位置并过滤不可接单者，同时返回 GEOSEARCH 命中数
+func (s *RiderService) searchLiveRidersOnce(ctx context.Context, lat, lng, radiusKm float64, count int) ([]*model.NearbyRider, int, error) {
+	hits, err := s.locationStore.Search(ctx, lat, lng, radiusKm, count)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrGetRiderList, err)
+	}
+
+	ids := make([]int64, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.RiderID)
+	}
+	riders, err := s.riderRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrGetRiderList, err)
+	}
+	byID := make(map[int64]*model.Rider, len(riders))
+	for _, rider := range riders {
+		byID[rider.ID] = rider
+	}
+
+	nearby := make([]*model.NearbyRider, 0, len(hits))
+	for _, hit := range hits {
+		rider, ok := byID[hit.RiderID]
+		if !ok || !rider.IsAvailableForOrder() {
+			continue
+		}
+		rider.CurrentLat, rider.CurrentLng = hit.Lat, hit.Lng
+		nearby = append(nearby, &model.NearbyRider{Rider: rider, DistanceKm: hit.DistanceKm})
+	}
+	return nearby, len(hits), nil
+}
+
 // #endregion
 
 // #region 配送员验证
@@ -450,8 +601,8 @@ func (s *RiderService) ValidateRiderData(rider *model.Rider) error {
 }
 
 // CheckRiderAvailability 检查配送员可用性
-func (s *RiderService) CheckRiderAvailability(username, email, phone, licenseNumber string) error {
-	exists, err := s.riderRepo.CheckRiderExists(username, email, phone, licenseNumber)
+func (s *RiderService) CheckRiderAvailability(ctx context.Context, username, email, phone, licenseNumber string) error {
+	exists, err := s.riderRepo.CheckRiderExists(ctx, username, email, phone, licenseNumber)
 	if err != nil {
 		return ErrAvailabilityCheck
 	}
@@ -468,12 +619,12 @@ func (s *RiderService) CheckRiderAvailability(username, email, phone, licenseNum
 // #region 配送员列表和搜索
 
 // GetRiderList 获取配送员列表
-func (s *RiderService) GetRiderList(offset, limit int) ([]*model.Rider, int64, error) {
+func (s *RiderService) GetRiderList(ctx context.Context, offset, limit int) ([]*model.Rider, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	riders, total, err := s.riderRepo.GetRiderList(offset, limit)
+	riders, total, err := s.riderRepo.GetRiderList(ctx, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -482,12 +633,12 @@ func (s *RiderService) GetRiderList(offset, limit int) ([]*model.Rider, int64, e
 }
 
 // SearchRiders 搜索配送员
-func (s *RiderService) SearchRiders(keyword string, offset, limit int) ([]*model.Rider, int64, error) {
+func (s *RiderService) SearchRiders(ctx context.Context, keyword string, offset, limit int) ([]*model.Rider, int64, error) {
 	if offset < 0 || limit <= 0 {
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	riders, total, err := s.riderRepo.SearchRiders(keyword, offset, limit)
+	riders, total, err := s.riderRepo.SearchRiders(ctx, keyword, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -496,7 +647,7 @@ func (s *RiderService) SearchRiders(keyword string, offset, limit int) ([]*model
 }
 
 // GetRidersByVehicleType 根据交通工具类型获取配送员
-func (s *RiderService) GetRidersByVehicleType(vehicleType string, offset, limit int) ([]*model.Rider, int64, error) {
+func (s *RiderService) GetRidersByVehicleType(ctx context.Context, vehicleType string, offset, limit int) ([]*model.Rider, int64, error) {
 	if vehicleType == "" {
 		return nil, 0, ErrVehicleTypeEmpty
 	}
@@ -504,7 +655,7 @@ func (s *RiderService) GetRidersByVehicleType(vehicleType string, offset, limit
 		return nil, 0, ErrPaginationInvalid
 	}
 
-	riders, total, err := s.riderRepo.GetRidersByVehicleType(vehicleType, offset, limit)
+	riders, total, err := s.riderRepo.GetRidersByVehicleType(ctx, vehicleType, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -517,8 +668,8 @@ func (s *RiderService) GetRidersByVehicleType(vehicleType string, offset, limit
 // #region 配送员统计
 
 // GetRiderStats 获取配送员统计信息
-func (s *RiderService) GetRiderStats() (map[string]interface{}, error) {
-	stats, err := s.riderRepo.GetRiderStats()
+func (s *RiderService) GetRiderStats(ctx context.Context) (map[string]interface{}, error) {
+	stats, err := s.riderRepo.GetRiderStats(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -527,12 +678,12 @@ func (s *RiderService) GetRiderStats() (map[string]interface{}, error) {
 }
 
 // GetTopRidersByRating 获取评分最高的配送员
-func (s *RiderService) GetTopRidersByRating(limit int) ([]*model.Rider, error) {
+func (s *RiderService) GetTopRidersByRating(ctx context.Context, limit int) ([]*model.Rider, error) {
 	if limit <= 0 {
 		return nil, ErrLimitInvalid
 	}
 
-	riders, err := s.riderRepo.GetTopRidersByRating(limit)
+	riders, err := s.riderRepo.GetTopRidersByRating(ctx, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -541,12 +692,12 @@ func (s *RiderService) GetTopRidersByRating(limit int) ([]*model.Rider, error) {
 }
 
 // GetRidersByOrderCount 根据订单数量范围获取配送员
-func (s *RiderService) GetRidersByOrderCount(minOrders, maxOrders int64) ([]*model.Rider, error) {
+func (s *RiderService) GetRidersByOrderCount(ctx context.Context, minOrders, maxOrders int64) ([]*model.Rider, error) {
 	if minOrders < 0 || maxOrders < minOrders {
 		return nil, ErrOrderCountRangeInvalid
 	}
 
-	riders, err := s.riderRepo.GetRidersByOrderCount(minOrders, maxOrders)
+	riders, err := s.riderRepo.GetRidersByOrderCount(ctx, minOrders, maxOrders)
 	if err != nil {
 		return nil, err
 	}
@@ -559,20 +710,20 @@ func (s *RiderService) GetRidersByOrderCount(minOrders, maxOrders int64) ([]*mod
 // #region 私有辅助方法
 
 // getRiderByLoginInfo 根据登录信息获取配送员
-func (s *RiderService) getRiderByLoginInfo(loginInfo, loginType string) (*model.Rider, error) {
+func (s *RiderService) getRiderByLoginInfo(ctx context.Context, loginInfo, loginType string) (*model.Rider, error) {
 	switch loginType {
 	case "email":
-		return s.riderRepo.GetByEmail(loginInfo)
+		return s.riderRepo.GetByEmail(ctx, loginInfo)
 	case "phone":
-		return s.riderRepo.GetByPhone(loginInfo)
+		return s.riderRepo.GetByPhone(ctx, loginInfo)
 	default:
 		// 智能检测登录类型
 		if validator.IsEmail(loginInfo) {
-			return s.riderRepo.GetByEmail(loginInfo)
+			return s.riderRepo.GetByEmail(ctx, loginInfo)
 		} else if validator.IsPhone(loginInfo) {
-			return s.riderRepo.GetByPhone(loginInfo)
+			return s.riderRepo.GetByPhone(ctx, loginInfo)
 		} else {
-			return s.riderRepo.GetByUsername(loginInfo)
+			return s.riderRepo.GetByUsername(ctx, loginInfo)
 		}
 	}
 }
@@ -590,6 +741,26 @@ func (s *RiderService) validateRiderFields(name, vehicleType, vehicleNumber, lic
 	return tempRider.ValidateAll()
 }
 
+// normalizeNearbyQuery 校验附近查询参数并返回生效的结果数量限制
+func normalizeNearbyQuery(lat, lng, radiusKm float64, limit int) (int, error) {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return 0, ErrInvalidLocation
+	}
+	if radiusKm <= 0 {
+		return 0, ErrRadiusInvalid
+	}
+	if limit < 0 {
+		return 0, ErrLimitInvalid
+	}
+	if limit == 0 {
+		return defaultNearbyRiderLimit, nil
+	}
+	if limit > maxNearbyRiderLimit {
+		return maxNearbyRiderLimit, nil
+	}
+	return limit, nil
+}
+
 // #endregion
 
 // #region 日志记录方法
@@ -0,0 +1,472 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Hermitf/the-pass/internal/database"
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/sms"
+	"github.com/Hermitf/the-pass/pkg/storage"
+)
+
+// #region 服务定义
+
+// RiderVerification 配送员资质审核进度、待审核信息及已上传材料
+type RiderVerification struct {
+	RiderID           int64                         `json:"rider_id"`
+	Status            string                        `json:"status"`
+	RejectReason      string                        `json:"reject_reason,omitempty"`
+	SubmittedAt       *time.Time                    `json:"submitted_at,omitempty"`
+	VerifiedAt        *time.Time                    `json:"verified_at,omitempty"`
+	Name              string                        `json:"name"`
+	IDNumber          string                        `json:"id_number"`
+	VehicleType       string                        `json:"vehicle_type"`
+	VehicleNumber     string                        `json:"vehicle_number"`
+	LicenseNumber     string                        `json:"license_number"`
+	RequiredDocuments []string                      `json:"required_documents"`
+	Documents         []*model.VerificationDocument `json:"documents"`
+}
+
+// RiderApplication 配送员提交审核前填写的身份与车辆信息
+type RiderApplication struct {
+	Name          string
+	IDNumber      string
+	VehicleType   string
+	VehicleNumber string
+	LicenseNumber string
+}
+
+// RiderVerificationServiceInterface 配送员资质审核服务接口
+//
+// 状态流转与商家一致：draft/rejected -> submitted -> under_review -> approved/rejected
+// 配送员填写身份与车辆信息、上传材料后提交审核；未通过审核的配送员不能上线接单。
+type RiderVerificationServiceInterface interface {
+	// 配送员侧
+	GetVerification(ctx context.Context, riderID int64) (*RiderVerification, error)
+	UpdateApplication(ctx context.Context, riderID int64, app RiderApplication) error
+	UploadDocument(ctx context.Context, riderID int64, upload DocumentUpload) (*model.VerificationDocument, error)
+	OpenDocument(ctx context.Context, riderID, docID int64) (*model.VerificationDocument, io.ReadCloser, error)
+	DeleteDocument(ctx context.Context, riderID, docID int64) error
+	SubmitVerification(ctx context.Context, riderID int64) error
+
+	// 管理员审核
+	ListVerifications(ctx context.Context, status string, offset, limit int) ([]*model.Rider, int64, error)
+	StartReview(ctx context.Context, actor AdminActor, riderID int64) error
+	ApproveVerification(ctx context.Context, actor AdminActor, riderID int64, remark string) error
+	RejectVerification(ctx context.Context, actor AdminActor, riderID int64, reason string) error
+
+	// 接单准入
+	IsRiderVerified(ctx context.Context, riderID int64) (bool, error)
+}
+
+// RiderVerificationService 配送员资质审核服务实现
+type RiderVerificationService struct {
+	riderRepo repository.RiderRepositoryInterface
+	auditRepo repository.AuditLogRepositoryInterface
+	documents *verificationDocumentStore
+	notifier  sms.Provider
+	txManager database.TxManager
+}
+
+// #endregion
+
+// #region 构造函数和依赖注入
+
+// RiderVerificationServiceDependencies 配送员资质审核服务依赖
+type RiderVerificationServiceDependencies struct {
+	RiderRepo     repository.RiderRepositoryInterface
+	DocumentRepo  repository.VerificationDocumentRepositoryInterface
+	AuditRepo     repository.AuditLogRepositoryInterface
+	Storage       storage.Storage
+	Notifier      sms.Provider // 审核结果短信通知，为空时只记录日志
+	TxManager     database.TxManager
+	MaxUploadSize int64
+}
+
+// NewRiderVerificationService 创建配送员资质审核服务实例
+func NewRiderVerificationService(deps RiderVerificationServiceDependencies) RiderVerificationServiceInterface {
+	return &RiderVerificationService{
+		riderRepo: deps.RiderRepo,
+		auditRepo: deps.AuditRepo,
+		documents: newVerificationDocumentStore(deps.DocumentRepo, deps.Storage, deps.MaxUploadSize),
+		notifier:  deps.Notifier,
+		txManager: deps.TxManager,
+	}
+}
+
+// #endregion
+
+// #region 配送员侧
+
+// GetVerification 获取配送员审核进度、待审核信息及材料列表
+func (s *RiderVerificationService) GetVerification(ctx context.Context, riderID int64) (*RiderVerification, error) {
+	rider, err := s.getRider(ctx, riderID)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.documents.repo.ListByOwner(ctx, model.AccountTypeRider, riderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RiderVerification{
+		RiderID:           rider.ID,
+		Status:            rider.VerificationStatus,
+		RejectReason:      rider.VerificationRejectReason,
+		SubmittedAt:       rider.VerificationSubmittedAt,
+		VerifiedAt:        rider.VerifiedAt,
+		Name:              rider.Name,
+		IDNumber:          rider.IDNumber,
+		VehicleType:       rider.VehicleType,
+		VehicleNumber:     rider.VehicleNumber,
+		LicenseNumber:     rider.LicenseNumber,
+		RequiredDocuments: model.RequiredRiderDocuments(rider.VehicleType),
+		Documents:         docs,
+	}, nil
+}
+
+// UpdateApplication 填写或修改待审核的身份与车辆信息，仅草稿或被驳回状态可修改
+// 此处只做格式校验，身份证校验码与车证一致性在提交审核时统一检查
+func (s *RiderVerificationService) UpdateApplication(ctx context.Context, riderID int64, app RiderApplication) error {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		rider, err := s.lockRider(ctx, riderID)
+		if err != nil {
+			return err
+		}
+		if !model.CanEditVerificationDocuments(rider.VerificationStatus) {
+			return ErrRiderProfileLocked
+		}
+
+		rider.Name = strings.TrimSpace(app.Name)
+		rider.IDNumber = strings.ToUpper(strings.TrimSpace(app.IDNumber))
+		rider.VehicleType = app.VehicleType
+		rider.VehicleNumber = strings.ToUpper(strings.TrimSpace(app.VehicleNumber))
+		rider.LicenseNumber = strings.ToUpper(strings.TrimSpace(app.LicenseNumber))
+		if err := rider.ValidateAll(); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+		}
+
+		if rider.IDNumber != "" {
+			existing, err := s.riderRepo.GetByIDNumber(ctx, rider.IDNumber)
+			if err != nil && !repository.IsNotFoundError(err) {
+				return err
+			}
+			if existing != nil && existing.ID != rider.ID {
+				return ErrRiderIDNumberTaken
+			}
+		}
+		return s.riderRepo.UpdateIdentity(ctx, rider)
+	})
+	if err != nil {
+		return s.wrapVerificationError(err)
+	}
+
+	log.Printf("配送员更新审核信息 - 配送员ID: %d, 交通工具: %s, 时间: %s",
+		riderID, app.VehicleType, time.Now().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// UploadDocument 上传资质材料，仅草稿或被驳回状态可修改材料
+func (s *RiderVerificationService) UploadDocument(ctx context.Context, riderID int64, upload DocumentUpload) (*model.VerificationDocument, error) {
+	if !model.IsValidRiderDocType(upload.DocType) {
+		return nil, ErrDocumentTypeInvalid
+	}
+	rider, err := s.getRider(ctx, riderID)
+	if err != nil {
+		return nil, err
+	}
+	if !model.CanEditVerificationDocuments(rider.VerificationStatus) {
+		return nil, ErrDocumentsLocked
+	}
+
+	doc, err := s.documents.put(ctx, model.AccountTypeRider, riderID, upload)
+	if err != nil {
+		return nil, err
+	}
+
+	// 锁定配送员行后复核状态，避免与提交审核并发时材料在审核期间被修改
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		rider, err := s.lockRider(ctx, riderID)
+		if err != nil {
+			return err
+		}
+		if !model.CanEditVerificationDocuments(rider.VerificationStatus) {
+			return ErrDocumentsLocked
+		}
+		return s.documents.repo.Create(ctx, doc)
+	})
+	if err != nil {
+		s.documents.discard(ctx, doc.StorageKey)
+		if errors.Is(err, ErrDocumentsLocked) || errors.Is(err, ErrRiderNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDataSaveFailed, err)
+	}
+
+	log.Printf("配送员资质材料上传 - 配送员ID: %d, 材料ID: %d, 类型: %s, 大小: %d, 时间: %s",
+		riderID, doc.ID, doc.DocType, doc.Size, time.Now().Format("2006-01-02 15:04:05"))
+	return doc, nil
+}
+
+// OpenDocument 读取配送员名下的一份材料，调用方负责关闭返回的 ReadCloser
+func (s *RiderVerificationService) OpenDocument(ctx context.Context, riderID, docID int64) (*model.VerificationDocument, io.ReadCloser, error) {
+	if riderID <= 0 {
+		return nil, nil, ErrInvalidRiderID
+	}
+	return s.documents.open(ctx, model.AccountTypeRider, riderID, docID)
+}
+
+// DeleteDocument 删除资质材料，仅草稿或被驳回状态可修改材料
+func (s *RiderVerificationService) DeleteDocument(ctx context.Context, riderID, docID int64) error {
+	var doc *model.VerificationDocument
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		rider, err := s.lockRider(ctx, riderID)
+		if err != nil {
+			return err
+		}
+		if !model.CanEditVerificationDocuments(rider.VerificationStatus) {
+			return ErrDocumentsLocked
+		}
+		doc, err = s.documents.repo.GetByID(ctx, model.AccountTypeRider, riderID, docID)
+		if err != nil {
+			return err
+		}
+		return s.documents.repo.Delete(ctx, model.AccountTypeRider, riderID, docID)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrVerificationDocumentNotFound) {
+			return ErrDocumentNotFound
+		}
+		if errors.Is(err, ErrDocumentsLocked) || errors.Is(err, ErrRiderNotFound) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+	}
+
+	// 元数据已删除，文件删除失败只会留下孤儿文件，不影响业务
+	s.documents.discard(ctx, doc.StorageKey)
+	return nil
+}
+
+// SubmitVerification 提交资质审核
+// 要求身份证号通过校验码检查、车证信息与交通工具类型相符，且已上传该类型必需的全部材料
+func (s *RiderVerificationService) SubmitVerification(ctx context.Context, riderID int64) error {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		rider, err := s.lockRider(ctx, riderID)
+		if err != nil {
+			return err
+		}
+		if err := rider.ValidateForVerification(); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+		}
+		for _, docType := range model.RequiredRiderDocuments(rider.VehicleType) {
+			count, err := s.documents.repo.CountByDocType(ctx, model.AccountTypeRider, riderID, docType)
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				return fmt.Errorf("%w: %s", ErrRiderDocumentRequired, docType)
+			}
+		}
+		return s.transition(ctx, rider, model.VerificationSubmitted, "")
+	})
+	if err != nil {
+		return s.wrapVerificationError(err)
+	}
+
+	log.Printf("配送员提交资质审核 - 配送员ID: %d, 时间: %s", riderID, time.Now().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// #endregion
+
+// #region 管理员审核
+
+// ListVerifications 按审核状态分页查询配送员，按提交时间先后排列
+func (s *RiderVerificationService) ListVerifications(ctx context.Context, status string, offset, limit int) ([]*model.Rider, int64, error) {
+	if !model.IsValidVerificationStatus(status) {
+		return nil, 0, ErrVerificationStatusInvalid
+	}
+	if offset < 0 || limit <= 0 || limit > 100 {
+		return nil, 0, ErrPaginationInvalid
+	}
+	return s.riderRepo.ListByVerificationStatus(ctx, status, offset, limit)
+}
+
+// StartReview 管理员受理审核申请
+func (s *RiderVerificationService) StartReview(ctx context.Context, actor AdminActor, riderID int64) error {
+	_, err := s.review(ctx, actor, riderID, model.VerificationUnderReview, model.AuditActionRiderReview, "受理资质审核")
+	return err
+}
+
+// ApproveVerification 管理员审核通过，remark 为空时使用默认说明
+// 通过前再次执行提交时的校验，防止审核期间数据被绕过接口修改
+func (s *RiderVerificationService) ApproveVerification(ctx context.Context, actor AdminActor, riderID int64, remark string) error {
+	remark = strings.TrimSpace(remark)
+	if remark == "" {
+		remark = "资质审核通过"
+	}
+	rider, err := s.review(ctx, actor, riderID, model.VerificationApproved, model.AuditActionRiderApprove, remark)
+	if err != nil {
+		return err
+	}
+
+	s.notify(ctx, rider, "【The Pass】您的配送员资质已通过审核，现在可以上线接单。")
+	return nil
+}
+
+// RejectVerification 管理员驳回审核，必须填写驳回原因，原因会展示给配送员
+func (s *RiderVerificationService) RejectVerification(ctx context.Context, actor AdminActor, riderID int64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrAuditReasonRequired
+	}
+	rider, err := s.review(ctx, actor, riderID, model.VerificationRejected, model.AuditActionRiderReject, reason)
+	if err != nil {
+		return err
+	}
+
+	s.notify(ctx, rider, fmt.Sprintf("【The Pass】您的配送员资质审核未通过，原因：%s。请修改资料后重新提交。", reason))
+	return nil
+}
+
+// review 在同一事务中迁移审核状态并写入审计日志，返回更新后的配送员
+func (s *RiderVerificationService) review(ctx context.Context, actor AdminActor, riderID int64, to, action, reason string) (*model.Rider, error) {
+	if actor.AdminID <= 0 {
+		return nil, ErrInvalidAdminID
+	}
+	if utf8.RuneCountInString(reason) > model.MaxAuditReasonLength {
+		return nil, ErrAuditReasonTooLong
+	}
+
+	var rider *model.Rider
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		rider, err = s.lockRider(ctx, riderID)
+		if err != nil {
+			return err
+		}
+		if to == model.VerificationApproved {
+			if err := rider.ValidateForVerification(); err != nil {
+				return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+			}
+		}
+		from := rider.VerificationStatus
+		rejectReason := ""
+		if to == model.VerificationRejected {
+			rejectReason = reason
+		}
+		if err := s.transition(ctx, rider, to, rejectReason); err != nil {
+			return err
+		}
+
+		detail, _ := json.Marshal(map[string]string{"from": from, "to": to})
+		return s.auditRepo.Create(ctx, &model.AuditLog{
+			ActorType:  model.AuditActorAdmin,
+			ActorID:    actor.AdminID,
+			Action:     action,
+			TargetType: model.AccountTypeRider,
+			TargetID:   riderID,
+			Reason:     reason,
+			Detail:     string(detail),
+			ClientIP:   actor.ClientIP,
+		})
+	})
+	if err != nil {
+		return nil, s.wrapVerificationError(err)
+	}
+
+	log.Printf("管理员操作 - 管理员ID: %d, 操作: %s, 对象: %s/%d, 原因: %s, 时间: %s",
+		actor.AdminID, action, model.AccountTypeRider, riderID, reason, time.Now().Format("2006-01-02 15:04:05"))
+	return rider, nil
+}
+
+// #endregion
+
+// #region 接单准入
+
+// IsRiderVerified 配送员是否已通过资质审核
+func (s *RiderVerificationService) IsRiderVerified(ctx context.Context, riderID int64) (bool, error) {
+	rider, err := s.getRider(ctx, riderID)
+	if err != nil {
+		return false, err
+	}
+	return rider.IsVerified(), nil
+}
+
+// #endregion
+
+// #region 辅助方法
+
+// getRider 根据ID获取配送员
+func (s *RiderVerificationService) getRider(ctx context.Context, riderID int64) (*model.Rider, error) {
+	if riderID <= 0 {
+		return nil, ErrInvalidRiderID
+	}
+	rider, err := s.riderRepo.GetByID(ctx, riderID)
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return nil, ErrRiderNotFound
+		}
+		return nil, err
+	}
+	return rider, nil
+}
+
+// lockRider 在当前事务中锁定配送员行
+func (s *RiderVerificationService) lockRider(ctx context.Context, riderID int64) (*model.Rider, error) {
+	if riderID <= 0 {
+		return nil, ErrInvalidRiderID
+	}
+	rider, err := s.riderRepo.GetByIDForUpdate(ctx, riderID)
+	if err != nil {
+		if repository.IsNotFoundError(err) {
+			return nil, ErrRiderNotFound
+		}
+		return nil, err
+	}
+	return rider, nil
+}
+
+// transition 迁移审核状态并持久化
+func (s *RiderVerificationService) transition(ctx context.Context, rider *model.Rider, to, rejectReason string) error {
+	if err := rider.TransitionVerification(to, rejectReason, time.Now()); err != nil {
+		return ErrInvalidVerificationState
+	}
+	return s.riderRepo.UpdateVerification(ctx, rider)
+}
+
+// wrapVerificationError 保留业务错误，其余错误归为数据更新失败
+func (s *RiderVerificationService) wrapVerificationError(err error) error {
+	for _, known := range []error{
+		ErrInvalidRiderID, ErrRiderNotFound, ErrInvalidVerificationState, ErrRiderProfileLocked,
+		ErrRiderIDNumberTaken, ErrRiderDocumentRequired, ErrValidationFailed,
+	} {
+		if errors.Is(err, known) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
+}
+
+// notify 通知配送员审核结果；通知失败不回滚审核决定，只记录日志
+func (s *RiderVerificationService) notify(ctx context.Context, rider *model.Rider, content string) {
+	if s.notifier == nil || rider.Phone == "" {
+		log.Printf("配送员审核结果未发送短信通知 - 配送员ID: %d, 状态: %s", rider.ID, rider.VerificationStatus)
+		return
+	}
+	if err := s.notifier.SendSMS(ctx, rider.Phone, content); err != nil {
+		log.Printf("配送员审核结果短信通知失败 - 配送员ID: %d, 状态: %s, 错误: %v", rider.ID, rider.VerificationStatus, err)
+	}
+}
+
+// #endregion
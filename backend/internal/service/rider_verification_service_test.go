@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/storage"
+)
+
+type verificationRiderRepo struct {
+	repository.RiderRepositoryInterface
+	riders map[int64]*model.Rider
+}
+
+func (f *verificationRiderRepo) GetByID(ctx context.Context, id int64) (*model.Rider, error) {
+	rider, ok := f.riders[id]
+	if !ok {
+		return nil, repository.ErrRiderNotFound
+	}
+	copied := *rider
+	return &copied, nil
+}
+
+func (f *verificationRiderRepo) GetByIDForUpdate(ctx context.Context, id int64) (*model.Rider, error) {
+	return f.GetByID(ctx, id)
+}
+
+func (f *verificationRiderRepo) GetByIDNumber(ctx context.Context, idNumber string) (*model.Rider, error) {
+	for _, rider := range f.riders {
+		if rider.IDNumber == idNumber {
+			copied := *rider
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrRiderNotFound
+}
+
+func (f *verificationRiderRepo) UpdateIdentity(ctx context.Context, rider *model.Rider) error {
+	copied := *rider
+	f.riders[rider.ID] = &copied
+	return nil
+}
+
+func (f *verificationRiderRepo) UpdateVerification(ctx context.Context, rider *model.Rider) error {
+	copied := *rider
+	f.riders[rider.ID] = &copied
+	return nil
+}
+
+func TestRiderVerification_SubmitRules(t *testing.T) {
+	riders := &verificationRiderRepo{riders: map[int64]*model.Rider{
+		1: {ID: 1, Phone: "13800138000", IsActive: true, VerificationStatus: model.VerificationDraft},
+		2: {ID: 2, IDNumber: "440308199901010012", IsActive: true, VerificationStatus: model.VerificationApproved},
+	}}
+	audit := &fakeAuditLogRepo{}
+	svc := NewRiderVerificationService(RiderVerificationServiceDependencies{
+		RiderRepo:    riders,
+		DocumentRepo: &memDocumentRepo{},
+		AuditRepo:    audit,
+		Storage:      storage.NewLocalStorage(t.TempDir()),
+		Notifier:     &lastMessage{},
+		TxManager:    passthroughTxManager{},
+	})
+	ctx := context.Background()
+	admin := AdminActor{AdminID: 1}
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+	upload := func(docType string) {
+		t.Helper()
+		_, err := svc.UploadDocument(ctx, 1, DocumentUpload{
+			DocType: docType, FileName: docType + ".png", Size: int64(len(png)), Content: bytes.NewReader(png),
+		})
+		if err != nil {
+			t.Fatalf("UploadDocument(%s): %v", docType, err)
+		}
+	}
+	apply := func(app RiderApplication) error {
+		app.Name = "张三"
+		return svc.UpdateApplication(ctx, 1, app)
+	}
+
+	if err := apply(RiderApplication{IDNumber: "440308199901010012", VehicleType: model.VehicleTypeBike}); !errors.Is(err, ErrRiderIDNumberTaken) {
+		t.Fatalf("duplicate ID number err=%v want ErrRiderIDNumberTaken", err)
+	}
+
+	// 校验码错误的身份证号格式合法，提交时才被拒绝
+	if err := apply(RiderApplication{IDNumber: "110105194912310021", VehicleType: model.VehicleTypeBike}); err != nil {
+		t.Fatalf("UpdateApplication: %v", err)
+	}
+	upload(model.RiderDocIDCardFront)
+	upload(model.RiderDocIDCardBack)
+	if err := svc.SubmitVerification(ctx, 1); !errors.Is(err, ErrValidationFailed) {
+		t.Fatalf("bad checksum submit err=%v want ErrValidationFailed", err)
+	}
+
+	// 摩托车须持驾驶证，且驾驶证号与身份证号一致
+	if err := apply(RiderApplication{IDNumber: "11010519491231002x", VehicleType: model.VehicleTypeMotorcycle,
+		VehicleNumber: "京A12345", LicenseNumber: "440308199901010012"}); err != nil {
+		t.Fatalf("UpdateApplication: %v", err)
+	}
+	if err := svc.SubmitVerification(ctx, 1); !errors.Is(err, ErrValidationFailed) {
+		t.Fatalf("licence mismatch submit err=%v want ErrValidationFailed", err)
+	}
+	if err := apply(RiderApplication{IDNumber: "11010519491231002x", VehicleType: model.VehicleTypeMotorcycle,
+		VehicleNumber: "京A12345", LicenseNumber: "11010519491231002X"}); err != nil {
+		t.Fatalf("UpdateApplication: %v", err)
+	}
+	if err := svc.SubmitVerification(ctx, 1); !errors.Is(err, ErrRiderDocumentRequired) {
+		t.Fatalf("submit without licences err=%v want ErrRiderDocumentRequired", err)
+	}
+	upload(model.RiderDocDriverLicense)
+	upload(model.RiderDocVehicleLicense)
+	if err := svc.SubmitVerification(ctx, 1); err != nil {
+		t.Fatalf("SubmitVerification: %v", err)
+	}
+
+	if err := apply(RiderApplication{IDNumber: "11010519491231002X", VehicleType: model.VehicleTypeBike}); !errors.Is(err, ErrRiderProfileLocked) {
+		t.Fatalf("edit after submit err=%v want ErrRiderProfileLocked", err)
+	}
+	if err := svc.StartReview(ctx, admin, 1); err != nil {
+		t.Fatalf("StartReview: %v", err)
+	}
+	if err := svc.ApproveVerification(ctx, admin, 1, ""); err != nil {
+		t.Fatalf("ApproveVerification: %v", err)
+	}
+	if verified, _ := svc.IsRiderVerified(ctx, 1); !verified {
+		t.Fatal("rider should be verified after approval")
+	}
+	if len(audit.entries) != 2 || audit.entries[1].Action != model.AuditActionRiderApprove {
+		t.Fatalf("audit logs=%+v want review and approve", audit.entries)
+	}
+}
+
+func TestRiderService_SetOnlineStatusRequiresVerification(t *testing.T) {
+	riders := &verificationRiderRepo{riders: map[int64]*model.Rider{
+		1: {ID: 1, IsActive: true, VerificationStatus: model.VerificationSubmitted},
+	}}
+	svc := NewRiderService(RiderServiceDependencies{RiderRepo: riders})
+
+	if err := svc.SetOnlineStatus(context.Background(), 1, true); !errors.Is(err, ErrRiderNotVerified) {
+		t.Fatalf("SetOnlineStatus err=%v want ErrRiderNotVerified", err)
+	}
+}
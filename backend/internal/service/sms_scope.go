@@ -0,0 +1,32 @@
+package service
+
+import "github.com/Hermitf/the-pass/pkg/sms"
+
+// #region 短信验证码作用域
+
+// 验证码角色，与签发令牌时的账号类型保持一致
+const (
+	smsRoleUser     = "user"
+	smsRoleMerchant = "merchant"
+	smsRoleRider    = "rider"
+	smsRoleEmployee = "employee"
+)
+
+// resolveSMSScope 解析请求中的验证码用途并绑定角色；用途缺省为验证码登录
+func resolveSMSScope(role, purpose string) (sms.Scope, error) {
+	if purpose == "" {
+		return sms.Scope{Role: role, Purpose: sms.PurposeLogin}, nil
+	}
+	p, err := sms.ParsePurpose(purpose)
+	if err != nil {
+		return sms.Scope{}, ErrSMSPurposeInvalid
+	}
+	return sms.Scope{Role: role, Purpose: p}, nil
+}
+
+// smsTargetsNewPhone 注册与换绑的验证码发往尚未注册的手机号，其余用途要求手机号已注册
+func smsTargetsNewPhone(purpose sms.Purpose) bool {
+	return purpose == sms.PurposeRegister || purpose == sms.PurposeChangePhone
+}
+
+// #endregion
@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/Hermitf/the-pass/internal/database"
 	"github.com/Hermitf/the-pass/internal/model"
 	"github.com/Hermitf/the-pass/internal/repository"
 	"github.com/Hermitf/the-pass/pkg/crypto"
@@ -19,30 +20,29 @@ import (
 type UserServiceInterface interface {
 	// 用户注册和认证
 	RegisterUser(ctx context.Context, user *model.User, smsCode string) error
-	LoginUser(loginInfo, password, loginType string) (string, error)
+	LoginUser(ctx context.Context, loginInfo, password, loginType, clientIP string) (*TokenPair, error)
 
 	// 短信验证相关
-	SendSMSCode(ctx context.Context, phone string) error
-	VerifySMSCode(ctx context.Context, phone, code string) error
+	SendSMSCode(ctx context.Context, phone, purpose string) error
+	VerifySMSCode(ctx context.Context, phone, code, purpose string) error
 	CanSendSMSCode(ctx context.Context, phone string) (bool, time.Duration, error)
 
 	// 用户信息管理
-	GetUserProfile(userID uint) (*model.User, error)
-	GetUserByID(userID int64) (*model.User, error)
-	UpdateUserProfile(userID uint, username, email, phone string) error
-	UpdatePassword(userID uint, oldPassword, newPassword string) error
-	ResetPassword(identifier, newPassword string) error
+	GetUserProfile(ctx context.Context, userID uint) (*model.User, error)
+	GetUserByID(ctx context.Context, userID int64) (*model.User, error)
+	UpdateUserProfile(ctx context.Context, userID uint, username, email, phone string) error
+	UpdatePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error
 
 	// 用户验证
 	ValidateUserData(user *model.User) error
-	CheckUserAvailability(username, email, phone string) error
+	CheckUserAvailability(ctx context.Context, username, email, phone string) error
 
 	// 用户列表和搜索
-	GetUserList(offset, limit int) ([]*model.User, int64, error)
-	SearchUsers(keyword string, offset, limit int) ([]*model.User, int64, error)
+	GetUserList(ctx context.Context, offset, limit int) ([]*model.User, int64, error)
+	SearchUsers(ctx context.Context, keyword string, offset, limit int) ([]*model.User, int64, error)
 
 	// 用户统计
-	GetUserStats() (map[string]interface{}, error)
+	GetUserStats(ctx context.Context) (map[string]interface{}, error)
 }
 
 // UserService 用户服务实现
@@ -50,6 +50,8 @@ type UserService struct {
 	userRepo   repository.UserRepositoryInterface
 	jwtService JWTServiceInterface
 	smsService *sms.Service
+	loginGuard *LoginGuard
+	txManager  database.TxManager
 }
 
 // #endregion
@@ -61,6 +63,8 @@ type UserServiceDependencies struct {
 	UserRepo   repository.UserRepositoryInterface
 	JWTService JWTServiceInterface
 	SMSService *sms.Service
+	LoginGuard *LoginGuard
+	TxManager  database.TxManager
 }
 
 // NewUserService 创建用户服务实例
@@ -69,6 +73,8 @@ func NewUserService(deps UserServiceDependencies) UserServiceInterface {
 		userRepo:   deps.UserRepo,
 		jwtService: deps.JWTService,
 		smsService: deps.SMSService,
+		loginGuard: deps.LoginGuard,
+		txManager:  deps.TxManager,
 	}
 }
 
@@ -93,7 +99,7 @@ func (s *UserService) RegisterUser(ctx context.Context, user *model.User, smsCod
 	}
 
 	// 检查用户是否已存在
-	if err := s.CheckUserAvailability(user.Username, user.Email, user.Phone); err != nil {
+	if err := s.CheckUserAvailability(ctx, user.Username, user.Email, user.Phone); err != nil {
 		return fmt.Errorf("%w: %v", ErrAvailabilityCheck, err)
 	}
 
@@ -105,7 +111,8 @@ func (s *UserService) RegisterUser(ctx context.Context, user *model.User, smsCod
 		if smsCode == "" {
 			return ErrSMSCodeEmpty
 		}
-		if err := s.smsService.VerifyCode(ctx, user.Phone, smsCode); err != nil {
+		registerScope := sms.Scope{Role: smsRoleUser, Purpose: sms.PurposeRegister}
+		if err := s.smsService.VerifyCode(ctx, registerScope, user.Phone, smsCode); err != nil {
 			// 统一收敛为业务层的验证码错误
 			return ErrSMSCodeInvalid
 		}
@@ -121,12 +128,12 @@ func (s *UserService) RegisterUser(ctx context.Context, user *model.User, smsCod
 	}
 
 	// 在事务中执行：二次可用性检查 + 创建
-	if err := s.userRepo.WithTx(ctx, func(txRepo repository.UserRepositoryInterface) error {
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
 		// 二次可用性检查（防止并发注册）
-		if err := s.CheckUserAvailability(user.Username, user.Email, user.Phone); err != nil {
+		if err := s.CheckUserAvailability(ctx, user.Username, user.Email, user.Phone); err != nil {
 			return fmt.Errorf("%w: %v", ErrAvailabilityCheck, err)
 		}
-		if err := txRepo.CreateUser(user); err != nil {
+		if err := s.userRepo.CreateUser(ctx, user); err != nil {
 			return fmt.Errorf("%w: %v", ErrUserCreationFailed, err)
 		}
 		return nil
@@ -139,10 +146,11 @@ func (s *UserService) RegisterUser(ctx context.Context, user *model.User, smsCod
 }
 
 // LoginUser 用户登录
+// 账号不存在、密码或验证码错误计入登录失败限流；clientIP 为空时仅按账号计数
 // TODO: 支持更多登录类型（如第三方登录）并细化异常类型。
-func (s *UserService) LoginUser(loginInfo, password, loginType string) (string, error) {
+func (s *UserService) LoginUser(ctx context.Context, loginInfo, password, loginType, clientIP string) (*TokenPair, error) {
 	if loginInfo == "" || password == "" {
-		return "", ErrLoginInfoEmpty
+		return nil, ErrLoginInfoEmpty
 	}
 
 	// 设置默认登录类型
@@ -150,68 +158,94 @@ func (s *UserService) LoginUser(loginInfo, password, loginType string) (string,
 		loginType = "password"
 	}
 
+	if err := s.loginGuard.Check(ctx, "user", loginInfo, clientIP); err != nil {
+		return nil, err
+	}
+
 	// 根据登录信息类型获取用户
-	user, err := s.getUserByLoginInfo(loginInfo)
+	user, err := s.getUserByLoginInfo(ctx, loginInfo)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrUserNotFound, err)
+		s.loginGuard.RecordFailure(ctx, "user", loginInfo, clientIP)
+		return nil, fmt.Errorf("%w: %v", ErrUserNotFound, err)
 	}
 
 	// 验证登录凭据
-	if err := s.verifyLoginCredentials(user, loginInfo, password, loginType); err != nil {
+	if err := s.verifyLoginCredentials(ctx, user, loginInfo, password, loginType); err != nil {
 		// 将细化错误统一映射为未授权，便于上层处理
 		switch err {
-		case ErrInvalidPassword, ErrSMSCodeInvalid, ErrAccountDeactivated, ErrUnsupportedLoginType:
-			return "", err
+		case ErrInvalidPassword, ErrSMSCodeInvalid:
+			s.loginGuard.RecordFailure(ctx, "user", loginInfo, clientIP)
+			return nil, err
+		case ErrAccountDeactivated, ErrUnsupportedLoginType:
+			return nil, err
 		default:
-			return "", ErrInvalidCredentials
+			return nil, ErrInvalidCredentials
 		}
 	}
+	s.loginGuard.RecordSuccess(ctx, "user", loginInfo, clientIP)
 
-	// 生成 JWT Token
-	token, err := s.generateToken(user)
+	// 签发访问令牌与刷新令牌
+	tokens, err := s.generateTokens(ctx, user)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrTokenGeneration, err)
+		return nil, fmt.Errorf("%w: %v", ErrTokenGeneration, err)
 	}
 
 	s.logUserLogin(user, loginType)
-	return token, nil
+	return tokens, nil
 }
 
 // #endregion
 
 // #region 短信验证相关
 
-// SendSMSCode 发送短信验证码
-func (s *UserService) SendSMSCode(ctx context.Context, phone string) error {
+// SendSMSCode 发送指定用途的短信验证码
+// 注册/换绑要求手机号尚未注册，登录/找回密码要求手机号已注册
+func (s *UserService) SendSMSCode(ctx context.Context, phone, purpose string) error {
 	if phone == "" {
 		return ErrPhoneEmpty
 	}
 	if !validator.IsPhone(phone) {
 		return ErrPhoneInvalid
 	}
-	user, err := s.userRepo.GetUserByPhone(phone)
+	scope, err := resolveSMSScope(smsRoleUser, purpose)
 	if err != nil {
-		return ErrPhoneNotRegistered
+		return err
+	}
+	var userID int64
+	user, err := s.userRepo.GetUserByPhone(ctx, phone)
+	if smsTargetsNewPhone(scope.Purpose) {
+		if err == nil {
+			return ErrPhoneAlreadyExists
+		}
+	} else {
+		if err != nil {
+			return ErrPhoneNotRegistered
+		}
+		userID = user.ID
 	}
 	if s.smsService == nil {
 		return ErrSMSSendFailed
 	}
-	if err := s.smsService.SendCode(ctx, phone); err != nil {
+	if err := s.smsService.SendCode(ctx, scope, phone); err != nil {
 		return err
 	}
-	s.logSMSSent(phone, user.ID)
+	s.logSMSSent(phone, string(scope.Purpose), userID)
 	return nil
 }
 
-// VerifySMSCode 验证短信验证码
-func (s *UserService) VerifySMSCode(ctx context.Context, phone, code string) error {
+// VerifySMSCode 验证指定用途的短信验证码
+func (s *UserService) VerifySMSCode(ctx context.Context, phone, code, purpose string) error {
 	if phone == "" || code == "" {
 		return ErrSMSCodeEmpty
 	}
+	scope, err := resolveSMSScope(smsRoleUser, purpose)
+	if err != nil {
+		return err
+	}
 	if s.smsService == nil {
 		return ErrSMSCodeInvalid
 	}
-	if err := s.smsService.VerifyCode(ctx, phone, code); err != nil {
+	if err := s.smsService.VerifyCode(ctx, scope, phone, code); err != nil {
 		return err
 	}
 	return nil
@@ -233,12 +267,12 @@ func (s *UserService) CanSendSMSCode(ctx context.Context, phone string) (bool, t
 // #region 用户信息管理
 
 // GetUserProfile 获取用户档案
-func (s *UserService) GetUserProfile(userID uint) (*model.User, error) {
+func (s *UserService) GetUserProfile(ctx context.Context, userID uint) (*model.User, error) {
 	if userID == 0 {
 		return nil, ErrInvalidUserID
 	}
 
-	user, err := s.userRepo.GetUserByID(userID)
+	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
@@ -247,22 +281,22 @@ func (s *UserService) GetUserProfile(userID uint) (*model.User, error) {
 }
 
 // GetUserByID 根据ID获取用户信息（int64版本）
-func (s *UserService) GetUserByID(userID int64) (*model.User, error) {
+func (s *UserService) GetUserByID(ctx context.Context, userID int64) (*model.User, error) {
 	if userID <= 0 {
 		return nil, ErrInvalidUserID
 	}
 
-	return s.GetUserProfile(uint(userID))
+	return s.GetUserProfile(ctx, uint(userID))
 }
 
 // UpdateUserProfile 更新用户档案
-func (s *UserService) UpdateUserProfile(userID uint, username, email, phone string) error {
+func (s *UserService) UpdateUserProfile(ctx context.Context, userID uint, username, email, phone string) error {
 	if userID == 0 {
 		return ErrInvalidUserID
 	}
 
 	// 获取当前用户信息
-	currentUser, err := s.userRepo.GetUserByID(userID)
+	currentUser, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrUserNotFound, err)
 	}
@@ -284,12 +318,12 @@ func (s *UserService) UpdateUserProfile(userID uint, username, email, phone stri
 	}
 
 	// 业务验证：检查重复数据（排除当前用户）
-	if err := s.checkUserAvailabilityExcluding(userID, username, email, phone); err != nil {
+	if err := s.checkUserAvailabilityExcluding(ctx, userID, username, email, phone); err != nil {
 		return fmt.Errorf("%w: %v", ErrAvailabilityCheck, err)
 	}
 
 	// 执行更新（使用基础的Repository方法）
-	if err := s.userRepo.UpdateUser(updateData); err != nil {
+	if err := s.userRepo.UpdateUser(ctx, updateData); err != nil {
 		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
 	}
 
@@ -299,7 +333,7 @@ func (s *UserService) UpdateUserProfile(userID uint, username, email, phone stri
 }
 
 // UpdatePassword 更新用户密码
-func (s *UserService) UpdatePassword(userID uint, oldPassword, newPassword string) error {
+func (s *UserService) UpdatePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error {
 	if userID == 0 {
 		return ErrInvalidUserID
 	}
@@ -308,7 +342,7 @@ func (s *UserService) UpdatePassword(userID uint, oldPassword, newPassword strin
 	}
 
 	// 获取用户信息
-	user, err := s.userRepo.GetUserByID(userID)
+	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return ErrUserNotFound
 	}
@@ -326,7 +360,7 @@ func (s *UserService) UpdatePassword(userID uint, oldPassword, newPassword strin
 
 	// 更新密码
 	user.PasswordHash = hashedPassword
-	if err := s.userRepo.UpdateUser(user); err != nil {
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
 		return ErrUserUpdateFailed
 	}
 
@@ -334,34 +368,6 @@ func (s *UserService) UpdatePassword(userID uint, oldPassword, newPassword strin
 	return nil
 }
 
-// ResetPassword 重置密码
-func (s *UserService) ResetPassword(identifier, newPassword string) error {
-	if identifier == "" || newPassword == "" {
-		return ErrLoginInfoEmpty
-	}
-
-	// 根据标识符获取用户
-	user, err := s.getUserByLoginInfo(identifier)
-	if err != nil {
-		return fmt.Errorf("%w: %v", ErrUserNotFound, err)
-	}
-
-	// 加密新密码
-	hashedPassword, err := crypto.HashPassword(newPassword)
-	if err != nil {
-		return fmt.Errorf("%w: %v", ErrPasswordHashing, err)
-	}
-
-	// 更新密码
-	user.PasswordHash = hashedPassword
-	if err := s.userRepo.UpdateUser(user); err != nil {
-		return fmt.Errorf("%w: %v", ErrDataUpdateFailed, err)
-	}
-
-	s.logPasswordReset(user.ID)
-	return nil
-}
-
 // #endregion
 
 // #region 用户验证
@@ -375,8 +381,8 @@ func (s *UserService) ValidateUserData(user *model.User) error {
 	return s.validateUserFields(user)
 }
 
-// CheckUserAvailability 检查用户可用性
-func (s *UserService) CheckUserAvailability(username, email, phone string) error {
+// CheckUserAvailability 检查用户可用性；ctx 中有事务时在事务内检查
+func (s *UserService) CheckUserAvailability(ctx context.Context, username, email, phone string) error {
 	// 业务验证：至少需要提供一个字段
 	if username == "" && email == "" && phone == "" {
 		return ErrNoFieldProvided
@@ -384,7 +390,7 @@ func (s *UserService) CheckUserAvailability(username, email, phone string) error
 
 	// 检查用户名
 	if username != "" {
-		exists, err := s.userRepo.ExistsWithUsername(username)
+		exists, err := s.userRepo.ExistsWithUsername(ctx, username)
 		if err != nil {
 			return fmt.Errorf("%w: %v", ErrCheckAvailability, err)
 		}
@@ -395,7 +401,7 @@ func (s *UserService) CheckUserAvailability(username, email, phone string) error
 
 	// 检查邮箱
 	if email != "" {
-		exists, err := s.userRepo.ExistsWithEmail(email)
+		exists, err := s.userRepo.ExistsWithEmail(ctx, email)
 		if err != nil {
 			return fmt.Errorf("%w: %v", ErrCheckAvailability, err)
 		}
@@ -406,7 +412,7 @@ func (s *UserService) CheckUserAvailability(username, email, phone string) error
 
 	// 检查手机号
 	if phone != "" {
-		exists, err := s.userRepo.ExistsWithPhone(phone)
+		exists, err := s.userRepo.ExistsWithPhone(ctx, phone)
 		if err != nil {
 			return fmt.Errorf("%w: %v", ErrCheckAvailability, err)
 		}
@@ -423,7 +429,7 @@ func (s *UserService) CheckUserAvailability(username, email, phone string) error
 // #region 用户列表和搜索
 
 // GetUserList 获取用户列表（业务层增强 - 权限检查、数据过滤、缓存等）
-func (s *UserService) GetUserList(offset, limit int) ([]*model.User, int64, error) {
+func (s *UserService) GetUserList(ctx context.Context, offset, limit int) ([]*model.User, int64, error) {
 	// 业务验证：分页参数检查
 	if offset < 0 {
 		return nil, 0, ErrPaginationInvalid
@@ -433,7 +439,7 @@ func (s *UserService) GetUserList(offset, limit int) ([]*model.User, int64, erro
 	}
 
 	// 调用数据层
-	users, total, err := s.userRepo.GetUserList(offset, limit)
+	users, total, err := s.userRepo.GetUserList(ctx, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -451,7 +457,7 @@ func (s *UserService) GetUserList(offset, limit int) ([]*model.User, int64, erro
 }
 
 // SearchUsers 搜索用户（业务层增强 - 搜索日志、结果过滤等）
-func (s *UserService) SearchUsers(keyword string, offset, limit int) ([]*model.User, int64, error) {
+func (s *UserService) SearchUsers(ctx context.Context, keyword string, offset, limit int) ([]*model.User, int64, error) {
 	// 业务验证：分页参数检查
 	if offset < 0 {
 		return nil, 0, ErrPaginationInvalid
@@ -466,7 +472,7 @@ func (s *UserService) SearchUsers(keyword string, offset, limit int) ([]*model.U
 	}
 
 	// 调用数据层
-	users, total, err := s.userRepo.SearchUsers(keyword, offset, limit)
+	users, total, err := s.userRepo.SearchUsers(ctx, keyword, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -494,18 +500,18 @@ func (s *UserService) SearchUsers(keyword string, offset, limit int) ([]*model.U
 // #region 用户统计
 
 // GetUserStats 获取用户统计信息（业务层增强）
-func (s *UserService) GetUserStats() (map[string]interface{}, error) {
+func (s *UserService) GetUserStats(ctx context.Context) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
 	// 总用户数
-	totalUsers, err := s.userRepo.CountUsers()
+	totalUsers, err := s.userRepo.CountUsers(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total user count: %w", err)
 	}
 	stats["total_users"] = totalUsers
 
 	// 活跃用户数（可以根据具体业务定义）
-	activeUsers, err := s.userRepo.CountUsersByStatus("active")
+	activeUsers, err := s.userRepo.CountUsersByStatus(ctx, "active")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active user count: %w", err)
 	}
@@ -526,18 +532,18 @@ func (s *UserService) GetUserStats() (map[string]interface{}, error) {
 // #region 私有辅助方法
 
 // getUserByLoginInfo 根据登录信息获取用户
-func (s *UserService) getUserByLoginInfo(loginInfo string) (*model.User, error) {
+func (s *UserService) getUserByLoginInfo(ctx context.Context, loginInfo string) (*model.User, error) {
 	if validator.IsEmail(loginInfo) {
-		return s.userRepo.GetUserByEmail(loginInfo)
+		return s.userRepo.GetUserByEmail(ctx, loginInfo)
 	} else if validator.IsPhone(loginInfo) {
-		return s.userRepo.GetUserByPhone(loginInfo)
+		return s.userRepo.GetUserByPhone(ctx, loginInfo)
 	} else {
-		return s.userRepo.GetUserByUsername(loginInfo)
+		return s.userRepo.GetUserByUsername(ctx, loginInfo)
 	}
 }
 
 // verifyLoginCredentials 验证登录凭据
-func (s *UserService) verifyLoginCredentials(user *model.User, loginInfo, password, loginType string) error {
+func (s *UserService) verifyLoginCredentials(ctx context.Context, user *model.User, loginInfo, password, loginType string) error {
 	switch loginType {
 	case "password":
 		if err := crypto.VerifyPassword(user.PasswordHash, password); err != nil {
@@ -550,7 +556,9 @@ func (s *UserService) verifyLoginCredentials(user *model.User, loginInfo, passwo
 		if s.smsService == nil {
 			return ErrSMSCodeInvalid
 		}
-		if err := s.smsService.VerifyCode(context.Background(), loginInfo, password); err != nil {
+		// 只接受登录用途的验证码，注册等其他用途的验证码不能用于登录
+		loginScope := sms.Scope{Role: smsRoleUser, Purpose: sms.PurposeLogin}
+		if err := s.smsService.VerifyCode(ctx, loginScope, loginInfo, password); err != nil {
 			return ErrSMSCodeInvalid
 		}
 	case "oauth":
@@ -563,9 +571,9 @@ func (s *UserService) verifyLoginCredentials(user *model.User, loginInfo, passwo
 	return nil
 }
 
-// generateToken 生成JWT Token
-func (s *UserService) generateToken(user *model.User) (string, error) {
-	return s.jwtService.GenerateToken(user.ID, "user")
+// generateTokens 签发访问令牌与刷新令牌
+func (s *UserService) generateTokens(ctx context.Context, user *model.User) (*TokenPair, error) {
+	return s.jwtService.IssueTokenPair(ctx, user.ID, "user")
 }
 
 // validateUserFields 验证用户字段
@@ -592,16 +600,16 @@ func (s *UserService) validateUserFields(user *model.User) error {
 }
 
 // checkUserAvailabilityExcluding 检查用户可用性（排除指定用户）
-func (s *UserService) checkUserAvailabilityExcluding(excludeUserID uint, username, email, phone string) error {
+func (s *UserService) checkUserAvailabilityExcluding(ctx context.Context, excludeUserID uint, username, email, phone string) error {
 	// 检查用户名
 	if username != "" {
-		exists, err := s.userRepo.ExistsWithUsername(username)
+		exists, err := s.userRepo.ExistsWithUsername(ctx, username)
 		if err != nil {
 			return fmt.Errorf("%w: %v", ErrCheckAvailability, err)
 		}
 		if exists {
 			// 检查是否是当前用户
-			existingUser, err := s.userRepo.GetUserByUsername(username)
+			existingUser, err := s.userRepo.GetUserByUsername(ctx, username)
 			if err == nil && existingUser.ID != int64(excludeUserID) {
 				return ErrUsernameAlreadyExists
 			}
@@ -610,12 +618,12 @@ func (s *UserService) checkUserAvailabilityExcluding(excludeUserID uint, usernam
 
 	// 检查邮箱
 	if email != "" {
-		exists, err := s.userRepo.ExistsWithEmail(email)
+		exists, err := s.userRepo.ExistsWithEmail(ctx, email)
 		if err != nil {
 			return fmt.Errorf("%w: %v", ErrCheckAvailability, err)
 		}
 		if exists {
-			existingUser, err := s.userRepo.GetUserByEmail(email)
+			existingUser, err := s.userRepo.GetUserByEmail(ctx, email)
 			if err == nil && existingUser.ID != int64(excludeUserID) {
 				return ErrEmailAlreadyExists
 			}
@@ -624,12 +632,12 @@ func (s *UserService) checkUserAvailabilityExcluding(excludeUserID uint, usernam
 
 	// 检查手机号
 	if phone != "" {
-		exists, err := s.userRepo.ExistsWithPhone(phone)
+		exists, err := s.userRepo.ExistsWithPhone(ctx, phone)
 		if err != nil {
 			return fmt.Errorf("%w: %v", ErrCheckAvailability, err)
 		}
 		if exists {
-			existingUser, err := s.userRepo.GetUserByPhone(phone)
+			existingUser, err := s.userRepo.GetUserByPhone(ctx, phone)
 			if err == nil && existingUser.ID != int64(excludeUserID) {
 				return ErrPhoneAlreadyExists
 			}
@@ -656,9 +664,9 @@ func (s *UserService) logUserLogin(user *model.User, loginType string) {
 }
 
 // logSMSSent 记录短信发送日志
-func (s *UserService) logSMSSent(phone string, userID int64) {
-	log.Printf("短信发送记录 - 手机号: %s, 用户ID: %d, 时间: %s",
-		phone, userID, time.Now().Format("2006-01-02 15:04:05"))
+func (s *UserService) logSMSSent(phone, purpose string, userID int64) {
+	log.Printf("短信发送记录 - 手机号: %s, 用途: %s, 用户ID: %d, 时间: %s",
+		phone, purpose, userID, time.Now().Format("2006-01-02 15:04:05"))
 }
 
 // logUserProfileUpdated 记录用户档案更新日志
@@ -673,10 +681,4 @@ func (s *UserService) logPasswordUpdated(userID uint) {
 		userID, time.Now().Format("2006-01-02 15:04:05"))
 }
 
-// logPasswordReset 记录密码重置日志
-func (s *UserService) logPasswordReset(userID int64) {
-	log.Printf("用户密码重置 - 用户ID: %d, 时间: %s",
-		userID, time.Now().Format("2006-01-02 15:04:05"))
-}
-
 // #endregion
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/Hermitf/the-pass/internal/model"
+	"github.com/Hermitf/the-pass/internal/repository"
+	"github.com/Hermitf/the-pass/pkg/storage"
+)
+
+// DefaultMaxDocumentSize 审核材料默认大小上限
+const DefaultMaxDocumentSize int64 = 10 << 20
+
+// allowedDocumentTypes 允许上传的材料格式（按文件内容识别，不信任客户端声明）
+var allowedDocumentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// DocumentUpload 待上传的审核材料
+type DocumentUpload struct {
+	DocType  string
+	FileName string
+	Size     int64
+	Content  io.Reader
+}
+
+// verificationDocumentStore 审核材料的文件存储与元数据读写，商家与配送员审核共用
+type verificationDocumentStore struct {
+	repo    repository.VerificationDocumentRepositoryInterface
+	storage storage.Storage
+	maxSize int64
+}
+
+func newVerificationDocumentStore(repo repository.VerificationDocumentRepositoryInterface, fileStorage storage.Storage, maxSize int64) *verificationDocumentStore {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxDocumentSize
+	}
+	return &verificationDocumentStore{repo: repo, storage: fileStorage, maxSize: maxSize}
+}
+
+// put 校验格式与大小后写入文件存储，返回待入库的材料记录
+// 元数据入库失败时调用方需 discard 已写入的文件
+func (s *verificationDocumentStore) put(ctx context.Context, ownerType string, ownerID int64, upload DocumentUpload) (*model.VerificationDocument, error) {
+	if s.storage == nil {
+		return nil, ErrStorageUnavailable
+	}
+	if upload.Content == nil || upload.Size <= 0 {
+		return nil, ErrDocumentEmpty
+	}
+	if upload.Size > s.maxSize {
+		return nil, fmt.Errorf("%w: 不能超过 %d MB", ErrDocumentTooLarge, s.maxSize>>20)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%w: %v", ErrDocumentEmpty, err)
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	ext, ok := allowedDocumentTypes[contentType]
+	if !ok {
+		return nil, ErrDocumentFormatUnsupported
+	}
+
+	key, err := documentStorageKey(ownerType, ownerID, upload.DocType, ext)
+	if err != nil {
+		return nil, err
+	}
+	// 多读 1 字节用于发现声明大小与实际内容不符的请求
+	body := io.LimitReader(io.MultiReader(bytes.NewReader(head), upload.Content), s.maxSize+1)
+	counter := &countingReader{r: body}
+	if err := s.storage.Put(ctx, key, counter); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStorageUnavailable, err)
+	}
+	if counter.n > s.maxSize {
+		s.discard(ctx, key)
+		return nil, fmt.Errorf("%w: 不能超过 %d MB", ErrDocumentTooLarge, s.maxSize>>20)
+	}
+
+	return &model.VerificationDocument{
+		OwnerType:   ownerType,
+		OwnerID:     ownerID,
+		DocType:     upload.DocType,
+		FileName:    filepath.Base(upload.FileName),
+		ContentType: contentType,
+		Size:        counter.n,
+		StorageKey:  key,
+	}, nil
+}
+
+// open 读取账号名下的一份材料
+func (s *verificationDocumentStore) open(ctx context.Context, ownerType string, ownerID, docID int64) (*model.VerificationDocument, io.ReadCloser, error) {
+	if s.storage == nil {
+		return nil, nil, ErrStorageUnavailable
+	}
+	doc, err := s.repo.GetByID(ctx, ownerType, ownerID, docID)
+	if err != nil {
+		if errors.Is(err, repository.ErrVerificationDocumentNotFound) {
+			return nil, nil, ErrDocumentNotFound
+		}
+		return nil, nil, err
+	}
+	rc, err := s.storage.Open(ctx, doc.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil, ErrDocumentNotFound
+		}
+		return nil, nil, fmt.Errorf("%w: %v", ErrStorageUnavailable, err)
+	}
+	return doc, rc, nil
+}
+
+// discard 尽力删除已写入的文件，失败只记录日志
+func (s *verificationDocumentStore) discard(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		log.Printf("审核材料文件删除失败 - 存储键: %s, 错误: %v", key, err)
+	}
+}
+
+// documentStorageKey 生成不可猜测的存储键：{账号类型}/{账号ID}/{材料类型}-{随机串}{扩展名}
+func documentStorageKey(ownerType string, ownerID int64, docType, ext string) (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrStorageUnavailable, err)
+	}
+	return fmt.Sprintf("%s/%d/%s-%s%s", ownerType, ownerID, docType, hex.EncodeToString(buf), ext), nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
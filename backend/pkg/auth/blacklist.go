@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	revokedJTIKeyPrefix = "auth:revoked:jti:"
+	validAfterKeyPrefix = "auth:valid_after:"
+)
+
+// ErrTokenRevoked 令牌已被吊销（主动登出或全部登出）
+var ErrTokenRevoked = errors.New("令牌已被吊销")
+
+// TokenRevocationStore 访问令牌吊销存储接口
+//
+// 两种吊销粒度：
+//   - 单个令牌：按 jti 加入黑名单，条目 TTL 为令牌剩余有效期，过期后自动清理；
+//   - 用户全部令牌：记录"令牌生效起点"时间戳，签发时间早于该时间的令牌一律失效。
+type TokenRevocationStore interface {
+	// RevokeJTI 将 jti 加入黑名单直至 expiresAt
+	RevokeJTI(ctx context.Context, jti string, expiresAt time.Time) error
+	// IsJTIRevoked 检查 jti 是否在黑名单中
+	IsJTIRevoked(ctx context.Context, jti string) (bool, error)
+	// SetTokensValidAfter 设置用户令牌生效起点，ttl 应不小于访问令牌有效期加校验允许的时钟偏差
+	SetTokensValidAfter(ctx context.Context, userType string, userID int64, at time.Time, ttl time.Duration) error
+	// TokensValidAfter 读取用户令牌生效起点，未设置时返回零值
+	TokensValidAfter(ctx context.Context, userType string, userID int64) (time.Time, error)
+}
+
+// CheckRevocation 校验声明是否已被吊销，store 为空时视为未启用吊销
+func CheckRevocation(ctx context.Context, store TokenRevocationStore, claims *Claims) error {
+	if store == nil || claims == nil {
+		return nil
+	}
+
+	if claims.ID != "" {
+		revoked, err := store.IsJTIRevoked(ctx, claims.ID)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return ErrTokenRevoked
+		}
+	}
+
+	validAfter, err := store.TokensValidAfter(ctx, claims.UserType, claims.UserID)
+	if err != nil {
+		return err
+	}
+	// iat 精度为秒，无法区分同一秒内先后签发的令牌，保守处理：与生效起点同一秒签发的令牌也视为失效
+	if !validAfter.IsZero() && (claims.IssuedAt == nil || !claims.IssuedAt.Time.After(validAfter)) {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// RedisRevocationStore Redis 实现的令牌吊销存储
+//
+// Redis 键命名规范：
+//   - auth:revoked:jti:{jti}                 黑名单条目，TTL = exp - now
+//   - auth:valid_after:{userType}:{userID}   令牌生效起点（Unix 秒）
+type RedisRevocationStore struct {
+	client *redis.Client
+}
+
+// NewRedisRevocationStore 创建 Redis 令牌吊销存储实例
+func NewRedisRevocationStore(client *redis.Client) *RedisRevocationStore {
+	return &RedisRevocationStore{client: client}
+}
+
+func validAfterKey(userType string, userID int64) string {
+	return fmt.Sprintf("%s%s:%d", validAfterKeyPrefix, userType, userID)
+}
+
+// RevokeJTI 将 jti 加入黑名单，已过期的令牌无需记录
+func (s *RedisRevocationStore) RevokeJTI(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := s.client.Set(ctx, revokedJTIKeyPrefix+jti, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("写入令牌黑名单失败: %w", err)
+	}
+	return nil
+}
+
+// IsJTIRevoked 检查 jti 是否在黑名单中
+func (s *RedisRevocationStore) IsJTIRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := s.client.Exists(ctx, revokedJTIKeyPrefix+jti).Result()
+	if err != nil {
+		return false, fmt.Errorf("查询令牌黑名单失败: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SetTokensValidAfter 设置用户令牌生效起点
+// 在 ttl 之后，早于该时间签发的访问令牌已自然过期，记录随之清理
+func (s *RedisRevocationStore) SetTokensValidAfter(ctx context.Context, userType string, userID int64, at time.Time, ttl time.Duration) error {
+	if err := s.client.Set(ctx, validAfterKey(userType, userID), at.Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("写入令牌生效起点失败: %w", err)
+	}
+	return nil
+}
+
+// TokensValidAfter 读取用户令牌生效起点
+func (s *RedisRevocationStore) TokensValidAfter(ctx context.Context, userType string, userID int64) (time.Time, error) {
+	raw, err := s.client.Get(ctx, validAfterKey(userType, userID)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("读取令牌生效起点失败: %w", err)
+	}
+	sec, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析令牌生效起点失败: %w", err)
+	}
+	return time.Unix(sec, 0), nil
+}
@@ -0,0 +1,27 @@
+package auth
+
+import "errors"
+
+// #region 令牌校验错误
+var (
+	ErrTokenEmpty        = errors.New("令牌不能为空")
+	ErrTokenExpired      = errors.New("令牌已过期")
+	ErrTokenNotYetValid  = errors.New("令牌尚未生效")
+	ErrTokenInvalid      = errors.New("令牌无效")
+	ErrClaimsInvalid     = errors.New("令牌声明无效")
+	ErrAlgorithmMismatch = errors.New("令牌签名算法不匹配")
+	ErrUnknownKeyID      = errors.New("未知的签名密钥")
+)
+
+// #endregion
+
+// #region 密钥配置错误
+var (
+	ErrSecretNotConfigured  = errors.New("JWT密钥未配置")
+	ErrUnsupportedAlgorithm = errors.New("不支持的签名算法")
+	ErrKeyLoad              = errors.New("加载签名密钥失败")
+	ErrNoSigningKey         = errors.New("当前密钥仅可用于验证，无法签名")
+	ErrDuplicateKeyID       = errors.New("密钥ID重复")
+)
+
+// #endregion
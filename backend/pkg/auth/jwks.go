@@ -0,0 +1,35 @@
+package auth
+
+// JWK 单个公钥的 JSON Web Key 表示（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	// RSA 公钥参数
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC 公钥参数
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet JWK 集合，对应 /.well-known/jwks.json 的响应体
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// BuildJWKSet 导出提供者中全部可公开的验证公钥，对称密钥会被跳过
+func BuildJWKSet(provider KeyProvider) JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if provider == nil {
+		return set
+	}
+	for _, s := range provider.Signers() {
+		if jwk, ok := s.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
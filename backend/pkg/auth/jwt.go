@@ -1,15 +1,44 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
-	"github.com/golang-jwt/jwt/v5"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // JWTConfig JWT配置结构
 type JWTConfig struct {
-	SecretKey string
-	ExpiresIn int64
+	SecretKey        string // 未配置 Keys 时使用的 HS256 密钥
+	ExpiresIn        int64
+	RefreshExpiresIn int64 // 刷新令牌有效期（秒），为 0 时使用 DefaultRefreshExpiresIn
+
+	Issuer   string      // iss，非空时签发写入并在验证时校验
+	Audience string      // aud，非空时签发写入并在验证时校验
+	Leeway   int64       // exp/nbf/iat 校验允许的时钟偏差（秒）
+	Keys     KeyProvider // 签名/验证密钥；为空时退化为 SecretKey 的 HS256
+}
+
+// keyProvider 返回配置的密钥提供者，未配置时使用 SecretKey 构造单密钥 HS256
+func (c JWTConfig) keyProvider() (KeyProvider, error) {
+	if c.Keys != nil {
+		return c.Keys, nil
+	}
+	signer, err := NewHS256Signer("", []byte(c.SecretKey))
+	if err != nil {
+		return nil, err
+	}
+	return NewStaticKeyProvider(signer)
+}
+
+// RefreshTTL 返回刷新令牌有效期
+func (c JWTConfig) RefreshTTL() time.Duration {
+	if c.RefreshExpiresIn <= 0 {
+		return time.Duration(DefaultRefreshExpiresIn) * time.Second
+	}
+	return time.Duration(c.RefreshExpiresIn) * time.Second
 }
 
 // Claims JWT声明结构
@@ -20,7 +49,8 @@ type Claims struct {
 }
 
 // GenerateToken 生成JWT令牌
-// TODO: 支持多种签名算法、可扩展的自定义声明以及多租户隔离。
+// 使用当前签名密钥签发，头部写入 kid；配置了 Issuer/Audience 时一并写入
+// TODO: 支持可扩展的自定义声明以及多租户隔离。
 func GenerateToken(userID int64, userType string, jwtConfig JWTConfig) (string, error) {
 	if userID <= 0 {
 		return "", fmt.Errorf("用户ID无效")
@@ -30,8 +60,9 @@ func GenerateToken(userID int64, userType string, jwtConfig JWTConfig) (string,
 		return "", fmt.Errorf("用户类型不能为空")
 	}
 
-	if jwtConfig.SecretKey == "" {
-		return "", fmt.Errorf("JWT密钥未配置")
+	keys, err := jwtConfig.keyProvider()
+	if err != nil {
+		return "", err
 	}
 
 	now := time.Now()
@@ -39,42 +70,77 @@ func GenerateToken(userID int64, userType string, jwtConfig JWTConfig) (string,
 		UserID:   userID,
 		UserType: userType,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(), // jti，用于单个令牌吊销
+			Issuer:    jwtConfig.Issuer,
 			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(jwtConfig.ExpiresIn) * time.Second)),
+			NotBefore: jwt.NewNumericDate(now),
 			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
+	if jwtConfig.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{jwtConfig.Audience}
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtConfig.SecretKey))
+	return keys.ActiveSigner().Sign(claims)
 }
 
-// VerifyToken 验证JWT令牌（预留 ctx 以扩展黑名单/审计）
+// VerifyToken 验证JWT令牌
+// 流程：
+// 1) 按头部 kid 找到验证密钥（未携带 kid 时使用当前密钥）；
+// 2) 令牌声明的算法必须与该密钥的算法一致，防止算法混淆攻击；
+// 3) 校验签名、exp/nbf/iat（允许 Leeway 偏差），以及配置的 iss/aud。
 func VerifyToken(tokenString string, jwtConfig JWTConfig) (*Claims, error) {
 	if tokenString == "" {
-		return nil, fmt.Errorf("令牌不能为空")
+		return nil, ErrTokenEmpty
+	}
+
+	keys, err := jwtConfig.keyProvider()
+	if err != nil {
+		return nil, err
 	}
 
-	if jwtConfig.SecretKey == "" {
-		return nil, fmt.Errorf("JWT密钥未配置")
+	opts := []jwt.ParserOption{
+		jwt.WithLeeway(time.Duration(jwtConfig.Leeway) * time.Second),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	}
+	if jwtConfig.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(jwtConfig.Issuer))
+	}
+	if jwtConfig.Audience != "" {
+		opts = append(opts, jwt.WithAudience(jwtConfig.Audience))
 	}
 
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("无效的签名方法")
+		kid, _ := token.Header["kid"].(string)
+		signer, err := keys.Signer(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != signer.Method().Alg() {
+			return nil, ErrAlgorithmMismatch
 		}
-		return []byte(jwtConfig.SecretKey), nil
-	})
+		return signer.VerifyKey(), nil
+	}, opts...)
 
 	if err != nil {
-		if err == jwt.ErrTokenExpired {
-			return nil, fmt.Errorf("令牌已过期")
+		switch {
+		case errors.Is(err, jwt.ErrTokenExpired):
+			return nil, ErrTokenExpired
+		case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+			return nil, ErrTokenNotYetValid
+		case errors.Is(err, ErrUnknownKeyID):
+			return nil, fmt.Errorf("%w: %w", ErrTokenInvalid, ErrUnknownKeyID)
+		case errors.Is(err, ErrAlgorithmMismatch):
+			return nil, fmt.Errorf("%w: %w", ErrTokenInvalid, ErrAlgorithmMismatch)
+		default:
+			return nil, ErrTokenInvalid
 		}
-		return nil, fmt.Errorf("令牌无效")
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		return claims, nil
 	}
 
-	return nil, fmt.Errorf("令牌声明无效")
+	return nil, ErrClaimsInvalid
 }
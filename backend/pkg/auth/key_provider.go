@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyProvider 密钥提供者：提供当前签名密钥，并按 kid 查找验证密钥
+type KeyProvider interface {
+	// ActiveSigner 返回当前用于签发的密钥
+	ActiveSigner() Signer
+	// Signer 按 kid 查找验证密钥；kid 为空时返回当前密钥（兼容未携带 kid 的旧令牌）
+	Signer(kid string) (Signer, error)
+	// Signers 返回全部可用于验证的密钥（当前密钥在前）
+	Signers() []Signer
+}
+
+// StaticKeyProvider 固定密钥集合的 KeyProvider 实现
+//
+// 密钥轮换流程：
+// 1) 生成新密钥并设为 active，旧密钥移入 previous（仅需公钥）；
+// 2) 旧密钥签发的令牌在有效期内仍可通过 kid 找到旧密钥完成验证；
+// 3) 旧令牌全部过期后，从 previous 中移除旧密钥。
+type StaticKeyProvider struct {
+	active Signer
+	byKID  map[string]Signer
+	order  []Signer
+}
+
+// NewStaticKeyProvider 创建固定密钥集合，kid 不允许重复
+func NewStaticKeyProvider(active Signer, previous ...Signer) (*StaticKeyProvider, error) {
+	if active == nil {
+		return nil, fmt.Errorf("%w: 未配置当前签名密钥", ErrKeyLoad)
+	}
+
+	p := &StaticKeyProvider{active: active, byKID: make(map[string]Signer, len(previous)+1)}
+	for _, s := range append([]Signer{active}, previous...) {
+		if _, exists := p.byKID[s.KeyID()]; exists {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateKeyID, s.KeyID())
+		}
+		p.byKID[s.KeyID()] = s
+		p.order = append(p.order, s)
+	}
+	return p, nil
+}
+
+// ActiveSigner 返回当前签名密钥
+func (p *StaticKeyProvider) ActiveSigner() Signer {
+	return p.active
+}
+
+// Signer 按 kid 查找验证密钥
+func (p *StaticKeyProvider) Signer(kid string) (Signer, error) {
+	if kid == "" {
+		return p.active, nil
+	}
+	s, ok := p.byKID[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+	}
+	return s, nil
+}
+
+// Signers 返回全部验证密钥
+func (p *StaticKeyProvider) Signers() []Signer {
+	return p.order
+}
+
+// #region 从配置加载
+
+// KeyConfig 单把密钥的配置
+// HS256 使用 Secret；RS256/ES256 使用 PEM 文件，当前签名密钥需提供私钥，验证密钥只需公钥
+type KeyConfig struct {
+	KeyID          string
+	Algorithm      string // HS256 / RS256 / ES256，默认 HS256
+	Secret         string
+	PrivateKeyFile string
+	PublicKeyFile  string
+}
+
+// LoadKeyProvider 根据配置加载当前签名密钥与轮换期间仍需验证的旧密钥
+func LoadKeyProvider(active KeyConfig, previous []KeyConfig) (*StaticKeyProvider, error) {
+	activeSigner, err := LoadSigner(active)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := activeSigner.Sign(jwt.RegisteredClaims{}); err != nil {
+		return nil, fmt.Errorf("%w: 当前密钥 %s 无法签名: %v", ErrKeyLoad, active.KeyID, err)
+	}
+
+	previousSigners := make([]Signer, 0, len(previous))
+	for _, kc := range previous {
+		s, err := LoadSigner(kc)
+		if err != nil {
+			return nil, err
+		}
+		previousSigners = append(previousSigners, s)
+	}
+	return NewStaticKeyProvider(activeSigner, previousSigners...)
+}
+
+// LoadSigner 按算法加载单把密钥
+func LoadSigner(kc KeyConfig) (Signer, error) {
+	switch strings.ToUpper(kc.Algorithm) {
+	case "", AlgorithmHS256:
+		return NewHS256Signer(kc.KeyID, []byte(kc.Secret))
+
+	case AlgorithmRS256:
+		privPEM, pubPEM, err := readKeyFiles(kc)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			priv *rsa.PrivateKey
+			pub  *rsa.PublicKey
+		)
+		if privPEM != nil {
+			if priv, err = jwt.ParseRSAPrivateKeyFromPEM(privPEM); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrKeyLoad, kc.PrivateKeyFile, err)
+			}
+		}
+		if pubPEM != nil {
+			if pub, err = jwt.ParseRSAPublicKeyFromPEM(pubPEM); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrKeyLoad, kc.PublicKeyFile, err)
+			}
+		}
+		return NewRS256Signer(kc.KeyID, priv, pub)
+
+	case AlgorithmES256:
+		privPEM, pubPEM, err := readKeyFiles(kc)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			priv *ecdsa.PrivateKey
+			pub  *ecdsa.PublicKey
+		)
+		if privPEM != nil {
+			if priv, err = jwt.ParseECPrivateKeyFromPEM(privPEM); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrKeyLoad, kc.PrivateKeyFile, err)
+			}
+		}
+		if pubPEM != nil {
+			if pub, err = jwt.ParseECPublicKeyFromPEM(pubPEM); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrKeyLoad, kc.PublicKeyFile, err)
+			}
+		}
+		return NewES256Signer(kc.KeyID, priv, pub)
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, kc.Algorithm)
+	}
+}
+
+// readKeyFiles 读取 PEM 文件，未配置的文件返回 nil
+func readKeyFiles(kc KeyConfig) ([]byte, []byte, error) {
+	if kc.PrivateKeyFile == "" && kc.PublicKeyFile == "" {
+		return nil, nil, fmt.Errorf("%w: 密钥 %s 未配置 PEM 文件", ErrKeyLoad, kc.KeyID)
+	}
+
+	var privPEM, pubPEM []byte
+	var err error
+	if kc.PrivateKeyFile != "" {
+		if privPEM, err = os.ReadFile(kc.PrivateKeyFile); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrKeyLoad, err)
+		}
+	}
+	if kc.PublicKeyFile != "" {
+		if pubPEM, err = os.ReadFile(kc.PublicKeyFile); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrKeyLoad, err)
+		}
+	}
+	return privPEM, pubPEM, nil
+}
+
+// #endregion
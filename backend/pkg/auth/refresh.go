@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	refreshTokenKeyPrefix  = "auth:refresh:token:"
+	refreshFamilyKeyPrefix = "auth:refresh:family:"
+	refreshUserKeyPrefix   = "auth:refresh:user:"
+	refreshTokenBytes      = 32
+
+	// DefaultRefreshExpiresIn 刷新令牌默认有效期（秒）：7 天
+	DefaultRefreshExpiresIn int64 = 7 * 24 * 3600
+)
+
+var (
+	// ErrRefreshTokenInvalid 刷新令牌不存在或已过期
+	ErrRefreshTokenInvalid = errors.New("刷新令牌无效或已过期")
+	// ErrRefreshTokenReused 已轮换的刷新令牌被再次使用，整个令牌族已被吊销
+	ErrRefreshTokenReused = errors.New("刷新令牌已被使用，登录会话已失效")
+	// ErrRefreshTokenRevoked 刷新令牌所属令牌族已被吊销
+	ErrRefreshTokenRevoked = errors.New("登录会话已失效")
+	// ErrRefreshTokenNotOwned 刷新令牌属于其他账号
+	ErrRefreshTokenNotOwned = errors.New("刷新令牌不属于当前账号")
+)
+
+// RefreshSession 刷新令牌对应的会话信息
+type RefreshSession struct {
+	FamilyID string
+	UserID   int64
+	UserType string
+}
+
+// RefreshTokenStore 刷新令牌存储接口
+//
+// 刷新令牌为不透明随机串，仅服务端可解析。同一次登录派生出的所有刷新令牌属于同一个令牌族（family），
+// 每次刷新都会轮换出新令牌并将旧令牌标记为已使用；已使用的令牌再次出现即视为泄露，吊销整个令牌族。
+type RefreshTokenStore interface {
+	// Issue 为新登录创建令牌族并签发首个刷新令牌
+	Issue(ctx context.Context, userID int64, userType string, ttl time.Duration) (string, *RefreshSession, error)
+	// Rotate 校验并轮换刷新令牌，返回新令牌及会话信息
+	Rotate(ctx context.Context, token string, ttl time.Duration) (string, *RefreshSession, error)
+	// RevokeFamily 吊销整个令牌族
+	RevokeFamily(ctx context.Context, familyID string) error
+	// RevokeToken 吊销刷新令牌所属的令牌族（登出当前会话），令牌属于其他账号时返回 ErrRefreshTokenNotOwned
+	RevokeToken(ctx context.Context, token string, userID int64, userType string) error
+	// RevokeUser 吊销用户的全部令牌族（登出全部会话）
+	RevokeUser(ctx context.Context, userID int64, userType string) error
+}
+
+// RedisRefreshTokenStore Redis 实现的刷新令牌存储
+//
+// Redis 键命名规范：
+//   - auth:refresh:token:{sha256(token)}  HASH{family,user_id,user_type,rotated}，TTL 为刷新令牌有效期
+//   - auth:refresh:family:{familyID}      令牌族存活标记，删除即吊销整族；TTL 自登录起算，轮换不延长
+//   - auth:refresh:user:{userType}:{userID}  SET，用户名下的令牌族 ID，用于登出全部会话
+//
+// 令牌仅以哈希形式落库，Redis 数据泄露不会直接暴露可用的刷新令牌。
+type RedisRefreshTokenStore struct {
+	client *redis.Client
+}
+
+// NewRedisRefreshTokenStore 创建 Redis 刷新令牌存储实例
+func NewRedisRefreshTokenStore(client *redis.Client) *RedisRefreshTokenStore {
+	return &RedisRefreshTokenStore{client: client}
+}
+
+// refreshTokenKey 以令牌哈希作为键，避免明文落库
+func refreshTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return refreshTokenKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+func refreshFamilyKey(familyID string) string {
+	return refreshFamilyKeyPrefix + familyID
+}
+
+func refreshUserKey(userType string, userID int64) string {
+	return fmt.Sprintf("%s%s:%d", refreshUserKeyPrefix, userType, userID)
+}
+
+// newRefreshToken 生成 256 位随机不透明令牌
+func newRefreshToken() (string, error) {
+	buf := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成刷新令牌失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// writeToken 在管道中写入令牌记录
+func writeToken(ctx context.Context, pipe redis.Pipeliner, token string, session *RefreshSession, ttl time.Duration) {
+	key := refreshTokenKey(token)
+	pipe.HSet(ctx, key, map[string]interface{}{
+		"family":    session.FamilyID,
+		"user_id":   session.UserID,
+		"user_type": session.UserType,
+		"rotated":   0,
+	})
+	pipe.Expire(ctx, key, ttl)
+}
+
+// Issue 为新登录创建令牌族并签发首个刷新令牌
+func (s *RedisRefreshTokenStore) Issue(ctx context.Context, userID int64, userType string, ttl time.Duration) (string, *RefreshSession, error) {
+	token, err := newRefreshToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	session := &RefreshSession{FamilyID: uuid.NewString(), UserID: userID, UserType: userType}
+	pipe := s.client.TxPipeline()
+	writeToken(ctx, pipe, token, session, ttl)
+	// 令牌族的绝对有效期在登录时确定，轮换不会延长
+	pipe.Set(ctx, refreshFamilyKey(session.FamilyID), session.UserType+":"+strconv.FormatInt(session.UserID, 10), ttl)
+	userKey := refreshUserKey(session.UserType, session.UserID)
+	pipe.SAdd(ctx, userKey, session.FamilyID)
+	pipe.Expire(ctx, userKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", nil, fmt.Errorf("保存刷新令牌失败: %w", err)
+	}
+	return token, session, nil
+}
+
+// Rotate 校验并轮换刷新令牌
+// 流程：
+// 1) 读取令牌记录，不存在则返回 ErrRefreshTokenInvalid；
+// 2) 已标记 rotated 说明令牌被重放，吊销整个令牌族并返回 ErrRefreshTokenReused；
+// 3) 令牌族标记不存在说明已被吊销，返回 ErrRefreshTokenRevoked；
+// 4) 在同一事务内标记旧令牌为 rotated 并写入新令牌。
+// 旧令牌保留至自身 TTL 到期，以便识别重放。
+// 令牌族 TTL 不随轮换延长：新令牌有效期取 ttl 与令牌族剩余时间的较小值，持续刷新的会话也会在登录满 ttl 后失效。
+func (s *RedisRefreshTokenStore) Rotate(ctx context.Context, token string, ttl time.Duration) (string, *RefreshSession, error) {
+	if token == "" {
+		return "", nil, ErrRefreshTokenInvalid
+	}
+
+	key := refreshTokenKey(token)
+	newToken, err := newRefreshToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var session *RefreshSession
+	var reused bool
+	err = s.client.Watch(ctx, func(tx *redis.Tx) error {
+		fields, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return ErrRefreshTokenInvalid
+		}
+
+		userID, err := strconv.ParseInt(fields["user_id"], 10, 64)
+		if err != nil {
+			return fmt.Errorf("解析刷新令牌记录失败: %w", err)
+		}
+		current := &RefreshSession{FamilyID: fields["family"], UserID: userID, UserType: fields["user_type"]}
+
+		if fields["rotated"] == "1" {
+			reused = true
+			session = current
+			return ErrRefreshTokenReused
+		}
+
+		// PTTL 在键不存在时返回 -2
+		familyTTL, err := tx.PTTL(ctx, refreshFamilyKey(current.FamilyID)).Result()
+		if err != nil {
+			return err
+		}
+		if familyTTL == -2 {
+			return ErrRefreshTokenRevoked
+		}
+		tokenTTL := ttl
+		if familyTTL > 0 && familyTTL < tokenTTL {
+			tokenTTL = familyTTL
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "rotated", 1)
+			writeToken(ctx, pipe, newToken, current, tokenTTL)
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, redis.TxFailedErr) {
+				// 并发刷新同一令牌：另一个请求已完成轮换，本次按重放处理
+				reused = true
+				session = current
+				return ErrRefreshTokenReused
+			}
+			return err
+		}
+
+		session = current
+		return nil
+	}, key)
+
+	if reused {
+		if revokeErr := s.RevokeFamily(ctx, session.FamilyID); revokeErr != nil {
+			return "", nil, fmt.Errorf("%w: %v", ErrRefreshTokenReused, revokeErr)
+		}
+		return "", nil, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return newToken, session, nil
+}
+
+// RevokeFamily 吊销整个令牌族，族内所有刷新令牌立即失效
+func (s *RedisRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	if familyID == "" {
+		return nil
+	}
+	if err := s.client.Del(ctx, refreshFamilyKey(familyID)).Err(); err != nil {
+		return fmt.Errorf("吊销令牌族失败: %w", err)
+	}
+	return nil
+}
+
+// RevokeToken 吊销刷新令牌所属的令牌族，令牌不存在时视为已失效
+// 只允许令牌持有账号本人吊销，防止拿到他人刷新令牌后强制其下线
+func (s *RedisRefreshTokenStore) RevokeToken(ctx context.Context, token string, userID int64, userType string) error {
+	if token == "" {
+		return nil
+	}
+	fields, err := s.client.HMGet(ctx, refreshTokenKey(token), "family", "user_id", "user_type").Result()
+	if err != nil {
+		return fmt.Errorf("查询刷新令牌失败: %w", err)
+	}
+	familyID, _ := fields[0].(string)
+	if familyID == "" {
+		return nil
+	}
+	if owner, _ := fields[1].(string); owner != strconv.FormatInt(userID, 10) || fields[2] != userType {
+		return ErrRefreshTokenNotOwned
+	}
+	return s.RevokeFamily(ctx, familyID)
+}
+
+// RevokeUser 吊销用户名下全部令牌族
+func (s *RedisRefreshTokenStore) RevokeUser(ctx context.Context, userID int64, userType string) error {
+	userKey := refreshUserKey(userType, userID)
+	families, err := s.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return fmt.Errorf("查询用户令牌族失败: %w", err)
+	}
+
+	keys := make([]string, 0, len(families)+1)
+	for _, familyID := range families {
+		keys = append(keys, refreshFamilyKey(familyID))
+	}
+	keys = append(keys, userKey)
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("吊销用户令牌族失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRefreshStore(t *testing.T) (*RedisRefreshTokenStore, *miniredis.Miniredis, context.Context) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(func() { mr.Close() })
+
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	return NewRedisRefreshTokenStore(rdb), mr, context.Background()
+}
+
+func TestRedisRefreshTokenStore_Rotate(t *testing.T) {
+	store, _, ctx := newTestRefreshStore(t)
+
+	token, session, err := store.Issue(ctx, 7, "rider", time.Hour)
+	if err != nil {
+		t.Fatalf("Issue error: %v", err)
+	}
+
+	next, rotated, err := store.Rotate(ctx, token, time.Hour)
+	if err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	if next == token {
+		t.Fatalf("rotated token must differ from the original")
+	}
+	if rotated.FamilyID != session.FamilyID || rotated.UserID != 7 || rotated.UserType != "rider" {
+		t.Fatalf("session=%+v want family=%s rider:7", rotated, session.FamilyID)
+	}
+
+	if _, _, err := store.Rotate(ctx, next, time.Hour); err != nil {
+		t.Fatalf("second Rotate error: %v", err)
+	}
+}
+
+func TestRedisRefreshTokenStore_ReuseRevokesFamily(t *testing.T) {
+	store, _, ctx := newTestRefreshStore(t)
+
+	token, _, err := store.Issue(ctx, 1, "user", time.Hour)
+	if err != nil {
+		t.Fatalf("Issue error: %v", err)
+	}
+	next, _, err := store.Rotate(ctx, token, time.Hour)
+	if err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+
+	// 重放已轮换的令牌
+	if _, _, err := store.Rotate(ctx, token, time.Hour); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse err=%v want ErrRefreshTokenReused", err)
+	}
+	// 同族最新令牌也随之失效
+	if _, _, err := store.Rotate(ctx, next, time.Hour); !errors.Is(err, ErrRefreshTokenRevoked) {
+		t.Fatalf("family member err=%v want ErrRefreshTokenRevoked", err)
+	}
+}
+
+func TestRedisRefreshTokenStore_InvalidAndExpired(t *testing.T) {
+	store, mr, ctx := newTestRefreshStore(t)
+
+	if _, _, err := store.Rotate(ctx, "unknown", time.Hour); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("unknown token err=%v want ErrRefreshTokenInvalid", err)
+	}
+
+	token, _, err := store.Issue(ctx, 1, "user", time.Minute)
+	if err != nil {
+		t.Fatalf("Issue error: %v", err)
+	}
+	mr.FastForward(2 * time.Minute)
+	if _, _, err := store.Rotate(ctx, token, time.Minute); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("expired token err=%v want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestRedisRefreshTokenStore_FamilyHasAbsoluteExpiry(t *testing.T) {
+	store, mr, ctx := newTestRefreshStore(t)
+
+	token, session, err := store.Issue(ctx, 1, "user", time.Hour)
+	if err != nil {
+		t.Fatalf("Issue error: %v", err)
+	}
+	mr.FastForward(40 * time.Minute)
+	next, _, err := store.Rotate(ctx, token, time.Hour)
+	if err != nil {
+		t.Fatalf("Rotate error: %v", err)
+	}
+	if ttl := mr.TTL(refreshFamilyKey(session.FamilyID)); ttl > 20*time.Minute {
+		t.Fatalf("family ttl=%v must not slide on rotate", ttl)
+	}
+
+	// 登录满 1 小时后，即使一直在刷新也必须重新登录
+	mr.FastForward(30 * time.Minute)
+	if _, _, err := store.Rotate(ctx, next, time.Hour); err == nil {
+		t.Fatal("rotating past the family's absolute expiry should fail")
+	}
+}
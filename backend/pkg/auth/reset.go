@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const resetTokenKeyPrefix = "auth:pwreset:"
+
+// 一次性凭证的种类
+const (
+	// ResetKindEmail 邮件中发送的找回密码令牌
+	ResetKindEmail = "email"
+	// ResetKindTicket 通过短信/邮件校验后换得的改密票据
+	ResetKindTicket = "ticket"
+)
+
+// ErrResetTokenInvalid 找回密码凭证不存在、已使用或已过期
+var ErrResetTokenInvalid = errors.New("找回密码凭证无效或已过期")
+
+// ResetSubject 找回密码凭证所属的账号
+type ResetSubject struct {
+	UserID   int64
+	UserType string
+}
+
+// PasswordResetStore 找回密码一次性凭证存储接口
+//
+// 凭证为不透明随机串，按种类隔离：邮件令牌不能直接当作改密票据使用，反之亦然。
+type PasswordResetStore interface {
+	// Issue 为账号签发指定种类的一次性凭证
+	Issue(ctx context.Context, kind string, subject ResetSubject, ttl time.Duration) (string, error)
+	// Consume 校验并作废凭证，返回其所属账号；凭证无效时返回 ErrResetTokenInvalid
+	Consume(ctx context.Context, kind, token string) (*ResetSubject, error)
+}
+
+// RedisPasswordResetStore Redis 实现的找回密码凭证存储
+//
+// Redis 键命名规范：
+//   - auth:pwreset:{kind}:{sha256(token)}  值为 {userType}:{userID}，TTL 为凭证有效期
+//
+// 与刷新令牌相同，凭证仅以哈希形式落库。
+type RedisPasswordResetStore struct {
+	client *redis.Client
+}
+
+// NewRedisPasswordResetStore 创建 Redis 找回密码凭证存储实例
+func NewRedisPasswordResetStore(client *redis.Client) *RedisPasswordResetStore {
+	return &RedisPasswordResetStore{client: client}
+}
+
+func resetTokenKey(kind, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return resetTokenKeyPrefix + kind + ":" + hex.EncodeToString(sum[:])
+}
+
+// Issue 签发一次性凭证
+func (s *RedisPasswordResetStore) Issue(ctx context.Context, kind string, subject ResetSubject, ttl time.Duration) (string, error) {
+	token, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	value := subject.UserType + ":" + strconv.FormatInt(subject.UserID, 10)
+	if err := s.client.Set(ctx, resetTokenKey(kind, token), value, ttl).Err(); err != nil {
+		return "", fmt.Errorf("保存找回密码凭证失败: %w", err)
+	}
+	return token, nil
+}
+
+// Consume 原子地读取并删除凭证（GETDEL），保证凭证只能使用一次
+func (s *RedisPasswordResetStore) Consume(ctx context.Context, kind, token string) (*ResetSubject, error) {
+	if token == "" {
+		return nil, ErrResetTokenInvalid
+	}
+	value, err := s.client.GetDel(ctx, resetTokenKey(kind, token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrResetTokenInvalid
+		}
+		return nil, fmt.Errorf("读取找回密码凭证失败: %w", err)
+	}
+
+	userType, rawID, ok := strings.Cut(value, ":")
+	userID, convErr := strconv.ParseInt(rawID, 10, 64)
+	if !ok || convErr != nil {
+		return nil, ErrResetTokenInvalid
+	}
+	return &ResetSubject{UserID: userID, UserType: userType}, nil
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+// Signer 单个签名密钥的抽象
+//
+// 每个 Signer 绑定一个 kid 与一种算法。签发时 kid 写入令牌头部，验证时据此找回对应密钥，
+// 从而在密钥轮换期间让新旧多把密钥同时可用于验证。
+// 仅持有公钥的 Signer 只能验证，Sign 返回 ErrNoSigningKey。
+type Signer interface {
+	// KeyID 返回密钥标识（令牌头部的 kid）
+	KeyID() string
+	// Method 返回签名算法
+	Method() jwt.SigningMethod
+	// Sign 签发令牌，自动写入 kid 头部
+	Sign(claims jwt.Claims) (string, error)
+	// VerifyKey 返回验证签名所用的密钥
+	VerifyKey() interface{}
+	// JWK 返回可公开的公钥描述；对称密钥不可公开，返回 false
+	JWK() (JWK, bool)
+}
+
+// baseSigner 封装各算法共用的签发逻辑
+type baseSigner struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func (s *baseSigner) KeyID() string             { return s.kid }
+func (s *baseSigner) Method() jwt.SigningMethod { return s.method }
+func (s *baseSigner) VerifyKey() interface{}    { return s.verifyKey }
+
+func (s *baseSigner) Sign(claims jwt.Claims) (string, error) {
+	if s.signKey == nil {
+		return "", ErrNoSigningKey
+	}
+	token := jwt.NewWithClaims(s.method, claims)
+	if s.kid != "" {
+		token.Header["kid"] = s.kid
+	}
+	return token.SignedString(s.signKey)
+}
+
+// #region HS256
+
+// hmacSigner HS256 对称签名
+type hmacSigner struct {
+	baseSigner
+}
+
+// NewHS256Signer 创建 HS256 签名器
+func NewHS256Signer(kid string, secret []byte) (Signer, error) {
+	if len(secret) == 0 {
+		return nil, ErrSecretNotConfigured
+	}
+	return &hmacSigner{baseSigner{kid: kid, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}}, nil
+}
+
+// JWK 对称密钥不对外公开
+func (s *hmacSigner) JWK() (JWK, bool) { return JWK{}, false }
+
+// #endregion
+
+// #region RS256
+
+// rsaSigner RS256 非对称签名
+type rsaSigner struct {
+	baseSigner
+	pub *rsa.PublicKey
+}
+
+// NewRS256Signer 创建 RS256 签名器；priv 为空时仅用于验证
+func NewRS256Signer(kid string, priv *rsa.PrivateKey, pub *rsa.PublicKey) (Signer, error) {
+	if pub == nil && priv != nil {
+		pub = &priv.PublicKey
+	}
+	if pub == nil {
+		return nil, fmt.Errorf("%w: RS256 缺少公钥", ErrKeyLoad)
+	}
+	if priv != nil && !priv.PublicKey.Equal(pub) {
+		return nil, fmt.Errorf("%w: RS256 公私钥不匹配", ErrKeyLoad)
+	}
+	s := &rsaSigner{baseSigner: baseSigner{kid: kid, method: jwt.SigningMethodRS256, verifyKey: pub}, pub: pub}
+	if priv != nil {
+		s.signKey = priv
+	}
+	return s, nil
+}
+
+// JWK 导出 RSA 公钥（RFC 7518 6.3）
+func (s *rsaSigner) JWK() (JWK, bool) {
+	return JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: AlgorithmRS256,
+		Kid: s.kid,
+		N:   base64.RawURLEncoding.EncodeToString(s.pub.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.pub.E)).Bytes()),
+	}, true
+}
+
+// #endregion
+
+// #region ES256
+
+// ecdsaSigner ES256 非对称签名（P-256 曲线）
+type ecdsaSigner struct {
+	baseSigner
+	pub *ecdsa.PublicKey
+}
+
+// NewES256Signer 创建 ES256 签名器；priv 为空时仅用于验证
+func NewES256Signer(kid string, priv *ecdsa.PrivateKey, pub *ecdsa.PublicKey) (Signer, error) {
+	if pub == nil && priv != nil {
+		pub = &priv.PublicKey
+	}
+	if pub == nil {
+		return nil, fmt.Errorf("%w: ES256 缺少公钥", ErrKeyLoad)
+	}
+	if priv != nil && !priv.PublicKey.Equal(pub) {
+		return nil, fmt.Errorf("%w: ES256 公私钥不匹配", ErrKeyLoad)
+	}
+	if pub.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("%w: ES256 要求 P-256 曲线", ErrKeyLoad)
+	}
+	s := &ecdsaSigner{baseSigner: baseSigner{kid: kid, method: jwt.SigningMethodES256, verifyKey: pub}, pub: pub}
+	if priv != nil {
+		s.signKey = priv
+	}
+	return s, nil
+}
+
+// JWK 导出 EC 公钥（RFC 7518 6.2），坐标按曲线长度定长编码
+func (s *ecdsaSigner) JWK() (JWK, bool) {
+	ecdhKey, err := s.pub.ECDH()
+	if err != nil {
+		return JWK{}, false
+	}
+	// 未压缩点格式：0x04 || X || Y
+	raw := ecdhKey.Bytes()[1:]
+	size := len(raw) / 2
+	return JWK{
+		Kty: "EC",
+		Use: "sig",
+		Alg: AlgorithmES256,
+		Kid: s.kid,
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(raw[:size]),
+		Y:   base64.RawURLEncoding.EncodeToString(raw[size:]),
+	}, true
+}
+
+// #endregion
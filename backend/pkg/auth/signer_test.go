@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writePEM 将密钥写入临时 PEM 文件并返回路径
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func rsaKeyFiles(t *testing.T) (privPath, pubPath string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	return writePEM(t, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)),
+		writePEM(t, "rsa.pub.pem", "PUBLIC KEY", pubDER)
+}
+
+func ecKeyFiles(t *testing.T) (privPath, pubPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	privDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	return writePEM(t, "ec.pem", "EC PRIVATE KEY", privDER), writePEM(t, "ec.pub.pem", "PUBLIC KEY", pubDER)
+}
+
+func TestGenerateAndVerify_AllAlgorithms(t *testing.T) {
+	rsaPriv, _ := rsaKeyFiles(t)
+	ecPriv, _ := ecKeyFiles(t)
+
+	cases := []KeyConfig{
+		{KeyID: "hs-1", Algorithm: AlgorithmHS256, Secret: "secret"},
+		{KeyID: "rs-1", Algorithm: AlgorithmRS256, PrivateKeyFile: rsaPriv},
+		{KeyID: "es-1", Algorithm: AlgorithmES256, PrivateKeyFile: ecPriv},
+	}
+
+	for _, kc := range cases {
+		t.Run(kc.Algorithm, func(t *testing.T) {
+			keys, err := LoadKeyProvider(kc, nil)
+			if err != nil {
+				t.Fatalf("LoadKeyProvider: %v", err)
+			}
+			cfg := JWTConfig{ExpiresIn: 60, Issuer: "the-pass", Audience: "api", Keys: keys}
+
+			token, err := GenerateToken(9, "merchant", cfg)
+			if err != nil {
+				t.Fatalf("GenerateToken: %v", err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified: %v", err)
+			}
+			if parsed.Header["kid"] != kc.KeyID || parsed.Method.Alg() != kc.Algorithm {
+				t.Fatalf("header=%v want kid=%s alg=%s", parsed.Header, kc.KeyID, kc.Algorithm)
+			}
+
+			claims, err := VerifyToken(token, cfg)
+			if err != nil {
+				t.Fatalf("VerifyToken: %v", err)
+			}
+			if claims.UserID != 9 || claims.UserType != "merchant" || claims.NotBefore == nil {
+				t.Fatalf("claims=%+v", claims)
+			}
+		})
+	}
+}
+
+func TestVerifyToken_KeyRotation(t *testing.T) {
+	oldPriv, oldPub := rsaKeyFiles(t)
+	newPriv, _ := ecKeyFiles(t)
+
+	oldKeys, err := LoadKeyProvider(KeyConfig{KeyID: "2025", Algorithm: AlgorithmRS256, PrivateKeyFile: oldPriv}, nil)
+	if err != nil {
+		t.Fatalf("LoadKeyProvider old: %v", err)
+	}
+	oldToken, err := GenerateToken(1, "user", JWTConfig{ExpiresIn: 60, Keys: oldKeys})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	// 轮换：新密钥签发，旧密钥仅保留公钥用于验证
+	rotated, err := LoadKeyProvider(
+		KeyConfig{KeyID: "2026", Algorithm: AlgorithmES256, PrivateKeyFile: newPriv},
+		[]KeyConfig{{KeyID: "2025", Algorithm: AlgorithmRS256, PublicKeyFile: oldPub}},
+	)
+	if err != nil {
+		t.Fatalf("LoadKeyProvider rotated: %v", err)
+	}
+	rotatedCfg := JWTConfig{ExpiresIn: 60, Keys: rotated}
+	if _, err := VerifyToken(oldToken, rotatedCfg); err != nil {
+		t.Fatalf("old token after rotation: %v", err)
+	}
+
+	jwks := BuildJWKSet(rotated)
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "2026" || jwks.Keys[0].Kty != "EC" || jwks.Keys[1].Kty != "RSA" {
+		t.Fatalf("jwks=%+v", jwks)
+	}
+
+	// 旧密钥下线后，旧令牌的 kid 无法识别
+	retired, err := LoadKeyProvider(KeyConfig{KeyID: "2026", Algorithm: AlgorithmES256, PrivateKeyFile: newPriv}, nil)
+	if err != nil {
+		t.Fatalf("LoadKeyProvider retired: %v", err)
+	}
+	if _, err := VerifyToken(oldToken, JWTConfig{ExpiresIn: 60, Keys: retired}); !errors.Is(err, ErrUnknownKeyID) {
+		t.Fatalf("retired key err=%v want ErrUnknownKeyID", err)
+	}
+}
+
+func TestVerifyToken_RejectsAlgorithmConfusion(t *testing.T) {
+	_, rsaPub := rsaKeyFiles(t)
+	pubPEM, err := os.ReadFile(rsaPub)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	activePriv, _ := rsaKeyFiles(t)
+	keys, err := LoadKeyProvider(
+		KeyConfig{KeyID: "rs", Algorithm: AlgorithmRS256, PrivateKeyFile: activePriv},
+		[]KeyConfig{{KeyID: "rs-old", Algorithm: AlgorithmRS256, PublicKeyFile: rsaPub}},
+	)
+	if err != nil {
+		t.Fatalf("LoadKeyProvider: %v", err)
+	}
+
+	// 攻击者用公开的 RSA 公钥作为 HMAC 密钥伪造令牌
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID:   1,
+		UserType: "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+	forged.Header["kid"] = "rs-old"
+	token, err := forged.SignedString(pubPEM)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+
+	if _, err := VerifyToken(token, JWTConfig{Keys: keys}); !errors.Is(err, ErrAlgorithmMismatch) {
+		t.Fatalf("err=%v want ErrAlgorithmMismatch", err)
+	}
+}
+
+func TestVerifyToken_ClaimsValidation(t *testing.T) {
+	cfg := JWTConfig{SecretKey: "secret", ExpiresIn: 60, Issuer: "the-pass", Audience: "api"}
+	token, err := GenerateToken(1, "user", cfg)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	wrongIssuer := cfg
+	wrongIssuer.Issuer = "someone-else"
+	if _, err := VerifyToken(token, wrongIssuer); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("issuer mismatch err=%v", err)
+	}
+
+	wrongAudience := cfg
+	wrongAudience.Audience = "admin"
+	if _, err := VerifyToken(token, wrongAudience); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("audience mismatch err=%v", err)
+	}
+
+	// nbf 在未来 30 秒：无偏差容忍时拒绝，Leeway 覆盖时接受
+	signer, _ := NewHS256Signer("", []byte("secret"))
+	now := time.Now()
+	early, err := signer.Sign(&Claims{
+		UserID:   1,
+		UserType: "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "the-pass",
+			Audience:  jwt.ClaimStrings{"api"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			NotBefore: jwt.NewNumericDate(now.Add(30 * time.Second)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if _, err := VerifyToken(early, cfg); !errors.Is(err, ErrTokenNotYetValid) {
+		t.Fatalf("nbf err=%v want ErrTokenNotYetValid", err)
+	}
+	lenient := cfg
+	lenient.Leeway = 60
+	if _, err := VerifyToken(early, lenient); err != nil {
+		t.Fatalf("nbf within leeway: %v", err)
+	}
+}
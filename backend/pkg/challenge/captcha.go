@@ -0,0 +1,174 @@
+package challenge
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	mrand "math/rand/v2"
+	"strings"
+)
+
+// 图片验证码尺寸
+const (
+	captchaLength = 5
+	captchaWidth  = 160
+	captchaHeight = 50
+	captchaScale  = 4 // 字模放大倍数
+	captchaNoise  = 6 // 干扰线条数
+)
+
+// captchaGlyphs 5x7 点阵数字字模，每行低 5 位从左到右
+var captchaGlyphs = [10][7]uint8{
+	{0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110}, // 0
+	{0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110}, // 1
+	{0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111}, // 2
+	{0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110}, // 3
+	{0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010}, // 4
+	{0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110}, // 5
+	{0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110}, // 6
+	{0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000}, // 7
+	{0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110}, // 8
+	{0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100}, // 9
+}
+
+// ImageCaptcha 内置数字图片验证码
+//
+// 字符随机上下偏移、逐点抖动并叠加干扰线与噪点，答案保存在服务端，客户端只拿到 PNG 图片
+type ImageCaptcha struct{}
+
+// NewImageCaptcha 创建图片验证码挑战
+func NewImageCaptcha() *ImageCaptcha {
+	return &ImageCaptcha{}
+}
+
+// Kind 挑战类型
+func (c *ImageCaptcha) Kind() string {
+	return KindCaptcha
+}
+
+// New 生成随机数字并渲染为 PNG（data URI），校验数据为答案本身
+func (c *ImageCaptcha) New() (Challenge, string, error) {
+	answer, err := randomDigits(captchaLength)
+	if err != nil {
+		return Challenge{}, "", err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, renderCaptcha(answer)); err != nil {
+		return Challenge{}, "", err
+	}
+	dataURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	return Challenge{Image: dataURI}, answer, nil
+}
+
+// Verify 常量时间比对答案（忽略首尾空白）
+func (c *ImageCaptcha) Verify(secret, answer string) bool {
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(strings.TrimSpace(answer))) == 1
+}
+
+// randomDigits 生成 n 位随机数字（丢弃 250 以上的字节以避免取模偏差）
+func randomDigits(n int) (string, error) {
+	digits := make([]byte, 0, n)
+	buf := make([]byte, n)
+	for len(digits) < n {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if b < 250 && len(digits) < n {
+				digits = append(digits, '0'+b%10)
+			}
+		}
+	}
+	return string(digits), nil
+}
+
+// renderCaptcha 绘制验证码图片
+func renderCaptcha(answer string) *image.Paletted {
+	palette := color.Palette{
+		color.RGBA{0xf5, 0xf5, 0xf0, 0xff},
+		color.RGBA{0x2a, 0x3b, 0x8f, 0xff},
+		color.RGBA{0x8f, 0x2a, 0x3b, 0xff},
+		color.RGBA{0x2a, 0x6f, 0x3b, 0xff},
+		color.RGBA{0x9a, 0x9a, 0xa8, 0xff},
+	}
+	img := image.NewPaletted(image.Rect(0, 0, captchaWidth, captchaHeight), palette)
+
+	glyphWidth := 5 * captchaScale
+	step := (captchaWidth - 16) / len(answer)
+	for i, ch := range answer {
+		glyph := captchaGlyphs[ch-'0']
+		ink := uint8(1 + mrand.IntN(3))
+		x0 := 8 + i*step + mrand.IntN(step-glyphWidth+1)
+		y0 := 4 + mrand.IntN(captchaHeight-7*captchaScale-8)
+		for row, bitsRow := range glyph {
+			for col := 0; col < 5; col++ {
+				if bitsRow&(1<<(4-col)) == 0 {
+					continue
+				}
+				// 每个点阵单元随机抖动，破坏字形的规则边缘
+				x := x0 + col*captchaScale + mrand.IntN(3) - 1
+				y := y0 + row*captchaScale + mrand.IntN(3) - 1
+				fillRect(img, x, y, captchaScale, captchaScale, ink)
+			}
+		}
+	}
+
+	for i := 0; i < captchaNoise; i++ {
+		drawLine(img,
+			mrand.IntN(captchaWidth), mrand.IntN(captchaHeight),
+			mrand.IntN(captchaWidth), mrand.IntN(captchaHeight),
+			uint8(1+mrand.IntN(len(palette)-1)))
+	}
+	for i := 0; i < captchaWidth*captchaHeight/20; i++ {
+		img.SetColorIndex(mrand.IntN(captchaWidth), mrand.IntN(captchaHeight), uint8(mrand.IntN(len(palette))))
+	}
+	return img
+}
+
+func fillRect(img *image.Paletted, x, y, w, h int, index uint8) {
+	for dy := 0; dy < h; dy++ {
+		for dx := 0; dx < w; dx++ {
+			img.SetColorIndex(x+dx, y+dy, index)
+		}
+	}
+}
+
+// drawLine Bresenham 画线
+func drawLine(img *image.Paletted, x0, y0, x1, y1 int, index uint8) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.SetColorIndex(x0, y0, index)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
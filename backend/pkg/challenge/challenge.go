@@ -0,0 +1,215 @@
+// Package challenge 提供发送短信前的人机验证
+//
+// 公开的短信发送接口只能按手机号限流，攻击者轮换号码即可消耗短信额度（短信轰炸 / SMS pumping）。
+// Gate 根据风险信号（同一 IP、目标手机号、设备的发送量）决定是否要求验证以及验证强度：
+//
+//	风险低    → 直接放行
+//	风险升高  → 工作量证明（hashcash，浏览器后台计算，用户无感）
+//	风险高    → 图片验证码
+//
+// 挑战的答案校验数据保存在 Redis 中，校验时原子读取并删除，每个挑战只能提交一次。
+package challenge
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 挑战类型
+const (
+	KindPoW     = "pow"
+	KindCaptcha = "captcha"
+)
+
+// defaultChallengeTTL 挑战默认有效期
+const defaultChallengeTTL = 2 * time.Minute
+
+// Challenge 下发给客户端的挑战
+type Challenge struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Image      string    `json:"image,omitempty"`      // 图片验证码，data:image/png;base64,...
+	Prefix     string    `json:"prefix,omitempty"`     // 工作量证明前缀
+	Difficulty int       `json:"difficulty,omitempty"` // 工作量证明要求的前导零比特数
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Challenger 一种人机验证实现
+type Challenger interface {
+	// Kind 挑战类型，如 pow / captcha
+	Kind() string
+
+	// New 生成挑战，返回下发给客户端的内容与服务端保存的校验数据
+	New() (Challenge, string, error)
+
+	// Verify 校验客户端答案，secret 为 New 返回的校验数据
+	Verify(secret, answer string) bool
+}
+
+// Risk 风险等级
+type Risk int
+
+const (
+	RiskNone     Risk = iota // 无需验证
+	RiskElevated             // 风险升高
+	RiskHigh                 // 高风险
+)
+
+// Subject 一次发送请求的风险信号来源
+type Subject struct {
+	IP       string // 客户端 IP
+	Phone    string // 目标手机号
+	DeviceID string // 客户端上报的设备标识，未上报时为空
+}
+
+// RiskAssessor 风险评估
+//
+// Assess 每次调用视为一次发送请求（会计入统计）
+type RiskAssessor interface {
+	Assess(ctx context.Context, subject Subject) (Risk, error)
+}
+
+// Store 挑战校验数据存储
+type Store interface {
+	// Save 保存挑战校验数据
+	Save(ctx context.Context, id, kind, secret string, ttl time.Duration) error
+
+	// Take 原子读取并删除挑战，不存在时返回 ErrChallengeFailed
+	Take(ctx context.Context, id string) (kind, secret string, err error)
+}
+
+// GateConfig 验证关卡配置
+//
+// 字段说明：
+//   - TTL: 挑战有效期，默认 2 分钟
+//   - Challengers: 各风险等级要求的挑战，未配置的等级沿用较低等级的挑战；
+//     提交的挑战强度不低于当前要求即可通过（如已完成验证码的请求不再要求工作量证明）
+type GateConfig struct {
+	TTL         time.Duration
+	Challengers map[Risk]Challenger
+}
+
+// Gate 人机验证关卡
+type Gate struct {
+	store Store
+	risk  RiskAssessor
+	ttl   time.Duration
+
+	challengers map[Risk]Challenger
+	kinds       map[string]Risk // 挑战类型对应的强度
+	now         func() time.Time
+}
+
+// NewGate 创建人机验证关卡
+func NewGate(store Store, risk RiskAssessor, cfg GateConfig) *Gate {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultChallengeTTL
+	}
+	g := &Gate{
+		store:       store,
+		risk:        risk,
+		ttl:         cfg.TTL,
+		challengers: make(map[Risk]Challenger, len(cfg.Challengers)),
+		kinds:       make(map[string]Risk, len(cfg.Challengers)),
+		now:         time.Now,
+	}
+	for level, ch := range cfg.Challengers {
+		g.challengers[level] = ch
+		g.kinds[ch.Kind()] = level
+	}
+	return g
+}
+
+// Check 评估请求风险并校验客户端提交的挑战
+//
+// 返回：
+//   - nil, nil: 放行
+//   - 新挑战, ErrChallengeRequired: 需要验证但未提交挑战
+//   - 新挑战, ErrChallengeFailed: 提交的挑战无效、强度不足或答案错误（原挑战已作废）
+//   - nil, 其他错误: 存储访问失败
+func (g *Gate) Check(ctx context.Context, subject Subject, id, answer string) (*Challenge, error) {
+	risk, err := g.risk.Assess(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	required := g.challengerFor(risk)
+	if required == nil {
+		return nil, nil
+	}
+
+	reason := ErrChallengeRequired
+	if id != "" {
+		passed, err := g.verify(ctx, id, answer, g.kinds[required.Kind()])
+		if err != nil {
+			return nil, err
+		}
+		if passed {
+			return nil, nil
+		}
+		reason = ErrChallengeFailed
+	}
+
+	next, err := g.issue(ctx, required)
+	if err != nil {
+		return nil, err
+	}
+	return next, reason
+}
+
+// verify 取出并校验挑战，无论成败挑战都已作废
+func (g *Gate) verify(ctx context.Context, id, answer string, minRisk Risk) (bool, error) {
+	kind, secret, err := g.store.Take(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrChallengeFailed) {
+			return false, nil
+		}
+		return false, err
+	}
+	level, ok := g.kinds[kind]
+	if !ok || level < minRisk {
+		return false, nil
+	}
+	return g.challengers[level].Verify(secret, answer), nil
+}
+
+// challengerFor 取不高于给定风险等级的最强挑战
+func (g *Gate) challengerFor(risk Risk) Challenger {
+	for level := risk; level > RiskNone; level-- {
+		if ch, ok := g.challengers[level]; ok {
+			return ch
+		}
+	}
+	return nil
+}
+
+// issue 生成并保存新挑战
+func (g *Gate) issue(ctx context.Context, ch Challenger) (*Challenge, error) {
+	c, secret, err := ch.New()
+	if err != nil {
+		return nil, err
+	}
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.store.Save(ctx, id, ch.Kind(), secret, g.ttl); err != nil {
+		return nil, err
+	}
+	c.ID = id
+	c.Kind = ch.Kind()
+	c.ExpiresAt = g.now().Add(g.ttl)
+	return &c, nil
+}
+
+// randomHex 生成 n 字节的随机十六进制串
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,163 @@
+package challenge
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestGate(t *testing.T) (*Gate, *RedisStore) {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	store := NewRedisStore(client)
+	gate := NewGate(store, NewVolumeRisk(client, VolumePolicy{FreeSends: 1, CaptchaAfter: 3}), GateConfig{
+		TTL: time.Minute,
+		Challengers: map[Risk]Challenger{
+			RiskElevated: NewProofOfWork(8),
+			RiskHigh:     NewImageCaptcha(),
+		},
+	})
+	return gate, store
+}
+
+// solvePoW 暴力求解工作量证明
+func solvePoW(t *testing.T, c *Challenge) string {
+	t.Helper()
+	pow := NewProofOfWork(c.Difficulty)
+	secret := strconv.Itoa(c.Difficulty) + ":" + c.Prefix
+	for i := 0; i < 1<<20; i++ {
+		if nonce := strconv.Itoa(i); pow.Verify(secret, nonce) {
+			return nonce
+		}
+	}
+	t.Fatal("no nonce found")
+	return ""
+}
+
+func TestGate_EscalatesWithIPVolume(t *testing.T) {
+	gate, store := newTestGate(t)
+	ctx := context.Background()
+	subject := Subject{IP: "203.0.113.7", Phone: "13800000001", DeviceID: "device-1"}
+
+	// 窗口内第一次请求免验证
+	if next, err := gate.Check(ctx, subject, "", ""); err != nil || next != nil {
+		t.Fatalf("first request: next=%+v err=%v", next, err)
+	}
+
+	// 第二次要求工作量证明
+	next, err := gate.Check(ctx, subject, "", "")
+	if !errors.Is(err, ErrChallengeRequired) || next == nil || next.Kind != KindPoW || next.Difficulty != 8 {
+		t.Fatalf("second request: next=%+v err=%v", next, err)
+	}
+	nonce := solvePoW(t, next)
+	if _, err := gate.Check(ctx, subject, next.ID, nonce); err != nil {
+		t.Fatalf("solved pow rejected: %v", err)
+	}
+
+	// 挑战只能提交一次；此时已超过阈值，换发图片验证码
+	retry, err := gate.Check(ctx, subject, next.ID, nonce)
+	if !errors.Is(err, ErrChallengeFailed) || retry == nil || retry.Kind != KindCaptcha {
+		t.Fatalf("replayed pow: next=%+v err=%v", retry, err)
+	}
+	if !strings.HasPrefix(retry.Image, "data:image/png;base64,") {
+		t.Fatalf("captcha image=%.40q", retry.Image)
+	}
+
+	// 高风险时工作量证明强度不足
+	weak, err := gate.issue(ctx, NewProofOfWork(8))
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if _, err := gate.Check(ctx, subject, weak.ID, solvePoW(t, weak)); !errors.Is(err, ErrChallengeFailed) {
+		t.Fatalf("pow under high risk: err=%v want ErrChallengeFailed", err)
+	}
+
+	// 其他 IP、号码与设备不受影响
+	other := Subject{IP: "198.51.100.1", Phone: "13800000002", DeviceID: "device-2"}
+	if next, err := gate.Check(ctx, other, "", ""); err != nil || next != nil {
+		t.Fatalf("other subject: next=%+v err=%v", next, err)
+	}
+
+	// 错误答案同样作废挑战
+	if next, err := gate.Check(ctx, subject, retry.ID, "wrong"); !errors.Is(err, ErrChallengeFailed) || next == nil || next.ID == retry.ID {
+		t.Fatalf("wrong captcha answer: next=%+v err=%v", next, err)
+	}
+	if _, _, err := store.Take(ctx, retry.ID); !errors.Is(err, ErrChallengeFailed) {
+		t.Fatalf("captcha should have been consumed by the failed attempt: %v", err)
+	}
+}
+
+func TestGate_EscalatesWithPhoneAndDeviceVolume(t *testing.T) {
+	gate, _ := newTestGate(t)
+	ctx := context.Background()
+
+	cases := []struct {
+		name     string
+		subjects [2]Subject
+	}{
+		// 轮换 IP 与设备轰炸同一号码
+		{"same phone", [2]Subject{
+			{IP: "203.0.113.1", Phone: "13800000010", DeviceID: "a1"},
+			{IP: "203.0.113.2", Phone: "13800000010", DeviceID: "a2"},
+		}},
+		// 同一设备轮换 IP 与号码
+		{"same device", [2]Subject{
+			{IP: "203.0.113.3", Phone: "13800000011", DeviceID: "b"},
+			{IP: "203.0.113.4", Phone: "13800000012", DeviceID: "b"},
+		}},
+		// 未上报设备标识的请求共用一个计数桶
+		{"missing device", [2]Subject{
+			{IP: "203.0.113.5", Phone: "13800000013"},
+			{IP: "203.0.113.6", Phone: "13800000014"},
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if next, err := gate.Check(ctx, tc.subjects[0], "", ""); err != nil || next != nil {
+				t.Fatalf("first request: next=%+v err=%v", next, err)
+			}
+			if next, err := gate.Check(ctx, tc.subjects[1], "", ""); !errors.Is(err, ErrChallengeRequired) || next == nil {
+				t.Fatalf("second request: next=%+v err=%v want challenge", next, err)
+			}
+		})
+	}
+}
+
+func TestImageCaptcha(t *testing.T) {
+	captcha := NewImageCaptcha()
+	c, answer, err := captcha.New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if len(answer) != captchaLength || strings.Trim(answer, "0123456789") != "" {
+		t.Fatalf("answer=%q", answer)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(c.Image, "data:image/png;base64,"))
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(raw))
+	if err != nil || img.Bounds().Dx() != captchaWidth || img.Bounds().Dy() != captchaHeight {
+		t.Fatalf("png: err=%v", err)
+	}
+
+	if !captcha.Verify(answer, " "+answer+" ") || captcha.Verify(answer, "") || captcha.Verify(answer, "00000x") {
+		t.Fatal("unexpected verify result")
+	}
+}
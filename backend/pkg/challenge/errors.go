@@ -0,0 +1,11 @@
+package challenge
+
+import "errors"
+
+var (
+	// ErrChallengeRequired 当前风险等级要求先完成人机验证
+	ErrChallengeRequired = errors.New("请先完成人机验证")
+
+	// ErrChallengeFailed 人机验证答案错误，或挑战不存在、已使用、已过期
+	ErrChallengeFailed = errors.New("人机验证未通过或已失效")
+)
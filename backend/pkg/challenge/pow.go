@@ -0,0 +1,72 @@
+package challenge
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPoWDifficulty 默认难度：约 2^20 次 SHA-256，浏览器中约 1 秒
+	DefaultPoWDifficulty = 20
+	maxPoWDifficulty     = 32
+	maxPoWAnswerLength   = 64
+)
+
+// ProofOfWork hashcash 风格的工作量证明
+//
+// 客户端需找到字符串 nonce，使 SHA-256(prefix + nonce) 至少有 Difficulty 个前导零比特；
+// 服务端只需计算一次哈希即可校验
+type ProofOfWork struct {
+	difficulty int
+}
+
+// NewProofOfWork 创建工作量证明挑战，difficulty <= 0 时使用默认难度，上限 32
+func NewProofOfWork(difficulty int) *ProofOfWork {
+	if difficulty <= 0 {
+		difficulty = DefaultPoWDifficulty
+	}
+	if difficulty > maxPoWDifficulty {
+		difficulty = maxPoWDifficulty
+	}
+	return &ProofOfWork{difficulty: difficulty}
+}
+
+// Kind 挑战类型
+func (p *ProofOfWork) Kind() string {
+	return KindPoW
+}
+
+// New 生成随机前缀，校验数据为 "难度:前缀"
+func (p *ProofOfWork) New() (Challenge, string, error) {
+	prefix, err := randomHex(16)
+	if err != nil {
+		return Challenge{}, "", err
+	}
+	return Challenge{Prefix: prefix, Difficulty: p.difficulty}, fmt.Sprintf("%d:%s", p.difficulty, prefix), nil
+}
+
+// Verify 校验 nonce
+func (p *ProofOfWork) Verify(secret, answer string) bool {
+	rawDifficulty, prefix, ok := strings.Cut(secret, ":")
+	difficulty, err := strconv.Atoi(rawDifficulty)
+	if !ok || err != nil || answer == "" || len(answer) > maxPoWAnswerLength {
+		return false
+	}
+	sum := sha256.Sum256([]byte(prefix + answer))
+	return leadingZeroBits(sum[:]) >= difficulty
+}
+
+// leadingZeroBits 计算字节串的前导零比特数
+func leadingZeroBits(b []byte) int {
+	n := 0
+	for _, v := range b {
+		if v != 0 {
+			return n + bits.LeadingZeros8(v)
+		}
+		n += 8
+	}
+	return n
+}
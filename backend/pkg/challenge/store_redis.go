@@ -0,0 +1,145 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	challengeKeyPrefix  = "challenge:"
+	riskIPKeyPrefix     = "challenge:risk:ip:"
+	riskPhoneKeyPrefix  = "challenge:risk:phone:"
+	riskDeviceKeyPrefix = "challenge:risk:device:"
+)
+
+// #region 挑战存储
+
+// RedisStore Redis 实现的挑战存储，多实例共享
+//
+// Redis 键命名规范：
+//   - challenge:{id}  值为 {kind}:{secret}，TTL 为挑战有效期
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore 创建 Redis 挑战存储
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Save 保存挑战校验数据
+func (s *RedisStore) Save(ctx context.Context, id, kind, secret string, ttl time.Duration) error {
+	if err := s.client.Set(ctx, challengeKeyPrefix+id, kind+":"+secret, ttl).Err(); err != nil {
+		return fmt.Errorf("保存人机验证挑战失败: %w", err)
+	}
+	return nil
+}
+
+// Take 原子读取并删除挑战（GETDEL），保证每个挑战只能提交一次
+func (s *RedisStore) Take(ctx context.Context, id string) (string, string, error) {
+	value, err := s.client.GetDel(ctx, challengeKeyPrefix+id).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", "", ErrChallengeFailed
+		}
+		return "", "", fmt.Errorf("读取人机验证挑战失败: %w", err)
+	}
+	kind, secret, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", "", ErrChallengeFailed
+	}
+	return kind, secret, nil
+}
+
+// #endregion
+
+// #region 发送量风险评估
+
+// 各维度请求计数自增，首次计数时设置窗口过期时间，返回各维度中最大的窗口内请求数
+var luaIncrMaxScript = redis.NewScript(`
+local max = 0
+for _, key in ipairs(KEYS) do
+  local count = redis.call('INCR', key)
+  if count == 1 then
+    redis.call('PEXPIRE', key, ARGV[1])
+  end
+  if count > max then
+    max = count
+  end
+end
+return max
+`)
+
+// unknownDevice 未上报设备标识的请求共用的设备计数桶
+const unknownDevice = "unknown"
+
+// VolumePolicy 按发送量评估风险的阈值，零值字段使用默认值；IP、手机号、设备各自计数，取最大值比较
+//
+// 字段说明：
+//   - Window: 统计窗口，默认 1 小时
+//   - FreeSends: 窗口内免验证的请求数，默认 3
+//   - CaptchaAfter: 窗口内请求数超过该值视为高风险，默认 10
+type VolumePolicy struct {
+	Window       time.Duration
+	FreeSends    int
+	CaptchaAfter int
+}
+
+// VolumeRisk 按同一 IP、同一目标手机号、同一设备在窗口内的发送请求数评估风险
+//
+// 只按 IP 计数时，攻击者轮换代理 IP 即可对同一号码持续轰炸；手机号与设备维度补上这一缺口。
+// 未上报设备标识的请求共用一个设备计数桶，很快就会要求验证。
+//
+// Redis 键命名规范：
+//   - challenge:risk:ip:{ip}          IP 请求计数，TTL 为统计窗口（首次请求时设置）
+//   - challenge:risk:phone:{phone}    目标手机号请求计数
+//   - challenge:risk:device:{device}  设备请求计数，未上报时为 challenge:risk:device:unknown
+type VolumeRisk struct {
+	client *redis.Client
+	policy VolumePolicy
+}
+
+// NewVolumeRisk 创建发送量风险评估
+func NewVolumeRisk(client *redis.Client, policy VolumePolicy) *VolumeRisk {
+	if policy.Window <= 0 {
+		policy.Window = time.Hour
+	}
+	if policy.FreeSends <= 0 {
+		policy.FreeSends = 3
+	}
+	if policy.CaptchaAfter <= 0 {
+		policy.CaptchaAfter = 10
+	}
+	return &VolumeRisk{client: client, policy: policy}
+}
+
+// Assess 在各维度计入一次请求并返回风险等级
+func (r *VolumeRisk) Assess(ctx context.Context, subject Subject) (Risk, error) {
+	device := subject.DeviceID
+	if device == "" {
+		device = unknownDevice
+	}
+	keys := []string{riskIPKeyPrefix + subject.IP, riskDeviceKeyPrefix + device}
+	if subject.Phone != "" {
+		keys = append(keys, riskPhoneKeyPrefix+subject.Phone)
+	}
+
+	count, err := luaIncrMaxScript.Run(ctx, r.client, keys, r.policy.Window.Milliseconds()).Int()
+	if err != nil {
+		return RiskNone, fmt.Errorf("统计发送量失败: %w", err)
+	}
+	switch {
+	case count > r.policy.CaptchaAfter:
+		return RiskHigh, nil
+	case count > r.policy.FreeSends:
+		return RiskElevated, nil
+	}
+	return RiskNone, nil
+}
+
+// #endregion
@@ -0,0 +1,154 @@
+// Package clock 提供可替换的时间源
+//
+// 业务代码依赖 Clock 接口而非直接调用 time 包，测试中注入 Fake 即可精确推进时间、
+// 确定性地触发超时回调，而无需真实等待。
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock 时间源
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+	// AfterFunc 在 d 之后于独立的 goroutine（Fake 为调用 Advance 的 goroutine）中执行 f
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer 可取消的定时器
+type Timer interface {
+	// Stop 取消定时器；定时器已触发或已取消时返回 false
+	Stop() bool
+}
+
+// #region 真实时钟
+
+type realClock struct{}
+
+// Real 返回基于 time 包的系统时钟
+func Real() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// #endregion
+
+// #region 假时钟
+
+// Fake 测试用时钟：时间只在调用 Advance / Set 时前进，到期的定时器按到期顺序同步执行
+type Fake struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock   *Fake
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+// NewFake 创建从 start 开始的假时钟
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+// Now 返回假时钟的当前时间
+func (c *Fake) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc 注册定时器，d <= 0 时在下一次 Advance 中触发
+func (c *Fake) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance 将时间推进 d，并依次执行期间到期的定时器
+// 回调中新注册的定时器若也在推进范围内，同样会被执行
+func (c *Fake) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+	c.Set(target)
+}
+
+// Set 将时间设置为 t（不可回拨），并执行到期的定时器
+func (c *Fake) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+		due := c.nextDueLocked(t)
+		if due == nil {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mu.Unlock()
+			return
+		}
+		if due.at.After(c.now) {
+			c.now = due.at
+		}
+		due.stopped = true
+		c.mu.Unlock()
+
+		due.f()
+	}
+}
+
+// PendingTimers 返回尚未触发且未取消的定时器数量
+func (c *Fake) PendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, t := range c.timers {
+		if !t.stopped {
+			n++
+		}
+	}
+	return n
+}
+
+// nextDueLocked 取出最早到期（不晚于 t）的定时器，并清理已停止的定时器
+func (c *Fake) nextDueLocked(t time.Time) *fakeTimer {
+	active := c.timers[:0]
+	for _, timer := range c.timers {
+		if !timer.stopped {
+			active = append(active, timer)
+		}
+	}
+	c.timers = active
+
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].at.Before(c.timers[j].at) })
+	if len(c.timers) == 0 || c.timers[0].at.After(t) {
+		return nil
+	}
+	return c.timers[0]
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	if t.stopped {
+		return false
+	}
+	t.stopped = true
+	return true
+}
+
+// #endregion
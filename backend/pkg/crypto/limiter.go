@@ -1,99 +1,151 @@
 package crypto
 
 import (
-	"log"
+	"context"
 	"sync"
 	"time"
+
+	"github.com/Hermitf/the-pass/pkg/clock"
 )
 
 // LimiterPolicy 限制策略
 type LimiterPolicy struct {
-	MaxAttempts int           // 允许的最大连续失败次数（>0 生效）
+	MaxAttempts int           // 窗口内允许的最大失败次数（>0 生效）
 	Window      time.Duration // 计数窗口
-	Lockout     time.Duration // 触发后锁定时长
+	Lockout     time.Duration // 触发后锁定时长（为 0 时锁定一个窗口）
+}
+
+// Enabled 策略是否生效
+func (p LimiterPolicy) Enabled() bool {
+	return p.MaxAttempts > 0 && p.Window > 0
 }
 
+// lockDuration 触发阈值后的锁定时长
+func (p LimiterPolicy) lockDuration() time.Duration {
+	if p.Lockout > 0 {
+		return p.Lockout
+	}
+	return p.Window
+}
+
+// AttemptLimiter 失败尝试次数限制器
+//
+// 调用方按“检查 → 校验凭据 → 失败记一次 / 成功重置”的顺序使用；
+// key 由调用方拼装（如 账号+IP、IP），限制器本身不关心其含义。
+type AttemptLimiter interface {
+	// Check 检查 key 是否处于锁定期；锁定时返回 ErrTooManyAttempts 与剩余锁定时长
+	Check(ctx context.Context, key string) (time.Duration, error)
+	// RecordFailure 记录一次失败；本次失败触发锁定时返回 ErrTooManyAttempts 与锁定时长
+	RecordFailure(ctx context.Context, key string) (time.Duration, error)
+	// Reset 清除 key 的失败计数与锁定
+	Reset(ctx context.Context, key string) error
+}
+
+// #region 内存实现
+
 type attemptRec struct {
-	mu         sync.Mutex
-	count      int
-	windowFrom time.Time
-	lockUntil  time.Time
+	count     int
+	windowEnd time.Time
+	lockUntil time.Time
 }
 
-var attemptMap sync.Map // key -> *attemptRec
+// MemoryAttemptLimiter 进程内限制器，适用于单实例部署与测试
+//
+// 过期记录在写入时按窗口周期惰性清理，内存占用与活跃 key 数量成正比。
+type MemoryAttemptLimiter struct {
+	policy    LimiterPolicy
+	clock     clock.Clock
+	mu        sync.Mutex
+	records   map[string]*attemptRec
+	lastSweep time.Time
+}
 
-// VerifyPasswordWithLimit 验证密码并对指定标识符（如用户名/IP）施加尝试次数限制
-// - id 为空则不启用限制
-// - 验证失败与锁定事件会记录轻量日志
-// 流程：
-// 1) 若启用限制：检查并累计当前 id 的尝试次数，必要时直接返回 ErrTooManyAttempts
-// 2) 调用 VerifyPassword 进行校验
-// 3) 若失败：记录轻量日志并返回错误（不重置计数）
-// 4) 若成功：重置当前 id 的计数与锁定信息
-func VerifyPasswordWithLimit(id string, hashedPassword, password string, policy LimiterPolicy) error {
-	if id != "" && policy.MaxAttempts > 0 {
-		if err := checkAndIncAttempts(id, policy); err != nil {
-			return err
-		}
+// NewMemoryAttemptLimiter 创建进程内限制器；clk 为 nil 时使用系统时钟
+func NewMemoryAttemptLimiter(policy LimiterPolicy, clk clock.Clock) *MemoryAttemptLimiter {
+	if clk == nil {
+		clk = clock.Real()
+	}
+	return &MemoryAttemptLimiter{
+		policy:    policy,
+		clock:     clk,
+		records:   make(map[string]*attemptRec),
+		lastSweep: clk.Now(),
 	}
+}
 
-	err := VerifyPassword(hashedPassword, password)
-	if err != nil {
-		if id != "" && policy.MaxAttempts > 0 {
-			// 留给上层更丰富的审计；这里仅输出一条轻日志
-			log.Printf("password verify failed for id=%s", id)
-		}
-		return err
+// Check 检查是否处于锁定期
+func (l *MemoryAttemptLimiter) Check(ctx context.Context, key string) (time.Duration, error) {
+	if !l.policy.Enabled() {
+		return 0, nil
 	}
+	now := l.clock.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
-	if id != "" && policy.MaxAttempts > 0 {
-		resetAttempts(id)
+	if rec, ok := l.records[key]; ok && rec.lockUntil.After(now) {
+		return rec.lockUntil.Sub(now), ErrTooManyAttempts
 	}
-	return nil
+	return 0, nil
 }
 
-func checkAndIncAttempts(id string, policy LimiterPolicy) error {
-	// 步骤：
-	// 1) 获取/初始化该 id 的计数记录
-	// 2) 加锁保护记录
-	// 3) 若处于锁定期，直接返回 ErrTooManyAttempts
-	// 4) 若窗口已过期，重置窗口与计数
-	// 5) 递增计数，若超过阈值则设置 lockUntil 并返回 ErrTooManyAttempts
-	now := time.Now()
-	recAny, _ := attemptMap.LoadOrStore(id, &attemptRec{windowFrom: now})
-	rec := recAny.(*attemptRec)
-	rec.mu.Lock()
-	defer rec.mu.Unlock()
+// RecordFailure 记录一次失败
+// 流程：
+// 1) 惰性清理过期记录
+// 2) 窗口已过期则重新开窗计数
+// 3) 计数达到阈值时设置锁定并清零计数
+func (l *MemoryAttemptLimiter) RecordFailure(ctx context.Context, key string) (time.Duration, error) {
+	if !l.policy.Enabled() {
+		return 0, nil
+	}
+	now := l.clock.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
+	l.sweepLocked(now)
+	rec, ok := l.records[key]
+	if !ok {
+		rec = &attemptRec{}
+		l.records[key] = rec
+	}
 	if rec.lockUntil.After(now) {
-		return ErrTooManyAttempts
+		return rec.lockUntil.Sub(now), ErrTooManyAttempts
 	}
-	// 窗口滚动
-	if policy.Window > 0 && now.Sub(rec.windowFrom) > policy.Window {
-		rec.windowFrom = now
+	if !rec.windowEnd.After(now) {
 		rec.count = 0
+		rec.windowEnd = now.Add(l.policy.Window)
 	}
 	rec.count++
-	if rec.count > policy.MaxAttempts {
-		if policy.Lockout > 0 {
-			rec.lockUntil = now.Add(policy.Lockout)
-		} else if policy.Window > 0 { // 没有 Lockout 就让其到窗口结束
-			rec.lockUntil = rec.windowFrom.Add(policy.Window)
+	if rec.count >= l.policy.MaxAttempts {
+		lock := l.policy.lockDuration()
+		rec.count = 0
+		rec.lockUntil = now.Add(lock)
+		if rec.windowEnd.Before(rec.lockUntil) {
+			rec.windowEnd = rec.lockUntil
 		}
-		log.Printf("password attempts locked id=%s until=%s", id, rec.lockUntil.Format(time.RFC3339))
-		return ErrTooManyAttempts
+		return lock, ErrTooManyAttempts
 	}
+	return 0, nil
+}
+
+// Reset 清除计数与锁定
+func (l *MemoryAttemptLimiter) Reset(ctx context.Context, key string) error {
+	l.mu.Lock()
+	delete(l.records, key)
+	l.mu.Unlock()
 	return nil
 }
 
-func resetAttempts(id string) {
-	// 成功验证后调用：将计数归零、清除锁定、刷新窗口起点
-	if recAny, ok := attemptMap.Load(id); ok {
-		rec := recAny.(*attemptRec)
-		rec.mu.Lock()
-		rec.count = 0
-		rec.lockUntil = time.Time{}
-		rec.windowFrom = time.Now()
-		rec.mu.Unlock()
+// sweepLocked 每个窗口周期最多全量扫描一次，删除窗口与锁定均已过期的记录
+func (l *MemoryAttemptLimiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < l.policy.Window {
+		return
+	}
+	l.lastSweep = now
+	for key, rec := range l.records {
+		if !rec.windowEnd.After(now) && !rec.lockUntil.After(now) {
+			delete(l.records, key)
+		}
 	}
 }
+
+// #endregion
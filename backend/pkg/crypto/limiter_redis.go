@@ -0,0 +1,100 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 失败计数自增；达到阈值时删除计数并写入锁定键，返回锁定剩余毫秒数（未锁定为 0）
+var luaRecordFailureScript = redis.NewScript(`
+local failKey = KEYS[1]
+local lockKey = KEYS[2]
+local maxAttempts = tonumber(ARGV[1])
+local windowMs = tonumber(ARGV[2])
+local lockMs = tonumber(ARGV[3])
+local ttl = redis.call('PTTL', lockKey)
+if ttl > 0 then
+  return ttl
+end
+local count = redis.call('INCR', failKey)
+if count == 1 then
+  redis.call('PEXPIRE', failKey, windowMs)
+end
+if count >= maxAttempts then
+  redis.call('DEL', failKey)
+  redis.call('SET', lockKey, '1', 'PX', lockMs)
+  return lockMs
+end
+return 0
+`)
+
+// RedisAttemptLimiter Redis 实现的限制器，多实例共享计数
+//
+// Redis 键命名规范：
+//   - {prefix}:fail:{key}  失败计数，TTL 为计数窗口（首次失败时设置）
+//   - {prefix}:lock:{key}  锁定标记，TTL 为锁定时长
+type RedisAttemptLimiter struct {
+	client *redis.Client
+	prefix string
+	policy LimiterPolicy
+}
+
+// NewRedisAttemptLimiter 创建 Redis 限制器；prefix 用于区分不同维度（如账号、IP）
+func NewRedisAttemptLimiter(client *redis.Client, prefix string, policy LimiterPolicy) *RedisAttemptLimiter {
+	return &RedisAttemptLimiter{client: client, prefix: prefix, policy: policy}
+}
+
+func (l *RedisAttemptLimiter) failKey(key string) string {
+	return l.prefix + ":fail:" + key
+}
+
+func (l *RedisAttemptLimiter) lockKey(key string) string {
+	return l.prefix + ":lock:" + key
+}
+
+// Check 读取锁定键剩余 TTL
+func (l *RedisAttemptLimiter) Check(ctx context.Context, key string) (time.Duration, error) {
+	if !l.policy.Enabled() {
+		return 0, nil
+	}
+	ttl, err := l.client.PTTL(ctx, l.lockKey(key)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("读取锁定状态失败: %w", err)
+	}
+	if ttl > 0 {
+		return ttl, ErrTooManyAttempts
+	}
+	return 0, nil
+}
+
+// RecordFailure 通过 Lua 脚本原子地累计失败并在达到阈值时锁定
+func (l *RedisAttemptLimiter) RecordFailure(ctx context.Context, key string) (time.Duration, error) {
+	if !l.policy.Enabled() {
+		return 0, nil
+	}
+	ms, err := luaRecordFailureScript.Run(ctx, l.client,
+		[]string{l.failKey(key), l.lockKey(key)},
+		strconv.Itoa(l.policy.MaxAttempts),
+		strconv.FormatInt(l.policy.Window.Milliseconds(), 10),
+		strconv.FormatInt(l.policy.lockDuration().Milliseconds(), 10),
+	).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("记录失败次数失败: %w", err)
+	}
+	if ms > 0 {
+		return time.Duration(ms) * time.Millisecond, ErrTooManyAttempts
+	}
+	return 0, nil
+}
+
+// Reset 删除计数与锁定键
+func (l *RedisAttemptLimiter) Reset(ctx context.Context, key string) error {
+	if err := l.client.Del(ctx, l.failKey(key), l.lockKey(key)).Err(); err != nil {
+		return fmt.Errorf("重置失败次数失败: %w", err)
+	}
+	return nil
+}
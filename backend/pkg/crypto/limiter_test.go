@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Hermitf/the-pass/pkg/clock"
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// exerciseLimiter 连续失败触发锁定 → 锁定期内拒绝 → advance 推进时间后解锁 → Reset 清零计数
+func exerciseLimiter(t *testing.T, limiter AttemptLimiter, advance func(time.Duration)) {
+	t.Helper()
+	ctx := context.Background()
+
+	for i := 1; i < 3; i++ {
+		if _, err := limiter.RecordFailure(ctx, "alice|1.2.3.4"); err != nil {
+			t.Fatalf("failure %d: unexpected %v", i, err)
+		}
+	}
+	if lock, err := limiter.RecordFailure(ctx, "alice|1.2.3.4"); !errors.Is(err, ErrTooManyAttempts) || lock != time.Minute {
+		t.Fatalf("third failure: lock=%v err=%v want 1m ErrTooManyAttempts", lock, err)
+	}
+	if remaining, err := limiter.Check(ctx, "alice|1.2.3.4"); !errors.Is(err, ErrTooManyAttempts) || remaining <= 0 {
+		t.Fatalf("check while locked: remaining=%v err=%v", remaining, err)
+	}
+	if _, err := limiter.Check(ctx, "bob|1.2.3.4"); err != nil {
+		t.Fatalf("other key should not be locked: %v", err)
+	}
+
+	advance(time.Minute + time.Second)
+	if _, err := limiter.Check(ctx, "alice|1.2.3.4"); err != nil {
+		t.Fatalf("check after lockout: %v", err)
+	}
+
+	// 成功登录后重置，之前的失败不再计入
+	if _, err := limiter.RecordFailure(ctx, "alice|1.2.3.4"); err != nil {
+		t.Fatalf("failure after lockout: %v", err)
+	}
+	if err := limiter.Reset(ctx, "alice|1.2.3.4"); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	for i := 1; i < 3; i++ {
+		if _, err := limiter.RecordFailure(ctx, "alice|1.2.3.4"); err != nil {
+			t.Fatalf("failure %d after reset: unexpected %v", i, err)
+		}
+	}
+}
+
+func TestMemoryAttemptLimiter(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := NewMemoryAttemptLimiter(LimiterPolicy{MaxAttempts: 3, Window: time.Minute, Lockout: time.Minute}, fake)
+	exerciseLimiter(t, limiter, fake.Advance)
+
+	// 过期记录在下一次写入时被清理
+	fake.Advance(2 * time.Minute)
+	if _, err := limiter.RecordFailure(context.Background(), "carol"); err != nil {
+		t.Fatalf("RecordFailure: %v", err)
+	}
+	if len(limiter.records) != 1 {
+		t.Fatalf("expired records not swept: %d left", len(limiter.records))
+	}
+}
+
+func TestRedisAttemptLimiter(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	defer mr.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	limiter := NewRedisAttemptLimiter(rdb, "test:login", LimiterPolicy{MaxAttempts: 3, Window: time.Minute, Lockout: time.Minute})
+	exerciseLimiter(t, limiter, mr.FastForward)
+}
@@ -0,0 +1,91 @@
+// Package mail 提供邮件发送抽象
+//
+// 业务层只依赖 Sender 接口；生产环境按配置使用 SMTPSender，
+// LogSender 仅用于开发调试，不会真正投递邮件。
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// 邮件服务名称（对应配置 mail.provider）
+const (
+	ProviderSMTP = "smtp"
+)
+
+// ErrSenderConfig 邮件服务配置无效
+var ErrSenderConfig = errors.New("邮件服务配置无效")
+
+// Message 待发送的邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender 邮件发送服务抽象接口
+type Sender interface {
+	// Send 发送邮件，失败时返回错误（网络、认证、收件人无效等）
+	Send(ctx context.Context, msg Message) error
+}
+
+// SenderConfig 邮件服务接入配置（与配置文件结构解耦）
+//
+// 字段说明：
+//   - Provider: 服务名称，目前支持 smtp；为空表示未配置邮件服务
+//   - Host / Port: SMTP 服务器地址，端口 465 使用隐式 TLS，其余端口在服务器支持时升级 STARTTLS
+//   - Username / Password: SMTP 认证信息，为空时不认证
+//   - From: 发件人地址
+//   - Timeout: 单封邮件的发送超时，<=0 时取 10 秒
+type SenderConfig struct {
+	Provider string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// NewSender 按配置创建邮件发送实现；未配置邮件服务时返回 nil, nil
+//
+// 返回 ErrSenderConfig 表示服务未知或缺少必填项，调用方应在启动阶段直接失败
+func NewSender(cfg SenderConfig) (Sender, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Provider)) {
+	case "":
+		return nil, nil
+	case ProviderSMTP:
+		sender, err := NewSMTPSender(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return sender, nil
+	default:
+		return nil, fmt.Errorf("%w: 未知邮件服务 %q", ErrSenderConfig, cfg.Provider)
+	}
+}
+
+// LogSender 模拟邮件发送实现，只打印收件人与主题
+// 正文可能包含重置令牌等凭证，不写入日志
+type LogSender struct{}
+
+// NewLogSender 创建 LogSender 实例
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send 模拟发送，仅打印收件人与主题
+func (LogSender) Send(ctx context.Context, msg Message) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		log.Printf("[Mail Mock] 发送到 %s，主题：%s", msg.To, msg.Subject)
+		return nil
+	}
+}
@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogSender_OmitsBody(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	msg := Message{To: "a@example.com", Subject: "找回密码", Body: "重置令牌：secret-token"}
+	if err := NewLogSender().Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, msg.To) || strings.Contains(out, "secret-token") {
+		t.Fatalf("log output=%q want recipient without body", out)
+	}
+}
+
+func TestNewSender(t *testing.T) {
+	if sender, err := NewSender(SenderConfig{}); sender != nil || err != nil {
+		t.Fatalf("unconfigured sender=%v err=%v want nil, nil", sender, err)
+	}
+	if _, err := NewSender(SenderConfig{Provider: "log"}); !errors.Is(err, ErrSenderConfig) {
+		t.Fatalf("unknown provider err=%v want ErrSenderConfig", err)
+	}
+	if _, err := NewSender(SenderConfig{Provider: ProviderSMTP, Host: "smtp.example.com"}); !errors.Is(err, ErrSenderConfig) {
+		t.Fatalf("incomplete smtp err=%v want ErrSenderConfig", err)
+	}
+	sender, err := NewSender(SenderConfig{Provider: ProviderSMTP, Host: "smtp.example.com", Port: 587, From: "noreply@example.com"})
+	if err != nil {
+		t.Fatalf("smtp sender: %v", err)
+	}
+	if _, ok := sender.(*SMTPSender); !ok {
+		t.Fatalf("sender=%T want *SMTPSender", sender)
+	}
+}
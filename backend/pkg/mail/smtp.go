@@ -0,0 +1,143 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSMTPTimeout 单封邮件的默认发送超时
+const defaultSMTPTimeout = 10 * time.Second
+
+// smtpImplicitTLSPort 使用隐式 TLS 的 SMTPS 端口
+const smtpImplicitTLSPort = 465
+
+// SMTPSender 通过 SMTP 服务器投递邮件
+type SMTPSender struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	timeout  time.Duration
+}
+
+// NewSMTPSender 创建 SMTP 发送实现，缺少服务器地址或发件人时返回 ErrSenderConfig
+func NewSMTPSender(cfg SenderConfig) (*SMTPSender, error) {
+	var missing []string
+	if cfg.Host == "" {
+		missing = append(missing, "host")
+	}
+	if cfg.Port <= 0 {
+		missing = append(missing, "port")
+	}
+	if cfg.From == "" {
+		missing = append(missing, "from")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: smtp 缺少 %s", ErrSenderConfig, strings.Join(missing, ", "))
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+	return &SMTPSender{
+		host:     cfg.Host,
+		port:     cfg.Port,
+		username: cfg.Username,
+		password: cfg.Password,
+		from:     cfg.From,
+		timeout:  timeout,
+	}, nil
+}
+
+// Send 连接 SMTP 服务器发送一封纯文本邮件
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid mail header (to=%s)", msg.To)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("smtp dial failed (host=%s): %w", s.host, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("smtp handshake failed (host=%s): %w", s.host, err)
+	}
+	defer client.Close()
+
+	if s.port != smtpImplicitTLSPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+				return fmt.Errorf("smtp starttls failed (host=%s): %w", s.host, err)
+			}
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("smtp auth failed (host=%s): %w", s.host, err)
+		}
+	}
+
+	if err := client.Mail(s.from); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("smtp RCPT TO failed (to=%s): %w", msg.To, err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(s.compose(msg)); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("smtp write failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial 建立到 SMTP 服务器的连接，465 端口直接使用 TLS
+func (s *SMTPSender) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	if s.port == smtpImplicitTLSPort {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: s.host}}
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// compose 组装邮件头与正文，主题按 RFC 2047 编码
+func (s *SMTPSender) compose(msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -14,11 +14,11 @@
 //	    │  (存储接口)  │  │(发送接口) │
 //	    └─────────────┘  └──────────┘
 //	         ↓                ↓
-//	  ┌───────────┐    ┌──────────────┐       ← 实现层
-//	  │RedisStore │    │ MockProvider │
-//	  │           │    │ (可扩展为     │
-//	  │           │    │ 阿里云/腾讯云)│
-//	  └───────────┘    └──────────────┘
+//	  ┌───────────┐    ┌─────────────────┐    ← 实现层
+//	  │RedisStore │    │ MockProvider    │
+//	  │           │    │ AliyunProvider  │
+//	  │           │    │ TencentProvider │
+//	  └───────────┘    └─────────────────┘
 //
 // # 核心组件
 //
@@ -33,14 +33,33 @@
 //
 // 3. Provider 接口 (provider.go)
 //   - 定义短信发送服务的抽象
-//   - 支持多种第三方服务商（阿里云、腾讯云等）
+//   - TemplateProvider 扩展接口：按服务商模板发送，Service 只传递模板变量
+//   - 阿里云 (provider_aliyun.go)：RPC 接口 HMAC-SHA1 签名
+//   - 腾讯云 (provider_tencent.go)：API 3.0 TC3-HMAC-SHA256 签名
+//   - NewProvider 按配置名称选择实现，服务商错误码映射为本包哨兵错误（见 ProviderError）
+//   - 多服务商故障转移 (provider_failover.go)：按优先级与权重路由，单服务商熔断，
+//     可重试错误（见 IsRetryable）换下一家并指数退避，Stats 汇报各家成功数与耗时
 //
 // 4. Service 编排层 (service.go)
 //   - 整合 Store 和 Provider
 //   - 实现发送验证码的完整流程（限流→生成→存储→发送）
-//   - 实现验证验证码的流程（读取→比对→删除）
-//
-// 5. 错误定义 (errors.go)
+//   - 实现验证验证码的流程（登记尝试→常量时间比对→删除）
+//   - 验证码按作用域（角色 + 用途）隔离，注册验证码不能用于登录
+//   - SetQueue 后验证码入队即返回，由发送队列异步调用 Provider
+//
+// 5. 发送队列 (queue_redis.go, send_log.go)
+//   - Redis Stream 消费组，多实例共享；工作协程发送，可重试错误按退避重发
+//   - 超时未确认的消息由 XAUTOCLAIM 接管，验证码过期后不再发送
+//   - SendLogStore 记录入队、服务商受理（流水号见 Receipt）与送达回执状态
+//   - ParseDeliveryReports 解析阿里云、腾讯云推送的送达回执
+//
+// 6. 多维度限额与号码名单 (limits.go, limits_redis.go)
+//   - Store 实现 LimitStore 时，单号码频率、每日上限与客户端 IP、设备、号段、全站每小时预算
+//     由一个 Lua 脚本原子检查，全部通过才计入，被拦截的请求不消耗其他维度额度
+//   - 客户端信息通过 WithClientInfo 写入 ctx；被拦截时返回 *LimitError，Dimension 为拦截维度
+//   - PhoneListStore 管理黑名单（禁止发送）与白名单（跳过全部限额），黑名单优先
+//
+// 7. 错误定义 (errors.go)
 //   - 统一管理业务错误
 //   - 使用哨兵错误模式，便于上层判断
 //
@@ -57,8 +76,8 @@
 //	redisClient := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
 //	store := sms.NewRedisStore(redisClient)
 //
-//	// 2. 创建发送服务商（开发环境使用 Mock）
-//	provider := sms.NewMockProvider()
+//	// 2. 创建发送服务商（开发环境使用 Mock，生产按配置选择阿里云/腾讯云）
+//	provider, err := sms.NewProvider(sms.ProviderConfig{Name: "aliyun", AccessKey: "...", SecretKey: "...", SignName: "..."})
 //
 //	// 3. 配置运行时参数
 //	cfg := sms.SMSRuntimeConfig{
@@ -75,13 +94,16 @@
 //
 // 发送验证码：
 //
-//	err := smsService.SendCode(ctx, "13800000000")
+//	scope := sms.Scope{Role: "user", Purpose: sms.PurposeLogin}
+//	err := smsService.SendCode(ctx, scope, "13800000000")
 //	if err != nil {
 //	    switch {
 //	    case errors.Is(err, sms.ErrSendTooFrequent):
 //	        // 提示用户发送过于频繁
 //	    case errors.Is(err, sms.ErrDailyLimitReached):
 //	        // 提示用户超过每日上限
+//	    case errors.Is(err, sms.ErrPhoneBlocked), errors.Is(err, sms.ErrSendLimited):
+//	        // 号码在黑名单中，或 IP / 设备 / 号段 / 全站额度已用完（errors.As 取 *LimitError 查看维度）
 //	    default:
 //	        // 其他错误处理
 //	    }
@@ -99,13 +121,15 @@
 //	     // 根据 retryAfter 告诉用户需等待多久
 //	 }
 //
-//		err := smsService.VerifyCode(ctx, "13800000000", "123456")
+//		err := smsService.VerifyCode(ctx, scope, "13800000000", "123456")
 //		if err != nil {
 //		    switch {
 //		    case errors.Is(err, sms.ErrCodeExpired):
 //		        // 提示验证码已过期
 //		    case errors.Is(err, sms.ErrCodeMismatch):
 //		        // 提示验证码错误
+//		    case errors.Is(err, sms.ErrCodeAttemptsExceeded):
+//		        // 错误次数过多，提示重新获取
 //		    }
 //		}
 //
@@ -113,15 +137,15 @@
 //
 // ## 添加新的短信服务商
 //
-// 实现 Provider 接口即可：
+// 实现 Provider 接口即可；只能按模板发送的服务商另外实现 TemplateProvider，
+// 并在 NewProvider 中登记名称。服务商错误码应映射为本包哨兵错误并包装进 ProviderError：
 //
-//	type AliyunProvider struct {
-//	    accessKey string
-//	    secretKey string
+//	type HuaweiProvider struct {
+//	    cfg sms.ProviderConfig
 //	}
 //
-//	func (a *AliyunProvider) SendSMS(ctx context.Context, phone, content string) error {
-//	    // 调用阿里云 SDK 发送短信
+//	func (h *HuaweiProvider) SendTemplate(ctx context.Context, phone, templateCode string, params []sms.TemplateParam) error {
+//	    // 签名并调用服务商接口
 //	    return nil
 //	}
 //
@@ -133,7 +157,7 @@
 //	    collection *mongo.Collection
 //	}
 //
-//	func (m *MongoStore) SaveCode(phone, code string, expireIn time.Duration) error {
+//	func (m *MongoStore) SaveCode(scope sms.Scope, phone, code string, expireIn time.Duration) error {
 //	    // 使用 MongoDB 存储验证码
 //	    return nil
 //	}
@@ -156,6 +180,7 @@
 //
 // 4. 安全建议：
 //   - 验证成功后立即删除验证码（防重放）
+//   - 限制单个验证码的错误尝试次数（MaxAttempts，防爆破）
 //   - 使用 crypto/rand 生成验证码（防预测）
 //   - 配置合理的频率限制（防刷）
 package sms
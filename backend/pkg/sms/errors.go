@@ -1,6 +1,9 @@
 package sms
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // 短信业务错误定义（集中管理）
 //
@@ -24,16 +27,72 @@ var (
 	// ErrCodeMismatch 验证码不匹配
 	ErrCodeMismatch = errors.New("验证码不匹配")
 
+	// ErrCodeAttemptsExceeded 错误尝试次数过多，验证码已作废
+	ErrCodeAttemptsExceeded = errors.New("验证码错误次数过多，请重新获取")
+
+	// ErrPurposeInvalid 验证码用途无效
+	ErrPurposeInvalid = errors.New("验证码用途无效")
+
+	// ErrScopeInvalid 验证码作用域无效（角色为空或含非法字符）
+	ErrScopeInvalid = errors.New("验证码作用域无效")
+
 	// ErrSendTooFrequent 发送过于频繁（触发频率限制）
 	ErrSendTooFrequent = errors.New("发送过于频繁，请稍后再试")
 
 	// ErrDailyLimitReached 当天发送次数已达上限
 	ErrDailyLimitReached = errors.New("当天发送次数已达上限")
 
+	// ErrPhoneBlocked 号码在黑名单中
+	ErrPhoneBlocked = errors.New("该号码已被禁止接收验证码")
+
+	// ErrSendLimited 客户端 IP、设备、号段或全站发送量超过限额（维度见 LimitError）
+	ErrSendLimited = errors.New("发送次数超过限制，请稍后再试")
+
+	// ErrPhoneListInvalid 号码名单名称无效（仅支持 blocklist / allowlist）
+	ErrPhoneListInvalid = errors.New("号码名单无效")
+
 	// ErrProviderDisabled 短信服务未启用
 	ErrProviderDisabled = errors.New("短信服务未启用")
 
 	// ErrStoreFailure 短信存储访问失败（统一包装 Redis 之类的后端错误）
 	// 上层可用 errors.Is(err, ErrStoreFailure) 判断是否为存储层异常
 	ErrStoreFailure = errors.New("短信存储访问失败")
+
+	// ErrProviderConfig 短信服务商配置无效（服务商名称未知或缺少密钥、签名等必填项）
+	ErrProviderConfig = errors.New("短信服务商配置无效")
+
+	// ErrProviderAuth 服务商鉴权失败（AccessKey 无效、签名不匹配或账号被停用）
+	ErrProviderAuth = errors.New("短信服务商鉴权失败")
+
+	// ErrProviderTemplate 短信签名、模板或模板变量未通过服务商校验
+	ErrProviderTemplate = errors.New("短信签名或模板无效")
+
+	// ErrProviderBalance 服务商账户余额或套餐包不足
+	ErrProviderBalance = errors.New("短信服务商余额不足")
+
+	// ErrProviderUnavailable 服务商暂时不可用（网络错误、超时、5xx 或系统繁忙），可稍后重试
+	ErrProviderUnavailable = errors.New("短信服务商暂不可用")
+
+	// ErrProviderRejected 服务商拒绝发送（黑名单、内容违规等其余业务错误）
+	ErrProviderRejected = errors.New("短信服务商拒绝发送")
 )
+
+// ProviderError 服务商返回的原始错误
+//
+// Err 为映射后的哨兵错误（如 ErrSendTooFrequent、ErrProviderAuth），
+// 上层仍可用 errors.Is 判断；Code、Message、RequestID 保留服务商原文，便于排查与对账
+type ProviderError struct {
+	Provider  string // 服务商名称，如 aliyun / tencent
+	Code      string // 服务商错误码
+	Message   string // 服务商错误描述
+	RequestID string // 服务商请求ID
+	Err       error  // 映射后的哨兵错误
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%v: %s %s %s (request_id=%s)", e.Err, e.Provider, e.Code, e.Message, e.RequestID)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,152 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// 限额维度，CanSend / SendCode 被拦截时通过 LimitError.Dimension 报告
+const (
+	LimitBlocklist = "blocklist" // 号码在黑名单中
+	LimitPhone     = "phone"     // 单号码频率（RateMax / RateWindow）
+	LimitDaily     = "daily"     // 单号码每日上限（DailyMax）
+	LimitIP        = "ip"        // 单个客户端 IP
+	LimitDevice    = "device"    // 单个设备标识
+	LimitPrefix    = "prefix"    // 号段（手机号前若干位，对应运营商与归属地）
+	LimitGlobal    = "global"    // 全站每小时发送预算
+)
+
+// DefaultPrefixLength 号段默认长度：3 位网号 + 4 位地区码
+const DefaultPrefixLength = 7
+
+// SendLimits 手机号之外的发送限额，Max <= 0 的维度不限制
+//
+// 字段说明：
+//   - IPMax / IPWindow: 同一客户端 IP 在窗口内的发送次数
+//   - DeviceMax / DeviceWindow: 同一设备标识在窗口内的发送次数
+//   - UnknownDeviceMax: 未上报设备标识的公开请求共用一个设备桶，窗口同 DeviceWindow；<= 0 时取 DeviceMax
+//   - PrefixMax / PrefixWindow: 同一号段在窗口内的发送次数，号段长度取 PrefixLength（默认 7）
+//   - GlobalHourlyMax: 全站每小时发送次数
+type SendLimits struct {
+	IPMax           int
+	IPWindow        time.Duration
+	DeviceMax       int
+	DeviceWindow    time.Duration
+	PrefixMax       int
+	PrefixWindow    time.Duration
+	PrefixLength    int
+	GlobalHourlyMax int
+
+	UnknownDeviceMax int
+}
+
+// ClientInfo 发起发送请求的客户端
+type ClientInfo struct {
+	IP       string
+	DeviceID string
+}
+
+type clientInfoKey struct{}
+
+// WithClientInfo 将客户端信息写入 ctx，供 Service 按 IP 与设备维度限额
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+// ClientInfoFrom 读取 ctx 中的客户端信息，未设置时返回零值
+func ClientInfoFrom(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info
+}
+
+// SendQuota 一次发送涉及的全部限额
+type SendQuota struct {
+	Phone      string
+	Client     ClientInfo
+	RateMax    int
+	RateWindow time.Duration
+	DailyMax   int
+	Limits     SendLimits
+}
+
+// LimitError 发送被限额拦截
+//
+// Err 为 ErrPhoneBlocked、ErrSendTooFrequent、ErrDailyLimitReached 或 ErrSendLimited，
+// 可继续使用 errors.Is 判断；errors.As 取出后可读取拦截维度与等待时间
+type LimitError struct {
+	Dimension  string
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v（%s）", e.Err, e.Dimension)
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// newLimitError 按维度选择对应的哨兵错误
+func newLimitError(dimension string, retryAfter time.Duration) *LimitError {
+	err := ErrSendLimited
+	switch dimension {
+	case LimitBlocklist:
+		err = ErrPhoneBlocked
+	case LimitPhone:
+		err = ErrSendTooFrequent
+	case LimitDaily:
+		err = ErrDailyLimitReached
+	}
+	return &LimitError{Dimension: dimension, RetryAfter: retryAfter, Err: err}
+}
+
+// LimitStore 多维度限额存储（可选接口）
+//
+// Store 实现该接口时，Service 改为一次原子检查名单与全部维度，
+// 替代 CheckRateLimit / IncrementDailyCount 的逐项检查
+type LimitStore interface {
+	// AcquireSendQuota 检查名单与各维度限额，全部通过才计入；被拦截时返回 *LimitError
+	// 白名单号码跳过全部限额
+	AcquireSendQuota(ctx context.Context, quota SendQuota) error
+
+	// PeekSendQuota 只读检查，不计入
+	PeekSendQuota(ctx context.Context, quota SendQuota) error
+}
+
+// PhoneList 号码名单
+type PhoneList string
+
+const (
+	PhoneBlocklist PhoneList = "blocklist" // 黑名单：禁止发送
+	PhoneAllowlist PhoneList = "allowlist" // 白名单：跳过全部限额（测试号码、审核账号等）
+)
+
+// ParsePhoneList 解析名单名称，未知名单返回 ErrPhoneListInvalid
+func ParsePhoneList(raw string) (PhoneList, error) {
+	switch l := PhoneList(raw); l {
+	case PhoneBlocklist, PhoneAllowlist:
+		return l, nil
+	default:
+		return "", ErrPhoneListInvalid
+	}
+}
+
+// PhoneListEntry 名单中的号码
+type PhoneListEntry struct {
+	Phone  string `json:"phone"`
+	Reason string `json:"reason"`
+}
+
+// PhoneListStore 号码名单管理
+type PhoneListStore interface {
+	// AddPhone 加入名单（已存在时更新原因）
+	AddPhone(ctx context.Context, list PhoneList, phone, reason string) error
+
+	// RemovePhone 移出名单，返回号码此前是否在名单中
+	RemovePhone(ctx context.Context, list PhoneList, phone string) (bool, error)
+
+	// ListPhones 列出名单中的全部号码
+	ListPhones(ctx context.Context, list PhoneList) ([]PhoneListEntry, error)
+}
@@ -0,0 +1,166 @@
+package sms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// limitRule 一个滑动窗口维度
+type limitRule struct {
+	dimension string
+	key       string
+	max       int
+	window    time.Duration
+}
+
+// Redis 键生成函数（限额与名单），设备标识取哈希，避免任意长度的请求头进入键名
+func (r *RedisStore) limitKey(dimension, id string) string {
+	return fmt.Sprintf("%s:limit_z:%s:%s", r.prefix, dimension, id)
+}
+
+func (r *RedisStore) phoneListKey(list PhoneList) string {
+	return fmt.Sprintf("%s:phones:%s", r.prefix, list)
+}
+
+// unknownDeviceID 未上报设备标识的请求共用的设备桶（哈希后的设备标识不会与之冲突）
+const unknownDeviceID = "unknown"
+
+// limitRules 按配置与客户端信息列出需要检查的窗口，未配置的维度跳过
+// 未带客户端信息的服务端内部发送不计入 IP 与设备维度；公开请求缺少设备标识时计入共用的未知设备桶，
+// 省略请求头不能绕过设备限额
+func (r *RedisStore) limitRules(q SendQuota) []limitRule {
+	var rules []limitRule
+	add := func(dimension, key string, max int, window time.Duration) {
+		if max > 0 && window > 0 {
+			rules = append(rules, limitRule{dimension: dimension, key: key, max: max, window: window})
+		}
+	}
+
+	add(LimitPhone, r.rateSortedSet(q.Phone), q.RateMax, q.RateWindow)
+	if q.Client.IP != "" {
+		add(LimitIP, r.limitKey(LimitIP, q.Client.IP), q.Limits.IPMax, q.Limits.IPWindow)
+	}
+	switch {
+	case q.Client.DeviceID != "":
+		sum := sha256.Sum256([]byte(q.Client.DeviceID))
+		add(LimitDevice, r.limitKey(LimitDevice, hex.EncodeToString(sum[:16])), q.Limits.DeviceMax, q.Limits.DeviceWindow)
+	case q.Client.IP != "" && q.Limits.DeviceMax > 0:
+		unknownMax := q.Limits.UnknownDeviceMax
+		if unknownMax <= 0 {
+			unknownMax = q.Limits.DeviceMax
+		}
+		add(LimitDevice, r.limitKey(LimitDevice, unknownDeviceID), unknownMax, q.Limits.DeviceWindow)
+	}
+	prefixLength := q.Limits.PrefixLength
+	if prefixLength <= 0 {
+		prefixLength = DefaultPrefixLength
+	}
+	prefix := q.Phone
+	if len(prefix) > prefixLength {
+		prefix = prefix[:prefixLength]
+	}
+	add(LimitPrefix, r.limitKey(LimitPrefix, prefix), q.Limits.PrefixMax, q.Limits.PrefixWindow)
+	add(LimitGlobal, fmt.Sprintf("%s:limit_z:global", r.prefix), q.Limits.GlobalHourlyMax, time.Hour)
+	return rules
+}
+
+// AcquireSendQuota 原子检查名单与各维度限额，全部通过才计入
+func (r *RedisStore) AcquireSendQuota(ctx context.Context, quota SendQuota) error {
+	return r.runSendQuota(ctx, quota, true)
+}
+
+// PeekSendQuota 只读检查名单与各维度限额
+func (r *RedisStore) PeekSendQuota(ctx context.Context, quota SendQuota) error {
+	return r.runSendQuota(ctx, quota, false)
+}
+
+func (r *RedisStore) runSendQuota(ctx context.Context, quota SendQuota, record bool) error {
+	rules := r.limitRules(quota)
+	keys := make([]string, 0, 3+len(rules))
+	keys = append(keys, r.phoneListKey(PhoneBlocklist), r.phoneListKey(PhoneAllowlist), r.dailyKey(quota.Phone))
+
+	now := time.Now().UnixNano()
+	recordFlag := "0"
+	if record {
+		recordFlag = "1"
+	}
+	expireSec := secondsUntilEndOfDay()
+	if expireSec <= 0 {
+		expireSec = 24 * 60 * 60
+	}
+	args := []any{
+		quota.Phone,
+		strconv.FormatInt(now, 10),
+		fmt.Sprintf("%d-%08x", now, rand.Uint32()),
+		recordFlag,
+		quota.DailyMax,
+		expireSec,
+	}
+	for _, rule := range rules {
+		keys = append(keys, rule.key)
+		args = append(args, rule.max, rule.window.Nanoseconds())
+	}
+
+	res, err := luaSendQuotaScript.Run(ctx, r.client, keys, args...).Int64Slice()
+	if err != nil {
+		return wrapRedisErr("EVAL send_quota", maskPhone(quota.Phone), err)
+	}
+	if len(res) < 3 {
+		return fmt.Errorf("redis EVAL send_quota invalid result: %v", res)
+	}
+	if res[0] == 1 {
+		return nil
+	}
+
+	retryAfter := time.Duration(res[2]) * time.Millisecond
+	switch code := res[1]; {
+	case code == -1:
+		return newLimitError(LimitBlocklist, 0)
+	case code == -3:
+		return newLimitError(LimitDaily, retryAfter)
+	case code >= 1 && int(code) <= len(rules):
+		return newLimitError(rules[code-1].dimension, retryAfter)
+	default:
+		return fmt.Errorf("redis EVAL send_quota invalid result: %v", res)
+	}
+}
+
+// AddPhone 加入名单（已存在时更新原因）
+func (r *RedisStore) AddPhone(ctx context.Context, list PhoneList, phone, reason string) error {
+	key := r.phoneListKey(list)
+	if err := r.client.HSet(ctx, key, phone, reason).Err(); err != nil {
+		return wrapRedisErr("HSET", key, err)
+	}
+	return nil
+}
+
+// RemovePhone 移出名单，返回号码此前是否在名单中
+func (r *RedisStore) RemovePhone(ctx context.Context, list PhoneList, phone string) (bool, error) {
+	key := r.phoneListKey(list)
+	n, err := r.client.HDel(ctx, key, phone).Result()
+	if err != nil {
+		return false, wrapRedisErr("HDEL", key, err)
+	}
+	return n > 0, nil
+}
+
+// ListPhones 列出名单中的全部号码
+func (r *RedisStore) ListPhones(ctx context.Context, list PhoneList) ([]PhoneListEntry, error) {
+	key := r.phoneListKey(list)
+	values, err := r.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, wrapRedisErr("HGETALL", key, err)
+	}
+	entries := make([]PhoneListEntry, 0, len(values))
+	for phone, reason := range values {
+		entries = append(entries, PhoneListEntry{Phone: phone, Reason: reason})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Phone < entries[j].Phone })
+	return entries, nil
+}
@@ -0,0 +1,100 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestService_SendLimitsReportDimension(t *testing.T) {
+	store, _, ctx := newTestStore(t)
+	svc := NewService(store, &scriptedProvider{}, SMSRuntimeConfig{
+		Enabled: true, ExpireIn: time.Minute, RateMax: 1, RateWindow: time.Minute,
+		Limits: SendLimits{IPMax: 2, IPWindow: time.Hour, PrefixMax: 3, PrefixWindow: time.Hour, GlobalHourlyMax: 4},
+	})
+	scope := Scope{Role: "user", Purpose: PurposeLogin}
+	from := func(ip string) context.Context {
+		return WithClientInfo(ctx, ClientInfo{IP: ip, DeviceID: "device-" + ip})
+	}
+	send := func(ip, phone, wantDimension string) {
+		t.Helper()
+		err := svc.SendCode(from(ip), scope, phone)
+		var limitErr *LimitError
+		switch {
+		case wantDimension == "" && err != nil:
+			t.Fatalf("send %s from %s: %v", phone, ip, err)
+		case wantDimension != "" && (!errors.As(err, &limitErr) || limitErr.Dimension != wantDimension):
+			t.Fatalf("send %s from %s: err=%v want dimension %s", phone, ip, err, wantDimension)
+		}
+	}
+
+	send("192.0.2.1", "13800000060", "")
+	// 被拦截的请求不计入其他维度
+	send("192.0.2.1", "13800000060", LimitPhone)
+	send("192.0.2.1", "13800000061", "")
+	send("192.0.2.1", "13800000062", LimitIP)
+
+	allowed, retryAfter, err := svc.CanSend(from("192.0.2.1"), "13900000060")
+	var limitErr *LimitError
+	if allowed || !errors.As(err, &limitErr) || limitErr.Dimension != LimitIP || !errors.Is(err, ErrSendLimited) || retryAfter <= 0 {
+		t.Fatalf("CanSend: allowed=%v retryAfter=%v err=%v", allowed, retryAfter, err)
+	}
+
+	send("192.0.2.2", "13800000063", "")
+	send("192.0.2.3", "13800000064", LimitPrefix)
+	send("192.0.2.4", "13900000060", "")
+	send("192.0.2.5", "13700000060", LimitGlobal)
+
+	// 黑名单优先于白名单与全部限额；白名单号码跳过限额
+	for _, list := range []PhoneList{PhoneBlocklist, PhoneAllowlist} {
+		if err := store.AddPhone(ctx, list, "13600000060", "测试"); err != nil {
+			t.Fatalf("AddPhone %s: %v", list, err)
+		}
+	}
+	if _, _, err := svc.CanSend(from("192.0.2.6"), "13600000060"); !errors.Is(err, ErrPhoneBlocked) {
+		t.Fatalf("blocklisted CanSend err=%v", err)
+	}
+	if removed, err := store.RemovePhone(ctx, PhoneBlocklist, "13600000060"); err != nil || !removed {
+		t.Fatalf("RemovePhone: removed=%v err=%v", removed, err)
+	}
+	send("192.0.2.6", "13600000060", "")
+	send("192.0.2.6", "13600000060", "")
+
+	entries, err := store.ListPhones(ctx, PhoneAllowlist)
+	if err != nil || len(entries) != 1 || entries[0].Phone != "13600000060" || entries[0].Reason != "测试" {
+		t.Fatalf("ListPhones: entries=%+v err=%v", entries, err)
+	}
+}
+
+func TestService_SendWithoutDeviceIDUsesSharedBucket(t *testing.T) {
+	store, _, ctx := newTestStore(t)
+	svc := NewService(store, &scriptedProvider{}, SMSRuntimeConfig{
+		Enabled: true, ExpireIn: time.Minute, RateMax: 1, RateWindow: time.Minute,
+		Limits: SendLimits{DeviceMax: 5, DeviceWindow: time.Hour, UnknownDeviceMax: 2},
+	})
+	scope := Scope{Role: "user", Purpose: PurposeLogin}
+	// 省略设备请求头：不同 IP 共用未知设备桶，不能绕过设备限额
+	withoutDevice := func(ip string) context.Context {
+		return WithClientInfo(ctx, ClientInfo{IP: ip})
+	}
+
+	for i, phone := range []string{"13800000070", "13800000071"} {
+		if err := svc.SendCode(withoutDevice("192.0.2."+strconv.Itoa(i+1)), scope, phone); err != nil {
+			t.Fatalf("send %s: %v", phone, err)
+		}
+	}
+	var limitErr *LimitError
+	if err := svc.SendCode(withoutDevice("192.0.2.9"), scope, "13800000072"); !errors.As(err, &limitErr) || limitErr.Dimension != LimitDevice {
+		t.Fatalf("third headerless send err=%v want device limit", err)
+	}
+
+	// 上报设备标识的请求与服务端内部发送不受影响
+	if err := svc.SendCode(WithClientInfo(ctx, ClientInfo{IP: "192.0.2.9", DeviceID: "device-1"}), scope, "13800000073"); err != nil {
+		t.Fatalf("send with device: %v", err)
+	}
+	if err := svc.SendCode(ctx, scope, "13800000074"); err != nil {
+		t.Fatalf("internal send: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 )
 
 // Provider 短信发送服务抽象接口
@@ -23,6 +24,67 @@ type Provider interface {
 	SendSMS(ctx context.Context, phone string, content string) error
 }
 
+// TemplateParam 模板变量
+//
+// 阿里云按名称填充（${code}），腾讯云按顺序填充（{1}），因此同时保留名称与顺序
+type TemplateParam struct {
+	Name  string
+	Value string
+}
+
+// TemplateProvider 按服务商模板发送短信的扩展接口
+//
+// 阿里云、腾讯云只允许发送审核通过的模板，不接受任意正文；
+// Service 检测到 Provider 实现该接口时改为传递模板变量，不再渲染短信全文
+type TemplateProvider interface {
+	Provider
+
+	// SendTemplate 使用模板发送短信，templateCode 为空时使用服务商配置的默认模板
+	SendTemplate(ctx context.Context, phone string, templateCode string, params []TemplateParam) error
+}
+
+// Message 一条待发送短信
+//
+// 模板类服务商使用 TemplateCode 与 Params，其余 Provider 发送 Content；
+// 只有 Content 时模板类服务商按业务通知模板发送
+type Message struct {
+	ID           string          `json:"id"`                      // 消息ID，对应发送日志
+	Phone        string          `json:"phone"`                   // 目标手机号
+	Category     string          `json:"category"`                // 业务分类，验证码为作用域（如 user:login）
+	TemplateCode string          `json:"template_code,omitempty"` // 服务商模板编号，为空使用服务商默认模板
+	Params       []TemplateParam `json:"params,omitempty"`        // 模板变量
+	Content      string          `json:"content,omitempty"`       // 短信全文
+	ExpiresAt    time.Time       `json:"expires_at,omitempty"`    // 过期后不再发送（验证码已失效），零值表示不过期
+}
+
+// Receipt 服务商受理回执
+type Receipt struct {
+	Provider  string // 实际受理的服务商，如 aliyun / tencent
+	ReceiptID string // 服务商流水号（阿里云 BizId、腾讯云 SerialNo），用于匹配送达回执
+}
+
+// Dispatcher 可返回服务商受理回执的 Provider
+// 异步队列据此记录流水号，再由送达回执回调更新最终状态
+type Dispatcher interface {
+	Dispatch(ctx context.Context, msg Message) (Receipt, error)
+}
+
+// dispatchMessage 通过任意 Provider 发送消息，不支持回执的实现返回空回执
+func dispatchMessage(ctx context.Context, p Provider, msg Message) (Receipt, error) {
+	if d, ok := p.(Dispatcher); ok {
+		return d.Dispatch(ctx, msg)
+	}
+	if len(msg.Params) > 0 {
+		if tp, ok := p.(TemplateProvider); ok {
+			return Receipt{}, tp.SendTemplate(ctx, msg.Phone, msg.TemplateCode, msg.Params)
+		}
+		if msg.Content == "" {
+			return Receipt{}, p.SendSMS(ctx, msg.Phone, renderTemplateParams(msg.Params))
+		}
+	}
+	return Receipt{}, p.SendSMS(ctx, msg.Phone, msg.Content)
+}
+
 // MockProvider 模拟短信发送实现（用于开发与测试阶段）
 //
 // 不会真正发送短信，只打印日志到控制台
@@ -45,6 +107,9 @@ func (m *MockProvider) SendSMS(ctx context.Context, phone string, content string
 	}
 }
 
+// CodeParamName 验证码在模板中的变量名（阿里云模板写作 ${code}，腾讯云为第 1 个变量）
+const CodeParamName = "code"
+
 // FormatContent 根据模板渲染短信内容
 //
 // 参数：
@@ -0,0 +1,209 @@
+package sms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 阿里云短信（Dysms）接口常量
+const (
+	aliyunDefaultEndpoint = "https://dysmsapi.aliyuncs.com/"
+	aliyunDefaultRegion   = "cn-hangzhou"
+	aliyunAPIVersion      = "2017-05-25"
+	aliyunSuccessCode     = "OK"
+)
+
+// AliyunProvider 阿里云短信服务实现
+//
+// 使用 RPC 风格接口 SendSms，请求参数按 HMAC-SHA1 签名（SignatureVersion 1.0），
+// 模板变量以 JSON 对象传入 TemplateParam
+type AliyunProvider struct {
+	cfg    ProviderConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewAliyunProvider 创建阿里云短信 Provider
+func NewAliyunProvider(cfg ProviderConfig) (*AliyunProvider, error) {
+	cfg.Name = ProviderAliyun
+	if err := validateVendorConfig(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = aliyunDefaultEndpoint
+	}
+	if cfg.Region == "" {
+		cfg.Region = aliyunDefaultRegion
+	}
+	return &AliyunProvider{cfg: cfg, client: newVendorHTTPClient(cfg.Timeout), now: time.Now}, nil
+}
+
+// SendSMS 发送业务通知，正文作为通知模板的 content 变量
+func (p *AliyunProvider) SendSMS(ctx context.Context, phone string, content string) error {
+	_, err := p.Dispatch(ctx, Message{Phone: phone, Content: content})
+	return err
+}
+
+// SendTemplate 按模板发送短信
+func (p *AliyunProvider) SendTemplate(ctx context.Context, phone string, templateCode string, params []TemplateParam) error {
+	_, err := p.Dispatch(ctx, Message{Phone: phone, TemplateCode: templateCode, Params: params})
+	return err
+}
+
+// Dispatch 发送短信并返回服务商流水号
+// 只有正文时按通知模板发送，正文作为模板变量
+func (p *AliyunProvider) Dispatch(ctx context.Context, msg Message) (Receipt, error) {
+	phone, templateCode, params := msg.Phone, msg.TemplateCode, msg.Params
+	if len(params) == 0 && msg.Content != "" {
+		var err error
+		if templateCode, params, err = notifyParams(p.cfg, msg.Content); err != nil {
+			return Receipt{}, err
+		}
+	}
+	if templateCode == "" {
+		templateCode = p.cfg.TemplateCode
+	}
+	if templateCode == "" {
+		return Receipt{}, fmt.Errorf("%w: aliyun 未指定模板编号", ErrProviderTemplate)
+	}
+
+	templateParam := make(map[string]string, len(params))
+	for _, param := range params {
+		templateParam[param.Name] = param.Value
+	}
+	paramJSON, err := json.Marshal(templateParam)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("模板变量序列化失败: %w", err)
+	}
+
+	values := url.Values{}
+	values.Set("Action", "SendSms")
+	values.Set("Version", aliyunAPIVersion)
+	values.Set("RegionId", p.cfg.Region)
+	values.Set("Format", "JSON")
+	values.Set("AccessKeyId", p.cfg.AccessKey)
+	values.Set("SignatureMethod", "HMAC-SHA1")
+	values.Set("SignatureVersion", "1.0")
+	values.Set("SignatureNonce", aliyunNonce())
+	values.Set("Timestamp", p.now().UTC().Format("2006-01-02T15:04:05Z"))
+	values.Set("PhoneNumbers", phone)
+	values.Set("SignName", p.cfg.SignName)
+	values.Set("TemplateCode", templateCode)
+	values.Set("TemplateParam", string(paramJSON))
+	values.Set("Signature", aliyunSignature(http.MethodPost, values, p.cfg.SecretKey))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.Endpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return Receipt{}, fmt.Errorf("%w: 构造请求失败: %v", ErrProviderConfig, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	status, body, err := doVendorRequest(ctx, p.client, req)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	var resp struct {
+		RequestID string `json:"RequestId"`
+		Code      string `json:"Code"`
+		Message   string `json:"Message"`
+		BizID     string `json:"BizId"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Code == "" {
+		return Receipt{}, fmt.Errorf("%w: 无法解析响应 HTTP %d", ErrProviderUnavailable, status)
+	}
+	if resp.Code != aliyunSuccessCode {
+		return Receipt{}, &ProviderError{
+			Provider:  ProviderAliyun,
+			Code:      resp.Code,
+			Message:   resp.Message,
+			RequestID: resp.RequestID,
+			Err:       mapAliyunError(resp.Code),
+		}
+	}
+	return Receipt{Provider: ProviderAliyun, ReceiptID: resp.BizID}, nil
+}
+
+// #region 签名与错误码
+
+// aliyunSignature 计算 RPC 风格接口签名
+//
+// StringToSign = Method + "&" + percentEncode("/") + "&" + percentEncode(规范化查询串)，
+// 规范化查询串按参数名排序、RFC 3986 编码；密钥为 AccessKeySecret + "&"
+func aliyunSignature(method string, values url.Values, secret string) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key == "Signature" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, aliyunPercentEncode(key)+"="+aliyunPercentEncode(values.Get(key)))
+	}
+	stringToSign := method + "&" + aliyunPercentEncode("/") + "&" + aliyunPercentEncode(strings.Join(pairs, "&"))
+
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliyunPercentEncode 按阿里云要求的 RFC 3986 规则编码（空格为 %20，保留 ~）
+func aliyunPercentEncode(s string) string {
+	encoded := url.QueryEscape(s)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	encoded = strings.ReplaceAll(encoded, "%7E", "~")
+	return encoded
+}
+
+// aliyunNonce 生成防重放随机数
+func aliyunNonce() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// mapAliyunError 将阿里云错误码映射为哨兵错误
+func mapAliyunError(code string) error {
+	switch code {
+	case "isv.MOBILE_NUMBER_ILLEGAL", "isv.MOBILE_COUNT_OVER_LIMIT":
+		return ErrPhoneInvalid
+	case "isv.BUSINESS_LIMIT_CONTROL":
+		return ErrSendTooFrequent
+	case "isv.DAY_LIMIT_CONTROL":
+		return ErrDailyLimitReached
+	case "isv.AMOUNT_NOT_ENOUGH", "isv.OUT_OF_SERVICE":
+		return ErrProviderBalance
+	case "isv.SMS_SIGNATURE_ILLEGAL", "isv.SMS_TEMPLATE_ILLEGAL", "isv.TEMPLATE_MISSING_PARAMETERS",
+		"isv.INVALID_JSON_PARAM", "isv.PARAM_LENGTH_LIMIT", "isv.TEMPLATE_PARAMS_ILLEGAL",
+		"isv.SMS_SIGN_ILLEGAL", "isv.SMS_TEMPLATE_NOT_EXIST", "isv.SMS_SIGNATURE_SCENE_ILLEGAL":
+		return ErrProviderTemplate
+	case "InvalidAccessKeyId.NotFound", "InvalidAccessKeyId.Inactive", "SignatureDoesNotMatch",
+		"IncompleteSignature", "isv.ACCOUNT_NOT_EXISTS", "isv.ACCOUNT_ABNORMAL",
+		"isv.PRODUCT_UN_SUBSCRIPT", "isv.PRODUCT_UNSUBSCRIBE", "isv.RAM_PERMISSION_DENY", "isp.RAM_PERMISSION_DENY", "Forbidden.RAM":
+		return ErrProviderAuth
+	case "isp.SYSTEM_ERROR", "Throttling.User", "Throttling.Api", "ServiceUnavailable":
+		return ErrProviderUnavailable
+	}
+	return ErrProviderRejected
+}
+
+// #endregion
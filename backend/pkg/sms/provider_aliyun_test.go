@@ -0,0 +1,132 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAliyunSignature_DocumentedExample(t *testing.T) {
+	// 阿里云签名机制文档中的示例请求
+	values := url.Values{}
+	for key, value := range map[string]string{
+		"AccessKeyId":      "testId",
+		"Action":           "SendSms",
+		"Format":           "XML",
+		"OutId":            "123",
+		"PhoneNumbers":     "15300000001",
+		"RegionId":         "cn-hangzhou",
+		"SignName":         "阿里云短信测试专用",
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   "45e25e9b-0a6f-4070-8c85-2956eda1b466",
+		"SignatureVersion": "1.0",
+		"TemplateCode":     "SMS_71390007",
+		"TemplateParam":    `{"customer":"test"}`,
+		"Timestamp":        "2017-07-12T02:42:19Z",
+		"Version":          "2017-05-25",
+	} {
+		values.Set(key, value)
+	}
+
+	if got := aliyunSignature(http.MethodGet, values, "testSecret"); got != "zJDF+Lrzhj/ThnlvIToysFRq6t4=" {
+		t.Fatalf("signature=%s", got)
+	}
+}
+
+func TestAliyunProvider_SendCodeThroughService(t *testing.T) {
+	var received url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm: %v", err)
+		}
+		received = r.PostForm
+		if want := aliyunSignature(http.MethodPost, r.PostForm, "secret"); r.PostForm.Get("Signature") != want {
+			w.Write([]byte(`{"Code":"SignatureDoesNotMatch","Message":"bad signature","RequestId":"r1"}`))
+			return
+		}
+		w.Write([]byte(`{"Code":"OK","Message":"OK","BizId":"biz-1","RequestId":"r1"}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(ProviderConfig{
+		Name: ProviderAliyun, AccessKey: "key", SecretKey: "secret", SignName: "通行证",
+		TemplateCode: "SMS_0001", Endpoint: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	store, _, _ := newTestStore(t)
+	svc := NewService(store, provider, SMSRuntimeConfig{Enabled: true, ExpireIn: time.Minute})
+	scope := Scope{Role: "user", Purpose: PurposeLogin}
+	phone := "13800000020"
+
+	if err := svc.SendCode(context.Background(), scope, phone); err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	if received.Get("PhoneNumbers") != phone || received.Get("SignName") != "通行证" || received.Get("TemplateCode") != "SMS_0001" {
+		t.Fatalf("unexpected request params: %v", received)
+	}
+	var params map[string]string
+	if err := json.Unmarshal([]byte(received.Get("TemplateParam")), &params); err != nil {
+		t.Fatalf("TemplateParam=%q: %v", received.Get("TemplateParam"), err)
+	}
+	if err := svc.VerifyCode(context.Background(), scope, phone, params[CodeParamName]); err != nil {
+		t.Fatalf("VerifyCode with delivered code: %v", err)
+	}
+}
+
+func TestAliyunProvider_ErrorMapping(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{http.StatusOK, `{"Code":"isv.BUSINESS_LIMIT_CONTROL","Message":"触发分钟级流控","RequestId":"r2"}`, ErrSendTooFrequent},
+		{http.StatusOK, `{"Code":"isv.MOBILE_NUMBER_ILLEGAL","Message":"非法手机号","RequestId":"r3"}`, ErrPhoneInvalid},
+		{http.StatusOK, `{"Code":"isv.AMOUNT_NOT_ENOUGH","Message":"账户余额不足","RequestId":"r4"}`, ErrProviderBalance},
+		{http.StatusNotFound, `{"Code":"InvalidAccessKeyId.NotFound","Message":"Specified access key is not found.","RequestId":"r5"}`, ErrProviderAuth},
+		{http.StatusServiceUnavailable, `unavailable`, ErrProviderUnavailable},
+	}
+	for _, tc := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			w.Write([]byte(tc.body))
+		}))
+		provider, err := NewAliyunProvider(ProviderConfig{AccessKey: "key", SecretKey: "secret", SignName: "通行证", Endpoint: server.URL})
+		if err != nil {
+			t.Fatalf("NewAliyunProvider: %v", err)
+		}
+
+		err = provider.SendTemplate(context.Background(), "13800000021", "SMS_0001", nil)
+		server.Close()
+		if !errors.Is(err, tc.want) {
+			t.Fatalf("body %s: err=%v want %v", tc.body, err, tc.want)
+		}
+		var providerErr *ProviderError
+		if tc.status < http.StatusInternalServerError && (!errors.As(err, &providerErr) || providerErr.RequestID == "") {
+			t.Fatalf("body %s: err=%v should carry vendor code and request id", tc.body, err)
+		}
+	}
+}
+
+func TestNewProvider_RejectsIncompleteConfig(t *testing.T) {
+	if _, err := NewProvider(ProviderConfig{Name: "unknown"}); !errors.Is(err, ErrProviderConfig) {
+		t.Fatalf("unknown provider err=%v want ErrProviderConfig", err)
+	}
+	if _, err := NewProvider(ProviderConfig{Name: ProviderAliyun, AccessKey: "key"}); !errors.Is(err, ErrProviderConfig) {
+		t.Fatalf("missing secret err=%v want ErrProviderConfig", err)
+	}
+	if _, err := NewProvider(ProviderConfig{Name: ProviderTencent, AccessKey: "id", SecretKey: "key", SignName: "通行证"}); !errors.Is(err, ErrProviderConfig) {
+		t.Fatalf("missing app id err=%v want ErrProviderConfig", err)
+	}
+	if provider, err := NewProvider(ProviderConfig{}); err != nil {
+		t.Fatalf("default provider err=%v", err)
+	} else if _, ok := provider.(*MockProvider); !ok {
+		t.Fatalf("default provider=%T want *MockProvider", provider)
+	}
+}
@@ -0,0 +1,122 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 服务商名称（对应配置 sms.provider）
+const (
+	ProviderMock    = "mock"
+	ProviderAliyun  = "aliyun"
+	ProviderTencent = "tencent"
+)
+
+// defaultProviderTimeout 调用服务商接口的默认超时
+const defaultProviderTimeout = 5 * time.Second
+
+// maxProviderResponseSize 服务商响应体读取上限，避免异常响应占满内存
+const maxProviderResponseSize = 1 << 20
+
+// ProviderConfig 短信服务商接入配置（与配置文件结构解耦）
+//
+// 字段说明：
+//   - Name: 服务商名称 mock / aliyun / tencent，为空时使用 mock
+//   - AccessKey / SecretKey: 阿里云 AccessKeyId / AccessKeySecret，腾讯云 SecretId / SecretKey
+//   - SignName: 短信签名
+//   - TemplateCode: 验证码默认模板编号
+//   - NotifyTemplateCode: 业务通知模板编号（SendSMS 发送任意正文时使用，正文作为唯一变量 content 传入）
+//   - AppID: 腾讯云 SmsSdkAppId（阿里云无需）
+//   - Region: 地域，为空时阿里云使用 cn-hangzhou，腾讯云使用 ap-guangzhou
+//   - Endpoint: 接口地址，为空时使用官方地址（测试时可指向 httptest 服务）
+//   - Timeout: 单次请求超时，<=0 时取 5 秒
+type ProviderConfig struct {
+	Name               string
+	AccessKey          string
+	SecretKey          string
+	SignName           string
+	TemplateCode       string
+	NotifyTemplateCode string
+	AppID              string
+	Region             string
+	Endpoint           string
+	Timeout            time.Duration
+}
+
+// NewProvider 按配置创建短信服务商实现
+//
+// 返回 ErrProviderConfig 表示服务商未知或缺少必填项，调用方应在启动阶段直接失败，
+// 避免上线后才发现验证码发不出去
+func NewProvider(cfg ProviderConfig) (Provider, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Name)) {
+	case "", ProviderMock:
+		return NewMockProvider(), nil
+	case ProviderAliyun:
+		return NewAliyunProvider(cfg)
+	case ProviderTencent:
+		return NewTencentProvider(cfg)
+	default:
+		return nil, fmt.Errorf("%w: 未知服务商 %q", ErrProviderConfig, cfg.Name)
+	}
+}
+
+// validateVendorConfig 校验真实服务商的公共必填项
+func validateVendorConfig(cfg ProviderConfig) error {
+	var missing []string
+	if cfg.AccessKey == "" {
+		missing = append(missing, "api_key")
+	}
+	if cfg.SecretKey == "" {
+		missing = append(missing, "api_secret")
+	}
+	if cfg.SignName == "" {
+		missing = append(missing, "sign_name")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s 缺少 %s", ErrProviderConfig, cfg.Name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// newVendorHTTPClient 创建调用服务商接口的 HTTP 客户端
+func newVendorHTTPClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultProviderTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// doVendorRequest 发送请求并读取响应体
+//
+// 网络错误、超时与 5xx 统一映射为 ErrProviderUnavailable；调用方取消时原样返回 ctx 错误
+func doVendorRequest(ctx context.Context, client *http.Client, req *http.Request) (int, []byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, nil, ctxErr
+		}
+		return 0, nil, fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProviderResponseSize))
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("%w: 读取响应失败: %v", ErrProviderUnavailable, err)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return resp.StatusCode, body, fmt.Errorf("%w: HTTP %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// notifyParams 业务通知正文对应的模板变量
+func notifyParams(cfg ProviderConfig, content string) (string, []TemplateParam, error) {
+	if cfg.NotifyTemplateCode == "" {
+		return "", nil, fmt.Errorf("%w: %s 未配置通知模板，无法发送任意正文", ErrProviderTemplate, cfg.Name)
+	}
+	return cfg.NotifyTemplateCode, []TemplateParam{{Name: "content", Value: content}}, nil
+}
@@ -0,0 +1,451 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"    // 正常放行
+	BreakerOpen     = "open"      // 熔断中，跳过该服务商
+	BreakerHalfOpen = "half_open" // 冷却结束，放行一次试探请求
+)
+
+// 故障转移默认参数
+const (
+	defaultFailureThreshold = 3
+	defaultOpenTimeout      = 30 * time.Second
+	defaultRetryBackoff     = 200 * time.Millisecond
+	defaultMaxRetryBackoff  = 2 * time.Second
+)
+
+// FailoverMember 参与故障转移的服务商
+//
+// Priority 越小越优先，同优先级按 Weight 加权随机分流（Weight <= 0 视为 1）
+type FailoverMember struct {
+	Name     string
+	Provider Provider
+	Priority int
+	Weight   int
+}
+
+// FailoverConfig 故障转移与熔断参数，零值字段使用默认值
+//
+// 字段说明：
+//   - FailureThreshold: 连续失败多少次后熔断，默认 3
+//   - OpenTimeout: 熔断持续时间，到期后放行一次试探请求，默认 30 秒
+//   - MaxAttempts: 单条短信最多尝试的服务商个数，默认全部服务商
+//   - Backoff: 换服务商重试前的等待时间，每次翻倍，默认 200 毫秒
+//   - MaxBackoff: 重试等待上限，默认 2 秒
+type FailoverConfig struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	MaxAttempts      int
+	Backoff          time.Duration
+	MaxBackoff       time.Duration
+}
+
+// ProviderStats 单个服务商的发送统计
+type ProviderStats struct {
+	Name                string  `json:"name"`
+	State               string  `json:"state"`                // 熔断器状态 closed / open / half_open
+	Successes           int64   `json:"successes"`            // 发送成功次数
+	Failures            int64   `json:"failures"`             // 计入熔断的失败次数（服务商故障）
+	Rejections          int64   `json:"rejections"`           // 服务商正常响应但拒绝发送的次数（号码无效、频控等）
+	ConsecutiveFailures int     `json:"consecutive_failures"` // 当前连续失败次数
+	AvgLatencyMs        float64 `json:"avg_latency_ms"`       // 平均调用耗时（毫秒）
+	MaxLatencyMs        float64 `json:"max_latency_ms"`       // 最大调用耗时（毫秒）
+	LastError           string  `json:"last_error,omitempty"` // 最近一次失败原因
+}
+
+// StatsReporter 可以汇报各服务商发送统计的 Provider
+type StatsReporter interface {
+	Stats() []ProviderStats
+}
+
+// IsRetryable 判断发送失败后是否值得换一家服务商重试
+//
+// 服务商故障、鉴权、余额、模板等问题只与该服务商有关，换一家可能成功；
+// 号码无效、频控、日上限、内容被拒与调用方取消则换哪家都一样，直接返回。
+// 无法识别的错误（如自定义 Provider 的网络错误）按可重试处理
+func IsRetryable(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.Is(err, ErrPhoneInvalid), errors.Is(err, ErrSendTooFrequent),
+		errors.Is(err, ErrDailyLimitReached), errors.Is(err, ErrProviderRejected):
+		return false
+	}
+	return true
+}
+
+// FailoverProvider 多服务商故障转移 Provider
+//
+// 按优先级与权重挑选服务商，可重试错误换下一家并指数退避；
+// 每个服务商独立熔断，连续失败达到阈值后在 OpenTimeout 内不再调用，
+// 冷却结束放行一次试探请求，成功即恢复。实现 Provider、TemplateProvider 与 Dispatcher，
+// 可直接传给 NewService。
+//
+// 多家模板类服务商的模板编号各不相同，此时 Service 的 Template 应留空，
+// 由各服务商 ProviderConfig.TemplateCode 生效
+type FailoverProvider struct {
+	members []*failoverMember
+	cfg     FailoverConfig
+	logger  Logger
+
+	mu   sync.Mutex
+	rand *rand.Rand
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// failoverMember 服务商及其熔断状态与统计
+type failoverMember struct {
+	FailoverMember
+
+	mu                  sync.Mutex
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	trialInFlight       bool
+	successes           int64
+	failures            int64
+	rejections          int64
+	calls               int64
+	totalLatency        time.Duration
+	maxLatency          time.Duration
+	lastError           string
+}
+
+// NewFailoverProvider 创建多服务商故障转移 Provider
+func NewFailoverProvider(members []FailoverMember, cfg FailoverConfig) (*FailoverProvider, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("%w: 至少需要一个服务商", ErrProviderConfig)
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultFailureThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = defaultOpenTimeout
+	}
+	if cfg.MaxAttempts <= 0 || cfg.MaxAttempts > len(members) {
+		cfg.MaxAttempts = len(members)
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultRetryBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxRetryBackoff
+	}
+
+	seen := make(map[string]bool, len(members))
+	wrapped := make([]*failoverMember, 0, len(members))
+	for i, member := range members {
+		if member.Provider == nil {
+			return nil, fmt.Errorf("%w: 第 %d 个服务商为空", ErrProviderConfig, i+1)
+		}
+		if member.Name == "" {
+			member.Name = fmt.Sprintf("provider-%d", i+1)
+		}
+		if seen[member.Name] {
+			return nil, fmt.Errorf("%w: 服务商名称重复 %q", ErrProviderConfig, member.Name)
+		}
+		seen[member.Name] = true
+		if member.Weight <= 0 {
+			member.Weight = 1
+		}
+		wrapped = append(wrapped, &failoverMember{FailoverMember: member, state: BreakerClosed})
+	}
+
+	return &FailoverProvider{
+		members: wrapped,
+		cfg:     cfg,
+		logger:  StdLogger{},
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:     time.Now,
+		sleep:   sleepContext,
+	}, nil
+}
+
+// SetLogger 设置自定义日志器
+func (f *FailoverProvider) SetLogger(l Logger) {
+	if l == nil {
+		return
+	}
+	f.logger = l
+}
+
+// SendSMS 发送短信正文
+func (f *FailoverProvider) SendSMS(ctx context.Context, phone string, content string) error {
+	_, err := f.Dispatch(ctx, Message{Phone: phone, Content: content})
+	return err
+}
+
+// SendTemplate 按模板发送短信
+// 不支持模板的服务商（如 Mock）改为发送由模板变量渲染的正文
+func (f *FailoverProvider) SendTemplate(ctx context.Context, phone string, templateCode string, params []TemplateParam) error {
+	_, err := f.Dispatch(ctx, Message{Phone: phone, TemplateCode: templateCode, Params: params})
+	return err
+}
+
+// Dispatch 发送短信并返回实际受理服务商的回执
+func (f *FailoverProvider) Dispatch(ctx context.Context, msg Message) (Receipt, error) {
+	return f.send(ctx, msg.Phone, func(ctx context.Context, p Provider) (Receipt, error) {
+		return dispatchMessage(ctx, p, msg)
+	})
+}
+
+// Stats 返回各服务商的发送统计（按配置顺序）
+func (f *FailoverProvider) Stats() []ProviderStats {
+	now := f.now()
+	stats := make([]ProviderStats, 0, len(f.members))
+	for _, m := range f.members {
+		m.mu.Lock()
+		s := ProviderStats{
+			Name:                m.Name,
+			State:               m.currentState(now, f.cfg.OpenTimeout),
+			Successes:           m.successes,
+			Failures:            m.failures,
+			Rejections:          m.rejections,
+			ConsecutiveFailures: m.consecutiveFailures,
+			MaxLatencyMs:        durationMs(m.maxLatency),
+			LastError:           m.lastError,
+		}
+		if m.calls > 0 {
+			s.AvgLatencyMs = durationMs(m.totalLatency / time.Duration(m.calls))
+		}
+		m.mu.Unlock()
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// send 依次尝试候选服务商，可重试错误换下一家
+func (f *FailoverProvider) send(ctx context.Context, phone string, call func(context.Context, Provider) (Receipt, error)) (Receipt, error) {
+	candidates := f.candidates()
+	if len(candidates) == 0 {
+		return Receipt{}, fmt.Errorf("%w: 所有服务商均处于熔断状态", ErrProviderUnavailable)
+	}
+
+	var lastErr error
+	tried := 0
+	for _, member := range candidates {
+		if tried >= f.cfg.MaxAttempts {
+			break
+		}
+		if !member.acquire(f.now(), f.cfg.OpenTimeout) {
+			// 排序之后被其他请求打开了熔断，或试探名额已被占用
+			continue
+		}
+		if tried > 0 {
+			if err := f.sleep(ctx, f.backoff(tried)); err != nil {
+				member.release()
+				return Receipt{}, err
+			}
+		}
+		tried++
+
+		start := f.now()
+		receipt, err := call(ctx, member.Provider)
+		member.record(err, f.now().Sub(start), f.now(), f.cfg.FailureThreshold)
+		if err == nil {
+			if receipt.Provider == "" {
+				receipt.Provider = member.Name
+			}
+			return receipt, nil
+		}
+
+		lastErr = err
+		if !IsRetryable(err) {
+			return Receipt{}, err
+		}
+		f.logger.Errorf("sms.Failover provider=%s phone=%s attempt=%d err=%v", member.Name, maskPhone(phone), tried, err)
+	}
+	if lastErr == nil {
+		return Receipt{}, fmt.Errorf("%w: 没有可用的服务商", ErrProviderUnavailable)
+	}
+	return Receipt{}, lastErr
+}
+
+// candidates 按优先级排序、同优先级加权随机的可用服务商列表
+func (f *FailoverProvider) candidates() []*failoverMember {
+	now := f.now()
+	groups := make(map[int][]*failoverMember)
+	var priorities []int
+	for _, m := range f.members {
+		m.mu.Lock()
+		state := m.currentState(now, f.cfg.OpenTimeout)
+		available := state == BreakerClosed || (state == BreakerHalfOpen && !m.trialInFlight)
+		m.mu.Unlock()
+		if !available {
+			continue
+		}
+		if _, ok := groups[m.Priority]; !ok {
+			priorities = append(priorities, m.Priority)
+		}
+		groups[m.Priority] = append(groups[m.Priority], m)
+	}
+	sort.Ints(priorities)
+
+	ordered := make([]*failoverMember, 0, len(f.members))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, priority := range priorities {
+		ordered = append(ordered, weightedShuffle(f.rand, groups[priority])...)
+	}
+	return ordered
+}
+
+// backoff 第 attempt 次重试前的等待时间（指数退避，带上限）
+func (f *FailoverProvider) backoff(attempt int) time.Duration {
+	d := f.cfg.Backoff << (attempt - 1)
+	if d <= 0 || d > f.cfg.MaxBackoff {
+		return f.cfg.MaxBackoff
+	}
+	return d
+}
+
+// #region 熔断器
+
+// currentState 计算当前熔断状态（需持有 m.mu）
+// 熔断超过 OpenTimeout 后视为半开
+func (m *failoverMember) currentState(now time.Time, openTimeout time.Duration) string {
+	if m.state == BreakerOpen && now.Sub(m.openedAt) >= openTimeout {
+		return BreakerHalfOpen
+	}
+	return m.state
+}
+
+// acquire 申请调用该服务商；半开状态只放行一个试探请求
+func (m *failoverMember) acquire(now time.Time, openTimeout time.Duration) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch m.currentState(now, openTimeout) {
+	case BreakerClosed:
+		return true
+	case BreakerHalfOpen:
+		if m.trialInFlight {
+			return false
+		}
+		m.state = BreakerHalfOpen
+		m.trialInFlight = true
+		return true
+	}
+	return false
+}
+
+// release 放弃已申请的调用（未真正发出请求），归还半开状态的试探名额
+func (m *failoverMember) release() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.trialInFlight {
+		m.trialInFlight = false
+		m.state = BreakerOpen
+	}
+}
+
+// record 记录调用结果并推进熔断状态
+//
+// 只有可重试错误（服务商故障）计入熔断；号码无效、频控等说明服务商工作正常，
+// 视同健康响应清零连续失败次数。调用方取消不影响服务商健康度
+func (m *failoverMember) record(err error, latency time.Duration, now time.Time, threshold int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls++
+	m.totalLatency += latency
+	if latency > m.maxLatency {
+		m.maxLatency = latency
+	}
+	trial := m.trialInFlight
+	m.trialInFlight = false
+
+	switch {
+	case err == nil:
+		m.successes++
+		m.consecutiveFailures = 0
+		m.state = BreakerClosed
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		if trial {
+			// 试探请求被取消，回到熔断状态等待下一次试探
+			m.state = BreakerOpen
+		}
+	case IsRetryable(err):
+		m.failures++
+		m.consecutiveFailures++
+		m.lastError = err.Error()
+		if trial || m.consecutiveFailures >= threshold {
+			m.state = BreakerOpen
+			m.openedAt = now
+		}
+	default:
+		m.rejections++
+		m.consecutiveFailures = 0
+		m.lastError = err.Error()
+		m.state = BreakerClosed
+	}
+}
+
+// #endregion
+
+// weightedShuffle 按权重随机排序（权重越大越可能排在前面）
+func weightedShuffle(rng *rand.Rand, members []*failoverMember) []*failoverMember {
+	remaining := append([]*failoverMember(nil), members...)
+	ordered := make([]*failoverMember, 0, len(members))
+	for len(remaining) > 0 {
+		total := 0
+		for _, m := range remaining {
+			total += m.Weight
+		}
+		pick := rng.Intn(total)
+		for i, m := range remaining {
+			if pick < m.Weight {
+				ordered = append(ordered, m)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+			pick -= m.Weight
+		}
+	}
+	return ordered
+}
+
+// renderTemplateParams 为不支持模板的服务商渲染正文
+// 验证码使用默认文案，其余变量按顺序拼接
+func renderTemplateParams(params []TemplateParam) string {
+	values := make([]string, 0, len(params))
+	for _, param := range params {
+		if param.Name == CodeParamName {
+			return FormatContent("", param.Value)
+		}
+		values = append(values, param.Value)
+	}
+	return strings.Join(values, "，")
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// sleepContext 等待指定时长，ctx 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
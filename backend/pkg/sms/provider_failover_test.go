@@ -0,0 +1,120 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedProvider 依次返回预设错误，用完后一直成功
+type scriptedProvider struct {
+	errs  []error
+	calls int
+	last  string
+}
+
+func (p *scriptedProvider) SendSMS(ctx context.Context, phone string, content string) error {
+	p.calls++
+	p.last = content
+	if len(p.errs) == 0 {
+		return nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return err
+}
+
+func newTestFailover(t *testing.T, cfg FailoverConfig, members ...FailoverMember) (*FailoverProvider, *time.Time, *[]time.Duration) {
+	t.Helper()
+	f, err := NewFailoverProvider(members, cfg)
+	if err != nil {
+		t.Fatalf("NewFailoverProvider: %v", err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+	f.now = func() time.Time { return now }
+	f.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return f, &now, &sleeps
+}
+
+func TestFailoverProvider_RetriesOnAnotherProvider(t *testing.T) {
+	primary := &scriptedProvider{errs: []error{ErrProviderUnavailable}}
+	backup := &scriptedProvider{}
+	f, _, sleeps := newTestFailover(t, FailoverConfig{Backoff: 100 * time.Millisecond},
+		FailoverMember{Name: "backup", Provider: backup, Priority: 2},
+		FailoverMember{Name: "primary", Provider: primary, Priority: 1},
+	)
+
+	// 经由 Service 发送：Mock 类服务商收到按默认文案渲染的验证码
+	store, _, _ := newTestStore(t)
+	svc := NewService(store, f, SMSRuntimeConfig{Enabled: true, ExpireIn: time.Minute})
+	if err := svc.SendCode(context.Background(), Scope{Role: "user", Purpose: PurposeLogin}, "13800000040"); err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	if primary.calls != 1 || backup.calls != 1 || !strings.Contains(backup.last, "验证码") {
+		t.Fatalf("calls primary=%d backup=%d last=%q", primary.calls, backup.calls, backup.last)
+	}
+	if len(*sleeps) != 1 || (*sleeps)[0] != 100*time.Millisecond {
+		t.Fatalf("backoff sleeps=%v", *sleeps)
+	}
+
+	stats := f.Stats()
+	if stats[0].Name != "backup" || stats[0].Successes != 1 || stats[1].Failures != 1 || stats[1].ConsecutiveFailures != 1 {
+		t.Fatalf("stats=%+v", stats)
+	}
+
+	// 号码类错误换哪家都一样，不再重试
+	primary.errs = []error{&ProviderError{Provider: "primary", Code: "isv.MOBILE_NUMBER_ILLEGAL", Err: ErrPhoneInvalid}}
+	if err := f.SendSMS(context.Background(), "13800000041", "通知"); !errors.Is(err, ErrPhoneInvalid) {
+		t.Fatalf("err=%v want ErrPhoneInvalid", err)
+	}
+	if backup.calls != 1 {
+		t.Fatalf("non-retryable error reached backup: calls=%d", backup.calls)
+	}
+	if stats := f.Stats(); stats[1].Rejections != 1 || stats[1].ConsecutiveFailures != 0 {
+		t.Fatalf("rejection should reset consecutive failures: %+v", stats[1])
+	}
+}
+
+func TestFailoverProvider_CircuitBreaker(t *testing.T) {
+	primary := &scriptedProvider{errs: []error{ErrProviderUnavailable, ErrProviderUnavailable}}
+	backup := &scriptedProvider{}
+	f, now, _ := newTestFailover(t, FailoverConfig{FailureThreshold: 2, OpenTimeout: time.Minute},
+		FailoverMember{Name: "primary", Provider: primary, Priority: 1},
+		FailoverMember{Name: "backup", Provider: backup, Priority: 2},
+	)
+	ctx := context.Background()
+	send := func() {
+		t.Helper()
+		if err := f.SendSMS(ctx, "13800000042", "通知"); err != nil {
+			t.Fatalf("SendSMS: %v", err)
+		}
+	}
+
+	send()
+	send()
+	if state := f.Stats()[0].State; state != BreakerOpen {
+		t.Fatalf("primary state=%s want open after 2 failures", state)
+	}
+
+	// 熔断期间直接走备用通道
+	send()
+	if primary.calls != 2 || backup.calls != 3 {
+		t.Fatalf("calls primary=%d backup=%d", primary.calls, backup.calls)
+	}
+
+	// 冷却结束放行试探请求，成功即恢复
+	*now = now.Add(time.Minute)
+	if state := f.Stats()[0].State; state != BreakerHalfOpen {
+		t.Fatalf("primary state=%s want half_open", state)
+	}
+	send()
+	if primary.calls != 3 || backup.calls != 3 || f.Stats()[0].State != BreakerClosed {
+		t.Fatalf("trial: calls primary=%d backup=%d stats=%+v", primary.calls, backup.calls, f.Stats()[0])
+	}
+}
@@ -0,0 +1,256 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 腾讯云短信接口常量
+const (
+	tencentDefaultEndpoint = "https://sms.tencentcloudapi.com/"
+	tencentDefaultRegion   = "ap-guangzhou"
+	tencentAPIVersion      = "2021-01-11"
+	tencentService         = "sms"
+	tencentAlgorithm       = "TC3-HMAC-SHA256"
+	tencentContentType     = "application/json; charset=utf-8"
+	tencentSuccessCode     = "Ok"
+)
+
+// TencentProvider 腾讯云短信服务实现
+//
+// 使用 API 3.0 接口 SendSms，请求按 TC3-HMAC-SHA256 签名；
+// 模板变量按顺序传入 TemplateParamSet，对应模板中的 {1}、{2}…
+type TencentProvider struct {
+	cfg    ProviderConfig
+	host   string
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewTencentProvider 创建腾讯云短信 Provider
+func NewTencentProvider(cfg ProviderConfig) (*TencentProvider, error) {
+	cfg.Name = ProviderTencent
+	if err := validateVendorConfig(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.AppID == "" {
+		return nil, fmt.Errorf("%w: tencent 缺少 app_id", ErrProviderConfig)
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = tencentDefaultEndpoint
+	}
+	if cfg.Region == "" {
+		cfg.Region = tencentDefaultRegion
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: tencent endpoint 无效 %q", ErrProviderConfig, cfg.Endpoint)
+	}
+	return &TencentProvider{
+		cfg:    cfg,
+		host:   endpoint.Host,
+		client: newVendorHTTPClient(cfg.Timeout),
+		now:    time.Now,
+	}, nil
+}
+
+// SendSMS 发送业务通知，正文作为通知模板的唯一变量
+func (p *TencentProvider) SendSMS(ctx context.Context, phone string, content string) error {
+	_, err := p.Dispatch(ctx, Message{Phone: phone, Content: content})
+	return err
+}
+
+// SendTemplate 按模板发送短信
+func (p *TencentProvider) SendTemplate(ctx context.Context, phone string, templateCode string, params []TemplateParam) error {
+	_, err := p.Dispatch(ctx, Message{Phone: phone, TemplateCode: templateCode, Params: params})
+	return err
+}
+
+// Dispatch 发送短信并返回服务商流水号
+// 只有正文时按通知模板发送，正文作为模板变量
+func (p *TencentProvider) Dispatch(ctx context.Context, msg Message) (Receipt, error) {
+	phone, templateCode, params := msg.Phone, msg.TemplateCode, msg.Params
+	if len(params) == 0 && msg.Content != "" {
+		var err error
+		if templateCode, params, err = notifyParams(p.cfg, msg.Content); err != nil {
+			return Receipt{}, err
+		}
+	}
+	if templateCode == "" {
+		templateCode = p.cfg.TemplateCode
+	}
+	if templateCode == "" {
+		return Receipt{}, fmt.Errorf("%w: tencent 未指定模板编号", ErrProviderTemplate)
+	}
+
+	paramSet := make([]string, 0, len(params))
+	for _, param := range params {
+		paramSet = append(paramSet, param.Value)
+	}
+	payload, err := json.Marshal(map[string]any{
+		"PhoneNumberSet":   []string{tencentPhoneNumber(phone)},
+		"SmsSdkAppId":      p.cfg.AppID,
+		"SignName":         p.cfg.SignName,
+		"TemplateId":       templateCode,
+		"TemplateParamSet": paramSet,
+	})
+	if err != nil {
+		return Receipt{}, fmt.Errorf("请求序列化失败: %w", err)
+	}
+
+	timestamp := p.now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return Receipt{}, fmt.Errorf("%w: 构造请求失败: %v", ErrProviderConfig, err)
+	}
+	req.Header.Set("Content-Type", tencentContentType)
+	req.Header.Set("X-TC-Action", "SendSms")
+	req.Header.Set("X-TC-Version", tencentAPIVersion)
+	req.Header.Set("X-TC-Region", p.cfg.Region)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("Authorization", tencentAuthorization(p.cfg.AccessKey, p.cfg.SecretKey, tencentService, p.host, payload, timestamp))
+
+	status, body, err := doVendorRequest(ctx, p.client, req)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	var resp struct {
+		Response struct {
+			RequestID string `json:"RequestId"`
+			Error     *struct {
+				Code    string `json:"Code"`
+				Message string `json:"Message"`
+			} `json:"Error"`
+			SendStatusSet []struct {
+				SerialNo    string `json:"SerialNo"`
+				PhoneNumber string `json:"PhoneNumber"`
+				Code        string `json:"Code"`
+				Message     string `json:"Message"`
+			} `json:"SendStatusSet"`
+		} `json:"Response"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Receipt{}, fmt.Errorf("%w: 无法解析响应 HTTP %d", ErrProviderUnavailable, status)
+	}
+
+	// 请求级错误（鉴权、参数、频控等）
+	if e := resp.Response.Error; e != nil {
+		return Receipt{}, &ProviderError{
+			Provider:  ProviderTencent,
+			Code:      e.Code,
+			Message:   e.Message,
+			RequestID: resp.Response.RequestID,
+			Err:       mapTencentError(e.Code),
+		}
+	}
+	// 号码级发送结果，只发送了一个号码
+	if len(resp.Response.SendStatusSet) == 0 {
+		return Receipt{}, fmt.Errorf("%w: 响应缺少发送结果 HTTP %d", ErrProviderUnavailable, status)
+	}
+	result := resp.Response.SendStatusSet[0]
+	if result.Code != tencentSuccessCode {
+		return Receipt{}, &ProviderError{
+			Provider:  ProviderTencent,
+			Code:      result.Code,
+			Message:   result.Message,
+			RequestID: resp.Response.RequestID,
+			Err:       mapTencentError(result.Code),
+		}
+	}
+	return Receipt{Provider: ProviderTencent, ReceiptID: result.SerialNo}, nil
+}
+
+// #region 签名与错误码
+
+// tencentAuthorization 计算 TC3-HMAC-SHA256 签名并拼接 Authorization 头
+//
+// 只签名 content-type 与 host 两个头；派生密钥依次以日期、服务名（短信为 sms）、"tc3_request" 做 HMAC
+func tencentAuthorization(secretID, secretKey, service, host string, payload []byte, timestamp int64) string {
+	date := time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+	payloadHash := sha256.Sum256(payload)
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodPost,
+		"/",
+		"",
+		"content-type:" + tencentContentType + "\nhost:" + host + "\n",
+		"content-type;host",
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	credentialScope := date + "/" + service + "/tc3_request"
+	stringToSign := strings.Join([]string{
+		tencentAlgorithm,
+		strconv.FormatInt(timestamp, 10),
+		credentialScope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	secretDate := hmacSHA256([]byte("TC3"+secretKey), date)
+	secretService := hmacSHA256(secretDate, service)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=content-type;host, Signature=%s",
+		tencentAlgorithm, secretID, credentialScope, signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// tencentPhoneNumber 腾讯云要求 E.164 格式，国内号码补 +86
+func tencentPhoneNumber(phone string) string {
+	if strings.HasPrefix(phone, "+") {
+		return phone
+	}
+	return "+86" + phone
+}
+
+// mapTencentError 将腾讯云错误码映射为哨兵错误
+func mapTencentError(code string) error {
+	switch {
+	case code == "InvalidParameterValue.IncorrectPhoneNumber", code == "FailedOperation.PhoneNumberParseFail",
+		code == "UnsupportedOperation.ContainDomesticAndInternationalPhoneNumber":
+		return ErrPhoneInvalid
+	case code == "LimitExceeded.PhoneNumberDailyLimit":
+		return ErrDailyLimitReached
+	case code == "LimitExceeded.PhoneNumberThirtySecondLimit", code == "LimitExceeded.PhoneNumberOneHourLimit",
+		code == "LimitExceeded.PhoneNumberSameContentDailyLimit":
+		return ErrSendTooFrequent
+	case code == "FailedOperation.InsufficientBalanceInSmsPackage", code == "FailedOperation.ContainerNotFound",
+		code == "UnauthorizedOperation.SerivceSuspendDueToArrears":
+		return ErrProviderBalance
+	case code == "FailedOperation.TemplateIncorrectOrUnapproved", code == "FailedOperation.SignatureIncorrectOrUnapproved",
+		code == "FailedOperation.TemplateParamSetNotMatchApprovedTemplate",
+		strings.HasPrefix(code, "InvalidParameterValue.TemplateParameter"), code == "InvalidParameterValue.ProhibitedUseUrlInTemplateParameter",
+		code == "InvalidParameterValue.SdkAppIdNotExist":
+		return ErrProviderTemplate
+	case strings.HasPrefix(code, "AuthFailure."), code == "UnauthorizedOperation.RequestPermissionDeny",
+		code == "UnauthorizedOperation.SmsSdkAppIdVerifyFail", code == "UnauthorizedOperation.RequestIpNotInWhitelist",
+		code == "UnauthorizedOperation.IndividualUserMarketingSmsPermissionDeny":
+		return ErrProviderAuth
+	// 应用级日限额与接口频控针对整个账号，不是该号码的问题，按暂不可用处理
+	case strings.HasPrefix(code, "InternalError"), code == "ServiceUnavailable", code == "RequestLimitExceeded",
+		code == "LimitExceeded.DailyLimit", code == "LimitExceeded.AppDailyLimit",
+		code == "LimitExceeded.AppGlobalDailyLimit", code == "LimitExceeded.AppMainlandChinaDailyLimit":
+		return ErrProviderUnavailable
+	}
+	return ErrProviderRejected
+}
+
+// #endregion
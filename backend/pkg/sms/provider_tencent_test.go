@@ -0,0 +1,117 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTencentAuthorization_DocumentedExample(t *testing.T) {
+	// 腾讯云 API 3.0 签名方法 v3 文档中的示例请求
+	payload := []byte(`{"Limit": 1, "Filters": [{"Values": ["\u672a\u547d\u540d"], "Name": "instance-name"}]}`)
+	got := tencentAuthorization("AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE", "Gu5t9xGARNpq86cd98joQYCN3EXAMPLE",
+		"cvm", "cvm.tencentcloudapi.com", payload, 1551113065)
+
+	want := "TC3-HMAC-SHA256 Credential=AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE/2019-02-25/cvm/tc3_request, " +
+		"SignedHeaders=content-type;host, Signature=72e494ea809ad7a8c8f7a4507b9bddcbaa8e581f516e8da2f66e2c5a96525168"
+	if got != want {
+		t.Fatalf("authorization=%s", got)
+	}
+}
+
+// tencentStub 腾讯云 SendSms 接口替身，校验签名后返回给定响应
+func tencentStub(t *testing.T, response string, received *map[string]any) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get("X-TC-Timestamp"), 10, 64)
+		if r.Header.Get("X-TC-Action") != "SendSms" ||
+			r.Header.Get("Authorization") != tencentAuthorization("AKIDtest", "secret", tencentService, r.Host, body, timestamp) {
+			w.Write([]byte(`{"Response":{"Error":{"Code":"AuthFailure.SignatureFailure","Message":"bad signature"},"RequestId":"r0"}}`))
+			return
+		}
+		if received != nil {
+			if err := json.Unmarshal(body, received); err != nil {
+				t.Errorf("payload: %v", err)
+			}
+		}
+		w.Write([]byte(response))
+	}))
+}
+
+func newTestTencentProvider(t *testing.T, endpoint string) *TencentProvider {
+	t.Helper()
+	provider, err := NewTencentProvider(ProviderConfig{
+		AccessKey: "AKIDtest", SecretKey: "secret", SignName: "通行证", AppID: "1400000000",
+		TemplateCode: "100001", NotifyTemplateCode: "100002", Endpoint: endpoint,
+	})
+	if err != nil {
+		t.Fatalf("NewTencentProvider: %v", err)
+	}
+	return provider
+}
+
+func TestTencentProvider_SendCodeThroughService(t *testing.T) {
+	var received map[string]any
+	server := tencentStub(t, `{"Response":{"SendStatusSet":[{"SerialNo":"s1","PhoneNumber":"+8613800000030","Code":"Ok","Message":"send success"}],"RequestId":"r1"}}`, &received)
+	defer server.Close()
+
+	store, _, _ := newTestStore(t)
+	svc := NewService(store, newTestTencentProvider(t, server.URL), SMSRuntimeConfig{Enabled: true, ExpireIn: time.Minute})
+	scope := Scope{Role: "rider", Purpose: PurposeRegister}
+	phone := "13800000030"
+
+	if err := svc.SendCode(context.Background(), scope, phone); err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	phones, _ := received["PhoneNumberSet"].([]any)
+	params, _ := received["TemplateParamSet"].([]any)
+	if len(phones) != 1 || phones[0] != "+86"+phone || received["TemplateId"] != "100001" || received["SmsSdkAppId"] != "1400000000" || len(params) != 1 {
+		t.Fatalf("unexpected payload: %v", received)
+	}
+	code, _ := params[0].(string)
+	if err := svc.VerifyCode(context.Background(), scope, phone, code); err != nil {
+		t.Fatalf("VerifyCode with delivered code: %v", err)
+	}
+}
+
+func TestTencentProvider_ErrorMapping(t *testing.T) {
+	cases := []struct {
+		body string
+		want error
+	}{
+		// 号码级错误
+		{`{"Response":{"SendStatusSet":[{"Code":"LimitExceeded.PhoneNumberThirtySecondLimit","Message":"30秒内下发短信条数超过上限"}],"RequestId":"r2"}}`, ErrSendTooFrequent},
+		{`{"Response":{"SendStatusSet":[{"Code":"LimitExceeded.PhoneNumberDailyLimit","Message":"单个手机号日下发短信条数超过上限"}],"RequestId":"r3"}}`, ErrDailyLimitReached},
+		{`{"Response":{"SendStatusSet":[{"Code":"InvalidParameterValue.IncorrectPhoneNumber","Message":"手机号格式错误"}],"RequestId":"r4"}}`, ErrPhoneInvalid},
+		// 请求级错误
+		{`{"Response":{"Error":{"Code":"FailedOperation.TemplateIncorrectOrUnapproved","Message":"模板未审批或内容不匹配"},"RequestId":"r5"}}`, ErrProviderTemplate},
+		{`{"Response":{"Error":{"Code":"FailedOperation.InsufficientBalanceInSmsPackage","Message":"套餐包余量不足"},"RequestId":"r6"}}`, ErrProviderBalance},
+		{`{"Response":{"Error":{"Code":"InternalError.Timeout","Message":"请求下发短信超时"},"RequestId":"r7"}}`, ErrProviderUnavailable},
+	}
+	for _, tc := range cases {
+		server := tencentStub(t, tc.body, nil)
+		err := newTestTencentProvider(t, server.URL).SendSMS(context.Background(), "13800000031", "您的资质审核已通过")
+		server.Close()
+
+		var providerErr *ProviderError
+		if !errors.Is(err, tc.want) || !errors.As(err, &providerErr) || providerErr.RequestID == "" {
+			t.Fatalf("body %s: err=%v want %v with vendor details", tc.body, err, tc.want)
+		}
+	}
+
+	// 签名错误映射为鉴权失败
+	server := tencentStub(t, "", nil)
+	defer server.Close()
+	provider := newTestTencentProvider(t, server.URL)
+	provider.cfg.SecretKey = "wrong"
+	if err := provider.SendTemplate(context.Background(), "13800000032", "", nil); !errors.Is(err, ErrProviderAuth) {
+		t.Fatalf("bad signature err=%v want ErrProviderAuth", err)
+	}
+}
@@ -0,0 +1,309 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 发送队列默认参数
+const (
+	defaultQueueStream       = "sms:queue"
+	defaultQueueGroup        = "sms-senders"
+	defaultQueueWorkers      = 4
+	defaultQueueMaxAttempts  = 3
+	defaultQueueRetryBackoff = time.Second
+	defaultQueueClaimIdle    = time.Minute
+	defaultQueueMaxLen       = 10000
+	queueReadBlock           = 2 * time.Second
+	queuePayloadField        = "payload"
+)
+
+// QueueConfig 发送队列配置，零值字段使用默认值
+//
+// 字段说明：
+//   - Stream / Group: Redis Stream 键名与消费组，默认 sms:queue / sms-senders
+//   - Consumer: 消费者名称前缀，默认 主机名-进程号，多实例部署时须互不相同
+//   - Workers: 工作协程数，默认 4
+//   - MaxAttempts: 单条消息最多发送次数（可重试错误才会重发），默认 3
+//   - RetryBackoff: 重发前等待时间，每次翻倍，默认 1 秒
+//   - ClaimIdle: 消息被领取后超过该时长未确认（进程崩溃）即由其他消费者接管，默认 1 分钟
+//   - MaxLen: Stream 近似长度上限，默认 10000
+type QueueConfig struct {
+	Stream       string
+	Group        string
+	Consumer     string
+	Workers      int
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	ClaimIdle    time.Duration
+	MaxLen       int64
+}
+
+// Queue 基于 Redis Stream 的短信发送队列
+//
+// Enqueue 写入发送日志后入队即返回，工作协程通过消费组领取消息并调用 Provider，
+// 受理结果（含服务商流水号）写回发送日志，最终送达状态由服务商回执回调更新。
+// 进程崩溃时已领取未确认的消息超过 ClaimIdle 后由其他消费者接管；
+// 验证码消息携带过期时间，过期后不再发送
+type Queue struct {
+	client   *redis.Client
+	provider Provider
+	sendLog  SendLogStore
+	cfg      QueueConfig
+	logger   Logger
+
+	block time.Duration
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewQueue 创建发送队列，sendLog 为 nil 时不记录发送日志
+func NewQueue(client *redis.Client, provider Provider, sendLog SendLogStore, cfg QueueConfig) *Queue {
+	if cfg.Stream == "" {
+		cfg.Stream = defaultQueueStream
+	}
+	if cfg.Group == "" {
+		cfg.Group = defaultQueueGroup
+	}
+	if cfg.Consumer == "" {
+		host, _ := os.Hostname()
+		cfg.Consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultQueueWorkers
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultQueueMaxAttempts
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultQueueRetryBackoff
+	}
+	if cfg.ClaimIdle <= 0 {
+		cfg.ClaimIdle = defaultQueueClaimIdle
+	}
+	if cfg.MaxLen <= 0 {
+		cfg.MaxLen = defaultQueueMaxLen
+	}
+	return &Queue{
+		client:   client,
+		provider: provider,
+		sendLog:  sendLog,
+		cfg:      cfg,
+		logger:   StdLogger{},
+		block:    queueReadBlock,
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+}
+
+// SetLogger 设置自定义日志器
+func (q *Queue) SetLogger(l Logger) {
+	if l == nil {
+		return
+	}
+	q.logger = l
+}
+
+// Enqueue 写入发送日志并入队，返回消息ID
+//
+// 发送日志写入失败只记录错误、不阻止发送；入队失败返回 ErrStoreFailure
+func (q *Queue) Enqueue(ctx context.Context, msg Message) (string, error) {
+	if msg.ID == "" {
+		msg.ID = uuid.NewString()
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return "", fmt.Errorf("短信消息序列化失败: %w", err)
+	}
+
+	if q.sendLog != nil {
+		record := &SendRecord{
+			MessageID:    msg.ID,
+			MaskedPhone:  maskPhone(msg.Phone),
+			Category:     msg.Category,
+			TemplateCode: msg.TemplateCode,
+		}
+		if err := q.sendLog.Create(ctx, record); err != nil {
+			q.logger.Errorf("sms.Queue 发送日志写入失败 id=%s err=%v", msg.ID, err)
+		}
+	}
+
+	err = q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.cfg.Stream,
+		MaxLen: q.cfg.MaxLen,
+		Approx: true,
+		Values: map[string]any{queuePayloadField: payload},
+	}).Err()
+	if err != nil {
+		q.markFailed(context.WithoutCancel(ctx), msg.ID, 0, "入队失败")
+		return "", wrapRedisErr("XADD", q.cfg.Stream, err)
+	}
+	return msg.ID, nil
+}
+
+// Run 启动工作协程与超时消息接管，阻塞到 ctx 取消且全部协程退出
+func (q *Queue) Run(ctx context.Context) {
+	if err := q.ensureGroup(ctx); err != nil {
+		q.logger.Errorf("sms.Queue 创建消费组失败 stream=%s err=%v", q.cfg.Stream, err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < q.cfg.Workers; i++ {
+		consumer := fmt.Sprintf("%s-%d", q.cfg.Consumer, i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx, consumer)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.reclaim(ctx, q.cfg.Consumer+"-reclaim")
+	}()
+	wg.Wait()
+}
+
+// ensureGroup 创建消费组（Stream 不存在时一并创建），已存在时忽略
+func (q *Queue) ensureGroup(ctx context.Context) error {
+	err := q.client.XGroupCreateMkStream(ctx, q.cfg.Stream, q.cfg.Group, "0").Err()
+	if err != nil && !strings.Contains(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// work 工作协程：阻塞读取新消息并逐条处理
+func (q *Queue) work(ctx context.Context, consumer string) {
+	for ctx.Err() == nil {
+		streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    q.cfg.Group,
+			Consumer: consumer,
+			Streams:  []string{q.cfg.Stream, ">"},
+			Count:    1,
+			Block:    q.block,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			q.logger.Errorf("sms.Queue 读取失败 consumer=%s err=%v", consumer, err)
+			_ = q.sleep(ctx, time.Second)
+			continue
+		}
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				q.process(ctx, entry)
+			}
+		}
+	}
+}
+
+// reclaim 定期接管其他消费者领取后长时间未确认的消息
+func (q *Queue) reclaim(ctx context.Context, consumer string) {
+	interval := q.cfg.ClaimIdle / 2
+	for q.sleep(ctx, interval) == nil {
+		start := "0-0"
+		for ctx.Err() == nil {
+			entries, next, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream:   q.cfg.Stream,
+				Group:    q.cfg.Group,
+				Consumer: consumer,
+				MinIdle:  q.cfg.ClaimIdle,
+				Start:    start,
+				Count:    10,
+			}).Result()
+			if err != nil {
+				if ctx.Err() == nil {
+					q.logger.Errorf("sms.Queue 接管超时消息失败 err=%v", err)
+				}
+				break
+			}
+			for _, entry := range entries {
+				q.process(ctx, entry)
+			}
+			if next == "0-0" || len(entries) == 0 {
+				break
+			}
+			start = next
+		}
+	}
+}
+
+// process 发送一条消息，可重试错误按退避重发；完成后确认并删除消息
+//
+// 进程退出导致的中断不确认，消息留待接管后重发
+func (q *Queue) process(ctx context.Context, entry redis.XMessage) {
+	var msg Message
+	raw, _ := entry.Values[queuePayloadField].(string)
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		q.logger.Errorf("sms.Queue 丢弃无法解析的消息 entry=%s err=%v", entry.ID, err)
+		q.ack(ctx, entry.ID)
+		return
+	}
+
+	if !msg.ExpiresAt.IsZero() && q.now().After(msg.ExpiresAt) {
+		q.markFailed(ctx, msg.ID, 0, "消息已过期，放弃发送")
+		q.ack(ctx, entry.ID)
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		receipt, err := dispatchMessage(ctx, q.provider, msg)
+		if err == nil {
+			q.markSent(ctx, msg.ID, receipt, attempt)
+			break
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if !IsRetryable(err) || attempt >= q.cfg.MaxAttempts {
+			q.logger.Errorf("sms.Queue 发送失败 id=%s phone=%s attempts=%d err=%v", msg.ID, maskPhone(msg.Phone), attempt, err)
+			q.markFailed(ctx, msg.ID, attempt, err.Error())
+			break
+		}
+		if q.sleep(ctx, q.cfg.RetryBackoff<<(attempt-1)) != nil {
+			return
+		}
+	}
+	q.ack(ctx, entry.ID)
+}
+
+// ack 确认并删除消息，避免 Stream 中长期保留含验证码的内容
+func (q *Queue) ack(ctx context.Context, entryID string) {
+	ctx = context.WithoutCancel(ctx)
+	pipe := q.client.TxPipeline()
+	pipe.XAck(ctx, q.cfg.Stream, q.cfg.Group, entryID)
+	pipe.XDel(ctx, q.cfg.Stream, entryID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		q.logger.Errorf("sms.Queue 确认消息失败 entry=%s err=%v", entryID, err)
+	}
+}
+
+func (q *Queue) markSent(ctx context.Context, messageID string, receipt Receipt, attempts int) {
+	if q.sendLog == nil {
+		return
+	}
+	if err := q.sendLog.MarkSent(context.WithoutCancel(ctx), messageID, receipt, attempts); err != nil {
+		q.logger.Errorf("sms.Queue 发送日志更新失败 id=%s err=%v", messageID, err)
+	}
+}
+
+func (q *Queue) markFailed(ctx context.Context, messageID string, attempts int, reason string) {
+	if q.sendLog == nil {
+		return
+	}
+	if err := q.sendLog.MarkFailed(context.WithoutCancel(ctx), messageID, attempts, reason); err != nil {
+		q.logger.Errorf("sms.Queue 发送日志更新失败 id=%s err=%v", messageID, err)
+	}
+}
@@ -0,0 +1,158 @@
+package sms
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memSendLog 内存版发送日志
+type memSendLog struct {
+	mu      sync.Mutex
+	records map[string]*memSendEntry
+}
+
+type memSendEntry struct {
+	SendRecord
+	status   string
+	receipt  Receipt
+	attempts int
+	reason   string
+}
+
+func newMemSendLog() *memSendLog {
+	return &memSendLog{records: map[string]*memSendEntry{}}
+}
+
+func (l *memSendLog) Create(ctx context.Context, record *SendRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records[record.MessageID] = &memSendEntry{SendRecord: *record, status: SendStatusQueued}
+	return nil
+}
+
+func (l *memSendLog) MarkSent(ctx context.Context, messageID string, receipt Receipt, attempts int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := l.records[messageID]
+	entry.status, entry.receipt, entry.attempts = SendStatusSent, receipt, attempts
+	return nil
+}
+
+func (l *memSendLog) MarkFailed(ctx context.Context, messageID string, attempts int, reason string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := l.records[messageID]
+	entry.status, entry.attempts, entry.reason = SendStatusFailed, attempts, reason
+	return nil
+}
+
+func (l *memSendLog) ApplyDeliveryReport(ctx context.Context, report DeliveryReport) (bool, error) {
+	return false, nil
+}
+
+func (l *memSendLog) get(messageID string) memSendEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return *l.records[messageID]
+}
+
+func (l *memSendLog) only(t *testing.T) string {
+	t.Helper()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.records) != 1 {
+		t.Fatalf("send log has %d records, want 1", len(l.records))
+	}
+	for id := range l.records {
+		return id
+	}
+	return ""
+}
+
+func TestQueue_SendCodeReturnsOnceEnqueued(t *testing.T) {
+	store, _, ctx := newTestStore(t)
+	provider := &scriptedProvider{errs: []error{ErrProviderUnavailable}}
+	sendLog := newMemSendLog()
+	queue := NewQueue(store.client, provider, sendLog, QueueConfig{Workers: 1, MaxAttempts: 2})
+	queue.block = 20 * time.Millisecond
+	queue.sleep = func(ctx context.Context, d time.Duration) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+			return nil
+		}
+	}
+
+	svc := NewService(store, provider, SMSRuntimeConfig{Enabled: true, ExpireIn: time.Minute})
+	svc.SetQueue(queue)
+	if err := svc.SendCode(ctx, Scope{Role: "user", Purpose: PurposeLogin}, "13800000050"); err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	id := sendLog.only(t)
+	if entry := sendLog.get(id); entry.status != SendStatusQueued || entry.MaskedPhone != maskPhone("13800000050") || entry.Category != "user:login" || provider.calls != 0 {
+		t.Fatalf("after enqueue: entry=%+v calls=%d", entry, provider.calls)
+	}
+
+	// 验证码已过期的消息不再发送
+	expired, err := queue.Enqueue(ctx, Message{Phone: "13800000051", Content: "过期", ExpiresAt: time.Now().Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		queue.Run(runCtx)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for sendLog.get(id).status == SendStatusQueued || sendLog.get(expired).status == SendStatusQueued {
+		if time.Now().After(deadline) {
+			t.Fatal("queue did not process messages in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	// 第一次服务商不可用，退避后重发成功
+	if entry := sendLog.get(id); entry.status != SendStatusSent || entry.attempts != 2 || provider.calls != 2 || !strings.Contains(provider.last, "验证码") {
+		t.Fatalf("sent entry=%+v calls=%d last=%q", entry, provider.calls, provider.last)
+	}
+	if entry := sendLog.get(expired); entry.status != SendStatusFailed || entry.attempts != 0 {
+		t.Fatalf("expired entry=%+v", entry)
+	}
+	// 处理完成的消息已确认并从 Stream 删除
+	if n, err := store.client.XLen(ctx, defaultQueueStream).Result(); err != nil || n != 0 {
+		t.Fatalf("stream length=%d err=%v", n, err)
+	}
+}
+
+func TestParseDeliveryReports(t *testing.T) {
+	aliyun := `[{"phone_number":"13800000052","send_time":"2026-01-01 12:00:00","report_time":"2026-01-01 12:00:05","success":true,"err_code":"DELIVERED","err_msg":"用户接收成功","sms_size":"1","biz_id":"932702304080415357^0","out_id":""}]`
+	reports, err := ParseDeliveryReports(ProviderAliyun, []byte(aliyun))
+	if err != nil || len(reports) != 1 {
+		t.Fatalf("aliyun: reports=%+v err=%v", reports, err)
+	}
+	want := time.Date(2026, 1, 1, 4, 0, 5, 0, time.UTC)
+	if r := reports[0]; r.ReceiptID != "932702304080415357^0" || !r.Delivered || r.ErrCode != "DELIVERED" || !r.ReportedAt.Equal(want) {
+		t.Fatalf("aliyun report=%+v", r)
+	}
+
+	tencent := `[{"user_receive_time":"2026-01-01 12:00:05","nationcode":"86","mobile":"13800000053","report_status":"FAIL","errmsg":"MK:0001","description":"用户关机","sid":"2019:-4983302326488719875"}]`
+	reports, err = ParseDeliveryReports(ProviderTencent, []byte(tencent))
+	if err != nil || len(reports) != 1 {
+		t.Fatalf("tencent: reports=%+v err=%v", reports, err)
+	}
+	if r := reports[0]; r.ReceiptID != "2019:-4983302326488719875" || r.Delivered || r.ErrCode != "MK:0001" || r.ErrMessage != "用户关机" {
+		t.Fatalf("tencent report=%+v", r)
+	}
+
+	if _, err := ParseDeliveryReports("mock", []byte("[]")); err == nil {
+		t.Fatal("unknown provider should be rejected")
+	}
+}
@@ -51,3 +51,79 @@ if res and #res >= 2 then
 end
 return {0, 0}
 `)
+
+// 登记一次校验尝试：验证码不存在返回 {0, "", 0}；
+// 否则尝试次数 +1，达到上限时删除验证码，返回 {attempts, code, exhausted}
+// 比对在调用方以常量时间完成，脚本只负责原子计数，避免并发请求绕过次数限制
+var luaRegisterAttemptScript = redis.NewScript(`
+local key = KEYS[1]
+local maxAttempts = tonumber(ARGV[1])
+local code = redis.call('HGET', key, 'code')
+if not code then
+  return {0, '', 0}
+end
+local attempts = redis.call('HINCRBY', key, 'attempts', 1)
+local exhausted = 0
+if maxAttempts > 0 and attempts >= maxAttempts then
+  redis.call('DEL', key)
+  exhausted = 1
+end
+return {attempts, code, exhausted}
+`)
+
+// 多维度发送限额：依次检查黑名单、白名单、每日上限与各滑动窗口，全部通过且 record=1 时才计入
+// KEYS: [1] 黑名单 Hash [2] 白名单 Hash [3] 每日计数 [4..] 各维度窗口（ZSET，分值为纳秒时间戳）
+// ARGV: [1] 手机号 [2] 当前纳秒 [3] 本次记录的成员名 [4] record [5] 每日上限 [6] 每日计数过期秒数，
+// 其后每个窗口依次为 上限, 窗口纳秒
+// 返回 {allowed, code, retryMs}：code -1 黑名单，-2 白名单放行，-3 每日上限，>0 为被拦截窗口的序号
+var luaSendQuotaScript = redis.NewScript(`
+local phone = ARGV[1]
+local now = tonumber(ARGV[2])
+local record = ARGV[4] == '1'
+if redis.call('HEXISTS', KEYS[1], phone) == 1 then
+  return {0, -1, 0}
+end
+if redis.call('HEXISTS', KEYS[2], phone) == 1 then
+  return {1, -2, 0}
+end
+local dailyMax = tonumber(ARGV[5])
+if dailyMax > 0 then
+  local count = tonumber(redis.call('GET', KEYS[3]) or '0')
+  if count >= dailyMax then
+    local ttl = redis.call('PTTL', KEYS[3])
+    if ttl < 0 then
+      ttl = 0
+    end
+    return {0, -3, ttl}
+  end
+end
+for i = 4, #KEYS do
+  local max = tonumber(ARGV[7 + (i - 4) * 2])
+  local window = tonumber(ARGV[8 + (i - 4) * 2])
+  redis.call('ZREMRANGEBYSCORE', KEYS[i], '-inf', now - window)
+  local count = redis.call('ZCARD', KEYS[i])
+  if count >= max then
+    local oldest = redis.call('ZRANGE', KEYS[i], count - max, count - max, 'WITHSCORES')
+    local retry = 0
+    if oldest[2] then
+      retry = math.floor((tonumber(oldest[2]) + window - now) / 1000000)
+    end
+    return {0, i - 3, retry}
+  end
+end
+if record then
+  if dailyMax > 0 then
+    local ttl = redis.call('TTL', KEYS[3])
+    redis.call('INCR', KEYS[3])
+    if ttl < 0 then
+      redis.call('EXPIRE', KEYS[3], tonumber(ARGV[6]))
+    end
+  end
+  for i = 4, #KEYS do
+    local window = tonumber(ARGV[8 + (i - 4) * 2])
+    redis.call('ZADD', KEYS[i], now, ARGV[3])
+    redis.call('PEXPIRE', KEYS[i], math.ceil(window / 1000000))
+  end
+end
+return {1, 0, 0}
+`)
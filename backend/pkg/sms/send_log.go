@@ -0,0 +1,136 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 发送日志状态
+const (
+	SendStatusQueued      = "queued"      // 已入队，等待发送
+	SendStatusSent        = "sent"        // 服务商已受理，等待送达回执
+	SendStatusFailed      = "failed"      // 发送失败（重试用尽、不可重试错误或已过期）
+	SendStatusDelivered   = "delivered"   // 送达回执：用户已接收
+	SendStatusUndelivered = "undelivered" // 送达回执：未送达
+)
+
+// SendRecord 入队时写入的发送日志
+//
+// 手机号只保存脱敏后的形式，短信正文与模板变量（含验证码）不落库
+type SendRecord struct {
+	MessageID    string
+	MaskedPhone  string
+	Category     string
+	TemplateCode string
+}
+
+// DeliveryReport 服务商推送的送达回执
+type DeliveryReport struct {
+	Provider   string
+	ReceiptID  string    // 服务商流水号，与发送时的 Receipt.ReceiptID 对应
+	Delivered  bool      // 是否送达
+	ErrCode    string    // 服务商状态码，如 DELIVERED / DELIVRD
+	ErrMessage string    // 服务商状态描述
+	ReportedAt time.Time // 用户接收（或失败）时间
+}
+
+// SendLogStore 短信发送日志存储
+//
+// 由业务层实现（如数据库表），队列在入队、发送完成与收到送达回执时更新状态
+type SendLogStore interface {
+	// Create 记录一条已入队的消息
+	Create(ctx context.Context, record *SendRecord) error
+
+	// MarkSent 记录服务商已受理及其流水号
+	MarkSent(ctx context.Context, messageID string, receipt Receipt, attempts int) error
+
+	// MarkFailed 记录发送失败原因
+	MarkFailed(ctx context.Context, messageID string, attempts int, reason string) error
+
+	// ApplyDeliveryReport 按服务商与流水号更新送达状态，返回是否找到对应消息
+	ApplyDeliveryReport(ctx context.Context, report DeliveryReport) (bool, error)
+}
+
+// #region 送达回执解析
+
+// callbackTimeLayout 服务商回执中的时间格式（北京时间）
+const callbackTimeLayout = "2006-01-02 15:04:05"
+
+var callbackLocation = time.FixedZone("CST", 8*3600)
+
+// ParseDeliveryReports 解析服务商推送的送达回执
+//
+// 阿里云（SmsReport 消息推送）与腾讯云（短信下发状态回调）均为 JSON 数组，一次可推送多条
+func ParseDeliveryReports(provider string, body []byte) ([]DeliveryReport, error) {
+	switch provider {
+	case ProviderAliyun:
+		var items []struct {
+			BizID      string `json:"biz_id"`
+			Success    bool   `json:"success"`
+			ErrCode    string `json:"err_code"`
+			ErrMsg     string `json:"err_msg"`
+			ReportTime string `json:"report_time"`
+		}
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, fmt.Errorf("阿里云回执格式错误: %w", err)
+		}
+		reports := make([]DeliveryReport, 0, len(items))
+		for _, item := range items {
+			reports = append(reports, DeliveryReport{
+				Provider:   ProviderAliyun,
+				ReceiptID:  item.BizID,
+				Delivered:  item.Success,
+				ErrCode:    item.ErrCode,
+				ErrMessage: item.ErrMsg,
+				ReportedAt: parseCallbackTime(item.ReportTime),
+			})
+		}
+		return reports, nil
+	case ProviderTencent:
+		var items []struct {
+			SID             string `json:"sid"`
+			ReportStatus    string `json:"report_status"`
+			ErrMsg          string `json:"errmsg"`
+			Description     string `json:"description"`
+			UserReceiveTime string `json:"user_receive_time"`
+		}
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, fmt.Errorf("腾讯云回执格式错误: %w", err)
+		}
+		reports := make([]DeliveryReport, 0, len(items))
+		for _, item := range items {
+			reports = append(reports, DeliveryReport{
+				Provider:   ProviderTencent,
+				ReceiptID:  item.SID,
+				Delivered:  strings.EqualFold(item.ReportStatus, "SUCCESS"),
+				ErrCode:    item.ErrMsg,
+				ErrMessage: item.Description,
+				ReportedAt: parseCallbackTime(item.UserReceiveTime),
+			})
+		}
+		return reports, nil
+	}
+	return nil, fmt.Errorf("%w: 不支持的回执服务商 %q", ErrProviderConfig, provider)
+}
+
+// DeliveryReportAck 服务商要求的回执应答体，应答不符时服务商会重复推送
+func DeliveryReportAck(provider string) any {
+	if provider == ProviderTencent {
+		return map[string]any{"result": 0, "errmsg": "OK"}
+	}
+	return map[string]any{"code": 0, "msg": "成功"}
+}
+
+// parseCallbackTime 解析回执时间，格式不符时取当前时间
+func parseCallbackTime(value string) time.Time {
+	t, err := time.ParseInLocation(callbackTimeLayout, value, callbackLocation)
+	if err != nil {
+		return time.Now()
+	}
+	return t
+}
+
+// #endregion
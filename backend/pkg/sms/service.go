@@ -2,6 +2,8 @@ package sms
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,7 +15,7 @@ import (
 // 职责：
 //   - 整合存储（Store）、发送（Provider）、配置（Config）
 //   - 实现发送验证码的完整流程：限流 → 生成码 → 存储 → 发送
-//   - 实现验证验证码的流程：读取 → 比对 → 删除
+//   - 实现验证验证码的流程：登记尝试 → 常量时间比对 → 删除
 //
 // 使用示例：
 //
@@ -21,11 +23,13 @@ import (
 //	provider := sms.NewMockProvider()
 //	cfg := sms.SMSRuntimeConfig{Enabled: true, ExpireIn: 5*time.Minute, ...}
 //	service := sms.NewService(store, provider, cfg)
-//	err := service.SendCode(ctx, "13800000000")
+//	err := service.SendCode(ctx, sms.Scope{Role: "user", Purpose: sms.PurposeLogin}, "13800000000")
 type Service struct {
 	store    Store
 	provider Provider
 	cfg      SMSRuntimeConfig
+
+	queue *Queue
 }
 
 // ensureEnabled 返回服务是否启用的错误信息
@@ -56,6 +60,35 @@ func (s *Service) enforceRateLimit(phone string) error {
 	return nil
 }
 
+// enforceLimits 发送前的限额检查
+// Store 实现 LimitStore 时由一次原子脚本检查名单与全部维度，否则逐项检查频率与每日上限
+func (s *Service) enforceLimits(ctx context.Context, phone string) error {
+	if ls, ok := s.store.(LimitStore); ok {
+		err := ls.AcquireSendQuota(ctx, s.sendQuota(ctx, phone))
+		var limitErr *LimitError
+		if err != nil && !errors.As(err, &limitErr) {
+			return fmt.Errorf("限额检查失败: %w", err)
+		}
+		return err
+	}
+	if err := s.enforceRateLimit(phone); err != nil {
+		return err
+	}
+	return s.enforceDailyLimit(phone)
+}
+
+// sendQuota 组装本次发送涉及的限额
+func (s *Service) sendQuota(ctx context.Context, phone string) SendQuota {
+	return SendQuota{
+		Phone:      phone,
+		Client:     ClientInfoFrom(ctx),
+		RateMax:    s.cfg.RateMax,
+		RateWindow: s.cfg.RateWindow,
+		DailyMax:   s.cfg.DailyMax,
+		Limits:     s.cfg.Limits,
+	}
+}
+
 // enforceDailyLimit 递增日发送次数并判断是否超过上限
 func (s *Service) enforceDailyLimit(phone string) error {
 	if s.cfg.DailyMax <= 0 {
@@ -134,88 +167,96 @@ func (s *Service) inspectDailyLimit(ctx context.Context, phone string) (bool, ti
 //   - RateMax: 时间窗口内最大发送次数（如 1 次）
 //   - RateWindow: 时间窗口大小（如 60 秒）
 //   - DailyMax: 每日最大发送次数（0 表示不限制）
-//   - Template: 短信内容模板（如 "您的验证码是 %s，5分钟内有效"）
+//   - MaxAttempts: 单个验证码允许的校验次数，用尽即作废（<=0 时取 DefaultMaxAttempts）
+//   - Limits: 手机号之外的限额维度（见 SendLimits）；客户端信息由调用方通过 WithClientInfo 写入 ctx
+//   - Template: 短信内容模板（如 "您的验证码是 %s，5分钟内有效"）；
+//     Provider 实现 TemplateProvider 时为服务商模板编号（如阿里云 "SMS_123456789"），可留空使用服务商默认模板
 type SMSRuntimeConfig struct {
-	Enabled    bool
-	ExpireIn   time.Duration
-	RateMax    int
-	RateWindow time.Duration
-	DailyMax   int
-	Template   string
+	Enabled     bool
+	ExpireIn    time.Duration
+	RateMax     int
+	RateWindow  time.Duration
+	DailyMax    int
+	MaxAttempts int
+	Template    string
+
+	// Limits 客户端 IP、设备、号段与全站限额，需 Store 实现 LimitStore
+	Limits SendLimits
 }
 
+// DefaultMaxAttempts 单个验证码默认允许的校验次数
+const DefaultMaxAttempts = 5
+
 // NewService 创建短信服务实例
 func NewService(store Store, provider Provider, cfg SMSRuntimeConfig) *Service {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
 	return &Service{store: store, provider: provider, cfg: cfg}
 }
 
+// SetQueue 启用异步发送：SendCode 在消息入队后即返回，由队列工作协程调用 Provider
+func (s *Service) SetQueue(q *Queue) {
+	s.queue = q
+}
+
 // SendCode 发送验证码（完整流程）
 //
 // 执行步骤：
 //  1. 检查服务是否启用
-//  2. 验证手机号格式
-//  3. 检查发送频率限制（防刷）
+//  2. 校验作用域与手机号格式
+//  3. 检查号码名单与发送频率限制（防刷；Store 实现 LimitStore 时同时检查 IP、设备、号段与全站限额）
 //  4. 检查每日发送上限（可选）
 //  5. 生成随机验证码
-//  6. 保存验证码到存储（带过期时间）
-//  7. 调用 Provider 发送短信
-//  8. 如果发送失败，删除已保存的验证码
+//  6. 按作用域保存验证码到存储（带过期时间）
+//  7. 调用 Provider 发送短信；启用队列时入队即返回
+//  8. 如果发送（入队）失败，删除已保存的验证码
 //
 // 参数：
 //   - ctx: 上下文，用于超时控制
+//   - scope: 验证码作用域（角色 + 用途），校验时必须一致
 //   - phone: 目标手机号
 //
 // 返回：
-//   - nil: 发送成功
+//   - nil: 发送成功（启用队列时为已入队）
 //   - ErrProviderDisabled: 短信服务未启用
+//   - ErrScopeInvalid / ErrPurposeInvalid: 作用域不合法
 //   - ErrPhoneInvalid: 手机号格式错误
 //   - ErrSendTooFrequent: 发送过于频繁
 //   - ErrDailyLimitReached: 超过每日上限
+//   - ErrPhoneBlocked / ErrSendLimited: 号码被拉黑或其他维度超限（均为 *LimitError，可取出拦截维度）
 //   - 其他错误: 存储或发送失败
-func (s *Service) SendCode(ctx context.Context, phone string) error {
+func (s *Service) SendCode(ctx context.Context, scope Scope, phone string) error {
 	// 1. 检查服务状态
 	if err := s.ensureEnabled(); err != nil {
 		return err
 	}
 
-	// 2. 验证手机号
-	if err := s.validatePhone(phone); err != nil {
+	// 2. 校验作用域与手机号
+	if err := scope.Validate(); err != nil {
 		return err
 	}
-
-	// 3. 频率限制检查
-	if err := s.enforceRateLimit(phone); err != nil {
+	if err := s.validatePhone(phone); err != nil {
 		return err
 	}
 
-	// 4. 每日上限检查（可选）
-	if err := s.enforceDailyLimit(phone); err != nil {
+	// 3-4. 名单、频率限制与每日上限检查
+	if err := s.enforceLimits(ctx, phone); err != nil {
 		return err
 	}
 
 	// 5. 生成验证码
 	code := GenerateCode()
 
-	// 6. 保存到存储（优先使用带 ctx 的接口）
-	if cs, ok := s.store.(CtxStore); ok {
-		if err := cs.SaveCodeCtx(ctx, phone, code, s.cfg.ExpireIn); err != nil {
-			return fmt.Errorf("验证码保存失败: %w", err)
-		}
-	} else {
-		if err := s.store.SaveCode(phone, code, s.cfg.ExpireIn); err != nil {
-			return fmt.Errorf("验证码保存失败: %w", err)
-		}
+	// 6. 保存到存储
+	if err := s.saveCode(ctx, scope, phone, code); err != nil {
+		return fmt.Errorf("验证码保存失败: %w", err)
 	}
 
 	// 7. 发送短信
-	content := FormatContent(s.cfg.Template, code)
-	if err := s.provider.SendSMS(ctx, phone, content); err != nil {
+	if err := s.deliver(ctx, scope, phone, code); err != nil {
 		// 发送失败则删除已保存的验证码（忽略删除错误）
-		if cs, ok := s.store.(CtxStore); ok {
-			_ = cs.DeleteCodeCtx(ctx, phone)
-		} else {
-			_ = s.store.DeleteCode(phone)
-		}
+		_, _ = s.deleteCode(ctx, scope, phone)
 		return fmt.Errorf("短信发送失败: %w", err)
 	}
 
@@ -225,55 +266,67 @@ func (s *Service) SendCode(ctx context.Context, phone string) error {
 // VerifyCode 验证验证码
 //
 // 执行步骤：
-//  1. 检查验证码是否为空
-//  2. 从存储中获取保存的验证码
-//  3. 比对验证码
-//  4. 验证成功后删除验证码（防止重复使用）
+//  1. 检查验证码与作用域
+//  2. 原子登记一次尝试并取回存储的验证码（达到上限时验证码随即作废）
+//  3. 常量时间比对验证码
+//  4. 验证成功后删除验证码（防止重复使用；并发校验只有一方成功）
 //
 // 参数：
 //   - ctx: 上下文
+//   - scope: 验证码作用域，须与发送时一致
 //   - phone: 手机号
 //   - code: 用户输入的验证码
 //
 // 返回：
 //   - nil: 验证成功
 //   - ErrCodeEmpty: 验证码为空
+//   - ErrScopeInvalid / ErrPurposeInvalid: 作用域不合法
 //   - ErrCodeExpired: 验证码不存在或已过期
 //   - ErrCodeMismatch: 验证码错误
-func (s *Service) VerifyCode(ctx context.Context, phone, code string) error {
+//   - ErrCodeAttemptsExceeded: 错误次数已用尽，验证码已作废
+func (s *Service) VerifyCode(ctx context.Context, scope Scope, phone, code string) error {
 	// 1. 参数检查
 	if code == "" {
 		return ErrCodeEmpty
 	}
+	if err := scope.Validate(); err != nil {
+		return err
+	}
 
-	// 2. 获取存储的验证码（优先使用带 ctx 的接口）
-	var stored string
-	var err error
-	if cs, ok := s.store.(CtxStore); ok {
-		stored, err = cs.GetCodeCtx(ctx, phone)
-	} else {
-		stored, err = s.store.GetCode(phone)
+	// 2. 登记尝试并获取存储的验证码
+	attempt, err := s.registerAttempt(ctx, scope, phone)
+	if err != nil {
+		return fmt.Errorf("验证码读取失败: %w", err)
 	}
-	if err != nil || stored == "" {
+	if attempt.Code == "" {
 		return ErrCodeExpired
 	}
 
-	// 3. 比对验证码
-	if stored != code {
+	// 3. 常量时间比对，避免通过响应耗时逐位猜测
+	if subtle.ConstantTimeCompare([]byte(attempt.Code), []byte(code)) != 1 {
+		if attempt.Exhausted {
+			return ErrCodeAttemptsExceeded
+		}
 		return ErrCodeMismatch
 	}
 
-	// 4. 验证成功，删除验证码（一次性使用）
-	if cs, ok := s.store.(CtxStore); ok {
-		_ = cs.DeleteCodeCtx(ctx, phone)
-	} else {
-		_ = s.store.DeleteCode(phone)
+	// 4. 验证成功，删除验证码（一次性使用）；最后一次尝试时脚本已删除
+	if attempt.Exhausted {
+		return nil
+	}
+	deleted, err := s.deleteCode(ctx, scope, phone)
+	if err != nil {
+		return fmt.Errorf("验证码删除失败: %w", err)
+	}
+	if !deleted {
+		// 并发请求已先一步使用了该验证码
+		return ErrCodeExpired
 	}
 	return nil
 }
 
 // CanSend 只读检测：当前是否允许发送验证码，并返回需要等待的时间
-// 不会写入限流窗口，适合前端“按钮冷却时间”展示
+// 不会写入限流窗口，适合前端“按钮冷却时间”展示；被限额拦截时错误为 *LimitError，其中记录拦截维度
 func (s *Service) CanSend(ctx context.Context, phone string) (bool, time.Duration, error) {
 	if err := s.ensureEnabled(); err != nil {
 		return false, 0, err
@@ -282,6 +335,17 @@ func (s *Service) CanSend(ctx context.Context, phone string) (bool, time.Duratio
 		return false, 0, err
 	}
 
+	if ls, ok := s.store.(LimitStore); ok {
+		if err := ls.PeekSendQuota(ctx, s.sendQuota(ctx, phone)); err != nil {
+			var limitErr *LimitError
+			if errors.As(err, &limitErr) {
+				return false, limitErr.RetryAfter, err
+			}
+			return false, 0, fmt.Errorf("限额只读检查失败: %w", err)
+		}
+		return true, 0, nil
+	}
+
 	if allowed, retryAfter, err := s.peekRateLimit(ctx, phone); err != nil || !allowed {
 		return false, retryAfter, err
 	}
@@ -292,3 +356,51 @@ func (s *Service) CanSend(ctx context.Context, phone string) (bool, time.Duratio
 
 	return true, 0, nil
 }
+
+// deliver 发送验证码短信
+// 模板类服务商传递模板变量，其余 Provider 发送按 Template 渲染后的全文；
+// 启用队列时只负责入队，消息在验证码过期后不再发送
+func (s *Service) deliver(ctx context.Context, scope Scope, phone, code string) error {
+	msg := Message{Phone: phone, Category: scope.String()}
+	if s.cfg.ExpireIn > 0 {
+		msg.ExpiresAt = time.Now().Add(s.cfg.ExpireIn)
+	}
+	if _, ok := s.provider.(TemplateProvider); ok {
+		msg.TemplateCode = s.cfg.Template
+		msg.Params = []TemplateParam{{Name: CodeParamName, Value: code}}
+	} else {
+		msg.Content = FormatContent(s.cfg.Template, code)
+	}
+
+	if s.queue != nil {
+		_, err := s.queue.Enqueue(ctx, msg)
+		return err
+	}
+	_, err := dispatchMessage(ctx, s.provider, msg)
+	return err
+}
+
+// #region 存储适配（优先使用带 ctx 的接口）
+
+func (s *Service) saveCode(ctx context.Context, scope Scope, phone, code string) error {
+	if cs, ok := s.store.(CtxStore); ok {
+		return cs.SaveCodeCtx(ctx, scope, phone, code, s.cfg.ExpireIn)
+	}
+	return s.store.SaveCode(scope, phone, code, s.cfg.ExpireIn)
+}
+
+func (s *Service) deleteCode(ctx context.Context, scope Scope, phone string) (bool, error) {
+	if cs, ok := s.store.(CtxStore); ok {
+		return cs.DeleteCodeCtx(ctx, scope, phone)
+	}
+	return s.store.DeleteCode(scope, phone)
+}
+
+func (s *Service) registerAttempt(ctx context.Context, scope Scope, phone string) (AttemptResult, error) {
+	if cs, ok := s.store.(CtxStore); ok {
+		return cs.RegisterAttemptCtx(ctx, scope, phone, s.cfg.MaxAttempts)
+	}
+	return s.store.RegisterAttempt(scope, phone, s.cfg.MaxAttempts)
+}
+
+// #endregion
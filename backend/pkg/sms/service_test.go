@@ -0,0 +1,91 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingProvider 记录最近一次发送的内容，便于从短信中取出验证码
+type recordingProvider struct {
+	last string
+}
+
+func (p *recordingProvider) SendSMS(ctx context.Context, phone string, content string) error {
+	p.last = content
+	return nil
+}
+
+func (p *recordingProvider) code() string {
+	return strings.TrimSpace(p.last)
+}
+
+func newTestService(t *testing.T) (*Service, *recordingProvider) {
+	t.Helper()
+	store, _, _ := newTestStore(t)
+	provider := &recordingProvider{}
+	svc := NewService(store, provider, SMSRuntimeConfig{
+		Enabled:     true,
+		ExpireIn:    time.Minute,
+		MaxAttempts: 3,
+		Template:    "%s",
+	})
+	return svc, provider
+}
+
+func TestService_VerifyCodeRequiresMatchingScope(t *testing.T) {
+	svc, provider := newTestService(t)
+	ctx := context.Background()
+	phone := "13800000010"
+	register := Scope{Role: "user", Purpose: PurposeRegister}
+	login := Scope{Role: "user", Purpose: PurposeLogin}
+
+	if err := svc.SendCode(ctx, register, phone); err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	code := provider.code()
+
+	if err := svc.VerifyCode(ctx, login, phone, code); !errors.Is(err, ErrCodeExpired) {
+		t.Fatalf("register code used for login: err=%v want ErrCodeExpired", err)
+	}
+	if err := svc.VerifyCode(ctx, register, phone, code); err != nil {
+		t.Fatalf("VerifyCode: %v", err)
+	}
+	if err := svc.VerifyCode(ctx, register, phone, code); !errors.Is(err, ErrCodeExpired) {
+		t.Fatalf("code reused: err=%v want ErrCodeExpired", err)
+	}
+	if err := svc.SendCode(ctx, Scope{Role: "user", Purpose: "unknown"}, phone); !errors.Is(err, ErrPurposeInvalid) {
+		t.Fatalf("unknown purpose err=%v want ErrPurposeInvalid", err)
+	}
+}
+
+func TestService_VerifyCodeInvalidatesAfterMaxAttempts(t *testing.T) {
+	svc, provider := newTestService(t)
+	ctx := context.Background()
+	phone := "13800000011"
+	scope := Scope{Role: "merchant", Purpose: PurposeResetPassword}
+
+	if err := svc.SendCode(ctx, scope, phone); err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	code := provider.code()
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	if err := svc.VerifyCode(ctx, scope, phone, wrong); !errors.Is(err, ErrCodeMismatch) {
+		t.Fatalf("attempt 1 err=%v want ErrCodeMismatch", err)
+	}
+	if err := svc.VerifyCode(ctx, scope, phone, wrong); !errors.Is(err, ErrCodeMismatch) {
+		t.Fatalf("attempt 2 err=%v want ErrCodeMismatch", err)
+	}
+	if err := svc.VerifyCode(ctx, scope, phone, wrong); !errors.Is(err, ErrCodeAttemptsExceeded) {
+		t.Fatalf("attempt 3 err=%v want ErrCodeAttemptsExceeded", err)
+	}
+	if err := svc.VerifyCode(ctx, scope, phone, code); !errors.Is(err, ErrCodeExpired) {
+		t.Fatalf("correct code after exhaustion err=%v want ErrCodeExpired", err)
+	}
+}
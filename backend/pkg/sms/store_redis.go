@@ -21,11 +21,14 @@ import (
 // - 错误处理：为 Redis 操作添加了更丰富的上下文信息（操作/键名）。
 // - 键命名：引入可配置前缀（env/version），便于多环境/演进；默认前缀为 "sms"。
 // - 高并发优化：频率限制与每日计数改用 Lua 原子脚本，减少往返并避免竞态。
+// - 验证码按角色与用途隔离，错误尝试次数由 Lua 脚本原子累计。
 //
 // Redis 键命名规范：
-//   - sms:code:{phone}      验证码存储
+//   - sms:code:{role}:{purpose}:{phone}  验证码存储（Hash：code / attempts）
 //   - sms:rate_z:{phone}    限流时间窗口（ZSET，分值为时间戳）
 //   - sms:daily:{date}:{phone}  每日计数
+//   - sms:limit_z:{dimension}:{id}  IP / 设备 / 号段限额窗口（ZSET），全站预算为 sms:limit_z:global
+//   - sms:phones:{blocklist|allowlist}  号码名单（Hash：手机号 → 原因）
 type RedisStore struct {
 	client *redis.Client
 	prefix string // 键名前缀，默认 "sms"，支持多环境如 "dev:sms" / "prod:sms"
@@ -54,8 +57,8 @@ func (r *RedisStore) SetLogger(l Logger) {
 }
 
 // Redis 键生成函数
-func (r *RedisStore) codeKey(phone string) string {
-	return fmt.Sprintf("%s:code:%s", r.prefix, phone)
+func (r *RedisStore) codeKey(scope Scope, phone string) string {
+	return fmt.Sprintf("%s:code:%s:%s", r.prefix, scope, phone)
 }
 
 func (r *RedisStore) rateSortedSet(phone string) string {
@@ -66,54 +69,85 @@ func (r *RedisStore) dailyKey(phone string) string {
 	return fmt.Sprintf("%s:daily:%s:%s", r.prefix, time.Now().Format("20060102"), phone)
 }
 
-// SaveCode 保存验证码并设置过期时间（包含简易脱敏日志）
-// SaveCodeCtx 使用调用方上下文
-func (r *RedisStore) SaveCodeCtx(ctx context.Context, phone string, code string, expireIn time.Duration) error {
-	key := r.codeKey(phone)
-	if err := r.client.Set(ctx, key, code, expireIn).Err(); err != nil {
-		return wrapRedisErr("SET", key, err)
+// SaveCodeCtx 保存验证码并设置过期时间（包含简易脱敏日志）
+//
+// 验证码以 Hash 存储：code 为验证码，attempts 为已尝试次数；重新保存即清零尝试次数
+func (r *RedisStore) SaveCodeCtx(ctx context.Context, scope Scope, phone string, code string, expireIn time.Duration) error {
+	key := r.codeKey(scope, phone)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "code", code, "attempts", 0)
+		pipe.PExpire(ctx, key, expireIn)
+		return nil
+	})
+	if err != nil {
+		return wrapRedisErr("HSET", key, err)
 	}
 	// 简易日志：生产可替换为结构化日志并脱敏
 	if r.logger != nil {
-		r.logger.Infof("sms.SaveCode phone=%s ttl=%s", maskPhone(phone), expireIn.String())
+		r.logger.Infof("sms.SaveCode scope=%s phone=%s ttl=%s", scope, maskPhone(phone), expireIn.String())
 	} else {
-		log.Printf("sms.SaveCode phone=%s ttl=%s", maskPhone(phone), expireIn.String())
+		log.Printf("sms.SaveCode scope=%s phone=%s ttl=%s", scope, maskPhone(phone), expireIn.String())
 	}
 	return nil
 }
 
 // SaveCode 兼容旧接口，使用 context.Background
-func (r *RedisStore) SaveCode(phone string, code string, expireIn time.Duration) error {
-	return r.SaveCodeCtx(context.Background(), phone, code, expireIn)
+func (r *RedisStore) SaveCode(scope Scope, phone string, code string, expireIn time.Duration) error {
+	return r.SaveCodeCtx(context.Background(), scope, phone, code, expireIn)
 }
 
-// GetCode 获取存储的验证码
+// GetCodeCtx 获取存储的验证码
 //
 // 如果验证码不存在或已过期，返回 redis.Nil 错误
-func (r *RedisStore) GetCodeCtx(ctx context.Context, phone string) (string, error) {
-	key := r.codeKey(phone)
-	val, err := r.client.Get(ctx, key).Result()
+func (r *RedisStore) GetCodeCtx(ctx context.Context, scope Scope, phone string) (string, error) {
+	key := r.codeKey(scope, phone)
+	val, err := r.client.HGet(ctx, key, "code").Result()
 	if err != nil {
-		return "", wrapRedisErr("GET", key, err)
+		return "", wrapRedisErr("HGET", key, err)
 	}
 	return val, nil
 }
 
-func (r *RedisStore) GetCode(phone string) (string, error) {
-	return r.GetCodeCtx(context.Background(), phone)
+func (r *RedisStore) GetCode(scope Scope, phone string) (string, error) {
+	return r.GetCodeCtx(context.Background(), scope, phone)
 }
 
-// DeleteCode 删除验证码（验证成功后调用）
-func (r *RedisStore) DeleteCodeCtx(ctx context.Context, phone string) error {
-	key := r.codeKey(phone)
-	if err := r.client.Del(ctx, key).Err(); err != nil {
-		return wrapRedisErr("DEL", key, err)
+// DeleteCodeCtx 删除验证码（验证成功后调用），返回验证码此前是否存在
+//
+// 并发校验同一验证码时只有一个调用方能删除成功，借此保证验证码只被使用一次
+func (r *RedisStore) DeleteCodeCtx(ctx context.Context, scope Scope, phone string) (bool, error) {
+	key := r.codeKey(scope, phone)
+	n, err := r.client.Del(ctx, key).Result()
+	if err != nil {
+		return false, wrapRedisErr("DEL", key, err)
 	}
-	return nil
+	return n > 0, nil
+}
+
+func (r *RedisStore) DeleteCode(scope Scope, phone string) (bool, error) {
+	return r.DeleteCodeCtx(context.Background(), scope, phone)
+}
+
+// RegisterAttemptCtx 原子登记一次校验尝试并取回验证码（见 luaRegisterAttemptScript）
+func (r *RedisStore) RegisterAttemptCtx(ctx context.Context, scope Scope, phone string, maxAttempts int) (AttemptResult, error) {
+	key := r.codeKey(scope, phone)
+	res, err := luaRegisterAttemptScript.Run(ctx, r.client, []string{key}, strconv.Itoa(maxAttempts)).Result()
+	if err != nil {
+		return AttemptResult{}, wrapRedisErr("EVAL register_attempt", key, err)
+	}
+	arr, ok := res.([]interface{})
+	if !ok || len(arr) < 3 {
+		return AttemptResult{}, fmt.Errorf("redis EVAL register_attempt invalid result: %T", res)
+	}
+	attempts, _ := arr[0].(int64)
+	code, _ := arr[1].(string)
+	exhausted, _ := arr[2].(int64)
+	return AttemptResult{Code: code, Attempts: int(attempts), Exhausted: exhausted == 1}, nil
 }
 
-func (r *RedisStore) DeleteCode(phone string) error {
-	return r.DeleteCodeCtx(context.Background(), phone)
+func (r *RedisStore) RegisterAttempt(scope Scope, phone string, maxAttempts int) (AttemptResult, error) {
+	return r.RegisterAttemptCtx(context.Background(), scope, phone, maxAttempts)
 }
 
 // CheckRateLimit 检查发送频率限制（滑动时间窗口算法）
@@ -27,13 +27,14 @@ func TestRedisStore_SaveGetDeleteCode(t *testing.T) {
 	store, mr, ctx := newTestStore(t)
 	phone := "13800000000"
 	code := "123456"
+	scope := Scope{Role: "user", Purpose: PurposeLogin}
 
 	// Save
-	if err := store.SaveCode(phone, code, 1*time.Second); err != nil {
+	if err := store.SaveCode(scope, phone, code, 1*time.Second); err != nil {
 		t.Fatalf("SaveCode error: %v", err)
 	}
 	// Get
-	got, err := store.GetCode(phone)
+	got, err := store.GetCode(scope, phone)
 	if err != nil {
 		t.Fatalf("GetCode error: %v", err)
 	}
@@ -42,25 +43,81 @@ func TestRedisStore_SaveGetDeleteCode(t *testing.T) {
 	}
 	// Expire path: advance miniredis clock beyond TTL
 	mr.FastForward(2 * time.Second)
-	_, err = store.GetCode(phone)
+	_, err = store.GetCode(scope, phone)
 	if err == nil {
 		t.Fatalf("expected error for expired code, got nil")
 	}
 
 	// Re-save and delete
-	if err := store.SaveCode(phone, code, time.Minute); err != nil {
+	if err := store.SaveCode(scope, phone, code, time.Minute); err != nil {
 		t.Fatalf("re-SaveCode error: %v", err)
 	}
-	if err := store.DeleteCode(phone); err != nil {
+	if _, err := store.DeleteCode(scope, phone); err != nil {
 		t.Fatalf("DeleteCode error: %v", err)
 	}
-	_, err = store.GetCode(phone)
+	_, err = store.GetCode(scope, phone)
 	if err == nil {
 		t.Fatalf("expected error after delete, got nil")
 	}
 	_ = ctx // reserved for future context usage
 }
 
+func TestRedisStore_CodeScopedByRoleAndPurpose(t *testing.T) {
+	store, _, _ := newTestStore(t)
+	phone := "13800000003"
+	register := Scope{Role: "user", Purpose: PurposeRegister}
+
+	if err := store.SaveCode(register, phone, "123456", time.Minute); err != nil {
+		t.Fatalf("SaveCode error: %v", err)
+	}
+	for _, other := range []Scope{
+		{Role: "user", Purpose: PurposeLogin},
+		{Role: "rider", Purpose: PurposeRegister},
+	} {
+		if _, err := store.GetCode(other, phone); err == nil {
+			t.Fatalf("code saved for %s visible under %s", register, other)
+		}
+	}
+	if got, err := store.GetCode(register, phone); err != nil || got != "123456" {
+		t.Fatalf("GetCode got=%q err=%v", got, err)
+	}
+}
+
+func TestRedisStore_RegisterAttempt(t *testing.T) {
+	store, _, _ := newTestStore(t)
+	phone := "13800000004"
+	scope := Scope{Role: "user", Purpose: PurposeLogin}
+
+	res, err := store.RegisterAttempt(scope, phone, 3)
+	if err != nil || res.Code != "" || res.Attempts != 0 {
+		t.Fatalf("missing code: res=%+v err=%v", res, err)
+	}
+
+	if err := store.SaveCode(scope, phone, "123456", time.Minute); err != nil {
+		t.Fatalf("SaveCode error: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		res, err = store.RegisterAttempt(scope, phone, 3)
+		if err != nil {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+		if res.Code != "123456" || res.Attempts != i || res.Exhausted != (i == 3) {
+			t.Fatalf("attempt %d: unexpected result %+v", i, res)
+		}
+	}
+	if res, _ = store.RegisterAttempt(scope, phone, 3); res.Code != "" {
+		t.Fatalf("code should be gone after exhausting attempts, got %+v", res)
+	}
+
+	// 重新保存清零尝试次数
+	if err := store.SaveCode(scope, phone, "654321", time.Minute); err != nil {
+		t.Fatalf("re-SaveCode error: %v", err)
+	}
+	if res, _ = store.RegisterAttempt(scope, phone, 3); res.Attempts != 1 || res.Code != "654321" {
+		t.Fatalf("attempts not reset: %+v", res)
+	}
+}
+
 func TestRedisStore_CheckRateLimit_and_PeekRate(t *testing.T) {
 	store, _, _ := newTestStore(t)
 	phone := "13800000001"
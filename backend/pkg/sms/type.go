@@ -11,33 +11,96 @@
 //	provider := sms.NewMockProvider()
 //	cfg := sms.SMSRuntimeConfig{...}
 //	service := sms.NewService(store, provider, cfg)
-//	service.SendCode(ctx, phone)
-//	service.VerifyCode(ctx, phone, code)
+//	scope := sms.Scope{Role: "user", Purpose: sms.PurposeLogin}
+//	service.SendCode(ctx, scope, phone)
+//	service.VerifyCode(ctx, scope, phone, code)
 package sms
 
 import (
 	"context"
 	"crypto/rand"
 	"io"
+	"regexp"
 	"time"
 )
 
+// Purpose 验证码用途
+//
+// 验证码只能在申请时声明的用途下使用，避免注册验证码被拿去登录等越权场景
+type Purpose string
+
+const (
+	PurposeRegister      Purpose = "register"       // 注册
+	PurposeLogin         Purpose = "login"          // 验证码登录
+	PurposeResetPassword Purpose = "reset_password" // 找回密码
+	PurposeChangePhone   Purpose = "change_phone"   // 换绑手机号（发往新号码）
+)
+
+// ParsePurpose 解析用途字符串，未知用途返回 ErrPurposeInvalid
+func ParsePurpose(raw string) (Purpose, error) {
+	switch p := Purpose(raw); p {
+	case PurposeRegister, PurposeLogin, PurposeResetPassword, PurposeChangePhone:
+		return p, nil
+	default:
+		return "", ErrPurposeInvalid
+	}
+}
+
+// rolePattern 角色名只允许小写字母与下划线（会拼入 Redis 键名）
+var rolePattern = regexp.MustCompile(`^[a-z_]+$`)
+
+// Scope 验证码作用域
+//
+// 同一手机号可能同时是用户、商家、配送员，不同角色、不同用途的验证码互不通用
+type Scope struct {
+	Role    string  // 账号类型，如 user / merchant / rider
+	Purpose Purpose // 验证码用途
+}
+
+// Validate 校验作用域是否合法
+func (s Scope) Validate() error {
+	if !rolePattern.MatchString(s.Role) {
+		return ErrScopeInvalid
+	}
+	if _, err := ParsePurpose(string(s.Purpose)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// String 返回作用域的键名片段，形如 "user:login"
+func (s Scope) String() string {
+	return s.Role + ":" + string(s.Purpose)
+}
+
+// AttemptResult 一次校验尝试的登记结果
+type AttemptResult struct {
+	Code      string // 存储的验证码；为空表示验证码不存在或已过期
+	Attempts  int    // 含本次在内的已尝试次数
+	Exhausted bool   // 本次已用尽尝试次数，验证码已被作废
+}
+
 // Store 定义验证码存储与限流的抽象接口
 //
 // 实现类需要提供：
-//   - 验证码的保存、读取、删除（支持过期时间）
+//   - 按作用域（角色 + 用途）保存、读取、删除验证码（支持过期时间）
+//   - 原子登记校验尝试次数，达到上限即作废验证码
 //   - 发送频率限制（滑动时间窗口）
 //   - 每日发送次数统计
 type Store interface {
-	// SaveCode 保存验证码到存储，并设置过期时间
-	SaveCode(phone string, code string, expireIn time.Duration) error
+	// SaveCode 保存验证码到存储，并设置过期时间（重新保存会清零尝试次数）
+	SaveCode(scope Scope, phone string, code string, expireIn time.Duration) error
 
-	// GetCode 根据手机号获取存储的验证码
+	// GetCode 根据作用域与手机号获取存储的验证码
 	// 返回空字符串或错误表示验证码不存在或已过期
-	GetCode(phone string) (string, error)
+	GetCode(scope Scope, phone string) (string, error)
+
+	// DeleteCode 删除验证码（验证成功后调用），返回验证码此前是否存在
+	DeleteCode(scope Scope, phone string) (bool, error)
 
-	// DeleteCode 删除手机号对应的验证码（验证成功后调用）
-	DeleteCode(phone string) error
+	// RegisterAttempt 原子地登记一次校验尝试并取回验证码
+	// maxAttempts > 0 时，第 maxAttempts 次尝试后验证码即被删除
+	RegisterAttempt(scope Scope, phone string, maxAttempts int) (AttemptResult, error)
 
 	// CheckRateLimit 检查手机号在指定时间窗口内的发送次数是否超过上限
 	// maxCount: 时间窗口内最大允许次数，<=0 表示不限制
@@ -60,10 +123,11 @@ type Store interface {
 // CtxStore 是带上下文的存储接口，便于调用端传递超时/取消信号
 // 建议新代码优先实现/使用该接口；旧接口仍保留做兼容
 type CtxStore interface {
-	// 带 ctx 的验证码写入/读取/删除
-	SaveCodeCtx(ctx context.Context, phone string, code string, expireIn time.Duration) error
-	GetCodeCtx(ctx context.Context, phone string) (string, error)
-	DeleteCodeCtx(ctx context.Context, phone string) error
+	// 带 ctx 的验证码写入/读取/删除/尝试登记
+	SaveCodeCtx(ctx context.Context, scope Scope, phone string, code string, expireIn time.Duration) error
+	GetCodeCtx(ctx context.Context, scope Scope, phone string) (string, error)
+	DeleteCodeCtx(ctx context.Context, scope Scope, phone string) (bool, error)
+	RegisterAttemptCtx(ctx context.Context, scope Scope, phone string, maxAttempts int) (AttemptResult, error)
 
 	// 带 ctx 的限流与统计
 	CheckRateLimitCtx(ctx context.Context, phone string, maxCount int, interval time.Duration) (bool, error)
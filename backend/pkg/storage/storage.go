@@ -0,0 +1,113 @@
+// Package storage 提供文件存储抽象
+//
+// 业务层只依赖 Storage 接口并自行生成存储键；开发与单机部署使用 LocalStorage，
+// 生产环境可替换为 OSS / COS / S3 等对象存储实现。
+// 存储的文件默认不公开，读取须经业务层鉴权后由 Open 流式返回。
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultLocalDir 未配置 storage.local_dir 时的本地存储根目录
+const DefaultLocalDir = "./data/uploads"
+
+var (
+	ErrObjectNotFound = errors.New("文件不存在")
+	ErrInvalidKey     = errors.New("存储键无效")
+)
+
+// Storage 文件存储服务抽象接口
+type Storage interface {
+	// Put 写入文件，key 已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open 读取文件，调用方负责关闭；不存在时返回 ErrObjectNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除文件，不存在时不报错
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalStorage 本地磁盘存储实现，key 映射为根目录下的相对路径
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage 创建本地磁盘存储，root 为空时使用 DefaultLocalDir
+func NewLocalStorage(root string) *LocalStorage {
+	if root == "" {
+		root = DefaultLocalDir
+	}
+	return &LocalStorage{root: root}
+}
+
+// path 把 key 解析为根目录下的路径，拒绝绝对路径与 .. 越界
+func (s *LocalStorage) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, cleaned), nil
+}
+
+// Put 先写临时文件再重命名，避免读到写了一半的文件
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open 打开文件
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+// Delete 删除文件
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
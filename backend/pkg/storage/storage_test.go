@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	s := NewLocalStorage(t.TempDir())
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "merchants/1/license.png", strings.NewReader("png-bytes")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rc, err := s.Open(ctx, "merchants/1/license.png")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "png-bytes" {
+		t.Fatalf("content=%q", data)
+	}
+
+	if err := s.Delete(ctx, "merchants/1/license.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Open(ctx, "merchants/1/license.png"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("after delete err=%v want ErrObjectNotFound", err)
+	}
+
+	for _, key := range []string{"", "/etc/passwd", "../escape", "a/../../escape"} {
+		if err := s.Put(ctx, key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("key %q err=%v want ErrInvalidKey", key, err)
+		}
+	}
+}
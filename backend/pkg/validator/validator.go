@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 )
 
 const maxEmailLength = 254 // RFC 5321 limit for email addresses
@@ -72,3 +73,48 @@ func ValidatePhone(phone string) error {
 
 	return nil
 }
+
+// residentIDWeights GB 11643 校验码加权因子
+var residentIDWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+// residentIDCheckCodes 加权和模 11 对应的校验码
+const residentIDCheckCodes = "10X98765432"
+
+// IsResidentIDNumber 验证 18 位居民身份证号码（GB 11643-1999）
+// 校验地址码首位、出生日期与末位校验码，末位 x 视同 X
+func IsResidentIDNumber(id string) bool {
+	return ValidateResidentIDNumber(id) == nil
+}
+
+// ValidateResidentIDNumber 验证居民身份证号码并返回错误
+func ValidateResidentIDNumber(id string) error {
+	if id == "" {
+		return fmt.Errorf("身份证号不能为空")
+	}
+	if len(id) != 18 {
+		return fmt.Errorf("身份证号长度不正确")
+	}
+	id = strings.ToUpper(id)
+
+	sum := 0
+	for i := 0; i < 17; i++ {
+		c := id[i]
+		if c < '0' || c > '9' {
+			return fmt.Errorf("身份证号格式不正确")
+		}
+		sum += int(c-'0') * residentIDWeights[i]
+	}
+	if id[0] == '0' {
+		return fmt.Errorf("身份证号地址码无效")
+	}
+
+	birth, err := time.Parse("20060102", id[6:14])
+	if err != nil || birth.Year() < 1900 || birth.After(time.Now()) {
+		return fmt.Errorf("身份证号出生日期无效")
+	}
+
+	if id[17] != residentIDCheckCodes[sum%11] {
+		return fmt.Errorf("身份证号校验码不正确")
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package validator
+
+import "testing"
+
+func TestValidateResidentIDNumber(t *testing.T) {
+	valid := []string{"11010519491231002X", "11010519491231002x", "440308199901010012"}
+	for _, id := range valid {
+		if err := ValidateResidentIDNumber(id); err != nil {
+			t.Errorf("%s: unexpected error %v", id, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"wrong check digit": "110105194912310021",
+		"bad birth date":    "110105194913310021",
+		"future birth date": "110105299912310020",
+		"zero region":       "010105194912310026",
+		"letter in body":    "1101051949123100AX",
+		"too short":         "11010519491231002",
+	}
+	for name, id := range invalid {
+		if IsResidentIDNumber(id) {
+			t.Errorf("%s: %s should be rejected", name, id)
+		}
+	}
+}